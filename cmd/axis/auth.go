@@ -0,0 +1,103 @@
+/*
+File: cmd/axis/auth.go
+Description: `axis auth` subcommand for the oauth credential strategy. `axis
+auth login` runs the browser consent flow for the scopes the configuration
+needs and caches the token; `axis auth logout` removes the cache.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"axis/internal/config"
+	"axis/internal/credentials"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+const loginTimeout = 5 * time.Minute
+
+func runAuth(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: axis auth login|logout")
+	}
+	switch args[0] {
+	case "login":
+		return runAuthLogin(ctx, args[1:])
+	case "logout":
+		return runAuthLogout()
+	default:
+		return fmt.Errorf("unknown auth command %q (want login or logout)", args[0])
+	}
+}
+
+// scopeList collects repeated -scope flags.
+type scopeList []string
+
+func (l *scopeList) String() string     { return strings.Join(*l, ",") }
+func (l *scopeList) Set(v string) error { *l = append(*l, v); return nil }
+
+func runAuthLogin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("auth login", flag.ContinueOnError)
+	var extra scopeList
+	fs.Var(&extra, "scope", "additional scope to consent to (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	if cfg.CredentialStrategy != credentials.StrategyOAuth {
+		return errors.New("axis auth login needs AXIS_CREDENTIAL_STRATEGY=oauth")
+	}
+	o, err := credentials.LoadOAuth(cfg.OAuthClientFile, cfg.OAuthTokenFile)
+	if err != nil {
+		return err
+	}
+
+	scopes := cfg.Scopes(cfg.AirGap)
+	if cfg.PubSubTopic != "" {
+		scopes = append(scopes, pubsub.PubsubScope)
+	}
+	if cfg.BQDataset != "" {
+		scopes = append(scopes, bigquery.BigqueryScope)
+	}
+	for _, s := range extra {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+	err = o.Login(ctx, scopes, func(authURL string) {
+		fmt.Fprintf(os.Stderr, "Sign in as %s to grant Axis access:\n\n  %s\n\nWaiting for the browser...\n", cfg.AdminEmail, authURL)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Signed in; token cached in %s", cfg.OAuthTokenFile)
+	return nil
+}
+
+func runAuthLogout() error {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(cfg.OAuthTokenFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	log.Printf("Removed the OAuth token cache %s", cfg.OAuthTokenFile)
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+File: cmd/axis/completion.go
+Description: `axis completion bash|zsh|fish` prints a shell completion script
+for the subcommands and flags below. Source it from the shell's startup file,
+e.g. `source <(axis completion bash)`.
+*/
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"axis/internal/config"
+)
+
+// cliCommand describes a subcommand for completion. Keep it in step with the
+// flag sets of the run functions.
+type cliCommand struct {
+	Name  string
+	Flags []string
+	Subs  []cliCommand
+}
+
+var cliCommands = []cliCommand{
+	{Name: "serve", Flags: config.FlagNames()},
+	{Name: "export", Flags: []string{"format", "out"}, Subs: []cliCommand{
+		{Name: "site", Flags: []string{"ids", "title", "out"}},
+	}},
+	{Name: "import", Subs: []cliCommand{
+		{Name: "takeout", Flags: []string{"dry-run", "include-archived", "include-trashed", "yes"}},
+	}},
+	{Name: "db", Subs: []cliCommand{{Name: "migrate"}}},
+	{Name: "migrate", Flags: []string{"from", "dry-run", "yes"}},
+	{Name: "index", Subs: []cliCommand{
+		{Name: "rebuild", Flags: []string{"path"}},
+		{Name: "update", Flags: []string{"path"}},
+	}},
+	{Name: "registry", Subs: []cliCommand{
+		{Name: "export", Flags: []string{"format", "out"}},
+	}},
+	{Name: "service", Subs: []cliCommand{
+		{Name: "install", Flags: []string{"name", "workdir", "user", "log-file"}},
+		{Name: "uninstall", Flags: []string{"name", "user"}},
+		{Name: "run", Flags: []string{"name", "workdir", "log-file"}},
+	}},
+	{Name: "self-update", Flags: []string{"check", "force", "rollback", "yes"}},
+	{Name: "version"},
+	{Name: "plan", Subs: []cliCommand{
+		{Name: "keygen", Flags: []string{"out"}},
+		{Name: "show"},
+		{Name: "approve", Flags: []string{"key", "as", "yes"}},
+		{Name: "apply", Flags: []string{"yes"}},
+	}},
+	{Name: "auth", Subs: []cliCommand{
+		{Name: "login", Flags: []string{"scope"}},
+		{Name: "logout"},
+	}},
+	{Name: "completion", Subs: []cliCommand{{Name: "bash"}, {Name: "zsh"}, {Name: "fish"}}},
+}
+
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: axis completion bash|zsh|fish")
+	}
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout)
+	case "zsh":
+		fmt.Fprintln(os.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(os.Stdout)
+	case "fish":
+		writeFishCompletion(os.Stdout)
+	default:
+		return fmt.Errorf("unknown shell %q (want bash, zsh or fish)", args[0])
+	}
+	return nil
+}
+
+func commandNames(cmds []cliCommand) string {
+	names := make([]string, len(cmds))
+	for i, c := range cmds {
+		names[i] = c.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func flagWords(flags []string) string {
+	words := make([]string, len(flags))
+	for i, f := range flags {
+		words[i] = "--" + f
+	}
+	return strings.Join(words, " ")
+}
+
+// writeBashCompletion completes on the path of subcommands typed so far:
+// "import takeout" offers takeout's flags, "import" offers takeout.
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintln(w, "_axis() {")
+	fmt.Fprintln(w, `	local cur="${COMP_WORDS[COMP_CWORD]}" path="" word i`)
+	fmt.Fprintln(w, `	for ((i = 1; i < COMP_CWORD; i++)); do`)
+	fmt.Fprintln(w, `		word="${COMP_WORDS[i]}"`)
+	fmt.Fprintln(w, `		[[ "$word" == -* ]] || path="${path:+$path }$word"`)
+	fmt.Fprintln(w, `	done`)
+	fmt.Fprintln(w, `	local words`)
+	fmt.Fprintln(w, `	case "$path" in`)
+	fmt.Fprintf(w, "\t\"\") words=%q ;;\n", commandNames(cliCommands))
+	var walk func(prefix string, cmds []cliCommand)
+	walk = func(prefix string, cmds []cliCommand) {
+		for _, c := range cmds {
+			path := strings.TrimSpace(prefix + " " + c.Name)
+			words := strings.TrimSpace(commandNames(c.Subs) + " " + flagWords(c.Flags))
+			fmt.Fprintf(w, "\t%q) words=%q ;;\n", path, words)
+			walk(path, c.Subs)
+		}
+	}
+	walk("", cliCommands)
+	fmt.Fprintln(w, `	esac`)
+	fmt.Fprintln(w, `	COMPREPLY=($(compgen -W "$words" -- "$cur"))`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o default -F _axis axis")
+}
+
+// writeFishCompletion emits one complete line per subcommand and flag,
+// conditioned on the enclosing subcommands having been typed.
+func writeFishCompletion(w io.Writer) {
+	var walk func(parents []string, cmds []cliCommand)
+	walk = func(parents []string, cmds []cliCommand) {
+		cond := "__fish_use_subcommand"
+		if len(parents) > 0 {
+			var seen []string
+			for _, p := range parents {
+				seen = append(seen, "__fish_seen_subcommand_from "+p)
+			}
+			cond = strings.Join(seen, "; and ")
+		}
+		for _, c := range cmds {
+			fmt.Fprintf(w, "complete -c axis -f -n '%s' -a %s\n", cond, c.Name)
+		}
+		for _, c := range cmds {
+			path := append(append([]string(nil), parents...), c.Name)
+			var seen []string
+			for _, p := range path {
+				seen = append(seen, "__fish_seen_subcommand_from "+p)
+			}
+			for _, f := range c.Flags {
+				fmt.Fprintf(w, "complete -c axis -n '%s' -l %s\n", strings.Join(seen, "; and "), f)
+			}
+			walk(path, c.Subs)
+		}
+	}
+	walk(nil, cliCommands)
+}
@@ -0,0 +1,56 @@
+/*
+File: cmd/axis/db.go
+Description: `axis db` subcommand for state store maintenance. `axis db migrate`
+applies pending schema migrations and lists the applied versions; moving data
+between backends is `axis migrate`.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"axis/internal/config"
+	"axis/internal/store"
+)
+
+func runDB(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: axis db migrate")
+	}
+	switch args[0] {
+	case "migrate":
+		return runDBMigrate(ctx)
+	default:
+		return fmt.Errorf("unknown db command %q (want migrate)", args[0])
+	}
+}
+
+// runDBMigrate opens the configured store, which applies pending migrations,
+// and reports the schema version.
+func runDBMigrate(ctx context.Context) error {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	st, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	sqlStore, ok := st.(*store.SQLStore)
+	if !ok {
+		log.Printf("The file backend has no schema to migrate")
+		return nil
+	}
+	applied, err := sqlStore.Migrations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range applied {
+		log.Printf("Migration %d (%s) applied %s", m.Version, m.Name, m.AppliedAt.Format("2006-01-02 15:04:05"))
+	}
+	log.Printf("%s schema is current (%d migrations)", sqlStore.Dialect(), len(applied))
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+File: cmd/axis/export.go
+Description: `axis export` subcommand. Writes Keep notes as a Markdown or HTML
+bundle with an assets folder into a local directory; `axis export site` publishes
+selected Docs and notes as a static HTML site to a directory or Cloud Storage.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"axis/internal/config"
+	"axis/internal/export"
+
+	"google.golang.org/api/option"
+	storage "google.golang.org/api/storage/v1"
+)
+
+func runExport(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "site" {
+		return runSiteExport(ctx, args[1:])
+	}
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatFlag := fs.String("format", "md", "output format: md or html")
+	out := fs.String("out", "./export", "destination directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	ws, err := newWorkspaceService(ctx, cfg, nil, cfg.AirGap)
+	if err != nil {
+		return err
+	}
+
+	sink, err := export.NewDirSink(*out)
+	if err != nil {
+		return err
+	}
+
+	sum, err := export.New(ws, format).Export(ctx, sink)
+	if err != nil {
+		return err
+	}
+	for _, f := range sum.Failed {
+		log.Printf("Warning: %s", f)
+	}
+	log.Printf("Exported %d notes and %d attachments to %s", sum.Notes, sum.Attachments, *out)
+	return nil
+}
+
+func runSiteExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export site", flag.ContinueOnError)
+	ids := fs.String("ids", "", "comma-separated Doc IDs and note names (notes/...) to publish")
+	title := fs.String("title", "", "publish every Doc and note whose title contains this text")
+	out := fs.String("out", "./site", "destination directory or gs://bucket/prefix")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	ws, err := newWorkspaceService(ctx, cfg, nil, cfg.AirGap)
+	if err != nil {
+		return err
+	}
+
+	var sink export.Sink
+	if bucket, prefix, ok := export.ParseGCSURL(*out); ok {
+		ts, err := newCloudTokenSource(ctx, cfg, "storage", storage.DevstorageReadWriteScope)
+		if err != nil {
+			return err
+		}
+		svc, err := storage.NewService(ctx, option.WithTokenSource(ts))
+		if err != nil {
+			return fmt.Errorf("failed to create Cloud Storage service: %w", err)
+		}
+		sink = export.NewGCSSink(svc, bucket, prefix)
+	} else {
+		dir, err := export.NewDirSink(*out)
+		if err != nil {
+			return err
+		}
+		sink = dir
+	}
+
+	sel := export.Selection{TitleContains: *title}
+	if *ids != "" {
+		sel.IDs = strings.Split(*ids, ",")
+	}
+	sum, err := export.NewSite(ws).Export(ctx, sink, sel)
+	if err != nil {
+		return err
+	}
+	for _, f := range sum.Failed {
+		log.Printf("Warning: %s", f)
+	}
+	log.Printf("Published %d docs, %d notes and %d assets to %s", sum.Docs, sum.Notes, sum.Attachments, *out)
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+File: cmd/axis/import.go
+Description: `axis import` subcommand. Currently supports `axis import takeout <dir>`
+for recreating notes from a Google Takeout Keep export, after confirming the
+list of notes to be created.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"axis/internal/config"
+	"axis/internal/takeout"
+)
+
+func runImport(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "takeout" {
+		return fmt.Errorf("usage: axis import takeout [--dry-run] [--include-archived] [--include-trashed] [--yes] <dir>")
+	}
+
+	fs := flag.NewFlagSet("import takeout", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be imported without creating notes")
+	archived := fs.Bool("include-archived", false, "also import archived notes")
+	trashed := fs.Bool("include-trashed", false, "also import trashed notes")
+	yes := yesFlag(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: axis import takeout [flags] <dir>")
+	}
+	dir := fs.Arg(0)
+	opts := takeout.Options{
+		DryRun:          *dryRun,
+		IncludeArchived: *archived,
+		IncludeTrashed:  *trashed,
+	}
+
+	if !*dryRun {
+		entries, err := takeout.Read(dir)
+		if err != nil {
+			return err
+		}
+		selected := takeout.Selected(entries, opts)
+		if len(selected) == 0 {
+			log.Printf("No notes to import in %s", dir)
+			return nil
+		}
+		preview := make([]string, len(selected))
+		for i, e := range selected {
+			title := e.Note.Title
+			if title == "" {
+				title = "(untitled)"
+			}
+			preview[i] = fmt.Sprintf("%s  [%s]", title, e.Path)
+		}
+		if err := confirm(*yes, fmt.Sprintf("Create %d Keep notes:", len(selected)), preview); err != nil {
+			return err
+		}
+	}
+
+	// Importing creates notes, so it always asks for write access.
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	ws, err := newWorkspaceService(ctx, cfg, nil, false)
+	if err != nil {
+		return err
+	}
+
+	rep, err := takeout.Import(ctx, ws, dir, opts)
+	if err != nil {
+		return err
+	}
+	for _, f := range rep.Failed {
+		log.Printf("Failed: %s", f)
+	}
+	for _, m := range rep.AttachmentsMissing {
+		log.Printf("Missing media: %s", m)
+	}
+	verb := "Imported"
+	if *dryRun {
+		verb = "Would import"
+	}
+	log.Printf("%s %d of %d notes (%d skipped, %d failed); %d attachments not uploaded (unsupported by the Keep API)",
+		verb, rep.Created, rep.Scanned, rep.Skipped, len(rep.Failed), rep.AttachmentsSkipped)
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+File: cmd/axis/index.go
+Description: `axis index` subcommand for the local full-text search index.
+`axis index rebuild` indexes every note, Doc and Sheet from scratch; `axis index
+update` re-indexes only what changed since the saved index was built; with
+AXIS_INDEX_CONTENT_MB set both also export Docs and Sheets. A running server
+keeps its index current on its own and saves over the file, so these are for
+use while it is stopped.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"axis/internal/config"
+	"axis/internal/index"
+	"axis/internal/workspace"
+)
+
+func runIndex(ctx context.Context, args []string) error {
+	if len(args) == 0 || (args[0] != "rebuild" && args[0] != "update") {
+		return fmt.Errorf("usage: axis index rebuild|update [--path axis.index.json]")
+	}
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("index "+args[0], flag.ContinueOnError)
+	defaultPath := cfg.IndexPath
+	if defaultPath == "" {
+		defaultPath = "axis.index.json"
+	}
+	path := fs.String("path", defaultPath, "index file")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	x := index.New()
+	if args[0] == "update" {
+		if x, err = index.Load(*path); err != nil {
+			return err
+		}
+	}
+	x.SetContentLimit(cfg.IndexContentMB << 20)
+
+	ws, err := newWorkspaceService(ctx, cfg, nil, cfg.AirGap)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	var items []workspace.RegistryItem
+	for _, itemType := range ws.Types() {
+		list, err := ws.ListItems(ctx, itemType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", itemType, err)
+		}
+		items = append(items, list...)
+	}
+	stats, err := x.Update(ctx, ws, items)
+	if err != nil {
+		return err
+	}
+	for _, f := range stats.Failed {
+		log.Printf("Skipped: %s", f)
+	}
+	if stats.Queued > 0 {
+		content, err := x.FillContent(ctx, ws)
+		if err != nil {
+			return err
+		}
+		for _, f := range content.Failed {
+			log.Printf("Not exported: %s", f)
+		}
+		used, limit := x.ContentUsage()
+		log.Printf("Exported %d Docs and Sheets (%d left queued); %d of %d content bytes used", content.Exported, content.Pending, used, limit)
+	}
+	if err := x.Save(*path); err != nil {
+		return fmt.Errorf("failed to save index: %w", err)
+	}
+	log.Printf("Indexed %d items (%d unchanged, %d removed, %d failed) into %s in %s",
+		stats.Indexed, stats.Unchanged, stats.Removed, len(stats.Failed), *path, time.Since(start).Round(time.Millisecond))
+	return nil
+}
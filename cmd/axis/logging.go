@@ -0,0 +1,26 @@
+/*
+File: cmd/axis/logging.go
+Description: Process-wide structured logging. log_level (debug, info, warn,
+error; default info) and log_format (json or text; default json) configure the
+slog logger the server writes to stdout; serve also routes the standard log
+package through it.
+*/
+package main
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+
+	"axis/internal/config"
+)
+
+// newLogger builds a logger writing to w as the validated configuration
+// describes.
+func newLogger(w io.Writer, cfg *config.Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.Level()}
+	if strings.EqualFold(cfg.LogFormat, "text") {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
@@ -2,25 +2,65 @@
 File: cmd/axis/main.go
 Description: Entry point for the Axis application. Initializes Google Workspace services
 using service account impersonation and starts the web-based terminal server. Updated
-to use read-only scopes matching Domain-Wide Delegation.
+to use read-only scopes matching Domain-Wide Delegation. Subcommands (e.g. export)
+reuse the same service bootstrap.
 */
 package main
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"axis/internal/auth"
+	"axis/internal/backup"
+	"axis/internal/cluster"
+	"axis/internal/config"
+	"axis/internal/credentials"
+	"axis/internal/cron"
+	"axis/internal/events"
+	"axis/internal/faults"
+	"axis/internal/federation"
+	"axis/internal/index"
+	"axis/internal/plan"
+	"axis/internal/playbook"
+	"axis/internal/quota"
+	"axis/internal/rules"
+	"axis/internal/scheduler"
 	"axis/internal/server"
+	"axis/internal/sheetconfig"
+	"axis/internal/store"
+	"axis/internal/warehouse"
 	"axis/internal/workspace"
 
 	"github.com/joho/godotenv"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/oauth2"
 	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	bigquery "google.golang.org/api/bigquery/v2"
+	calendar "google.golang.org/api/calendar/v3"
 	docs "google.golang.org/api/docs/v1"
 	drive "google.golang.org/api/drive/v3"
+	drivelabels "google.golang.org/api/drivelabels/v2"
+	gmail "google.golang.org/api/gmail/v1"
 	"google.golang.org/api/impersonate"
 	keep "google.golang.org/api/keep/v1"
 	"google.golang.org/api/option"
+	pubsub "google.golang.org/api/pubsub/v1"
 	sheets "google.golang.org/api/sheets/v4"
 )
 
@@ -32,78 +72,727 @@ func main() {
 
 	ctx := context.Background()
 
+	cmd := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(ctx, args)
+	case "export":
+		err = runExport(ctx, args)
+	case "import":
+		err = runImport(ctx, args)
+	case "db":
+		err = runDB(ctx, args)
+	case "migrate":
+		err = runMigrate(ctx, args)
+	case "index":
+		err = runIndex(ctx, args)
+	case "registry":
+		err = runRegistry(ctx, args)
+	case "service":
+		err = runService(ctx, args)
+	case "self-update":
+		err = runSelfUpdate(ctx, args)
+	case "version":
+		err = runVersion()
+	case "plan":
+		err = runPlan(ctx, args)
+	case "auth":
+		err = runAuth(ctx, args)
+	case "completion":
+		err = runCompletion(args)
+	default:
+		err = fmt.Errorf("unknown command %q (want serve, export, import, db, migrate, index, registry, service, self-update, plan, auth, version or completion)", cmd)
+	}
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// runServe verifies the operator profile and runs the persistent TUI server
+// until SIGINT or SIGTERM, then shuts it down gracefully.
+func runServe(ctx context.Context, args []string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		return err
+	}
+	logger := newLogger(os.Stdout, cfg)
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := setupTracing(ctx)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.WithoutCancel(ctx))
+
+	transport := newAPITransport(cfg)
+	var injector *faults.Injector
+	if faults.Enabled {
+		injector = faults.NewInjector(nil)
+		transport.Base = injector
+		slog.Warn("fault injection build; faults are controlled at /api/debug/faults")
+	}
+	// One client span per attempt, so retries and injected faults show up as
+	// separate requests.
+	transport.Base = otelhttp.NewTransport(transport.Base)
+	disabled, err := checkScopes(ctx, cfg, cfg.AirGap)
+	if err != nil {
+		return err
+	}
+	ws, err := newWorkspaceService(ctx, cfg, transport, cfg.AirGap)
+	if err != nil {
+		return err
+	}
+
+	// 6. Verification check
+	user, err := ws.GetUser(cfg.UserEmail)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	slog.Info("verification successful", "name", user.Name, "email", user.Email)
+
+	// 7. Start the Persistent TUI Server
+	port := strconv.Itoa(cfg.Port)
+	_, shownLocation := cfg.StateLocation()
+	about := server.About{
+		Version:        version,
+		StartedAt:      time.Now(),
+		Tenant:         cfg.Tenant(),
+		Admin:          cfg.AdminEmail,
+		ServiceAccount: cfg.ServiceAccountEmail,
+		Scopes:         ws.Scopes(),
+		StateBackend:   cfg.StateBackend + " (" + shownLocation + ")",
+		Listen:         ":" + port,
+	}
+	for _, f := range disabled {
+		about.Disable(f.name, strings.Join(f.scopes, " ")+" not granted")
+	}
+	about.Safety.ReadOnly = cfg.AirGap
+	about.Safety.DirectoryWrite = slices.Contains(about.Scopes, admin.AdminDirectoryUserScope)
+	if transport.Limiter != nil {
+		about.Enable("quota", fmt.Sprintf("%g req/s, burst %d, %d API budgets", transport.Limiter.Rate(), transport.Limiter.Burst(), len(transport.APIs)))
+	}
+	if _, tracing := otel.GetTracerProvider().(*sdktrace.TracerProvider); tracing {
+		about.Enable("tracing", "otlp")
+	}
+
+	st, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	creds, err := loadCredentials(cfg)
+	if err != nil {
+		return err
+	}
+	opts := []server.Option{server.WithLogger(logger), server.WithStore(st), server.WithQuota(transport), server.WithTokenMonitor(tokenMonitor(cfg))}
+	opts = append(opts, server.WithBackups(backup.New(ws, cfg.BackupDir)))
+	if creds != nil {
+		opts = append(opts, server.WithCredentials(creds))
+	}
+	if injector != nil {
+		opts = append(opts, server.WithFaults(injector))
+	}
+
+	if cfg.Cluster {
+		coord, ok := st.(store.Coordinator)
+		if !ok {
+			return fmt.Errorf("AXIS_CLUSTER requires a shared state backend, not %s", cfg.StateBackend)
+		}
+		node := cluster.NewNode(coord, cfg.InstanceID, logger)
+		opts = append(opts, server.WithCluster(node))
+		about.Enable("cluster", node.ID())
+	}
+
+	authn, err := newAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, server.WithAuth(authn))
+	about.Safety.Auth = authn.Methods()
+	if creds != nil && creds.Active() != credentials.SlotDefault {
+		about.Enable("key_rotation", "active "+creds.Active())
+	}
+	if cfg.CredentialStrategy != credentials.StrategyImpersonate {
+		about.Enable("credentials", cfg.CredentialStrategy)
+	}
+
+	var catalog *playbook.Catalog
+	if path := cfg.PlaybooksFile; path != "" {
+		catalog, err = playbook.Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load playbooks: %w", err)
+		}
+		opts = append(opts, server.WithPlaybooks(catalog))
+		about.Enable("playbooks", path)
+	}
+
+	if path := cfg.FederationFile; path != "" {
+		fed, err := federation.Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load federation: %w", err)
+		}
+		opts = append(opts, server.WithFederation(fed, cfg.FederationInterval))
+		about.Enable("federation", fmt.Sprintf("%d members", len(fed.Members())))
+	}
+
+	if topic := cfg.PubSubTopic; topic != "" {
+		ts, err := newCloudTokenSource(ctx, cfg, "pubsub", pubsub.PubsubScope)
+		if err != nil {
+			return err
+		}
+		pubsubSvc, err := pubsub.NewService(ctx, option.WithTokenSource(ts))
+		if err != nil {
+			return fmt.Errorf("failed to create Pub/Sub service: %w", err)
+		}
+		opts = append(opts, server.WithPublisher(events.NewPubSubPublisher(pubsubSvc, topic)))
+		about.Enable("pubsub", topic)
+	}
+
+	if cfg.AirGap {
+		key, err := plan.ParsePrivateKey(cfg.PlanSigningKey)
+		if err != nil {
+			return fmt.Errorf("AXIS_AIRGAP requires AXIS_PLAN_SIGNING_KEY: %w", err)
+		}
+		opts = append(opts, server.WithAirGap(cfg.PlanDir, key))
+		about.Enable("airgap", "plans in "+cfg.PlanDir)
+	}
+
+	if calendarID := cfg.ReminderCalendar; calendarID != "" && cfg.AirGap {
+		slog.Warn("reminder sync writes to Calendar and is disabled in air-gapped mode")
+	} else if calendarID != "" {
+		opts = append(opts, server.WithReminderCalendar(calendarID))
+		about.Enable("reminders", calendarID)
+	}
+
+	if sheetID := cfg.NoteLogSheet; sheetID != "" && cfg.AirGap {
+		slog.Warn("the note log writes to Sheets and is disabled in air-gapped mode")
+	} else if sheetID != "" {
+		opts = append(opts, server.WithNoteLog(sheetID, cfg.NoteLogRange))
+		about.Enable("note_log", sheetID)
+	}
+
+	if folder := cfg.ArchiveFolder; folder != "" && cfg.AirGap {
+		slog.Warn("archiving moves files in Drive and is disabled in air-gapped mode")
+	} else if folder != "" {
+		opts = append(opts, server.WithArchiveFolder(folder))
+		about.Enable("archive", folder)
+	}
+
+	if cfg.DriveUpload && cfg.AirGap {
+		slog.Warn("uploads write to Drive and are disabled in air-gapped mode")
+	} else if cfg.DriveUpload {
+		opts = append(opts, server.WithDriveUpload())
+		about.Enable("drive_upload", "api")
+	}
+
+	if folder := cfg.AttachmentFolder; folder != "" && cfg.AirGap {
+		slog.Warn("attachment sync uploads to Drive and is disabled in air-gapped mode")
+	} else if folder != "" && slices.Contains(cfg.ItemTypes, "keep") {
+		opts = append(opts, server.WithAttachmentFolder(folder))
+		about.Enable("attachment_sync", folder)
+	}
+
+	if cfg.NoteConvert && cfg.AirGap {
+		slog.Warn("note conversion writes to Docs and is disabled in air-gapped mode")
+	} else if cfg.NoteConvert && slices.Contains(cfg.ItemTypes, "keep") {
+		opts = append(opts, server.WithNoteConvert())
+		about.Enable("note_convert", "keep to docs")
+	}
+
+	if to := cfg.CleanupDocTo; len(to) > 0 && cfg.AirGap {
+		slog.Warn("the cleanup plan Doc writes to Docs and is disabled in air-gapped mode")
+	} else if len(to) > 0 {
+		day, _ := cfg.CleanupWeekday()
+		opts = append(opts, server.WithCleanupDoc(to, day))
+		about.Enable("cleanup_doc", day.String())
+	}
+
+	if folder := cfg.ReportFolder; folder != "" && cfg.AirGap {
+		slog.Warn("summary reports write to Drive and are disabled in air-gapped mode")
+	} else if folder != "" {
+		var schedule *cron.Schedule
+		if cfg.ReportSchedule != "" {
+			schedule, _ = cron.Parse(cfg.ReportSchedule)
+		}
+		opts = append(opts, server.WithReports(folder, cfg.ReportFormat, schedule, cfg.ReportTo))
+		about.Enable("reports", cmp.Or(cfg.ReportSchedule, "on request"))
+	}
+
+	if cfg.LinkCheckEvery > 0 {
+		opts = append(opts, server.WithLinkScanInterval(cfg.LinkCheckEvery))
+	}
+
+	if len(cfg.Enrichers) > 0 {
+		opts = append(opts, server.WithEnrichers(cfg.Enrichers...))
+	}
+
+	if path := cfg.IndexPath; path != "" {
+		x, err := index.Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load search index: %w", err)
+		}
+		x.SetContentLimit(cfg.IndexContentMB << 20)
+		opts = append(opts, server.WithIndex(x, path))
+		detail := fmt.Sprintf("%s (%d items)", path, x.Len())
+		if cfg.IndexContentMB > 0 {
+			detail += fmt.Sprintf(", content up to %d MB", cfg.IndexContentMB)
+		}
+		about.Enable("index", detail)
+	}
+
+	if cfg.JournalRetention > 0 {
+		opts = append(opts, server.WithJournalRetention(cfg.JournalRetention))
+	}
+	if cfg.SnapshotRetention > 0 {
+		opts = append(opts, server.WithSnapshotRetention(cfg.SnapshotRetention))
+	}
+	if cfg.StatusGrace > 0 {
+		opts = append(opts, server.WithStatusGrace(cfg.StatusGrace))
+	}
+
+	if cfg.PollInterval > 0 || cfg.PollSchedule != "" {
+		sched, err := newPollSchedule(cfg)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, server.WithPollSchedule(sched))
+		about.Enable("poll_schedule", sched.String())
+	}
+
+	if path := cfg.RulesFile; path != "" {
+		set, err := rules.Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load rules: %w", err)
+		}
+		for _, rule := range set.UserRules() {
+			if _, ok := catalog.Playbook(rule.Playbook); rule.Playbook != "" && !ok {
+				return fmt.Errorf("user rule %q references unknown playbook %q", rule.Name, rule.Playbook)
+			}
+		}
+		opts = append(opts, server.WithRules(set))
+		about.Enable("rules", path)
+	}
+
+	if name := cfg.SuspendedPlaybook; name != "" {
+		if _, ok := catalog.Playbook(name); !ok {
+			return fmt.Errorf("AXIS_SUSPENDED_PLAYBOOK names unknown playbook %q", name)
+		}
+		opts = append(opts, server.WithSuspendedPlaybook(name))
+		about.Enable("suspended_playbook", name)
+	}
+
+	if len(cfg.UserSchemas) > 0 {
+		opts = append(opts, server.WithUserSchemas(cfg.UserSchemas))
+	}
+
+	if cfg.UserRuleInterval > 0 {
+		opts = append(opts, server.WithUserRuleInterval(cfg.UserRuleInterval))
+	}
+
+	// Sharing outside these domains flags items as externally exposed; the
+	// admin's own domain is internal by default.
+	opts = append(opts, server.WithInternalDomains(cfg.Domains()))
+
+	if cfg.DirectoryGroups {
+		opts = append(opts, server.WithDirectoryGroups())
+	}
+	if cfg.InactiveDays > 0 {
+		opts = append(opts, server.WithInactiveAfter(time.Duration(cfg.InactiveDays)*24*time.Hour))
+	}
+	if cfg.StorageLimitMB > 0 || cfg.StorageQuotaPct > 0 {
+		opts = append(opts, server.WithStorageThresholds(int64(cfg.StorageLimitMB)<<20, cfg.StorageQuotaPct))
+	}
+
+	if len(cfg.WebSocketOrigins) > 0 {
+		opts = append(opts, server.WithWebSocketOrigins(cfg.WebSocketOrigins))
+	}
+
+	if cfg.LegacyGETMutations {
+		opts = append(opts, server.WithLegacyGETMutations(true))
+		about.Safety.LegacyGETMutations = true
+		slog.Warn("deprecated GET mutation routes are enabled (AXIS_LEGACY_GET_MUTATIONS)")
+	}
+
+	if len(cfg.Reviewers) > 0 {
+		opts = append(opts, server.WithReviewers(cfg.Reviewers))
+	}
+
+	if sheetID := cfg.PolicySheetID; sheetID != "" {
+		opts = append(opts, server.WithPolicySheet(sheetconfig.NewLoader(ws, sheetID)))
+		about.Enable("policy_sheet", sheetID)
+	}
+
+	// The warehouse event buffer must be registered as a publisher before the
+	// server exists, while the registry source needs the server itself.
+	var warehouseEvents *warehouse.EventSource
+	if cfg.BQDataset != "" {
+		warehouseEvents = warehouse.NewEventSource()
+		opts = append(opts, server.WithPublisher(warehouseEvents))
+		about.Enable("bigquery", cfg.BQDataset)
+	}
+
+	if path := cfg.TenantsFile; path != "" {
+		tenants, stores, err := newTenantServers(ctx, cfg, transport, logger, catalog)
+		if err != nil {
+			return err
+		}
+		for _, st := range stores {
+			defer st.Close()
+		}
+		opts = append(opts, server.WithTenants(tenants))
+		about.Enable("tenants", fmt.Sprintf("%d hosted", len(tenants)))
+	}
+
+	srv := server.NewServer(ws, user, append(opts, server.WithAbout(about))...)
+	srv.About().PrintBanner(os.Stderr)
+
+	if cfg.BQDataset != "" {
+		exporter, err := newWarehouseExporter(ctx, cfg, srv, warehouseEvents)
+		if err != nil {
+			return err
+		}
+		go exporter.Run(ctx)
+	}
+
+	if err := srv.Start(ctx, port); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// openStateStore opens the configured state backend (file, sqlite, postgres or redis).
+func openStateStore(cfg *config.Config) (store.Store, error) {
+	location, shown := cfg.StateLocation()
+	st, err := store.Open(cfg.StateBackend, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	slog.Debug("state backend", "backend", cfg.StateBackend, "location", shown)
+	return st, nil
+}
+
+// newWorkspaceService validates the identities and builds the Google API
+// clients. Every Workspace API call goes through transport, which throttles by
+// partition and API and retries rate-limited calls. readOnly requests only
+// read-only scopes, so the Domain-Wide Delegation grant can omit write access.
+func newWorkspaceService(ctx context.Context, cfg *config.Config, transport *quota.Transport, readOnly bool) (*workspace.Service, error) {
 	// 2. Validation
-	adminEmail := os.Getenv("ADMIN_EMAIL")
-	serviceAccountEmail := os.Getenv("SERVICE_ACCOUNT_EMAIL")
-	userEmail := os.Getenv("USER_EMAIL")
-
-	if adminEmail == "" || serviceAccountEmail == "" || userEmail == "" {
-		log.Fatal("Error: ADMIN_EMAIL, SERVICE_ACCOUNT_EMAIL, and USER_EMAIL must be set.")
-	}
-
-	log.Printf("Initializing Services for %s via SA %s...", adminEmail, serviceAccountEmail)
-
-	// 3. Create the Token Source with Admin and Keep scopes
-	// Changed AdminDirectoryUserScope to AdminDirectoryUserReadonlyScope to match DWD permissions
-	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
-		TargetPrincipal: serviceAccountEmail,
-		Subject:         adminEmail,
-		Scopes: []string{
-			admin.AdminDirectoryUserReadonlyScope,
-			keep.KeepScope,
-			docs.DocumentsScope,
-			sheets.SpreadsheetsScope,
-			drive.DriveReadonlyScope,
-		},
-	})
+	if err := cfg.RequireWorkspace(); err != nil {
+		return nil, err
+	}
+	adminEmail, serviceAccountEmail := cfg.AdminEmail, cfg.ServiceAccountEmail
+
+	slog.Debug("initializing services", "admin", adminEmail, "service_account", serviceAccountEmail)
+
+	// 3. Create the Token Source with the scopes the enabled features need
+	scopes := cfg.Scopes(readOnly)
+	calendarID := cfg.ReminderCalendar
+	if readOnly {
+		calendarID = ""
+	}
+
+	ts, err := newTokenSource(ctx, cfg, adminEmail, scopes...)
 	if err != nil {
-		log.Fatalf("Failed to create token source: %v", err)
+		return nil, fmt.Errorf("failed to create token source: %w", err)
 	}
+	ts = tokenMonitor(cfg).Watch("workspace", adminEmail, scopes, ts)
+	client := &http.Client{Transport: &oauth2.Transport{
+		Source: ts,
+		Base:   transport,
+	}}
 
 	// 4. Create the Google API Services
-	adminSvc, err := admin.NewService(ctx, option.WithTokenSource(ts))
+	adminSvc, err := admin.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create Admin service: %v", err)
+		return nil, fmt.Errorf("failed to create Admin service: %w", err)
 	}
 
-	keepSvc, err := keep.NewService(ctx, option.WithTokenSource(ts))
+	keepSvc, err := keep.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create Keep service: %v", err)
+		return nil, fmt.Errorf("failed to create Keep service: %w", err)
 	}
 
-	docsSvc, err := docs.NewService(ctx, option.WithTokenSource(ts))
+	docsSvc, err := docs.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create Docs service: %v", err)
+		return nil, fmt.Errorf("failed to create Docs service: %w", err)
 	}
 
-	sheetsSvc, err := sheets.NewService(ctx, option.WithTokenSource(ts))
+	sheetsSvc, err := sheets.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create Sheets service: %v", err)
+		return nil, fmt.Errorf("failed to create Sheets service: %w", err)
 	}
 
-	driveSvc, err := drive.NewService(ctx, option.WithTokenSource(ts))
+	driveSvc, err := drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		log.Fatalf("Failed to create Drive service: %v", err)
+		return nil, fmt.Errorf("failed to create Drive service: %w", err)
+	}
+
+	var wsOpts []workspace.Option
+	if calendarID != "" {
+		calendarSvc, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Calendar service: %w", err)
+		}
+		wsOpts = append(wsOpts, workspace.WithCalendar(calendarSvc))
+	}
+	if len(cfg.ReportTo) > 0 && !readOnly {
+		gmailSvc, err := gmail.NewService(ctx, option.WithHTTPClient(client))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gmail service: %w", err)
+		}
+		wsOpts = append(wsOpts, workspace.WithGmail(gmailSvc))
+	}
+	if cfg.LoginReports || cfg.StorageReports {
+		reportsSvc, err := reports.NewService(ctx, option.WithHTTPClient(client))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Reports service: %w", err)
+		}
+		if cfg.LoginReports {
+			wsOpts = append(wsOpts, workspace.WithReports(reportsSvc))
+		}
+		if cfg.StorageReports {
+			wsOpts = append(wsOpts, workspace.WithUsageReports(reportsSvc))
+		}
+	}
+	if cfg.DriveTagLabel != "" {
+		labelsSvc, err := drivelabels.NewService(ctx, option.WithHTTPClient(client))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Drive Labels service: %w", err)
+		}
+		wsOpts = append(wsOpts, workspace.WithTagLabel(labelsSvc, cfg.DriveTagLabel, cfg.DriveTagField))
 	}
 
+	// Only delegating strategies can act as other users for the suspended-user
+	// content dashboard.
+	if credentials.Delegates(cfg.CredentialStrategy) {
+		wsOpts = append(wsOpts, workspace.WithImpersonation(newImpersonator(cfg, transport)))
+	}
+	wsOpts = append(wsOpts, workspace.WithScopes(scopes), workspace.WithItemTypes(cfg.ItemTypes...))
+
 	// 5. Initialize internal workspace wrapper
-	ws := workspace.NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc)
+	return workspace.NewService(adminSvc, keepSvc, docsSvc, sheetsSvc, driveSvc, wsOpts...), nil
+}
 
-	// 6. Verification check
-	user, err := ws.GetUser(userEmail)
+// newImpersonator acts as other domain users, for the suspended-user content
+// dashboard. It only reads, so it asks for read-only Keep, Drive and Calendar
+// scopes.
+func newImpersonator(cfg *config.Config, transport *quota.Transport) workspace.Impersonator {
+	return func(ctx context.Context, email string) (*workspace.Service, error) {
+		ts, err := newTokenSource(ctx, cfg, email, keep.KeepReadonlyScope, drive.DriveReadonlyScope, calendar.CalendarEventsReadonlyScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token source: %w", err)
+		}
+		client := option.WithHTTPClient(&http.Client{Transport: &oauth2.Transport{Source: ts, Base: transport}})
+		keepSvc, err := keep.NewService(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Keep service: %w", err)
+		}
+		driveSvc, err := drive.NewService(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Drive service: %w", err)
+		}
+		calendarSvc, err := calendar.NewService(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Calendar service: %w", err)
+		}
+		return workspace.NewService(nil, keepSvc, nil, nil, driveSvc, workspace.WithCalendar(calendarSvc)), nil
+	}
+}
+
+// newAPITransport applies the Workspace API limits: api_rate requests per
+// second (0 disables) with bursts up to api_burst, per-API budgets from
+// api_budgets ("drive=5,keep=2:4"), and api_retries retries for rate-limited
+// calls (0 disables). The values were validated by config.Load.
+func newAPITransport(cfg *config.Config) *quota.Transport {
+	budgets, _ := quota.ParseBudgets(cfg.APIBudgets)
+	retry := quota.DefaultRetry
+	retry.Retries = cfg.APIRetries
+
+	t := &quota.Transport{Limiter: quota.NewLimiter(cfg.APIRate, cfg.APIBurst), APIs: quota.NewLimiters(budgets)}
+	if retry.Retries > 0 {
+		t.Retry = &retry
+	}
+	for api, b := range budgets {
+		slog.Debug("workspace API budget", "api", api, "rate", b.Rate, "burst", b.Burst)
+	}
+	return t
+}
+
+// newPollSchedule uses poll_interval (default 60s) and per-type overrides from
+// poll_schedule ("keep=1m,doc=10m,sheet=10m").
+func newPollSchedule(cfg *config.Config) (*scheduler.Scheduler, error) {
+	def := time.Minute
+	if cfg.PollInterval > 0 {
+		def = cfg.PollInterval
+	}
+	overrides, _ := scheduler.ParseSchedule(cfg.PollSchedule)
+	return scheduler.New(workspace.ItemTypes, def, overrides)
+}
+
+// newCloudTokenSource returns a token for the service account itself (no
+// domain-wide delegation subject), used for GCP APIs such as Pub/Sub. With the
+// adc and oauth strategies it is the credential's own identity. name labels
+// the source in the token monitor.
+func newCloudTokenSource(ctx context.Context, cfg *config.Config, name string, scopes ...string) (oauth2.TokenSource, error) {
+	ts, err := newTokenSource(ctx, cfg, "", scopes...)
 	if err != nil {
-		log.Fatalf("Verification failed: %v", err)
+		return nil, fmt.Errorf("failed to create cloud token source: %w", err)
 	}
-	log.Printf("Verification successful: %s (%s)", user.Name, user.Email)
+	return tokenMonitor(cfg).Watch(name, "", scopes, ts), nil
+}
 
-	// 7. Start the Persistent TUI Server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// newTokenSource mints tokens for scopes acting as subject, or as the
+// credential's own identity when subject is empty, with the configured
+// credential strategy. adc and oauth cannot act as anyone but themselves, and
+// are taken to be signed in as admin_email.
+func newTokenSource(ctx context.Context, cfg *config.Config, subject string, scopes ...string) (oauth2.TokenSource, error) {
+	switch cfg.CredentialStrategy {
+	case credentials.StrategyADC, credentials.StrategyOAuth:
+		if subject != "" && subject != cfg.AdminEmail {
+			return nil, fmt.Errorf("%w: %s", credentials.ErrNoDelegation, cfg.CredentialStrategy)
+		}
+		if cfg.CredentialStrategy == credentials.StrategyADC {
+			return credentials.DefaultTokenSource(ctx, scopes...)
+		}
+		o, err := loadOAuth(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return o.TokenSource(ctx, scopes...)
 	}
+	creds, err := loadCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return creds.TokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: cfg.ServiceAccountEmail,
+		Subject:         subject,
+		Scopes:          scopes,
+	})
+}
+
+var credentialsOnce struct {
+	sync.Once
+	m   *credentials.Manager
+	err error
+}
 
-	srv := server.NewServer(ws, user)
-	if err := srv.Start(port); err != nil {
-		log.Fatalf("Server failed: %v", err)
+// loadCredentials loads the base keys used to impersonate the service account
+// once per process: sa_key_primary and sa_key_secondary name service account
+// key files, and without either Application Default Credentials are used. With
+// the key strategy the keys sign tokens themselves. Keys are health-checked by
+// acting as the admin with read-only Directory access. The adc and oauth
+// strategies have no keys to rotate and return a nil manager.
+func loadCredentials(cfg *config.Config) (*credentials.Manager, error) {
+	if !credentials.Delegates(cfg.CredentialStrategy) {
+		return nil, nil
 	}
+	c := &credentialsOnce
+	c.Do(func() {
+		check := impersonate.CredentialsConfig{
+			TargetPrincipal: cfg.ServiceAccountEmail,
+			Subject:         cfg.AdminEmail,
+			Scopes:          []string{admin.AdminDirectoryUserReadonlyScope},
+		}
+		newManager := credentials.NewManager
+		if cfg.CredentialStrategy == credentials.StrategyKey {
+			newManager = credentials.NewKeyManager
+		}
+		m, err := newManager(context.Background(), check, cfg.SAKeyPrimary, cfg.SAKeySecondary)
+		if err != nil {
+			c.err = fmt.Errorf("failed to load service account keys: %w", err)
+			return
+		}
+		for _, k := range m.Status() {
+			if k.Error != "" {
+				slog.Warn("service account key failed its health check", "slot", k.Slot, "error", k.Error)
+			}
+		}
+		slog.Debug("service account key", "slot", m.Active())
+		c.m = m
+	})
+	return c.m, c.err
+}
+
+var monitorOnce struct {
+	sync.Once
+	m *credentials.Monitor
+}
+
+// tokenMonitor returns the process's token monitor, which the server's
+// watchdog checks and reports at /api/auth/status.
+func tokenMonitor(cfg *config.Config) *credentials.Monitor {
+	c := &monitorOnce
+	c.Do(func() { c.m = credentials.NewMonitor(cfg.CredentialStrategy) })
+	return c.m
+}
+
+var oauthOnce struct {
+	sync.Once
+	o   *credentials.OAuth
+	err error
+}
+
+// loadOAuth reads the OAuth client and cached token of the oauth strategy
+// once per process.
+func loadOAuth(cfg *config.Config) (*credentials.OAuth, error) {
+	c := &oauthOnce
+	c.Do(func() { c.o, c.err = credentials.LoadOAuth(cfg.OAuthClientFile, cfg.OAuthTokenFile) })
+	return c.o, c.err
+}
+
+// newAuthenticator loads API access control. Without auth_file every API
+// request is refused unless auth_disabled explicitly opts out.
+func newAuthenticator(cfg *config.Config) (*auth.Authenticator, error) {
+	if path := cfg.AuthFile; path != "" {
+		a, err := auth.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load auth config: %w", err)
+		}
+		slog.Debug("API authentication loaded", "path", path)
+		return a, nil
+	}
+	if cfg.AuthDisabled {
+		slog.Warn("AXIS_AUTH_DISABLED is set; every API caller is treated as admin")
+		return auth.Disabled(), nil
+	}
+	slog.Warn("no AXIS_AUTH_FILE configured; all API requests will be rejected")
+	return nil, nil
+}
+
+// leaderSnapshot limits registry exports to the cluster leader so several
+// instances do not write duplicate snapshots.
+func leaderSnapshot(srv *server.Server) func(context.Context) ([]workspace.RegistryItem, error) {
+	return func(ctx context.Context) ([]workspace.RegistryItem, error) {
+		if !srv.IsLeader() {
+			return nil, nil
+		}
+		return srv.RegistrySnapshot(ctx)
+	}
+}
+
+// newWarehouseExporter builds the BigQuery exporter for the "project.dataset"
+// target in bq_dataset.
+func newWarehouseExporter(ctx context.Context, cfg *config.Config, srv *server.Server, evts *warehouse.EventSource) (*warehouse.Exporter, error) {
+	project, dataset, _ := strings.Cut(cfg.BQDataset, ".")
+	ts, err := newCloudTokenSource(ctx, cfg, "bigquery", bigquery.BigqueryScope)
+	if err != nil {
+		return nil, err
+	}
+	bqSvc, err := bigquery.NewService(ctx, option.WithTokenSource(ts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create BigQuery service: %w", err)
+	}
+
+	return warehouse.NewExporter(bqSvc, project, dataset, cfg.BQInterval, slog.Default(),
+		warehouse.NewRegistrySource(leaderSnapshot(srv)), evts), nil
 }
@@ -0,0 +1,101 @@
+/*
+File: cmd/axis/migrate.go
+Description: `axis migrate` subcommand. Copies state from the legacy
+axis.state.json (or any other backend) into the configured state backend,
+normalizing old status values, then re-reads the destination to verify it. The
+statuses to be written are previewed and confirmed first.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"axis/internal/config"
+	"axis/internal/store"
+)
+
+func runMigrate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	from := fs.String("from", store.BackendFile+":axis.state.json", "source store as backend:location, e.g. file:axis.state.json, sqlite:axis.db or redis:redis://host/0")
+	dryRun := fs.Bool("dry-run", false, "validate the source and report counts without writing")
+	yes := yesFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	backend, location, ok := strings.Cut(*from, ":")
+	if !ok || location == "" {
+		return fmt.Errorf("usage: axis migrate [--dry-run] [--yes] [--from backend:location]")
+	}
+	if backend == store.BackendFile {
+		if _, err := os.Stat(location); err != nil {
+			return fmt.Errorf("source state file: %w", err)
+		}
+	}
+	src, err := store.Open(backend, location)
+	if err != nil {
+		return fmt.Errorf("failed to open source store: %w", err)
+	}
+	defer src.Close()
+
+	inv, err := store.Inventory(ctx, src)
+	if err != nil {
+		return fmt.Errorf("source validation failed: %w", err)
+	}
+	st, err := src.LoadState(ctx)
+	if err != nil {
+		return fmt.Errorf("source validation failed: %w", err)
+	}
+	if st.Mode != "" && st.Mode != "AUTO" && st.Mode != "MANUAL" && st.Mode != "SIMULATE" && st.Mode != "AIRGAP" {
+		return fmt.Errorf("source has invalid mode %q", st.Mode)
+	}
+	normalized, legacy := store.NormalizeState(st)
+	log.Printf("Source %s: mode %q, %d statuses (%d legacy values to upgrade), %d deletions, %d events, %d comments, %d transitions, %d tokens",
+		*from, st.Mode, inv.Statuses, legacy, inv.Deletions, inv.Events, inv.Comments, inv.Transitions, inv.Tokens)
+	if *dryRun {
+		return nil
+	}
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	dstBackend := cfg.StateBackend
+	if backend == "" {
+		backend = store.BackendFile
+	}
+	if dstLocation, _ := cfg.StateLocation(); dstBackend == backend && dstLocation == location {
+		return fmt.Errorf("source and destination are the same store; set AXIS_STATE_BACKEND to the new backend")
+	}
+	preview := []string{fmt.Sprintf("mode %q", st.Mode)}
+	for _, id := range slices.Sorted(maps.Keys(normalized.Statuses)) {
+		preview = append(preview, fmt.Sprintf("%s: %s", id, normalized.Statuses[id]))
+	}
+	heading := fmt.Sprintf("Replace the %s state with %d statuses, %d deletions, %d events, %d comments, %d transitions and %d tokens from %s:",
+		dstBackend, inv.Statuses, inv.Deletions, inv.Events, inv.Comments, inv.Transitions, inv.Tokens, *from)
+	if err := confirm(*yes, heading, preview); err != nil {
+		return err
+	}
+
+	dst, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	rep, err := store.Copy(ctx, dst, src)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if err := store.Verify(ctx, dst, src); err != nil {
+		return fmt.Errorf("migration verification failed: %w", err)
+	}
+	log.Printf("Migrated %d statuses, %d deletions, %d events, %d comments, %d transitions and %d tokens to %s; verified",
+		rep.Statuses, rep.Deletions, rep.Events, rep.Comments, rep.Transitions, rep.Tokens, dstBackend)
+	return nil
+}
@@ -0,0 +1,333 @@
+/*
+File: cmd/axis/plan.go
+Description: `axis plan` subcommand, the offline half of AIRGAP mode. keygen
+creates signing keys, show prints a plan file, approve adds an approver's
+signature, and apply verifies the Axis signature and approvals and then makes
+the changes with write access. Items edited after they were proposed are
+skipped, and the outcome of each action is written next to the plan.
+*/
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"axis/internal/backup"
+	"axis/internal/config"
+	"axis/internal/plan"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const planUsage = "usage: axis plan keygen [--out FILE] | show <plan> | approve --key FILE --as EMAIL [--yes] <plan> | apply [--yes] <plan>"
+
+// planResult is the outcome of one applied action.
+type planResult struct {
+	Action  string `json:"action"`
+	Outcome string `json:"outcome"` // applied, skipped or failed
+	Detail  string `json:"detail,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+func runPlan(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(planUsage)
+	}
+	switch args[0] {
+	case "keygen":
+		return runPlanKeygen(args[1:])
+	case "show":
+		return runPlanShow(args[1:])
+	case "approve":
+		return runPlanApprove(args[1:])
+	case "apply":
+		return runPlanApply(ctx, args[1:])
+	}
+	return fmt.Errorf(planUsage)
+}
+
+func runPlanKeygen(args []string) error {
+	fs := flag.NewFlagSet("plan keygen", flag.ContinueOnError)
+	out := fs.String("out", "", "write the private key to this file instead of printing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	public, private, err := plan.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		fmt.Printf("private: %s\npublic:  %s\n", private, public)
+		return nil
+	}
+	if err := os.WriteFile(*out, []byte(private+"\n"), 0o600); err != nil {
+		return err
+	}
+	log.Printf("Private key written to %s", *out)
+	fmt.Println(public)
+	return nil
+}
+
+// originKey is the Axis public key plans must be signed with.
+func originKey() (ed25519.PublicKey, error) {
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := plan.ParsePublicKeys(cfg.PlanPublicKey)
+	if err != nil || len(keys) != 1 {
+		return nil, fmt.Errorf("AXIS_PLAN_PUBLIC_KEY must hold the Axis plan signing public key")
+	}
+	return keys[0], nil
+}
+
+func planFileArg(fs *flag.FlagSet, args []string) (*plan.File, string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if fs.NArg() != 1 {
+		return nil, "", fmt.Errorf(planUsage)
+	}
+	f, err := plan.Read(fs.Arg(0))
+	return f, fs.Arg(0), err
+}
+
+func runPlanShow(args []string) error {
+	f, _, err := planFileArg(flag.NewFlagSet("plan show", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	p, err := f.Plan()
+	if err != nil {
+		return err
+	}
+	signature := "not checked (AXIS_PLAN_PUBLIC_KEY unset)"
+	if key, kerr := originKey(); kerr == nil {
+		if _, verr := f.VerifyOrigin(key); verr != nil {
+			signature = "INVALID"
+		} else {
+			signature = "valid"
+		}
+	}
+	fmt.Printf("Plan %s from %s\n", p.ID, p.Origin)
+	fmt.Printf("Created %s, expires %s\n", p.Created.Format(time.RFC3339), p.Expires.Format(time.RFC3339))
+	fmt.Printf("Signature: %s\n", signature)
+	for _, a := range f.Approvals {
+		fmt.Printf("Approved by %s at %s\n", a.Approver, a.Time.Format(time.RFC3339))
+	}
+	fmt.Printf("%d actions:\n", len(p.Actions))
+	for _, a := range p.Actions {
+		fmt.Printf("  %s\n", a.Describe())
+	}
+	return nil
+}
+
+func runPlanApprove(args []string) error {
+	fs := flag.NewFlagSet("plan approve", flag.ContinueOnError)
+	keyFile := fs.String("key", "", "approver private key file (from axis plan keygen --out)")
+	approver := fs.String("as", "", "approver identity recorded with the signature")
+	yes := yesFlag(fs)
+	f, path, err := planFileArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if *keyFile == "" || *approver == "" {
+		return fmt.Errorf("plan approve requires --key and --as")
+	}
+	raw, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return err
+	}
+	key, err := plan.ParsePrivateKey(string(raw))
+	if err != nil {
+		return err
+	}
+	origin, err := originKey()
+	if err != nil {
+		return err
+	}
+	p, err := f.VerifyOrigin(origin)
+	if err != nil {
+		return err
+	}
+	if err := confirm(*yes, fmt.Sprintf("Approve plan %s with %d actions as %s:", p.ID, len(p.Actions), *approver), describeActions(p)); err != nil {
+		return err
+	}
+	f.Approve(*approver, key, time.Now())
+	if err := f.Write(path); err != nil {
+		return err
+	}
+	log.Printf("Approved %s (%d approvals)", path, len(f.Approvals))
+	return nil
+}
+
+func runPlanApply(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("plan apply", flag.ContinueOnError)
+	yes := yesFlag(fs)
+	f, path, err := planFileArg(fs, args)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	origin, err := originKey()
+	if err != nil {
+		return err
+	}
+	approvers, err := plan.ParsePublicKeys(strings.Join(cfg.PlanApproverKeys, ","))
+	if err != nil {
+		return fmt.Errorf("AXIS_PLAN_APPROVER_KEYS: %w", err)
+	}
+	p, err := f.Verify(origin, approvers, cfg.PlanApprovals, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := confirm(*yes, fmt.Sprintf("Apply plan %s (%d actions):", p.ID, len(p.Actions)), describeActions(p)); err != nil {
+		return err
+	}
+
+	ws, err := newWorkspaceService(ctx, cfg, nil, false)
+	if err != nil {
+		return err
+	}
+	st, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	current, err := listPlanItems(ctx, ws, p)
+	if err != nil {
+		return err
+	}
+
+	backups := backup.New(ws, cfg.BackupDir)
+	actor := "plan:" + p.ID
+	results := make([]planResult, 0, len(p.Actions))
+	failed := 0
+	for _, a := range p.Actions {
+		res := planResult{Action: a.Describe(), Outcome: "applied"}
+		item, exists := current[a.ItemID]
+		switch {
+		case !exists:
+			res.Outcome, res.Detail = "skipped", "item no longer exists"
+		case a.Modified != nil && item.Modified != nil && !item.Modified.Equal(*a.Modified):
+			res.Outcome, res.Detail = "skipped", "edited since it was proposed"
+		default:
+			ref, err := applyAction(ctx, ws, backups, a)
+			res.Backup = ref
+			if err != nil {
+				res.Outcome, res.Detail = "failed", err.Error()
+				failed++
+			}
+		}
+		if a.Op == plan.OpDelete {
+			recordPlanDeletion(ctx, st, a, actor, res)
+		}
+		log.Printf("%s: %s %s", res.Outcome, res.Action, res.Detail)
+		results = append(results, res)
+	}
+
+	report := strings.TrimSuffix(path, ".json") + ".result.json"
+	data, err := json.MarshalIndent(map[string]any{"plan": p.ID, "applied": time.Now().UTC(), "results": results}, "", "  ")
+	if err == nil {
+		err = os.WriteFile(report, append(data, '\n'), 0o600)
+	}
+	if err != nil {
+		log.Printf("Warning: failed to write %s: %v", report, err)
+	} else {
+		log.Printf("Results written to %s", report)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d actions failed", failed, len(p.Actions))
+	}
+	return nil
+}
+
+func describeActions(p plan.Plan) []string {
+	lines := make([]string, len(p.Actions))
+	for i, a := range p.Actions {
+		lines[i] = a.Describe()
+	}
+	return lines
+}
+
+// listPlanItems lists the item types the plan touches, keyed by ID.
+func listPlanItems(ctx context.Context, ws *workspace.Service, p plan.Plan) (map[string]workspace.RegistryItem, error) {
+	listed := make(map[string]bool)
+	items := make(map[string]workspace.RegistryItem)
+	for _, a := range p.Actions {
+		if listed[a.ItemType] {
+			continue
+		}
+		listed[a.ItemType] = true
+		list, err := ws.ListItems(ctx, a.ItemType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", a.ItemType, err)
+		}
+		for _, item := range list {
+			items[item.ID] = item
+		}
+	}
+	return items, nil
+}
+
+// applyAction backs up the Doc or Sheet an action changes, then applies it.
+// It returns the backup's audit reference; a failed backup fails the action.
+func applyAction(ctx context.Context, ws *workspace.Service, backups *backup.Backups, a plan.Action) (string, error) {
+	var ref string
+	if backup.Supports(a.ItemType) {
+		b, err := backups.Take(ctx, a.ItemID, a.ItemType)
+		if err != nil {
+			return "", err
+		}
+		ref = b.Ref()
+	}
+	return ref, runAction(ctx, ws, a)
+}
+
+func runAction(ctx context.Context, ws *workspace.Service, a plan.Action) error {
+	switch {
+	case a.Op == plan.OpSheetUpdate:
+		_, err := ws.UpdateSheetValues(ctx, a.ItemID, a.Range, a.Values, a.Raw)
+		return err
+	case a.Op == plan.OpDelete && a.ItemType == "keep":
+		return ws.DeleteNote(ctx, a.ItemID)
+	case a.Op == plan.OpDelete && a.ItemType == "doc":
+		return ws.DeleteDoc(ctx, a.ItemID)
+	case a.Op == plan.OpDelete && a.ItemType == "sheet":
+		return ws.DeleteSheet(ctx, a.ItemID)
+	}
+	return fmt.Errorf("unsupported action %s on %s", a.Op, a.ItemType)
+}
+
+// recordPlanDeletion adds an applied delete to the audit history.
+func recordPlanDeletion(ctx context.Context, st store.Store, a plan.Action, actor string, res planResult) {
+	outcome := map[string]string{
+		"applied": store.OutcomeDeleted,
+		"skipped": store.OutcomeRefused,
+		"failed":  store.OutcomeFailed,
+	}[res.Outcome]
+	err := st.RecordDeletion(ctx, store.Deletion{
+		Time:     time.Now().UTC(),
+		ItemID:   a.ItemID,
+		ItemType: a.ItemType,
+		Title:    a.Title,
+		Actor:    actor + " for " + a.Actor,
+		Mode:     "AIRGAP",
+		Outcome:  outcome,
+		Detail:   res.Detail,
+		Backup:   res.Backup,
+	})
+	if err != nil {
+		log.Printf("Warning: audit write failed for %s: %v", a.ItemID, err)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+File: cmd/axis/prompt.go
+Description: Confirmation prompts for destructive subcommands. The affected items
+are previewed before the operator is asked to proceed; --yes skips the question,
+and it is required when stdin is not a terminal so scripts never hang.
+*/
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// previewLimit caps the preview lines shown before a prompt.
+const previewLimit = 20
+
+var (
+	promptIn  io.Reader = os.Stdin
+	promptOut io.Writer = os.Stderr
+)
+
+// yesFlag registers --yes and its -y shorthand on fs.
+func yesFlag(fs *flag.FlagSet) *bool {
+	yes := fs.Bool("yes", false, "proceed without asking for confirmation")
+	fs.BoolVar(yes, "y", false, "shorthand for --yes")
+	return yes
+}
+
+// confirm shows the heading and preview, then asks whether to proceed. It
+// returns an error when the answer is no or when there is no terminal to ask.
+func confirm(yes bool, heading string, preview []string) error {
+	fmt.Fprintln(promptOut, heading)
+	for i, line := range preview {
+		if i == previewLimit {
+			fmt.Fprintf(promptOut, "  ... and %d more\n", len(preview)-previewLimit)
+			break
+		}
+		fmt.Fprintf(promptOut, "  %s\n", line)
+	}
+	if yes {
+		return nil
+	}
+	if f, ok := promptIn.(*os.File); ok {
+		if info, err := f.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return fmt.Errorf("not a terminal; pass --yes to proceed")
+		}
+	}
+	fmt.Fprint(promptOut, "Proceed? [y/N] ")
+	answer, err := bufio.NewReader(promptIn).ReadString('\n')
+	if err != nil && answer == "" {
+		return fmt.Errorf("aborted")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return fmt.Errorf("aborted")
+}
@@ -0,0 +1,91 @@
+/*
+File: cmd/axis/registry.go
+Description: `axis registry export` subcommand. Writes every registry item as a
+CSV or Excel spreadsheet, like GET /api/registry/export, reading statuses and
+scheduled deletions from the state store. Items without a recorded status are
+left blank, where the server would show the rules' default.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"axis/internal/config"
+	"axis/internal/export"
+	"axis/internal/workspace"
+)
+
+func runRegistry(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "export" {
+		return fmt.Errorf("usage: axis registry export [--format csv|xlsx] [--out file]")
+	}
+	fs := flag.NewFlagSet("registry export", flag.ContinueOnError)
+	formatFlag := fs.String("format", "csv", "output format: csv or xlsx")
+	out := fs.String("out", "", "destination file (default: stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	format, err := export.ParseTableFormat(*formatFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	ws, err := newWorkspaceService(ctx, cfg, nil, true)
+	if err != nil {
+		return err
+	}
+	st, err := openStateStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	state, err := st.LoadState(ctx)
+	if err != nil {
+		return err
+	}
+
+	var items []workspace.RegistryItem
+	for _, itemType := range ws.Types() {
+		list, err := ws.ListItems(ctx, itemType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", itemType, err)
+		}
+		items = append(items, list...)
+	}
+	for i := range items {
+		item := &items[i]
+		item.Status = state.Statuses[item.ID]
+		if at, ok := state.Deadlines[item.ID]; ok {
+			item.DeleteAt = &at
+		}
+		if item.Type == "keep" || !ws.DriveTagsEnabled() {
+			item.Tags = state.Tags[item.ID]
+		}
+		item.Owner, item.Size = item.Source.Owner, item.Source.Bytes
+	}
+
+	if *out == "" {
+		return export.WriteRegistry(os.Stdout, format, items)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteRegistry(f, format, items); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("Exported %d registry items to %s", len(items), *out)
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+File: cmd/axis/scopes.go
+Description: Startup scope check. Before the Google API clients are built,
+`axis serve` mints a token for the scopes of each enabled feature; a feature
+whose scopes the credentials lack is turned off and reported, so one missing
+Domain-Wide Delegation grant does not fail every token.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"axis/internal/config"
+)
+
+const scopeCheckTimeout = 30 * time.Second
+
+// disabledFeature is a feature turned off by checkScopes.
+type disabledFeature struct {
+	name   string
+	scopes []string
+	err    error
+}
+
+// checkScopes turns off the features whose scopes cannot be obtained and
+// returns them. Turning one off can enable a fallback (the read-only
+// Directory scope instead of the read-write one), which is checked in turn. A
+// required feature that fails stops startup, since then the credentials
+// themselves are most likely at fault.
+func checkScopes(ctx context.Context, cfg *config.Config, readOnly bool) ([]disabledFeature, error) {
+	if err := cfg.RequireWorkspace(); err != nil {
+		return nil, err
+	}
+	var disabled []disabledFeature
+	checked := make(map[string]bool)
+	for {
+		changed := false
+		for _, f := range cfg.Features(readOnly) {
+			if checked[f.Name] {
+				continue
+			}
+			checked[f.Name] = true
+			err := probeScopes(ctx, cfg, f.Scopes)
+			if err == nil {
+				continue
+			}
+			if f.Required || !cfg.Disable(f.Name) {
+				return nil, fmt.Errorf("unable to obtain the %s scopes (%s): %w", f.Name, strings.Join(f.Scopes, " "), err)
+			}
+			slog.Warn("feature disabled: scopes not granted", "feature", f.Name, "scopes", f.Scopes, "error", err)
+			disabled = append(disabled, disabledFeature{name: f.Name, scopes: f.Scopes, err: err})
+			changed = true
+		}
+		if !changed {
+			return disabled, nil
+		}
+	}
+}
+
+// probeScopes mints a token for scopes as the admin. Application Default
+// Credentials of a user carry the scopes they were created with, so for them
+// the check passes whatever was granted.
+func probeScopes(ctx context.Context, cfg *config.Config, scopes []string) error {
+	ctx, cancel := context.WithTimeout(ctx, scopeCheckTimeout)
+	defer cancel()
+	ts, err := newTokenSource(ctx, cfg, cfg.AdminEmail, scopes...)
+	if err != nil {
+		return err
+	}
+	_, err = ts.Token()
+	return err
+}
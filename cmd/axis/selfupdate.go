@@ -0,0 +1,94 @@
+/*
+File: cmd/axis/selfupdate.go
+Description: `axis self-update` for workstations without a package manager.
+Checks AXIS_UPDATE_URL for a newer release, verifies it against
+AXIS_UPDATE_PUBLIC_KEY, previews the change and swaps the binary in place,
+rolling back if the new binary fails its health check. `--rollback` restores
+the previous binary.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"axis/internal/config"
+	"axis/internal/selfupdate"
+)
+
+func runSelfUpdate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "report whether an update is available without installing it")
+	force := fs.Bool("force", false, "install even when the release is not newer")
+	rollback := fs.Bool("rollback", false, "restore the binary replaced by the last update")
+	yes := yesFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if *rollback {
+		if err := confirm(*yes, "Restore the previous binary:", []string{exe + ".old -> " + exe}); err != nil {
+			return err
+		}
+		if err := selfupdate.Rollback(exe); err != nil {
+			return fmt.Errorf("rollback failed: %w", err)
+		}
+		log.Printf("Restored the previous binary; restart the service to use it")
+		return nil
+	}
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+	manifestURL := cfg.UpdateURL
+	if manifestURL == "" {
+		return fmt.Errorf("AXIS_UPDATE_URL must point at the release manifest")
+	}
+	key, err := selfupdate.ParsePublicKey(cfg.UpdatePublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid AXIS_UPDATE_PUBLIC_KEY: %w", err)
+	}
+	u := &selfupdate.Updater{ManifestURL: manifestURL, PublicKey: key}
+
+	m, asset, err := u.Check(ctx)
+	if err != nil {
+		return err
+	}
+	if !selfupdate.Newer(m.Version, version) && !*force {
+		log.Printf("axis %s is up to date (latest release %s)", version, m.Version)
+		return nil
+	}
+	if *check {
+		log.Printf("Update available: %s -> %s", version, m.Version)
+		return nil
+	}
+
+	data, err := u.Download(ctx, asset)
+	if err != nil {
+		return err
+	}
+	preview := []string{fmt.Sprintf("%s: %s -> %s (%d bytes, signature verified)", exe, version, m.Version, len(data))}
+	if m.Notes != "" {
+		preview = append(preview, m.Notes)
+	}
+	if err := confirm(*yes, "Install update:", preview); err != nil {
+		return err
+	}
+	if err := selfupdate.Install(ctx, exe, data, m.Version); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	log.Printf("Updated to %s; restart the service to run it (previous binary kept at %s.old)", m.Version, exe)
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+File: cmd/axis/service.go
+Description: `axis service install|uninstall|run`. Installs Axis as a systemd unit
+on Linux or a Windows service, so it no longer needs a terminal session. `run` is
+what the service manager starts: it moves to the install-time working directory
+(for .env and web/dist), then serves until the manager asks it to stop, which
+goes through the server's graceful shutdown.
+*/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// serviceStopTimeout is how long a service manager should wait for a stop. It
+// covers the server's own shutdown timeout plus the final state flush.
+const serviceStopTimeout = 30 * time.Second
+
+// serviceConfig is what install records and run receives back.
+type serviceConfig struct {
+	Name    string
+	WorkDir string
+	Exe     string
+	User    bool   // systemd user unit instead of a system unit
+	LogFile string // Windows: where stdout, stderr and the log go
+}
+
+func runService(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: axis service install|uninstall|run [--name axis] [--workdir dir]")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("service "+args[0], flag.ContinueOnError)
+	name := fs.String("name", "axis", "service name")
+	workDir := fs.String("workdir", wd, "working directory holding .env and web/dist")
+	user := fs.Bool("user", false, "install a systemd user unit (Linux)")
+	logFile := fs.String("log-file", "", "log file (Windows; default axis.log in the working directory)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg := serviceConfig{Name: *name, User: *user, LogFile: *logFile}
+	if cfg.WorkDir, err = filepath.Abs(*workDir); err != nil {
+		return err
+	}
+	if cfg.LogFile == "" {
+		cfg.LogFile = filepath.Join(cfg.WorkDir, "axis.log")
+	}
+	if cfg.Exe, err = os.Executable(); err != nil {
+		return err
+	}
+	if exe, err := filepath.EvalSymlinks(cfg.Exe); err == nil {
+		cfg.Exe = exe
+	}
+
+	switch args[0] {
+	case "install":
+		return installService(cfg)
+	case "uninstall":
+		return uninstallService(cfg)
+	case "run":
+		if err := os.Chdir(cfg.WorkDir); err != nil {
+			return err
+		}
+		// main loaded .env from the manager's directory; load the real one.
+		// Variables already set (e.g. by the unit) keep precedence.
+		godotenv.Load()
+		return runAsService(ctx, cfg)
+	default:
+		return fmt.Errorf("unknown service command %q (want install, uninstall or run)", args[0])
+	}
+}
@@ -0,0 +1,149 @@
+/*
+File: cmd/axis/service_linux.go
+Description: systemd integration. install writes a unit that runs `axis service
+run` in the chosen working directory, with output going to the journal, and
+enables it; systemd's SIGTERM on stop triggers the graceful shutdown.
+*/
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var unitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description=Axis workspace registry
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{.Exec}} service run --name {{.Name}} --workdir {{.DirArg}}
+WorkingDirectory={{.Dir}}
+Restart=on-failure
+RestartSec=5
+KillSignal=SIGTERM
+TimeoutStopSec={{.StopSeconds}}
+StandardOutput=journal
+StandardError=journal
+SyslogIdentifier={{.Name}}
+
+[Install]
+WantedBy={{.WantedBy}}
+`))
+
+// unitPath is the system unit directory, or the user's for --user.
+func unitPath(cfg serviceConfig) (string, error) {
+	dir := "/etc/systemd/system"
+	if cfg.User {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(config, "systemd", "user")
+	}
+	return filepath.Join(dir, cfg.Name+".service"), nil
+}
+
+// systemdQuote quotes a command-line argument in a unit file when it needs it.
+// WorkingDirectory= takes the path as is.
+func systemdQuote(s string) string {
+	if strings.ContainsAny(s, " \t\"'\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func systemctl(cfg serviceConfig, args ...string) error {
+	if cfg.User {
+		args = append([]string{"--user"}, args...)
+	}
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func installService(cfg serviceConfig) error {
+	path, err := unitPath(cfg)
+	if err != nil {
+		return err
+	}
+	wantedBy := "multi-user.target"
+	if cfg.User {
+		wantedBy = "default.target"
+	}
+	var unit bytes.Buffer
+	err = unitTemplate.Execute(&unit, map[string]any{
+		"Exec":        systemdQuote(cfg.Exe),
+		"Name":        cfg.Name,
+		"Dir":         cfg.WorkDir,
+		"DirArg":      systemdQuote(cfg.WorkDir),
+		"StopSeconds": int(serviceStopTimeout.Seconds()),
+		"WantedBy":    wantedBy,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, unit.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write unit: %w", err)
+	}
+	log.Printf("Wrote %s", path)
+	if err := systemctl(cfg, "daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl(cfg, "enable", "--now", cfg.Name+".service"); err != nil {
+		return err
+	}
+	log.Printf("Service %s enabled and started; follow it with journalctl %s-u %s", cfg.Name, userFlag(cfg), cfg.Name)
+	return nil
+}
+
+func uninstallService(cfg serviceConfig) error {
+	path, err := unitPath(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("service %s is not installed: %w", cfg.Name, err)
+	}
+	if err := systemctl(cfg, "disable", "--now", cfg.Name+".service"); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if err := systemctl(cfg, "daemon-reload"); err != nil {
+		return err
+	}
+	log.Printf("Service %s stopped and removed", cfg.Name)
+	return nil
+}
+
+func userFlag(cfg serviceConfig) string {
+	if cfg.User {
+		return "--user "
+	}
+	return ""
+}
+
+// runAsService serves in the foreground; the journal timestamps each line, so
+// the log's own timestamps are dropped.
+func runAsService(ctx context.Context, cfg serviceConfig) error {
+	if os.Getenv("INVOCATION_ID") != "" {
+		log.SetFlags(0)
+	}
+	return runServe(ctx, nil)
+}
@@ -0,0 +1,26 @@
+//go:build !linux && !windows
+
+/*
+File: cmd/axis/service_other.go
+Description: Service management on platforms without systemd or the Windows
+service manager. run still serves in the foreground for external supervisors.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+func installService(serviceConfig) error {
+	return fmt.Errorf("service install is not supported on %s", runtime.GOOS)
+}
+
+func uninstallService(serviceConfig) error {
+	return fmt.Errorf("service uninstall is not supported on %s", runtime.GOOS)
+}
+
+func runAsService(ctx context.Context, _ serviceConfig) error {
+	return runServe(ctx, nil)
+}
@@ -0,0 +1,158 @@
+/*
+File: cmd/axis/service_windows.go
+Description: Windows service integration. install registers an auto-start
+service with restart-on-failure recovery and an event log source; run answers
+the service control manager, sending output to a log file (services have no
+console) and turning Stop and Shutdown requests into a graceful shutdown.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+func installService(cfg serviceConfig) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(cfg.Name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", cfg.Name)
+	}
+
+	s, err := m.CreateService(cfg.Name, cfg.Exe, mgr.Config{
+		DisplayName: "Axis",
+		Description: "Axis workspace registry",
+		StartType:   mgr.StartAutomatic,
+	}, "service", "run", "--name", cfg.Name, "--workdir", cfg.WorkDir, "--log-file", cfg.LogFile)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+	}, uint32((24 * time.Hour).Seconds())); err != nil {
+		log.Printf("Warning: recovery actions not set: %v", err)
+	}
+	if err := eventlog.InstallAsEventCreate(cfg.Name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		log.Printf("Warning: event log source not registered: %v", err)
+	}
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("service installed but failed to start: %w", err)
+	}
+	log.Printf("Service %s installed and started; logging to %s", cfg.Name, cfg.LogFile)
+	return nil
+}
+
+func uninstallService(cfg serviceConfig) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to the service manager: %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(cfg.Name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed: %w", cfg.Name, err)
+	}
+	defer s.Close()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		deadline := time.Now().Add(serviceStopTimeout)
+		for status.State != svc.Stopped && time.Now().Before(deadline) {
+			time.Sleep(500 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	eventlog.Remove(cfg.Name)
+	log.Printf("Service %s stopped and removed", cfg.Name)
+	return nil
+}
+
+// runAsService serves under the service manager, or in the foreground when
+// started from a console.
+func runAsService(ctx context.Context, cfg serviceConfig) error {
+	inService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !inService {
+		return runServe(ctx, nil)
+	}
+
+	f, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer f.Close()
+	// The server's structured logger writes to os.Stdout when it is created.
+	os.Stdout, os.Stderr = f, f
+	log.SetOutput(f)
+
+	elog, err := eventlog.Open(cfg.Name)
+	if err != nil {
+		return err
+	}
+	defer elog.Close()
+	elog.Info(1, fmt.Sprintf("%s starting; logging to %s", cfg.Name, cfg.LogFile))
+	err = svc.Run(cfg.Name, &windowsService{ctx: ctx, elog: elog})
+	if err != nil {
+		elog.Error(1, fmt.Sprintf("%s failed: %v", cfg.Name, err))
+	}
+	return err
+}
+
+type windowsService struct {
+	ctx  context.Context
+	elog *eventlog.Log
+}
+
+// Execute runs the server and stops it gracefully on Stop or Shutdown.
+func (h *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- runServe(ctx, nil) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: uint32(serviceStopTimeout.Milliseconds())}
+				cancel()
+				if err := <-done; err != nil {
+					h.elog.Warning(1, fmt.Sprintf("shutdown: %v", err))
+				}
+				h.elog.Info(1, "stopped")
+				return false, 0
+			}
+		case err := <-done:
+			if err == nil {
+				err = errors.New("server exited")
+			}
+			log.Printf("Error: %v", err)
+			h.elog.Error(1, err.Error())
+			return true, 1
+		}
+	}
+}
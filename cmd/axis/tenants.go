@@ -0,0 +1,143 @@
+/*
+File: cmd/axis/tenants.go
+Description: Hosted tenants of `axis serve`. Each entry in the tenants file gets
+its own scope check, Workspace clients, operator verification, state store and
+Server, sharing the main tenant's quota, authentication file, rules, playbooks
+and poll schedule. The main server serves them under /api/t/{tenant}.
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"axis/internal/backup"
+	"axis/internal/config"
+	"axis/internal/plan"
+	"axis/internal/playbook"
+	"axis/internal/quota"
+	"axis/internal/rules"
+	"axis/internal/server"
+	"axis/internal/store"
+)
+
+// newTenantServers builds a Server for every tenant in cfg's tenants file.
+// The returned stores must be closed once the servers stop.
+func newTenantServers(ctx context.Context, cfg *config.Config, transport *quota.Transport, logger *slog.Logger, catalog *playbook.Catalog) (map[string]*server.Server, []store.Store, error) {
+	list, err := cfg.LoadTenants(cfg.TenantsFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	tenants := make(map[string]*server.Server, len(list))
+	var stores []store.Store
+	fail := func(err error) (map[string]*server.Server, []store.Store, error) {
+		for _, st := range stores {
+			st.Close()
+		}
+		return nil, nil, err
+	}
+	for _, t := range list {
+		srv, st, err := newTenantServer(ctx, cfg.ForTenant(t), t.Name, transport, logger.With("tenant", t.Name), catalog)
+		if st != nil {
+			stores = append(stores, st)
+		}
+		if err != nil {
+			return fail(fmt.Errorf("tenant %s: %w", t.Name, err))
+		}
+		tenants[t.Name] = srv
+	}
+	return tenants, stores, nil
+}
+
+func newTenantServer(ctx context.Context, cfg *config.Config, name string, transport *quota.Transport, logger *slog.Logger, catalog *playbook.Catalog) (*server.Server, store.Store, error) {
+	disabled, err := checkScopes(ctx, cfg, cfg.AirGap)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws, err := newWorkspaceService(ctx, cfg, transport, cfg.AirGap)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := ws.GetUser(cfg.UserEmail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("verification failed: %w", err)
+	}
+	logger.Info("tenant verification successful", "name", user.Name, "email", user.Email)
+
+	st, err := openStateStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, shownLocation := cfg.StateLocation()
+	about := server.About{
+		Version:        version,
+		StartedAt:      time.Now(),
+		Tenant:         cfg.Tenant(),
+		Admin:          cfg.AdminEmail,
+		ServiceAccount: cfg.ServiceAccountEmail,
+		Scopes:         ws.Scopes(),
+		StateBackend:   cfg.StateBackend + " (" + shownLocation + ")",
+		Listen:         "/api/t/" + name,
+	}
+	for _, f := range disabled {
+		about.Disable(f.name, strings.Join(f.scopes, " ")+" not granted")
+	}
+	about.Safety.ReadOnly = cfg.AirGap
+
+	// Each tenant resolves its own API tokens, so it gets its own
+	// authenticator over the shared auth file.
+	authn, err := newAuthenticator(cfg)
+	if err != nil {
+		return nil, st, err
+	}
+	opts := []server.Option{
+		server.WithLogger(logger),
+		server.WithStore(st),
+		server.WithQuota(transport),
+		server.WithAuth(authn),
+		server.WithBackups(backup.New(ws, cfg.BackupDir)),
+		server.WithInternalDomains(cfg.Domains()),
+	}
+	if cfg.AirGap {
+		key, err := plan.ParsePrivateKey(cfg.PlanSigningKey)
+		if err != nil {
+			return nil, st, fmt.Errorf("AXIS_AIRGAP requires AXIS_PLAN_SIGNING_KEY: %w", err)
+		}
+		opts = append(opts, server.WithAirGap(cfg.PlanDir, key))
+		about.Enable("airgap", "plans in "+cfg.PlanDir)
+	}
+	if catalog != nil {
+		opts = append(opts, server.WithPlaybooks(catalog))
+	}
+	if path := cfg.RulesFile; path != "" {
+		set, err := rules.Load(path)
+		if err != nil {
+			return nil, st, fmt.Errorf("failed to load rules: %w", err)
+		}
+		opts = append(opts, server.WithRules(set))
+	}
+	if cfg.PollInterval > 0 || cfg.PollSchedule != "" {
+		// Every tenant keeps its own due times.
+		sched, err := newPollSchedule(cfg)
+		if err != nil {
+			return nil, st, err
+		}
+		opts = append(opts, server.WithPollSchedule(sched))
+	}
+	if len(cfg.Enrichers) > 0 {
+		opts = append(opts, server.WithEnrichers(cfg.Enrichers...))
+	}
+	if cfg.InactiveDays > 0 {
+		opts = append(opts, server.WithInactiveAfter(time.Duration(cfg.InactiveDays)*24*time.Hour))
+	}
+	if len(cfg.WebSocketOrigins) > 0 {
+		opts = append(opts, server.WithWebSocketOrigins(cfg.WebSocketOrigins))
+	}
+	if cfg.LegacyGETMutations {
+		opts = append(opts, server.WithLegacyGETMutations(true))
+	}
+	return server.NewServer(ws, user, append(opts, server.WithAbout(about))...), st, nil
+}
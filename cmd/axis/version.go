@@ -0,0 +1,16 @@
+/*
+File: cmd/axis/version.go
+Description: Build version. Release builds set it with
+`go build -ldflags "-X main.version=1.5.0" ./cmd/axis`; `axis version` prints it
+and doubles as the self-update health check.
+*/
+package main
+
+import "fmt"
+
+var version = "dev"
+
+func runVersion() error {
+	fmt.Printf("axis %s\n", version)
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+File: internal/analytics/stale.go
+Description: Staleness aggregates over the registry inventory: per item type,
+how many items went unmodified for 30, 90 and 180 days, and the largest Drive
+files. It works on an item listing alone, so it is as current as the registry
+cache it is given.
+*/
+package analytics
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// StaleDays are the ages, in days, items are counted against.
+var StaleDays = []int{30, 90, 180}
+
+// DefaultTop is how many of the largest files a report lists by default.
+const DefaultTop = 10
+
+// StaleReport aggregates the inventory's staleness.
+type StaleReport struct {
+	Generated time.Time             `json:"generated"`
+	Days      []int                 `json:"days"`
+	Total     TypeCounts            `json:"total"`
+	ByType    map[string]TypeCounts `json:"by_type"`
+	Largest   []LargeFile           `json:"largest"`
+}
+
+// TypeCounts counts the items of one type. Stale maps an age such as "90d" to
+// the items not modified within it, so "30d" includes "90d". Items without a
+// modification time are counted as Unknown and not as stale; trashed ones are
+// left out.
+type TypeCounts struct {
+	Items   int            `json:"items"`
+	Unknown int            `json:"unknown"`
+	Stale   map[string]int `json:"stale"`
+}
+
+// LargeFile is one of the largest Drive files by storage used.
+type LargeFile struct {
+	ID       string     `json:"id"`
+	Type     string     `json:"type"`
+	Title    string     `json:"title"`
+	Owner    string     `json:"owner,omitempty"`
+	Bytes    int64      `json:"bytes"`
+	Modified *time.Time `json:"modified,omitempty"`
+	URL      string     `json:"url"`
+}
+
+// Stale computes the report for items as of now, listing the top largest
+// Drive files.
+func Stale(items []workspace.RegistryItem, now time.Time, top int) StaleReport {
+	rep := StaleReport{
+		Generated: now,
+		Days:      StaleDays,
+		Total:     newTypeCounts(),
+		ByType:    make(map[string]TypeCounts),
+		Largest:   []LargeFile{},
+	}
+	var files []workspace.RegistryItem
+	for _, item := range items {
+		if item.Trashed {
+			continue
+		}
+		counts, ok := rep.ByType[item.Type]
+		if !ok {
+			counts = newTypeCounts()
+		}
+		counts.add(item, now)
+		rep.ByType[item.Type] = counts
+		rep.Total.add(item, now)
+		// A note's size is its text length, not storage.
+		if item.Type != "keep" && item.Source.Bytes > 0 {
+			files = append(files, item)
+		}
+	}
+
+	slices.SortStableFunc(files, func(a, b workspace.RegistryItem) int { return cmp.Compare(b.Source.Bytes, a.Source.Bytes) })
+	for _, f := range files[:min(top, len(files))] {
+		rep.Largest = append(rep.Largest, LargeFile{
+			ID:       f.ID,
+			Type:     f.Type,
+			Title:    f.Title,
+			Owner:    f.Source.Owner,
+			Bytes:    f.Source.Bytes,
+			Modified: f.Modified,
+			URL:      workspace.ItemURL(f),
+		})
+	}
+	return rep
+}
+
+func newTypeCounts() TypeCounts {
+	c := TypeCounts{Stale: make(map[string]int, len(StaleDays))}
+	for _, d := range StaleDays {
+		c.Stale[dayKey(d)] = 0
+	}
+	return c
+}
+
+func (c *TypeCounts) add(item workspace.RegistryItem, now time.Time) {
+	c.Items++
+	if item.Modified == nil {
+		c.Unknown++
+		return
+	}
+	age := now.Sub(*item.Modified)
+	for _, d := range StaleDays {
+		if age >= time.Duration(d)*24*time.Hour {
+			c.Stale[dayKey(d)]++
+		}
+	}
+}
+
+func dayKey(days int) string { return strconv.Itoa(days) + "d" }
@@ -0,0 +1,272 @@
+/*
+File: internal/auth/auth.go
+Description: Authentication and role-based access for the HTTP API. Requests are
+identified by API key, minted token, or a signed session cookie issued after
+Google OIDC login; each principal carries a role (viewer, operator, admin). With
+nothing configured every request is rejected.
+*/
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Role orders what a principal may do; each role includes the ones below it.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleViewer
+	RoleOperator
+	RoleAdmin
+)
+
+// ParseRole converts a configured role name.
+func ParseRole(raw string) (Role, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "viewer":
+		return RoleViewer, nil
+	case "operator":
+		return RoleOperator, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("unknown role %q (want viewer, operator or admin)", raw)
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// MarshalJSON encodes the role by name.
+func (r Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Name   string `json:"name"`
+	Role   Role   `json:"role"`
+	Method string `json:"method"`
+	// Scopes and Campaign limit a minted token (see tokens.go).
+	Scopes   []string `json:"scopes,omitempty"`
+	Campaign string   `json:"campaign,omitempty"`
+}
+
+// ErrUnauthenticated means the request carried no valid credentials.
+var ErrUnauthenticated = errors.New("authentication required")
+
+// APIKey grants a role to requests presenting the key. The key itself is read
+// from the environment variable named by KeyEnv so it never lives in the file.
+type APIKey struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	KeyEnv string `json:"key_env"`
+}
+
+// User assigns a role to an OIDC-authenticated email address.
+type User struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// Config is the on-disk layout of the auth file.
+type Config struct {
+	APIKeys          []APIKey    `json:"api_keys"`
+	OIDC             *OIDCConfig `json:"oidc,omitempty"`
+	Users            []User      `json:"users"`
+	SessionSecretEnv string      `json:"session_secret_env"`
+}
+
+type apiKey struct {
+	name   string
+	role   Role
+	digest [sha256.Size]byte
+}
+
+// Authenticator resolves request credentials to principals.
+type Authenticator struct {
+	keys     []apiKey
+	users    map[string]Role
+	oidc     *OIDC
+	sessions *sessionCodec
+	tokens   TokenResolver
+	disabled bool
+}
+
+// Load reads and validates an auth file.
+func Load(path string) (*Authenticator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read auth file %s: %w", path, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse auth file %s: %w", path, err)
+	}
+	return New(cfg)
+}
+
+// New validates the configuration and resolves secrets from the environment.
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{users: make(map[string]Role, len(cfg.Users))}
+	for _, k := range cfg.APIKeys {
+		role, err := ParseRole(k.Role)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", k.Name, err)
+		}
+		secret := os.Getenv(k.KeyEnv)
+		if k.Name == "" || k.KeyEnv == "" || secret == "" {
+			return nil, fmt.Errorf("api key %q has no key (set %s)", k.Name, k.KeyEnv)
+		}
+		a.keys = append(a.keys, apiKey{name: k.Name, role: role, digest: sha256.Sum256([]byte(secret))})
+	}
+	for _, u := range cfg.Users {
+		role, err := ParseRole(u.Role)
+		if err != nil {
+			return nil, fmt.Errorf("user %q: %w", u.Email, err)
+		}
+		a.users[strings.ToLower(u.Email)] = role
+	}
+	if cfg.OIDC != nil {
+		secret := os.Getenv(cfg.SessionSecretEnv)
+		if cfg.SessionSecretEnv == "" || len(secret) < 32 {
+			return nil, fmt.Errorf("oidc login needs a session secret of at least 32 bytes (set %s)", cfg.SessionSecretEnv)
+		}
+		oidc, err := newOIDC(*cfg.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		a.oidc = oidc
+		a.sessions = newSessionCodec([]byte(secret))
+	}
+	return a, nil
+}
+
+// Disabled returns an authenticator that treats every request as an admin.
+// It exists for local development and must be chosen explicitly.
+func Disabled() *Authenticator {
+	return &Authenticator{disabled: true}
+}
+
+// IsDisabled reports whether authentication is bypassed.
+func (a *Authenticator) IsDisabled() bool {
+	return a != nil && a.disabled
+}
+
+// Methods lists the accepted credentials: "api_key", "oidc" and "token", or just
+// "disabled" when authentication is bypassed. Empty means nothing is accepted.
+func (a *Authenticator) Methods() []string {
+	methods := []string{}
+	switch {
+	case a == nil:
+	case a.disabled:
+		methods = append(methods, MethodDisabled)
+	default:
+		if len(a.keys) > 0 {
+			methods = append(methods, MethodAPIKey)
+		}
+		if a.oidc != nil {
+			methods = append(methods, MethodOIDC)
+		}
+		// Tokens are minted by an admin who signed in some other way.
+		if a.tokens != nil && len(methods) > 0 {
+			methods = append(methods, MethodToken)
+		}
+	}
+	return methods
+}
+
+// OIDC returns the login flow, or nil when OIDC is not configured.
+func (a *Authenticator) OIDC() *OIDC {
+	if a == nil {
+		return nil
+	}
+	return a.oidc
+}
+
+// Authenticate identifies the caller. API keys and minted tokens are accepted
+// as a bearer token or in X-Axis-Key; browsers use the session cookie set by
+// the OIDC callback.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if a == nil {
+		return nil, ErrUnauthenticated
+	}
+	if a.disabled {
+		return &Principal{Name: "anonymous", Role: RoleAdmin, Method: MethodDisabled}, nil
+	}
+	if key := presentedKey(r); key != "" {
+		if strings.HasPrefix(key, TokenPrefix) {
+			return a.resolveToken(key)
+		}
+		digest := sha256.Sum256([]byte(key))
+		for _, k := range a.keys {
+			if subtle.ConstantTimeCompare(digest[:], k.digest[:]) == 1 {
+				return &Principal{Name: "key:" + k.name, Role: k.role, Method: MethodAPIKey}, nil
+			}
+		}
+		return nil, ErrUnauthenticated
+	}
+	if a.sessions != nil {
+		if c, err := r.Cookie(sessionCookie); err == nil {
+			if email, ok := a.sessions.decode(c.Value); ok {
+				if role := a.roleFor(email); role != RoleNone {
+					return &Principal{Name: "user:" + email, Role: role, Method: MethodOIDC}, nil
+				}
+			}
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+// roleFor resolves an email's role: an explicit user entry wins, then the OIDC
+// default role for addresses in the hosted domain. Roles are looked up on every request so
+// configuration changes apply to existing sessions.
+func (a *Authenticator) roleFor(email string) Role {
+	email = strings.ToLower(email)
+	if role, ok := a.users[email]; ok {
+		return role
+	}
+	if a.oidc != nil && a.oidc.hostedDomain != "" && strings.HasSuffix(email, "@"+a.oidc.hostedDomain) {
+		return a.oidc.defaultRole
+	}
+	return RoleNone
+}
+
+func presentedKey(r *http.Request) string {
+	if v, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(v)
+	}
+	return strings.TrimSpace(r.Header.Get("X-Axis-Key"))
+}
+
+type principalKey struct{}
+
+// WithPrincipal attaches the caller to ctx.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller attached by WithPrincipal.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,155 @@
+/*
+File: internal/auth/oidc.go
+Description: Google OIDC login. /auth/login redirects to Google, /auth/callback
+validates the ID token (audience, verified email, hosted domain) and issues the
+session cookie, /auth/logout clears it.
+*/
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/idtoken"
+)
+
+const stateCookie = "axis_oauth_state"
+
+// OIDCConfig configures Google sign-in. DefaultRole applies to verified users of
+// HostedDomain without an explicit user entry; leave it empty to admit only
+// listed users.
+type OIDCConfig struct {
+	ClientID        string `json:"client_id"`
+	ClientSecretEnv string `json:"client_secret_env"`
+	RedirectURL     string `json:"redirect_url"`
+	HostedDomain    string `json:"hosted_domain,omitempty"`
+	DefaultRole     string `json:"default_role,omitempty"`
+}
+
+// OIDC is a configured Google login flow.
+type OIDC struct {
+	oauth        oauth2.Config
+	hostedDomain string
+	defaultRole  Role
+	secure       bool
+}
+
+func newOIDC(cfg OIDCConfig) (*OIDC, error) {
+	secret := os.Getenv(cfg.ClientSecretEnv)
+	if cfg.ClientID == "" || cfg.RedirectURL == "" || secret == "" {
+		return nil, fmt.Errorf("oidc needs client_id, redirect_url and a client secret (set %s)", cfg.ClientSecretEnv)
+	}
+	o := &OIDC{
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: secret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     google.Endpoint,
+			Scopes:       []string{"openid", "email"},
+		},
+		hostedDomain: strings.ToLower(cfg.HostedDomain),
+		secure:       strings.HasPrefix(cfg.RedirectURL, "https://"),
+	}
+	if cfg.DefaultRole != "" {
+		role, err := ParseRole(cfg.DefaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("oidc default_role: %w", err)
+		}
+		if o.hostedDomain == "" {
+			return nil, fmt.Errorf("oidc default_role requires hosted_domain")
+		}
+		o.defaultRole = role
+	}
+	return o, nil
+}
+
+// HandleLogin starts the Google sign-in redirect.
+func (a *Authenticator) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	o := a.OIDC()
+	if o == nil {
+		http.Error(w, "oidc login is not configured", http.StatusNotFound)
+		return
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	state := hex.EncodeToString(buf)
+	http.SetCookie(w, &http.Cookie{
+		Name: stateCookie, Value: state, Path: "/auth", MaxAge: 600,
+		HttpOnly: true, Secure: o.secure, SameSite: http.SameSiteLaxMode,
+	})
+	var opts []oauth2.AuthCodeOption
+	if o.hostedDomain != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("hd", o.hostedDomain))
+	}
+	http.Redirect(w, r, o.oauth.AuthCodeURL(state, opts...), http.StatusFound)
+}
+
+// HandleCallback completes sign-in and sets the session cookie.
+func (a *Authenticator) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	o := a.OIDC()
+	if o == nil {
+		http.Error(w, "oidc login is not configured", http.StatusNotFound)
+		return
+	}
+	c, err := r.Cookie(stateCookie)
+	if err != nil || c.Value == "" || c.Value != r.URL.Query().Get("state") {
+		http.Error(w, "invalid login state", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/auth", MaxAge: -1})
+
+	tok, err := o.oauth.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, "login failed", http.StatusUnauthorized)
+		return
+	}
+	rawID, _ := tok.Extra("id_token").(string)
+	payload, err := idtoken.Validate(r.Context(), rawID, o.oauth.ClientID)
+	if err != nil {
+		http.Error(w, "invalid id token", http.StatusUnauthorized)
+		return
+	}
+	email, _ := payload.Claims["email"].(string)
+	verified, _ := payload.Claims["email_verified"].(bool)
+	hd, _ := payload.Claims["hd"].(string)
+	if email == "" || !verified {
+		http.Error(w, "email not verified", http.StatusForbidden)
+		return
+	}
+	_, listed := a.users[strings.ToLower(email)]
+	if !listed && (o.hostedDomain == "" || strings.ToLower(hd) != o.hostedDomain) {
+		http.Error(w, "account not allowed", http.StatusForbidden)
+		return
+	}
+	if a.roleFor(email) == RoleNone {
+		http.Error(w, "account not allowed", http.StatusForbidden)
+		return
+	}
+
+	value, err := a.sessions.encode(strings.ToLower(email), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Value: value, Path: "/", MaxAge: int(sessionTTL.Seconds()),
+		HttpOnly: true, Secure: o.secure, SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// HandleLogout clears the session cookie.
+func (a *Authenticator) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
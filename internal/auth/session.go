@@ -0,0 +1,71 @@
+/*
+File: internal/auth/session.go
+Description: Signed session cookies. A session carries only the email and an
+expiry, HMAC-signed with the configured secret; roles are resolved per request.
+*/
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	sessionCookie = "axis_session"
+	sessionTTL    = 12 * time.Hour
+)
+
+type sessionClaims struct {
+	Email   string `json:"email"`
+	Expires int64  `json:"exp"`
+}
+
+type sessionCodec struct {
+	secret []byte
+}
+
+func newSessionCodec(secret []byte) *sessionCodec {
+	return &sessionCodec{secret: secret}
+}
+
+func (c *sessionCodec) encode(email string, now time.Time) (string, error) {
+	payload, err := json.Marshal(sessionClaims{Email: email, Expires: now.Add(sessionTTL).Unix()})
+	if err != nil {
+		return "", err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + base64.RawURLEncoding.EncodeToString(c.sign(body)), nil
+}
+
+func (c *sessionCodec) decode(value string) (string, bool) {
+	body, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, c.sign(body)) {
+		return "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return "", false
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Email == "" {
+		return "", false
+	}
+	if time.Now().Unix() > claims.Expires {
+		return "", false
+	}
+	return claims.Email, true
+}
+
+func (c *sessionCodec) sign(body string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
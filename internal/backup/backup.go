@@ -0,0 +1,130 @@
+/*
+File: internal/backup/backup.go
+Description: Backups taken before Axis changes a Doc or Sheet. Drive cannot pin
+revisions of Docs editors files, so a backup notes the revision current before
+the change (restorable from version history while Drive keeps it) and saves
+the file exported as .docx or .xlsx under the backup directory, so every
+Axis-initiated content change can be undone.
+*/
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source reads what a backup needs; workspace.Service implements it.
+type Source interface {
+	LatestRevision(ctx context.Context, fileID string) (string, error)
+	ExportFile(ctx context.Context, fileID, mimeType string, w io.Writer) (int64, error)
+}
+
+type format struct{ mime, ext string }
+
+var formats = map[string]format{
+	"doc":   {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"},
+	"sheet": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ".xlsx"},
+}
+
+// Supports reports whether items of itemType are backed up; Keep notes are
+// not, since the Keep API has no revisions or export.
+func Supports(itemType string) bool {
+	_, ok := formats[itemType]
+	return ok
+}
+
+// Backup is one file's state before a change.
+type Backup struct {
+	ItemID   string    `json:"item_id"`
+	Revision string    `json:"revision"`
+	Path     string    `json:"path,omitempty"`
+	Bytes    int64     `json:"bytes,omitempty"`
+	SHA256   string    `json:"sha256,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Ref is the backup as one audit field: the export path, then "#" and the
+// Drive revision.
+func (b Backup) Ref() string {
+	if b.Revision == "" && b.Path == "" {
+		return ""
+	}
+	return b.Path + "#" + b.Revision
+}
+
+// Backups takes backups from a source.
+type Backups struct {
+	src Source
+	dir string
+}
+
+// New backs up from src into dir. An empty dir only notes revisions.
+func New(src Source, dir string) *Backups {
+	return &Backups{src: src, dir: dir}
+}
+
+// Dir is the backup directory; empty when only revisions are noted.
+func (b *Backups) Dir() string {
+	return b.dir
+}
+
+// Take backs up itemID. It fails unless both the revision and, with a
+// directory, the export were captured, so the caller can refuse the change.
+func (b *Backups) Take(ctx context.Context, itemID, itemType string) (Backup, error) {
+	f, ok := formats[itemType]
+	if !ok {
+		return Backup{}, fmt.Errorf("%s items are not backed up", itemType)
+	}
+	rev, err := b.src.LatestRevision(ctx, itemID)
+	if err != nil {
+		return Backup{}, fmt.Errorf("unable to back up %s: %w", itemID, err)
+	}
+	bk := Backup{ItemID: itemID, Revision: rev, Time: time.Now().UTC()}
+	if b.dir == "" {
+		return bk, nil
+	}
+	if strings.ContainsAny(itemID, `/\`) || itemID == "." || itemID == ".." {
+		return Backup{}, fmt.Errorf("unable to back up %q: not a Drive file ID", itemID)
+	}
+	if bk.Path, bk.Bytes, bk.SHA256, err = b.export(ctx, itemID, f, bk.Time); err != nil {
+		return Backup{}, fmt.Errorf("unable to back up %s: %w", itemID, err)
+	}
+	return bk, nil
+}
+
+// export writes <dir>/<item ID>/<time><ext> atomically.
+func (b *Backups) export(ctx context.Context, itemID string, f format, at time.Time) (string, int64, string, error) {
+	dir := filepath.Join(b.dir, itemID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", 0, "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return "", 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+	sum := sha256.New()
+	n, err := b.src.ExportFile(ctx, itemID, f.mime, io.MultiWriter(tmp, sum))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n == 0 {
+		err = errors.New("export is empty")
+	}
+	if err != nil {
+		return "", 0, "", err
+	}
+	path := filepath.Join(dir, at.Format("20060102T150405.000Z")+f.ext)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, "", err
+	}
+	return path, n, hex.EncodeToString(sum.Sum(nil)), nil
+}
@@ -0,0 +1,378 @@
+/*
+File: internal/broker/broker.go
+Description: In-process event bus for client broadcasts. SSE, WebSocket and any
+future consumer subscribe to the same Broker; publishing never blocks, and a
+subscriber that stops draining its buffer is dropped rather than stalling
+others. The subscriber list is copy-on-write, so fan-out takes no broker lock
+and connects and disconnects never wait on a publish.
+
+Every published event gets the next ID and is kept in a ring of recent events,
+so a client that reconnects can be sent what it missed. Concurrent publishers
+fan out independently; each subscription holds back an event that overtook an
+earlier one, so it still delivers in ID order. Ticks and countdowns are
+superseded by the next one within seconds, so they are neither numbered nor
+kept.
+*/
+package broker
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Event types carried on the bus.
+const (
+	TypeRegistry     = "registry"
+	TypeItemAdded    = "item-added"   // registry items new since the last broadcast
+	TypeItemUpdated  = "item-updated" // registry items changed since the last broadcast
+	TypeItemRemoved  = "item-removed" // IDs of registry items gone since the last broadcast
+	TypeTick         = "tick"
+	TypeStatus       = "status"
+	TypeMode         = "mode" // operating mode, on change and on connect
+	TypeSimulated    = "simulated"
+	TypeCountdown    = "countdown"     // time left on each scheduled deletion
+	TypeSources      = "sources"       // registry source health
+	TypeAuthDegraded = "auth-degraded" // a token source stopped refreshing
+	TypeAuthRestored = "auth-restored"
+	TypeJob          = "job" // a background job's state and progress
+)
+
+const (
+	// DefaultBuffer is the per-subscriber queue length.
+	DefaultBuffer = 10
+	// DefaultMaxDrops is how many consecutive events a subscriber may miss
+	// before it is disconnected.
+	DefaultMaxDrops = 50
+	// DefaultHistory is how many recent events are kept for replay.
+	DefaultHistory = 256
+)
+
+// transient lists the event types that are not retained for replay.
+var transient = map[string]bool{TypeTick: true, TypeCountdown: true}
+
+// Event is one typed broadcast with a JSON payload. ID is assigned on
+// Publish, increasing from 1; transient events and events delivered with Send
+// have none.
+type Event struct {
+	ID   uint64
+	Type string
+	Data json.RawMessage
+}
+
+// Subscription is a consumer's view of the bus. C is closed once the
+// subscriber is unsubscribed or disconnected for falling behind.
+type Subscription struct {
+	C <-chan Event
+
+	ch      chan Event
+	types   map[string]bool
+	mu      sync.Mutex
+	closed  bool
+	misses  int
+	dropped atomic.Uint64
+
+	// next is the ID of the next numbered event to deliver; held keeps the
+	// numbered events that arrived before it, at their ID modulo its length.
+	next uint64
+	held []Event
+}
+
+// Dropped reports how many events the subscriber has missed in total.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// offer queues e without blocking, after the numbered events before it. It
+// reports whether e was queued or held back, and whether the subscriber is
+// still alive afterwards.
+func (s *Subscription) offer(e Event, maxDrops int) (queued, alive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false, false
+	}
+	if e.ID == 0 {
+		return s.queueLocked(e, maxDrops)
+	}
+	if e.ID < s.next {
+		return false, true // published before the subscriber joined
+	}
+	if e.ID != s.next {
+		// A publisher holding an earlier ID has yet to reach this subscriber.
+		s.holdLocked(e)
+		return true, true
+	}
+	queued, alive = s.queueLocked(e, maxDrops)
+	for s.next++; alive && len(s.held) > 0; s.next++ {
+		slot := &s.held[s.next%uint64(len(s.held))]
+		if slot.ID != s.next {
+			break
+		}
+		h := *slot
+		*slot = Event{}
+		_, alive = s.queueLocked(h, maxDrops)
+	}
+	return queued, alive
+}
+
+// holdLocked keeps e until the events before it are delivered, growing the
+// ring when e is further ahead than it reaches.
+func (s *Subscription) holdLocked(e Event) {
+	if ahead := e.ID - s.next; ahead >= uint64(len(s.held)) {
+		size := max(8, len(s.held))
+		for uint64(size) <= ahead {
+			size *= 2
+		}
+		ring := make([]Event, size)
+		for _, h := range s.held {
+			if h.ID != 0 {
+				ring[h.ID%uint64(size)] = h
+			}
+		}
+		s.held = ring
+	}
+	s.held[e.ID%uint64(len(s.held))] = e
+}
+
+// send queues e without blocking, outside the ID order.
+func (s *Subscription) send(e Event, maxDrops int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	queued, _ := s.queueLocked(e, maxDrops)
+	return queued
+}
+
+// queueLocked puts e on the channel unless it is full or filtered out, counting
+// a miss when it is full.
+func (s *Subscription) queueLocked(e Event, maxDrops int) (queued, alive bool) {
+	if s.types != nil && !s.types[e.Type] {
+		return false, true
+	}
+	select {
+	case s.ch <- e:
+		s.misses = 0
+		return true, true
+	default:
+	}
+	s.dropped.Add(1)
+	s.misses++
+	if maxDrops > 0 && s.misses >= maxDrops {
+		s.closeLocked()
+		return false, false
+	}
+	return false, true
+}
+
+func (s *Subscription) closeLocked() {
+	if !s.closed {
+		s.closed = true
+		s.held = nil
+		close(s.ch)
+	}
+}
+
+// Broker fans published events out to subscribers.
+type Broker struct {
+	buffer   int
+	maxDrops int
+
+	// subs is replaced, never modified, under mu. Subscribers are added
+	// under histMu too, so each one's first ID is known.
+	subs atomic.Pointer[[]*Subscription]
+
+	mu     sync.Mutex
+	closed bool
+
+	// history is a ring of the last published events; seq is the last ID
+	// assigned. Both are guarded by histMu, under which Publish also takes
+	// the subscriber list it fans out to.
+	history []Event
+	seq     uint64
+	histMu  sync.Mutex
+
+	evicted atomic.Uint64
+}
+
+// New creates a broker with the given per-subscriber buffer and consecutive
+// drop limit; zero values select the defaults, a negative maxDrops never evicts.
+func New(buffer, maxDrops int) *Broker {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	if maxDrops == 0 {
+		maxDrops = DefaultMaxDrops
+	}
+	b := &Broker{buffer: buffer, maxDrops: maxDrops, history: make([]Event, DefaultHistory)}
+	b.subs.Store(&[]*Subscription{})
+	return b
+}
+
+// subscribers returns the current subscriber list, which must not be modified.
+func (b *Broker) subscribers() []*Subscription {
+	return *b.subs.Load()
+}
+
+// removeLocked publishes a copy of the list without the given subscribers and
+// reports how many were present.
+func (b *Broker) removeLocked(gone ...*Subscription) int {
+	cur := b.subscribers()
+	next := make([]*Subscription, 0, len(cur))
+	for _, sub := range cur {
+		if !slices.Contains(gone, sub) {
+			next = append(next, sub)
+		}
+	}
+	b.subs.Store(&next)
+	return len(cur) - len(next)
+}
+
+// Subscribe registers a consumer. With types given, only those events are delivered.
+func (b *Broker) Subscribe(types ...string) *Subscription {
+	sub, _, _ := b.SubscribeAfter(0, types...)
+	return sub
+}
+
+// SubscribeAfter registers a consumer like Subscribe and also returns the
+// retained events published after the one with ID after, oldest first.
+// complete is false when some of those events are no longer retained, or after
+// is not an ID this broker assigned (e.g. one from before a restart). The
+// channel carries the events after the backlog, with none repeated.
+func (b *Broker) SubscribeAfter(after uint64, types ...string) (sub *Subscription, backlog []Event, complete bool) {
+	ch := make(chan Event, b.buffer)
+	sub = &Subscription{C: ch, ch: ch}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.histMu.Lock()
+	defer b.histMu.Unlock()
+	sub.next = b.seq + 1
+	if b.closed {
+		sub.closeLocked()
+	} else {
+		next := append(slices.Clip(b.subscribers()), sub)
+		b.subs.Store(&next)
+	}
+	if after == 0 {
+		return sub, nil, true
+	}
+	if after > b.seq {
+		return sub, nil, false
+	}
+	size := uint64(len(b.history))
+	first := after + 1
+	complete = true
+	if b.seq >= size && first <= b.seq-size {
+		first, complete = b.seq-size+1, false
+	}
+	for id := first; id <= b.seq; id++ {
+		if e := b.history[id%size]; sub.types == nil || sub.types[e.Type] {
+			backlog = append(backlog, e)
+		}
+	}
+	return sub, backlog, complete
+}
+
+// Unsubscribe removes a consumer and closes its channel. It is safe to call
+// more than once and after the subscriber was evicted.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	b.removeLocked(sub)
+	b.mu.Unlock()
+
+	sub.mu.Lock()
+	sub.closeLocked()
+	sub.mu.Unlock()
+}
+
+// Close disconnects every subscriber; later subscriptions start closed. It is
+// used on shutdown so streaming handlers return promptly.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subscribers()
+	b.subs.Store(&[]*Subscription{})
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.mu.Lock()
+		sub.closeLocked()
+		sub.mu.Unlock()
+	}
+}
+
+// Closed reports whether Close has been called, letting a subscriber tell
+// shutdown apart from eviction.
+func (b *Broker) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// Publish numbers e and retains it for replay, unless it is transient, and
+// delivers it to every subscriber without blocking. It returns the number of
+// subscribers that received it or hold it until earlier events arrive.
+func (b *Broker) Publish(e Event) int {
+	e.ID = 0
+	var subs []*Subscription
+	if transient[e.Type] {
+		subs = b.subscribers()
+	} else {
+		b.histMu.Lock()
+		b.seq++
+		e.ID = b.seq
+		b.history[e.ID%uint64(len(b.history))] = e
+		// Every subscriber added after this ID is in the list, and none
+		// added before it, so each sees an unbroken run of IDs.
+		subs = b.subscribers()
+		b.histMu.Unlock()
+	}
+
+	delivered := 0
+	var evicted []*Subscription
+	for _, sub := range subs {
+		queued, alive := sub.offer(e, b.maxDrops)
+		if queued {
+			delivered++
+		}
+		if !alive {
+			evicted = append(evicted, sub)
+		}
+	}
+
+	if len(evicted) > 0 {
+		b.mu.Lock()
+		b.evicted.Add(uint64(b.removeLocked(evicted...)))
+		b.mu.Unlock()
+	}
+	return delivered
+}
+
+// PublishJSON marshals v and publishes it as an event of type t.
+func (b *Broker) PublishJSON(t string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b.Publish(Event{Type: t, Data: data})
+	return nil
+}
+
+// Send delivers e to a single subscriber, e.g. an initial snapshot on connect.
+func (b *Broker) Send(sub *Subscription, e Event) bool {
+	return sub.send(e, b.maxDrops)
+}
+
+// Stats reports current subscribers and the number evicted for falling behind.
+func (b *Broker) Stats() (subscribers int, evicted uint64) {
+	return len(b.subscribers()), b.evicted.Load()
+}
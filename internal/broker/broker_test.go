@@ -0,0 +1,429 @@
+/*
+File: internal/broker/broker_test.go
+Description: Slow-client handling: eviction after repeated misses, publishing
+that never blocks on a full buffer, idempotent unsubscribe and close, delivery
+in ID order under concurrent publishers, and Last-Event-ID replay from the
+history ring, including after it wraps. The benchmarks compare the
+copy-on-write fan-out with a map behind a read-write lock under concurrent
+publishers and connect churn.
+*/
+package broker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// drain reads what is queued on sub and reports whether its channel closed.
+func drain(sub *Subscription) (n int, closed bool) {
+	for {
+		select {
+		case _, ok := <-sub.C:
+			if !ok {
+				return n, true
+			}
+			n++
+		default:
+			return n, false
+		}
+	}
+}
+
+func TestEviction(t *testing.T) {
+	tests := []struct {
+		name        string
+		buffer      int
+		maxDrops    int
+		publishes   int
+		wantQueued  int
+		wantEvicted bool
+	}{
+		{"within buffer", 4, 3, 4, 4, false},
+		{"one short of the limit", 1, 3, 3, 1, false},
+		{"at the limit", 1, 3, 4, 1, true},
+		{"never evicts", 1, -1, 100, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.buffer, tt.maxDrops)
+			sub := b.Subscribe()
+			for i := 0; i < tt.publishes; i++ {
+				b.Publish(Event{Type: TypeStatus})
+			}
+			n, closed := drain(sub)
+			if n != tt.wantQueued || closed != tt.wantEvicted {
+				t.Fatalf("queued %d closed %t, want %d %t", n, closed, tt.wantQueued, tt.wantEvicted)
+			}
+			subs, evicted := b.Stats()
+			if wantEvicted := tt.wantEvicted; (evicted == 1) != wantEvicted || (subs == 0) != wantEvicted {
+				t.Fatalf("stats %d subscribers %d evicted, want evicted %t", subs, evicted, wantEvicted)
+			}
+			if got, want := sub.Dropped(), uint64(tt.publishes-tt.wantQueued); got != want {
+				t.Fatalf("dropped %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestDrainingResetsMisses(t *testing.T) {
+	b := New(1, 2)
+	sub := b.Subscribe()
+	for i := 0; i < 10; i++ {
+		b.Publish(Event{Type: TypeStatus}) // queued
+		b.Publish(Event{Type: TypeStatus}) // missed
+		if n, closed := drain(sub); n != 1 || closed {
+			t.Fatalf("round %d: queued %d closed %t", i, n, closed)
+		}
+	}
+}
+
+func TestPublishNeverBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxDrops int
+	}{
+		{"evicting", DefaultMaxDrops},
+		{"never evicting", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(1, tt.maxDrops)
+			b.Subscribe() // never read
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < 1000; i++ {
+					b.Publish(Event{Type: TypeStatus})
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Publish blocked on a full subscriber")
+			}
+		})
+	}
+}
+
+func TestTeardownTwice(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(b *Broker, sub *Subscription)
+	}{
+		{"unsubscribe twice", func(b *Broker, sub *Subscription) {
+			b.Unsubscribe(sub)
+			b.Unsubscribe(sub)
+		}},
+		{"close twice", func(b *Broker, sub *Subscription) {
+			b.Close()
+			b.Close()
+		}},
+		{"close then unsubscribe", func(b *Broker, sub *Subscription) {
+			b.Close()
+			b.Unsubscribe(sub)
+		}},
+		{"unsubscribe then close", func(b *Broker, sub *Subscription) {
+			b.Unsubscribe(sub)
+			b.Close()
+		}},
+		{"unsubscribe after eviction", func(b *Broker, sub *Subscription) {
+			for i := 0; i < 3; i++ {
+				b.Publish(Event{Type: TypeStatus})
+			}
+			b.Unsubscribe(sub)
+			b.Unsubscribe(sub)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(1, 2)
+			sub := b.Subscribe()
+			tt.run(b, sub)
+			if _, closed := drain(sub); !closed {
+				t.Fatal("subscription channel not closed")
+			}
+			if subs, _ := b.Stats(); subs != 0 {
+				t.Fatalf("%d subscribers left", subs)
+			}
+			if n := b.Publish(Event{Type: TypeStatus}); n != 0 {
+				t.Fatalf("published to %d subscribers after teardown", n)
+			}
+		})
+	}
+}
+
+func TestSubscribeAfterClose(t *testing.T) {
+	b := New(0, 0)
+	b.Close()
+	if _, closed := drain(b.Subscribe()); !closed {
+		t.Fatal("subscription after Close not closed")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	ids := func(from, to uint64) []uint64 {
+		var out []uint64
+		for id := from; id <= to; id++ {
+			out = append(out, id)
+		}
+		return out
+	}
+	tests := []struct {
+		name         string
+		published    int
+		after        uint64
+		types        []string
+		wantIDs      []uint64
+		wantComplete bool
+	}{
+		{"fresh connect", 5, 0, nil, nil, true},
+		{"gap", 5, 2, nil, ids(3, 5), true},
+		{"up to date", 5, 5, nil, nil, true},
+		{"unknown id", 5, 9, nil, nil, false},
+		{"full ring", DefaultHistory, 1, nil, ids(2, DefaultHistory), true},
+		{"wrapped, gap retained", DefaultHistory + 44, 100, nil, ids(101, DefaultHistory+44), true},
+		{"wrapped, oldest retained", DefaultHistory + 44, 44, nil, ids(45, DefaultHistory+44), true},
+		{"wrapped, gap lost", DefaultHistory + 44, 10, nil, ids(45, DefaultHistory+44), false},
+		{"filtered", 6, 1, []string{TypeSimulated}, []uint64{2, 4, 6}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(0, -1)
+			for i := 1; i <= tt.published; i++ {
+				typ := TypeStatus
+				if i%2 == 0 {
+					typ = TypeSimulated
+				}
+				b.Publish(Event{Type: typ, Data: []byte(fmt.Sprint(i))})
+				b.Publish(Event{Type: TypeTick}) // transient, never numbered
+			}
+			_, backlog, complete := b.SubscribeAfter(tt.after, tt.types...)
+			if complete != tt.wantComplete {
+				t.Fatalf("complete %t, want %t", complete, tt.wantComplete)
+			}
+			if len(backlog) != len(tt.wantIDs) {
+				t.Fatalf("replayed %d events, want %d", len(backlog), len(tt.wantIDs))
+			}
+			for i, e := range backlog {
+				if e.ID != tt.wantIDs[i] || string(e.Data) != fmt.Sprint(e.ID) {
+					t.Fatalf("event %d is %d %q, want %d", i, e.ID, e.Data, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryInIDOrder(t *testing.T) {
+	const publishers, each = 16, 2000
+	b := New(publishers*each, -1)
+	sub := b.Subscribe()
+	done := make(chan struct{})
+	for p := 0; p < publishers; p++ {
+		go func() {
+			for i := 0; i < each; i++ {
+				b.Publish(Event{Type: TypeStatus})
+			}
+			done <- struct{}{}
+		}()
+	}
+	for p := 0; p < publishers; p++ {
+		<-done
+	}
+	var last uint64
+	for i := 0; i < publishers*each; i++ {
+		e := <-sub.C
+		if e.ID != last+1 {
+			t.Fatalf("event %d after %d", e.ID, last)
+		}
+		last = e.ID
+	}
+}
+
+func TestResumeWhilePublishing(t *testing.T) {
+	const publishers, each = 4, 50
+	b := New(publishers*each, -1)
+	for i := 0; i < 100; i++ {
+		b.Publish(Event{Type: TypeStatus})
+	}
+	done := make(chan struct{})
+	for p := 0; p < publishers; p++ {
+		go func() {
+			for i := 0; i < each; i++ {
+				b.Publish(Event{Type: TypeStatus})
+			}
+			done <- struct{}{}
+		}()
+	}
+	// The backlog and the channel together continue from after with no gap
+	// and no repeat, however the subscription lands among the publishes.
+	after := uint64(50)
+	sub, backlog, complete := b.SubscribeAfter(after)
+	if !complete {
+		t.Fatal("backlog incomplete")
+	}
+	for p := 0; p < publishers; p++ {
+		<-done
+	}
+	last := after
+	for _, e := range backlog {
+		if e.ID != last+1 {
+			t.Fatalf("backlog event %d after %d", e.ID, last)
+		}
+		last = e.ID
+	}
+	for last < 100+publishers*each {
+		e := <-sub.C
+		if e.ID != last+1 {
+			t.Fatalf("live event %d after %d", e.ID, last)
+		}
+		last = e.ID
+	}
+}
+
+// bus is what the benchmarks drive: the Broker, or lockedBus as a baseline.
+type bus interface {
+	Subscribe(types ...string) *Subscription
+	Unsubscribe(sub *Subscription)
+	Publish(e Event) int
+}
+
+// lockedBus is the fan-out the Broker replaced: subscribers in a map behind a
+// read-write lock held for the whole fan-out, so connects and disconnects wait
+// for every publish in flight.
+type lockedBus struct {
+	maxDrops int
+	mu       sync.RWMutex
+	subs     map[*Subscription]struct{}
+}
+
+func newLockedBus() *lockedBus {
+	return &lockedBus{maxDrops: -1, subs: make(map[*Subscription]struct{})}
+}
+
+func (l *lockedBus) Subscribe(types ...string) *Subscription {
+	ch := make(chan Event, DefaultBuffer)
+	sub := &Subscription{C: ch, ch: ch}
+	l.mu.Lock()
+	l.subs[sub] = struct{}{}
+	l.mu.Unlock()
+	return sub
+}
+
+func (l *lockedBus) Unsubscribe(sub *Subscription) {
+	l.mu.Lock()
+	delete(l.subs, sub)
+	l.mu.Unlock()
+	sub.mu.Lock()
+	sub.closeLocked()
+	sub.mu.Unlock()
+}
+
+func (l *lockedBus) Publish(e Event) int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	delivered := 0
+	for sub := range l.subs {
+		if queued, _ := sub.offer(e, l.maxDrops); queued {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+var benchBuses = []struct {
+	name string
+	new  func() bus
+}{
+	{"copy-on-write", func() bus { return New(DefaultBuffer, -1) }},
+	{"rwmutex", func() bus { return newLockedBus() }},
+}
+
+// subscribeDrained adds n subscribers that read until unsubscribed.
+func subscribeDrained(bs bus, n int) []*Subscription {
+	subs := make([]*Subscription, n)
+	for i := range subs {
+		subs[i] = bs.Subscribe()
+		go func(c <-chan Event) {
+			for range c {
+			}
+		}(subs[i].C)
+	}
+	return subs
+}
+
+// untilStopped runs fn in a loop on another goroutine until the returned stop
+// function is called.
+func untilStopped(fn func()) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				fn()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// BenchmarkPublishFanout publishes from parallel goroutines while clients
+// connect and disconnect.
+func BenchmarkPublishFanout(b *testing.B) {
+	for _, bb := range benchBuses {
+		for _, n := range []int{1, 100, 1000} {
+			b.Run(fmt.Sprintf("%s/subscribers=%d", bb.name, n), func(b *testing.B) {
+				bs := bb.new()
+				subs := subscribeDrained(bs, n)
+				stop := untilStopped(func() { bs.Unsubscribe(bs.Subscribe()) })
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						bs.Publish(Event{Type: TypeStatus})
+					}
+				})
+				b.StopTimer()
+				stop()
+				for _, sub := range subs {
+					bs.Unsubscribe(sub)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkSubscribe connects and disconnects one client while four goroutines
+// publish to 1000 others.
+func BenchmarkSubscribe(b *testing.B) {
+	for _, bb := range benchBuses {
+		b.Run(bb.name, func(b *testing.B) {
+			bs := bb.new()
+			subs := subscribeDrained(bs, 1000)
+			var stops []func()
+			for i := 0; i < 4; i++ {
+				stops = append(stops, untilStopped(func() { bs.Publish(Event{Type: TypeStatus}) }))
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bs.Unsubscribe(bs.Subscribe())
+			}
+			b.StopTimer()
+			for _, stop := range stops {
+				stop()
+			}
+			for _, sub := range subs {
+				bs.Unsubscribe(sub)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+File: internal/cluster/cluster.go
+Description: Multi-instance coordination over a shared state store. A Node holds
+the leader lease that gates the poller and schedulers, and relays broadcasts
+through the store so every instance's SSE and WebSocket clients see the same
+events regardless of which instance produced them.
+*/
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"axis/internal/store"
+)
+
+const (
+	leaderLease = "leader"
+
+	// LeaseTTL is how long a crashed leader blocks failover.
+	LeaseTTL      = 15 * time.Second
+	renewInterval = LeaseTTL / 3
+
+	pollInterval    = 500 * time.Millisecond
+	pollBatch       = 200
+	relayRetention  = 10 * time.Minute
+	pruneInterval   = time.Minute
+	shutdownTimeout = 2 * time.Second
+)
+
+// Node is one Axis instance taking part in a cluster.
+type Node struct {
+	id     string
+	coord  store.Coordinator
+	logger *slog.Logger
+	leader atomic.Bool
+}
+
+// NewNode joins the cluster coordinated by coord. An empty id derives one from
+// the hostname and a random suffix.
+func NewNode(coord store.Coordinator, id string, logger *slog.Logger) *Node {
+	if id == "" {
+		id = defaultID()
+	}
+	return &Node{id: id, coord: coord, logger: logger}
+}
+
+func defaultID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "axis"
+	}
+	b := make([]byte, 4)
+	rand.Read(b)
+	return host + "-" + hex.EncodeToString(b)
+}
+
+// ID returns the instance identifier recorded as lease holder and broadcast origin.
+func (n *Node) ID() string { return n.id }
+
+// IsLeader reports whether this instance currently holds the leader lease.
+func (n *Node) IsLeader() bool { return n.leader.Load() }
+
+// Publish relays a broadcast to the other instances.
+func (n *Node) Publish(ctx context.Context, typ string, data []byte) error {
+	return n.coord.AppendBroadcast(ctx, store.Broadcast{Origin: n.id, Type: typ, Data: data})
+}
+
+// Run campaigns for leadership and delivers other instances' broadcasts to
+// deliver until ctx ends, then releases the lease so failover is immediate.
+func (n *Node) Run(ctx context.Context, deliver func(store.Broadcast)) {
+	lastID, err := n.coord.LatestBroadcastID(ctx)
+	if err != nil {
+		n.logger.Error("cluster: broadcast position unavailable", "error", err)
+	}
+
+	n.campaign(ctx)
+	renew := time.NewTicker(renewInterval)
+	defer renew.Stop()
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-renew.C:
+			n.campaign(ctx)
+		case <-poll.C:
+			lastID = n.relay(ctx, lastID, deliver)
+		case <-prune.C:
+			if n.IsLeader() {
+				if err := n.coord.PruneBroadcasts(ctx, time.Now().Add(-relayRetention)); err != nil {
+					n.logger.Warn("cluster: prune failed", "error", err)
+				}
+			}
+		case <-ctx.Done():
+			if n.leader.Swap(false) {
+				releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+				if err := n.coord.ReleaseLease(releaseCtx, leaderLease, n.id); err != nil {
+					n.logger.Warn("cluster: lease release failed", "error", err)
+				}
+				cancel()
+			}
+			return
+		}
+	}
+}
+
+func (n *Node) campaign(ctx context.Context) {
+	ok, err := n.coord.AcquireLease(ctx, leaderLease, n.id, LeaseTTL)
+	if err != nil {
+		// Without a renewal the lease may lapse; step down rather than risk
+		// two leaders.
+		n.logger.Error("cluster: lease renewal failed", "error", err)
+		ok = false
+	}
+	if was := n.leader.Swap(ok); was != ok {
+		n.logger.Info("cluster: leadership changed", "node", n.id, "leader", ok)
+	}
+}
+
+func (n *Node) relay(ctx context.Context, lastID int64, deliver func(store.Broadcast)) int64 {
+	for {
+		batch, err := n.coord.ListBroadcasts(ctx, lastID, pollBatch)
+		if err != nil {
+			n.logger.Warn("cluster: broadcast poll failed", "error", err)
+			return lastID
+		}
+		for _, b := range batch {
+			lastID = b.ID
+			if b.Origin != n.id {
+				deliver(b)
+			}
+		}
+		if len(batch) < pollBatch {
+			return lastID
+		}
+	}
+}
@@ -0,0 +1,617 @@
+/*
+File: internal/config/config.go
+Description: Typed Axis configuration. Every setting has a YAML key, an
+environment variable and an `axis serve` flag; Load starts from the defaults
+and applies an optional YAML file, then the environment, then flags, and
+validates the result, reporting every problem with the source that set it.
+*/
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/credentials"
+	"axis/internal/cron"
+	"axis/internal/quota"
+	"axis/internal/scheduler"
+	"axis/internal/store"
+	"axis/internal/workspace"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the YAML file when --config is not given.
+const FileEnv = "AXIS_CONFIG_FILE"
+
+// Config holds every setting of an Axis deployment. Zero durations leave the
+// server's default in place.
+type Config struct {
+	AdminEmail          string `yaml:"admin_email" env:"ADMIN_EMAIL" help:"Workspace admin the service account acts as"`
+	ServiceAccountEmail string `yaml:"service_account_email" env:"SERVICE_ACCOUNT_EMAIL" help:"service account to impersonate"`
+	UserEmail           string `yaml:"user_email" env:"USER_EMAIL" help:"operator profile verified at startup"`
+	SAKeyPrimary        string `yaml:"sa_key_primary" env:"AXIS_SA_KEY_PRIMARY" help:"primary service account key file"`
+	SAKeySecondary      string `yaml:"sa_key_secondary" env:"AXIS_SA_KEY_SECONDARY" help:"secondary service account key file"`
+	CredentialStrategy  string `yaml:"credential_strategy" env:"AXIS_CREDENTIAL_STRATEGY" help:"impersonate, key, adc or oauth"`
+	OAuthClientFile     string `yaml:"oauth_client_file" env:"AXIS_OAUTH_CLIENT_FILE" help:"desktop OAuth client file of the oauth strategy"`
+	OAuthTokenFile      string `yaml:"oauth_token_file" env:"AXIS_OAUTH_TOKEN_FILE" help:"token cache of the oauth strategy"`
+
+	Port     int    `yaml:"port" env:"PORT" help:"HTTP listen port"`
+	LogLevel string `yaml:"log_level" env:"AXIS_LOG_LEVEL" help:"debug, info, warn or error"`
+	// LogFormat is "json" or "text".
+	LogFormat string `yaml:"log_format" env:"AXIS_LOG_FORMAT" help:"json or text"`
+
+	StateBackend string `yaml:"state_backend" env:"AXIS_STATE_BACKEND" help:"file, sqlite, postgres or redis"`
+	StatePath    string `yaml:"state_path" env:"AXIS_STATE_PATH" help:"directory relative state_file and sqlite_path are resolved in (default: working directory)"`
+	StateFile    string `yaml:"state_file" env:"AXIS_STATE_FILE" help:"state file of the file backend"`
+	SQLitePath   string `yaml:"sqlite_path" env:"AXIS_SQLITE_PATH" help:"database file of the sqlite backend"`
+	PostgresDSN  string `yaml:"postgres_dsn" env:"AXIS_POSTGRES_DSN" help:"connection string of the postgres backend"`
+	RedisURL     string `yaml:"redis_url" env:"AXIS_REDIS_URL" help:"redis:// or rediss:// URL of the redis backend"`
+	Cluster      bool   `yaml:"cluster" env:"AXIS_CLUSTER" help:"coordinate with other instances through the state store"`
+	InstanceID   string `yaml:"instance_id" env:"AXIS_INSTANCE_ID" help:"cluster instance name"`
+
+	AirGap             bool     `yaml:"airgap" env:"AXIS_AIRGAP" help:"read-only scopes; deletes become signed plans"`
+	PlanSigningKey     string   `yaml:"plan_signing_key" env:"AXIS_PLAN_SIGNING_KEY" help:"private key plans are signed with"`
+	PlanDir            string   `yaml:"plan_dir" env:"AXIS_PLAN_DIR" help:"directory plans are written to"`
+	BackupDir          string   `yaml:"backup_dir" env:"AXIS_BACKUP_DIR" help:"directory Docs and Sheets are exported to before Axis changes them (empty: only note the revision)"`
+	PlanPublicKey      string   `yaml:"plan_public_key" env:"AXIS_PLAN_PUBLIC_KEY" help:"public key plans must be signed with"`
+	PlanApproverKeys   []string `yaml:"plan_approver_keys" env:"AXIS_PLAN_APPROVER_KEYS" help:"public keys of plan approvers"`
+	PlanApprovals      int      `yaml:"plan_approvals" env:"AXIS_PLAN_APPROVALS" help:"approvals a plan needs before it applies"`
+	AuthFile           string   `yaml:"auth_file" env:"AXIS_AUTH_FILE" help:"API access control file"`
+	AuthDisabled       bool     `yaml:"auth_disabled" env:"AXIS_AUTH_DISABLED" help:"treat every API caller as admin"`
+	LegacyGETMutations bool     `yaml:"legacy_get_mutations" env:"AXIS_LEGACY_GET_MUTATIONS" help:"serve the deprecated GET mutation routes"`
+	WebSocketOrigins   []string `yaml:"ws_origins" env:"AXIS_WS_ORIGINS" help:"extra origins allowed to open the WebSocket uplink"`
+	DirectoryWrite     bool     `yaml:"directory_write" env:"AXIS_DIRECTORY_WRITE" help:"request the read-write Directory user scope"`
+	DirectoryGroups    bool     `yaml:"directory_groups" env:"AXIS_DIRECTORY_GROUPS" help:"read Directory groups and org units"`
+	LoginReports       bool     `yaml:"login_reports" env:"AXIS_LOGIN_REPORTS" help:"read sign-ins from the Reports audit log"`
+	StorageReports     bool     `yaml:"storage_reports" env:"AXIS_STORAGE_REPORTS" help:"read per-user storage from the Reports usage log"`
+	DriveTagLabel      string   `yaml:"drive_tag_label" env:"AXIS_DRIVE_TAG_LABEL" help:"Drive Label holding Doc and Sheet tags"`
+	DriveTagField      string   `yaml:"drive_tag_field" env:"AXIS_DRIVE_TAG_FIELD" help:"selection field of the tag label"`
+	ArchiveFolder      string   `yaml:"archive_folder" env:"AXIS_ARCHIVE_FOLDER" help:"Drive folder archived items are moved to"`
+	DriveUpload        bool     `yaml:"drive_upload" env:"AXIS_DRIVE_UPLOAD" help:"allow uploading files to Drive through the API"`
+	AttachmentFolder   string   `yaml:"attachment_folder" env:"AXIS_ATTACHMENT_FOLDER" help:"Drive folder Keep note attachments are copied to before the note is removed"`
+	NoteConvert        bool     `yaml:"note_convert" env:"AXIS_NOTE_CONVERT" help:"allow converting Keep notes to Docs, uploading attachments to Drive"`
+
+	APIRate    float64 `yaml:"api_rate" env:"AXIS_API_RATE" help:"Workspace API requests per second (0 disables)"`
+	APIBurst   int     `yaml:"api_burst" env:"AXIS_API_BURST" help:"Workspace API burst size"`
+	APIBudgets string  `yaml:"api_budgets" env:"AXIS_API_BUDGETS" help:"per-API budgets, e.g. drive=5,keep=2:4"`
+	APIRetries int     `yaml:"api_retries" env:"AXIS_API_RETRIES" help:"retries of rate-limited calls (0 disables)"`
+
+	ItemTypes         []string      `yaml:"item_types" env:"AXIS_ITEM_TYPES" help:"registry item types to manage; the others' scopes are not requested"`
+	PollInterval      time.Duration `yaml:"poll_interval" env:"AXIS_POLL_INTERVAL" help:"registry poll interval"`
+	PollSchedule      string        `yaml:"poll_schedule" env:"AXIS_POLL_SCHEDULE" help:"per-type poll intervals, e.g. keep=1m,doc=10m"`
+	LinkCheckEvery    time.Duration `yaml:"linkcheck_interval" env:"AXIS_LINKCHECK_INTERVAL" help:"background link scan interval (0 disables)"`
+	JournalRetention  time.Duration `yaml:"journal_retention" env:"AXIS_JOURNAL_RETENTION" help:"how long journal entries are kept"`
+	SnapshotRetention time.Duration `yaml:"snapshot_retention" env:"AXIS_SNAPSHOT_RETENTION" help:"how long daily registry snapshots are kept for rule previews"`
+	StatusGrace       time.Duration `yaml:"status_grace" env:"AXIS_STATUS_GRACE" help:"how long an item may be missing from the registry before its status is removed"`
+	Enrichers         []string      `yaml:"enrichers" env:"AXIS_ENRICHERS" help:"enrichment pipeline stages in order"`
+	IndexPath         string        `yaml:"index_path" env:"AXIS_INDEX_PATH" help:"search index file"`
+	IndexContentMB    int           `yaml:"index_content_mb" env:"AXIS_INDEX_CONTENT_MB" help:"megabytes of exported Doc and Sheet text to index (0 disables)"`
+
+	RulesFile          string        `yaml:"rules_file" env:"AXIS_RULES_FILE" help:"rules file"`
+	PlaybooksFile      string        `yaml:"playbooks_file" env:"AXIS_PLAYBOOKS_FILE" help:"playbook catalog file"`
+	SuspendedPlaybook  string        `yaml:"suspended_playbook" env:"AXIS_SUSPENDED_PLAYBOOK" help:"playbook run for newly suspended users"`
+	UserSchemas        []string      `yaml:"user_schemas" env:"AXIS_USER_SCHEMAS" help:"custom user schemas to read (all when empty)"`
+	UserRuleInterval   time.Duration `yaml:"user_rule_interval" env:"AXIS_USER_RULE_INTERVAL" help:"user rule evaluation interval"`
+	InactiveDays       int           `yaml:"inactive_days" env:"AXIS_INACTIVE_DAYS" help:"days without sign-in before a user counts as inactive"`
+	StorageLimitMB     int           `yaml:"storage_limit_mb" env:"AXIS_STORAGE_LIMIT_MB" help:"megabytes of storage past which a user is flagged (0 disables)"`
+	StorageQuotaPct    float64       `yaml:"storage_quota_percent" env:"AXIS_STORAGE_QUOTA_PERCENT" help:"share of their quota, in percent, past which a user is flagged (0 disables)"`
+	InternalDomains    []string      `yaml:"internal_domains" env:"AXIS_INTERNAL_DOMAINS" help:"domains sharing with which is not external (default: the admin's)"`
+	Reviewers          []string      `yaml:"reviewers" env:"AXIS_REVIEWERS" help:"accounts that sign off review checklists"`
+	CleanupDocTo       []string      `yaml:"cleanup_doc_recipients" env:"AXIS_CLEANUP_DOC_RECIPIENTS" help:"accounts the weekly cleanup plan Doc is shared with (empty disables it)"`
+	CleanupDocDay      string        `yaml:"cleanup_doc_day" env:"AXIS_CLEANUP_DOC_DAY" help:"weekday the cleanup plan Doc is written, in UTC"`
+	ReportFolder       string        `yaml:"report_folder" env:"AXIS_REPORT_FOLDER" help:"Drive folder summary reports are written to (empty disables them)"`
+	ReportSchedule     string        `yaml:"report_schedule" env:"AXIS_REPORT_SCHEDULE" help:"cron schedule of the summary report, in UTC (empty: on request only)"`
+	ReportFormat       string        `yaml:"report_format" env:"AXIS_REPORT_FORMAT" help:"summary report file: doc or sheet"`
+	ReportTo           []string      `yaml:"report_recipients" env:"AXIS_REPORT_RECIPIENTS" help:"accounts the summary report is emailed to"`
+	PolicySheetID      string        `yaml:"policy_sheet_id" env:"AXIS_POLICY_SHEET_ID" help:"policy spreadsheet"`
+	FederationFile     string        `yaml:"federation_file" env:"AXIS_FEDERATION_FILE" help:"subsidiary instances to aggregate"`
+	FederationInterval time.Duration `yaml:"federation_interval" env:"AXIS_FEDERATION_INTERVAL" help:"federation refresh interval"`
+	TenantsFile        string        `yaml:"tenants_file" env:"AXIS_TENANTS_FILE" help:"further Workspace tenants served under /api/t/{tenant}"`
+
+	ReminderCalendar string        `yaml:"reminder_calendar" env:"AXIS_REMINDER_CALENDAR" help:"calendar note reminders are synced to"`
+	NoteLogSheet     string        `yaml:"note_log_sheet" env:"AXIS_NOTE_LOG_SHEET" help:"spreadsheet notes are logged to"`
+	NoteLogRange     string        `yaml:"note_log_range" env:"AXIS_NOTE_LOG_RANGE" help:"A1 range of the note log"`
+	PubSubTopic      string        `yaml:"pubsub_topic" env:"AXIS_PUBSUB_TOPIC" help:"Pub/Sub topic events are published to"`
+	BQDataset        string        `yaml:"bq_dataset" env:"AXIS_BQ_DATASET" help:"BigQuery export target as project.dataset"`
+	BQInterval       time.Duration `yaml:"bq_interval" env:"AXIS_BQ_INTERVAL" help:"BigQuery export interval"`
+
+	UpdateURL       string `yaml:"update_url" env:"AXIS_UPDATE_URL" help:"self-update release manifest"`
+	UpdatePublicKey string `yaml:"update_public_key" env:"AXIS_UPDATE_PUBLIC_KEY" help:"public key release binaries must be signed with"`
+}
+
+// Default returns the settings Axis uses when nothing is configured.
+func Default() *Config {
+	return &Config{
+		Port:               8080,
+		CredentialStrategy: credentials.StrategyImpersonate,
+		OAuthTokenFile:     "axis.oauth.json",
+		LogLevel:           "info",
+		LogFormat:          "json",
+		StateBackend:       store.BackendFile,
+		StateFile:          "axis.state.json",
+		SQLitePath:         "axis.db",
+		PlanDir:            "plans",
+		BackupDir:          "backups",
+		PlanApprovals:      1,
+		APIRate:            10,
+		APIBurst:           20,
+		APIRetries:         quota.DefaultRetry.Retries,
+		BQInterval:         time.Hour,
+		CleanupDocDay:      "monday",
+		ReportFormat:       "doc",
+		ItemTypes:          slices.Clone(workspace.ItemTypes),
+	}
+}
+
+// setting is one field of Config with its names.
+type setting struct {
+	key, env, help string
+	field          reflect.Value
+}
+
+func (c *Config) settings() []setting {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	out := make([]setting, t.NumField())
+	for i := range out {
+		f := t.Field(i)
+		out[i] = setting{key: f.Tag.Get("yaml"), env: f.Tag.Get("env"), help: f.Tag.Get("help"), field: v.Field(i)}
+	}
+	return out
+}
+
+// FlagNames lists the `axis serve` flags, for shell completion.
+func FlagNames() []string {
+	names := []string{"config"}
+	for _, s := range Default().settings() {
+		names = append(names, s.flagName())
+	}
+	return names
+}
+
+// flagName is the serve flag of a setting.
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.key, "_", "-")
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// set parses raw into the setting's field.
+func (s setting) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	f := s.field
+	switch {
+	case f.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 90s or 1h", raw)
+		}
+		f.SetInt(int64(d))
+	case f.Kind() == reflect.String:
+		f.SetString(raw)
+	case f.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		f.SetBool(b)
+	case f.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		f.SetInt(int64(n))
+	case f.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		f.SetFloat(n)
+	case f.Kind() == reflect.Slice:
+		var list []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		f.Set(reflect.ValueOf(list))
+	}
+	return nil
+}
+
+// flagValue defers a flag until the file and environment have been applied,
+// so flags win regardless of parse order.
+type flagValue struct {
+	raw  *string
+	bool bool
+}
+
+func (v flagValue) String() string {
+	if v.raw == nil {
+		return ""
+	}
+	return *v.raw
+}
+
+func (v flagValue) Set(raw string) error {
+	*v.raw = raw
+	return nil
+}
+
+func (v flagValue) IsBoolFlag() bool { return v.bool }
+
+// Load reads the configuration for `axis serve` from args, or from the file
+// and environment alone when args is nil. The file is --config or
+// AXIS_CONFIG_FILE.
+func Load(args []string) (*Config, error) {
+	c := Default()
+	settings := c.settings()
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv(FileEnv), "YAML configuration file ("+FileEnv+")")
+	flagged := make([]string, len(settings))
+	for i, s := range settings {
+		fs.Var(flagValue{raw: &flagged[i], bool: s.field.Kind() == reflect.Bool}, s.flagName(), s.help+" ("+s.env+")")
+	}
+	if args != nil {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() > 0 {
+			return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		}
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var errs []error
+	if *path != "" {
+		errs = append(errs, c.loadFile(*path)...)
+	}
+	for _, s := range settings {
+		if raw := os.Getenv(s.env); raw != "" {
+			if err := s.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for i, s := range settings {
+		if set[s.flagName()] {
+			if err := s.set(flagged[i]); err != nil {
+				errs = append(errs, fmt.Errorf("--%s: %w", s.flagName(), err))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		errs = c.validate()
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  %w", joinErrors(errs))
+	}
+	return c, nil
+}
+
+// loadFile applies a YAML file of settings keyed by their YAML names.
+func (c *Config) loadFile(path string) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("unable to read config file: %w", err)}
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil && !errors.Is(err, io.EOF) {
+		return []error{fmt.Errorf("%s: %w", path, err)}
+	}
+	byKey := make(map[string]setting)
+	for _, s := range c.settings() {
+		byKey[s.key] = s
+	}
+	var errs []error
+	for key, value := range doc {
+		s, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q%s", path, key, suggest(key, byKey)))
+			continue
+		}
+		raw, err := scalar(value)
+		if err == nil {
+			err = s.set(raw)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+		}
+	}
+	return errs
+}
+
+// scalar renders a YAML value as the string form the environment would use;
+// lists become comma-separated.
+func scalar(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", errors.New("want a value, not a mapping")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// suggest names a known key that differs from key only in case or separators.
+func suggest(key string, known map[string]setting) string {
+	norm := func(s string) string { return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s)) }
+	for k, s := range known {
+		if norm(k) == norm(key) || strings.EqualFold(s.env, key) {
+			return fmt.Sprintf(" (did you mean %q?)", k)
+		}
+	}
+	return ""
+}
+
+func joinErrors(errs []error) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n  "))
+}
+
+// validate checks values that parsed but make no sense, and combinations.
+func (c *Config) validate() []error {
+	var errs []error
+	fail := func(key, format string, args ...any) {
+		env := ""
+		for _, s := range c.settings() {
+			if s.key == key {
+				env = s.env
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s (%s): "+format, append([]any{key, env}, args...)...))
+	}
+	for key, email := range map[string]string{"admin_email": c.AdminEmail, "service_account_email": c.ServiceAccountEmail, "user_email": c.UserEmail} {
+		if email != "" && !strings.Contains(email, "@") {
+			fail(key, "%q is not an email address", email)
+		}
+	}
+	switch c.CredentialStrategy {
+	case credentials.StrategyImpersonate, credentials.StrategyADC:
+	case credentials.StrategyKey:
+		if c.SAKeyPrimary == "" && c.SAKeySecondary == "" {
+			fail("credential_strategy", "key needs sa_key_primary or sa_key_secondary")
+		}
+	case credentials.StrategyOAuth:
+		if c.OAuthClientFile == "" {
+			fail("credential_strategy", "oauth needs oauth_client_file")
+		}
+		if c.OAuthTokenFile == "" {
+			fail("oauth_token_file", "must be set with the oauth strategy")
+		}
+	default:
+		fail("credential_strategy", "%q is not %s", c.CredentialStrategy, strings.Join(credentials.Strategies, ", "))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		fail("port", "%d is not a port between 1 and 65535", c.Port)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		fail("log_level", "%q is not debug, info, warn or error", c.LogLevel)
+	}
+	if f := strings.ToLower(c.LogFormat); f != "json" && f != "text" {
+		fail("log_format", "%q is not json or text", c.LogFormat)
+	}
+	switch c.StateBackend {
+	case store.BackendFile, store.BackendSQLite:
+	case store.BackendPostgres:
+		if c.PostgresDSN == "" {
+			fail("postgres_dsn", "must be set for the postgres backend")
+		}
+	case store.BackendRedis:
+		if u, err := url.Parse(c.RedisURL); c.RedisURL == "" || err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") {
+			fail("redis_url", "must be a redis:// or rediss:// URL for the redis backend")
+		}
+	default:
+		fail("state_backend", "unknown backend %q (want file, sqlite, postgres or redis)", c.StateBackend)
+	}
+	if c.StatePath != "" {
+		if info, err := os.Stat(c.StatePath); err != nil || !info.IsDir() {
+			fail("state_path", "%q is not a directory", c.StatePath)
+		}
+	}
+	if c.PlanApprovals < 1 {
+		fail("plan_approvals", "must be at least 1, got %d", c.PlanApprovals)
+	}
+	if c.DriveTagLabel != "" && c.DriveTagField == "" {
+		fail("drive_tag_field", "must name the label's selection field when drive_tag_label is set")
+	}
+	if c.APIRate < 0 {
+		fail("api_rate", "must not be negative, got %g", c.APIRate)
+	}
+	if c.APIBurst < 1 {
+		fail("api_burst", "must be at least 1, got %d", c.APIBurst)
+	}
+	if _, err := quota.ParseBudgets(c.APIBudgets); err != nil {
+		fail("api_budgets", "%v", err)
+	}
+	if c.APIRetries < 0 {
+		fail("api_retries", "must not be negative, got %d", c.APIRetries)
+	}
+	if len(c.ItemTypes) == 0 {
+		fail("item_types", "must name at least one of %s", strings.Join(workspace.ItemTypes, ", "))
+	}
+	for _, t := range c.ItemTypes {
+		if !slices.Contains(workspace.ItemTypes, t) {
+			fail("item_types", "unknown item type %q (want %s)", t, strings.Join(workspace.ItemTypes, ", "))
+		}
+	}
+	if _, err := scheduler.ParseSchedule(c.PollSchedule); err != nil {
+		fail("poll_schedule", "%v", err)
+	}
+	for key, d := range map[string]time.Duration{
+		"poll_interval":       c.PollInterval,
+		"linkcheck_interval":  c.LinkCheckEvery,
+		"journal_retention":   c.JournalRetention,
+		"snapshot_retention":  c.SnapshotRetention,
+		"status_grace":        c.StatusGrace,
+		"federation_interval": c.FederationInterval,
+		"user_rule_interval":  c.UserRuleInterval,
+	} {
+		if d < 0 {
+			fail(key, "must not be negative, got %s", d)
+		}
+	}
+	if c.IndexContentMB < 0 {
+		fail("index_content_mb", "must not be negative, got %d", c.IndexContentMB)
+	}
+	if c.InactiveDays < 0 {
+		fail("inactive_days", "must not be negative, got %d", c.InactiveDays)
+	}
+	if c.StorageLimitMB < 0 {
+		fail("storage_limit_mb", "must not be negative, got %d", c.StorageLimitMB)
+	}
+	if c.StorageQuotaPct < 0 || c.StorageQuotaPct > 100 {
+		fail("storage_quota_percent", "must be between 0 and 100, got %g", c.StorageQuotaPct)
+	}
+	if c.BQDataset != "" {
+		if project, dataset, ok := strings.Cut(c.BQDataset, "."); !ok || project == "" || dataset == "" {
+			fail("bq_dataset", "must be project.dataset, got %q", c.BQDataset)
+		}
+		if c.BQInterval < time.Minute {
+			fail("bq_interval", "must be at least 1m, got %s", c.BQInterval)
+		}
+	}
+	for _, email := range c.CleanupDocTo {
+		if !strings.Contains(email, "@") {
+			fail("cleanup_doc_recipients", "%q is not an email address", email)
+		}
+	}
+	if _, ok := c.CleanupWeekday(); !ok {
+		fail("cleanup_doc_day", "%q is not a weekday", c.CleanupDocDay)
+	}
+	if c.ReportSchedule != "" {
+		if _, err := cron.Parse(c.ReportSchedule); err != nil {
+			fail("report_schedule", "%v", err)
+		}
+		if c.ReportFolder == "" {
+			fail("report_schedule", "needs report_folder")
+		}
+	}
+	if c.ReportFormat != "doc" && c.ReportFormat != "sheet" {
+		fail("report_format", "must be doc or sheet, got %q", c.ReportFormat)
+	}
+	for _, email := range c.ReportTo {
+		if !strings.Contains(email, "@") {
+			fail("report_recipients", "%q is not an email address", email)
+		}
+	}
+	if len(c.ReportTo) > 0 && c.ReportFolder == "" {
+		fail("report_recipients", "needs report_folder")
+	}
+	if c.TenantsFile != "" && c.Cluster {
+		fail("tenants_file", "tenants are not supported with cluster")
+	}
+	if c.UpdateURL != "" {
+		if u, err := url.Parse(c.UpdateURL); err != nil || u.Scheme != "https" && u.Scheme != "http" {
+			fail("update_url", "%q is not an http(s) URL", c.UpdateURL)
+		}
+	}
+	return errs
+}
+
+// CleanupWeekday parses CleanupDocDay, a weekday name such as "monday" or
+// "Mon".
+func (c *Config) CleanupWeekday() (time.Weekday, bool) {
+	name := strings.ToLower(strings.TrimSpace(c.CleanupDocDay))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if full := strings.ToLower(d.String()); name == full || name == full[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// RequireWorkspace reports the identities every Google API client needs. Only
+// impersonation names the service account; the other strategies take it from
+// the key or act as their own identity.
+func (c *Config) RequireWorkspace() error {
+	if c.CredentialStrategy == credentials.StrategyImpersonate && (c.AdminEmail == "" || c.ServiceAccountEmail == "" || c.UserEmail == "") {
+		return errors.New("ADMIN_EMAIL, SERVICE_ACCOUNT_EMAIL, and USER_EMAIL must be set")
+	}
+	if c.AdminEmail == "" || c.UserEmail == "" {
+		return errors.New("ADMIN_EMAIL and USER_EMAIL must be set")
+	}
+	return nil
+}
+
+// Level is the minimum level logged.
+func (c *Config) Level() slog.Level {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.LogLevel))
+	return level
+}
+
+// Tenant is the domain of the admin account.
+func (c *Config) Tenant() string {
+	_, domain, _ := strings.Cut(c.AdminEmail, "@")
+	return domain
+}
+
+// Domains returns the internal sharing domains, the admin's own by default.
+func (c *Config) Domains() []string {
+	if len(c.InternalDomains) > 0 {
+		return c.InternalDomains
+	}
+	return []string{c.Tenant()}
+}
+
+// StateLocation returns the state backend's location and a loggable form of
+// it; a Postgres DSN or Redis URL may carry a password, so only its host and
+// database are shown.
+func (c *Config) StateLocation() (location, shown string) {
+	switch c.StateBackend {
+	case store.BackendSQLite:
+		path := c.statePath(c.SQLitePath)
+		return path, path
+	case store.BackendPostgres:
+		if u, err := url.Parse(c.PostgresDSN); err == nil && u.Host != "" {
+			return c.PostgresDSN, u.Host + u.Path
+		}
+		return c.PostgresDSN, "AXIS_POSTGRES_DSN"
+	case store.BackendRedis:
+		if u, err := url.Parse(c.RedisURL); err == nil && u.Host != "" {
+			return c.RedisURL, u.Host + u.Path
+		}
+		return c.RedisURL, "AXIS_REDIS_URL"
+	default:
+		path := c.statePath(c.StateFile)
+		return path, path
+	}
+}
+
+// statePath resolves a relative state file in StatePath.
+func (c *Config) statePath(path string) string {
+	if c.StatePath == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.StatePath, path)
+}
@@ -0,0 +1,182 @@
+/*
+File: internal/config/features.go
+Description: Features and the OAuth scopes each needs. Axis requests only the
+scopes of the features the configuration enables, so a Keep-only deployment
+asks for no Drive access, and a feature whose scopes the Domain-Wide
+Delegation grant lacks can be turned off at startup rather than failing every
+token.
+*/
+package config
+
+import (
+	"slices"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	calendar "google.golang.org/api/calendar/v3"
+	docs "google.golang.org/api/docs/v1"
+	drive "google.golang.org/api/drive/v3"
+	drivelabels "google.golang.org/api/drivelabels/v2"
+	gmail "google.golang.org/api/gmail/v1"
+	keep "google.golang.org/api/keep/v1"
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// Feature is a part of Axis with the scopes it needs.
+type Feature struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// Required features cannot be turned off.
+	Required bool `json:"required,omitempty"`
+}
+
+// Features lists the enabled features. readOnly asks for read-only scopes,
+// so the Domain-Wide Delegation grant can omit write access, and leaves out
+// the features that only write.
+func (c *Config) Features(readOnly bool) []Feature {
+	pick := func(rw, ro string) string {
+		if readOnly {
+			return ro
+		}
+		return rw
+	}
+	var out []Feature
+	// Writing custom user schema fields needs the read-write Directory scope,
+	// which is an extra Domain-Wide Delegation grant.
+	if c.DirectoryWrite && !readOnly {
+		out = append(out, Feature{Name: "directory_write", Scopes: []string{admin.AdminDirectoryUserScope}})
+	} else {
+		out = append(out, Feature{Name: "directory", Scopes: []string{admin.AdminDirectoryUserReadonlyScope}, Required: true})
+	}
+	// Groups and org units are separate Directory grants.
+	if c.DirectoryGroups {
+		out = append(out, Feature{Name: "directory_groups", Scopes: []string{admin.AdminDirectoryGroupReadonlyScope, admin.AdminDirectoryOrgunitReadonlyScope}})
+	}
+	if slices.Contains(c.ItemTypes, "keep") {
+		out = append(out, Feature{Name: "keep", Scopes: []string{pick(keep.KeepScope, keep.KeepReadonlyScope)}})
+	}
+	// Docs and Sheets are listed through Drive.
+	if slices.Contains(c.ItemTypes, "doc") {
+		out = append(out, Feature{Name: "doc", Scopes: []string{pick(docs.DocumentsScope, docs.DocumentsReadonlyScope), drive.DriveReadonlyScope}})
+	}
+	if slices.Contains(c.ItemTypes, "sheet") {
+		out = append(out, Feature{Name: "sheet", Scopes: []string{pick(sheets.SpreadsheetsScope, sheets.SpreadsheetsReadonlyScope), drive.DriveReadonlyScope}})
+	}
+	if c.PolicySheetID != "" {
+		out = append(out, Feature{Name: "policy_sheet", Scopes: []string{pick(sheets.SpreadsheetsScope, sheets.SpreadsheetsReadonlyScope)}})
+	}
+	// The note log and Calendar reminders only write, so air-gapped
+	// deployments never run them.
+	if c.NoteLogSheet != "" && !readOnly {
+		out = append(out, Feature{Name: "note_log", Scopes: []string{sheets.SpreadsheetsScope}})
+	}
+	if c.ReminderCalendar != "" && !readOnly {
+		out = append(out, Feature{Name: "reminders", Scopes: []string{calendar.CalendarEventsScope}})
+	}
+	// Archiving moves files in Drive and exports Keep notes to new Docs.
+	if c.ArchiveFolder != "" && !readOnly {
+		out = append(out, Feature{Name: "archive", Scopes: []string{drive.DriveScope, docs.DocumentsScope}})
+	}
+	// Uploads may go into any folder, which needs full Drive access.
+	if c.DriveUpload && !readOnly {
+		out = append(out, Feature{Name: "drive_upload", Scopes: []string{drive.DriveScope}})
+	}
+	// Attachment sync uploads into an existing folder, which drive.file
+	// cannot reach.
+	if c.AttachmentFolder != "" && slices.Contains(c.ItemTypes, "keep") && !readOnly {
+		out = append(out, Feature{Name: "attachment_sync", Scopes: []string{drive.DriveScope}})
+	}
+	// Converted notes are new Docs; their attachments are uploaded to Drive.
+	if c.NoteConvert && slices.Contains(c.ItemTypes, "keep") && !readOnly {
+		out = append(out, Feature{Name: "note_convert", Scopes: []string{docs.DocumentsScope, drive.DriveFileScope}})
+	}
+	// The cleanup plan is a new Doc shared with the recipients.
+	if len(c.CleanupDocTo) > 0 && !readOnly {
+		out = append(out, Feature{Name: "cleanup_doc", Scopes: []string{docs.DocumentsScope, drive.DriveFileScope}})
+	}
+	// Summary reports are new Docs or Sheets moved into an existing folder,
+	// which drive.file cannot reach; mailing them sends as the admin.
+	if c.ReportFolder != "" && !readOnly {
+		file := docs.DocumentsScope
+		if c.ReportFormat == "sheet" {
+			file = sheets.SpreadsheetsScope
+		}
+		out = append(out, Feature{Name: "reports", Scopes: []string{file, drive.DriveScope}})
+		if len(c.ReportTo) > 0 {
+			out = append(out, Feature{Name: "report_mail", Scopes: []string{gmail.GmailSendScope}})
+		}
+	}
+	// Login activity from the Reports API is an extra Domain-Wide Delegation
+	// grant; without it inactivity relies on Directory sign-in times.
+	if c.LoginReports {
+		out = append(out, Feature{Name: "login_reports", Scopes: []string{reports.AdminReportsAuditReadonlyScope}})
+	}
+	if c.StorageReports {
+		out = append(out, Feature{Name: "storage_reports", Scopes: []string{reports.AdminReportsUsageReadonlyScope}})
+	}
+	// Tags on Docs and Sheets map to a Drive Label; writing label values
+	// needs full Drive access.
+	if c.DriveTagLabel != "" {
+		scopes := []string{drivelabels.DriveLabelsReadonlyScope}
+		if !readOnly {
+			scopes = append(scopes, drive.DriveScope)
+		}
+		out = append(out, Feature{Name: "drive_tags", Scopes: scopes})
+	}
+	return out
+}
+
+// Scopes returns the scopes of every enabled feature, each once.
+func (c *Config) Scopes(readOnly bool) []string {
+	var scopes []string
+	for _, f := range c.Features(readOnly) {
+		for _, s := range f.Scopes {
+			if !slices.Contains(scopes, s) {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
+
+// Disable turns a feature off, as if it had not been configured. It reports
+// false for required and unknown features.
+func (c *Config) Disable(name string) bool {
+	switch name {
+	case "directory_write":
+		c.DirectoryWrite = false
+	case "directory_groups":
+		c.DirectoryGroups = false
+	case "keep", "doc", "sheet":
+		c.ItemTypes = slices.DeleteFunc(slices.Clone(c.ItemTypes), func(t string) bool { return t == name })
+	case "policy_sheet":
+		c.PolicySheetID = ""
+	case "note_log":
+		c.NoteLogSheet = ""
+	case "reminders":
+		c.ReminderCalendar = ""
+	case "archive":
+		c.ArchiveFolder = ""
+	case "drive_upload":
+		c.DriveUpload = false
+	case "attachment_sync":
+		c.AttachmentFolder = ""
+	case "note_convert":
+		c.NoteConvert = false
+	case "cleanup_doc":
+		c.CleanupDocTo = nil
+	case "reports":
+		c.ReportFolder, c.ReportSchedule, c.ReportTo = "", "", nil
+	case "report_mail":
+		c.ReportTo = nil
+	case "login_reports":
+		c.LoginReports = false
+	case "storage_reports":
+		c.StorageReports = false
+	case "drive_tags":
+		c.DriveTagLabel = ""
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+/*
+File: internal/config/tenants.go
+Description: The tenants file. A server manages the Workspace domain its main
+settings describe and may serve further domains, each with its own identities
+and state, under /api/t/{tenant}. The file is JSON, like the auth and
+federation files; everything it leaves out is shared with the main tenant.
+*/
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"axis/internal/store"
+)
+
+var tenantName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// TenantConfig is one further tenant. State is the location of its state
+// backend (the main backend type is used); with the file backend it defaults
+// to the main state file with the tenant name before the extension.
+type TenantConfig struct {
+	Name                string   `json:"name"`
+	AdminEmail          string   `json:"admin_email"`
+	UserEmail           string   `json:"user_email"`
+	ServiceAccountEmail string   `json:"service_account_email,omitempty"`
+	InternalDomains     []string `json:"internal_domains,omitempty"`
+	State               string   `json:"state,omitempty"`
+}
+
+// LoadTenants reads and validates the tenants file against c, whose main
+// tenant the file must not repeat.
+func (c *Config) LoadTenants(path string) ([]TenantConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tenants file %s: %w", path, err)
+	}
+	var file struct {
+		Tenants []TenantConfig `json:"tenants"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse tenants file %s: %w", path, err)
+	}
+	if len(file.Tenants) == 0 {
+		return nil, fmt.Errorf("tenants file %s lists no tenants", path)
+	}
+	seen := map[string]bool{}
+	states := map[string]string{c.stateLocation(): "the main tenant"}
+	for i, t := range file.Tenants {
+		switch {
+		case !tenantName.MatchString(t.Name):
+			return nil, fmt.Errorf("tenants file %s: tenant %d: name %q must be lowercase letters, digits and dashes", path, i+1, t.Name)
+		case seen[t.Name]:
+			return nil, fmt.Errorf("tenants file %s: tenant %q is listed twice", path, t.Name)
+		case !strings.Contains(t.AdminEmail, "@") || !strings.Contains(t.UserEmail, "@"):
+			return nil, fmt.Errorf("tenants file %s: tenant %q needs admin_email and user_email", path, t.Name)
+		case strings.EqualFold(t.AdminEmail, c.AdminEmail):
+			return nil, fmt.Errorf("tenants file %s: tenant %q has the main tenant's admin_email", path, t.Name)
+		case t.State == "" && c.StateBackend != store.BackendFile:
+			return nil, fmt.Errorf("tenants file %s: tenant %q needs state with the %s backend", path, t.Name, c.StateBackend)
+		}
+		seen[t.Name] = true
+		loc := c.ForTenant(t).stateLocation()
+		if other, ok := states[loc]; ok {
+			return nil, fmt.Errorf("tenants file %s: tenant %q shares its state with %s", path, t.Name, other)
+		}
+		states[loc] = fmt.Sprintf("tenant %q", t.Name)
+	}
+	return file.Tenants, nil
+}
+
+// ForTenant returns a copy of c for the tenant t: its identities, internal
+// domains and state location replace the main tenant's, and the settings
+// that name the main tenant's own files, folders, calendars or exports are
+// cleared, so those features stay with the main tenant.
+func (c *Config) ForTenant(t TenantConfig) *Config {
+	tc := *c
+	tc.ReminderCalendar, tc.NoteLogSheet, tc.PolicySheetID = "", "", ""
+	tc.ArchiveFolder, tc.AttachmentFolder, tc.CleanupDocTo = "", "", nil
+	tc.ReportFolder, tc.ReportSchedule, tc.ReportTo = "", "", nil
+	tc.DriveTagLabel, tc.DriveTagField = "", ""
+	tc.PubSubTopic, tc.BQDataset, tc.FederationFile, tc.IndexPath = "", "", "", ""
+	if c.BackupDir != "" {
+		tc.BackupDir = filepath.Join(c.BackupDir, t.Name)
+	}
+	tc.PlanDir = filepath.Join(c.PlanDir, t.Name)
+	tc.AdminEmail, tc.UserEmail = t.AdminEmail, t.UserEmail
+	if t.ServiceAccountEmail != "" {
+		tc.ServiceAccountEmail = t.ServiceAccountEmail
+	}
+	tc.InternalDomains = t.InternalDomains
+	state := t.State
+	if state == "" {
+		ext := filepath.Ext(c.StateFile)
+		state = strings.TrimSuffix(c.StateFile, ext) + "." + t.Name + ext
+	}
+	switch c.StateBackend {
+	case store.BackendSQLite:
+		tc.SQLitePath = state
+	case store.BackendPostgres:
+		tc.PostgresDSN = state
+	case store.BackendRedis:
+		tc.RedisURL = state
+	default:
+		tc.StateFile = state
+	}
+	tc.TenantsFile = ""
+	return &tc
+}
+
+func (c *Config) stateLocation() string {
+	loc, _ := c.StateLocation()
+	return loc
+}
@@ -0,0 +1,333 @@
+/*
+File: internal/credentials/credentials.go
+Description: Service account key rotation. A Manager holds up to two base keys
+used to impersonate the Axis service account, or to sign tokens directly with
+the key strategy, health-checks each by minting a token, and switches every
+token source it handed out to another key in one atomic step, so a key can be
+rotated without a restart or an edited .env.
+*/
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// Key slot names.
+const (
+	SlotPrimary   = "primary"
+	SlotSecondary = "secondary"
+	// SlotDefault uses Application Default Credentials when no key is configured.
+	SlotDefault = "default"
+)
+
+const checkTimeout = 30 * time.Second
+
+var (
+	// ErrUnknownSlot reports a slot that is not configured.
+	ErrUnknownSlot = errors.New("unknown key slot")
+	// ErrUnhealthy reports a key that failed its health check.
+	ErrUnhealthy = errors.New("key failed its health check")
+)
+
+// KeyStatus describes one configured key.
+type KeyStatus struct {
+	Slot        string     `json:"slot"`
+	Path        string     `json:"path,omitempty"`
+	ClientEmail string     `json:"client_email,omitempty"`
+	KeyID       string     `json:"key_id,omitempty"`
+	Active      bool       `json:"active"`
+	Healthy     bool       `json:"healthy"`
+	Error       string     `json:"error,omitempty"`
+	Checked     *time.Time `json:"checked,omitempty"`
+}
+
+// key is one loaded key file. A reload replaces the whole value, so token
+// sources built from an older load are recognised and rebuilt.
+type key struct {
+	slot  string
+	path  string // empty for Application Default Credentials
+	json  []byte
+	email string
+	id    string
+}
+
+func (k *key) options() []option.ClientOption {
+	if k.json == nil {
+		return nil
+	}
+	return []option.ClientOption{option.WithAuthCredentialsJSON(option.ServiceAccount, k.json)}
+}
+
+type slot struct {
+	name string
+	path string
+	key  atomic.Pointer[key]
+}
+
+// Manager owns the base keys and which of them is active.
+type Manager struct {
+	check  impersonate.CredentialsConfig
+	direct bool // keys sign tokens themselves instead of impersonating
+	slots  []*slot
+	active atomic.Pointer[key]
+
+	mu     sync.Mutex // guards health and serializes Activate
+	health map[string]KeyStatus
+}
+
+// NewManager loads the primary and secondary key files; either may be empty.
+// With neither, the manager has a single default slot backed by Application
+// Default Credentials. check is the impersonation a health check performs.
+// The first key that passes its health check starts active, the primary when
+// both do.
+func NewManager(ctx context.Context, check impersonate.CredentialsConfig, primary, secondary string) (*Manager, error) {
+	return newManager(ctx, &Manager{check: check}, primary, secondary)
+}
+
+// NewKeyManager is NewManager for the key strategy: the key files are the
+// service account's own keys and sign tokens for the configurations' Subject
+// directly, so no Token Creator grant is needed. At least one key is required;
+// TargetPrincipal is ignored.
+func NewKeyManager(ctx context.Context, check impersonate.CredentialsConfig, primary, secondary string) (*Manager, error) {
+	if primary == "" && secondary == "" {
+		return nil, errors.New("the key strategy needs a service account key file")
+	}
+	return newManager(ctx, &Manager{check: check, direct: true}, primary, secondary)
+}
+
+func newManager(ctx context.Context, m *Manager, primary, secondary string) (*Manager, error) {
+	m.health = make(map[string]KeyStatus)
+	for _, s := range []*slot{{name: SlotPrimary, path: primary}, {name: SlotSecondary, path: secondary}} {
+		if s.path != "" {
+			m.slots = append(m.slots, s)
+		}
+	}
+	if len(m.slots) == 0 {
+		m.slots = []*slot{{name: SlotDefault}}
+	}
+	for _, s := range m.slots {
+		k, err := loadKey(s.name, s.path)
+		if err != nil {
+			return nil, err
+		}
+		s.key.Store(k)
+	}
+
+	m.active.Store(m.slots[0].key.Load())
+	for _, st := range m.Check(ctx) {
+		if st.Healthy {
+			m.active.Store(m.find(st.Slot).key.Load())
+			break
+		}
+	}
+	return m, nil
+}
+
+func loadKey(name, path string) (*key, error) {
+	k := &key{slot: name, path: path}
+	if path == "" {
+		return k, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s key: %w", name, err)
+	}
+	var f struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKeyID string `json:"private_key_id"`
+	}
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("invalid %s key %s: %w", name, path, err)
+	}
+	if f.Type != "service_account" {
+		return nil, fmt.Errorf("invalid %s key %s: type %q is not service_account", name, path, f.Type)
+	}
+	k.json, k.email, k.id = raw, f.ClientEmail, f.PrivateKeyID
+	return k, nil
+}
+
+func (m *Manager) find(name string) *slot {
+	for _, s := range m.slots {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Active names the slot in use.
+func (m *Manager) Active() string {
+	return m.active.Load().slot
+}
+
+// TokenSource impersonates cfg with whichever key is active when each token
+// is minted. Tokens already issued by the previous key stay cached only until
+// the switch; the next call mints from the new key.
+func (m *Manager) TokenSource(ctx context.Context, cfg impersonate.CredentialsConfig) (oauth2.TokenSource, error) {
+	ts := &switchSource{m: m, ctx: ctx, cfg: cfg, sources: make(map[string]keySource)}
+	// Fail at startup rather than on the first API call.
+	if _, err := ts.source(m.active.Load()); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+type keySource struct {
+	key *key
+	ts  oauth2.TokenSource
+}
+
+type switchSource struct {
+	m   *Manager
+	ctx context.Context
+	cfg impersonate.CredentialsConfig
+
+	mu      sync.Mutex
+	sources map[string]keySource // by slot
+}
+
+func (s *switchSource) Token() (*oauth2.Token, error) {
+	ts, err := s.source(s.m.active.Load())
+	if err != nil {
+		return nil, err
+	}
+	return ts.Token()
+}
+
+func (s *switchSource) source(k *key) (oauth2.TokenSource, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ks, ok := s.sources[k.slot]; ok && ks.key == k {
+		return ks.ts, nil
+	}
+	ts, err := s.m.mint(s.ctx, k, s.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to use %s key: %w", k.slot, err)
+	}
+	s.sources[k.slot] = keySource{key: k, ts: ts}
+	return ts, nil
+}
+
+// mint returns a token source for cfg backed by key k.
+func (m *Manager) mint(ctx context.Context, k *key, cfg impersonate.CredentialsConfig) (oauth2.TokenSource, error) {
+	if !m.direct {
+		return impersonate.CredentialsTokenSource(ctx, cfg, k.options()...)
+	}
+	jwt, err := google.JWTConfigFromJSON(k.json, cfg.Scopes...)
+	if err != nil {
+		return nil, err
+	}
+	jwt.Subject = cfg.Subject
+	return jwt.TokenSource(ctx), nil
+}
+
+// Check health-checks every key by minting a fresh token, rereading the key
+// files of inactive slots first so a key dropped in place is picked up.
+func (m *Manager) Check(ctx context.Context) []KeyStatus {
+	out := make([]KeyStatus, len(m.slots))
+	var wg sync.WaitGroup
+	for i, s := range m.slots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out[i] = m.checkSlot(ctx, s)
+		}()
+	}
+	wg.Wait()
+	m.mu.Lock()
+	for _, st := range out {
+		m.health[st.Slot] = st
+	}
+	m.mu.Unlock()
+	return m.Status()
+}
+
+func (m *Manager) checkSlot(ctx context.Context, s *slot) KeyStatus {
+	now := time.Now()
+	st := KeyStatus{Slot: s.name, Path: s.path, Checked: &now}
+	k := s.key.Load()
+	if k != m.active.Load() {
+		loaded, err := loadKey(s.name, s.path)
+		if err != nil {
+			st.Error = err.Error()
+			return st
+		}
+		s.key.Store(loaded)
+		k = loaded
+	}
+	st.ClientEmail, st.KeyID = k.email, k.id
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	ts, err := m.mint(ctx, k, m.check)
+	if err == nil {
+		_, err = ts.Token()
+	}
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	st.Healthy = true
+	return st
+}
+
+// CheckActive health-checks only the active key.
+func (m *Manager) CheckActive(ctx context.Context) KeyStatus {
+	s := m.find(m.Active())
+	st := m.checkSlot(ctx, s)
+	m.mu.Lock()
+	m.health[s.name] = st
+	m.mu.Unlock()
+	st.Active = true
+	return st
+}
+
+// Status reports every key as of its last health check.
+func (m *Manager) Status() []KeyStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	active := m.Active()
+	out := make([]KeyStatus, 0, len(m.slots))
+	for _, s := range m.slots {
+		st, ok := m.health[s.name]
+		if !ok {
+			k := s.key.Load()
+			st = KeyStatus{Slot: s.name, Path: s.path, ClientEmail: k.email, KeyID: k.id}
+		}
+		st.Active = s.name == active
+		out = append(out, st)
+	}
+	return out
+}
+
+// Activate health-checks a slot's key and, if it passes, makes it the active
+// key for every token source at once. Activating the active slot only checks it.
+func (m *Manager) Activate(ctx context.Context, name string) (KeyStatus, error) {
+	s := m.find(name)
+	if s == nil {
+		return KeyStatus{}, fmt.Errorf("%w: %q", ErrUnknownSlot, name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.checkSlot(ctx, s)
+	m.health[name] = st
+	if !st.Healthy {
+		return st, fmt.Errorf("%w: %s: %s", ErrUnhealthy, name, st.Error)
+	}
+	m.active.Store(s.key.Load())
+	st.Active = true
+	return st, nil
+}
@@ -0,0 +1,146 @@
+/*
+File: internal/credentials/monitor.go
+Description: Token refresh monitoring. A Monitor wraps the long-lived token
+sources of the Google API clients, records when each last minted a token and
+whether its last attempt failed (revoked delegation, an expired or deleted
+key, a lapsed OAuth grant), and reports changes between healthy and failing.
+*/
+package credentials
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenStatus is the refresh health of one monitored token source.
+type TokenStatus struct {
+	Name    string   `json:"name"`
+	Subject string   `json:"subject,omitempty"`
+	Scopes  []string `json:"scopes"`
+	Healthy bool     `json:"healthy"`
+	Error   string   `json:"error,omitempty"`
+	// Since is when the source entered its current state.
+	Since time.Time `json:"since"`
+	// LastRefresh is when a new token was last minted; Expiry is its expiry.
+	LastRefresh *time.Time `json:"last_refresh,omitempty"`
+	Expiry      *time.Time `json:"expiry,omitempty"`
+	Checked     *time.Time `json:"checked,omitempty"`
+}
+
+type watched struct {
+	ts     oauth2.TokenSource
+	status TokenStatus
+	last   string // access token last seen
+}
+
+// Monitor tracks the token sources it wraps.
+type Monitor struct {
+	strategy string
+
+	mu       sync.Mutex
+	sources  []*watched
+	onChange func(TokenStatus)
+}
+
+// NewMonitor returns a monitor for sources built with strategy.
+func NewMonitor(strategy string) *Monitor {
+	return &Monitor{strategy: strategy}
+}
+
+// Strategy names the credential strategy of the monitored sources.
+func (m *Monitor) Strategy() string {
+	return m.strategy
+}
+
+// OnChange sets the function told about every switch between healthy and
+// failing. It is called without the monitor's lock held.
+func (m *Monitor) OnChange(fn func(TokenStatus)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onChange = fn
+}
+
+// Watch returns ts recording every Token call under name. Watching a name
+// again replaces the earlier source.
+func (m *Monitor) Watch(name, subject string, scopes []string, ts oauth2.TokenSource) oauth2.TokenSource {
+	w := &watched{ts: ts, status: TokenStatus{Name: name, Subject: subject, Scopes: slices.Clone(scopes), Healthy: true, Since: time.Now()}}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sources = slices.DeleteFunc(m.sources, func(o *watched) bool { return o.status.Name == name })
+	m.sources = append(m.sources, w)
+	return &watchedSource{m: m, w: w}
+}
+
+type watchedSource struct {
+	m *Monitor
+	w *watched
+}
+
+func (s *watchedSource) Token() (*oauth2.Token, error) {
+	tok, err := s.w.ts.Token()
+	s.m.record(s.w, tok, err)
+	return tok, err
+}
+
+func (m *Monitor) record(w *watched, tok *oauth2.Token, err error) {
+	now := time.Now()
+	m.mu.Lock()
+	st := &w.status
+	st.Checked = &now
+	changed := false
+	if err != nil {
+		changed = st.Healthy
+		st.Healthy, st.Error = false, err.Error()
+	} else {
+		changed = !st.Healthy
+		st.Healthy, st.Error = true, ""
+		if tok.AccessToken != w.last {
+			w.last = tok.AccessToken
+			st.LastRefresh = &now
+			if !tok.Expiry.IsZero() {
+				expiry := tok.Expiry
+				st.Expiry = &expiry
+			}
+		}
+	}
+	if changed {
+		st.Since = now
+	}
+	out, notify := *st, m.onChange
+	m.mu.Unlock()
+	if changed && notify != nil {
+		notify(out)
+	}
+}
+
+// Check asks every source for a token. Sources hand out their cached token
+// until it nears expiry, so a failing refresh is caught by the first check
+// after it, whether or not any API call needed one.
+func (m *Monitor) Check(ctx context.Context) []TokenStatus {
+	m.mu.Lock()
+	sources := slices.Clone(m.sources)
+	m.mu.Unlock()
+	for _, w := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+		tok, err := w.ts.Token()
+		m.record(w, tok, err)
+	}
+	return m.Status()
+}
+
+// Status reports every source as of its last Token call.
+func (m *Monitor) Status() []TokenStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]TokenStatus, len(m.sources))
+	for i, w := range m.sources {
+		out[i] = w.status
+	}
+	return out
+}
@@ -0,0 +1,206 @@
+/*
+File: internal/credentials/oauth.go
+Description: OAuth browser flow for the oauth strategy. `axis auth login` opens
+Google's consent page, receives the code on a loopback listener and caches the
+refresh token with the granted scopes; the server reuses and refreshes it, so
+Axis acts as the signed-in user without any service account.
+*/
+package credentials
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// OAuth holds an OAuth client and the token cached for it.
+type OAuth struct {
+	config *oauth2.Config
+	cache  string
+
+	mu     sync.Mutex
+	cached *oauthCache
+	ts     oauth2.TokenSource // shared by every caller once built
+}
+
+// oauthCache is the token cache file.
+type oauthCache struct {
+	ClientID string        `json:"client_id"`
+	Scopes   []string      `json:"scopes"`
+	Token    *oauth2.Token `json:"token"`
+}
+
+// LoadOAuth reads a desktop OAuth client file downloaded from the Cloud
+// console and the token cached at cacheFile, if any. A token cached for
+// another client is ignored.
+func LoadOAuth(clientFile, cacheFile string) (*OAuth, error) {
+	raw, err := os.ReadFile(clientFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read OAuth client file: %w", err)
+	}
+	cfg, err := google.ConfigFromJSON(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OAuth client file %s: %w", clientFile, err)
+	}
+	o := &OAuth{config: cfg, cache: cacheFile}
+	data, err := os.ReadFile(cacheFile)
+	switch {
+	case os.IsNotExist(err):
+		return o, nil
+	case err != nil:
+		return nil, fmt.Errorf("unable to read OAuth token cache: %w", err)
+	}
+	var c oauthCache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("corrupt OAuth token cache %s: %w", cacheFile, err)
+	}
+	if c.ClientID == cfg.ClientID && c.Token != nil {
+		o.cached = &c
+	}
+	return o, nil
+}
+
+// Login runs the browser flow for scopes and caches the token. prompt shows
+// the consent URL; the flow waits until the browser returns or ctx ends.
+func (o *OAuth) Login(ctx context.Context, scopes []string, prompt func(authURL string)) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("unable to listen for the OAuth redirect: %w", err)
+	}
+	defer ln.Close()
+	cfg := *o.config
+	cfg.Scopes = scopes
+	cfg.RedirectURL = "http://" + ln.Addr().String() + "/"
+
+	var b [16]byte
+	rand.Read(b[:])
+	state := hex.EncodeToString(b[:])
+	verifier := oauth2.GenerateVerifier()
+
+	codes := make(chan string, 1)
+	fails := make(chan error, 1)
+	srv := &http.Server{ReadHeaderTimeout: 10 * time.Second, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/" || q.Get("state") != state {
+			http.NotFound(w, r)
+			return
+		}
+		if reason := q.Get("error"); reason != "" {
+			select {
+			case fails <- fmt.Errorf("authorization refused: %s", reason):
+			default:
+			}
+			fmt.Fprintln(w, "Authorization was refused. Return to the terminal.")
+			return
+		}
+		select {
+		case codes <- q.Get("code"):
+		default:
+		}
+		fmt.Fprintln(w, "Axis is signed in. You can close this tab.")
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	prompt(cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.ApprovalForce, oauth2.S256ChallengeOption(verifier)))
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-fails:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("no OAuth redirect received: %w", ctx.Err())
+	}
+	tok, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return fmt.Errorf("unable to exchange the OAuth code: %w", err)
+	}
+	if tok.RefreshToken == "" {
+		return errors.New("Google returned no refresh token; revoke Axis's access in the Google account and sign in again")
+	}
+	granted := scopes
+	if s, ok := tok.Extra("scope").(string); ok && s != "" {
+		granted = strings.Fields(s)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cached = &oauthCache{ClientID: cfg.ClientID, Scopes: granted, Token: tok}
+	o.ts = nil
+	return o.saveLocked()
+}
+
+// TokenSource returns the cached token, refreshed as needed, once every scope
+// asked for was granted. Refreshed tokens are written back to the cache.
+func (o *OAuth) TokenSource(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.cached == nil {
+		return nil, errors.New("no OAuth token is cached; run `axis auth login`")
+	}
+	for _, s := range scopes {
+		if !covers(o.cached.Scopes, s) {
+			return nil, fmt.Errorf("the cached OAuth token was not granted %s; run `axis auth login` again", s)
+		}
+	}
+	if o.ts == nil {
+		cfg := *o.config
+		cfg.Scopes = o.cached.Scopes
+		// The refresh must outlive the request that happened to trigger it.
+		o.ts = &cachingSource{o: o, ts: cfg.TokenSource(context.WithoutCancel(ctx), o.cached.Token), last: o.cached.Token.AccessToken}
+	}
+	return o.ts, nil
+}
+
+func (o *OAuth) saveLocked() error {
+	data, err := json.MarshalIndent(o.cached, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.cache, data, 0600); err != nil {
+		return fmt.Errorf("unable to write OAuth token cache: %w", err)
+	}
+	return nil
+}
+
+// cachingSource persists each new access token so a restart does not refresh
+// needlessly.
+type cachingSource struct {
+	o  *OAuth
+	ts oauth2.TokenSource
+
+	mu   sync.Mutex
+	last string
+}
+
+func (c *cachingSource) Token() (*oauth2.Token, error) {
+	tok, err := c.ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if tok.AccessToken != c.last {
+		c.last = tok.AccessToken
+		c.o.mu.Lock()
+		if c.o.cached != nil {
+			c.o.cached.Token = tok
+			// A failed write only costs a refresh after the next restart.
+			c.o.saveLocked()
+		}
+		c.o.mu.Unlock()
+	}
+	return tok, nil
+}
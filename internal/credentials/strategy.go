@@ -0,0 +1,65 @@
+/*
+File: internal/credentials/strategy.go
+Description: Credential strategies. Impersonation and service account keys act
+as domain users through Domain-Wide Delegation; Application Default
+Credentials and the OAuth browser flow act as one fixed identity, so Axis runs
+where no delegation grant exists.
+*/
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Strategy names.
+const (
+	// StrategyImpersonate impersonates the service account from base keys or
+	// Application Default Credentials, then acts as domain users (default).
+	StrategyImpersonate = "impersonate"
+	// StrategyKey signs tokens for domain users with the service account's key files.
+	StrategyKey = "key"
+	// StrategyADC uses Application Default Credentials as they are.
+	StrategyADC = "adc"
+	// StrategyOAuth uses a token cached by the OAuth browser flow.
+	StrategyOAuth = "oauth"
+)
+
+// Strategies lists the strategy names in documentation order.
+var Strategies = []string{StrategyImpersonate, StrategyKey, StrategyADC, StrategyOAuth}
+
+// ErrNoDelegation reports a strategy that cannot act as other users.
+var ErrNoDelegation = errors.New("credential strategy cannot act as other users")
+
+// Delegates reports whether a strategy can act as any domain user; the others
+// act only as their own identity.
+func Delegates(strategy string) bool {
+	return strategy == "" || strategy == StrategyImpersonate || strategy == StrategyKey
+}
+
+// DefaultTokenSource returns Application Default Credentials for scopes. User
+// credentials from `gcloud auth application-default login` carry the scopes
+// they were created with, whatever is asked for here.
+func DefaultTokenSource(ctx context.Context, scopes ...string) (oauth2.TokenSource, error) {
+	creds, err := google.FindDefaultCredentials(ctx, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find Application Default Credentials: %w", err)
+	}
+	return creds.TokenSource, nil
+}
+
+// covers reports whether the granted scopes include want. A read-write scope
+// covers its read-only form.
+func covers(granted []string, want string) bool {
+	for _, g := range granted {
+		if g == want || g == strings.TrimSuffix(want, ".readonly") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+/*
+File: internal/events/events.go
+Description: Axis event envelope and asynchronous emitter. Operational events
+(deletions, mode changes, status updates, playbook runs) are queued and fanned out
+to external publishers such as Google Pub/Sub. The schema is documented in
+docs/events.md.
+*/
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+)
+
+// SchemaVersion is bumped on incompatible envelope changes.
+const SchemaVersion = "1"
+
+// Event types.
+const (
+	TypeItemDeleted          = "item.deleted"
+	TypeItemCreated          = "item.created"
+	TypeModeChanged          = "mode.changed"
+	TypeStatusChanged        = "status.changed"
+	TypeTagsChanged          = "tags.changed"
+	TypePlaybookCompleted    = "playbook.completed"
+	TypeItemWouldDelete      = "item.would_delete"
+	TypeReviewCreated        = "review.created"
+	TypeRuleMatched          = "rule.matched"
+	TypeUserRuleMatched      = "user_rule.matched"
+	TypeUserUpdated          = "user.updated"
+	TypeSheetUpdated         = "sheet.updated"
+	TypeSheetWouldUpdate     = "sheet.would_update"
+	TypeDocUpdated           = "doc.updated"
+	TypeDocWouldUpdate       = "doc.would_update"
+	TypePlanProposed         = "plan.proposed"
+	TypePlanExported         = "plan.exported"
+	TypeCredentialsActivated = "credentials.activated"
+	TypeBudgetExhausted      = "budget.exhausted"
+	TypeCommentAdded         = "comment.added"
+	TypeAuthDegraded         = "auth.degraded"
+	TypeAuthRestored         = "auth.restored"
+	TypeDeletionScheduled    = "deletion.scheduled"
+	TypeDeletionCanceled     = "deletion.canceled"
+	TypeTokenCreated         = "token.created"
+	TypeTokenRevoked         = "token.revoked"
+	TypeItemArchived         = "item.archived"
+	TypeItemWouldArchive     = "item.would_archive"
+	TypeCleanupDocCreated    = "cleanup_doc.created"
+	TypeNoteConverted        = "note.converted"
+	TypeNoteUpdated          = "note.updated"
+	TypeNoteWouldUpdate      = "note.would_update"
+	TypeAttachmentsSynced    = "attachments.synced"
+	TypeFileUploaded         = "file.uploaded"
+	TypePollerPaused         = "poller.paused"
+	TypePollerResumed        = "poller.resumed"
+	TypePollerTriggered      = "poller.triggered"
+	TypeSubjectAccessed      = "subject.accessed"
+	TypeJobFinished          = "job.finished"
+	TypeReportCreated        = "report.created"
+	TypeStateRecovered       = "state.recovered"
+	TypeStateDiscarded       = "state.discarded"
+	TypeStatusesPruned       = "statuses.pruned"
+)
+
+const queueSize = 256
+
+// Event is the envelope delivered to every publisher.
+type Event struct {
+	ID      string         `json:"id"`
+	Type    string         `json:"type"`
+	Time    time.Time      `json:"time"`
+	Source  string         `json:"source"`
+	Actor   string         `json:"actor,omitempty"`
+	Subject string         `json:"subject,omitempty"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// Publisher delivers events to an external system.
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Emitter queues events and delivers them to publishers off the request path.
+type Emitter struct {
+	publishers []Publisher
+	queue      chan Event
+	logger     *slog.Logger
+}
+
+// NewEmitter creates an emitter for the supplied publishers.
+func NewEmitter(logger *slog.Logger, publishers ...Publisher) *Emitter {
+	return &Emitter{
+		publishers: publishers,
+		queue:      make(chan Event, queueSize),
+		logger:     logger,
+	}
+}
+
+// Emit stamps the event and enqueues it. It never blocks; when the queue is
+// full the event is dropped and logged.
+func (em *Emitter) Emit(e Event) {
+	if len(em.publishers) == 0 {
+		return
+	}
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.Source == "" {
+		e.Source = "axis"
+	}
+	select {
+	case em.queue <- e:
+	default:
+		em.logger.Warn("event queue full, dropping event", "type", e.Type, "id", e.ID)
+	}
+}
+
+// Run delivers queued events until ctx is cancelled, then drains what remains.
+func (em *Emitter) Run(ctx context.Context) {
+	for {
+		select {
+		case e := <-em.queue:
+			em.deliver(ctx, e)
+		case <-ctx.Done():
+			for {
+				select {
+				case e := <-em.queue:
+					em.deliver(context.Background(), e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (em *Emitter) deliver(ctx context.Context, e Event) {
+	for _, p := range em.publishers {
+		if err := p.Publish(ctx, e); err != nil {
+			em.logger.Error("event publish failed", "type", e.Type, "id", e.ID, "error", err)
+		}
+	}
+}
+
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
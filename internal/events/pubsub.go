@@ -0,0 +1,47 @@
+/*
+File: internal/events/pubsub.go
+Description: Google Pub/Sub publisher. Each event is published as one JSON message
+with type and schema version attributes for subscription filtering.
+*/
+package events
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// PubSubPublisher publishes events to a single topic.
+type PubSubPublisher struct {
+	svc   *pubsub.Service
+	topic string
+}
+
+// NewPubSubPublisher targets a topic in "projects/{project}/topics/{topic}" form.
+func NewPubSubPublisher(svc *pubsub.Service, topic string) *PubSubPublisher {
+	return &PubSubPublisher{svc: svc, topic: topic}
+}
+
+// Publish sends the event to the topic.
+func (p *PubSubPublisher) Publish(ctx context.Context, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("unable to marshal event %s: %w", e.ID, err)
+	}
+	_, err = p.svc.Projects.Topics.Publish(p.topic, &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{{
+			Data: base64.StdEncoding.EncodeToString(data),
+			Attributes: map[string]string{
+				"type":           e.Type,
+				"schema_version": SchemaVersion,
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to publish event %s to %s: %w", e.ID, p.topic, err)
+	}
+	return nil
+}
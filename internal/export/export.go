@@ -0,0 +1,207 @@
+/*
+File: internal/export/export.go
+Description: Export subsystem for Keep notes. Converts notes (text, nested checklists,
+attachments) into Markdown or HTML documents plus an assets folder, written through
+a Sink so the same bundle can land on disk or inside a zip archive.
+*/
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+// Format selects the document renderer.
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+)
+
+const assetsDir = "assets"
+
+// ParseFormat validates a user supplied format name.
+func ParseFormat(raw string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (want md or html)", raw)
+	}
+}
+
+// Source is the subset of workspace.Service the exporter reads from.
+type Source interface {
+	ListAllKeepNotes(ctx context.Context, opts workspace.ListNotesOptions) ([]*keepapi.Note, error)
+	OpenAttachmentMedia(ctx context.Context, attachmentName, mimeType string) (*workspace.AttachmentMedia, error)
+}
+
+// Summary reports what an export run produced.
+type Summary struct {
+	Notes       int      `json:"notes"`
+	Docs        int      `json:"docs,omitempty"`
+	Attachments int      `json:"attachments"`
+	Failed      []string `json:"failed,omitempty"`
+}
+
+// Exporter renders notes from a Source into a Sink.
+type Exporter struct {
+	src    Source
+	format Format
+}
+
+// New creates an exporter for the given format.
+func New(src Source, format Format) *Exporter {
+	return &Exporter{src: src, format: format}
+}
+
+// Export writes every non-trashed note, its attachments, and an index to the sink.
+// Attachment failures are recorded in the summary rather than aborting the run.
+func (e *Exporter) Export(ctx context.Context, sink Sink) (Summary, error) {
+	var sum Summary
+	notes, err := e.src.ListAllKeepNotes(ctx, workspace.ListNotesOptions{Filter: "trashed = false"})
+	if err != nil {
+		return sum, err
+	}
+
+	var entries []indexEntry
+	used := make(map[string]bool)
+	for _, note := range notes {
+		if note == nil || note.Trashed {
+			continue
+		}
+		doc := noteDoc{
+			ID:    note.Name,
+			Title: noteTitle(note),
+			Body:  note.Body,
+		}
+		for _, att := range note.Attachments {
+			asset, err := e.exportAttachment(ctx, sink, att)
+			if err != nil {
+				sum.Failed = append(sum.Failed, fmt.Sprintf("%s: %v", att.Name, err))
+				continue
+			}
+			doc.Assets = append(doc.Assets, asset)
+			sum.Attachments++
+		}
+
+		file := uniqueName(fileStem(doc.Title, note.Name), string(e.format), used)
+		if err := sink.Put(ctx, file, strings.NewReader(e.render(doc))); err != nil {
+			return sum, fmt.Errorf("unable to write %s: %w", file, err)
+		}
+		entries = append(entries, indexEntry{Title: doc.Title, File: file})
+		sum.Notes++
+	}
+
+	index := "index." + string(e.format)
+	if err := sink.Put(ctx, index, strings.NewReader(e.renderIndex(entries, time.Now()))); err != nil {
+		return sum, fmt.Errorf("unable to write %s: %w", index, err)
+	}
+	return sum, nil
+}
+
+func (e *Exporter) exportAttachment(ctx context.Context, sink Sink, att *keepapi.Attachment) (asset, error) {
+	if att == nil || att.Name == "" {
+		return asset{}, fmt.Errorf("attachment has no name")
+	}
+	mimeType := ""
+	if len(att.MimeType) > 0 {
+		mimeType = att.MimeType[0]
+	}
+	media, err := e.src.OpenAttachmentMedia(ctx, att.Name, mimeType)
+	if err != nil {
+		return asset{}, err
+	}
+	defer media.Body.Close()
+
+	contentType := media.ContentType
+	if contentType == "" {
+		contentType = mimeType
+	}
+	// Attachment IDs are only unique per note, so prefix with the note ID.
+	noteID := path.Base(path.Dir(path.Dir(att.Name)))
+	file := path.Join(assetsDir, noteID+"-"+workspace.AttachmentFilename(att.Name, contentType))
+	if err := sink.Put(ctx, file, media.Body); err != nil {
+		return asset{}, err
+	}
+	return asset{Path: file, Image: strings.HasPrefix(contentType, "image/")}, nil
+}
+
+func (e *Exporter) render(doc noteDoc) string {
+	if e.format == FormatHTML {
+		return renderHTML(doc)
+	}
+	return renderMarkdown(doc)
+}
+
+func (e *Exporter) renderIndex(entries []indexEntry, at time.Time) string {
+	if e.format == FormatHTML {
+		return renderHTMLIndex(entries, at)
+	}
+	return renderMarkdownIndex(entries, at)
+}
+
+type noteDoc struct {
+	ID     string
+	Title  string
+	Body   *keepapi.Section
+	Assets []asset
+	Index  string // back link to the index page, when rendered as part of a site
+}
+
+type asset struct {
+	Path  string
+	Image bool
+}
+
+type indexEntry struct {
+	Title string
+	File  string
+}
+
+func noteTitle(note *keepapi.Note) string {
+	t := strings.TrimSpace(note.Title)
+	if t == "" {
+		return "Untitled"
+	}
+	return t
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+func fileStem(title, name string) string {
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > 60 {
+		slug = strings.TrimRight(slug[:60], "-")
+	}
+	if slug == "" {
+		slug = "note"
+	}
+	return slug + "-" + path.Base(name)
+}
+
+func uniqueName(stem, ext string, used map[string]bool) string {
+	name := stem + "." + ext
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d.%s", stem, i, ext)
+	}
+	used[name] = true
+	return name
+}
+
+// Sink receives exported files by slash-separated relative path.
+type Sink interface {
+	Put(ctx context.Context, name string, r io.Reader) error
+}
@@ -0,0 +1,57 @@
+/*
+File: internal/export/gcs.go
+Description: Cloud Storage sink. Uploads export files as objects under a bucket
+prefix so a bundle or static site can be served or archived from object storage.
+*/
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	storage "google.golang.org/api/storage/v1"
+)
+
+// GCSSink writes exported files as objects in a Cloud Storage bucket.
+type GCSSink struct {
+	svc    *storage.Service
+	bucket string
+	prefix string
+}
+
+// NewGCSSink uploads to bucket, naming objects prefix/<file>.
+func NewGCSSink(svc *storage.Service, bucket, prefix string) *GCSSink {
+	return &GCSSink{svc: svc, bucket: bucket, prefix: strings.Trim(prefix, "/")}
+}
+
+// ParseGCSURL splits a gs://bucket/prefix destination.
+func ParseGCSURL(raw string) (bucket, prefix string, ok bool) {
+	rest, found := strings.CutPrefix(raw, "gs://")
+	if !found {
+		return "", "", false
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	return bucket, strings.Trim(prefix, "/"), bucket != ""
+}
+
+// Put uploads a file, setting the content type from its extension.
+func (g *GCSSink) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if strings.HasPrefix(name, "/") || strings.Contains("/"+name+"/", "/../") {
+		return fmt.Errorf("invalid export path %q", name)
+	}
+	obj := &storage.Object{Name: path.Join(g.prefix, name)}
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		obj.ContentType = ct
+	}
+	if _, err := g.svc.Objects.Insert(g.bucket, obj).Media(r).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to upload gs://%s/%s: %w", g.bucket, obj.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+File: internal/export/registry.go
+Description: Registry spreadsheets. Writes registry items, one per row with
+their status, timestamps and owner, as CSV or as a single-sheet Excel workbook.
+The workbook is put together by hand: inline strings and plain numbers need no
+shared string table or styles, which keeps it small enough to stream.
+*/
+package export
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// TableFormat selects the registry spreadsheet encoding.
+type TableFormat string
+
+const (
+	TableCSV  TableFormat = "csv"
+	TableXLSX TableFormat = "xlsx"
+)
+
+// ParseTableFormat validates a user supplied spreadsheet format name.
+func ParseTableFormat(raw string) (TableFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "csv":
+		return TableCSV, nil
+	case "xlsx", "excel":
+		return TableXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported registry export format %q (want csv or xlsx)", raw)
+	}
+}
+
+// ContentType is the MIME type of the format.
+func (f TableFormat) ContentType() string {
+	if f == TableXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// registryColumns heads the registry spreadsheet.
+var registryColumns = []string{
+	"id", "type", "title", "status", "owner", "created", "modified", "delete_at",
+	"size", "protected", "trashed", "tags", "link",
+}
+
+// registryRow renders an item under registryColumns. Size is a number; the
+// rest are text, with times in RFC 3339.
+func registryRow(item workspace.RegistryItem) []any {
+	when := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	var size any = ""
+	if item.Size > 0 {
+		size = item.Size
+	}
+	return []any{
+		item.ID, item.Type, item.Title, item.Status, item.Owner,
+		when(item.Created), when(item.Modified), when(item.DeleteAt),
+		size, strconv.FormatBool(item.Protected), strconv.FormatBool(item.Trashed),
+		strings.Join(item.Tags, " "), item.WebLink,
+	}
+}
+
+// WriteRegistry writes items to w in format.
+func WriteRegistry(w io.Writer, format TableFormat, items []workspace.RegistryItem) error {
+	if format == TableXLSX {
+		return writeRegistryXLSX(w, items)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(registryColumns); err != nil {
+		return err
+	}
+	record := make([]string, len(registryColumns))
+	for _, item := range items {
+		for i, v := range registryRow(item) {
+			record[i] = fmt.Sprint(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// xlsxParts are the fixed parts of a one-sheet workbook.
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Registry" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func writeRegistryXLSX(w io.Writer, items []workspace.RegistryItem) error {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+	header := make([]any, len(registryColumns))
+	for i, c := range registryColumns {
+		header[i] = c
+	}
+	if err := writeXLSXRow(f, header); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := writeXLSXRow(f, registryRow(item)); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(f, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeXLSXRow writes one row: integers as numbers, everything else as inline
+// strings.
+func writeXLSXRow(w io.Writer, cells []any) error {
+	var b strings.Builder
+	b.WriteString("<row>")
+	for _, v := range cells {
+		switch v := v.(type) {
+		case int64:
+			fmt.Fprintf(&b, `<c><v>%d</v></c>`, v)
+		default:
+			s := fmt.Sprint(v)
+			if s == "" {
+				b.WriteString("<c/>")
+				continue
+			}
+			b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			xml.EscapeText(&b, []byte(s))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString("</row>")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+File: internal/export/render.go
+Description: Markdown and HTML renderers for exported notes and the bundle index.
+*/
+package export
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"strings"
+	"time"
+
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+func renderMarkdown(doc noteDoc) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", doc.Title)
+	if doc.Body != nil {
+		if doc.Body.Text != nil && doc.Body.Text.Text != "" {
+			b.WriteString(doc.Body.Text.Text)
+			b.WriteString("\n\n")
+		}
+		if doc.Body.List != nil {
+			writeMarkdownList(&b, doc.Body.List.ListItems, 0)
+			b.WriteString("\n")
+		}
+	}
+	for _, a := range doc.Assets {
+		name := path.Base(a.Path)
+		if a.Image {
+			fmt.Fprintf(&b, "![%s](%s)\n\n", name, a.Path)
+		} else {
+			fmt.Fprintf(&b, "[%s](%s)\n\n", name, a.Path)
+		}
+	}
+	return b.String()
+}
+
+func writeMarkdownList(b *strings.Builder, items []*keepapi.ListItem, depth int) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		mark := " "
+		if item.Checked {
+			mark = "x"
+		}
+		fmt.Fprintf(b, "%s- [%s] %s\n", strings.Repeat("  ", depth), mark, listItemText(item))
+		writeMarkdownList(b, item.ChildListItems, depth+1)
+	}
+}
+
+func renderMarkdownIndex(entries []indexEntry, at time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Keep Export\n\nGenerated %s.\n\n", at.UTC().Format(time.RFC3339))
+	for _, e := range entries {
+		fmt.Fprintf(&b, "- [%s](%s)\n", e.Title, e.File)
+	}
+	return b.String()
+}
+
+func renderHTML(doc noteDoc) string {
+	var b strings.Builder
+	title := html.EscapeString(doc.Title)
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head><body>\n", title)
+	if doc.Index != "" {
+		fmt.Fprintf(&b, "<p><a href=\"%s\">&larr; Index</a></p>\n", html.EscapeString(doc.Index))
+	}
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", title)
+	if doc.Body != nil {
+		if doc.Body.Text != nil && doc.Body.Text.Text != "" {
+			for _, para := range strings.Split(doc.Body.Text.Text, "\n\n") {
+				fmt.Fprintf(&b, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+			}
+		}
+		if doc.Body.List != nil {
+			writeHTMLList(&b, doc.Body.List.ListItems)
+		}
+	}
+	for _, a := range doc.Assets {
+		src := html.EscapeString(a.Path)
+		if a.Image {
+			fmt.Fprintf(&b, "<p><img src=\"%s\" alt=\"%s\"></p>\n", src, html.EscapeString(path.Base(a.Path)))
+		} else {
+			fmt.Fprintf(&b, "<p><a href=\"%s\">%s</a></p>\n", src, html.EscapeString(path.Base(a.Path)))
+		}
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+func writeHTMLList(b *strings.Builder, items []*keepapi.ListItem) {
+	if len(items) == 0 {
+		return
+	}
+	b.WriteString("<ul>\n")
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		checked := ""
+		if item.Checked {
+			checked = " checked"
+		}
+		fmt.Fprintf(b, "<li><input type=\"checkbox\" disabled%s> %s", checked, html.EscapeString(listItemText(item)))
+		writeHTMLList(b, item.ChildListItems)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</ul>\n")
+}
+
+func renderHTMLIndex(entries []indexEntry, at time.Time) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Keep Export</title></head><body>\n")
+	fmt.Fprintf(&b, "<h1>Keep Export</h1>\n<p>Generated %s.</p>\n<ul>\n", at.UTC().Format(time.RFC3339))
+	for _, e := range entries {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(e.File), html.EscapeString(e.Title))
+	}
+	b.WriteString("</ul>\n</body></html>\n")
+	return b.String()
+}
+
+func listItemText(item *keepapi.ListItem) string {
+	if item.Text == nil {
+		return ""
+	}
+	return item.Text.Text
+}
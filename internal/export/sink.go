@@ -0,0 +1,88 @@
+/*
+File: internal/export/sink.go
+Description: Export sinks. DirSink writes the bundle under a local directory and
+ZipSink streams it into a zip archive (used by the HTTP export endpoint).
+*/
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSink writes exported files beneath a root directory.
+type DirSink struct {
+	root string
+}
+
+// NewDirSink creates the root directory if needed.
+func NewDirSink(root string) (*DirSink, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create export dir %s: %w", root, err)
+	}
+	return &DirSink{root: root}, nil
+}
+
+// Put writes a file, creating parent directories as necessary.
+func (d *DirSink) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	target, err := safeJoin(d.root, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ZipSink writes exported files as entries of a zip archive.
+type ZipSink struct {
+	zw *zip.Writer
+}
+
+// NewZipSink wraps w; Close must be called to finish the archive.
+func NewZipSink(w io.Writer) *ZipSink {
+	return &ZipSink{zw: zip.NewWriter(w)}
+}
+
+// Put adds a file entry to the archive.
+func (z *ZipSink) Put(ctx context.Context, name string, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w, err := z.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// Close writes the zip central directory.
+func (z *ZipSink) Close() error {
+	return z.zw.Close()
+}
+
+func safeJoin(root, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid export path %q", name)
+	}
+	return filepath.Join(root, clean), nil
+}
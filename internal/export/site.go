@@ -0,0 +1,337 @@
+/*
+File: internal/export/site.go
+Description: Static site export. Renders a selected set of Docs and Keep notes into
+a self-contained HTML site (index, one page per item, attachments and inline
+images) written through any Sink, including Cloud Storage.
+*/
+package export
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+
+	docs "google.golang.org/api/docs/v1"
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+const siteImageTimeout = time.Minute
+
+// SiteSource is the subset of workspace.Service the site exporter reads from.
+type SiteSource interface {
+	Source
+	ListRegistryItems() ([]workspace.RegistryItem, error)
+	GetNote(ctx context.Context, noteID string) (*keepapi.Note, error)
+	GetDoc(ctx context.Context, documentId string) (*docs.Document, error)
+}
+
+// Selection picks the items to publish. Explicit IDs win; otherwise every Doc
+// and note whose title contains TitleContains (case-insensitive) is included.
+type Selection struct {
+	IDs           []string
+	TitleContains string
+}
+
+// Site renders Docs and notes into a static HTML site.
+type Site struct {
+	src    SiteSource
+	notes  *Exporter
+	client *http.Client
+}
+
+// NewSite creates a site exporter.
+func NewSite(src SiteSource) *Site {
+	return &Site{
+		src:    src,
+		notes:  New(src, FormatHTML),
+		client: &http.Client{Timeout: siteImageTimeout},
+	}
+}
+
+// Export writes the selected items, their assets, and index.html to the sink.
+// Items that cannot be fetched are recorded in the summary; sink failures abort.
+func (s *Site) Export(ctx context.Context, sink Sink, sel Selection) (Summary, error) {
+	var sum Summary
+	items, err := s.resolve(sel)
+	if err != nil {
+		return sum, err
+	}
+
+	var docEntries, noteEntries []indexEntry
+	used := make(map[string]bool)
+	for _, item := range items {
+		switch item.Type {
+		case "keep":
+			note, err := s.src.GetNote(ctx, item.ID)
+			if err != nil {
+				sum.Failed = append(sum.Failed, fmt.Sprintf("%s: %v", item.ID, err))
+				continue
+			}
+			page := noteDoc{ID: note.Name, Title: noteTitle(note), Body: note.Body, Index: "index.html"}
+			for _, att := range note.Attachments {
+				a, err := s.notes.exportAttachment(ctx, sink, att)
+				if err != nil {
+					sum.Failed = append(sum.Failed, fmt.Sprintf("%s: %v", att.Name, err))
+					continue
+				}
+				page.Assets = append(page.Assets, a)
+				sum.Attachments++
+			}
+			file := uniqueName(fileStem(page.Title, note.Name), "html", used)
+			if err := sink.Put(ctx, file, strings.NewReader(renderHTML(page))); err != nil {
+				return sum, fmt.Errorf("unable to write %s: %w", file, err)
+			}
+			noteEntries = append(noteEntries, indexEntry{Title: page.Title, File: file})
+			sum.Notes++
+		case "doc":
+			doc, err := s.src.GetDoc(ctx, item.ID)
+			if err != nil {
+				sum.Failed = append(sum.Failed, fmt.Sprintf("%s: %v", item.ID, err))
+				continue
+			}
+			images := s.exportInlineImages(ctx, sink, doc, &sum)
+			title := strings.TrimSpace(doc.Title)
+			if title == "" {
+				title = "Untitled"
+			}
+			file := uniqueName(fileStem(title, doc.DocumentId), "html", used)
+			if err := sink.Put(ctx, file, strings.NewReader(renderDocHTML(doc, title, images))); err != nil {
+				return sum, fmt.Errorf("unable to write %s: %w", file, err)
+			}
+			docEntries = append(docEntries, indexEntry{Title: title, File: file})
+			sum.Docs++
+		}
+	}
+
+	index := renderSiteIndex(docEntries, noteEntries, time.Now())
+	if err := sink.Put(ctx, "index.html", strings.NewReader(index)); err != nil {
+		return sum, fmt.Errorf("unable to write index.html: %w", err)
+	}
+	return sum, nil
+}
+
+func (s *Site) resolve(sel Selection) ([]workspace.RegistryItem, error) {
+	if len(sel.IDs) > 0 {
+		items := make([]workspace.RegistryItem, 0, len(sel.IDs))
+		for _, id := range sel.IDs {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			itemType := "doc"
+			if strings.HasPrefix(id, "notes/") {
+				itemType = "keep"
+			}
+			items = append(items, workspace.RegistryItem{ID: id, Type: itemType})
+		}
+		return items, nil
+	}
+
+	all, err := s.src.ListRegistryItems()
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(sel.TitleContains)
+	var items []workspace.RegistryItem
+	for _, item := range all {
+		if item.Type != "keep" && item.Type != "doc" {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(item.Title), needle) {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Title < items[j].Title })
+	return items, nil
+}
+
+// exportInlineImages copies a Doc's embedded images into assets and returns the
+// asset path per inline object ID. Content URIs are short-lived, so this runs
+// right after the document is fetched.
+func (s *Site) exportInlineImages(ctx context.Context, sink Sink, doc *docs.Document, sum *Summary) map[string]string {
+	images := make(map[string]string)
+	for id, obj := range doc.InlineObjects {
+		if obj.InlineObjectProperties == nil || obj.InlineObjectProperties.EmbeddedObject == nil {
+			continue
+		}
+		props := obj.InlineObjectProperties.EmbeddedObject.ImageProperties
+		if props == nil || props.ContentUri == "" {
+			continue
+		}
+		file, err := s.copyImage(ctx, sink, props.ContentUri, doc.DocumentId+"-"+strings.TrimPrefix(id, "kix."))
+		if err != nil {
+			sum.Failed = append(sum.Failed, fmt.Sprintf("%s image %s: %v", doc.DocumentId, id, err))
+			continue
+		}
+		images[id] = file
+		sum.Attachments++
+	}
+	return images
+}
+
+func (s *Site) copyImage(ctx context.Context, sink Sink, uri, stem string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return "", fmt.Errorf("image fetch returned %s", resp.Status)
+	}
+
+	ext := ".png"
+	if exts, _ := mime.ExtensionsByType(resp.Header.Get("Content-Type")); len(exts) > 0 {
+		ext = exts[0]
+	}
+	file := path.Join(assetsDir, stem+ext)
+	if err := sink.Put(ctx, file, resp.Body); err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+func renderDocHTML(doc *docs.Document, title string, images map[string]string) string {
+	var b strings.Builder
+	t := html.EscapeString(title)
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>%s</title></head><body>\n", t)
+	b.WriteString("<p><a href=\"index.html\">&larr; Index</a></p>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", t)
+	if doc.Body != nil {
+		writeDocContent(&b, doc.Body.Content, images)
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+func writeDocContent(b *strings.Builder, content []*docs.StructuralElement, images map[string]string) {
+	inList := false
+	for _, el := range content {
+		if el == nil {
+			continue
+		}
+		isBullet := el.Paragraph != nil && el.Paragraph.Bullet != nil
+		if inList && !isBullet {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+		switch {
+		case el.Paragraph != nil:
+			inner := docParagraphHTML(el.Paragraph, images)
+			if strings.TrimSpace(inner) == "" {
+				continue
+			}
+			if isBullet {
+				if !inList {
+					b.WriteString("<ul>\n")
+					inList = true
+				}
+				fmt.Fprintf(b, "<li>%s</li>\n", inner)
+				continue
+			}
+			tag := docParagraphTag(el.Paragraph)
+			fmt.Fprintf(b, "<%s>%s</%s>\n", tag, inner, tag)
+		case el.Table != nil:
+			b.WriteString("<table border=\"1\">\n")
+			for _, row := range el.Table.TableRows {
+				b.WriteString("<tr>")
+				for _, cell := range row.TableCells {
+					b.WriteString("<td>")
+					writeDocContent(b, cell.Content, images)
+					b.WriteString("</td>")
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</table>\n")
+		}
+	}
+	if inList {
+		b.WriteString("</ul>\n")
+	}
+}
+
+func docParagraphTag(p *docs.Paragraph) string {
+	if p.ParagraphStyle == nil {
+		return "p"
+	}
+	switch style := p.ParagraphStyle.NamedStyleType; style {
+	case "TITLE":
+		return "h1"
+	case "SUBTITLE":
+		return "h2"
+	case "HEADING_1", "HEADING_2", "HEADING_3", "HEADING_4", "HEADING_5", "HEADING_6":
+		return "h" + strings.TrimPrefix(style, "HEADING_")
+	default:
+		return "p"
+	}
+}
+
+func docParagraphHTML(p *docs.Paragraph, images map[string]string) string {
+	var b strings.Builder
+	for _, pe := range p.Elements {
+		if pe == nil {
+			continue
+		}
+		if pe.InlineObjectElement != nil {
+			if src, ok := images[pe.InlineObjectElement.InlineObjectId]; ok {
+				fmt.Fprintf(&b, "<img src=\"%s\" alt=\"\">", html.EscapeString(src))
+			}
+			continue
+		}
+		run := pe.TextRun
+		if run == nil {
+			continue
+		}
+		text := strings.ReplaceAll(html.EscapeString(strings.TrimRight(run.Content, "\n")), "\v", "<br>")
+		if text == "" {
+			continue
+		}
+		if st := run.TextStyle; st != nil {
+			if st.Bold {
+				text = "<strong>" + text + "</strong>"
+			}
+			if st.Italic {
+				text = "<em>" + text + "</em>"
+			}
+			if st.Link != nil && st.Link.Url != "" {
+				text = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(st.Link.Url), text)
+			}
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+func renderSiteIndex(docEntries, noteEntries []indexEntry, at time.Time) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Workspace Archive</title></head><body>\n")
+	fmt.Fprintf(&b, "<h1>Workspace Archive</h1>\n<p>Generated %s.</p>\n", at.UTC().Format(time.RFC3339))
+	for _, section := range []struct {
+		heading string
+		entries []indexEntry
+	}{{"Docs", docEntries}, {"Notes", noteEntries}} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<h2>%s</h2>\n<ul>\n", section.heading)
+		for _, e := range section.entries {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(e.File), html.EscapeString(e.Title))
+		}
+		b.WriteString("</ul>\n")
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
@@ -0,0 +1,10 @@
+//go:build !faults
+
+/*
+File: internal/faults/disabled.go
+Description: Default builds leave fault injection out.
+*/
+package faults
+
+// Enabled reports whether this binary was built with fault injection.
+const Enabled = false
@@ -0,0 +1,11 @@
+//go:build faults
+
+/*
+File: internal/faults/enabled.go
+Description: Marks a build made with -tags faults, where fault injection is
+available.
+*/
+package faults
+
+// Enabled reports whether this binary was built with fault injection.
+const Enabled = true
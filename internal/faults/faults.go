@@ -0,0 +1,192 @@
+/*
+File: internal/faults/faults.go
+Description: Fault injection for Google API calls. An Injector sits under the
+quota transport and, per API, delays requests or answers them with synthetic
+429 and 5xx errors, so retries and partial-failure handling can be exercised
+in staging without spending real quota. Only wired in builds tagged faults.
+*/
+package faults
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/quota"
+)
+
+// AllAPIs matches every API.
+const AllAPIs = "*"
+
+// maxLatency bounds injected latency so a typo cannot hang the server.
+const maxLatency = 5 * time.Minute
+
+// Fault describes what to inject into calls to one API.
+type Fault struct {
+	// API is a quota.APIName ("drive", "keep", ...) or "*".
+	API string `json:"api"`
+	// LatencyMS delays each matched call before it is sent or failed.
+	LatencyMS int `json:"latency_ms,omitempty"`
+	// Status, when set, answers matched calls with this error instead of
+	// sending them: 429, 500, 502, 503 or 504.
+	Status int `json:"status,omitempty"`
+	// RetryAfter sets the Retry-After seconds of injected errors.
+	RetryAfter int `json:"retry_after,omitempty"`
+	// Rate is the fraction of calls matched, 0 < Rate <= 1; 0 means all.
+	Rate float64 `json:"rate,omitempty"`
+	// Remaining limits how many more calls are matched; 0 is unlimited.
+	Remaining int `json:"remaining,omitempty"`
+	// Injected counts the calls matched so far.
+	Injected int `json:"injected"`
+}
+
+// Validate checks a fault before it is installed.
+func (f Fault) Validate() error {
+	switch {
+	case f.API == "":
+		return fmt.Errorf("missing api")
+	case f.LatencyMS < 0 || time.Duration(f.LatencyMS)*time.Millisecond > maxLatency:
+		return fmt.Errorf("latency_ms must be between 0 and %d", maxLatency.Milliseconds())
+	case f.Rate < 0 || f.Rate > 1:
+		return fmt.Errorf("rate must be between 0 and 1")
+	case f.Remaining < 0 || f.RetryAfter < 0:
+		return fmt.Errorf("remaining and retry_after must not be negative")
+	case f.Status == 0 && f.LatencyMS == 0:
+		return fmt.Errorf("fault for %s injects nothing", f.API)
+	}
+	switch f.Status {
+	case 0, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return nil
+	}
+	return fmt.Errorf("unsupported status %d", f.Status)
+}
+
+// Injector is an http.RoundTripper that applies the installed faults.
+type Injector struct {
+	Base http.RoundTripper
+
+	mu     sync.Mutex
+	faults []Fault
+}
+
+// NewInjector wraps base, http.DefaultTransport when nil, with no faults.
+func NewInjector(base http.RoundTripper) *Injector {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Injector{Base: base}
+}
+
+// Faults returns the installed faults and their counters.
+func (in *Injector) Faults() []Fault {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return append([]Fault{}, in.faults...)
+}
+
+// Set replaces the installed faults; an empty list clears them.
+func (in *Injector) Set(faults []Fault) error {
+	for i, f := range faults {
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("fault %d: %w", i+1, err)
+		}
+		faults[i].Injected = 0
+	}
+	in.mu.Lock()
+	in.faults = append([]Fault{}, faults...)
+	in.mu.Unlock()
+	return nil
+}
+
+// match picks the first fault that applies to a call to api and counts it.
+// An exhausted fault is dropped.
+func (in *Injector) match(api string) (Fault, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	for i := range in.faults {
+		f := &in.faults[i]
+		if f.API != AllAPIs && f.API != api {
+			continue
+		}
+		if f.Rate > 0 && rand.Float64() >= f.Rate {
+			continue
+		}
+		f.Injected++
+		hit := *f
+		if f.Remaining > 0 {
+			if f.Remaining--; f.Remaining == 0 {
+				in.faults = append(in.faults[:i], in.faults[i+1:]...)
+			}
+		}
+		return hit, true
+	}
+	return Fault{}, false
+}
+
+// RoundTrip delays or fails the request per the first matching fault.
+func (in *Injector) RoundTrip(req *http.Request) (*http.Response, error) {
+	f, ok := in.match(quota.APIName(req))
+	if !ok {
+		return in.Base.RoundTrip(req)
+	}
+	if f.LatencyMS > 0 {
+		if err := sleep(req.Context(), time.Duration(f.LatencyMS)*time.Millisecond); err != nil {
+			return nil, err
+		}
+	}
+	if f.Status == 0 {
+		return in.Base.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return errorResponse(req, f), nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// errorResponse builds a Google-style JSON error, so callers parse it like a
+// real one.
+func errorResponse(req *http.Request, f Fault) *http.Response {
+	status := "INTERNAL"
+	switch f.Status {
+	case http.StatusTooManyRequests:
+		status = "RESOURCE_EXHAUSTED"
+	case http.StatusServiceUnavailable:
+		status = "UNAVAILABLE"
+	case http.StatusGatewayTimeout:
+		status = "DEADLINE_EXCEEDED"
+	}
+	body := fmt.Sprintf(`{"error":{"code":%d,"message":"injected fault","status":%q}}`, f.Status, status)
+	h := http.Header{"Content-Type": {"application/json; charset=UTF-8"}, "X-Axis-Fault": {"injected"}}
+	if f.RetryAfter > 0 {
+		h.Set("Retry-After", strconv.Itoa(f.RetryAfter))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,254 @@
+/*
+File: internal/federation/federation.go
+Description: Group-level federation. A central Axis instance reads the registry
+summary and inactive-user report of each subsidiary instance over its client
+API with a viewer or operator API key, and aggregates them for compliance
+reporting. Members keep their own Workspace credentials; nothing is written.
+*/
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fetchTimeout = 30 * time.Second
+	maxBody      = 4 << 20
+)
+
+// Summary is an instance's registry in figures, served at
+// GET /api/registry/summary.
+type Summary struct {
+	Tenant    string         `json:"tenant"`
+	Generated time.Time      `json:"generated"`
+	Items     int            `json:"items"`
+	ByType    map[string]int `json:"by_type"`
+	ByStatus  map[string]int `json:"by_status"`
+	Protected int            `json:"protected"`
+	External  int            `json:"external"`
+	LinkRot   int            `json:"link_rot"`
+	Bytes     int64          `json:"bytes"`
+	// Deleted counts the deletes executed in the last 30 days.
+	Deleted  int      `json:"deleted_30d"`
+	Mode     string   `json:"mode"`
+	Degraded []string `json:"degraded,omitempty"`
+}
+
+// Add accumulates o into s; Tenant, Generated and Mode are left alone.
+func (s *Summary) Add(o Summary) {
+	if s.ByType == nil {
+		s.ByType = make(map[string]int)
+	}
+	if s.ByStatus == nil {
+		s.ByStatus = make(map[string]int)
+	}
+	s.Items += o.Items
+	for k, n := range o.ByType {
+		s.ByType[k] += n
+	}
+	for k, n := range o.ByStatus {
+		s.ByStatus[k] += n
+	}
+	s.Protected += o.Protected
+	s.External += o.External
+	s.LinkRot += o.LinkRot
+	s.Bytes += o.Bytes
+	s.Deleted += o.Deleted
+}
+
+// Member is one subsidiary instance in the federation file. The API key is
+// read from the environment variable named by KeyEnv, as in the auth file.
+type Member struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	KeyEnv string `json:"key_env"`
+}
+
+// Config is the on-disk layout of the federation file.
+type Config struct {
+	Members []Member `json:"members"`
+}
+
+// MemberReport is what one member returned at the last refresh. Inactive is
+// nil when the member's key may not read the report.
+type MemberReport struct {
+	Name         string    `json:"name"`
+	URL          string    `json:"url"`
+	OK           bool      `json:"ok"`
+	Error        string    `json:"error,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+	Summary      *Summary  `json:"summary,omitempty"`
+	Inactive     *int      `json:"inactive_users,omitempty"`
+	ReportsError string    `json:"reports_error,omitempty"`
+}
+
+// Report aggregates the members. Totals only count members that answered.
+type Report struct {
+	Generated time.Time      `json:"generated"`
+	Members   []MemberReport `json:"members"`
+	Totals    Summary        `json:"totals"`
+	Inactive  int            `json:"inactive_users"`
+	Failed    int            `json:"failed"`
+}
+
+type member struct {
+	Member
+	base *url.URL
+	key  string
+}
+
+// Federation fetches and caches the members' reports.
+type Federation struct {
+	members []member
+	client  *http.Client
+
+	mu   sync.Mutex
+	last *Report
+}
+
+// Load reads and validates a federation file.
+func Load(path string) (*Federation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read federation file %s: %w", path, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse federation file %s: %w", path, err)
+	}
+	return New(cfg, nil)
+}
+
+// New validates the configuration and resolves API keys from the
+// environment. A nil client uses one with a 30 second timeout.
+func New(cfg Config, client *http.Client) (*Federation, error) {
+	if len(cfg.Members) == 0 {
+		return nil, errors.New("federation has no members")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: fetchTimeout}
+	}
+	f := &Federation{client: client}
+	seen := make(map[string]bool)
+	for _, m := range cfg.Members {
+		if m.Name == "" || seen[m.Name] {
+			return nil, fmt.Errorf("federation member %q: names must be set and unique", m.Name)
+		}
+		seen[m.Name] = true
+		u, err := url.Parse(m.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("federation member %q: %q is not an http(s) URL", m.Name, m.URL)
+		}
+		key := os.Getenv(m.KeyEnv)
+		if m.KeyEnv == "" || key == "" {
+			return nil, fmt.Errorf("federation member %q has no API key (set %s)", m.Name, m.KeyEnv)
+		}
+		f.members = append(f.members, member{Member: m, base: u, key: key})
+	}
+	return f, nil
+}
+
+// Members lists the configured members.
+func (f *Federation) Members() []Member {
+	out := make([]Member, len(f.members))
+	for i, m := range f.members {
+		out[i] = m.Member
+	}
+	return out
+}
+
+// Last returns the report of the last refresh, or nil before the first.
+func (f *Federation) Last() *Report {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.last
+}
+
+// Refresh fetches every member concurrently and caches the aggregate. A
+// member that fails is reported with its error and left out of the totals.
+func (f *Federation) Refresh(ctx context.Context) *Report {
+	reports := make([]MemberReport, len(f.members))
+	var wg sync.WaitGroup
+	for i, m := range f.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = f.fetch(ctx, m)
+		}()
+	}
+	wg.Wait()
+
+	res := &Report{Generated: time.Now(), Members: reports}
+	for _, r := range reports {
+		if !r.OK {
+			res.Failed++
+			continue
+		}
+		res.Totals.Add(*r.Summary)
+		if r.Inactive != nil {
+			res.Inactive += *r.Inactive
+		}
+	}
+	res.Totals.Generated = res.Generated
+	f.mu.Lock()
+	f.last = res
+	f.mu.Unlock()
+	return res
+}
+
+func (f *Federation) fetch(ctx context.Context, m member) MemberReport {
+	rep := MemberReport{Name: m.Name, URL: m.URL, Fetched: time.Now()}
+	var sum Summary
+	if err := f.get(ctx, m, "/api/registry/summary", &sum); err != nil {
+		rep.Error = err.Error()
+		return rep
+	}
+	rep.OK, rep.Summary = true, &sum
+
+	var inactive struct {
+		Users []json.RawMessage `json:"users"`
+	}
+	if err := f.get(ctx, m, "/api/reports/inactive-users", &inactive); err != nil {
+		rep.ReportsError = err.Error()
+		return rep
+	}
+	n := len(inactive.Users)
+	rep.Inactive = &n
+	return rep
+}
+
+func (f *Federation) get(ctx context.Context, m member, path string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	u := m.base.JoinPath(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+m.key)
+	req.Header.Set("Accept", "application/json")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body := io.LimitReader(resp.Body, maxBody)
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(body, 512))
+		return fmt.Errorf("%s answered %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+File: internal/index/content.go
+Description: Content indexing of Docs and Sheets from their Drive exports.
+Update only queues edited files; FillContent exports them in the background,
+newest first, within a per-file cap and a total byte budget, so deep search
+never depends on Drive's query limits and the index cannot grow unbounded.
+*/
+package index
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxFileContent caps the exported text kept from one file.
+const MaxFileContent = 1 << 20
+
+// exportTypes maps the exported item types to their export format.
+var exportTypes = map[string]string{
+	"doc":   "text/plain",
+	"sheet": "text/csv",
+}
+
+// Exporter exports Drive files as text; workspace.Service implements it.
+type Exporter interface {
+	ExportText(ctx context.Context, fileID, mimeType string, limit int) (string, error)
+}
+
+// ContentStats summarizes a FillContent.
+type ContentStats struct {
+	Exported int      `json:"exported"`
+	Bytes    int      `json:"bytes"`
+	Pending  int      `json:"pending"` // still queued: over budget, failed or interrupted
+	Failed   []string `json:"failed,omitempty"`
+}
+
+// SetContentLimit indexes the exported text of Docs and Sheets, at most limit
+// bytes in all; 0 turns it off, and the next Update re-indexes them the usual
+// way.
+func (x *Index) SetContentLimit(limit int) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.contentLimit = max(limit, 0)
+}
+
+// ContentUsage returns the bytes of exported text indexed and the limit.
+func (x *Index) ContentUsage() (used, limit int) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.content, x.contentLimit
+}
+
+// queue indexes d by title and marks it for export when content export covers
+// its type. handled is false for items Update indexes itself; queued is false
+// when d is already exported or queued at this modification time. A file
+// indexed the usual way keeps its terms until the export replaces them.
+func (x *Index) queue(d Document) (handled, queued bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.contentLimit == 0 || exportTypes[d.Type] == "" {
+		return false, false
+	}
+	if e, ok := x.docs[d.ID]; ok && e.Modified.Equal(d.Modified) {
+		if e.Exported {
+			return true, false
+		}
+		e.Exported, e.Pending = true, true
+		return true, true
+	}
+	e := newEntry(d)
+	e.Exported, e.Pending = true, true
+	x.remove(d.ID)
+	x.add(d.ID, e)
+	return true, true
+}
+
+type pendingFile struct {
+	id, typ, title string
+	modified       time.Time
+}
+
+// FillContent exports queued Docs and Sheets, most recently modified first,
+// until the budget runs out. A file is cut at MaxFileContent or at what is
+// left of the budget; files left over stay queued and are exported once
+// removals free space. Failed exports are retried by the next FillContent.
+func (x *Index) FillContent(ctx context.Context, exp Exporter) (ContentStats, error) {
+	var stats ContentStats
+	x.mu.RLock()
+	var queue []pendingFile
+	for id, e := range x.docs {
+		if e.Pending {
+			queue = append(queue, pendingFile{id: id, typ: e.Type, title: e.Title, modified: e.Modified})
+		}
+	}
+	x.mu.RUnlock()
+	sort.Slice(queue, func(i, j int) bool { return queue[i].modified.After(queue[j].modified) })
+
+	for i, f := range queue {
+		used, limit := x.ContentUsage()
+		if ctx.Err() != nil || limit == 0 || used >= limit {
+			stats.Pending = len(queue) - i
+			return stats, ctx.Err()
+		}
+		text, err := exp.ExportText(ctx, f.id, exportTypes[f.typ], min(MaxFileContent, limit-used))
+		if err != nil {
+			stats.Failed = append(stats.Failed, fmt.Sprintf("%s: %v", f.id, err))
+			stats.Pending++
+			continue
+		}
+		if x.putContent(f, text) {
+			stats.Exported++
+			stats.Bytes += len(text)
+		}
+	}
+	return stats, nil
+}
+
+// putContent replaces a queued entry with its exported text, unless the file
+// was edited or removed meanwhile. Text past the budget is dropped.
+func (x *Index) putContent(f pendingFile, text string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	old, ok := x.docs[f.id]
+	if !ok || !old.Pending || !old.Modified.Equal(f.modified) {
+		return false
+	}
+	if room := x.contentLimit - x.content; len(text) > room {
+		text = text[:max(room, 0)]
+	}
+	e := newEntry(Document{ID: f.id, Type: f.typ, Title: f.title, Modified: f.modified, Text: text})
+	e.Exported, e.Content = true, len(text)
+	x.remove(f.id)
+	x.add(f.id, e)
+	return true
+}
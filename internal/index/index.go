@@ -0,0 +1,365 @@
+/*
+File: internal/index/index.go
+Description: Local full-text index of registry content. Keep note bodies come
+with the registry listing and Doc text is fetched once per edit, so searches
+are answered from memory without API calls. The index is an in-memory inverted
+index scored with BM25 plus a title boost, persisted as a JSON file of per-item
+term counts from which the postings are rebuilt on load.
+*/
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"axis/internal/workspace"
+
+	docs "google.golang.org/api/docs/v1"
+)
+
+// fileVersion is the persisted format version; other versions are rebuilt.
+const fileVersion = 1
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Document is one item's indexable content.
+type Document struct {
+	ID       string
+	Type     string
+	Title    string
+	Modified time.Time
+	Text     string
+}
+
+// Hit is a search match.
+type Hit struct {
+	ID    string  `json:"id"`
+	Type  string  `json:"type"`
+	Score float64 `json:"score"`
+}
+
+// entry is the persisted form of an indexed item.
+type entry struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Modified time.Time      `json:"modified"`
+	Terms    map[string]int `json:"terms"`
+	Length   int            `json:"length"`
+	// Exported marks a Doc or Sheet indexed by its Drive export: Content
+	// bytes of text, or none yet while Pending.
+	Exported bool `json:"exported,omitempty"`
+	Content  int  `json:"content,omitempty"`
+	Pending  bool `json:"pending,omitempty"`
+}
+
+// Index is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*entry
+	postings map[string]map[string]int // term -> item ID -> count
+	length   int                       // sum of entry lengths
+	built    time.Time                 // last Update
+
+	content      int // sum of entry Content
+	contentLimit int // bytes of exported text allowed; 0 disables export
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{docs: make(map[string]*entry), postings: make(map[string]map[string]int)}
+}
+
+// Load reads an index saved at path. A missing file or an older format yields
+// an empty index.
+func Load(path string) (*Index, error) {
+	x := New()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return x, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Version int               `json:"version"`
+		Built   time.Time         `json:"built"`
+		Docs    map[string]*entry `json:"docs"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid index %s: %w", path, err)
+	}
+	if file.Version != fileVersion {
+		return x, nil
+	}
+	for id, e := range file.Docs {
+		x.add(id, e)
+	}
+	x.built = file.Built
+	return x, nil
+}
+
+// Save writes the index to path atomically.
+func (x *Index) Save(path string) error {
+	x.mu.RLock()
+	data, err := json.Marshal(struct {
+		Version int               `json:"version"`
+		Built   time.Time         `json:"built"`
+		Docs    map[string]*entry `json:"docs"`
+	}{fileVersion, x.built, x.docs})
+	x.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".axis-index-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Len returns the number of indexed items.
+func (x *Index) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.docs)
+}
+
+// Built returns when the index was last updated.
+func (x *Index) Built() time.Time {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.built
+}
+
+// Put indexes d, replacing any earlier version.
+func (x *Index) Put(d Document) {
+	e := newEntry(d)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(d.ID)
+	x.add(d.ID, e)
+}
+
+func newEntry(d Document) *entry {
+	e := &entry{Type: d.Type, Title: d.Title, Modified: d.Modified, Terms: make(map[string]int)}
+	for _, text := range []string{d.Title, d.Text} {
+		for _, term := range Tokenize(text) {
+			e.Terms[term]++
+			e.Length++
+		}
+	}
+	return e
+}
+
+// Delete removes an item and reports whether it was indexed.
+func (x *Index) Delete(id string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.remove(id)
+}
+
+// Current reports whether id is indexed at the given modification time.
+func (x *Index) Current(id string, modified time.Time) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.docs[id]
+	return ok && e.Modified.Equal(modified)
+}
+
+// current is Current for an entry indexed the way exported asks for.
+func (x *Index) current(id string, modified time.Time, exported bool) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.docs[id]
+	return ok && e.Modified.Equal(modified) && e.Exported == exported
+}
+
+func (x *Index) add(id string, e *entry) {
+	x.docs[id] = e
+	x.length += e.Length
+	x.content += e.Content
+	for term, n := range e.Terms {
+		p := x.postings[term]
+		if p == nil {
+			p = make(map[string]int)
+			x.postings[term] = p
+		}
+		p[id] = n
+	}
+}
+
+func (x *Index) remove(id string) bool {
+	e, ok := x.docs[id]
+	if !ok {
+		return false
+	}
+	for term := range e.Terms {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	x.length -= e.Length
+	x.content -= e.Content
+	delete(x.docs, id)
+	return true
+}
+
+// Search returns the items of the given types (all when none) containing every
+// query term, best first. Terms in the title add 3 each and the whole query as
+// a title phrase adds 5, on top of the BM25 score of the title and body.
+func (x *Index) Search(query string, types []string) []Hit {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	phrase := strings.Join(terms, " ")
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// Walk the rarest term's postings and check the others against them.
+	sort.Slice(terms, func(i, j int) bool { return len(x.postings[terms[i]]) < len(x.postings[terms[j]]) })
+	n := float64(len(x.docs))
+	avgLen := float64(x.length) / max(n, 1)
+
+	var hits []Hit
+	for id := range x.postings[terms[0]] {
+		e := x.docs[id]
+		if len(types) > 0 && !slices.Contains(types, e.Type) {
+			continue
+		}
+		title := strings.Join(Tokenize(e.Title), " ")
+		score := 0.0
+		for _, term := range terms {
+			tf, ok := x.postings[term][id]
+			if !ok {
+				score = -1
+				break
+			}
+			df := float64(len(x.postings[term]))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := 1 - bm25B + bm25B*float64(e.Length)/max(avgLen, 1)
+			score += idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+			if slices.Contains(strings.Fields(title), term) {
+				score += 3
+			}
+		}
+		if score < 0 {
+			continue
+		}
+		if len(terms) > 1 && strings.Contains(title, phrase) {
+			score += 5
+		}
+		hits = append(hits, Hit{ID: id, Type: e.Type, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// Tokenize lowercases text and splits it into runs of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// DocFetcher reads Doc content; workspace.Service implements it.
+type DocFetcher interface {
+	GetDoc(ctx context.Context, documentID string) (*docs.Document, error)
+}
+
+// UpdateStats summarizes an Update.
+type UpdateStats struct {
+	Indexed   int      `json:"indexed"`
+	Unchanged int      `json:"unchanged"`
+	Removed   int      `json:"removed"`
+	Queued    int      `json:"queued,omitempty"` // Docs and Sheets left for FillContent
+	Failed    []string `json:"failed,omitempty"`
+}
+
+// Update brings the index in line with a complete registry listing: new and
+// edited items are (re)indexed and items no longer listed are removed. Notes
+// use the body carried by the listing; only Docs cost an API call, and only
+// when edited. Sheets are indexed by title. Failed Docs are retried on the
+// next Update. With content export enabled, new and edited Docs and Sheets are
+// indexed by title and queued for FillContent instead.
+func (x *Index) Update(ctx context.Context, fetcher DocFetcher, items []workspace.RegistryItem) (UpdateStats, error) {
+	var stats UpdateStats
+	listed := make(map[string]bool, len(items))
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		listed[item.ID] = true
+		var modified time.Time
+		if item.Modified != nil {
+			modified = *item.Modified
+		}
+		d := Document{ID: item.ID, Type: item.Type, Title: item.Title, Modified: modified}
+		if handled, queued := x.queue(d); handled {
+			if queued {
+				stats.Queued++
+			} else {
+				stats.Unchanged++
+			}
+			continue
+		}
+		if x.current(item.ID, modified, false) {
+			stats.Unchanged++
+			continue
+		}
+		switch item.Type {
+		case "keep":
+			d.Text = item.Source.Text
+		case "doc":
+			doc, err := fetcher.GetDoc(ctx, item.ID)
+			if err != nil {
+				stats.Failed = append(stats.Failed, fmt.Sprintf("%s: %v", item.ID, err))
+				continue
+			}
+			d.Text = workspace.DocRawText(doc)
+		}
+		x.Put(d)
+		stats.Indexed++
+	}
+
+	x.mu.Lock()
+	for id := range x.docs {
+		if !listed[id] {
+			x.remove(id)
+			stats.Removed++
+		}
+	}
+	x.built = time.Now()
+	x.mu.Unlock()
+	return stats, nil
+}
@@ -0,0 +1,390 @@
+/*
+File: internal/jobs/jobs.go
+Description: Background jobs for long-running operations. A job is submitted
+with the function that does the work, waits in a bounded queue for one of a few
+workers, reports progress as it goes and ends with a JSON result, a result file
+or an error. Jobs can be canceled while queued or running, and finished jobs
+are kept for a while so their results can be collected.
+*/
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// States a job moves through. Succeeded, Failed and Canceled are final.
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateCanceled  = "canceled"
+)
+
+const (
+	// DefaultWorkers is how many jobs run at once.
+	DefaultWorkers = 2
+	// DefaultQueue is how many jobs may wait for a worker.
+	DefaultQueue = 32
+	// DefaultRetention is how long a finished job and its result are kept.
+	DefaultRetention = time.Hour
+	// maxFinished bounds the finished jobs kept regardless of age.
+	maxFinished = 100
+	// progressInterval throttles progress updates; state changes are always
+	// reported.
+	progressInterval = time.Second
+	pruneInterval    = time.Minute
+)
+
+var (
+	ErrNotFound  = errors.New("job not found")
+	ErrFinished  = errors.New("job already finished")
+	ErrQueueFull = errors.New("job queue is full")
+	ErrNoResult  = errors.New("job has no result")
+)
+
+// Job is a job's status as reported by the API and in update events.
+type Job struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"`
+	Actor    string          `json:"actor,omitempty"`
+	State    string          `json:"state"`
+	Done     int             `json:"done"`
+	Total    int             `json:"total,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	File     bool            `json:"file,omitempty"` // the result is a file served by the result endpoint
+	Created  time.Time       `json:"created"`
+	Started  *time.Time      `json:"started,omitempty"`
+	Finished *time.Time      `json:"finished,omitempty"`
+}
+
+// Final reports whether the job has stopped.
+func (j Job) Final() bool {
+	switch j.State {
+	case StateSucceeded, StateFailed, StateCanceled:
+		return true
+	}
+	return false
+}
+
+// Result is what a job's function produces: a value encoded as the job's JSON
+// result, a file, or both. The value is kept even when the function also
+// returns an error, so a canceled job still reports what it got through.
+type Result struct {
+	Value       any
+	Path        string // removed when the job is dropped
+	ContentType string
+	Filename    string
+}
+
+// File is a finished job's result file.
+type File struct {
+	Path        string
+	ContentType string
+	Filename    string
+}
+
+// Func does a job's work. ctx is canceled when the job is; p reports
+// progress.
+type Func func(ctx context.Context, p *Progress) (Result, error)
+
+// Progress lets a running job report how far it has got. A nil Progress
+// discards updates, so work shared with synchronous callers can report
+// unconditionally.
+type Progress struct {
+	m  *Manager
+	id string
+}
+
+// SetTotal sets the number of steps the job expects to take.
+func (p *Progress) SetTotal(n int) {
+	if p != nil {
+		p.m.update(p.id, func(e *entry) { e.job.Total = n })
+	}
+}
+
+// Add records n more steps done.
+func (p *Progress) Add(n int) {
+	if p != nil {
+		p.m.update(p.id, func(e *entry) { e.job.Done += n })
+	}
+}
+
+type entry struct {
+	job      Job
+	fn       Func
+	file     File
+	cancel   context.CancelFunc
+	notified time.Time
+}
+
+// Manager queues and runs jobs. Updates are passed to notify, at most once a
+// second per job for progress.
+type Manager struct {
+	workers   int
+	retention time.Duration
+	notify    func(Job)
+	queue     chan string
+
+	mu   sync.Mutex
+	jobs map[string]*entry
+}
+
+// New returns a manager running workers jobs at a time with up to queue
+// waiting. notify, if not nil, is called on every state change and on
+// progress.
+func New(workers, queue int, retention time.Duration, notify func(Job)) *Manager {
+	if notify == nil {
+		notify = func(Job) {}
+	}
+	return &Manager{
+		workers:   workers,
+		retention: retention,
+		notify:    notify,
+		queue:     make(chan string, queue),
+		jobs:      make(map[string]*entry),
+	}
+}
+
+// Submit queues fn as a job of kind on behalf of actor.
+func (m *Manager) Submit(kind, actor string, fn Func) (Job, error) {
+	e := &entry{job: Job{ID: newID(), Kind: kind, Actor: actor, State: StateQueued, Created: time.Now()}, fn: fn}
+	m.mu.Lock()
+	select {
+	case m.queue <- e.job.ID:
+	default:
+		m.mu.Unlock()
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[e.job.ID] = e
+	job := e.job
+	m.mu.Unlock()
+	m.notify(job)
+	return job, nil
+}
+
+// Get returns the job id.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// List returns every known job, newest first.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	out := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		out = append(out, e.job)
+	}
+	m.mu.Unlock()
+	slices.SortFunc(out, func(a, b Job) int { return b.Created.Compare(a.Created) })
+	return out
+}
+
+// Cancel stops a queued or running job. A running job ends once its function
+// returns.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	switch {
+	case !ok:
+		m.mu.Unlock()
+		return Job{}, ErrNotFound
+	case e.job.Final():
+		m.mu.Unlock()
+		return e.job, ErrFinished
+	case e.job.State == StateQueued:
+		m.finishLocked(e, StateCanceled, context.Canceled)
+		job := e.job
+		m.mu.Unlock()
+		m.notify(job)
+		return job, nil
+	}
+	e.cancel()
+	job := e.job
+	m.mu.Unlock()
+	return job, nil
+}
+
+// ResultFile returns the file a finished job produced.
+func (m *Manager) ResultFile(id string) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	switch {
+	case !ok:
+		return File{}, ErrNotFound
+	case e.file.Path == "":
+		return File{}, ErrNoResult
+	}
+	return e.file, nil
+}
+
+// Run starts the workers and prunes finished jobs until ctx is done. Jobs
+// still queued or running then are canceled, and result files removed.
+func (m *Manager) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range m.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.work(ctx)
+		}()
+	}
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			m.prune(now)
+		case <-ctx.Done():
+			wg.Wait()
+			m.mu.Lock()
+			var canceled []Job
+			for _, e := range m.jobs {
+				if !e.job.Final() {
+					m.finishLocked(e, StateCanceled, context.Canceled)
+					canceled = append(canceled, e.job)
+				}
+				if e.file.Path != "" {
+					os.Remove(e.file.Path)
+				}
+			}
+			m.mu.Unlock()
+			for _, job := range canceled {
+				m.notify(job)
+			}
+			return
+		}
+	}
+}
+
+func (m *Manager) work(ctx context.Context) {
+	for {
+		select {
+		case id := <-m.queue:
+			m.run(ctx, id)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Manager) run(ctx context.Context, id string) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	if !ok || e.job.State != StateQueued {
+		m.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	now := time.Now()
+	e.cancel = cancel
+	e.job.State, e.job.Started = StateRunning, &now
+	job, fn := e.job, e.fn
+	m.mu.Unlock()
+	m.notify(job)
+
+	res, err := fn(ctx, &Progress{m: m, id: id})
+	var raw json.RawMessage
+	if res.Value != nil {
+		var merr error
+		if raw, merr = json.Marshal(res.Value); err == nil {
+			err = merr
+		}
+	}
+
+	m.mu.Lock()
+	e.job.Result = raw
+	switch {
+	case ctx.Err() != nil:
+		m.finishLocked(e, StateCanceled, ctx.Err())
+	case err != nil:
+		m.finishLocked(e, StateFailed, err)
+	default:
+		if res.Path != "" {
+			e.file = File{Path: res.Path, ContentType: res.ContentType, Filename: res.Filename}
+			e.job.File = true
+		}
+		m.finishLocked(e, StateSucceeded, nil)
+	}
+	if e.job.State != StateSucceeded && res.Path != "" {
+		os.Remove(res.Path)
+	}
+	job = e.job
+	m.mu.Unlock()
+	m.notify(job)
+}
+
+// finishLocked ends a job in state; m.mu must be held.
+func (m *Manager) finishLocked(e *entry, state string, err error) {
+	now := time.Now()
+	e.job.State, e.job.Finished = state, &now
+	if err != nil {
+		e.job.Error = err.Error()
+	}
+	e.fn = nil
+}
+
+// update applies fn to a running job and reports it, throttled.
+func (m *Manager) update(id string, fn func(*entry)) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	if !ok || e.job.State != StateRunning {
+		m.mu.Unlock()
+		return
+	}
+	fn(e)
+	now := time.Now()
+	if now.Sub(e.notified) < progressInterval {
+		m.mu.Unlock()
+		return
+	}
+	e.notified = now
+	job := e.job
+	m.mu.Unlock()
+	m.notify(job)
+}
+
+// prune drops finished jobs older than the retention, and the oldest beyond
+// maxFinished, along with their result files.
+func (m *Manager) prune(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var finished []*entry
+	for _, e := range m.jobs {
+		if e.job.Final() {
+			finished = append(finished, e)
+		}
+	}
+	slices.SortFunc(finished, func(a, b *entry) int { return b.job.Finished.Compare(*a.job.Finished) })
+	for i, e := range finished {
+		if i >= maxFinished || now.Sub(*e.job.Finished) > m.retention {
+			if e.file.Path != "" {
+				os.Remove(e.file.Path)
+			}
+			delete(m.jobs, e.job.ID)
+		}
+	}
+}
+
+func newID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,164 @@
+/*
+File: internal/linkcheck/linkcheck.go
+Description: URL extraction and link health checking. Checks are rate limited,
+results are cached with a TTL, and each link is classified as ok, redirected, or
+dead so content with mostly dead links can be flagged for cleanup.
+*/
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultInterval = 200 * time.Millisecond
+	DefaultTTL      = 24 * time.Hour
+	requestTimeout  = 10 * time.Second
+	maxRedirects    = 5
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'\x60]+`)
+
+// ExtractURLs finds http(s) URLs in text, trimming trailing punctuation, and
+// returns them de-duplicated in first-seen order.
+func ExtractURLs(text string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, raw := range urlPattern.FindAllString(text, -1) {
+		u := strings.TrimRight(raw, ".,;:!?)]}")
+		if u == "" || seen[u] {
+			continue
+		}
+		if _, err := url.ParseRequestURI(u); err != nil {
+			continue
+		}
+		seen[u] = true
+		out = append(out, u)
+	}
+	return out
+}
+
+// Result is the health of a single URL.
+type Result struct {
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"`
+	FinalURL   string    `json:"final_url,omitempty"`
+	Redirected bool      `json:"redirected"`
+	Dead       bool      `json:"dead"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Checker performs rate-limited, cached link checks.
+type Checker struct {
+	client   *http.Client
+	interval time.Duration
+	ttl      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]Result
+	next  time.Time
+}
+
+// NewChecker creates a checker issuing at most one request per interval.
+func NewChecker(interval, ttl time.Duration) *Checker {
+	c := &Checker{
+		interval: interval,
+		ttl:      ttl,
+		cache:    make(map[string]Result),
+	}
+	c.client = &http.Client{
+		Timeout: requestTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+	return c
+}
+
+// Check returns the cached result or probes the URL.
+func (c *Checker) Check(ctx context.Context, target string) Result {
+	c.mu.Lock()
+	if r, ok := c.cache[target]; ok && time.Since(r.CheckedAt) < c.ttl {
+		c.mu.Unlock()
+		return r
+	}
+	c.mu.Unlock()
+
+	if err := c.wait(ctx); err != nil {
+		return Result{URL: target, Error: err.Error(), CheckedAt: time.Now()}
+	}
+	r := c.probe(ctx, target)
+	// Context cancellation says nothing about the link, so it is not cached.
+	if ctx.Err() == nil {
+		c.mu.Lock()
+		c.cache[target] = r
+		c.mu.Unlock()
+	}
+	return r
+}
+
+// wait reserves the next request slot, spacing requests by the interval.
+func (c *Checker) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.next
+	if slot.Before(now) {
+		slot = now
+	}
+	c.next = slot.Add(c.interval)
+	c.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Checker) probe(ctx context.Context, target string) Result {
+	r := Result{URL: target, CheckedAt: time.Now().UTC()}
+	resp, err := c.do(ctx, http.MethodHead, target)
+	// Many servers reject HEAD; retry those with GET before judging the link.
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusForbidden) {
+		resp.Body.Close()
+		resp, err = c.do(ctx, http.MethodGet, target)
+	}
+	if err != nil {
+		r.Dead = true
+		r.Error = err.Error()
+		return r
+	}
+	resp.Body.Close()
+
+	r.StatusCode = resp.StatusCode
+	r.FinalURL = resp.Request.URL.String()
+	r.Redirected = r.FinalURL != target
+	r.Dead = resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone || resp.StatusCode >= 500
+	return r
+}
+
+func (c *Checker) do(ctx context.Context, method, target string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "axis-linkcheck/1.0")
+	return c.client.Do(req)
+}
@@ -0,0 +1,242 @@
+/*
+File: internal/plan/plan.go
+Description: Signed action plans for air-gapped operation. An air-gapped Axis
+proposes deletions and sheet edits instead of making them and exports them as a
+plan file signed with its own ed25519 key. Approvers add their signatures
+offline, and the plan is applied later from a host that holds write access,
+which checks both the origin signature and the approvals first. Signatures cover
+the compact payload bytes, so a plan cannot be altered after it is signed.
+*/
+package plan
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Operations a plan can carry.
+const (
+	OpDelete      = "delete"
+	OpSheetUpdate = "sheet.update"
+)
+
+// DefaultValidity is how long an exported plan can be applied.
+const DefaultValidity = 7 * 24 * time.Hour
+
+// Action is one proposed change. Modified is the item's edit time when it was
+// proposed; apply skips items edited since.
+type Action struct {
+	Op       string     `json:"op"`
+	ItemID   string     `json:"item_id"`
+	ItemType string     `json:"item_type"`
+	Title    string     `json:"title"`
+	Modified *time.Time `json:"modified,omitempty"`
+	Range    string     `json:"range,omitempty"`
+	Values   [][]any    `json:"values,omitempty"`
+	Raw      bool       `json:"raw,omitempty"`
+	Actor    string     `json:"actor"`
+	Proposed time.Time  `json:"proposed"`
+}
+
+// Key identifies the change an action makes; a newer proposal with the same
+// key replaces the older one.
+func (a Action) Key() string {
+	return a.Op + "\x00" + a.ItemID + "\x00" + a.Range
+}
+
+// Describe summarizes the action in one line.
+func (a Action) Describe() string {
+	title := a.Title
+	if title == "" {
+		title = a.ItemID
+	}
+	switch a.Op {
+	case OpSheetUpdate:
+		cells := 0
+		for _, row := range a.Values {
+			cells += len(row)
+		}
+		return fmt.Sprintf("update %s!%s (%d cells) in %q, proposed by %s", a.ItemID, a.Range, cells, title, a.Actor)
+	default:
+		return fmt.Sprintf("%s %s %q (%s), proposed by %s", a.Op, a.ItemType, title, a.ItemID, a.Actor)
+	}
+}
+
+// Plan is the signed content of a plan file.
+type Plan struct {
+	ID      string    `json:"id"`
+	Origin  string    `json:"origin"` // the proposing operator profile
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+	Actions []Action  `json:"actions"`
+}
+
+// Approval is an approver's signature over a plan payload.
+type Approval struct {
+	Approver  string    `json:"approver"`
+	Key       string    `json:"key"` // base64 public key
+	Time      time.Time `json:"time"`
+	Signature string    `json:"signature"`
+}
+
+// File is a plan as exchanged on disk.
+type File struct {
+	Payload   json.RawMessage `json:"payload"`
+	Signature string          `json:"signature"`
+	Approvals []Approval      `json:"approvals,omitempty"`
+}
+
+// Sign serializes p and signs it as its origin.
+func Sign(p Plan, key ed25519.PrivateKey) (*File, error) {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Payload: payload, Signature: encode(ed25519.Sign(key, payload))}, nil
+}
+
+// Read loads a plan file.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil || len(f.Payload) == 0 {
+		return nil, fmt.Errorf("%s is not a plan file", path)
+	}
+	return &f, nil
+}
+
+// Write saves a plan file, readable only by its owner.
+func (f *File) Write(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// signed returns the payload bytes the signatures cover. Writing a file indents
+// the payload, so it is compacted back to the form Sign produced.
+func (f *File) signed() []byte {
+	var b bytes.Buffer
+	if err := json.Compact(&b, f.Payload); err != nil {
+		return f.Payload
+	}
+	return b.Bytes()
+}
+
+// Plan decodes the payload without verifying it.
+func (f *File) Plan() (Plan, error) {
+	var p Plan
+	if err := json.Unmarshal(f.Payload, &p); err != nil {
+		return p, fmt.Errorf("invalid plan payload: %w", err)
+	}
+	return p, nil
+}
+
+// VerifyOrigin checks the origin signature and decodes the plan.
+func (f *File) VerifyOrigin(origin ed25519.PublicKey) (Plan, error) {
+	sig, err := decode(f.Signature)
+	if err != nil || !ed25519.Verify(origin, f.signed(), sig) {
+		return Plan{}, errors.New("plan signature does not match the Axis key")
+	}
+	return f.Plan()
+}
+
+// approvalMessage binds an approval to the approver and time as well as the
+// payload, so it cannot be reattributed.
+func approvalMessage(payload []byte, approver string, at time.Time) []byte {
+	return fmt.Appendf(nil, "axis-plan-approval\n%s\n%s\n%s", approver, at.UTC().Format(time.RFC3339), payload)
+}
+
+// Approve adds an approval signed with key.
+func (f *File) Approve(approver string, key ed25519.PrivateKey, at time.Time) {
+	at = at.UTC().Truncate(time.Second)
+	f.Approvals = append(f.Approvals, Approval{
+		Approver:  approver,
+		Key:       encode(key.Public().(ed25519.PublicKey)),
+		Time:      at,
+		Signature: encode(ed25519.Sign(key, approvalMessage(f.signed(), approver, at))),
+	})
+}
+
+// Verify checks the origin signature, the expiry and that at least required
+// distinct trusted keys approved the plan, and returns the plan.
+func (f *File) Verify(origin ed25519.PublicKey, approvers []ed25519.PublicKey, required int, now time.Time) (Plan, error) {
+	p, err := f.VerifyOrigin(origin)
+	if err != nil {
+		return p, err
+	}
+	if !p.Expires.IsZero() && now.After(p.Expires) {
+		return p, fmt.Errorf("plan %s expired at %s", p.ID, p.Expires.Format(time.RFC3339))
+	}
+	payload := f.signed()
+	approved := make(map[string]bool)
+	for _, a := range f.Approvals {
+		pub, err := decode(a.Key)
+		if err != nil || !slices.ContainsFunc(approvers, func(k ed25519.PublicKey) bool { return k.Equal(ed25519.PublicKey(pub)) }) {
+			continue
+		}
+		sig, err := decode(a.Signature)
+		if err == nil && ed25519.Verify(pub, approvalMessage(payload, a.Approver, a.Time), sig) {
+			approved[a.Key] = true
+		}
+	}
+	if len(approved) < required {
+		return p, fmt.Errorf("plan %s has %d valid approvals, %d required", p.ID, len(approved), required)
+	}
+	return p, nil
+}
+
+// GenerateKey returns a new key pair as base64 strings.
+func GenerateKey() (public, private string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return encode(pub), encode(priv.Seed()), nil
+}
+
+// ParsePrivateKey decodes a base64 ed25519 seed or private key.
+func ParsePrivateKey(raw string) (ed25519.PrivateKey, error) {
+	b, err := decode(strings.TrimSpace(raw))
+	switch {
+	case err != nil:
+	case len(b) == ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case len(b) == ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(b), nil
+	}
+	return nil, errors.New("want a base64 ed25519 private key")
+}
+
+// ParsePublicKeys decodes comma-separated base64 ed25519 public keys.
+func ParsePublicKeys(raw string) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		b, err := decode(part)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key %q", part)
+		}
+		keys = append(keys, ed25519.PublicKey(b))
+	}
+	return keys, nil
+}
+
+func encode(b []byte) string { return base64.StdEncoding.EncodeToString(b) }
+
+func decode(s string) ([]byte, error) { return base64.StdEncoding.DecodeString(s) }
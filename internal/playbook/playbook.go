@@ -0,0 +1,388 @@
+/*
+File: internal/playbook/playbook.go
+Description: Playbook definitions and execution for externally triggered automation.
+A playbook is an ordered list of steps acting on the registry; triggers map inbound
+webhook names to playbooks and carry the secret used to sign their requests.
+A request signs its timestamp with its body, is accepted only within
+SignatureTolerance of that timestamp, and only once.
+*/
+package playbook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// Step actions understood by the runner.
+const (
+	ActionRefresh    = "refresh"
+	ActionSetMode    = "set_mode"
+	ActionSetStatus  = "set_status"
+	ActionDelete     = "delete"
+	ActionLogToSheet = "log_to_sheet"
+	ActionArchive    = "archive"
+)
+
+// SignatureTolerance is how far a trigger request's timestamp may be from the
+// time it arrives.
+const SignatureTolerance = 5 * time.Minute
+
+// Reasons Verify rejects a trigger request.
+var (
+	ErrBadSignature = errors.New("invalid signature")
+	ErrStale        = errors.New("timestamp missing or outside the tolerance")
+	ErrReplayed     = errors.New("request already received")
+)
+
+// defaultLogAction labels rows written by log_to_sheet without a log_action.
+const defaultLogAction = "processed"
+
+// Step is a single playbook instruction. Selector fields (Type, TitleContains,
+// Owner, IDs) narrow the registry items the step applies to; string fields may
+// reference trigger payload values as ${field}.
+type Step struct {
+	Action        string   `json:"action"`
+	Type          string   `json:"type,omitempty"`
+	TitleContains string   `json:"title_contains,omitempty"`
+	Owner         string   `json:"owner,omitempty"`
+	IDs           []string `json:"ids,omitempty"`
+	Status        string   `json:"status,omitempty"`
+	Mode          string   `json:"mode,omitempty"`
+	// Sheet and LogAction configure log_to_sheet; Sheet defaults to the
+	// server's note log spreadsheet.
+	Sheet     string `json:"sheet,omitempty"`
+	LogAction string `json:"log_action,omitempty"`
+}
+
+// Playbook is a named sequence of steps.
+type Playbook struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+// Trigger binds an inbound trigger name to a playbook. The HMAC secret is read
+// from the environment variable named by SecretEnv so it never lives in the file.
+type Trigger struct {
+	Name      string `json:"name"`
+	Playbook  string `json:"playbook"`
+	SecretEnv string `json:"secret_env"`
+}
+
+// Config is the on-disk layout of the playbook file.
+type Config struct {
+	Playbooks []Playbook `json:"playbooks"`
+	Triggers  []Trigger  `json:"triggers"`
+}
+
+// Catalog holds validated playbooks and triggers ready for lookup.
+type Catalog struct {
+	playbooks map[string]Playbook
+	triggers  map[string]Trigger
+	secrets   map[string][]byte
+
+	mu   sync.Mutex
+	seen map[string]time.Time // accepted signatures by their timestamp; guarded by mu
+}
+
+// Executor is the surface a playbook acts upon.
+type Executor interface {
+	Refresh(ctx context.Context) error
+	Items(ctx context.Context) ([]workspace.RegistryItem, error)
+	SetMode(ctx context.Context, mode string) error
+	SetStatus(ctx context.Context, item workspace.RegistryItem, status string) error
+	Delete(ctx context.Context, item workspace.RegistryItem) error
+	Archive(ctx context.Context, item workspace.RegistryItem) error
+	LogNote(ctx context.Context, item workspace.RegistryItem, sheet, action string) error
+}
+
+// StepResult reports the outcome of one step.
+type StepResult struct {
+	Action   string `json:"action"`
+	Affected int    `json:"affected"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Result reports the outcome of a playbook run.
+type Result struct {
+	Playbook string       `json:"playbook"`
+	Steps    []StepResult `json:"steps"`
+	OK       bool         `json:"ok"`
+}
+
+// Load reads and validates a playbook file.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read playbook file %s: %w", path, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse playbook file %s: %w", path, err)
+	}
+	return NewCatalog(cfg)
+}
+
+// NewCatalog validates the configuration and resolves trigger secrets.
+func NewCatalog(cfg Config) (*Catalog, error) {
+	c := &Catalog{
+		playbooks: make(map[string]Playbook, len(cfg.Playbooks)),
+		triggers:  make(map[string]Trigger, len(cfg.Triggers)),
+		secrets:   make(map[string][]byte, len(cfg.Triggers)),
+		seen:      make(map[string]time.Time),
+	}
+	for _, pb := range cfg.Playbooks {
+		if pb.Name == "" {
+			return nil, fmt.Errorf("playbook without a name")
+		}
+		if _, dup := c.playbooks[pb.Name]; dup {
+			return nil, fmt.Errorf("duplicate playbook %q", pb.Name)
+		}
+		for i, step := range pb.Steps {
+			if err := validateStep(step); err != nil {
+				return nil, fmt.Errorf("playbook %q step %d: %w", pb.Name, i+1, err)
+			}
+		}
+		c.playbooks[pb.Name] = pb
+	}
+	for _, t := range cfg.Triggers {
+		if t.Name == "" {
+			return nil, fmt.Errorf("trigger without a name")
+		}
+		if _, ok := c.playbooks[t.Playbook]; !ok {
+			return nil, fmt.Errorf("trigger %q references unknown playbook %q", t.Name, t.Playbook)
+		}
+		secret := os.Getenv(t.SecretEnv)
+		if t.SecretEnv == "" || secret == "" {
+			return nil, fmt.Errorf("trigger %q has no secret (set %s)", t.Name, t.SecretEnv)
+		}
+		c.triggers[t.Name] = t
+		c.secrets[t.Name] = []byte(secret)
+	}
+	return c, nil
+}
+
+// Playbook returns a playbook by name.
+func (c *Catalog) Playbook(name string) (Playbook, bool) {
+	if c == nil {
+		return Playbook{}, false
+	}
+	pb, ok := c.playbooks[name]
+	return pb, ok
+}
+
+// Trigger returns the trigger and its playbook by trigger name.
+func (c *Catalog) Trigger(name string) (Trigger, Playbook, bool) {
+	if c == nil {
+		return Trigger{}, Playbook{}, false
+	}
+	t, ok := c.triggers[name]
+	if !ok {
+		return Trigger{}, Playbook{}, false
+	}
+	return t, c.playbooks[t.Playbook], true
+}
+
+// Verify checks an "sha256=<hex>" signature header, the HMAC of the timestamp
+// header (Unix seconds), a dot and the body, and that the timestamp is within
+// SignatureTolerance of now. A request is only accepted once: its signature is
+// remembered until the timestamp falls out of the tolerance.
+func (c *Catalog) Verify(triggerName, timestamp string, body []byte, signature string, now time.Time) error {
+	secret, ok := c.secrets[triggerName]
+	if !ok {
+		return ErrBadSignature
+	}
+	sig, found := strings.CutPrefix(strings.TrimSpace(signature), "sha256=")
+	if !found {
+		return ErrBadSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrBadSignature
+	}
+	secs, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStale
+	}
+	at := time.Unix(secs, 0)
+	if d := now.Sub(at); d > SignatureTolerance || d < -SignatureTolerance {
+		return ErrStale
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, t := range c.seen {
+		if now.Sub(t) > SignatureTolerance {
+			delete(c.seen, key)
+		}
+	}
+	key := triggerName + ":" + hex.EncodeToString(got)
+	if _, dup := c.seen[key]; dup {
+		return ErrReplayed
+	}
+	c.seen[key] = at
+	return nil
+}
+
+// Run executes the playbook steps in order, stopping at the first failure.
+func Run(ctx context.Context, pb Playbook, exec Executor, vars map[string]string) Result {
+	res := Result{Playbook: pb.Name, OK: true}
+	for _, raw := range pb.Steps {
+		step := expandStep(raw, vars)
+		if collapsed(raw, step) {
+			// A selector referenced a payload value that was absent; never widen scope.
+			res.Steps = append(res.Steps, StepResult{Action: step.Action})
+			continue
+		}
+		affected, err := runStep(ctx, step, exec)
+		sr := StepResult{Action: step.Action, Affected: affected}
+		if err != nil {
+			sr.Error = err.Error()
+			res.OK = false
+		}
+		res.Steps = append(res.Steps, sr)
+		if err != nil {
+			break
+		}
+	}
+	return res
+}
+
+func runStep(ctx context.Context, step Step, exec Executor) (int, error) {
+	switch step.Action {
+	case ActionRefresh:
+		return 0, exec.Refresh(ctx)
+	case ActionSetMode:
+		return 0, exec.SetMode(ctx, step.Mode)
+	case ActionSetStatus, ActionDelete, ActionArchive, ActionLogToSheet:
+		items, err := exec.Items(ctx)
+		if err != nil {
+			return 0, err
+		}
+		affected := 0
+		for _, item := range items {
+			if !step.matches(item) {
+				continue
+			}
+			switch step.Action {
+			case ActionDelete:
+				err = exec.Delete(ctx, item)
+			case ActionArchive:
+				err = exec.Archive(ctx, item)
+			case ActionLogToSheet:
+				if item.Type != "keep" {
+					continue
+				}
+				action := step.LogAction
+				if action == "" {
+					action = defaultLogAction
+				}
+				err = exec.LogNote(ctx, item, step.Sheet, action)
+			default:
+				err = exec.SetStatus(ctx, item, step.Status)
+			}
+			if err != nil {
+				return affected, err
+			}
+			affected++
+		}
+		return affected, nil
+	default:
+		return 0, fmt.Errorf("unknown action %q", step.Action)
+	}
+}
+
+func (st Step) matches(item workspace.RegistryItem) bool {
+	if st.Type != "" && st.Type != item.Type {
+		return false
+	}
+	if st.TitleContains != "" && !strings.Contains(strings.ToLower(item.Title), strings.ToLower(st.TitleContains)) {
+		return false
+	}
+	if st.Owner != "" && !strings.EqualFold(st.Owner, item.Owner) {
+		return false
+	}
+	if len(st.IDs) > 0 {
+		for _, id := range st.IDs {
+			if id == item.ID {
+				return true
+			}
+		}
+		return false
+	}
+	// Destructive steps must be scoped by at least one selector.
+	return st.Type != "" || st.TitleContains != "" || st.Owner != ""
+}
+
+func collapsed(raw, expanded Step) bool {
+	return (raw.TitleContains != "" && expanded.TitleContains == "") ||
+		(raw.Owner != "" && expanded.Owner == "") ||
+		(len(raw.IDs) > 0 && len(expanded.IDs) == 0)
+}
+
+func validateStep(st Step) error {
+	switch st.Action {
+	case ActionRefresh:
+	case ActionSetMode:
+		if st.Mode == "" {
+			return fmt.Errorf("set_mode requires mode")
+		}
+	case ActionSetStatus:
+		if st.Status == "" {
+			return fmt.Errorf("set_status requires status")
+		}
+	case ActionDelete, ActionArchive:
+		if st.Type == "" && st.TitleContains == "" && st.Owner == "" && len(st.IDs) == 0 {
+			return fmt.Errorf("%s requires a selector (type, title_contains, owner or ids)", st.Action)
+		}
+	case ActionLogToSheet:
+		if st.Type != "" && st.Type != "keep" {
+			return fmt.Errorf("log_to_sheet only logs notes (type keep)")
+		}
+		if st.Type == "" && st.TitleContains == "" && st.Owner == "" && len(st.IDs) == 0 {
+			return fmt.Errorf("log_to_sheet requires a selector (type, title_contains, owner or ids)")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", st.Action)
+	}
+	return nil
+}
+
+func expandStep(st Step, vars map[string]string) Step {
+	expand := func(s string) string {
+		return os.Expand(s, func(key string) string { return vars[key] })
+	}
+	st.TitleContains = expand(st.TitleContains)
+	st.Owner = expand(st.Owner)
+	st.Status = expand(st.Status)
+	st.Mode = expand(st.Mode)
+	st.Sheet = expand(st.Sheet)
+	st.LogAction = expand(st.LogAction)
+	if len(st.IDs) > 0 {
+		ids := make([]string, 0, len(st.IDs))
+		for _, id := range st.IDs {
+			if v := expand(id); v != "" {
+				ids = append(ids, v)
+			}
+		}
+		st.IDs = ids
+	}
+	return st
+}
@@ -0,0 +1,387 @@
+/*
+File: internal/quota/quota.go
+Description: Client-side rate limiting for Google API calls, partitioned by tenant
+and user. All partitions share one token bucket, but waiting callers are served
+round-robin across partitions, so a long background sweep cannot starve another
+user's interactive requests.
+*/
+package quota
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTenant names the tenant of single-tenant deployments.
+const DefaultTenant = "default"
+
+// Background is the user partition for work not started by an API caller.
+const Background = "system"
+
+type partitionKey struct{}
+
+// Partition builds the partition name for a tenant and user.
+func Partition(tenant, user string) string {
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	if user == "" {
+		user = Background
+	}
+	return tenant + "/" + user
+}
+
+// WithPartition attributes API calls made under ctx to a partition.
+func WithPartition(ctx context.Context, partition string) context.Context {
+	return context.WithValue(ctx, partitionKey{}, partition)
+}
+
+// PartitionFrom returns the partition for ctx, defaulting to background work.
+func PartitionFrom(ctx context.Context) string {
+	if p, ok := ctx.Value(partitionKey{}).(string); ok && p != "" {
+		return p
+	}
+	return Partition("", "")
+}
+
+// Stats describes one partition's usage.
+type Stats struct {
+	Partition string        `json:"partition"`
+	Granted   uint64        `json:"granted"`
+	Cancelled uint64        `json:"cancelled"`
+	Waiting   int           `json:"waiting"`
+	WaitTotal time.Duration `json:"wait_total"`
+}
+
+type waiter struct {
+	ready    chan struct{}
+	enqueued time.Time
+}
+
+type partition struct {
+	queue []*waiter
+	stats Stats
+}
+
+// Limiter is a fair, partitioned token bucket.
+type Limiter struct {
+	interval time.Duration
+	burst    float64
+
+	mu         sync.Mutex
+	tokens     float64
+	last       time.Time
+	partitions map[string]*partition
+	ring       []string
+	next       int
+	wake       chan struct{}
+	running    bool
+}
+
+// NewLimiter allows rate requests per second with the given burst. A
+// non-positive rate returns nil, which never limits.
+func NewLimiter(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		interval:   time.Duration(float64(time.Second) / rate),
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		last:       time.Now(),
+		partitions: make(map[string]*partition),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Rate returns the requests per second allowed.
+func (l *Limiter) Rate() float64 {
+	return float64(time.Second) / float64(l.interval)
+}
+
+// Burst returns the largest burst allowed.
+func (l *Limiter) Burst() int {
+	return int(l.burst)
+}
+
+// Wait blocks until the partition may issue one request or ctx ends.
+func (l *Limiter) Wait(ctx context.Context, name string) error {
+	if l == nil {
+		return nil
+	}
+	w := &waiter{ready: make(chan struct{}), enqueued: time.Now()}
+
+	l.mu.Lock()
+	l.evictIdleLocked(w.enqueued)
+	p := l.partitionLocked(name)
+	p.queue = append(p.queue, w)
+	if !l.running {
+		l.running = true
+		go l.dispatch()
+	}
+	l.mu.Unlock()
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Granted while cancelling; the token is spent either way.
+		return nil
+	default:
+	}
+	for i, q := range p.queue {
+		if q == w {
+			p.queue = append(p.queue[:i], p.queue[i+1:]...)
+			break
+		}
+	}
+	p.stats.Cancelled++
+	return ctx.Err()
+}
+
+func (l *Limiter) partitionLocked(name string) *partition {
+	p, ok := l.partitions[name]
+	if !ok {
+		p = &partition{stats: Stats{Partition: name}}
+		l.partitions[name] = p
+		l.ring = append(l.ring, name)
+	}
+	return p
+}
+
+// evictIdleLocked drops partitions with nobody waiting once the bucket has
+// refilled, so callers that come and go do not accumulate partitions. Their
+// stats go with them.
+func (l *Limiter) evictIdleLocked(now time.Time) {
+	l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	l.last = now
+	if l.tokens < l.burst {
+		return
+	}
+	ring := l.ring[:0]
+	for _, name := range l.ring {
+		if len(l.partitions[name].queue) == 0 {
+			delete(l.partitions, name)
+			continue
+		}
+		ring = append(ring, name)
+	}
+	clear(l.ring[len(ring):])
+	l.ring = ring
+	if len(ring) == 0 {
+		l.next = 0
+	} else {
+		l.next %= len(ring)
+	}
+}
+
+// dispatch hands out tokens round-robin to partitions with waiters and exits
+// once every queue is empty.
+func (l *Limiter) dispatch() {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+		l.last = now
+
+		for l.tokens >= 1 {
+			p := l.nextWaitingLocked()
+			if p == nil {
+				break
+			}
+			w := p.queue[0]
+			p.queue = p.queue[1:]
+			p.stats.Granted++
+			p.stats.WaitTotal += now.Sub(w.enqueued)
+			l.tokens--
+			close(w.ready)
+		}
+
+		if l.nextWaitingPeekLocked() == nil {
+			l.running = false
+			l.mu.Unlock()
+			return
+		}
+		delay := time.Duration((1 - l.tokens) * float64(l.interval))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-l.wake:
+			timer.Stop()
+		}
+	}
+}
+
+func (l *Limiter) nextWaitingLocked() *partition {
+	for range l.ring {
+		name := l.ring[l.next%len(l.ring)]
+		l.next = (l.next + 1) % len(l.ring)
+		if p := l.partitions[name]; len(p.queue) > 0 {
+			return p
+		}
+	}
+	return nil
+}
+
+func (l *Limiter) nextWaitingPeekLocked() *partition {
+	for _, p := range l.partitions {
+		if len(p.queue) > 0 {
+			return p
+		}
+	}
+	return nil
+}
+
+// Stats returns per-partition usage sorted by partition name.
+func (l *Limiter) Stats() []Stats {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Stats, 0, len(l.partitions))
+	for _, p := range l.partitions {
+		st := p.stats
+		st.Waiting = len(p.queue)
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Partition < out[j].Partition })
+	return out
+}
+
+// Transport rate limits outgoing requests by the partition of their context,
+// applies per-API budgets and retries rate-limited calls.
+type Transport struct {
+	Base    http.RoundTripper
+	Limiter *Limiter
+	// APIs holds per-API limiters keyed by APIName; calls to other APIs are only
+	// subject to Limiter.
+	APIs map[string]*Limiter
+	// Retry enables retries; nil sends every request once.
+	Retry *RetryPolicy
+
+	mu      sync.Mutex
+	calls   map[string]uint64
+	retries map[string]uint64
+}
+
+// APIStats describes one API's traffic through a Transport.
+type APIStats struct {
+	API     string `json:"api"`
+	Calls   uint64 `json:"calls"`
+	Retries uint64 `json:"retries"`
+}
+
+// RoundTrip waits for the request's partition and API budget, then forwards
+// it, retrying per the policy. A retried request must have GetBody set when it
+// carries a body; otherwise it is sent once.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	api := APIName(req)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	canRetry := t.Retry != nil && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if err := t.Limiter.Wait(ctx, PartitionFrom(ctx)); err != nil {
+			return nil, err
+		}
+		if err := t.APIs[api].Wait(ctx, PartitionFrom(ctx)); err != nil {
+			return nil, err
+		}
+		out := req
+		if attempt > 0 {
+			out = req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				out.Body = body
+			}
+		}
+		t.count(api, attempt > 0)
+		resp, err := base.RoundTrip(out)
+
+		last := !canRetry || attempt >= t.Retry.Retries
+		var delay time.Duration
+		switch {
+		case last:
+			return resp, err
+		case err != nil:
+			if ctx.Err() != nil || !idempotent(req.Method) {
+				return nil, err
+			}
+			delay = t.Retry.backoff(attempt)
+		case !retryable(req.Method, resp):
+			return resp, nil
+		default:
+			delay = t.Retry.backoff(attempt)
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				if after > t.Retry.MaxDelay {
+					return resp, nil
+				}
+				delay = max(delay, after)
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (t *Transport) count(api string, retry bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.calls == nil {
+		t.calls = make(map[string]uint64)
+		t.retries = make(map[string]uint64)
+	}
+	t.calls[api]++
+	if retry {
+		t.retries[api]++
+	}
+}
+
+// Stats returns per-API call and retry counts sorted by API.
+func (t *Transport) Stats() []APIStats {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]APIStats, 0, len(t.calls))
+	for api, n := range t.calls {
+		out = append(out, APIStats{API: api, Calls: n, Retries: t.retries[api]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].API < out[j].API })
+	return out
+}
@@ -0,0 +1,161 @@
+/*
+File: internal/quota/retry.go
+Description: Retry policy and per-API budgets for Google API calls. Rate-limited
+responses (429, or 403 with a rateLimitExceeded reason) are retried with
+exponential backoff and jitter, honoring Retry-After; transient failures are
+retried only for idempotent methods so a create is never sent twice.
+*/
+package quota
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how failed API calls are retried.
+type RetryPolicy struct {
+	// Retries is the number of retries after the first attempt.
+	Retries   int
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than this is not waited
+	// out; the response is returned to the caller instead.
+	MaxDelay time.Duration
+}
+
+// DefaultRetry retries up to four times, backing off from 500ms to 30s.
+var DefaultRetry = RetryPolicy{Retries: 4, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+// backoff returns the full-jitter delay before retry n (0-based).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay << min(n, 16)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return time.Duration(rand.Int64N(int64(d)) + 1)
+}
+
+// Budget is a per-API rate limit.
+type Budget struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseBudgets reads per-API budgets such as "drive=5,keep=2:4" (requests per
+// second, optionally followed by the burst, which defaults to twice the rate).
+func ParseBudgets(raw string) (map[string]Budget, error) {
+	budgets := make(map[string]Budget)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		api, value, ok := strings.Cut(part, "=")
+		api = strings.TrimSpace(api)
+		if !ok || api == "" {
+			return nil, fmt.Errorf("invalid budget %q: want api=rate[:burst]", part)
+		}
+		rateRaw, burstRaw, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+		rate, err := strconv.ParseFloat(rateRaw, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate for %s: %q", api, rateRaw)
+		}
+		b := Budget{Rate: rate, Burst: max(1, int(2*rate))}
+		if hasBurst {
+			if b.Burst, err = strconv.Atoi(burstRaw); err != nil || b.Burst < 1 {
+				return nil, fmt.Errorf("invalid burst for %s: %q", api, burstRaw)
+			}
+		}
+		budgets[api] = b
+	}
+	return budgets, nil
+}
+
+// NewLimiters builds one limiter per budget.
+func NewLimiters(budgets map[string]Budget) map[string]*Limiter {
+	limiters := make(map[string]*Limiter, len(budgets))
+	for api, b := range budgets {
+		limiters[api] = NewLimiter(b.Rate, b.Burst)
+	}
+	return limiters
+}
+
+// APIName names the Google API a request goes to: the first host label
+// ("keep", "docs", "sheets", "admin"), or the first path segment for the shared
+// www.googleapis.com host ("drive", "calendar").
+func APIName(req *http.Request) string {
+	host := req.URL.Hostname()
+	if host == "www.googleapis.com" {
+		seg, _, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/"), "/")
+		if seg != "" {
+			return seg
+		}
+	}
+	label, _, _ := strings.Cut(host, ".")
+	return label
+}
+
+// retryable reports whether a response may be retried for the given method.
+func retryable(method string, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return rateLimited(resp)
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rateLimitReasons are the Google error reasons that mark a 403 as quota rather
+// than permission.
+var rateLimitReasons = []string{"rateLimitExceeded", "userRateLimitExceeded", "RESOURCE_EXHAUSTED"}
+
+// rateLimited peeks at a 403 body and puts back what it read.
+func rateLimited(resp *http.Response) bool {
+	head, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body = readCloser{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	for _, reason := range rateLimitReasons {
+		if bytes.Contains(head, []byte(reason)) {
+			return true
+		}
+	}
+	return false
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	raw := resp.Header.Get("Retry-After")
+	if raw == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(raw); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(raw); err == nil {
+		return max(0, t.Sub(now)), true
+	}
+	return 0, false
+}
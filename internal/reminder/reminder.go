@@ -0,0 +1,179 @@
+/*
+File: internal/reminder/reminder.go
+Description: Reminder extraction from free text. The Keep API exposes no native
+reminders, so note lines containing "remind me" phrases or recognisable dates are
+surfaced as structured reminders with a resolved due time when possible.
+*/
+package reminder
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reminder is a reminder-like line found in note text.
+type Reminder struct {
+	Text string `json:"text"`
+	// Due is the resolved date (and time when stated); nil when only a
+	// "remind me" phrase was found.
+	Due *time.Time `json:"due,omitempty"`
+	// Explicit is true when the line asks to be reminded.
+	Explicit bool `json:"explicit"`
+}
+
+var (
+	remindPattern  = regexp.MustCompile(`(?i)\b(remind me|reminder|don't forget|do not forget)\b`)
+	isoPattern     = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	slashPattern   = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{2}|\d{4})\b`)
+	monthDay       = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?\b`)
+	dayMonth       = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?(?:,?\s+(\d{4}))?\b`)
+	relativeDay    = regexp.MustCompile(`(?i)\b(today|tonight|tomorrow)\b`)
+	weekdayPattern = regexp.MustCompile(`(?i)\b(?:(next|on|this)\s+)(monday|tuesday|wednesday|thursday|friday|saturday|sunday)\b`)
+	nextWeek       = regexp.MustCompile(`(?i)\bnext week\b`)
+	inPattern      = regexp.MustCompile(`(?i)\bin (\d{1,3}) (day|days|week|weeks)\b`)
+	clockPattern   = regexp.MustCompile(`(?i)\bat (\d{1,2})(?::(\d{2}))?\s*(am|pm)?\b`)
+)
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "sept": time.September, "oct": time.October,
+	"nov": time.November, "dec": time.December,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+}
+
+// Extract scans text line by line. Relative expressions ("tomorrow", "next
+// friday") resolve against ref, normally the note's last update time.
+func Extract(text string, ref time.Time) []Reminder {
+	var out []Reminder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		explicit := remindPattern.MatchString(line)
+		due, ok := resolveDate(line, ref)
+		if !explicit && !ok {
+			continue
+		}
+		r := Reminder{Text: line, Explicit: explicit}
+		if ok {
+			due = applyClock(line, due)
+			r.Due = &due
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func resolveDate(line string, ref time.Time) (time.Time, bool) {
+	loc := ref.Location()
+	day := func(y int, m time.Month, d int) (time.Time, bool) {
+		t := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		// Reject overflowed dates such as 31/02.
+		return t, t.Month() == m && t.Day() == d
+	}
+
+	if m := isoPattern.FindStringSubmatch(line); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return day(y, time.Month(mo), d)
+	}
+	if m := slashPattern.FindStringSubmatch(line); m != nil {
+		mo, _ := strconv.Atoi(m[1])
+		d, _ := strconv.Atoi(m[2])
+		y, _ := strconv.Atoi(m[3])
+		if y < 100 {
+			y += 2000
+		}
+		return day(y, time.Month(mo), d)
+	}
+	if m := monthDay.FindStringSubmatch(line); m != nil {
+		d, _ := strconv.Atoi(m[2])
+		return withYear(day, months[strings.ToLower(m[1])], d, m[3], ref)
+	}
+	if m := dayMonth.FindStringSubmatch(line); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		return withYear(day, months[strings.ToLower(m[2])], d, m[3], ref)
+	}
+
+	base := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, loc)
+	if m := relativeDay.FindStringSubmatch(line); m != nil {
+		if strings.EqualFold(m[1], "tomorrow") {
+			return base.AddDate(0, 0, 1), true
+		}
+		return base, true
+	}
+	if m := weekdayPattern.FindStringSubmatch(line); m != nil {
+		want := weekdays[strings.ToLower(m[2])]
+		delta := (int(want) - int(base.Weekday()) + 7) % 7
+		// "on/this friday" on a Friday means today; "next friday" means a week out.
+		if delta == 0 && strings.EqualFold(m[1], "next") {
+			delta = 7
+		}
+		return base.AddDate(0, 0, delta), true
+	}
+	if nextWeek.MatchString(line) {
+		return base.AddDate(0, 0, 7), true
+	}
+	if m := inPattern.FindStringSubmatch(line); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if strings.HasPrefix(strings.ToLower(m[2]), "week") {
+			n *= 7
+		}
+		return base.AddDate(0, 0, n), true
+	}
+	return time.Time{}, false
+}
+
+// withYear uses an explicit year, or the next occurrence on or after ref.
+func withYear(day func(int, time.Month, int) (time.Time, bool), m time.Month, d int, year string, ref time.Time) (time.Time, bool) {
+	if year != "" {
+		y, _ := strconv.Atoi(year)
+		return day(y, m, d)
+	}
+	t, ok := day(ref.Year(), m, d)
+	if ok && t.Before(time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, ref.Location())) {
+		t, ok = day(ref.Year()+1, m, d)
+	}
+	return t, ok
+}
+
+func applyClock(line string, due time.Time) time.Time {
+	m := clockPattern.FindStringSubmatch(line)
+	if m == nil {
+		return due
+	}
+	h, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+	switch strings.ToLower(m[3]) {
+	case "pm":
+		if h < 12 {
+			h += 12
+		}
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	default:
+		// A bare "at 5" without minutes is too ambiguous (could be a count).
+		if m[2] == "" {
+			return due
+		}
+	}
+	if h > 23 || min > 59 {
+		return due
+	}
+	return time.Date(due.Year(), due.Month(), due.Day(), h, min, 0, 0, due.Location())
+}
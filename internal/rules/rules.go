@@ -0,0 +1,559 @@
+/*
+File: internal/rules/rules.go
+Description: Rules engine. A rule is a named conjunction of predicates over an
+item's attributes (type, title, status, word counts, last edit, ...); the engine
+evaluates rules against attribute sets built by the server from registry items,
+and picks the default status of items that have none. User rules test directory
+users, including their custom schema fields, and name a playbook to start for
+each user that comes to match. Budgets cap how many items, and how many bytes,
+may be deleted per day overall, per campaign or per rule. The file may also
+declare the status taxonomy (statuses.go).
+*/
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predicate operators.
+const (
+	OpEq        = "eq"
+	OpNe        = "ne"
+	OpLt        = "lt"
+	OpLte       = "lte"
+	OpGt        = "gt"
+	OpGte       = "gte"
+	OpContains  = "contains"
+	OpIn        = "in"
+	OpOlderThan = "older_than"
+	OpNewerThan = "newer_than"
+	OpHas       = "has"   // a comma-separated list holds the value
+	OpUnder     = "under" // an org unit path is the value or below it
+)
+
+// Attribute names the server provides for every item. Content attributes are
+// only computed when a rule references one of them.
+const (
+	AttrID        = "id"
+	AttrType      = "type"
+	AttrTitle     = "title"
+	AttrStatus    = "status"
+	AttrProtected = "protected"
+	AttrCampaign  = "campaign"
+	AttrLinkRot   = "link_rot"
+	AttrModified  = "modified"
+	AttrFolder    = "folder"
+	AttrOwner     = "owner"
+	AttrSize      = "size"
+	AttrStaleness = "staleness"
+	AttrExternal  = "external"
+
+	// Owner activity, known once the server has read login activity.
+	AttrOwnerInactive  = "owner_inactive"
+	AttrOwnerLastLogin = "owner_last_login"
+
+	// User rule attributes. Custom schema fields are "Schema.field".
+	AttrEmail     = "email"
+	AttrName      = "name"
+	AttrSuspended = "suspended"
+	AttrLastLogin = "last_login"
+	AttrOrgUnit   = "org_unit"
+	// AttrGroups lists the user's groups, comma-separated; users are only
+	// read with their groups when a user rule tests them.
+	AttrGroups = "groups"
+
+	AttrWords    = "words"
+	AttrChars    = "chars"
+	AttrLanguage = "language"
+	AttrRecency  = "recency"
+)
+
+var contentAttrs = map[string]bool{AttrWords: true, AttrChars: true, AttrLanguage: true, AttrRecency: true}
+
+var activityAttrs = map[string]bool{AttrOwnerInactive: true, AttrOwnerLastLogin: true}
+
+// Attributes are the facts about one item that predicates test. Values are
+// string, bool, float64, or time.Time.
+type Attributes map[string]any
+
+// Predicate tests one attribute. Value is compared according to Op; the age
+// operators take a duration such as "90d", "6mo", "1y", or "36h".
+type Predicate struct {
+	Field string `json:"field"`
+	Op    string `json:"op"`
+	Value any    `json:"value"`
+}
+
+// Rule matches items for which every predicate holds.
+type Rule struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	When        []Predicate `json:"when"`
+}
+
+// Default assigns Status to items without a status that are of Type (any type
+// when empty), satisfy every predicate in When, and match the named Rule when
+// one is given.
+type Default struct {
+	Type   string      `json:"type,omitempty"`
+	Rule   string      `json:"rule,omitempty"`
+	When   []Predicate `json:"when,omitempty"`
+	Status string      `json:"status"`
+}
+
+// UserRule matches directory users, for example users whose
+// Employment.offboardDate has passed ({"field": "Employment.offboardDate",
+// "op": "older_than", "value": "0d"}). Playbook is started once for each user
+// that comes to match.
+type UserRule struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	When        []Predicate `json:"when"`
+	Playbook    string      `json:"playbook,omitempty"`
+}
+
+// Matches evaluates the rule at time now.
+func (r UserRule) Matches(attrs Attributes, now time.Time) bool {
+	return Rule{When: r.When}.Matches(attrs, now)
+}
+
+// Budget limits the deletions executed per UTC day. It covers the items in
+// Campaign and matching Rule; with neither it covers every delete. A zero
+// maximum leaves that dimension unlimited.
+type Budget struct {
+	Name         string `json:"name"`
+	Campaign     string `json:"campaign,omitempty"`
+	Rule         string `json:"rule,omitempty"`
+	MaxDeletions int    `json:"max_deletions,omitempty"`
+	MaxBytes     int64  `json:"max_bytes,omitempty"`
+}
+
+// Covers reports whether a delete of the item described by attrs is charged to
+// the budget. rule is the budget's rule, if it names one.
+func (b Budget) Covers(attrs Attributes, rule Rule, now time.Time) bool {
+	if b.Campaign != "" && !equal(attrs[AttrCampaign], b.Campaign) {
+		return false
+	}
+	return b.Rule == "" || rule.Matches(attrs, now)
+}
+
+// Config is the on-disk layout of the rules file.
+type Config struct {
+	Rules []Rule     `json:"rules"`
+	Users []UserRule `json:"users,omitempty"`
+	// Defaults are tried in order; the first match wins. When absent the
+	// built-in defaults apply.
+	Defaults []Default `json:"defaults,omitempty"`
+	Budgets  []Budget  `json:"budgets,omitempty"`
+	// Statuses replaces the built-in status taxonomy.
+	Statuses *Taxonomy `json:"statuses,omitempty"`
+}
+
+// BuiltinDefaults start Keep notes as Pending and leave other items unset.
+var BuiltinDefaults = []Default{{Type: "keep", Status: "Pending"}}
+
+// Set is a validated collection of rules.
+type Set struct {
+	rules    []Rule
+	users    []UserRule
+	defaults []Default
+	budgets  []Budget
+	statuses Taxonomy
+	byName   map[string]Rule
+}
+
+// Load reads and validates a rules file.
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read rules file %s: %w", path, err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse rules file %s: %w", path, err)
+	}
+	return NewSet(cfg)
+}
+
+// NewSet validates rule names, operators, and operand types.
+func NewSet(cfg Config) (*Set, error) {
+	byName := make(map[string]Rule, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule without a name")
+		}
+		if _, dup := byName[r.Name]; dup {
+			return nil, fmt.Errorf("duplicate rule %q", r.Name)
+		}
+		byName[r.Name] = r
+		if len(r.When) == 0 {
+			return nil, fmt.Errorf("rule %q has no predicates", r.Name)
+		}
+		for i, p := range r.When {
+			if err := validatePredicate(p); err != nil {
+				return nil, fmt.Errorf("rule %q predicate %d: %w", r.Name, i+1, err)
+			}
+		}
+	}
+	statuses := BuiltinTaxonomy
+	if cfg.Statuses != nil {
+		if err := validateTaxonomy(*cfg.Statuses); err != nil {
+			return nil, err
+		}
+		statuses = *cfg.Statuses
+	}
+	// Defaults are resolved on every registry read, so they may not fetch content.
+	for i, d := range cfg.Defaults {
+		if d.Status == "" {
+			return nil, fmt.Errorf("default %d has no status", i+1)
+		}
+		if !statuses.Has(d.Status) {
+			return nil, fmt.Errorf("default %d: status %q is not among the statuses (%s)", i+1, d.Status, strings.Join(statuses.Values, ", "))
+		}
+		checks := d.When
+		if d.Rule != "" {
+			rule, ok := byName[d.Rule]
+			if !ok {
+				return nil, fmt.Errorf("default %d: unknown rule %q", i+1, d.Rule)
+			}
+			checks = append(slices.Clip(checks), rule.When...)
+		}
+		for j, p := range checks {
+			if contentAttrs[p.Field] {
+				return nil, fmt.Errorf("default %d: content field %q is not allowed in defaults", i+1, p.Field)
+			}
+			if j < len(d.When) {
+				if err := validatePredicate(p); err != nil {
+					return nil, fmt.Errorf("default %d predicate %d: %w", i+1, j+1, err)
+				}
+			}
+		}
+	}
+	userNames := make(map[string]bool, len(cfg.Users))
+	for _, r := range cfg.Users {
+		if r.Name == "" {
+			return nil, fmt.Errorf("user rule without a name")
+		}
+		if userNames[r.Name] {
+			return nil, fmt.Errorf("duplicate user rule %q", r.Name)
+		}
+		userNames[r.Name] = true
+		if len(r.When) == 0 {
+			return nil, fmt.Errorf("user rule %q has no predicates", r.Name)
+		}
+		for i, p := range r.When {
+			if contentAttrs[p.Field] {
+				return nil, fmt.Errorf("user rule %q: content field %q does not apply to users", r.Name, p.Field)
+			}
+			if err := validatePredicate(p); err != nil {
+				return nil, fmt.Errorf("user rule %q predicate %d: %w", r.Name, i+1, err)
+			}
+		}
+	}
+	budgetNames := make(map[string]bool, len(cfg.Budgets))
+	for i, b := range cfg.Budgets {
+		switch {
+		case b.Name == "":
+			return nil, fmt.Errorf("budget %d has no name", i+1)
+		case budgetNames[b.Name]:
+			return nil, fmt.Errorf("duplicate budget %q", b.Name)
+		case b.MaxDeletions < 0 || b.MaxBytes < 0:
+			return nil, fmt.Errorf("budget %q: negative maximum", b.Name)
+		case b.MaxDeletions == 0 && b.MaxBytes == 0:
+			return nil, fmt.Errorf("budget %q sets neither max_deletions nor max_bytes", b.Name)
+		}
+		budgetNames[b.Name] = true
+		if _, ok := byName[b.Rule]; b.Rule != "" && !ok {
+			return nil, fmt.Errorf("budget %q: unknown rule %q", b.Name, b.Rule)
+		}
+	}
+	defaults := cfg.Defaults
+	if defaults == nil {
+		defaults = BuiltinDefaults
+		for _, d := range defaults {
+			if !statuses.Has(d.Status) {
+				return nil, fmt.Errorf("statuses: the built-in default status %q is missing; declare defaults", d.Status)
+			}
+		}
+	}
+	return &Set{rules: cfg.Rules, users: cfg.Users, defaults: defaults, budgets: cfg.Budgets, statuses: statuses, byName: byName}, nil
+}
+
+// Statuses returns the status taxonomy.
+func (s *Set) Statuses() Taxonomy {
+	if s == nil {
+		return BuiltinTaxonomy
+	}
+	return s.statuses
+}
+
+// Defaults returns the default status assignments in evaluation order.
+func (s *Set) Defaults() []Default {
+	if s == nil {
+		return BuiltinDefaults
+	}
+	return s.defaults
+}
+
+// DefaultStatus returns the status of the first default matching an item, or
+// "" when none does.
+func (s *Set) DefaultStatus(attrs Attributes, now time.Time) string {
+	for _, d := range s.Defaults() {
+		if d.Type != "" && !equal(attrs[AttrType], d.Type) {
+			continue
+		}
+		if d.Rule != "" && !s.byName[d.Rule].Matches(attrs, now) {
+			continue
+		}
+		if !(Rule{When: d.When}).Matches(attrs, now) {
+			continue
+		}
+		return d.Status
+	}
+	return ""
+}
+
+// Rules returns the rules in file order.
+func (s *Set) Rules() []Rule {
+	if s == nil {
+		return nil
+	}
+	return s.rules
+}
+
+// UserRules returns the user rules in file order.
+func (s *Set) UserRules() []UserRule {
+	if s == nil {
+		return nil
+	}
+	return s.users
+}
+
+// Budgets returns the deletion budgets in file order.
+func (s *Set) Budgets() []Budget {
+	if s == nil {
+		return nil
+	}
+	return s.budgets
+}
+
+// Rule returns the named rule.
+func (s *Set) Rule(name string) (Rule, bool) {
+	if s == nil {
+		return Rule{}, false
+	}
+	r, ok := s.byName[name]
+	return r, ok
+}
+
+// NeedsContent reports whether any rule tests a content attribute, which
+// requires fetching item bodies.
+func (s *Set) NeedsContent() bool {
+	for _, r := range s.Rules() {
+		if r.NeedsContent() {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsActivity reports whether any rule or default tests owner activity, which
+// requires reading the directory and login reports.
+func (s *Set) NeedsActivity() bool {
+	var checks []Predicate
+	for _, r := range s.Rules() {
+		checks = append(checks, r.When...)
+	}
+	for _, d := range s.Defaults() {
+		checks = append(checks, d.When...)
+	}
+	for _, p := range checks {
+		if activityAttrs[p.Field] {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsGroups reports whether any user rule tests group membership.
+func (s *Set) NeedsGroups() bool {
+	for _, r := range s.UserRules() {
+		for _, p := range r.When {
+			if p.Field == AttrGroups {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NeedsContent reports whether the rule tests a content attribute.
+func (r Rule) NeedsContent() bool {
+	for _, p := range r.When {
+		if contentAttrs[p.Field] {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches evaluates the rule at time now. A missing attribute fails its predicate.
+func (r Rule) Matches(attrs Attributes, now time.Time) bool {
+	for _, p := range r.When {
+		if !p.Matches(attrs, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches evaluates one predicate.
+func (p Predicate) Matches(attrs Attributes, now time.Time) bool {
+	actual, ok := attrs[p.Field]
+	if !ok {
+		return false
+	}
+	switch p.Op {
+	case OpOlderThan, OpNewerThan:
+		t, ok := actual.(time.Time)
+		if !ok || t.IsZero() {
+			return false
+		}
+		age, _ := ParseAge(fmt.Sprint(p.Value))
+		if p.Op == OpOlderThan {
+			return now.Sub(t) > age
+		}
+		return now.Sub(t) < age
+	case OpIn:
+		list, _ := p.Value.([]any)
+		for _, v := range list {
+			if equal(actual, v) {
+				return true
+			}
+		}
+		return false
+	case OpContains:
+		return strings.Contains(strings.ToLower(fmt.Sprint(actual)), strings.ToLower(fmt.Sprint(p.Value)))
+	case OpHas:
+		want := strings.TrimSpace(fmt.Sprint(p.Value))
+		for _, entry := range strings.Split(fmt.Sprint(actual), ",") {
+			if strings.EqualFold(strings.TrimSpace(entry), want) {
+				return true
+			}
+		}
+		return false
+	case OpUnder:
+		path, unit := fmt.Sprint(actual), strings.TrimSuffix(fmt.Sprint(p.Value), "/")
+		return unit == "" || path == unit || strings.HasPrefix(path, unit+"/")
+	case OpEq:
+		return equal(actual, p.Value)
+	case OpNe:
+		return !equal(actual, p.Value)
+	}
+
+	a, aok := number(actual)
+	b, bok := number(p.Value)
+	if !aok || !bok {
+		return false
+	}
+	switch p.Op {
+	case OpLt:
+		return a < b
+	case OpLte:
+		return a <= b
+	case OpGt:
+		return a > b
+	case OpGte:
+		return a >= b
+	}
+	return false
+}
+
+func validatePredicate(p Predicate) error {
+	if p.Field == "" {
+		return fmt.Errorf("missing field")
+	}
+	switch p.Op {
+	case OpEq, OpNe, OpContains, OpHas:
+	case OpUnder:
+		if v, ok := p.Value.(string); !ok || !strings.HasPrefix(v, "/") {
+			return fmt.Errorf("under needs an org unit path such as \"/Contractors\"")
+		}
+	case OpLt, OpLte, OpGt, OpGte:
+		if _, ok := number(p.Value); !ok {
+			return fmt.Errorf("%s needs a numeric value", p.Op)
+		}
+	case OpIn:
+		if _, ok := p.Value.([]any); !ok {
+			return fmt.Errorf("in needs a list value")
+		}
+	case OpOlderThan, OpNewerThan:
+		if _, err := ParseAge(fmt.Sprint(p.Value)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown op %q", p.Op)
+	}
+	return nil
+}
+
+// ParseAge parses an age such as "45d", "2w", "6mo", "1y", or any
+// time.ParseDuration value. Months are 30 days and years 365 days.
+func ParseAge(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(strings.ToLower(raw))
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"mo", 30 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"w", 7 * 24 * time.Hour},
+		{"y", 365 * 24 * time.Hour},
+	}
+	for _, u := range units {
+		if n, ok := strings.CutSuffix(raw, u.suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v >= 0 {
+				return time.Duration(v) * u.unit, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", raw)
+	}
+	return d, nil
+}
+
+func equal(actual, want any) bool {
+	if a, ok := number(actual); ok {
+		b, ok := number(want)
+		return ok && a == b
+	}
+	if a, ok := actual.(bool); ok {
+		b, ok := want.(bool)
+		return ok && a == b
+	}
+	return strings.EqualFold(fmt.Sprint(actual), fmt.Sprint(want))
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
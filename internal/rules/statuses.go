@@ -0,0 +1,110 @@
+/*
+File: internal/rules/statuses.go
+Description: Status taxonomy. The rules file may declare the statuses items can
+take and, per status, the statuses it may change to; without a declaration the
+built-in Pending/Review/Archive/Execute statuses apply and any change between
+them is allowed.
+*/
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrUnknownStatus rejects a status outside the taxonomy.
+	ErrUnknownStatus = errors.New("unknown status")
+	// ErrTransition rejects a change the taxonomy does not allow.
+	ErrTransition = errors.New("status change not allowed")
+)
+
+// Taxonomy is the set of statuses and the changes allowed between them, for
+// example {"values": ["Keep", "Review", "Archive", "Delete"], "transitions":
+// {"Keep": ["Review"], "Review": ["Keep", "Archive", "Delete"], "Delete": []}}.
+// A status without a transitions entry may change to any other; an empty list
+// makes it final.
+type Taxonomy struct {
+	Values      []string            `json:"values"`
+	Transitions map[string][]string `json:"transitions,omitempty"`
+}
+
+// BuiltinTaxonomy covers the statuses Axis itself assigns: Pending notes wait
+// for review and Execute marks an item for deletion.
+var BuiltinTaxonomy = Taxonomy{Values: []string{"Pending", "Review", "Archive", "Execute"}}
+
+// Canonical returns the taxonomy's spelling of status, matched without regard
+// to case.
+func (t Taxonomy) Canonical(status string) (string, bool) {
+	for _, v := range t.Values {
+		if strings.EqualFold(v, status) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Has reports whether status is in the taxonomy, spelled exactly.
+func (t Taxonomy) Has(status string) bool {
+	return slices.Contains(t.Values, status)
+}
+
+// Next lists the statuses from may change to, in taxonomy order. A status
+// outside the taxonomy, such as one set before it was declared, or none at
+// all, may change to any.
+func (t Taxonomy) Next(from string) []string {
+	allowed, ok := t.Transitions[from]
+	if !ok || !t.Has(from) {
+		allowed = t.Values
+	}
+	out := make([]string, 0, len(allowed))
+	for _, v := range t.Values {
+		if v != from && slices.Contains(allowed, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Check validates a change from one status to another and returns the
+// canonical spelling of to. Setting the current status again is always
+// allowed.
+func (t Taxonomy) Check(from, to string) (string, error) {
+	canonical, ok := t.Canonical(to)
+	if !ok {
+		return "", fmt.Errorf("%w %q (want %s)", ErrUnknownStatus, to, strings.Join(t.Values, ", "))
+	}
+	if canonical == from || slices.Contains(t.Next(from), canonical) {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("%w: %s to %s", ErrTransition, from, canonical)
+}
+
+func validateTaxonomy(t Taxonomy) error {
+	if len(t.Values) == 0 {
+		return fmt.Errorf("statuses: no values")
+	}
+	for i, v := range t.Values {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("statuses: value %d is empty", i+1)
+		}
+		for _, prev := range t.Values[:i] {
+			if strings.EqualFold(prev, v) {
+				return fmt.Errorf("statuses: duplicate value %q", v)
+			}
+		}
+	}
+	for from, to := range t.Transitions {
+		if !t.Has(from) {
+			return fmt.Errorf("statuses: transitions from unknown status %q", from)
+		}
+		for _, v := range to {
+			if !t.Has(v) {
+				return fmt.Errorf("statuses: transition %s to unknown status %q", from, v)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+/*
+File: internal/scheduler/scheduler.go
+Description: Per-type refresh schedules for the registry poller. Each item type
+(Keep, Docs, Sheets) has its own interval, falling back to a default, and the
+poller asks which types are due on every tick.
+*/
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MinInterval bounds schedules so a typo cannot hammer the Workspace APIs.
+const MinInterval = 5 * time.Second
+
+// Config is the schedule as exchanged over /api/config.
+type Config struct {
+	Default Duration             `json:"poll_interval"`
+	Types   map[string]Duration  `json:"schedules"`
+	NextDue map[string]time.Time `json:"next_due,omitempty"`
+}
+
+// Duration marshals as a Go duration string ("90s", "10m").
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Scheduler tracks when each item type is next due for a refresh.
+type Scheduler struct {
+	mu        sync.Mutex
+	types     []string
+	def       time.Duration
+	intervals map[string]time.Duration
+	next      map[string]time.Time
+}
+
+// New schedules types every def, with per-type overrides.
+func New(types []string, def time.Duration, overrides map[string]time.Duration) (*Scheduler, error) {
+	s := &Scheduler{types: types, next: make(map[string]time.Time)}
+	if err := s.Update(def, overrides); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseSchedule reads "keep=1m,doc=10m" into per-type intervals.
+func ParseSchedule(raw string) (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("schedule entry %q is not type=interval", part)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("schedule entry %q: %w", part, err)
+		}
+		out[strings.TrimSpace(name)] = d
+	}
+	return out, nil
+}
+
+// Update replaces the default interval and overrides. Types keep their next due
+// time unless the new interval brings it closer.
+func (s *Scheduler) Update(def time.Duration, overrides map[string]time.Duration) error {
+	if def < MinInterval {
+		return fmt.Errorf("poll interval %s is below the %s minimum", def, MinInterval)
+	}
+	intervals := make(map[string]time.Duration, len(overrides))
+	for name, d := range overrides {
+		if !s.known(name) {
+			return fmt.Errorf("unknown item type %q in schedule", name)
+		}
+		if d < MinInterval {
+			return fmt.Errorf("%s interval %s is below the %s minimum", name, d, MinInterval)
+		}
+		intervals[name] = d
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.def, s.intervals = def, intervals
+	now := time.Now()
+	for _, t := range s.types {
+		if due := now.Add(s.intervalLocked(t)); s.next[t].IsZero() || due.Before(s.next[t]) {
+			s.next[t] = due
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) known(name string) bool {
+	for _, t := range s.types {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) intervalLocked(t string) time.Duration {
+	if d, ok := s.intervals[t]; ok {
+		return d
+	}
+	return s.def
+}
+
+// Merge changes the default when def is non-zero and sets the given per-type
+// overrides, keeping the others.
+func (s *Scheduler) Merge(def time.Duration, overrides map[string]time.Duration) error {
+	s.mu.Lock()
+	merged := make(map[string]time.Duration, len(s.intervals)+len(overrides))
+	for name, d := range s.intervals {
+		merged[name] = d
+	}
+	if def == 0 {
+		def = s.def
+	}
+	s.mu.Unlock()
+	for name, d := range overrides {
+		merged[name] = d
+	}
+	return s.Update(def, merged)
+}
+
+// Due returns the types whose refresh is due at now and schedules their next run.
+func (s *Scheduler) Due(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []string
+	for _, t := range s.types {
+		if !now.Before(s.next[t]) {
+			due = append(due, t)
+			s.next[t] = now.Add(s.intervalLocked(t))
+		}
+	}
+	return due
+}
+
+// Until returns the time from now to the next due refresh of any type.
+func (s *Scheduler) Until(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var soonest time.Time
+	for _, t := range s.types {
+		if soonest.IsZero() || s.next[t].Before(soonest) {
+			soonest = s.next[t]
+		}
+	}
+	return max(0, soonest.Sub(now))
+}
+
+// Reset restarts every type's interval from now, e.g. while polling is paused.
+func (s *Scheduler) Reset(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.types {
+		s.next[t] = now.Add(s.intervalLocked(t))
+	}
+}
+
+// Config reports the current schedule.
+func (s *Scheduler) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cfg := Config{
+		Default: Duration(s.def),
+		Types:   make(map[string]Duration, len(s.types)),
+		NextDue: make(map[string]time.Time, len(s.types)),
+	}
+	for _, t := range s.types {
+		cfg.Types[t] = Duration(s.intervalLocked(t))
+		cfg.NextDue[t] = s.next[t]
+	}
+	return cfg
+}
+
+// String formats the intervals as "keep=1m0s,doc=10m0s" for logging.
+func (s *Scheduler) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parts := make([]string, len(s.types))
+	for i, t := range s.types {
+		parts[i] = t + "=" + s.intervalLocked(t).String()
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,250 @@
+/*
+File: internal/selfupdate/selfupdate.go
+Description: Binary self-update from a release manifest. The manifest names one
+signed binary per platform; a download must match its SHA-256 and carry a valid
+ed25519 signature from the configured release key before it is staged. The
+staged binary has to pass a health check before it replaces the running one,
+and the installed binary is checked again, restoring the previous one if it
+fails. The previous binary is kept for a manual rollback.
+*/
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxBinarySize bounds a download.
+	maxBinarySize = 512 << 20
+	// healthTimeout bounds one health check run.
+	healthTimeout = 15 * time.Second
+)
+
+// Manifest is the release endpoint's response.
+type Manifest struct {
+	Version string           `json:"version"`
+	Notes   string           `json:"notes,omitempty"`
+	Assets  map[string]Asset `json:"assets"` // keyed by GOOS/GOARCH
+}
+
+// Asset is one platform's binary. Signature is the base64 ed25519 signature of
+// the binary itself; a relative URL is resolved against the manifest's.
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// Platform is this build's asset key.
+func Platform() string { return runtime.GOOS + "/" + runtime.GOARCH }
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(raw string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("want a base64 ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Updater fetches and installs releases.
+type Updater struct {
+	ManifestURL string
+	PublicKey   ed25519.PublicKey
+	Client      *http.Client
+}
+
+func (u *Updater) client() *http.Client {
+	if u.Client != nil {
+		return u.Client
+	}
+	return http.DefaultClient
+}
+
+// Check fetches the manifest and returns it with this platform's asset.
+func (u *Updater) Check(ctx context.Context) (Manifest, Asset, error) {
+	var m Manifest
+	body, err := u.get(ctx, u.ManifestURL, 1<<20)
+	if err != nil {
+		return m, Asset{}, fmt.Errorf("release manifest: %w", err)
+	}
+	if err := json.Unmarshal(body, &m); err != nil {
+		return m, Asset{}, fmt.Errorf("invalid release manifest: %w", err)
+	}
+	if m.Version == "" {
+		return m, Asset{}, errors.New("release manifest has no version")
+	}
+	asset, ok := m.Assets[Platform()]
+	if !ok {
+		return m, Asset{}, fmt.Errorf("release %s has no binary for %s", m.Version, Platform())
+	}
+	return m, asset, nil
+}
+
+// Download fetches an asset and verifies its checksum and signature.
+func (u *Updater) Download(ctx context.Context, asset Asset) ([]byte, error) {
+	base, err := url.Parse(u.ManifestURL)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(asset.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset URL %q", asset.URL)
+	}
+	data, err := u.get(ctx, base.ResolveReference(ref).String(), maxBinarySize)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), asset.SHA256) {
+		return nil, errors.New("download does not match the manifest checksum")
+	}
+	sig, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil || !ed25519.Verify(u.PublicKey, data, sig) {
+		return nil, errors.New("release signature is invalid")
+	}
+	return data, nil
+}
+
+func (u *Updater) get(ctx context.Context, target string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", target, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s exceeds %d bytes", target, limit)
+	}
+	return data, nil
+}
+
+// HealthCheck runs `<path> version` and requires it to report want.
+func HealthCheck(ctx context.Context, path, want string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "version").Output()
+	if err != nil {
+		return fmt.Errorf("health check: %w", err)
+	}
+	got := strings.TrimSpace(string(bytes.TrimPrefix(out, []byte("axis "))))
+	if got != want {
+		return fmt.Errorf("health check: binary reports version %q, want %q", got, want)
+	}
+	return nil
+}
+
+// Install replaces the binary at exe with data, which must report version.
+// The replaced binary is kept at exe+".old". Renaming works on a running
+// binary on every supported platform, so the calling process is unaffected.
+func Install(ctx context.Context, exe string, data []byte, version string) error {
+	staged := exe + ".new"
+	if err := os.WriteFile(staged, data, 0o755); err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := HealthCheck(ctx, staged, version); err != nil {
+		os.Remove(staged)
+		return err
+	}
+
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("failed to move the current binary aside: %w", err)
+	}
+	if err := os.Rename(staged, exe); err != nil {
+		os.Rename(old, exe)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	if err := HealthCheck(ctx, exe, version); err != nil {
+		os.Remove(exe)
+		if rerr := os.Rename(old, exe); rerr != nil {
+			return fmt.Errorf("%w; rollback failed: %v (previous binary is at %s)", err, rerr, old)
+		}
+		return fmt.Errorf("%w; rolled back", err)
+	}
+	return nil
+}
+
+// Rollback restores the binary kept by the last Install.
+func Rollback(exe string) error {
+	old := exe + ".old"
+	if _, err := os.Stat(old); err != nil {
+		return fmt.Errorf("no previous binary at %s", old)
+	}
+	failed := exe + ".failed"
+	os.Remove(failed)
+	if err := os.Rename(exe, failed); err != nil {
+		return err
+	}
+	if err := os.Rename(old, exe); err != nil {
+		os.Rename(failed, exe)
+		return err
+	}
+	os.Remove(failed)
+	return nil
+}
+
+// Newer reports whether version a is newer than b. Versions compare by their
+// dot-separated numeric parts, ignoring a leading "v"; a build without a
+// release version ("dev") is older than any release.
+func Newer(a, b string) bool {
+	pa, pb := versionParts(a), versionParts(b)
+	if pb == nil {
+		return pa != nil
+	}
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+func versionParts(v string) []int {
+	v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	var parts []int
+	for _, p := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,161 @@
+/*
+File: internal/server/about.go
+Description: Deployment report. The startup configuration (version, tenant,
+scopes, state backend, enabled modules, safety posture) is assembled once at
+boot, logged as a single structured record, printed as a banner and served at
+GET /api/about with the live mode and uptime for support diagnostics.
+*/
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Module is an optional subsystem turned on by configuration.
+type Module struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Safety summarizes what the deployment is able to change.
+type Safety struct {
+	Mode     string `json:"mode"`
+	AirGap   bool   `json:"air_gap"`
+	ReadOnly bool   `json:"read_only"` // only read-only Workspace scopes requested
+	// Auth lists the accepted credentials ("api_key", "oidc", "token"); "disabled"
+	// means every caller is admin, and an empty list refuses every request.
+	Auth               []string `json:"auth"`
+	LegacyGETMutations bool     `json:"legacy_get_mutations"`
+	DirectoryWrite     bool     `json:"directory_write"`
+	FaultInjection     bool     `json:"fault_injection"`
+}
+
+// About describes a running deployment.
+type About struct {
+	Version        string    `json:"version"`
+	GoVersion      string    `json:"go_version"`
+	StartedAt      time.Time `json:"started_at"`
+	Uptime         string    `json:"uptime,omitempty"`
+	Tenant         string    `json:"tenant"`
+	Admin          string    `json:"admin"`
+	ServiceAccount string    `json:"service_account"`
+	Scopes         []string  `json:"scopes"`
+	StateBackend   string    `json:"state_backend"`
+	Listen         string    `json:"listen"`
+	Enrichers      []string  `json:"enrichers"`
+	Modules        []Module  `json:"modules"`
+	// Disabled lists the configured features turned off at startup because
+	// the credentials lack their scopes; Detail is the reason.
+	Disabled []Module `json:"disabled,omitempty"`
+	Safety   Safety   `json:"safety"`
+}
+
+// Enable records an enabled module.
+func (a *About) Enable(name, detail string) {
+	a.Modules = append(a.Modules, Module{Name: name, Detail: detail})
+}
+
+// Disable records a configured feature that was turned off.
+func (a *About) Disable(name, reason string) {
+	a.Disabled = append(a.Disabled, Module{Name: name, Detail: reason})
+}
+
+// WithAbout supplies the startup report; the server adds what it resolves
+// itself, such as the restored mode and the enrichment pipeline.
+func WithAbout(a About) Option {
+	return func(s *Server) { s.about = a }
+}
+
+// About returns the deployment report as of now.
+func (s *Server) About() About {
+	a := s.about
+	a.GoVersion = runtime.Version()
+	a.Safety.Mode = s.currentMode()
+	a.Safety.AirGap = s.airgap != nil
+	a.Safety.FaultInjection = s.faults != nil
+	if s.auth != nil {
+		a.Safety.Auth = s.auth.Methods()
+	}
+	a.Enrichers = make([]string, 0, len(s.enrichers))
+	for _, e := range s.enrichers {
+		a.Enrichers = append(a.Enrichers, e.Name())
+	}
+	if a.Modules == nil {
+		a.Modules = []Module{}
+	}
+	if !a.StartedAt.IsZero() {
+		a.Uptime = time.Since(a.StartedAt).Round(time.Second).String()
+	}
+	return a
+}
+
+// logStartup writes the report as one structured record.
+func (s *Server) logStartup() {
+	a := s.About()
+	modules := make([]string, len(a.Modules))
+	for i, m := range a.Modules {
+		modules[i] = m.Name
+	}
+	disabled := make([]string, len(a.Disabled))
+	for i, m := range a.Disabled {
+		disabled[i] = m.Name
+	}
+	s.logger.Info("axis starting",
+		"version", a.Version, "go_version", a.GoVersion, "tenant", a.Tenant,
+		"admin", a.Admin, "service_account", a.ServiceAccount, "scopes", a.Scopes,
+		"state_backend", a.StateBackend, "listen", a.Listen,
+		"enrichers", a.Enrichers, "modules", modules, "disabled", disabled, "safety", a.Safety)
+}
+
+// PrintBanner writes the report for people watching the console.
+func (a About) PrintBanner(w io.Writer) {
+	auth := strings.Join(a.Safety.Auth, ", ")
+	if auth == "" {
+		auth = "none (all requests refused)"
+	}
+	fmt.Fprintf(w, "axis %s (%s)\n", a.Version, a.GoVersion)
+	fmt.Fprintf(w, "  tenant      %s as %s via %s\n", a.Tenant, a.Admin, a.ServiceAccount)
+	fmt.Fprintf(w, "  listen      %s\n", a.Listen)
+	fmt.Fprintf(w, "  state       %s\n", a.StateBackend)
+	fmt.Fprintf(w, "  mode        %s (air gap %t, read-only scopes %t)\n", a.Safety.Mode, a.Safety.AirGap, a.Safety.ReadOnly)
+	fmt.Fprintf(w, "  auth        %s\n", auth)
+	fmt.Fprintf(w, "  scopes      %s\n", strings.Join(a.Scopes, " "))
+	fmt.Fprintf(w, "  enrichers   %s\n", strings.Join(a.Enrichers, ", "))
+	for _, m := range a.Modules {
+		if m.Detail != "" {
+			fmt.Fprintf(w, "  module      %s: %s\n", m.Name, m.Detail)
+		} else {
+			fmt.Fprintf(w, "  module      %s\n", m.Name)
+		}
+	}
+	var warnings []string
+	if a.Safety.LegacyGETMutations {
+		warnings = append(warnings, "legacy GET mutations enabled")
+	}
+	if a.Safety.DirectoryWrite {
+		warnings = append(warnings, "directory write access")
+	}
+	if a.Safety.FaultInjection {
+		warnings = append(warnings, "fault injection build")
+	}
+	if len(a.Safety.Auth) == 1 && a.Safety.Auth[0] == "disabled" {
+		warnings = append(warnings, "authentication disabled")
+	}
+	for _, m := range a.Disabled {
+		warnings = append(warnings, fmt.Sprintf("%s disabled: %s", m.Name, m.Detail))
+	}
+	for _, msg := range warnings {
+		fmt.Fprintf(w, "  warning     %s\n", msg)
+	}
+}
+
+func (s *Server) handleAbout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.About())
+}
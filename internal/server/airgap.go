@@ -0,0 +1,244 @@
+/*
+File: internal/server/airgap.go
+Description: AIRGAP mode. The server holds read-only Workspace access, so
+deletions and sheet edits are queued as proposed plan actions instead of being
+made. Operators export the queue as a plan file signed with the Axis key; the
+plan is approved and applied offline with `axis plan`. The queue survives
+restarts in the plan directory.
+*/
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/plan"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const pendingPlanFile = "pending.json"
+
+var errAirGapDisabled = errors.New("air-gapped mode is not enabled")
+
+// airGap holds the proposal queue of an air-gapped server.
+type airGap struct {
+	dir string
+	key ed25519.PrivateKey
+
+	mu      sync.Mutex
+	pending []plan.Action
+}
+
+// PlanResponse is the body of GET /api/plan.
+type PlanResponse struct {
+	Mode    string        `json:"mode"`
+	Pending []plan.Action `json:"pending"`
+}
+
+// WithAirGap locks the server into AIRGAP mode, queueing proposals in dir and
+// signing exported plans with key.
+func WithAirGap(dir string, key ed25519.PrivateKey) Option {
+	return func(s *Server) { s.airgap = &airGap{dir: dir, key: key} }
+}
+
+// applyAirGap loads the proposal queue and pins the mode after state is
+// restored; a server without the option leaves a persisted AIRGAP for MANUAL.
+func (s *Server) applyAirGap() {
+	if s.airgap == nil {
+		if s.currentMode() == "AIRGAP" {
+			s.swapMode("MANUAL")
+		}
+		return
+	}
+	s.swapMode("AIRGAP")
+	data, err := os.ReadFile(filepath.Join(s.airgap.dir, pendingPlanFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.logger.Error("failed to load pending plan", "error", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(data, &s.airgap.pending); err != nil {
+		s.logger.Error("failed to load pending plan", "error", err)
+	}
+}
+
+// checkModeChange refuses leaving AIRGAP on an air-gapped server and entering
+// it on any other.
+func (s *Server) checkModeChange(mode string) error {
+	switch {
+	case s.airgap != nil && mode != "AIRGAP":
+		return fmt.Errorf("mode is locked to AIRGAP")
+	case s.airgap == nil && mode == "AIRGAP":
+		return errAirGapDisabled
+	}
+	return nil
+}
+
+// savePending writes the queue; callers hold the lock.
+func (a *airGap) savePending() error {
+	if err := os.MkdirAll(a.dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(a.pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(a.dir, pendingPlanFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// propose queues an action, replacing an earlier proposal for the same change.
+func (s *Server) propose(ctx context.Context, action plan.Action) error {
+	a := s.airgap
+	a.mu.Lock()
+	a.pending = slices.DeleteFunc(a.pending, func(p plan.Action) bool { return p.Key() == action.Key() })
+	a.pending = append(a.pending, action)
+	err := a.savePending()
+	queued := len(a.pending)
+	a.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to queue proposal: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "proposed", "op", action.Op, "id", action.ItemID, "title", action.Title, "actor", action.Actor, "pending", queued)
+	s.events.Emit(events.Event{
+		Type:    events.TypePlanProposed,
+		Actor:   action.Actor,
+		Subject: action.ItemID,
+		Data:    map[string]any{"op": action.Op, "type": action.ItemType, "title": action.Title, "pending": queued},
+	})
+	return nil
+}
+
+// proposeDeletion queues a delete and audits it as proposed.
+func (s *Server) proposeDeletion(ctx context.Context, item workspace.RegistryItem, actor string) error {
+	err := s.propose(ctx, plan.Action{
+		Op:       plan.OpDelete,
+		ItemID:   item.ID,
+		ItemType: item.Type,
+		Title:    item.Title,
+		Modified: item.Modified,
+		Actor:    actor,
+		Proposed: time.Now().UTC(),
+	})
+	d := store.Deletion{
+		Time:     time.Now().UTC(),
+		ItemID:   item.ID,
+		ItemType: item.Type,
+		Title:    item.Title,
+		Actor:    actor,
+		Mode:     "AIRGAP",
+		Outcome:  store.OutcomeProposed,
+	}
+	if err != nil {
+		d.Outcome, d.Detail = store.OutcomeFailed, err.Error()
+	}
+	if werr := s.store.RecordDeletion(context.WithoutCancel(ctx), d); werr != nil {
+		s.logger.ErrorContext(ctx, "audit write failed", "id", item.ID, "outcome", d.Outcome, "error", werr)
+	}
+	return err
+}
+
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	if s.airgap == nil {
+		apiError(w, errAirGapDisabled.Error(), http.StatusNotFound)
+		return
+	}
+	s.airgap.mu.Lock()
+	pending := slices.Clone(s.airgap.pending)
+	s.airgap.mu.Unlock()
+	if pending == nil {
+		pending = []plan.Action{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PlanResponse{Mode: s.currentMode(), Pending: pending})
+}
+
+// handlePlanExport signs the queued proposals as a plan, saves it in the plan
+// directory, clears the queue and returns the plan file for download.
+func (s *Server) handlePlanExport(w http.ResponseWriter, r *http.Request) {
+	a := s.airgap
+	if a == nil {
+		apiError(w, errAirGapDisabled.Error(), http.StatusNotFound)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.pending) == 0 {
+		apiError(w, "no pending proposals", http.StatusConflict)
+		return
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	p := plan.Plan{
+		ID:      "plan-" + now.Format("20060102-150405"),
+		Created: now,
+		Expires: now.Add(plan.DefaultValidity),
+		Actions: a.pending,
+	}
+	if s.user != nil {
+		p.Origin = s.user.Email
+	}
+	f, err := plan.Sign(p, a.key)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	name := p.ID + ".json"
+	if err := f.Write(filepath.Join(a.dir, name)); err != nil {
+		apiError(w, "failed to write plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	exported := a.pending
+	a.pending = nil
+	if err := a.savePending(); err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to clear pending plan", "error", err)
+	}
+
+	s.logger.InfoContext(r.Context(), "plan exported", "plan", p.ID, "actions", len(exported))
+	s.events.Emit(events.Event{
+		Type:    events.TypePlanExported,
+		Actor:   actorFrom(r.Context()),
+		Subject: p.ID,
+		Data:    map[string]any{"actions": len(exported)},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	json.NewEncoder(w).Encode(f)
+}
+
+// handlePlanDiscard drops the queued proposals.
+func (s *Server) handlePlanDiscard(w http.ResponseWriter, r *http.Request) {
+	a := s.airgap
+	if a == nil {
+		apiError(w, errAirGapDisabled.Error(), http.StatusNotFound)
+		return
+	}
+	a.mu.Lock()
+	discarded := len(a.pending)
+	a.pending = nil
+	err := a.savePending()
+	a.mu.Unlock()
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "pending plan discarded", "actions", discarded, "actor", actorFrom(r.Context()))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,39 @@
+/*
+File: internal/server/analysis.go
+Description: Inventory analytics for dashboards. GET /api/analysis/stale
+aggregates the cached, enriched registry into staleness counts per item type
+and the largest Drive files.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"axis/internal/analytics"
+)
+
+// maxAnalysisTop bounds ?top= of GET /api/analysis/stale.
+const maxAnalysisTop = 100
+
+// handleStaleAnalysis serves GET /api/analysis/stale[?top=].
+func (s *Server) handleStaleAnalysis(w http.ResponseWriter, r *http.Request) {
+	top := analytics.DefaultTop
+	if raw := r.URL.Query().Get("top"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > maxAnalysisTop {
+			apiError(w, "invalid top (0-100)", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+	items, err := s.RegistrySnapshot(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(analytics.Stale(items, time.Now(), top))
+}
@@ -0,0 +1,181 @@
+/*
+File: internal/server/apierror.go
+Description: Structured API errors. Every error response is a JSON envelope
+{"error": {"code", "message", "source", "retryable"}}. Google API failures are
+mapped from their HTTP status to the matching client status (not found,
+forbidden, rate limited, upstream failure) and described without the raw
+Google error text.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Error codes.
+const (
+	CodeBadRequest   = "bad_request"
+	CodeNotFound     = "not_found"
+	CodeForbidden    = "forbidden"
+	CodeUnauthorized = "unauthorized"
+	CodeConflict     = "conflict"
+	CodeRateLimited  = "rate_limited"
+	CodeUpstream     = "upstream_error"
+	CodeTimeout      = "timeout"
+	CodeInternal     = "internal"
+	CodeUnavailable  = "unavailable"
+)
+
+// Error sources: Axis itself, or the Google API it called.
+const (
+	SourceAxis   = "axis"
+	SourceGoogle = "google"
+)
+
+// APIError describes a failed request.
+type APIError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Source    string `json:"source"`
+	Retryable bool   `json:"retryable"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ErrorResponse is the body of every API error.
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// apiError replies with an Axis-side error; it takes http.Error's arguments.
+func apiError(w http.ResponseWriter, message string, status int) {
+	writeErrorEnvelope(w, status, APIError{
+		Code:      statusCode(status),
+		Message:   message,
+		Source:    SourceAxis,
+		Retryable: status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable,
+	})
+}
+
+// writeAPIError replies with err. Google API errors choose their own status;
+// anything else is reported with the given one.
+func writeAPIError(w http.ResponseWriter, err error, status int) {
+	var gerr *googleapi.Error
+	var uerr *url.Error
+	switch {
+	case errors.As(err, &gerr):
+		status, e := googleError(err, gerr)
+		writeErrorEnvelope(w, status, e)
+	case errors.Is(err, context.DeadlineExceeded):
+		writeErrorEnvelope(w, http.StatusGatewayTimeout, APIError{
+			Code: CodeTimeout, Message: contextMessage(err, context.DeadlineExceeded.Error(), "timed out"), Source: SourceAxis, Retryable: true,
+		})
+	case errors.As(err, &uerr):
+		// Transport failures name the Google endpoint; keep only our context.
+		writeErrorEnvelope(w, http.StatusBadGateway, APIError{
+			Code: CodeUpstream, Message: contextMessage(err, uerr.Error(), "google api unreachable"), Source: SourceGoogle, Retryable: true,
+		})
+	default:
+		apiError(w, err.Error(), status)
+	}
+}
+
+// googleError maps a Google API error. Client errors keep Google's short
+// message, which explains the problem; server and credential errors do not.
+func googleError(err error, gerr *googleapi.Error) (int, APIError) {
+	e := APIError{Source: SourceGoogle}
+	detail := strings.ToLower(http.StatusText(gerr.Code))
+	if gerr.Code >= 400 && gerr.Code < 500 && gerr.Code != http.StatusUnauthorized && gerr.Message != "" {
+		detail = gerr.Message
+	}
+	var status int
+	switch {
+	case gerr.Code == http.StatusNotFound:
+		status, e.Code = http.StatusNotFound, CodeNotFound
+	case gerr.Code == http.StatusForbidden && rateLimitedError(gerr):
+		status, e.Code, e.Retryable = http.StatusTooManyRequests, CodeRateLimited, true
+	case gerr.Code == http.StatusForbidden:
+		status, e.Code = http.StatusForbidden, CodeForbidden
+	case gerr.Code == http.StatusUnauthorized:
+		// Axis's own credentials were refused; the caller cannot fix that.
+		status, e.Code = http.StatusBadGateway, CodeUnauthorized
+	case gerr.Code == http.StatusConflict || gerr.Code == http.StatusPreconditionFailed:
+		status, e.Code = http.StatusConflict, CodeConflict
+	case gerr.Code == http.StatusTooManyRequests:
+		status, e.Code, e.Retryable = http.StatusTooManyRequests, CodeRateLimited, true
+	case gerr.Code >= 500:
+		status, e.Code, e.Retryable = http.StatusBadGateway, CodeUpstream, true
+	default:
+		status, e.Code = http.StatusBadRequest, CodeBadRequest
+	}
+	if e.Code == CodeRateLimited {
+		detail = "rate limit exceeded"
+	}
+	e.Message = contextMessage(err, gerr.Error(), detail)
+	return status, e
+}
+
+// rateLimitedError tells quota 403s from permission 403s.
+func rateLimitedError(gerr *googleapi.Error) bool {
+	for _, item := range gerr.Errors {
+		for _, reason := range []string{"rateLimitExceeded", "userRateLimitExceeded", "quotaExceeded"} {
+			if item.Reason == reason {
+				return true
+			}
+		}
+	}
+	return strings.Contains(gerr.Body, "RESOURCE_EXHAUSTED")
+}
+
+// contextMessage replaces the cause's text at the end of err's message with
+// detail, keeping the context Axis added ("unable to get note notes/a: ...").
+func contextMessage(err error, cause, detail string) string {
+	full := err.Error()
+	if prefix, ok := strings.CutSuffix(full, cause); ok {
+		if prefix = strings.TrimSuffix(prefix, ": "); prefix != "" {
+			return prefix + ": " + detail
+		}
+	}
+	return detail
+}
+
+// statusCode names the error code for an Axis-side status.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusMethodNotAllowed:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway:
+		return CodeUpstream
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	return CodeInternal
+}
+
+func writeErrorEnvelope(w http.ResponseWriter, status int, e APIError) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	e.RequestID = h.Get(requestIDHeader)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: e})
+}
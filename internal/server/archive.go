@@ -0,0 +1,202 @@
+/*
+File: internal/server/archive.go
+Description: The archive action. Archiving moves a Doc or Sheet into the
+configured Drive folder, and exports a Keep note to a Doc there before removing
+the note. Operators archive from the dashboard or POST /api/registry/archive,
+playbooks with the archive step, and in AUTO mode the poller archives items
+whose status is Archive.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// archiveStatus marks items the AUTO poller archives.
+const archiveStatus = "Archive"
+
+var (
+	errArchiveDisabled = errors.New("archive folder not configured")
+	errArchiveAirGap   = errors.New("archiving moves files, which AIRGAP mode cannot do")
+)
+
+// ArchiveResult is the response to POST /api/registry/archive. Doc is the
+// Doc a Keep note was exported to.
+type ArchiveResult struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title,omitempty"`
+	Folder    string `json:"folder"`
+	Doc       string `json:"doc,omitempty"`
+	Simulated bool   `json:"simulated,omitempty"`
+}
+
+// WithArchiveFolder enables archiving into the Drive folder folderID.
+func WithArchiveFolder(folderID string) Option {
+	return func(s *Server) { s.archiveFolder = folderID }
+}
+
+// archiveRegistryItem archives item under the current mode. Protected items
+// are refused like deletes; SIMULATE mode only reports what would move.
+func (s *Server) archiveRegistryItem(ctx context.Context, item workspace.RegistryItem) (ArchiveResult, error) {
+	res := ArchiveResult{ID: item.ID, Type: item.Type, Title: item.Title, Folder: s.archiveFolder}
+	if s.archiveFolder == "" {
+		return res, errArchiveDisabled
+	}
+	switch item.Type {
+	case "keep", "doc", "sheet":
+	default:
+		return res, fmt.Errorf("unsupported item type %q", item.Type)
+	}
+	if err := s.checkProtected(item); err != nil {
+		return res, err
+	}
+	actor, mode := actorFrom(ctx), s.currentMode()
+	switch mode {
+	case "AIRGAP":
+		return res, errArchiveAirGap
+	case "SIMULATE":
+		res.Simulated = true
+		s.logger.InfoContext(ctx, "would archive", "id", item.ID, "type", item.Type, "title", item.Title, "folder", s.archiveFolder, "actor", actor)
+		s.events.Emit(events.Event{
+			Type:    events.TypeItemWouldArchive,
+			Actor:   actor,
+			Subject: item.ID,
+			Data:    map[string]any{"type": item.Type, "title": item.Title, "folder": s.archiveFolder, "mode": mode},
+		})
+		return res, nil
+	}
+
+	if item.Type == "keep" {
+		if s.attachmentFolder != "" {
+			if _, err := s.syncAttachments(ctx, item); err != nil {
+				return res, err
+			}
+		}
+		doc, err := s.ws.ExportNoteToDoc(ctx, item.ID, s.archiveFolder)
+		if err != nil {
+			return res, err
+		}
+		res.Doc = doc
+		// The exported Doc is the note's backup in the deletion history.
+		err = s.ws.DeleteNote(ctx, item.ID)
+		s.recordDeletion(ctx, item, actor, mode, doc, err)
+		if err != nil {
+			return res, fmt.Errorf("exported note to doc %s but unable to remove it: %w", doc, err)
+		}
+	} else if err := s.ws.MoveToFolder(ctx, item.ID, s.archiveFolder); err != nil {
+		return res, err
+	}
+	s.clearDeadline(item.ID)
+
+	s.logger.InfoContext(ctx, "item archived", "id", item.ID, "type", item.Type, "title", item.Title, "folder", s.archiveFolder, "doc", res.Doc, "actor", actor)
+	data := map[string]any{"type": item.Type, "title": item.Title, "folder": s.archiveFolder, "mode": mode}
+	if res.Doc != "" {
+		data["doc"] = res.Doc
+	}
+	s.events.Emit(events.Event{
+		Type:    events.TypeItemArchived,
+		Actor:   actor,
+		Subject: item.ID,
+		Data:    data,
+	})
+	return res, nil
+}
+
+// executeArchives archives the items whose status is Archive, outside the
+// poller like executeDeadlines. A failed item waits deadlineRetry before the
+// next attempt.
+func (s *Server) executeArchives(ctx context.Context, now time.Time) {
+	if s.archiveFolder == "" || !s.rules.Statuses().Has(archiveStatus) {
+		return
+	}
+	var due []string
+	s.archiveMu.Lock()
+	for id, status := range s.currentStatuses() {
+		if retry, failed := s.archiveRetry[id]; status == archiveStatus && (!failed || !retry.After(now)) {
+			due = append(due, id)
+		}
+	}
+	s.archiveMu.Unlock()
+	if len(due) == 0 || !s.archiveRunning.CompareAndSwap(false, true) {
+		return
+	}
+	slices.Sort(due)
+
+	go func() {
+		defer s.archiveRunning.Store(false)
+		ctx := withActor(ctx, "archive")
+		archived := false
+		for _, id := range due {
+			if ctx.Err() != nil || s.currentMode() != "AUTO" {
+				break
+			}
+			item := s.registryItem(id, "")
+			if item.Type == "" {
+				continue
+			}
+			_, err := s.archiveRegistryItem(ctx, item)
+			s.archiveMu.Lock()
+			if err != nil {
+				s.archiveRetry[id] = time.Now().Add(deadlineRetry)
+			} else {
+				delete(s.archiveRetry, id)
+			}
+			s.archiveMu.Unlock()
+			if err != nil {
+				s.logger.Warn("archive failed", "id", id, "retry_in", deadlineRetry, "error", err)
+				continue
+			}
+			archived = true
+		}
+		if archived {
+			s.refreshAndBroadcast()
+		}
+	}()
+}
+
+// handleArchive serves POST /api/registry/archive?id=&type=.
+func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	id := q.Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	item := s.registryItem(id, q.Get("type"))
+	switch item.Type {
+	case "keep", "doc", "sheet":
+	default:
+		apiError(w, "unknown item type (pass type=keep, doc or sheet)", http.StatusBadRequest)
+		return
+	}
+	res, err := s.archiveRegistryItem(r.Context(), item)
+	switch {
+	case errors.Is(err, errArchiveDisabled):
+		writeAPIError(w, err, http.StatusNotFound)
+		return
+	case errors.Is(err, errArchiveAirGap):
+		writeAPIError(w, err, http.StatusConflict)
+		return
+	case errors.Is(err, errItemProtected):
+		writeAPIError(w, err, http.StatusForbidden)
+		return
+	case err != nil:
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !res.Simulated {
+		s.refreshAndBroadcast()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,64 @@
+/*
+File: internal/server/attachments.go
+Description: Keep attachment download endpoint. Streams media bytes from the Keep
+API straight to the client so large images and audio never sit in server memory.
+*/
+package server
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"axis/internal/workspace"
+)
+
+func (s *Server) handleAttachment(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		apiError(w, "missing name", http.StatusBadRequest)
+		return
+	}
+
+	// The Keep media endpoint requires an explicit MIME type to return bytes.
+	mimeType := r.URL.Query().Get("mime")
+	if mimeType == "" {
+		meta, err := s.wsFor(r.Context()).GetAttachmentMetadata(r.Context(), name)
+		if err != nil {
+			writeAPIError(w, err, http.StatusInternalServerError)
+			return
+		}
+		if len(meta.MimeType) > 0 {
+			mimeType = meta.MimeType[0]
+		}
+	}
+
+	media, err := s.wsFor(r.Context()).OpenAttachmentMedia(r.Context(), name, mimeType)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	defer media.Body.Close()
+
+	contentType := media.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": workspace.AttachmentFilename(name, contentType),
+	}))
+	if media.ContentLength > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(media.ContentLength, 10))
+	}
+
+	written, err := io.Copy(w, media.Body)
+	if err != nil {
+		// Headers are already sent; the client sees a truncated body.
+		s.logger.ErrorContext(r.Context(), "attachment stream interrupted", "name", name, "bytes", written, "error", err)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "attachment streamed", "name", name, "bytes", written)
+}
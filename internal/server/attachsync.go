@@ -0,0 +1,104 @@
+/*
+File: internal/server/attachsync.go
+Description: Note attachment sync. With an attachment folder configured, Axis
+copies a Keep note's attachments to Drive before deleting or archiving the
+note, refusing the change when the copy fails, and POST
+/api/notes/attachments/sync copies them on demand.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+var errAttachmentSyncDisabled = errors.New("attachment folder not configured")
+
+// AttachmentSyncResponse is the body of POST /api/notes/attachments/sync.
+type AttachmentSyncResponse struct {
+	Note        string                       `json:"note"`
+	Folder      string                       `json:"folder"`
+	Attachments []workspace.SyncedAttachment `json:"attachments"`
+}
+
+// WithAttachmentFolder copies Keep note attachments into the Drive folder
+// folderID.
+func WithAttachmentFolder(folderID string) Option {
+	return func(s *Server) { s.attachmentFolder = folderID }
+}
+
+// syncAttachments copies a note's attachments to the attachment folder and
+// emits attachments.synced when any were new.
+func (s *Server) syncAttachments(ctx context.Context, item workspace.RegistryItem) ([]workspace.SyncedAttachment, error) {
+	if s.attachmentFolder == "" {
+		return nil, errAttachmentSyncDisabled
+	}
+	synced, err := s.ws.SyncNoteAttachments(context.WithoutCancel(ctx), item.ID, s.attachmentFolder)
+	var files []string
+	for _, a := range synced {
+		if !a.Existing {
+			files = append(files, a.File)
+		}
+	}
+	if len(files) > 0 {
+		s.logger.InfoContext(ctx, "attachments synced", "id", item.ID, "folder", s.attachmentFolder, "uploaded", len(files), "total", len(synced))
+		s.events.Emit(events.Event{
+			Type:    events.TypeAttachmentsSynced,
+			Actor:   actorFrom(ctx),
+			Subject: item.ID,
+			Data:    map[string]any{"title": item.Title, "folder": s.attachmentFolder, "files": files},
+		})
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "attachment sync failed", "id", item.ID, "folder", s.attachmentFolder, "synced", len(synced), "error", err)
+		return synced, err
+	}
+	return synced, nil
+}
+
+// backupAttachments syncs a note's attachments ahead of its removal and
+// returns the audit reference to the folder, or "" when the note has none.
+func (s *Server) backupAttachments(ctx context.Context, item workspace.RegistryItem) (string, error) {
+	synced, err := s.syncAttachments(ctx, item)
+	if err != nil || len(synced) == 0 {
+		return "", err
+	}
+	return "drive-folder:" + s.attachmentFolder, nil
+}
+
+// handleAttachmentSync serves POST /api/notes/attachments/sync?id=.
+func (s *Server) handleAttachmentSync(w http.ResponseWriter, r *http.Request) {
+	if s.attachmentFolder == "" {
+		writeAPIError(w, errAttachmentSyncDisabled, http.StatusNotFound)
+		return
+	}
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "syncing attachments writes to Drive, which AIRGAP mode cannot do", http.StatusConflict)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	item := s.registryItem(id, "keep")
+	if item.Type != "keep" {
+		apiError(w, "only Keep notes have attachments", http.StatusBadRequest)
+		return
+	}
+	synced, err := s.syncAttachments(r.Context(), item)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if synced == nil {
+		synced = []workspace.SyncedAttachment{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AttachmentSyncResponse{Note: item.ID, Folder: s.attachmentFolder, Attachments: synced})
+}
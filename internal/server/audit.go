@@ -0,0 +1,126 @@
+/*
+File: internal/server/audit.go
+Description: Audit log of destructive operations. Every delete attempt is written to
+the state store with actor and mode; /api/audit queries it by time range and item
+type and exports CSV for compliance review.
+*/
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const defaultAuditLimit = 1000
+
+// recordDeletion writes the audit record for a delete attempt. The write uses a
+// detached context so a cancelled request cannot drop the record.
+func (s *Server) recordDeletion(ctx context.Context, item workspace.RegistryItem, actor, mode, backup string, deleteErr error) {
+	d := store.Deletion{
+		Time:     time.Now().UTC(),
+		ItemID:   item.ID,
+		ItemType: item.Type,
+		Title:    item.Title,
+		Actor:    actor,
+		Mode:     mode,
+		Outcome:  store.OutcomeDeleted,
+		Backup:   backup,
+	}
+	switch {
+	case errors.Is(deleteErr, errItemProtected), errors.Is(deleteErr, errBudgetExhausted):
+		d.Outcome, d.Detail = store.OutcomeRefused, deleteErr.Error()
+	case deleteErr != nil:
+		d.Outcome, d.Detail = store.OutcomeFailed, deleteErr.Error()
+	}
+	if err := s.store.RecordDeletion(context.WithoutCancel(ctx), d); err != nil {
+		s.logger.ErrorContext(ctx, "audit write failed", "id", item.ID, "outcome", d.Outcome, "error", err)
+	}
+}
+
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	records, err := s.store.ListDeletions(r.Context(), q)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if strings.EqualFold(r.URL.Query().Get("format"), "csv") {
+		writeAuditCSV(w, records)
+		return
+	}
+	if records == nil {
+		records = []store.Deletion{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// parseAuditQuery reads since/until (RFC 3339 or YYYY-MM-DD), type (comma
+// separated item types), and limit.
+func parseAuditQuery(r *http.Request) (store.Query, error) {
+	v := r.URL.Query()
+	q := store.Query{Limit: defaultAuditLimit}
+
+	var err error
+	if q.Since, err = parseTimeParam(v.Get("since")); err != nil {
+		return q, fmt.Errorf("invalid since: %w", err)
+	}
+	if q.Until, err = parseTimeParam(v.Get("until")); err != nil {
+		return q, fmt.Errorf("invalid until: %w", err)
+	}
+	for _, t := range strings.Split(v.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			q.Types = append(q.Types, t)
+		}
+	}
+	if raw := v.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid limit")
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
+
+func parseTimeParam(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+func writeAuditCSV(w http.ResponseWriter, records []store.Deletion) {
+	filename := fmt.Sprintf("axis-audit-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "item_id", "item_type", "title", "actor", "mode", "outcome", "detail", "backup"})
+	for _, d := range records {
+		cw.Write([]string{
+			d.Time.UTC().Format(time.RFC3339),
+			d.ItemID, d.ItemType, d.Title, d.Actor, d.Mode, d.Outcome, d.Detail, d.Backup,
+		})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,86 @@
+/*
+File: internal/server/auth.go
+Description: Route access control. Every API route declares the least role it
+needs, and the scope minted tokens need for it; the guard authenticates the
+request, rejects insufficient roles and scopes, and
+records the principal as the actor for audit and events and as the partition
+its Google API calls draw quota from.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"axis/internal/auth"
+	"axis/internal/quota"
+)
+
+// WithAuth sets the authenticator guarding the API. Without it every guarded
+// request is rejected.
+func WithAuth(a *auth.Authenticator) Option {
+	return func(s *Server) {
+		s.auth = a
+		a.UseTokens(tokenResolver{s})
+	}
+}
+
+// requires returns the same role for every request.
+func requires(role auth.Role) func(*http.Request) auth.Role {
+	return func(*http.Request) auth.Role { return role }
+}
+
+// requiresWhen escalates to elevated when the query carries param, e.g. the
+// mode endpoint reads for viewers but changes mode only for operators.
+func requiresWhen(param string, base, elevated auth.Role) func(*http.Request) auth.Role {
+	return func(r *http.Request) auth.Role {
+		if r.URL.Query().Has(param) {
+			return elevated
+		}
+		return base
+	}
+}
+
+func (s *Server) guard(need func(*http.Request) auth.Role, h http.HandlerFunc) http.HandlerFunc {
+	return s.guardScoped(need, "", h)
+}
+
+// guardScoped is guard for a route minted tokens may call with scope; see
+// checkTokenScope.
+func (s *Server) guardScoped(need func(*http.Request) auth.Role, scope string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.auth.Authenticate(r)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if !errors.Is(err, auth.ErrUnauthenticated) {
+				status = http.StatusInternalServerError
+			}
+			writeAPIError(w, err, status)
+			return
+		}
+		want := need(r)
+		if p.Role < want {
+			apiError(w, "requires "+want.String()+" role", http.StatusForbidden)
+			return
+		}
+		if p.Method == auth.MethodToken {
+			if err := s.checkTokenScope(r, p, want, scope); err != nil {
+				writeAPIError(w, err, http.StatusForbidden)
+				return
+			}
+		}
+		ctx := auth.WithPrincipal(r.Context(), p)
+		ctx = quota.WithPartition(ctx, quota.Partition(quota.DefaultTenant, p.Name))
+		if !s.auth.IsDisabled() {
+			ctx = withActor(ctx, p.Name)
+		}
+		h(w, r.WithContext(ctx))
+	}
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,137 @@
+/*
+File: internal/server/auth_test.go
+Description: Route access control: roles gate routes, minted tokens are held to
+their expiry and scopes, and cookie sessions cannot mutate state cross-site.
+*/
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"axis/internal/auth"
+	"axis/internal/store"
+)
+
+// rolesServer returns a server with one API key per role, presented as the
+// role's name, and the minted tokens toks by secret.
+func rolesServer(t *testing.T, toks map[string]store.Token) *Server {
+	t.Helper()
+	var keys []auth.APIKey
+	for _, role := range []string{"viewer", "operator", "admin"} {
+		env := "AXIS_TEST_KEY_" + role
+		t.Setenv(env, role)
+		keys = append(keys, auth.APIKey{Name: role, Role: role, KeyEnv: env})
+	}
+	a, err := auth.New(auth.Config{APIKeys: keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := store.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	for secret, tok := range toks {
+		tok.ID, tok.Digest = tok.Name, auth.TokenDigest(secret)
+		if err := st.SaveToken(context.Background(), tok); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewServer(nil, nil, WithStore(st), WithAuth(a))
+}
+
+// ok answers 204 for whatever passes the guard.
+func ok(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+
+func serve(h http.HandlerFunc, key string) int {
+	r := httptest.NewRequest(http.MethodPost, "/api/status?id=notes/a", nil)
+	if key != "" {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	h(rec, r)
+	return rec.Code
+}
+
+func TestGuardRoles(t *testing.T) {
+	s := rolesServer(t, nil)
+	for _, tc := range []struct {
+		need auth.Role
+		key  string
+		want int
+	}{
+		{auth.RoleViewer, "", http.StatusUnauthorized},
+		{auth.RoleViewer, "nonsense", http.StatusUnauthorized},
+		{auth.RoleViewer, "viewer", http.StatusNoContent},
+		{auth.RoleOperator, "viewer", http.StatusForbidden},
+		{auth.RoleOperator, "operator", http.StatusNoContent},
+		{auth.RoleAdmin, "operator", http.StatusForbidden},
+		{auth.RoleAdmin, "admin", http.StatusNoContent},
+	} {
+		if got := serve(s.guard(requires(tc.need), ok), tc.key); got != tc.want {
+			t.Errorf("%v route, key %q: status %d, want %d", tc.need, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestGuardTokens(t *testing.T) {
+	now := time.Now()
+	s := rolesServer(t, map[string]store.Token{
+		"axt_read":    {Name: "read", Scopes: []string{auth.ScopeRegistryRead}, Expires: now.Add(time.Hour)},
+		"axt_status":  {Name: "status", Scopes: []string{auth.ScopeStatusWrite}, Expires: now.Add(time.Hour)},
+		"axt_expired": {Name: "expired", Scopes: []string{auth.ScopeRegistryRead, auth.ScopeStatusWrite}, Expires: now.Add(-time.Minute)},
+	})
+	viewer := s.guard(requires(auth.RoleViewer), ok)
+	statusWrite := s.guardScoped(requires(auth.RoleOperator), auth.ScopeStatusWrite, ok)
+	unscoped := s.guard(requires(auth.RoleOperator), ok)
+	for _, tc := range []struct {
+		name  string
+		route http.HandlerFunc
+		key   string
+		want  int
+	}{
+		{"read token reads", viewer, "axt_read", http.StatusNoContent},
+		{"read token writes status", statusWrite, "axt_read", http.StatusForbidden},
+		{"status token writes status", statusWrite, "axt_status", http.StatusNoContent},
+		{"status token reads", viewer, "axt_status", http.StatusForbidden},
+		{"token on unscoped route", unscoped, "axt_status", http.StatusForbidden},
+		{"expired token", viewer, "axt_expired", http.StatusUnauthorized},
+		{"unknown token", viewer, "axt_unknown", http.StatusUnauthorized},
+	} {
+		if got := serve(tc.route, tc.key); got != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMutationRefusesCrossSite(t *testing.T) {
+	s := rolesServer(t, nil)
+	h := s.mutation(ok)
+	for _, tc := range []struct {
+		name, method      string
+		fetchSite, origin string
+		want              int
+	}{
+		{"session same-origin", auth.MethodOIDC, "same-origin", "", http.StatusNoContent},
+		{"session cross-site", auth.MethodOIDC, "cross-site", "", http.StatusForbidden},
+		{"session same-site", auth.MethodOIDC, "same-site", "", http.StatusForbidden},
+		{"session origin matches", auth.MethodOIDC, "", "https://axis.example.com", http.StatusNoContent},
+		{"session origin differs", auth.MethodOIDC, "", "https://evil.example.com", http.StatusForbidden},
+		{"session without headers", auth.MethodOIDC, "", "", http.StatusForbidden},
+		{"api key cross-site", auth.MethodAPIKey, "cross-site", "https://evil.example.com", http.StatusNoContent},
+		{"token cross-site", auth.MethodToken, "cross-site", "https://evil.example.com", http.StatusNoContent},
+		{"disabled cross-site", auth.MethodDisabled, "cross-site", "", http.StatusForbidden},
+		{"disabled without headers", auth.MethodDisabled, "", "", http.StatusNoContent},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "https://axis.example.com/api/mode?set=MANUAL", nil)
+		r.Header.Set("Sec-Fetch-Site", tc.fetchSite)
+		r.Header.Set("Origin", tc.origin)
+		r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Name: "p", Role: auth.RoleAdmin, Method: tc.method}))
+		rec := httptest.NewRecorder()
+		h(rec, r)
+		if rec.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
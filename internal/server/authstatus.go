@@ -0,0 +1,112 @@
+/*
+File: internal/server/authstatus.go
+Description: Token refresh watchdog. The token sources of the Google API
+clients are asked for a token every minute; a source that stops refreshing
+(revoked delegation, an expired or deleted key, a lapsed OAuth grant) is
+announced with an auth-degraded SSE event and again with auth-restored once it
+recovers. GET /api/auth/status reports every source with its scopes and last
+refresh.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/credentials"
+	"axis/internal/events"
+)
+
+const tokenCheckInterval = time.Minute
+
+// AuthStatusResponse is the body of GET /api/auth/status.
+type AuthStatusResponse struct {
+	Strategy string                    `json:"strategy"`
+	Healthy  bool                      `json:"healthy"`
+	Sources  []credentials.TokenStatus `json:"sources"`
+	// Keys are the rotation slots, when key rotation is configured.
+	Keys []credentials.KeyStatus `json:"keys,omitempty"`
+}
+
+// WithTokenMonitor watches the token sources registered with m.
+func WithTokenMonitor(m *credentials.Monitor) Option {
+	return func(s *Server) { s.tokens = m }
+}
+
+// runTokenWatchdog checks every token source periodically. Each instance
+// checks its own, since every instance refreshes its own tokens.
+func (s *Server) runTokenWatchdog(ctx context.Context) {
+	if s.tokens == nil {
+		return
+	}
+	s.tokens.OnChange(s.tokenHealthChanged)
+	ticker := time.NewTicker(tokenCheckInterval)
+	defer ticker.Stop()
+	for {
+		s.tokens.Check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) tokenHealthChanged(st credentials.TokenStatus) {
+	typ, kind := broker.TypeAuthRestored, events.TypeAuthRestored
+	if st.Healthy {
+		s.logger.Info("token source recovered", "source", st.Name, "subject", st.Subject)
+	} else {
+		typ, kind = broker.TypeAuthDegraded, events.TypeAuthDegraded
+		s.logger.Warn("token source failing", "source", st.Name, "subject", st.Subject, "error", st.Error)
+	}
+	if data, err := json.Marshal(st); err == nil {
+		// Token health is per instance, so it is not relayed to the cluster.
+		s.hub.Publish(broker.Event{Type: typ, Data: data})
+	}
+	payload := map[string]any{"subject": st.Subject, "scopes": st.Scopes}
+	if st.Error != "" {
+		payload["error"] = st.Error
+	}
+	s.events.Emit(events.Event{Type: kind, Actor: "watchdog", Subject: st.Name, Data: payload})
+}
+
+// tokenHealthEvents tells a new subscriber about the sources failing now.
+func (s *Server) tokenHealthEvents() []broker.Event {
+	if s.tokens == nil {
+		return nil
+	}
+	var out []broker.Event
+	for _, st := range s.tokens.Status() {
+		if st.Healthy {
+			continue
+		}
+		if data, err := json.Marshal(st); err == nil {
+			out = append(out, broker.Event{Type: broker.TypeAuthDegraded, Data: data})
+		}
+	}
+	return out
+}
+
+func (s *Server) handleAuthStatus(w http.ResponseWriter, r *http.Request) {
+	resp := AuthStatusResponse{Strategy: credentials.StrategyImpersonate, Healthy: true, Sources: []credentials.TokenStatus{}}
+	if s.tokens != nil {
+		resp.Strategy = s.tokens.Strategy()
+		if truthyParam(r.URL.Query().Get("check")) {
+			resp.Sources = s.tokens.Check(r.Context())
+		} else {
+			resp.Sources = s.tokens.Status()
+		}
+	}
+	if s.credentials != nil {
+		resp.Keys = s.credentials.Status()
+	}
+	for _, st := range resp.Sources {
+		resp.Healthy = resp.Healthy && st.Healthy
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
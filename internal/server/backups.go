@@ -0,0 +1,40 @@
+/*
+File: internal/server/backups.go
+Description: Backups before content changes. Deleting a Doc or Sheet, editing a
+Doc and writing Sheet cells first back the file up (its current revision and,
+with a backup directory, an export); deleting a note first copies its
+attachments to the attachment folder, when one is set. The change is refused
+when the backup fails, and the backup is linked from the audit record or event.
+*/
+package server
+
+import (
+	"context"
+
+	"axis/internal/backup"
+	"axis/internal/workspace"
+)
+
+// WithBackups backs up Docs and Sheets through b before Axis changes them.
+func WithBackups(b *backup.Backups) Option {
+	return func(s *Server) { s.backups = b }
+}
+
+// backupItem backs item up and returns the backup's audit reference. Notes
+// without attachments to sync, and every item without backups configured,
+// yield "".
+func (s *Server) backupItem(ctx context.Context, item workspace.RegistryItem) (string, error) {
+	if item.Type == "keep" && s.attachmentFolder != "" {
+		return s.backupAttachments(ctx, item)
+	}
+	if s.backups == nil || !backup.Supports(item.Type) {
+		return "", nil
+	}
+	b, err := s.backups.Take(context.WithoutCancel(ctx), item.ID, item.Type)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "backup failed; change refused", "id", item.ID, "type", item.Type, "error", err)
+		return "", err
+	}
+	s.logger.InfoContext(ctx, "backup taken", "id", item.ID, "revision", b.Revision, "path", b.Path, "bytes", b.Bytes, "sha256", b.SHA256)
+	return b.Ref(), nil
+}
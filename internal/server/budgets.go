@@ -0,0 +1,230 @@
+/*
+File: internal/server/budgets.go
+Description: Deletion budgets. Budgets from the rules file cap the deletes
+executed per UTC day, overall or per campaign or rule; each executed delete is
+charged to every budget covering the item and refused when one would be
+exceeded. GET /api/budgets reports today's consumption. Usage is restored from
+the item.deleted events of the day, so a restart does not reset it.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/rules"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+var errBudgetExhausted = errors.New("deletion budget exhausted")
+
+// BudgetStatus is one budget with what today's deletes consumed.
+type BudgetStatus struct {
+	rules.Budget
+	Deletions int   `json:"deletions"`
+	Bytes     int64 `json:"bytes"`
+	Exhausted bool  `json:"exhausted"`
+}
+
+// BudgetsResponse is the body of GET /api/budgets.
+type BudgetsResponse struct {
+	Day     string         `json:"day"`
+	Resets  time.Time      `json:"resets"`
+	Budgets []BudgetStatus `json:"budgets"`
+}
+
+type budgetUsage struct {
+	deletions int
+	bytes     int64
+}
+
+// budgetLedger counts today's usage per budget name.
+type budgetLedger struct {
+	mu        sync.Mutex
+	day       time.Time // UTC midnight the counts belong to; zero until restored
+	used      map[string]budgetUsage
+	exhausted map[string]bool // budgets already reported as exhausted today
+}
+
+// budgetCharge is what one delete reserved.
+type budgetCharge struct {
+	day     time.Time
+	budgets []string
+	bytes   int64
+}
+
+// chargeBudgets reserves one deletion and the item's size on every budget
+// covering it, or fails with errBudgetExhausted when one has no room left.
+// Nothing is reserved on failure.
+func (s *Server) chargeBudgets(ctx context.Context, item workspace.RegistryItem) (*budgetCharge, error) {
+	budgets := s.rules.Budgets()
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+	now := time.Now()
+	s.applyPolicy(&item)
+	// Cheap attributes first; content is only fetched for rules that need it.
+	attrs := s.itemAttributes(ctx, item, false)
+	withContent := false
+	var covering []rules.Budget
+	for _, b := range budgets {
+		rule, _ := s.rules.Rule(b.Rule)
+		if rule.NeedsContent() && !withContent {
+			attrs = s.itemAttributes(ctx, item, true)
+			withContent = true
+		}
+		if b.Covers(attrs, rule, now) {
+			covering = append(covering, b)
+		}
+	}
+	if len(covering) == 0 {
+		return nil, nil
+	}
+
+	l := &s.budgets
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := s.restoreBudgetUsage(ctx, now); err != nil {
+		return nil, err
+	}
+	for _, b := range covering {
+		u := l.used[b.Name]
+		var limit string
+		switch {
+		case b.MaxDeletions > 0 && u.deletions+1 > b.MaxDeletions:
+			limit = fmt.Sprintf("%d deletions", b.MaxDeletions)
+		case b.MaxBytes > 0 && u.bytes+item.Size > b.MaxBytes:
+			limit = fmt.Sprintf("%d bytes", b.MaxBytes)
+		default:
+			continue
+		}
+		if !l.exhausted[b.Name] {
+			l.exhausted[b.Name] = true
+			s.logger.WarnContext(ctx, "deletion budget exhausted", "budget", b.Name, "deletions", u.deletions, "bytes", u.bytes, "actor", actorFrom(ctx))
+			s.events.Emit(events.Event{
+				Type:    events.TypeBudgetExhausted,
+				Actor:   actorFrom(ctx),
+				Subject: b.Name,
+				Data:    map[string]any{"deletions": u.deletions, "bytes": u.bytes, "refused": item.ID},
+			})
+		}
+		return nil, fmt.Errorf("%w: %q allows %s per day", errBudgetExhausted, b.Name, limit)
+	}
+	charge := &budgetCharge{day: l.day, bytes: item.Size}
+	for _, b := range covering {
+		u := l.used[b.Name]
+		u.deletions++
+		u.bytes += item.Size
+		l.used[b.Name] = u
+		charge.budgets = append(charge.budgets, b.Name)
+	}
+	return charge, nil
+}
+
+// refundBudgets returns a charge whose delete failed.
+func (s *Server) refundBudgets(c *budgetCharge) {
+	if c == nil {
+		return
+	}
+	l := &s.budgets
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.day.Equal(c.day) {
+		return
+	}
+	for _, name := range c.budgets {
+		u := l.used[name]
+		u.deletions--
+		u.bytes -= c.bytes
+		l.used[name] = u
+	}
+}
+
+// restoreBudgetUsage starts the ledger of the current UTC day from the
+// charges recorded on its item.deleted events. The caller holds the ledger lock.
+func (s *Server) restoreBudgetUsage(ctx context.Context, now time.Time) error {
+	l := &s.budgets
+	day := now.UTC().Truncate(24 * time.Hour)
+	if l.day.Equal(day) {
+		return nil
+	}
+	list, err := s.store.ListEvents(ctx, store.Query{Since: day, Types: []string{events.TypeItemDeleted}})
+	if err != nil {
+		return fmt.Errorf("unable to restore today's budget usage: %w", err)
+	}
+	used := make(map[string]budgetUsage)
+	for _, e := range list {
+		size, _ := eventNumber(e.Data["bytes"])
+		for _, name := range eventStrings(e.Data["budgets"]) {
+			u := used[name]
+			u.deletions++
+			u.bytes += int64(size)
+			used[name] = u
+		}
+	}
+	l.day, l.used, l.exhausted = day, used, make(map[string]bool)
+	return nil
+}
+
+// eventNumber reads a numeric event field, which is float64 once decoded from the store.
+func eventNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// eventStrings reads a list event field, which is []any once decoded from the store.
+func eventStrings(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, x := range list {
+			if str, ok := x.(string); ok {
+				out = append(out, str)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func (s *Server) handleBudgets(w http.ResponseWriter, r *http.Request) {
+	l := &s.budgets
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := s.restoreBudgetUsage(r.Context(), time.Now()); err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	res := BudgetsResponse{
+		Day:     l.day.Format(time.DateOnly),
+		Resets:  l.day.Add(24 * time.Hour),
+		Budgets: []BudgetStatus{},
+	}
+	for _, b := range s.rules.Budgets() {
+		u := l.used[b.Name]
+		res.Budgets = append(res.Budgets, BudgetStatus{
+			Budget:    b,
+			Deletions: u.deletions,
+			Bytes:     u.bytes,
+			Exhausted: (b.MaxDeletions > 0 && u.deletions >= b.MaxDeletions) || (b.MaxBytes > 0 && u.bytes >= b.MaxBytes),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,183 @@
+/*
+File: internal/server/bulk.go
+Description: Bulk note operations. POST /api/notes/bulk-delete and
+/api/notes/bulk-status take a list of note IDs, work through them with bounded
+parallelism and report the outcome of each one, so operators can clear dozens
+of notes in one request. With ?async=true the work runs as a background job.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"axis/internal/jobs"
+)
+
+const (
+	maxBulkIDs      = 500
+	maxBulkBody     = 1 << 20
+	bulkParallelism = 8
+)
+
+var errBulkModeChanged = errors.New("mode changed to AUTO; delete skipped")
+
+// BulkRequest is the body of the bulk note endpoints. Status is only read by
+// bulk-status.
+type BulkRequest struct {
+	IDs    []string `json:"ids"`
+	Status string   `json:"status,omitempty"`
+}
+
+// BulkResult is the outcome for one ID.
+type BulkResult struct {
+	ID    string `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// BulkResponse lists results in request order.
+type BulkResponse struct {
+	Results   []BulkResult `json:"results"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+}
+
+// decodeBulk reads a bulk request and drops blank and repeated IDs.
+func decodeBulk(w http.ResponseWriter, r *http.Request) (BulkRequest, error) {
+	var req BulkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBulkBody)).Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid request: %w", err)
+	}
+	seen := make(map[string]bool, len(req.IDs))
+	ids := req.IDs[:0]
+	for _, id := range req.IDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	req.IDs = ids
+	switch {
+	case len(req.IDs) == 0:
+		return req, fmt.Errorf("missing ids")
+	case len(req.IDs) > maxBulkIDs:
+		return req, fmt.Errorf("at most %d ids per request", maxBulkIDs)
+	}
+	return req, nil
+}
+
+// runBulk applies fn to every ID, bulkParallelism at a time, counting each
+// one on p.
+func runBulk(ctx context.Context, ids []string, p *jobs.Progress, fn func(ctx context.Context, id string) error) BulkResponse {
+	res := BulkResponse{Results: make([]BulkResult, len(ids))}
+	p.SetTotal(len(ids))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, bulkParallelism)
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result := BulkResult{ID: id, OK: true}
+			if err := fn(ctx, id); err != nil {
+				result.OK, result.Error = false, err.Error()
+			}
+			res.Results[i] = result
+			p.Add(1)
+		}()
+	}
+	wg.Wait()
+	for _, result := range res.Results {
+		if result.OK {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	return res
+}
+
+func (s *Server) handleBulkDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.isInteractiveMode() {
+		apiError(w, "delete requires MANUAL, SIMULATE or AIRGAP mode", http.StatusForbidden)
+		return
+	}
+	req, err := decodeBulk(w, r)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if asyncRequested(r) {
+		s.submitJob(w, r, "bulk-delete", func(ctx context.Context, p *jobs.Progress) (jobs.Result, error) {
+			return jobs.Result{Value: s.bulkDelete(ctx, req.IDs, p)}, ctx.Err()
+		})
+		return
+	}
+	res := s.bulkDelete(r.Context(), req.IDs, nil)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// bulkDelete deletes the notes ids. A job may outlast the mode it was
+// submitted in, so each delete checks the mode again.
+func (s *Server) bulkDelete(ctx context.Context, ids []string, p *jobs.Progress) BulkResponse {
+	res := runBulk(ctx, ids, p, func(ctx context.Context, id string) error {
+		if !s.isInteractiveMode() {
+			return errBulkModeChanged
+		}
+		return s.deleteRegistryItem(ctx, s.registryItem(id, "keep"))
+	})
+	s.logger.InfoContext(ctx, "bulk delete", "requested", len(ids), "succeeded", res.Succeeded, "failed", res.Failed, "actor", actorFrom(ctx))
+
+	if res.Succeeded > 0 {
+		s.refreshRegistryCache()
+		s.broadcastRegistry()
+	}
+	return res
+}
+
+func (s *Server) handleBulkStatus(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeBulk(w, r)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	if req.Status == "" {
+		apiError(w, "missing status", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.rules.Statuses().Check("", req.Status); err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if asyncRequested(r) {
+		s.submitJob(w, r, "bulk-status", func(ctx context.Context, p *jobs.Progress) (jobs.Result, error) {
+			return jobs.Result{Value: s.bulkStatus(ctx, req, p)}, ctx.Err()
+		})
+		return
+	}
+	res := s.bulkStatus(r.Context(), req, nil)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (s *Server) bulkStatus(ctx context.Context, req BulkRequest, p *jobs.Progress) BulkResponse {
+	res := runBulk(ctx, req.IDs, p, func(ctx context.Context, id string) error {
+		if err := s.itemAllowed(ctx, id); err != nil {
+			return err
+		}
+		return s.setItemStatus(ctx, id, req.Status, s.getItemTitle(id))
+	})
+	s.logger.InfoContext(ctx, "bulk status", "status", req.Status, "requested", len(req.IDs), "actor", actorFrom(ctx))
+
+	s.broadcastRegistry()
+	return res
+}
@@ -0,0 +1,138 @@
+/*
+File: internal/server/cache.go
+Description: Per-endpoint HTTP caching. Each read endpoint that dashboards poll
+declares a policy: the service user profile is cacheable for minutes, the
+registry for a few seconds (revalidated with its ETag after that), and the
+operating mode never. Cheap, rarely changing responses are also kept in a
+short in-process micro-cache so repeated polls skip the handler.
+*/
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// cachePolicy is the Cache-Control and Expires a response gets. A zero maxAge
+// means the response must not be stored.
+type cachePolicy struct {
+	maxAge time.Duration
+}
+
+var (
+	cacheUser     = cachePolicy{maxAge: 5 * time.Minute}
+	cacheRegistry = cachePolicy{maxAge: 5 * time.Second}
+	cacheNoStore  = cachePolicy{}
+)
+
+// userCacheTTL bounds how long /api/user answers from the micro-cache.
+const userCacheTTL = 30 * time.Second
+
+// set writes the policy's headers. Responses vary by credentials, so caching
+// is private to the browser.
+func (p cachePolicy) set(h http.Header) {
+	if p.maxAge <= 0 {
+		h.Set("Cache-Control", "no-store")
+		h.Set("Expires", "0")
+		return
+	}
+	h.Set("Cache-Control", "private, max-age="+strconv.Itoa(int(p.maxAge/time.Second)))
+	h.Set("Expires", time.Now().Add(p.maxAge).UTC().Format(http.TimeFormat))
+}
+
+// cached applies p to successful responses of next; errors are never stored.
+func cached(p cachePolicy, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(&policyWriter{ResponseWriter: w, policy: p}, r)
+	}
+}
+
+// policyWriter sets cache headers once the status is known.
+type policyWriter struct {
+	http.ResponseWriter
+	policy      cachePolicy
+	wroteHeader bool
+}
+
+func (w *policyWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status == http.StatusOK || status == http.StatusNotModified {
+			w.policy.set(w.Header())
+		} else {
+			cacheNoStore.set(w.Header())
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *policyWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// microCache holds recent 200 responses of one endpoint by path. It is only
+// for responses that do not depend on the principal or the query.
+type microCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]microEntry
+}
+
+type microEntry struct {
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// serve answers from the cache, or runs next and stores a 200 response. It
+// sits behind guard, so only authorized requests reach it.
+func (c *microCache) serve(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		c.mu.Lock()
+		e, ok := c.entries[key]
+		c.mu.Unlock()
+		if ok && time.Now().Before(e.expires) {
+			for k, v := range e.header {
+				w.Header()[k] = v
+			}
+			w.Write(e.body)
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		if rec.status != http.StatusOK {
+			return
+		}
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[string]microEntry)
+		}
+		c.entries[key] = microEntry{header: w.Header().Clone(), body: rec.body.Bytes(), expires: time.Now().Add(c.ttl)}
+		c.mu.Unlock()
+	}
+}
+
+// recordingWriter copies the response it forwards.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,260 @@
+/*
+File: internal/server/cleanupdoc.go
+Description: Weekly cleanup plan Doc. Once a week the leader writes a Google Doc
+listing the changes proposed for the coming seven days (scheduled deletions,
+items marked Execute, and items waiting to be archived), grouped by owner with
+counts and links, and shares it with the configured stakeholders so the review
+happens where the organization already comments on documents.
+*/
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const (
+	cleanupDocCheck   = time.Hour
+	cleanupDocHorizon = 7 * 24 * time.Hour
+	cleanupDocRole    = "commenter"
+	cleanupNoOwner    = "(no owner)"
+)
+
+var errNoProposals = errors.New("no changes proposed for the coming week")
+
+// cleanupProposal is one change listed in the cleanup plan Doc; action is
+// "delete" (scheduled), "execute" or "archive".
+type cleanupProposal struct {
+	item   workspace.RegistryItem
+	action string
+}
+
+// CleanupDocResponse describes a generated cleanup plan Doc.
+type CleanupDocResponse struct {
+	Doc        string         `json:"doc"`
+	Title      string         `json:"title"`
+	URL        string         `json:"url"`
+	Items      int            `json:"items"`
+	Owners     map[string]int `json:"owners"`
+	Recipients []string       `json:"recipients"`
+	ShareError string         `json:"share_error,omitempty"`
+}
+
+// WithCleanupDoc writes the cleanup plan Doc every week on day and shares it
+// with recipients.
+func WithCleanupDoc(recipients []string, day time.Weekday) Option {
+	return func(s *Server) {
+		s.cleanupDocRecipients = recipients
+		s.cleanupDocDay = day
+	}
+}
+
+// cleanupProposals lists the changes due in the week from now: deletions
+// scheduled before its end, items marked Execute and, with an archive folder,
+// items whose status is Archive.
+func (s *Server) cleanupProposals(ctx context.Context, now time.Time) ([]cleanupProposal, error) {
+	items, err := s.RegistrySnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	end := now.Add(cleanupDocHorizon)
+	var out []cleanupProposal
+	for _, item := range items {
+		var action string
+		switch {
+		case item.DeleteAt != nil && item.DeleteAt.Before(end):
+			action = "delete"
+		case item.Status == defaultReviewStatus:
+			action = "execute"
+		case item.Status == archiveStatus && s.archiveFolder != "":
+			action = "archive"
+		default:
+			continue
+		}
+		out = append(out, cleanupProposal{item: item, action: action})
+	}
+	return out, nil
+}
+
+// cleanupDocText renders the Doc: a summary, then one section per owner with
+// the most proposals first.
+func cleanupDocText(proposals []cleanupProposal, now time.Time) (header, body string) {
+	byOwner := make(map[string][]cleanupProposal)
+	actions := make(map[string]int)
+	for _, p := range proposals {
+		owner := cmp.Or(p.item.Owner, cleanupNoOwner)
+		byOwner[owner] = append(byOwner[owner], p)
+		actions[p.action]++
+	}
+	owners := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		owners = append(owners, owner)
+	}
+	slices.SortFunc(owners, func(a, b string) int {
+		if c := cmp.Compare(len(byOwner[b]), len(byOwner[a])); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	var h strings.Builder
+	fmt.Fprintf(&h, "%d changes proposed for %s to %s, across %d owners:\n",
+		len(proposals), now.Format("2006-01-02"), now.Add(cleanupDocHorizon).Format("2006-01-02"), len(owners))
+	fmt.Fprintf(&h, "- %d scheduled deletions\n- %d items marked %s\n- %d items to archive\n",
+		actions["delete"], actions["execute"], defaultReviewStatus, actions["archive"])
+	fmt.Fprintf(&h, "Generated by Axis at %s. Comment on an item to hold it back.\n", now.UTC().Format("2006-01-02 15:04 UTC"))
+
+	var b strings.Builder
+	for _, owner := range owners {
+		list := byOwner[owner]
+		slices.SortFunc(list, func(x, y cleanupProposal) int {
+			return cmp.Or(cmp.Compare(x.action, y.action), strings.Compare(x.item.Title, y.item.Title))
+		})
+		fmt.Fprintf(&b, "\n%s (%d)\n", owner, len(list))
+		for _, p := range list {
+			var action string
+			switch p.action {
+			case "delete":
+				action = "Delete on " + p.item.DeleteAt.UTC().Format("2006-01-02")
+			case "execute":
+				action = "Delete (marked " + defaultReviewStatus + ")"
+			default:
+				action = "Archive"
+			}
+			fmt.Fprintf(&b, "- %s: %s\n", action, reviewEntry(p.item))
+		}
+	}
+	return h.String(), b.String()
+}
+
+// createCleanupDoc writes and shares the cleanup plan Doc.
+func (s *Server) createCleanupDoc(ctx context.Context, recipients []string) (CleanupDocResponse, error) {
+	now := time.Now()
+	proposals, err := s.cleanupProposals(ctx, now)
+	if err != nil {
+		return CleanupDocResponse{}, err
+	}
+	if len(proposals) == 0 {
+		return CleanupDocResponse{}, errNoProposals
+	}
+	header, body := cleanupDocText(proposals, now)
+	title := fmt.Sprintf("Axis cleanup plan: week of %s", now.Format("2006-01-02"))
+	doc, err := s.ws.CreateDoc(ctx, title, header)
+	if err != nil {
+		return CleanupDocResponse{}, err
+	}
+	if err := s.ws.AppendDocText(ctx, doc.DocumentId, body); err != nil {
+		return CleanupDocResponse{}, fmt.Errorf("created doc %s: %w", doc.DocumentId, err)
+	}
+
+	owners := make(map[string]int)
+	for _, p := range proposals {
+		owners[cmp.Or(p.item.Owner, cleanupNoOwner)]++
+	}
+	resp := CleanupDocResponse{
+		Doc:        doc.DocumentId,
+		Title:      title,
+		URL:        workspace.ItemURL(workspace.RegistryItem{ID: doc.DocumentId, Type: "doc"}),
+		Items:      len(proposals),
+		Owners:     owners,
+		Recipients: recipients,
+	}
+	// As with review checklists, the Doc exists now; a sharing failure is
+	// reported and the Doc can be shared by hand.
+	if err := s.ws.ShareFile(context.WithoutCancel(ctx), doc.DocumentId, cleanupDocRole, recipients); err != nil {
+		s.logger.ErrorContext(ctx, "cleanup doc share failed", "doc", doc.DocumentId, "error", err)
+		resp.ShareError = err.Error()
+	}
+
+	s.logger.InfoContext(ctx, "cleanup doc created", "doc", doc.DocumentId, "items", len(proposals), "owners", len(owners), "recipients", len(recipients))
+	s.events.Emit(events.Event{
+		Type:    events.TypeCleanupDocCreated,
+		Actor:   actorFrom(ctx),
+		Subject: doc.DocumentId,
+		Data:    map[string]any{"title": title, "items": len(proposals), "owners": len(owners), "recipients": recipients},
+	})
+	return resp, nil
+}
+
+// runCleanupDoc writes the weekly Doc on the configured day. The day's
+// cleanup_doc.created event marks it done, so a restart or another leader
+// does not write a second one.
+func (s *Server) runCleanupDoc(ctx context.Context) {
+	if len(s.cleanupDocRecipients) == 0 {
+		return
+	}
+	ticker := time.NewTicker(cleanupDocCheck)
+	defer ticker.Stop()
+	var done time.Time // the day this instance last wrote the Doc
+	for {
+		select {
+		case now := <-ticker.C:
+			day := now.UTC().Truncate(24 * time.Hour)
+			if !s.IsLeader() || now.UTC().Weekday() != s.cleanupDocDay || done.Equal(day) || s.currentMode() == "AIRGAP" {
+				continue
+			}
+			written, err := s.store.ListEvents(ctx, store.Query{Since: day, Types: []string{events.TypeCleanupDocCreated}, Limit: 1})
+			if err != nil {
+				s.logger.WarnContext(ctx, "cleanup doc check failed", "error", err)
+				continue
+			}
+			if len(written) > 0 {
+				done = day
+				continue
+			}
+			_, err = s.createCleanupDoc(withActor(ctx, "cleanup-doc"), s.cleanupDocRecipients)
+			switch {
+			case errors.Is(err, errNoProposals):
+				done = day
+				s.logger.InfoContext(ctx, "cleanup doc skipped", "reason", err)
+			case err != nil:
+				s.logger.ErrorContext(ctx, "cleanup doc failed", "retry_in", cleanupDocCheck, "error", err)
+			default:
+				done = day
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleCleanupDoc serves POST /api/reports/cleanup-doc, writing the Doc now
+// for ?recipients= or the configured stakeholders.
+func (s *Server) handleCleanupDoc(w http.ResponseWriter, r *http.Request) {
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "the cleanup plan Doc is written to Docs, which AIRGAP mode cannot do", http.StatusConflict)
+		return
+	}
+	recipients := s.cleanupDocRecipients
+	if raw := r.URL.Query().Get("recipients"); raw != "" {
+		recipients = strings.Split(raw, ",")
+	}
+	recipients, err := parseReviewers(recipients)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	resp, err := s.createCleanupDoc(r.Context(), recipients)
+	switch {
+	case errors.Is(err, errNoProposals):
+		writeAPIError(w, err, http.StatusNotFound)
+		return
+	case err != nil:
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
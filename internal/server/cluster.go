@@ -0,0 +1,144 @@
+/*
+File: internal/server/cluster.go
+Description: Highly available operation behind a load balancer. Instances share
+the SQL state store; the leader runs the poller and schedulers, client
+broadcasts and mode/status changes are relayed so each instance's clients and
+in-memory state follow the others.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"axis/internal/broker"
+	"axis/internal/cluster"
+	"axis/internal/scheduler"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+// Relay types for state changes; client broadcasts keep their broker type.
+const (
+	relayMode     = "state.mode"
+	relayStatus   = "state.status"
+	relayTags     = "state.tags"
+	relayDeadline = "state.deadline"
+)
+
+// WithCluster joins the server to a cluster of instances sharing its store.
+func WithCluster(n *cluster.Node) Option {
+	return func(s *Server) { s.cluster = n }
+}
+
+// IsLeader reports whether this instance runs the poller and schedulers. A
+// standalone server is always the leader.
+func (s *Server) IsLeader() bool {
+	return s.cluster == nil || s.cluster.IsLeader()
+}
+
+// broadcast publishes a client event locally and to the other instances, and
+// journals it for replay.
+func (s *Server) broadcast(e broker.Event) {
+	s.hub.Publish(e)
+	s.relay(e.Type, e.Data)
+	s.journalBroadcast(e)
+}
+
+// broadcastJSON marshals v and broadcasts it as a typ event.
+func (s *Server) broadcastJSON(typ string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.broadcast(broker.Event{Type: typ, Data: data})
+	return nil
+}
+
+// relay forwards an event to the other instances. Relaying is best effort: a
+// store outage must not block local clients.
+func (s *Server) relay(typ string, data []byte) {
+	if s.cluster == nil {
+		return
+	}
+	if err := s.cluster.Publish(context.Background(), typ, data); err != nil {
+		s.logger.Warn("cluster relay failed", "type", typ, "error", err)
+	}
+}
+
+func (s *Server) relayJSON(typ string, v any) {
+	if s.cluster == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.logger.Error("cluster relay marshal failed", "type", typ, "error", err)
+		return
+	}
+	s.relay(typ, data)
+}
+
+// applyRelayed folds another instance's broadcast into local state and clients.
+func (s *Server) applyRelayed(b store.Broadcast) {
+	switch b.Type {
+	case relayMode:
+		var m ModeResponse
+		if json.Unmarshal(b.Data, &m) == nil && validMode(m.Mode) && s.checkModeChange(m.Mode) == nil {
+			if s.swapMode(m.Mode) != m.Mode {
+				s.publishMode(m.Mode)
+			}
+		}
+		return
+	case relayStatus:
+		var st struct{ ID, Status string }
+		if json.Unmarshal(b.Data, &st) == nil && st.ID != "" {
+			s.updateStatuses(func(next statusSet) bool {
+				next[st.ID] = st.Status
+				return true
+			})
+		}
+		return
+	case relayTags:
+		var t TagsResponse
+		if json.Unmarshal(b.Data, &t) == nil && t.ID != "" {
+			s.setTags(t.ID, t.Tags)
+		}
+		return
+	case relayDeadline:
+		var d Deadline
+		if json.Unmarshal(b.Data, &d) == nil && d.ID != "" {
+			s.setDeadline(d.ID, d.DeleteAt)
+		}
+		return
+	case relayTokens:
+		var change map[string]string
+		json.Unmarshal(b.Data, &change)
+		s.reloadTokens(change["revoked"])
+		return
+	case relayPoller:
+		s.applyRelayedPoller(b.Data)
+		return
+	case relaySchedule:
+		var cfg scheduler.Config
+		if json.Unmarshal(b.Data, &cfg) == nil {
+			if err := s.applySchedule(cfg); err != nil {
+				s.logger.Warn("relayed schedule rejected", "error", err)
+			}
+		}
+		return
+	case broker.TypeRegistry:
+		// The leader's fresh listing saves this instance a Workspace fetch.
+		var items []workspace.RegistryItem
+		if json.Unmarshal(b.Data, &items) == nil && len(items) > 0 {
+			s.registryCache.update(func(*registrySnapshot) *registrySnapshot {
+				return &registrySnapshot{items: items, expiresAt: b.Time.Add(cacheTTL), loaded: true}
+			})
+			s.resetRegistryBaseline(items, b.Data, b.Time)
+			return
+		}
+	case broker.TypeItemAdded, broker.TypeItemUpdated, broker.TypeItemRemoved:
+		s.applyRegistryDelta(b.Type, b.Data)
+		return
+	}
+	s.hub.Publish(broker.Event{Type: b.Type, Data: b.Data})
+}
@@ -0,0 +1,129 @@
+/*
+File: internal/server/comments.go
+Description: Operator comments on registry items. GET /api/registry/comments
+returns an item's threads; POST adds a comment, or a reply when reply_to names
+a comment of the same item. Comments live in the state backend, attributed to
+the operator who wrote them.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"axis/internal/events"
+	"axis/internal/store"
+)
+
+const maxCommentLength = 4000
+
+// CommentRequest is the body of POST /api/registry/comments.
+type CommentRequest struct {
+	Body    string `json:"body"`
+	ReplyTo int64  `json:"reply_to,omitempty"`
+}
+
+// CommentThread is a top-level comment and its replies, oldest first.
+type CommentThread struct {
+	store.Comment
+	Replies []store.Comment `json:"replies"`
+}
+
+// CommentsResponse is the body of GET /api/registry/comments.
+type CommentsResponse struct {
+	ID       string          `json:"id"`
+	Comments int             `json:"comments"`
+	Threads  []CommentThread `json:"threads"`
+}
+
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	list, err := s.store.ListComments(r.Context(), id)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	res := CommentsResponse{ID: id, Comments: len(list), Threads: []CommentThread{}}
+	thread := make(map[int64]int, len(list)) // comment ID to thread index
+	for _, c := range list {
+		if at, ok := thread[c.ReplyTo]; ok && c.ReplyTo != 0 {
+			res.Threads[at].Replies = append(res.Threads[at].Replies, c)
+			continue
+		}
+		thread[c.ID] = len(res.Threads)
+		res.Threads = append(res.Threads, CommentThread{Comment: c, Replies: []store.Comment{}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (s *Server) handleCommentAdd(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	var req CommentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		apiError(w, "invalid comment: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	switch {
+	case body == "":
+		apiError(w, "comment body is empty", http.StatusBadRequest)
+		return
+	case utf8.RuneCountInString(body) > maxCommentLength:
+		apiError(w, "comment is longer than 4000 characters", http.StatusBadRequest)
+		return
+	}
+	item := s.registryItem(id, "")
+	if item.Type == "" {
+		apiError(w, "unknown item", http.StatusNotFound)
+		return
+	}
+
+	replyTo := int64(0)
+	if req.ReplyTo != 0 {
+		list, err := s.store.ListComments(r.Context(), id)
+		if err != nil {
+			writeAPIError(w, err, http.StatusInternalServerError)
+			return
+		}
+		for _, c := range list {
+			if c.ID == req.ReplyTo {
+				// Threads are one level deep: a reply to a reply joins its thread.
+				replyTo = c.ID
+				if c.ReplyTo != 0 {
+					replyTo = c.ReplyTo
+				}
+			}
+		}
+		if replyTo == 0 {
+			apiError(w, "reply_to is not a comment on this item", http.StatusBadRequest)
+			return
+		}
+	}
+
+	c, err := s.store.AddComment(r.Context(), store.Comment{ItemID: id, ReplyTo: replyTo, Author: actorFrom(r.Context()), Body: body})
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "comment added", "id", id, "comment", c.ID, "actor", c.Author)
+	s.events.Emit(events.Event{
+		Type:    events.TypeCommentAdded,
+		Actor:   c.Author,
+		Subject: id,
+		Data:    map[string]any{"type": item.Type, "title": item.Title, "comment": c.ID, "reply_to": c.ReplyTo, "body": c.Body},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
@@ -0,0 +1,56 @@
+/*
+File: internal/server/config.go
+Description: Runtime configuration endpoint. GET /api/config reports the registry
+poll schedule; PUT updates the default interval and per-type schedules until
+the next restart (the environment sets the startup values).
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"axis/internal/scheduler"
+)
+
+const relaySchedule = "state.schedule"
+
+// WithPollSchedule sets the default AUTO refresh interval and per-type
+// overrides (e.g. Keep every minute, Drive files every 10 minutes).
+func WithPollSchedule(sched *scheduler.Scheduler) Option {
+	return func(s *Server) { s.schedule = sched }
+}
+
+// ConfigResponse is the body of /api/config.
+type ConfigResponse struct {
+	Poll scheduler.Config `json:"poll"`
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ConfigResponse{Poll: s.schedule.Config()})
+}
+
+func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
+	var req ConfigResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, "invalid config: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.applySchedule(req.Poll); err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "poll schedule updated", "actor", actorFrom(r.Context()), "schedule", s.schedule.String())
+	s.relayJSON(relaySchedule, req.Poll)
+	s.handleConfig(w, r)
+}
+
+func (s *Server) applySchedule(cfg scheduler.Config) error {
+	overrides := make(map[string]time.Duration, len(cfg.Types))
+	for name, d := range cfg.Types {
+		overrides[name] = time.Duration(d)
+	}
+	return s.schedule.Merge(time.Duration(cfg.Default), overrides)
+}
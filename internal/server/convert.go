@@ -0,0 +1,67 @@
+/*
+File: internal/server/convert.go
+Description: Keep-to-Doc conversion endpoint. POST /api/notes/convert?id= turns a
+note into a Google Doc with its text or checklist and attachments; the note is
+kept, so a conversion can be followed by the usual delete or archive.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// ConvertResponse is the body of POST /api/notes/convert. AttachmentError
+// reports attachments left out of an otherwise complete Doc.
+type ConvertResponse struct {
+	Note            string `json:"note"`
+	Doc             string `json:"doc"`
+	URL             string `json:"url"`
+	AttachmentError string `json:"attachment_error,omitempty"`
+}
+
+// WithNoteConvert enables POST /api/notes/convert.
+func WithNoteConvert() Option {
+	return func(s *Server) { s.noteConvert = true }
+}
+
+func (s *Server) handleNoteConvert(w http.ResponseWriter, r *http.Request) {
+	if !s.noteConvert {
+		apiError(w, "note conversion not enabled (AXIS_NOTE_CONVERT)", http.StatusNotFound)
+		return
+	}
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "converting a note writes to Docs, which AIRGAP mode cannot do", http.StatusConflict)
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	doc, err := s.ws.ConvertNoteToDoc(r.Context(), id)
+	if doc == "" {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	resp := ConvertResponse{Note: id, Doc: doc, URL: workspace.ItemURL(workspace.RegistryItem{ID: doc, Type: "doc"})}
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "note conversion incomplete", "note", id, "doc", doc, "error", err)
+		resp.AttachmentError = err.Error()
+	}
+
+	s.logger.InfoContext(r.Context(), "note converted", "note", id, "doc", doc)
+	s.events.Emit(events.Event{
+		Type:    events.TypeNoteConverted,
+		Actor:   actorFrom(r.Context()),
+		Subject: id,
+		Data:    map[string]any{"doc": doc, "complete": err == nil},
+	})
+	s.refreshAndBroadcast()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,119 @@
+/*
+File: internal/server/create.go
+Description: Document creation. POST /api/docs and POST /api/sheets create a Doc
+or spreadsheet owned by the impersonated user, so Axis can write reports and
+scratch documents for operators. New items are added to the registry at once
+rather than waiting for Drive listings to catch up.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// maxCreateBody bounds a creation request body.
+const maxCreateBody = 8 << 20
+
+// CreateDocRequest is the body of POST /api/docs.
+type CreateDocRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+}
+
+// CreateSheetRequest is the body of POST /api/sheets.
+type CreateSheetRequest struct {
+	Title  string               `json:"title"`
+	Sheets []workspace.NewSheet `json:"sheets,omitempty"`
+}
+
+// CreatedResponse describes a created Doc or spreadsheet.
+type CreatedResponse struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	BodyError string `json:"body_error,omitempty"`
+}
+
+// decodeCreate parses a creation body and requires a title. AIRGAP mode has
+// no write access, so it refuses creation.
+func (s *Server) decodeCreate(w http.ResponseWriter, r *http.Request, v any, title *string) bool {
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "creating documents is not possible in AIRGAP mode", http.StatusConflict)
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCreateBody)).Decode(v); err != nil {
+		apiError(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if strings.TrimSpace(*title) == "" {
+		apiError(w, "missing title", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleCreateDoc(w http.ResponseWriter, r *http.Request) {
+	var req CreateDocRequest
+	if !s.decodeCreate(w, r, &req, &req.Title) {
+		return
+	}
+	doc, err := s.ws.CreateDoc(r.Context(), req.Title, req.Body)
+	if doc == nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	resp := CreatedResponse{ID: doc.DocumentId, Type: "doc", Title: doc.Title}
+	// The Doc exists at this point, so a failed body write is reported rather
+	// than failing the request.
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "doc body write failed", "id", doc.DocumentId, "error", err)
+		resp.BodyError = err.Error()
+	}
+	s.finishCreate(w, r, resp)
+}
+
+func (s *Server) handleCreateSheet(w http.ResponseWriter, r *http.Request) {
+	var req CreateSheetRequest
+	if !s.decodeCreate(w, r, &req, &req.Title) {
+		return
+	}
+	sheet, err := s.ws.CreateSpreadsheet(r.Context(), req.Title, req.Sheets)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	title := req.Title
+	if sheet.Properties != nil {
+		title = sheet.Properties.Title
+	}
+	s.finishCreate(w, r, CreatedResponse{ID: sheet.SpreadsheetId, Type: "sheet", Title: title})
+}
+
+// finishCreate caches the new item, announces it and writes the response.
+func (s *Server) finishCreate(w http.ResponseWriter, r *http.Request, resp CreatedResponse) {
+	now := time.Now().UTC()
+	snippet := map[string]string{"doc": "Google Doc", "sheet": "Google Sheet"}[resp.Type]
+	item := workspace.RegistryItem{ID: resp.ID, Type: resp.Type, Title: resp.Title, Snippet: snippet, Modified: &now}
+	resp.URL = workspace.ItemURL(item)
+
+	s.ensureItemCached(item)
+	s.broadcastRegistry()
+	s.logger.InfoContext(r.Context(), "item created", "id", resp.ID, "type", resp.Type, "title", resp.Title, "actor", actorFrom(r.Context()))
+	s.events.Emit(events.Event{
+		Type:    events.TypeItemCreated,
+		Actor:   actorFrom(r.Context()),
+		Subject: resp.ID,
+		Data:    map[string]any{"type": resp.Type, "title": resp.Title},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
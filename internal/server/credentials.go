@@ -0,0 +1,103 @@
+/*
+File: internal/server/credentials.go
+Description: Service account key rotation endpoints. GET /api/credentials reports
+both configured keys and their last health check (?check=true runs a new one);
+POST /api/credentials/activate?slot= health-checks a key and switches every
+Google API client over to it. Keys are rechecked in the background so a
+revoked or expiring key shows up before it is needed.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"axis/internal/credentials"
+	"axis/internal/events"
+)
+
+const credentialCheckInterval = 15 * time.Minute
+
+// CredentialsResponse is the body of GET /api/credentials.
+type CredentialsResponse struct {
+	Active string                  `json:"active"`
+	Keys   []credentials.KeyStatus `json:"keys"`
+}
+
+// WithCredentials enables key rotation through the manager the Google API
+// clients were built from.
+func WithCredentials(m *credentials.Manager) Option {
+	return func(s *Server) { s.credentials = m }
+}
+
+// runCredentialCheck rechecks every key periodically and logs the failing ones.
+func (s *Server) runCredentialCheck(ctx context.Context) {
+	if s.credentials == nil {
+		return
+	}
+	ticker := time.NewTicker(credentialCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for _, st := range s.credentials.Check(ctx) {
+				if !st.Healthy {
+					s.logger.WarnContext(ctx, "service account key unhealthy", "slot", st.Slot, "active", st.Active, "error", st.Error)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) handleCredentials(w http.ResponseWriter, r *http.Request) {
+	if s.credentials == nil {
+		apiError(w, "key rotation is not configured", http.StatusNotFound)
+		return
+	}
+	keys := s.credentials.Status()
+	if truthyParam(r.URL.Query().Get("check")) {
+		keys = s.credentials.Check(r.Context())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CredentialsResponse{Active: s.credentials.Active(), Keys: keys})
+}
+
+func (s *Server) handleCredentialsActivate(w http.ResponseWriter, r *http.Request) {
+	if s.credentials == nil {
+		apiError(w, "key rotation is not configured", http.StatusNotFound)
+		return
+	}
+	slot := r.URL.Query().Get("slot")
+	if slot == "" {
+		apiError(w, "missing slot", http.StatusBadRequest)
+		return
+	}
+	previous := s.credentials.Active()
+	st, err := s.credentials.Activate(r.Context(), slot)
+	switch {
+	case errors.Is(err, credentials.ErrUnknownSlot):
+		writeAPIError(w, err, http.StatusNotFound)
+		return
+	case err != nil:
+		writeAPIError(w, err, http.StatusConflict)
+		return
+	}
+
+	actor := actorFrom(r.Context())
+	if previous != slot {
+		s.logger.InfoContext(r.Context(), "service account key activated", "slot", slot, "previous", previous, "key_id", st.KeyID, "actor", actor)
+		s.events.Emit(events.Event{
+			Type:    events.TypeCredentialsActivated,
+			Actor:   actor,
+			Subject: slot,
+			Data:    map[string]any{"previous": previous, "key_id": st.KeyID},
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CredentialsResponse{Active: slot, Keys: s.credentials.Status()})
+}
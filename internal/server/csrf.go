@@ -0,0 +1,83 @@
+/*
+File: internal/server/csrf.go
+Description: Cross-site request forgery protection. State-changing routes only
+accept POST/DELETE from the same origin (API key and minted token clients are
+exempt, browsers never attach their credentials). The old GET forms of those
+routes stay available behind a deprecation flag.
+*/
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"axis/internal/auth"
+)
+
+// WithLegacyGETMutations keeps the deprecated GET forms of mutating routes
+// (`GET /api/notes/delete?id=`, `GET /api/mode?set=`, ...) working. They are
+// still origin checked and answer with a Deprecation header.
+func WithLegacyGETMutations(enabled bool) Option {
+	return func(s *Server) { s.legacyGETMutations = enabled }
+}
+
+// mutation rejects cross-origin requests to a state-changing handler.
+func (s *Server) mutation(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromContext(r.Context())
+		var method string
+		if p != nil {
+			method = p.Method
+		}
+		if method == auth.MethodAPIKey || method == auth.MethodToken {
+			h(w, r)
+			return
+		}
+		if !sameOrigin(r, method == auth.MethodOIDC) {
+			s.logger.Warn("cross-origin mutation refused", "path", r.URL.Path, "origin", r.Header.Get("Origin"))
+			apiError(w, "cross-origin request refused", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// legacy serves a GET route whose requests mutate state when the query
+// carries param ("" for every request). Those requests are refused with 405
+// unless legacy GET mutations are enabled; read-only requests pass through.
+func (s *Server) legacy(param string, h http.HandlerFunc) http.HandlerFunc {
+	guarded := s.mutation(h)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if param != "" && !r.URL.Query().Has(param) {
+			h(w, r)
+			return
+		}
+		if !s.legacyGETMutations {
+			apiError(w, "state changes require POST or DELETE", http.StatusMethodNotAllowed)
+			return
+		}
+		s.logger.Warn("deprecated GET mutation", "path", r.URL.Path)
+		w.Header().Set("Deprecation", "true")
+		guarded(w, r)
+	}
+}
+
+// sameOrigin uses Fetch Metadata when the browser sends it and falls back to
+// comparing Origin with Host. A request carrying neither header passes unless
+// it rides on a session cookie: only browsers hold those, and a browser that
+// sends neither cannot be told apart from a forged request.
+func sameOrigin(r *http.Request, cookie bool) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	case "":
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return !cookie
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
@@ -0,0 +1,307 @@
+/*
+File: internal/server/deadlines.go
+Description: Scheduled deletions. An item can be marked "delete in 7 days":
+the deadline is persisted with the statuses, the AUTO poller deletes the item
+once it falls due, and every second a countdown event tells clients how long
+each scheduled item has left.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/events"
+)
+
+const (
+	defaultDeleteAfter = 7 * 24 * time.Hour
+	// deadlineRetry spaces attempts at a due deletion that failed, such as one
+	// over budget or hit by a Workspace outage.
+	deadlineRetry = 15 * time.Minute
+)
+
+// Deadline is one scheduled deletion.
+type Deadline struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type,omitempty"`
+	Title            string    `json:"title,omitempty"`
+	DeleteAt         time.Time `json:"delete_at"`
+	SecondsRemaining int       `json:"seconds_remaining"`
+	// Error is why the last attempt failed; the next one is at RetryAt.
+	Error   string     `json:"error,omitempty"`
+	RetryAt *time.Time `json:"retry_at,omitempty"`
+}
+
+// CountdownEvent is the payload of the countdown SSE event.
+type CountdownEvent struct {
+	// Paused is set outside AUTO mode, where due items wait.
+	Paused bool       `json:"paused"`
+	Items  []Deadline `json:"items"`
+}
+
+type deadlineFailure struct {
+	err     string
+	retryAt time.Time
+}
+
+// parseSpan reads the span parameter name: a Go duration or a whole number of
+// days such as "7d". Empty means def.
+func parseSpan(name, v string, def time.Duration) (time.Duration, error) {
+	if v == "" {
+		return def, nil
+	}
+	var d time.Duration
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q", name, v)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return 0, fmt.Errorf("invalid %s %q", name, v)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive", name)
+	}
+	return d, nil
+}
+
+// deadlineList reports every scheduled deletion as of now, soonest first.
+func (s *Server) deadlineList(now time.Time) []Deadline {
+	due := s.currentDeadlines()
+	if len(due) == 0 {
+		return []Deadline{}
+	}
+	s.deadlineMu.Lock()
+	failures := make(map[string]deadlineFailure, len(s.deadlineFailures))
+	for id, f := range s.deadlineFailures {
+		failures[id] = f
+	}
+	s.deadlineMu.Unlock()
+
+	items := make(map[string]int, len(due))
+	cached := s.registryCache.snapshot().items
+	for i, item := range cached {
+		if _, ok := due[item.ID]; ok {
+			items[item.ID] = i
+		}
+	}
+	list := make([]Deadline, 0, len(due))
+	for id, at := range due {
+		d := Deadline{ID: id, DeleteAt: at, SecondsRemaining: max(0, int(at.Sub(now).Round(time.Second)/time.Second))}
+		if i, ok := items[id]; ok {
+			d.Type, d.Title = cached[i].Type, cached[i].Title
+		}
+		if f, ok := failures[id]; ok {
+			retryAt := f.retryAt
+			d.Error, d.RetryAt = f.err, &retryAt
+		}
+		list = append(list, d)
+	}
+	slices.SortFunc(list, func(a, b Deadline) int {
+		if c := a.DeleteAt.Compare(b.DeleteAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return list
+}
+
+// broadcastCountdown sends the per-item countdown while any deletion is
+// scheduled, plus one empty countdown after the last one goes so clients
+// clear theirs. It reports whether any item was listed.
+func (s *Server) broadcastCountdown(now time.Time, listed bool) bool {
+	list := s.deadlineList(now)
+	if len(list) == 0 && !listed {
+		return false
+	}
+	if err := s.broadcastJSON(broker.TypeCountdown, CountdownEvent{Paused: s.currentMode() != "AUTO" || s.poller.isPaused(), Items: list}); err != nil {
+		s.logger.Error("countdown marshal failed", "error", err)
+	}
+	return len(list) > 0
+}
+
+// clearDeadline removes id's deadline and any failed attempt, and reports
+// whether there was one.
+func (s *Server) clearDeadline(id string) bool {
+	s.deadlineMu.Lock()
+	delete(s.deadlineFailures, id)
+	s.deadlineMu.Unlock()
+	if !s.setDeadline(id, time.Time{}) {
+		return false
+	}
+	s.relayJSON(relayDeadline, Deadline{ID: id})
+	return true
+}
+
+// executeDeadlines deletes the items whose deadline has passed, outside the
+// poller so slow deletes and backups do not hold up the tick. Only one pass
+// runs at a time.
+func (s *Server) executeDeadlines(ctx context.Context, now time.Time) {
+	var due []string
+	s.deadlineMu.Lock()
+	for id, at := range s.currentDeadlines() {
+		if f, failed := s.deadlineFailures[id]; !at.After(now) && (!failed || !f.retryAt.After(now)) {
+			due = append(due, id)
+		}
+	}
+	s.deadlineMu.Unlock()
+	if len(due) == 0 || !s.deadlineRunning.CompareAndSwap(false, true) {
+		return
+	}
+	slices.Sort(due)
+
+	go func() {
+		defer s.deadlineRunning.Store(false)
+		ctx := withActor(ctx, "deadline")
+		deleted, cleared := false, false
+		for _, id := range due {
+			// A mode change mid-pass leaves the rest waiting.
+			if ctx.Err() != nil || s.currentMode() != "AUTO" {
+				break
+			}
+			item := s.registryItem(id, "")
+			if item.Type == "" {
+				// Not listed yet; stale-status cleanup drops the deadline if
+				// the item is gone.
+				continue
+			}
+			err := s.deleteRegistryItem(ctx, item)
+			switch {
+			case err == nil:
+				deleted = true
+				cleared = s.clearDeadline(id) || cleared
+				s.logger.Info("scheduled deletion executed", "id", id, "type", item.Type, "title", item.Title)
+			case errors.Is(err, errItemProtected):
+				// Protection does not lapse on its own; the refusal is audited.
+				cleared = s.clearDeadline(id) || cleared
+				s.logger.Warn("scheduled deletion refused", "id", id, "error", err)
+			default:
+				s.deadlineMu.Lock()
+				s.deadlineFailures[id] = deadlineFailure{err: err.Error(), retryAt: time.Now().Add(deadlineRetry)}
+				s.deadlineMu.Unlock()
+				s.logger.Warn("scheduled deletion failed", "id", id, "retry_in", deadlineRetry, "error", err)
+			}
+		}
+		if cleared {
+			s.triggerStateSnapshot()
+		}
+		if deleted {
+			s.refreshAndBroadcast()
+		}
+	}()
+}
+
+func (s *Server) handleDeadlines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.deadlineList(time.Now()))
+}
+
+// handleDeadlineSet serves POST /api/status/deadline?id=&in=7d (or &at= an
+// RFC 3339 time). Scheduling an item again moves its deadline.
+func (s *Server) handleDeadlineSet(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	id := q.Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	var at time.Time
+	if v := q.Get("at"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			apiError(w, "invalid at (want RFC 3339)", http.StatusBadRequest)
+			return
+		}
+		if !t.After(now) {
+			apiError(w, "at must be in the future", http.StatusBadRequest)
+			return
+		}
+		at = t
+	} else {
+		after, err := parseSpan("in", q.Get("in"), defaultDeleteAfter)
+		if err != nil {
+			writeAPIError(w, err, http.StatusBadRequest)
+			return
+		}
+		at = now.Add(after)
+	}
+	at = at.UTC().Truncate(time.Second)
+
+	item := s.registryItem(id, q.Get("type"))
+	switch item.Type {
+	case "keep", "doc", "sheet":
+	default:
+		apiError(w, "unknown item type (pass type=keep, doc or sheet)", http.StatusBadRequest)
+		return
+	}
+	if err := s.checkProtected(item); err != nil {
+		writeAPIError(w, err, http.StatusForbidden)
+		return
+	}
+
+	s.deadlineMu.Lock()
+	delete(s.deadlineFailures, id)
+	s.deadlineMu.Unlock()
+	s.setDeadline(id, at)
+	s.relayJSON(relayDeadline, Deadline{ID: id, DeleteAt: at})
+	s.triggerStateSnapshot()
+	s.broadcastRegistry()
+
+	actor := actorFrom(r.Context())
+	s.logger.InfoContext(r.Context(), "deletion scheduled", "id", id, "type", item.Type, "delete_at", at, "actor", actor)
+	s.events.Emit(events.Event{
+		Type:    events.TypeDeletionScheduled,
+		Actor:   actor,
+		Subject: id,
+		Data:    map[string]any{"type": item.Type, "title": item.Title, "delete_at": at},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Deadline{
+		ID:               id,
+		Type:             item.Type,
+		Title:            item.Title,
+		DeleteAt:         at,
+		SecondsRemaining: max(0, int(at.Sub(now).Round(time.Second)/time.Second)),
+	})
+}
+
+// handleDeadlineCancel serves DELETE /api/status/deadline?id=.
+func (s *Server) handleDeadlineCancel(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	if !s.clearDeadline(id) {
+		apiError(w, "no deletion scheduled for "+id, http.StatusNotFound)
+		return
+	}
+	s.triggerStateSnapshot()
+	s.broadcastRegistry()
+
+	item := s.registryItem(id, "")
+	actor := actorFrom(r.Context())
+	s.logger.InfoContext(r.Context(), "scheduled deletion canceled", "id", id, "actor", actor)
+	s.events.Emit(events.Event{
+		Type:    events.TypeDeletionCanceled,
+		Actor:   actor,
+		Subject: id,
+		Data:    map[string]any{"type": item.Type, "title": item.Title},
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
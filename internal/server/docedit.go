@@ -0,0 +1,99 @@
+/*
+File: internal/server/docedit.go
+Description: Doc content editing. PATCH /api/docs?id= takes a list of structured
+edits (insert text, replace text, append heading) and applies them through the
+workspace layer, which does the index math. Edits honor policy protection and
+are reported as "would update" events in SIMULATE mode; real edits are
+preceded by a backup.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// maxDocEditBody bounds a PATCH /api/docs body.
+const maxDocEditBody = 4 << 20
+
+// DocEditRequest is the body of PATCH /api/docs.
+type DocEditRequest struct {
+	Edits []workspace.DocEdit `json:"edits"`
+}
+
+// DocEditSimulated answers an edit made in SIMULATE mode.
+type DocEditSimulated struct {
+	Simulated bool `json:"simulated"`
+	Edits     int  `json:"edits"`
+}
+
+func (s *Server) handleDocEdit(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	var req DocEditRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDocEditBody)).Decode(&req); err != nil {
+		apiError(w, "invalid edits: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := workspace.ValidateDocEdits(req.Edits); err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	item := s.registryItem(id, "doc")
+	if err := s.checkProtected(item); err != nil {
+		writeAPIError(w, err, deleteErrorStatus(err))
+		return
+	}
+	actor, mode := actorFrom(r.Context()), s.currentMode()
+	ops := make([]string, len(req.Edits))
+	for i, e := range req.Edits {
+		ops[i] = e.Op
+	}
+	data := map[string]any{"title": item.Title, "edits": ops, "mode": mode}
+
+	switch mode {
+	case "SIMULATE":
+		s.logger.InfoContext(r.Context(), "would update doc", "id", id, "edits", len(req.Edits), "actor", actor)
+		s.events.Emit(events.Event{Type: events.TypeDocWouldUpdate, Actor: actor, Subject: id, Data: data})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DocEditSimulated{Simulated: true, Edits: len(req.Edits)})
+		return
+	case "AIRGAP":
+		apiError(w, "editing documents is not possible in AIRGAP mode", http.StatusConflict)
+		return
+	}
+
+	backupRef, err := s.backupItem(r.Context(), item)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if backupRef != "" {
+		data["backup"] = backupRef
+	}
+	res, err := s.ws.UpdateDoc(r.Context(), id, req.Edits)
+	if res.Batches > 0 {
+		// Some edits landed even if a later batch failed; record those.
+		data["replaced"], data["batches"] = res.Replaced, res.Batches
+		s.events.Emit(events.Event{Type: events.TypeDocUpdated, Actor: actor, Subject: id, Data: data})
+	}
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, workspace.ErrDocTextNotFound) {
+			status = http.StatusUnprocessableEntity
+		}
+		writeAPIError(w, err, status)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "doc updated", "id", id, "edits", len(req.Edits), "replaced", res.Replaced, "actor", actor)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
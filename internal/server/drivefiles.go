@@ -0,0 +1,160 @@
+/*
+File: internal/server/drivefiles.go
+Description: Drive file endpoints. Downloads and exports stream from Drive to
+the client, and uploads stream from the client to Drive, so file size is
+bounded by the upload limit rather than server memory.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// maxUploadBody bounds an uploaded file.
+const maxUploadBody = 256 << 20
+
+// UploadResponse is the body of POST /api/drive/upload.
+type UploadResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	URL      string `json:"url,omitempty"`
+}
+
+// WithDriveUpload enables POST /api/drive/upload.
+func WithDriveUpload() Option {
+	return func(s *Server) { s.driveUpload = true }
+}
+
+// handleDriveUpload serves POST /api/drive/upload?name=[&parent=], storing the
+// request body as a file of the request's Content-Type.
+func (s *Server) handleDriveUpload(w http.ResponseWriter, r *http.Request) {
+	if !s.driveUpload {
+		apiError(w, "uploads not enabled (AXIS_DRIVE_UPLOAD)", http.StatusNotFound)
+		return
+	}
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "uploading writes to Drive, which AIRGAP mode cannot do", http.StatusConflict)
+		return
+	}
+	q := r.URL.Query()
+	name := strings.TrimSpace(q.Get("name"))
+	if name == "" {
+		apiError(w, "missing name", http.StatusBadRequest)
+		return
+	}
+	mimeType := r.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	parent := q.Get("parent")
+	file, err := s.ws.UploadFile(r.Context(), parent, name, mimeType, http.MaxBytesReader(w, r.Body, maxUploadBody), nil)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apiError(w, "file exceeds the upload limit", http.StatusRequestEntityTooLarge)
+			return
+		}
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+
+	s.logger.InfoContext(r.Context(), "file uploaded", "id", file.Id, "name", file.Name, "mime_type", file.MimeType, "bytes", file.Size, "parent", parent)
+	s.events.Emit(events.Event{
+		Type:    events.TypeFileUploaded,
+		Actor:   actorFrom(r.Context()),
+		Subject: file.Id,
+		Data:    map[string]any{"name": file.Name, "mime_type": file.MimeType, "size": file.Size, "parent": parent},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(UploadResponse{ID: file.Id, Name: file.Name, MimeType: file.MimeType, Size: file.Size, URL: file.WebViewLink})
+}
+
+// handleDriveDownload serves GET /api/drive/download?id=.
+func (s *Server) handleDriveDownload(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	media, err := s.wsFor(r.Context()).DownloadFile(r.Context(), id)
+	if errors.Is(err, workspace.ErrNativeFile) {
+		apiError(w, err.Error()+" (use /api/drive/export?id=&mime=)", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	defer media.Body.Close()
+
+	w.Header().Set("Content-Type", media.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": media.Name}))
+	if media.ContentLength > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(media.ContentLength, 10))
+	}
+	written, err := io.Copy(w, media.Body)
+	if err != nil {
+		// Headers are already sent; the client sees a truncated body.
+		s.logger.ErrorContext(r.Context(), "file download interrupted", "id", id, "bytes", written, "error", err)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "file downloaded", "id", id, "bytes", written)
+}
+
+// handleDriveExport serves GET /api/drive/export?id=&mime=, converting a
+// Google-native file to mime.
+func (s *Server) handleDriveExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	id, target := q.Get("id"), q.Get("mime")
+	if id == "" || target == "" {
+		apiError(w, "missing id or mime", http.StatusBadRequest)
+		return
+	}
+	if _, _, err := mime.ParseMediaType(target); err != nil {
+		apiError(w, "invalid mime: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Headers wait for the first byte so an export Drive refuses still gets
+	// an error envelope.
+	out := &exportWriter{ResponseWriter: w, header: func(h http.Header) {
+		h.Set("Content-Type", target)
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": workspace.AttachmentFilename(id, target),
+		}))
+	}}
+	written, err := s.wsFor(r.Context()).ExportFile(r.Context(), id, target, out)
+	switch {
+	case err != nil && !out.started:
+		writeAPIError(w, err, http.StatusBadGateway)
+	case err != nil:
+		s.logger.ErrorContext(r.Context(), "file export interrupted", "id", id, "mime", target, "bytes", written, "error", err)
+	default:
+		s.logger.InfoContext(r.Context(), "file exported", "id", id, "mime", target, "bytes", written)
+	}
+}
+
+// exportWriter sets the response headers on the first write.
+type exportWriter struct {
+	http.ResponseWriter
+	header  func(http.Header)
+	started bool
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.started {
+		e.started = true
+		e.header(e.ResponseWriter.Header())
+	}
+	return e.ResponseWriter.Write(p)
+}
@@ -0,0 +1,120 @@
+/*
+File: internal/server/export.go
+Description: HTTP export endpoints. Streams the Keep note bundle as a zip archive,
+or with ?async=true builds it in a job whose result is the archive, and the
+registry as a CSV or Excel spreadsheet.
+*/
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"axis/internal/export"
+	"axis/internal/jobs"
+)
+
+// progressSink counts every file written to the archive as a step done.
+type progressSink struct {
+	export.Sink
+	p *jobs.Progress
+}
+
+func (s progressSink) Put(ctx context.Context, name string, r io.Reader) error {
+	err := s.Sink.Put(ctx, name, r)
+	s.p.Add(1)
+	return err
+}
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("format")
+	if raw == "" {
+		raw = string(export.FormatMarkdown)
+	}
+	format, err := export.ParseFormat(raw)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	filename := fmt.Sprintf("axis-export-%s.zip", start.UTC().Format("20060102-150405"))
+	if asyncRequested(r) {
+		s.submitJob(w, r, "export", func(ctx context.Context, p *jobs.Progress) (jobs.Result, error) {
+			return s.exportToFile(ctx, format, filename, p)
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// The archive streams as it is built, so failures after the first write
+	// can only be logged; the client receives a truncated zip.
+	sink := export.NewZipSink(w)
+	sum, err := export.New(s.ws, format).Export(r.Context(), sink)
+	if err != nil {
+		s.logger.ErrorContext(r.Context(), "export failed", "error", err, "notes", sum.Notes)
+		return
+	}
+	if err := sink.Close(); err != nil {
+		s.logger.ErrorContext(r.Context(), "export archive close failed", "error", err)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "export streamed", "format", format, "notes", sum.Notes, "attachments", sum.Attachments,
+		"failed", len(sum.Failed), "duration", time.Since(start))
+}
+
+// exportToFile builds the archive in a temporary file for a job to serve.
+func (s *Server) exportToFile(ctx context.Context, format export.Format, filename string, p *jobs.Progress) (jobs.Result, error) {
+	start := time.Now()
+	f, err := os.CreateTemp("", "axis-export-*.zip")
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	sink := export.NewZipSink(f)
+	sum, err := export.New(s.ws, format).Export(ctx, progressSink{Sink: sink, p: p})
+	if err == nil {
+		err = sink.Close()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return jobs.Result{Value: sum}, err
+	}
+	s.logger.InfoContext(ctx, "export written", "format", format, "notes", sum.Notes, "attachments", sum.Attachments,
+		"failed", len(sum.Failed), "duration", time.Since(start))
+	return jobs.Result{Value: sum, Path: f.Name(), ContentType: "application/zip", Filename: filename}, nil
+}
+
+// handleRegistryExport serves GET /api/registry/export?format=csv|xlsx with
+// every registry item, enriched as /api/registry lists them.
+func (s *Server) handleRegistryExport(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("format")
+	if raw == "" {
+		raw = string(export.TableCSV)
+	}
+	format, err := export.ParseTableFormat(raw)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	items, err := s.RegistrySnapshot(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	filename := fmt.Sprintf("axis-registry-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := export.WriteRegistry(w, format, items); err != nil {
+		s.logger.ErrorContext(r.Context(), "registry export failed", "format", format, "error", err)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "registry exported", "format", format, "items", len(items), "actor", actorFrom(r.Context()))
+}
@@ -0,0 +1,67 @@
+/*
+File: internal/server/external.go
+Description: External collaborator exposure. The "external" enricher flags
+registry items shared with anyone outside the Workspace's own domains, lists
+those grants, and /api/registry?external=true narrows the registry to them for
+recurring sharing reviews.
+*/
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"axis/internal/workspace"
+)
+
+// WithInternalDomains sets the domains whose users are not external
+// collaborators. Without any, items are never flagged.
+func WithInternalDomains(domains []string) Option {
+	return func(s *Server) {
+		s.internalDomains = make(map[string]bool, len(domains))
+		for _, d := range domains {
+			if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+				s.internalDomains[d] = true
+			}
+		}
+	}
+}
+
+// externalGrants returns the grants in shared that reach outside the internal
+// domains: outside emails and domains, and link sharing.
+func (s *Server) externalGrants(shared []string) []string {
+	var external []string
+	for _, grant := range shared {
+		domain := grant
+		switch {
+		case grant == workspace.GrantAnyone:
+			external = append(external, grant)
+			continue
+		case strings.HasPrefix(grant, "domain:"):
+			domain = strings.TrimPrefix(grant, "domain:")
+		default:
+			_, domain, _ = strings.Cut(grant, "@")
+		}
+		if !s.internalDomains[strings.ToLower(domain)] {
+			external = append(external, grant)
+		}
+	}
+	return external
+}
+
+// filterExternal applies ?external=: true keeps externally shared items,
+// false keeps the rest, and an absent parameter keeps everything.
+func filterExternal(r *http.Request, items []workspace.RegistryItem) []workspace.RegistryItem {
+	raw := r.URL.Query().Get("external")
+	if raw == "" {
+		return items
+	}
+	want := truthyParam(raw)
+	filtered := make([]workspace.RegistryItem, 0, len(items))
+	for _, item := range items {
+		if item.External == want {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,53 @@
+/*
+File: internal/server/faults.go
+Description: Fault injection control for builds tagged faults.
+GET /api/debug/faults lists the installed faults with their hit counts, PUT
+replaces them and DELETE clears them. Without the tag, or without an injector
+in the API transport, the routes are not registered.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"axis/internal/faults"
+)
+
+// maxFaultsBody bounds a PUT /api/debug/faults body.
+const maxFaultsBody = 64 << 10
+
+// FaultsRequest is the body of PUT /api/debug/faults.
+type FaultsRequest struct {
+	Faults []faults.Fault `json:"faults"`
+}
+
+// WithFaults exposes the injector under the API transport at /api/debug/faults.
+func WithFaults(in *faults.Injector) Option {
+	return func(s *Server) {
+		if faults.Enabled {
+			s.faults = in
+		}
+	}
+}
+
+func (s *Server) handleFaults(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPut:
+		var req FaultsRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFaultsBody)).Decode(&req); err != nil {
+			apiError(w, "invalid faults: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.faults.Set(req.Faults); err != nil {
+			writeAPIError(w, err, http.StatusBadRequest)
+			return
+		}
+		s.logger.WarnContext(r.Context(), "fault injection updated", "faults", len(req.Faults), "actor", actorFrom(r.Context()))
+	case http.MethodDelete:
+		s.faults.Set(nil)
+		s.logger.WarnContext(r.Context(), "fault injection cleared", "actor", actorFrom(r.Context()))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FaultsRequest{Faults: s.faults.Faults()})
+}
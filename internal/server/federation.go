@@ -0,0 +1,122 @@
+/*
+File: internal/server/federation.go
+Description: Federation endpoints. Every instance serves its registry in
+figures at GET /api/registry/summary; a central instance configured with a
+federation file refreshes its members' summaries and reports in the background
+and serves the aggregate at GET /api/federation.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"axis/internal/federation"
+	"axis/internal/store"
+)
+
+const (
+	defaultFederationInterval = 15 * time.Minute
+	summaryDeletionWindow     = 30 * 24 * time.Hour
+)
+
+// WithFederation aggregates the members of f, refreshed every interval
+// (15 minutes when zero).
+func WithFederation(f *federation.Federation, interval time.Duration) Option {
+	return func(s *Server) {
+		s.federation = f
+		s.federationInterval = interval
+	}
+}
+
+// runFederation refreshes the members' reports. Every instance of a cluster
+// refreshes on its own, since the fetches only read.
+func (s *Server) runFederation(ctx context.Context) {
+	if s.federation == nil {
+		return
+	}
+	interval := s.federationInterval
+	if interval <= 0 {
+		interval = defaultFederationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.refreshFederation(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) refreshFederation(ctx context.Context) *federation.Report {
+	rep := s.federation.Refresh(ctx)
+	for _, m := range rep.Members {
+		if !m.OK {
+			s.logger.WarnContext(ctx, "federation member unavailable", "member", m.Name, "error", m.Error)
+		}
+	}
+	return rep
+}
+
+func (s *Server) handleFederation(w http.ResponseWriter, r *http.Request) {
+	if s.federation == nil {
+		apiError(w, "federation is not configured", http.StatusNotFound)
+		return
+	}
+	rep := s.federation.Last()
+	if rep == nil || truthyParam(r.URL.Query().Get("refresh")) {
+		rep = s.refreshFederation(r.Context())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rep)
+}
+
+func (s *Server) handleRegistrySummary(w http.ResponseWriter, r *http.Request) {
+	items, err := s.RegistrySnapshot(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	sum := federation.Summary{
+		Tenant:    s.about.Tenant,
+		Generated: time.Now(),
+		Items:     len(items),
+		ByType:    make(map[string]int),
+		ByStatus:  make(map[string]int),
+		Mode:      s.currentMode(),
+		Degraded:  s.sources.degraded(),
+	}
+	for _, item := range items {
+		sum.ByType[item.Type]++
+		if item.Status != "" {
+			sum.ByStatus[item.Status]++
+		}
+		if item.Protected {
+			sum.Protected++
+		}
+		if item.External {
+			sum.External++
+		}
+		if item.LinkRot {
+			sum.LinkRot++
+		}
+		sum.Bytes += item.Size
+	}
+	deletions, err := s.store.ListDeletions(r.Context(), store.Query{Since: time.Now().Add(-summaryDeletionWindow)})
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	for _, d := range deletions {
+		if d.Outcome == store.OutcomeDeleted {
+			sum.Deleted++
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sum)
+}
@@ -0,0 +1,105 @@
+/*
+File: internal/server/groups.go
+Description: Directory groups and organizational units. GET /api/admin/groups
+lists groups, GET /api/admin/groups/{group}/members a group's direct members
+and GET /api/admin/orgunits the org unit tree; user rules can test a user's
+org_unit and groups to scope automation to, say, users in /Contractors.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"axis/internal/workspace"
+)
+
+var errGroupsDisabled = errors.New("directory groups not enabled (AXIS_DIRECTORY_GROUPS)")
+
+// WithDirectoryGroups enables the group and org unit endpoints and the groups
+// attribute of user rules.
+func WithDirectoryGroups() Option {
+	return func(s *Server) { s.directoryGroups = true }
+}
+
+// pageLimit parses a ?limit= of 1 to most; absent is 0, the call's default.
+func pageLimit(raw string, most int64) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 1 || n > most {
+		return 0, fmt.Errorf("invalid limit (1-%d)", most)
+	}
+	return n, nil
+}
+
+// handleGroups serves GET /api/admin/groups[?q=][&user=][&limit=][&page_token=],
+// one page of groups with the next page's token in X-Next-Page-Token. ?user=
+// lists the groups a user belongs to directly.
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	if !s.directoryGroups {
+		writeAPIError(w, errGroupsDisabled, http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	limit, err := pageLimit(q.Get("limit"), 200)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	groups, next, err := s.ws.ListGroups(r.Context(), workspace.GroupQuery{
+		Query: q.Get("q"), User: q.Get("user"), PageSize: limit, PageToken: q.Get("page_token"),
+	})
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if next != "" {
+		w.Header().Set("X-Next-Page-Token", next)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(groups)
+}
+
+// handleGroupMembers serves GET /api/admin/groups/{group}/members[?limit=][&page_token=].
+func (s *Server) handleGroupMembers(w http.ResponseWriter, r *http.Request) {
+	if !s.directoryGroups {
+		writeAPIError(w, errGroupsDisabled, http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	limit, err := pageLimit(q.Get("limit"), 200)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	members, next, err := s.ws.ListGroupMembers(r.Context(), r.PathValue("group"), limit, q.Get("page_token"))
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if next != "" {
+		w.Header().Set("X-Next-Page-Token", next)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// handleOrgUnits serves GET /api/admin/orgunits.
+func (s *Server) handleOrgUnits(w http.ResponseWriter, r *http.Request) {
+	if !s.directoryGroups {
+		writeAPIError(w, errGroupsDisabled, http.StatusNotFound)
+		return
+	}
+	units, err := s.ws.ListOrgUnits(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(units)
+}
@@ -0,0 +1,136 @@
+/*
+File: internal/server/health.go
+Description: Kubernetes probes. GET /healthz answers as long as the process
+serves HTTP. GET /readyz checks that the active service account key still
+mints tokens, that at least one Google API answers and that the state store
+accepts writes; results are cached briefly so frequent probes do not spend
+quota. Both are unauthenticated and carry no deployment details.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"axis/internal/store"
+)
+
+const (
+	readyCacheTTL     = 30 * time.Second
+	readyCheckTimeout = 10 * time.Second
+)
+
+// Readiness check names.
+const (
+	CheckToken  = "token"
+	CheckGoogle = "google"
+	CheckStore  = "store"
+)
+
+// ReadyCheck is the outcome of one readiness check. Failure details are only
+// logged, since the probe is unauthenticated.
+type ReadyCheck struct {
+	Name string `json:"name"`
+	OK   bool   `json:"ok"`
+}
+
+// ReadyResponse is the body of GET /readyz.
+type ReadyResponse struct {
+	Ready   bool         `json:"ready"`
+	Checks  []ReadyCheck `json:"checks"`
+	Checked time.Time    `json:"checked"`
+}
+
+// readiness caches the last readiness result. Its mutex also makes concurrent
+// probes wait for one run instead of starting their own.
+type readiness struct {
+	mu   sync.Mutex
+	last *ReadyResponse
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte("ok\n"))
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	res := s.ready(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !res.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// ready returns the cached readiness result, running the checks again when
+// it is older than readyCacheTTL.
+func (s *Server) ready(ctx context.Context) ReadyResponse {
+	c := &s.readiness
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.last != nil && time.Since(c.last.Checked) < readyCacheTTL {
+		return *c.last
+	}
+
+	// A probe that gives up must not leave a half-run check cached.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), readyCheckTimeout)
+	defer cancel()
+	checks := []struct {
+		name string
+		run  func(context.Context) error
+	}{
+		{CheckToken, s.checkToken},
+		{CheckGoogle, s.ws.Probe},
+		{CheckStore, s.checkStore},
+	}
+	res := ReadyResponse{Ready: true, Checks: make([]ReadyCheck, len(checks))}
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = check.run(ctx)
+		}()
+	}
+	wg.Wait()
+	res.Checked = time.Now()
+	for i, check := range checks {
+		res.Checks[i] = ReadyCheck{Name: check.name, OK: errs[i] == nil}
+		if errs[i] != nil {
+			res.Ready = false
+			s.logger.WarnContext(ctx, "readiness check failed", "check", check.name, "error", errs[i])
+		}
+	}
+	if c.last != nil && c.last.Ready != res.Ready {
+		s.logger.InfoContext(ctx, "readiness changed", "ready", res.Ready)
+	}
+	c.last = &res
+	return res
+}
+
+// checkToken mints a token with the active key. Without key rotation the API
+// probe exercises the token source instead.
+func (s *Server) checkToken(ctx context.Context) error {
+	if s.credentials == nil {
+		return nil
+	}
+	if st := s.credentials.CheckActive(ctx); !st.Healthy {
+		return errors.New(st.Error)
+	}
+	return nil
+}
+
+// checkStore probes the state store for writes when the backend supports it.
+func (s *Server) checkStore(ctx context.Context) error {
+	if p, ok := s.store.(store.Prober); ok {
+		return p.Probe(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+/*
+File: internal/server/history.go
+Description: Registry history and rule previews. With a SQL state backend the
+leader stores a registry snapshot once a day; POST /api/rules/preview
+evaluates a configured or draft rule against the stored snapshots, each at its
+own time, to show what the rule would have matched before it is enabled.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"axis/internal/rules"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const (
+	snapshotInterval         = 24 * time.Hour
+	snapshotCheckInterval    = time.Hour
+	defaultSnapshotRetention = 90 * 24 * time.Hour
+	defaultPreviewDays       = 90
+	maxPreviewBody           = 64 << 10
+)
+
+// WithSnapshotRetention sets how long daily registry snapshots are kept
+// (default 90 days), which bounds how far back rule previews reach.
+func WithSnapshotRetention(d time.Duration) Option {
+	return func(s *Server) { s.snapshotRetention = d }
+}
+
+// RulePreviewRequest is the body of POST /api/rules/preview. Name selects a
+// rule from the rules file; otherwise Rule is a draft to evaluate.
+type RulePreviewRequest struct {
+	Name string     `json:"name,omitempty"`
+	Rule rules.Rule `json:"rule"`
+	Days int        `json:"days,omitempty"`
+}
+
+// RulePreview is what a rule would have matched over the stored history.
+type RulePreview struct {
+	Rule      rules.Rule        `json:"rule"`
+	Since     time.Time         `json:"since"`
+	Snapshots []PreviewSnapshot `json:"snapshots"`
+	Items     []PreviewMatch    `json:"items"`
+	Matched   int               `json:"matched"`
+	Protected int               `json:"protected"` // matched items that policy would have kept
+	Note      string            `json:"note,omitempty"`
+}
+
+// PreviewSnapshot summarizes the matches in one snapshot. New counts items
+// matching for the first time.
+type PreviewSnapshot struct {
+	Time    time.Time `json:"time"`
+	Items   int       `json:"items"`
+	Matched int       `json:"matched"`
+	New     int       `json:"new"`
+}
+
+// PreviewMatch is an item the rule matched in at least one snapshot.
+type PreviewMatch struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title"`
+	FirstMatched time.Time `json:"first_matched"`
+	LastMatched  time.Time `json:"last_matched"`
+	Protected    bool      `json:"protected"`
+	Exists       bool      `json:"exists"` // still in the current registry
+}
+
+// runSnapshots stores a registry snapshot on the leader whenever the newest is
+// a day old, and prunes snapshots past their retention.
+func (s *Server) runSnapshots(ctx context.Context) {
+	sn, ok := s.store.(store.Snapshotter)
+	if !ok {
+		return
+	}
+	ticker := time.NewTicker(snapshotCheckInterval)
+	defer ticker.Stop()
+	for {
+		if s.IsLeader() {
+			s.takeSnapshot(ctx, sn)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) takeSnapshot(ctx context.Context, sn store.Snapshotter) {
+	latest, err := sn.LatestSnapshot(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "registry snapshot check failed", "error", err)
+		return
+	}
+	if time.Since(latest) < snapshotInterval {
+		return
+	}
+	items, err := s.RegistrySnapshot(ctx)
+	if err != nil || len(items) == 0 {
+		return
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "registry snapshot marshal failed", "error", err)
+		return
+	}
+	if err := sn.SaveSnapshot(ctx, store.Snapshot{Time: time.Now().UTC(), Items: data}); err != nil {
+		s.logger.WarnContext(ctx, "registry snapshot failed", "error", err)
+		return
+	}
+	if err := sn.PruneSnapshots(ctx, time.Now().Add(-s.snapshotRetention)); err != nil {
+		s.logger.WarnContext(ctx, "registry snapshot prune failed", "error", err)
+	}
+	s.logger.DebugContext(ctx, "registry snapshot stored", "items", len(items))
+}
+
+func (s *Server) handleRulePreview(w http.ResponseWriter, r *http.Request) {
+	sn, ok := s.store.(store.Snapshotter)
+	if !ok {
+		apiError(w, "registry history needs a sqlite or postgres state backend", http.StatusNotFound)
+		return
+	}
+	var req RulePreviewRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPreviewBody)).Decode(&req); err != nil {
+		apiError(w, "invalid preview request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule := req.Rule
+	if req.Name != "" {
+		if rule, ok = s.rules.Rule(req.Name); !ok {
+			apiError(w, fmt.Sprintf("unknown rule %q", req.Name), http.StatusNotFound)
+			return
+		}
+	} else if _, err := rules.NewSet(rules.Config{Rules: []rules.Rule{rule}}); err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	if rule.NeedsContent() {
+		apiError(w, "content fields cannot be evaluated against registry history", http.StatusUnprocessableEntity)
+		return
+	}
+	days := req.Days
+	if days == 0 {
+		days = defaultPreviewDays
+	}
+	if days < 1 {
+		apiError(w, "days must be positive", http.StatusBadRequest)
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	snapshots, err := sn.ListSnapshots(r.Context(), store.Query{Since: since})
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	current, _ := s.cachedItemsFresh()
+	preview, err := s.previewRule(r.Context(), rule, snapshots, current)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	preview.Since = since
+	switch {
+	case len(snapshots) == 0:
+		preview.Note = "no registry snapshots in the requested period yet; one is stored daily"
+	case snapshots[0].Time.Sub(since) > snapshotInterval:
+		preview.Note = "history starts " + snapshots[0].Time.Format(time.DateOnly)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// previewRule evaluates rule against each snapshot at the snapshot's time.
+// Owner activity attributes reflect the current directory, not the past.
+func (s *Server) previewRule(ctx context.Context, rule rules.Rule, snapshots []store.Snapshot, current []workspace.RegistryItem) (RulePreview, error) {
+	res := RulePreview{
+		Rule:      rule,
+		Snapshots: make([]PreviewSnapshot, 0, len(snapshots)),
+		Items:     []PreviewMatch{},
+	}
+	byID := make(map[string]*PreviewMatch)
+	for _, sn := range snapshots {
+		var items []workspace.RegistryItem
+		if err := json.Unmarshal(sn.Items, &items); err != nil {
+			return res, fmt.Errorf("registry snapshot of %s is unreadable: %w", sn.Time.Format(time.RFC3339), err)
+		}
+		sum := PreviewSnapshot{Time: sn.Time, Items: len(items)}
+		for _, item := range items {
+			if !rule.Matches(s.itemAttributes(ctx, item, false), sn.Time) {
+				continue
+			}
+			sum.Matched++
+			m, seen := byID[item.ID]
+			if !seen {
+				sum.New++
+				m = &PreviewMatch{ID: item.ID, Type: item.Type, FirstMatched: sn.Time}
+				byID[item.ID] = m
+			}
+			m.Title, m.LastMatched, m.Protected = item.Title, sn.Time, item.Protected
+		}
+		res.Snapshots = append(res.Snapshots, sum)
+	}
+
+	exists := make(map[string]bool, len(current))
+	for _, item := range current {
+		exists[item.ID] = true
+	}
+	for _, m := range byID {
+		m.Exists = exists[m.ID]
+		if m.Protected {
+			res.Protected++
+		}
+		res.Items = append(res.Items, *m)
+	}
+	slices.SortFunc(res.Items, func(a, b PreviewMatch) int {
+		if c := a.FirstMatched.Compare(b.FirstMatched); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	res.Matched = len(res.Items)
+	return res, nil
+}
@@ -0,0 +1,206 @@
+/*
+File: internal/server/inactive.go
+Description: Inactive user detection. Each user's last sign-in is the later of
+the Directory's lastLoginTime and the newest login in the Reports audit log.
+GET /api/reports/inactive-users lists accounts idle past a threshold, and item
+rules can test owner_inactive and owner_last_login to scope content policies to
+what inactive users left behind.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultInactiveAfter    = 90 * 24 * time.Hour
+	activityRefreshInterval = time.Hour
+	activityTimeout         = 10 * time.Minute
+	// loginReportLookback is about as far back as the Reports API keeps
+	// login events.
+	loginReportLookback = 180 * 24 * time.Hour
+)
+
+// userActivity is one reading of every user's last sign-in.
+type userActivity struct {
+	users        map[string]activityEntry // by lowercased email
+	at           time.Time
+	reportsError string
+}
+
+type activityEntry struct {
+	Name      string
+	Suspended bool
+	LastLogin *time.Time
+	Source    string // "directory" or "reports"; "" when never signed in
+}
+
+// InactiveUser is an account idle past the threshold.
+type InactiveUser struct {
+	Email     string     `json:"email"`
+	Name      string     `json:"name"`
+	Suspended bool       `json:"suspended"`
+	LastLogin *time.Time `json:"last_login,omitempty"`
+	Source    string     `json:"source,omitempty"`
+	// InactiveDays counts days since LastLogin; -1 for accounts that never
+	// signed in.
+	InactiveDays int `json:"inactive_days"`
+}
+
+// InactiveUsersResponse is the body of GET /api/reports/inactive-users.
+type InactiveUsersResponse struct {
+	Users        []InactiveUser `json:"users"`
+	Days         int            `json:"days"`
+	Computed     time.Time      `json:"computed"`
+	ReportsError string         `json:"reports_error,omitempty"`
+}
+
+// WithInactiveAfter sets how long without a sign-in makes a user inactive
+// (90 days by default).
+func WithInactiveAfter(d time.Duration) Option {
+	return func(s *Server) { s.inactiveAfter = d }
+}
+
+func (s *Server) inactiveThreshold() time.Duration {
+	if s.inactiveAfter > 0 {
+		return s.inactiveAfter
+	}
+	return defaultInactiveAfter
+}
+
+// inactive reports whether an entry has gone without a sign-in for longer
+// than after.
+func (e activityEntry) inactive(now time.Time, after time.Duration) bool {
+	return e.LastLogin == nil || now.Sub(*e.LastLogin) > after
+}
+
+// refreshActivity reads the directory and, when configured, login reports.
+// A failing Reports call falls back to Directory times and is recorded.
+func (s *Server) refreshActivity(ctx context.Context) (*userActivity, error) {
+	users, err := s.ws.ListDirectoryUsers(ctx, s.userSchemas)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	act := &userActivity{users: make(map[string]activityEntry, len(users)), at: now}
+	for _, u := range users {
+		e := activityEntry{Name: u.Name, Suspended: u.Suspended, LastLogin: u.LastLogin}
+		if u.LastLogin != nil {
+			e.Source = "directory"
+		}
+		act.users[strings.ToLower(u.Email)] = e
+	}
+	if s.ws.ReportsEnabled() {
+		logins, err := s.ws.LastLogins(ctx, now.Add(-loginReportLookback))
+		if err != nil {
+			act.reportsError = err.Error()
+			s.logger.WarnContext(ctx, "login reports unavailable; using directory times", "error", err)
+		}
+		for email, at := range logins {
+			e, ok := act.users[email]
+			if ok && (e.LastLogin == nil || at.After(*e.LastLogin)) {
+				e.LastLogin, e.Source = &at, "reports"
+				act.users[email] = e
+			}
+		}
+	}
+	s.activity.Store(act)
+	return act, nil
+}
+
+// runActivityRefresh keeps owner activity current for rules that test it.
+func (s *Server) runActivityRefresh(ctx context.Context) {
+	if !s.rules.NeedsActivity() {
+		return
+	}
+	refresh := func() {
+		rctx, cancel := context.WithTimeout(ctx, activityTimeout)
+		defer cancel()
+		if _, err := s.refreshActivity(rctx); err != nil {
+			s.logger.ErrorContext(ctx, "user activity refresh failed", "error", err)
+			return
+		}
+		s.broadcastRegistry()
+	}
+	refresh()
+	ticker := time.NewTicker(activityRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresh()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ownerActivity returns what is known about an item owner's sign-ins.
+func (s *Server) ownerActivity(owner string) (activityEntry, bool) {
+	act := s.activity.Load()
+	if act == nil || owner == "" {
+		return activityEntry{}, false
+	}
+	e, ok := act.users[strings.ToLower(owner)]
+	return e, ok
+}
+
+// handleInactiveUsers serves GET /api/reports/inactive-users[?days=][&refresh=].
+// Readings older than the refresh interval are taken again.
+func (s *Server) handleInactiveUsers(w http.ResponseWriter, r *http.Request) {
+	after := s.inactiveThreshold()
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 1 {
+			apiError(w, "invalid days", http.StatusBadRequest)
+			return
+		}
+		after = time.Duration(days) * 24 * time.Hour
+	}
+	act := s.activity.Load()
+	if act == nil || truthyParam(r.URL.Query().Get("refresh")) || time.Since(act.at) > activityRefreshInterval {
+		var err error
+		if act, err = s.refreshActivity(r.Context()); err != nil {
+			writeAPIError(w, err, http.StatusBadGateway)
+			return
+		}
+	}
+
+	now := time.Now()
+	users := []InactiveUser{}
+	for email, e := range act.users {
+		if !e.inactive(now, after) {
+			continue
+		}
+		u := InactiveUser{Email: email, Name: e.Name, Suspended: e.Suspended, LastLogin: e.LastLogin, Source: e.Source, InactiveDays: -1}
+		if e.LastLogin != nil {
+			u.InactiveDays = int(now.Sub(*e.LastLogin) / (24 * time.Hour))
+		}
+		users = append(users, u)
+	}
+	// Never signed in first, then longest idle.
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if (a.LastLogin == nil) != (b.LastLogin == nil) {
+			return a.LastLogin == nil
+		}
+		if a.InactiveDays != b.InactiveDays {
+			return a.InactiveDays > b.InactiveDays
+		}
+		return a.Email < b.Email
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(InactiveUsersResponse{
+		Users:        users,
+		Days:         int(after / (24 * time.Hour)),
+		Computed:     act.at,
+		ReportsError: act.reportsError,
+	})
+}
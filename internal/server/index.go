@@ -0,0 +1,142 @@
+/*
+File: internal/server/index.go
+Description: Keeps the local full-text index current. Every registry refresh
+signals the indexer, which folds the new listing into the index in the
+background and saves it; /api/search answers from the index once it is built.
+With content indexing on, a second job then exports the queued Docs and
+Sheets.
+*/
+package server
+
+import (
+	"context"
+	"time"
+
+	"axis/internal/index"
+)
+
+const (
+	// indexUpdateTimeout bounds one indexing pass, which fetches edited Docs.
+	indexUpdateTimeout = 10 * time.Minute
+	// indexContentTimeout bounds one export pass; what is left over waits
+	// for the next.
+	indexContentTimeout = 30 * time.Minute
+)
+
+// WithIndex serves search from x and keeps it current, saving it to path.
+func WithIndex(x *index.Index, path string) Option {
+	return func(s *Server) {
+		s.index = x
+		s.indexPath = path
+	}
+}
+
+// signalIndex asks the indexer for a pass without blocking.
+func (s *Server) signalIndex() {
+	if s.index == nil {
+		return
+	}
+	select {
+	case s.indexDirty <- struct{}{}:
+	default:
+	}
+}
+
+// indexReady reports whether search can be answered from the index.
+func (s *Server) indexReady() bool {
+	return s.index != nil && !s.index.Built().IsZero()
+}
+
+// signalIndexContent asks the content job for a pass without blocking.
+func (s *Server) signalIndexContent() {
+	select {
+	case s.indexContent <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) runIndexer(ctx context.Context) {
+	if s.index == nil {
+		return
+	}
+	for {
+		select {
+		case <-s.indexDirty:
+			s.updateIndex(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// updateIndex indexes the cached registry. It waits for the first complete
+// listing so the index never drops items a partial listing left out.
+func (s *Server) updateIndex(parent context.Context) {
+	snap := s.registryCache.snapshot()
+	if !snap.loaded {
+		return
+	}
+	ctx, cancel := context.WithTimeout(parent, indexUpdateTimeout)
+	defer cancel()
+	start := time.Now()
+
+	stats, err := s.index.Update(ctx, s.ws, snap.items)
+	if err != nil {
+		s.logger.Warn("index update interrupted", "error", err)
+	}
+	for _, f := range stats.Failed {
+		s.logger.Warn("index: item skipped", "error", f)
+	}
+	if stats.Indexed > 0 || stats.Removed > 0 || stats.Queued > 0 {
+		s.saveIndex()
+	}
+	s.logger.Info("index updated", "duration", time.Since(start), "indexed", stats.Indexed,
+		"removed", stats.Removed, "queued", stats.Queued, "failed", len(stats.Failed), "items", s.index.Len())
+	if _, limit := s.index.ContentUsage(); limit > 0 {
+		s.signalIndexContent()
+	}
+}
+
+func (s *Server) saveIndex() {
+	if err := s.index.Save(s.indexPath); err != nil {
+		s.logger.Error("index save failed", "path", s.indexPath, "error", err)
+	}
+}
+
+// runIndexContent exports queued Docs and Sheets after each index pass, apart
+// from the indexer so titles and notes stay current while exports run.
+func (s *Server) runIndexContent(ctx context.Context) {
+	if s.index == nil {
+		return
+	}
+	for {
+		select {
+		case <-s.indexContent:
+			s.fillIndexContent(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) fillIndexContent(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, indexContentTimeout)
+	defer cancel()
+	start := time.Now()
+
+	stats, err := s.index.FillContent(ctx, s.ws)
+	if err != nil {
+		s.logger.Warn("index content export interrupted", "error", err)
+	}
+	for _, f := range stats.Failed {
+		s.logger.Warn("index: export skipped", "error", f)
+	}
+	if stats.Exported > 0 {
+		s.saveIndex()
+	}
+	used, limit := s.index.ContentUsage()
+	if stats.Exported > 0 || stats.Pending > 0 {
+		s.logger.Info("index content exported", "duration", time.Since(start), "exported", stats.Exported,
+			"bytes", stats.Bytes, "pending", stats.Pending, "used", used, "limit", limit)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+File: internal/server/items.go
+Description: Unified item detail endpoint. GET /api/items describes a note, Doc,
+Sheet or other Drive file in one shape, with the status and tags Axis keeps for
+it, so clients need a single code path for any item.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// ItemDetailResponse is the body of GET /api/items: the item as its API
+// reports it, plus what Axis tracks about it.
+type ItemDetailResponse struct {
+	workspace.ItemDetail
+	Status    string     `json:"status,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Protected bool       `json:"protected,omitempty"`
+	DeleteAt  *time.Time `json:"delete_at,omitempty"`
+}
+
+// handleItem serves GET /api/items?id=[&type=]. Without type the item's kind
+// is taken from the registry, then from the ID and Drive.
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	id, itemType := q.Get("id"), q.Get("type")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	switch itemType {
+	case "", "keep", "doc", "sheet", "file":
+	default:
+		apiError(w, "unknown item type (want keep, doc, sheet or file)", http.StatusBadRequest)
+		return
+	}
+	// Axis tracks nothing about another user's items.
+	item := workspace.RegistryItem{ID: id, Type: itemType}
+	if _, ok := subjectFrom(r.Context()); !ok {
+		item = s.registryItem(id, itemType)
+	}
+	detail, err := s.wsFor(r.Context()).GetItemDetail(r.Context(), id, item.Type)
+	switch {
+	case errors.Is(err, workspace.ErrItemType):
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	case err != nil:
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ItemDetailResponse{
+		ItemDetail: detail,
+		Status:     item.Status,
+		Tags:       item.Tags,
+		Protected:  item.Protected,
+		DeleteAt:   item.DeleteAt,
+	})
+}
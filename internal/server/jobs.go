@@ -0,0 +1,216 @@
+/*
+File: internal/server/jobs.go
+Description: Background job API. Bulk deletes and status changes, exports and
+the suspended-user scan run as jobs when called with ?async=true: the request
+is answered 202 with the job, progress arrives as job events on the stream, and
+GET /api/jobs/{id}/result returns the outcome. Jobs belong to the instance and
+user that submitted them; admins see everyone's, on the stream as in the API.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"axis/internal/auth"
+	"axis/internal/broker"
+	"axis/internal/events"
+	"axis/internal/jobs"
+)
+
+// apiPrefixKey carries the path the API is served under, /api or a tenant's
+// /api/t/{tenant}, for links in responses.
+type apiPrefixKey struct{}
+
+func withAPIPrefix(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, apiPrefixKey{}, prefix)
+}
+
+func apiPrefix(ctx context.Context) string {
+	if prefix, ok := ctx.Value(apiPrefixKey{}).(string); ok {
+		return prefix
+	}
+	return "/api"
+}
+
+// jobContext gives a job the submitting request's values (principal, actor,
+// quota partition, request ID) under the manager's cancellation.
+type jobContext struct {
+	context.Context
+	req context.Context
+}
+
+func (c jobContext) Value(key any) any {
+	if v := c.req.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// asyncRequested reports whether the request asks to run as a job.
+func asyncRequested(r *http.Request) bool {
+	return truthyParam(r.URL.Query().Get("async"))
+}
+
+// submitJob queues fn as a job of kind for the request's actor and answers
+// 202 with the job and its location.
+func (s *Server) submitJob(w http.ResponseWriter, r *http.Request, kind string, fn jobs.Func) {
+	req := context.WithoutCancel(r.Context())
+	job, err := s.jobs.Submit(kind, actorFrom(r.Context()), func(ctx context.Context, p *jobs.Progress) (jobs.Result, error) {
+		return fn(jobContext{Context: ctx, req: req}, p)
+	})
+	if err != nil {
+		writeAPIError(w, err, http.StatusServiceUnavailable)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "job submitted", "job", job.ID, "kind", kind, "actor", job.Actor)
+	w.Header().Set("Location", apiPrefix(r.Context())+"/jobs/"+job.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// notifyJob streams a job update to this instance's clients that may see the
+// job (see streamVisible), without the result, which can be large. Finished jobs are also recorded as events.
+func (s *Server) notifyJob(job jobs.Job) {
+	update := job
+	update.Result = nil
+	if err := s.hub.PublishJSON(broker.TypeJob, update); err != nil {
+		s.logger.Error("job update marshal failed", "error", err)
+	}
+	if !job.Final() {
+		return
+	}
+	data := map[string]any{"kind": job.Kind, "state": job.State, "done": job.Done}
+	if job.Total > 0 {
+		data["total"] = job.Total
+	}
+	if job.Error != "" {
+		data["error"] = job.Error
+	}
+	s.logger.Info("job finished", "job", job.ID, "kind", job.Kind, "state", job.State, "actor", job.Actor)
+	s.events.Emit(events.Event{Type: events.TypeJobFinished, Actor: job.Actor, Subject: job.ID, Data: data})
+}
+
+// visibleJob reports whether the request may see job: its submitter and
+// admins may.
+func visibleJob(r *http.Request, job jobs.Job) bool {
+	if p, ok := auth.FromContext(r.Context()); ok && p.Role >= auth.RoleAdmin {
+		return true
+	}
+	return job.Actor == actorFrom(r.Context())
+}
+
+// streamVisible reports whether a stream opened by r may be sent e: job
+// updates only reach the clients visibleJob lets see the job.
+func streamVisible(r *http.Request, e broker.Event) bool {
+	if e.Type != broker.TypeJob {
+		return true
+	}
+	var job jobs.Job
+	if err := json.Unmarshal(e.Data, &job); err != nil {
+		return false
+	}
+	return visibleJob(r, job)
+}
+
+// requestJob looks up the job named in the path, answering 404 if the request
+// may not see it.
+func (s *Server) requestJob(w http.ResponseWriter, r *http.Request) (jobs.Job, bool) {
+	job, ok := s.jobs.Get(r.PathValue("id"))
+	if !ok || !visibleJob(r, job) {
+		writeAPIError(w, jobs.ErrNotFound, http.StatusNotFound)
+		return jobs.Job{}, false
+	}
+	return job, true
+}
+
+// handleJobs serves GET /api/jobs, newest first.
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	list := []jobs.Job{}
+	for _, job := range s.jobs.List() {
+		if visibleJob(r, job) {
+			job.Result = nil
+			list = append(list, job)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleJob serves GET /api/jobs/{id}.
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.requestJob(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleJobCancel serves DELETE /api/jobs/{id}. A running job reports
+// canceled once it has stopped.
+func (s *Server) handleJobCancel(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.requestJob(w, r)
+	if !ok {
+		return
+	}
+	job, err := s.jobs.Cancel(job.ID)
+	switch {
+	case errors.Is(err, jobs.ErrFinished):
+		writeAPIError(w, err, http.StatusConflict)
+		return
+	case errors.Is(err, jobs.ErrNotFound):
+		writeAPIError(w, err, http.StatusNotFound)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "job cancel requested", "job", job.ID, "kind", job.Kind, "actor", actorFrom(r.Context()))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleJobResult serves GET /api/jobs/{id}/result: the file a job wrote, or
+// its JSON result. A job that has not succeeded has none.
+func (s *Server) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.requestJob(w, r)
+	if !ok {
+		return
+	}
+	if job.State != jobs.StateSucceeded {
+		apiError(w, fmt.Sprintf("job is %s", job.State), http.StatusConflict)
+		return
+	}
+	if !job.File {
+		w.Header().Set("Content-Type", "application/json")
+		if job.Result == nil {
+			w.Write([]byte("null\n"))
+			return
+		}
+		w.Write(append(job.Result, '\n'))
+		return
+	}
+	file, err := s.jobs.ResultFile(job.ID)
+	if err != nil {
+		writeAPIError(w, err, http.StatusNotFound)
+		return
+	}
+	f, err := os.Open(file.Path)
+	if err != nil {
+		writeAPIError(w, err, http.StatusGone)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Filename))
+	http.ServeContent(w, r, file.Filename, info.ModTime(), f)
+}
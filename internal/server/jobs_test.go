@@ -0,0 +1,45 @@
+/*
+File: internal/server/jobs_test.go
+Description: Job updates on the event stream reach the job's submitter and
+admins only.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"axis/internal/auth"
+	"axis/internal/broker"
+	"axis/internal/jobs"
+)
+
+func TestJobEventsReachSubmitterAndAdmins(t *testing.T) {
+	data, _ := json.Marshal(jobs.Job{ID: "j1", Kind: "export", Actor: "alice@example.com", State: "running"})
+	update := broker.Event{ID: 7, Type: broker.TypeJob, Data: data}
+	status := broker.Event{ID: 8, Type: broker.TypeStatus, Data: []byte(`{}`)}
+
+	for _, tc := range []struct {
+		name string
+		p    auth.Principal
+		want bool
+	}{
+		{"submitter", auth.Principal{Name: "alice@example.com", Role: auth.RoleOperator}, true},
+		{"admin", auth.Principal{Name: "root@example.com", Role: auth.RoleAdmin}, true},
+		{"other operator", auth.Principal{Name: "bob@example.com", Role: auth.RoleOperator}, false},
+		{"viewer", auth.Principal{Name: "carol@example.com", Role: auth.RoleViewer}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/events", nil)
+			ctx := auth.WithPrincipal(r.Context(), &tc.p)
+			r = r.WithContext(withActor(ctx, tc.p.Name))
+			if got := streamVisible(r, update); got != tc.want {
+				t.Errorf("job update visible = %v, want %v", got, tc.want)
+			}
+			if !streamVisible(r, status) {
+				t.Error("status event hidden")
+			}
+		})
+	}
+}
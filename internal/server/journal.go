@@ -0,0 +1,171 @@
+/*
+File: internal/server/journal.go
+Description: Persistent event journal. Status changes, simulations, audit events
+and registry deltas (not full snapshots) are recorded in the state store with
+time-based retention; GET /api/events/replay returns them to clients that
+reconnect after an outage or start fresh.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/events"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const (
+	// journalRegistryDelta entries carry added, changed and removed items.
+	journalRegistryDelta = "registry.delta"
+	journalAudit         = "audit"
+
+	defaultJournalRetention = 24 * time.Hour
+	journalPruneInterval    = time.Hour
+)
+
+// WithJournalRetention sets how long journal entries are kept (default 24h).
+func WithJournalRetention(d time.Duration) Option {
+	return func(s *Server) { s.journalRetention = d }
+}
+
+// registryDelta is the payload of a registry.delta entry.
+type registryDelta struct {
+	Added   []workspace.RegistryItem `json:"added,omitempty"`
+	Changed []workspace.RegistryItem `json:"changed,omitempty"`
+	Removed []string                 `json:"removed,omitempty"`
+}
+
+// journalBroadcast records a client broadcast. Ticks are not journaled and
+// registry snapshots are reduced to a delta against the previous one.
+func (s *Server) journalBroadcast(e broker.Event) {
+	switch e.Type {
+	case broker.TypeStatus, broker.TypeSimulated:
+		s.journal(e.Type, e.Data)
+	case broker.TypeRegistry:
+		s.journalRegistry(e.Data)
+	}
+}
+
+func (s *Server) journal(typ string, data []byte) {
+	if _, err := s.store.AppendJournal(context.Background(), store.JournalEntry{Type: typ, Data: data}); err != nil {
+		s.logger.Warn("journal append failed", "type", typ, "error", err)
+	}
+}
+
+// journalRegistry diffs a snapshot against the last journaled one. The first
+// snapshot after startup only sets the baseline; clients pair the journal with
+// GET /api/registry for the current state.
+func (s *Server) journalRegistry(data []byte) {
+	var items []workspace.RegistryItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return
+	}
+	next := make(map[string]string, len(items))
+	var delta registryDelta
+
+	s.journalMu.Lock()
+	baseline := s.journalBase != nil
+	for _, item := range items {
+		encoded, _ := json.Marshal(item)
+		next[item.ID] = string(encoded)
+		prev, ok := s.journalBase[item.ID]
+		switch {
+		case !ok:
+			delta.Added = append(delta.Added, item)
+		case prev != string(encoded):
+			delta.Changed = append(delta.Changed, item)
+		}
+	}
+	for id := range s.journalBase {
+		if _, ok := next[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+	s.journalBase = next
+	s.journalMu.Unlock()
+
+	if !baseline || len(delta.Added)+len(delta.Changed)+len(delta.Removed) == 0 {
+		return
+	}
+	payload, err := json.Marshal(delta)
+	if err != nil {
+		s.logger.Error("registry delta marshal failed", "error", err)
+		return
+	}
+	s.journal(journalRegistryDelta, payload)
+}
+
+// auditJournal journals every emitted audit event.
+type auditJournal struct{ s *Server }
+
+// Publish implements events.Publisher.
+func (a auditJournal) Publish(ctx context.Context, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = a.s.store.AppendJournal(ctx, store.JournalEntry{Type: journalAudit, Time: e.Time, Data: data})
+	return err
+}
+
+func (s *Server) runJournalPruner(ctx context.Context) {
+	ticker := time.NewTicker(journalPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !s.IsLeader() {
+				continue
+			}
+			if err := s.store.PruneJournal(ctx, time.Now().Add(-s.journalRetention)); err != nil {
+				s.logger.WarnContext(ctx, "journal prune failed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// JournalResponse is the body of /api/events/replay. Next is the cursor for the
+// following request (?after=next).
+type JournalResponse struct {
+	Entries []store.JournalEntry `json:"entries"`
+	Next    int64                `json:"next"`
+}
+
+// handleReplay serves ?since= (RFC 3339 or YYYY-MM-DD), ?after=<seq>,
+// ?type=status,audit and ?limit= (default 1000).
+func (s *Server) handleReplay(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	jq := store.JournalQuery{Since: q.Since, Types: q.Types, Limit: q.Limit}
+	if raw := r.URL.Query().Get("after"); raw != "" {
+		if jq.AfterSeq, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			apiError(w, fmt.Sprintf("invalid after %q", raw), http.StatusBadRequest)
+			return
+		}
+	}
+	entries, err := s.store.ListJournal(r.Context(), jq)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	resp := JournalResponse{Entries: entries, Next: jq.AfterSeq}
+	if len(entries) > 0 {
+		resp.Next = entries[len(entries)-1].Seq
+	} else {
+		resp.Entries = []store.JournalEntry{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
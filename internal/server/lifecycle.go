@@ -0,0 +1,145 @@
+/*
+File: internal/server/lifecycle.go
+Description: Server lifecycle. Start serves until its context ends or Shutdown is
+called; shutdown closes the broker so SSE and WebSocket clients disconnect,
+drains in-flight requests, stops the background loops, and waits for the final
+state flush. Hosted tenants' loops run and stop with the main server's.
+*/
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// lifecycle tracks what Shutdown has to stop.
+type lifecycle struct {
+	mu         sync.Mutex
+	httpServer *http.Server
+	cancel     context.CancelFunc
+	background sync.WaitGroup
+	once       sync.Once
+	done       chan struct{}
+	err        error
+}
+
+// Start serves the API on port and runs the background loops until ctx ends
+// or Shutdown is called. When ctx ends, Start shuts down within
+// shutdownTimeout and returns the shutdown error.
+func (s *Server) Start(ctx context.Context, port string) error {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	httpServer := &http.Server{Addr: ":" + port, Handler: traced(withRequestID(s.routes()))}
+
+	s.life.mu.Lock()
+	if s.life.done != nil {
+		s.life.mu.Unlock()
+		cancel()
+		return errors.New("server already started")
+	}
+	s.life.httpServer = httpServer
+	s.life.cancel = cancel
+	s.life.done = make(chan struct{})
+	s.life.mu.Unlock()
+
+	s.runLoops(runCtx)
+	for _, name := range s.tenantNames() {
+		t := s.tenants[name]
+		t.runLoops(runCtx)
+		s.logger.InfoContext(ctx, "tenant active", "tenant", name, "domain", t.about.Tenant, "mode", t.currentMode())
+	}
+
+	s.logStartup()
+	errChan := make(chan error, 1)
+	go func() { errChan <- httpServer.ListenAndServe() }()
+	s.logger.InfoContext(ctx, "axis server active", "port", port, "sse", true, "websocket", true)
+
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, http.ErrServerClosed) {
+			// The listener never came up; stop the loops started above.
+			s.Shutdown(context.WithoutCancel(ctx))
+			return err
+		}
+		// Shutdown was called directly; report its outcome once it finishes.
+		<-s.life.done
+		return s.life.err
+	case <-ctx.Done():
+		shutdownCtx, done := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer done()
+		return s.Shutdown(shutdownCtx)
+	}
+}
+
+// Shutdown stops a started server. Stream clients are disconnected first so the
+// HTTP drain is not held open by them; background loops then stop and the final
+// state flush completes before Shutdown returns. Calls after the first wait for
+// and return the same result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.life.mu.Lock()
+	httpServer, cancel, done := s.life.httpServer, s.life.cancel, s.life.done
+	s.life.mu.Unlock()
+	if done == nil {
+		return errors.New("server not started")
+	}
+
+	s.life.once.Do(func() {
+		go func() {
+			defer close(done)
+			s.logger.InfoContext(ctx, "axis server shutting down")
+			s.hub.Close()
+			for _, t := range s.tenants {
+				t.hub.Close()
+			}
+			err := httpServer.Shutdown(ctx)
+			cancel()
+			s.life.background.Wait()
+			for _, t := range s.tenants {
+				t.life.background.Wait()
+			}
+			s.life.err = err
+			s.logger.InfoContext(ctx, "axis server stopped", "error", err)
+		}()
+	})
+
+	select {
+	case <-done:
+		return s.life.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runLoops starts the background loops, tracked for Shutdown.
+func (s *Server) runLoops(ctx context.Context) {
+	s.goBackground(ctx, s.runPersistence)
+	s.goBackground(ctx, s.runPoller)
+	s.goBackground(ctx, s.events.Run)
+	s.goBackground(ctx, s.runLinkScanner)
+	s.goBackground(ctx, s.runUserRules)
+	s.goBackground(ctx, s.runActivityRefresh)
+	s.goBackground(ctx, s.runStorageRefresh)
+	s.goBackground(ctx, s.runCredentialCheck)
+	s.goBackground(ctx, s.runTokenWatchdog)
+	s.goBackground(ctx, s.runJournalPruner)
+	s.goBackground(ctx, s.runSnapshots)
+	s.goBackground(ctx, s.runCleanupDoc)
+	s.goBackground(ctx, s.runReports)
+	s.goBackground(ctx, s.runFederation)
+	s.goBackground(ctx, s.runIndexer)
+	s.goBackground(ctx, s.runIndexContent)
+	s.goBackground(ctx, s.jobs.Run)
+	if s.cluster != nil {
+		s.goBackground(ctx, func(ctx context.Context) { s.cluster.Run(ctx, s.applyRelayed) })
+	}
+}
+
+// goBackground runs a tracked loop that Shutdown waits for.
+func (s *Server) goBackground(ctx context.Context, run func(context.Context)) {
+	s.life.background.Add(1)
+	go func() {
+		defer s.life.background.Done()
+		run(ctx)
+	}()
+}
@@ -0,0 +1,192 @@
+/*
+File: internal/server/links.go
+Description: Link health scanning for Docs and Keep content. A scan extracts URLs,
+checks them through the shared rate-limited checker, and flags items whose links
+are mostly dead; results are served at /api/links and mark registry items.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"axis/internal/linkcheck"
+	"axis/internal/workspace"
+)
+
+const (
+	linkScanTimeout = 30 * time.Minute
+	// deadLinkThreshold is the dead-link share above which an item is flagged.
+	deadLinkThreshold = 0.5
+)
+
+// LinkReport summarizes link health for one item.
+type LinkReport struct {
+	ItemID     string             `json:"item_id"`
+	Type       string             `json:"type"`
+	Title      string             `json:"title"`
+	Links      []linkcheck.Result `json:"links"`
+	Dead       int                `json:"dead"`
+	Redirected int                `json:"redirected"`
+	DeadRatio  float64            `json:"dead_ratio"`
+	Flagged    bool               `json:"flagged"`
+}
+
+// WithLinkScanInterval scans links periodically in addition to on demand.
+func WithLinkScanInterval(d time.Duration) Option {
+	return func(s *Server) { s.linkScanInterval = d }
+}
+
+func (s *Server) runLinkScanner(ctx context.Context) {
+	if s.linkScanInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.linkScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.IsLeader() {
+				s.scanLinks(ctx)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// scanLinks checks every Keep note and Doc; overlapping scans are skipped.
+func (s *Server) scanLinks(parent context.Context) {
+	if !s.linkScanning.CompareAndSwap(false, true) {
+		return
+	}
+	defer s.linkScanning.Store(false)
+
+	ctx, cancel := context.WithTimeout(parent, linkScanTimeout)
+	defer cancel()
+	start := time.Now()
+
+	reports := make(map[string]LinkReport)
+	notes, err := s.ws.ListAllKeepNotes(ctx, workspace.ListNotesOptions{Filter: "trashed = false"})
+	if err != nil {
+		s.logger.Error("link scan: note listing failed", "error", err)
+	}
+	for _, note := range notes {
+		urls := linkcheck.ExtractURLs(workspace.NoteText(note.Body))
+		if rep, ok := s.checkLinks(ctx, note.Name, "keep", note.Title, urls); ok {
+			reports[note.Name] = rep
+		}
+	}
+
+	items, _ := s.RegistrySnapshot(ctx)
+	for _, item := range items {
+		if item.Type != "doc" || ctx.Err() != nil {
+			continue
+		}
+		doc, err := s.ws.GetDoc(ctx, item.ID)
+		if err != nil {
+			s.logger.Error("link scan: doc fetch failed", "id", item.ID, "error", err)
+			continue
+		}
+		urls := append(workspace.DocLinks(doc), linkcheck.ExtractURLs(workspace.DocRawText(doc))...)
+		if rep, ok := s.checkLinks(ctx, item.ID, "doc", item.Title, dedupe(urls)); ok {
+			reports[item.ID] = rep
+		}
+	}
+
+	flagged := 0
+	for _, rep := range reports {
+		if rep.Flagged {
+			flagged++
+		}
+	}
+	s.linksMu.Lock()
+	s.linkReports = reports
+	s.linksScannedAt = time.Now().UTC()
+	s.linksMu.Unlock()
+	s.logger.Info("link scan complete", "items", len(reports), "flagged", flagged, "duration", time.Since(start))
+}
+
+func (s *Server) checkLinks(ctx context.Context, id, itemType, title string, urls []string) (LinkReport, bool) {
+	if len(urls) == 0 {
+		return LinkReport{}, false
+	}
+	rep := LinkReport{ItemID: id, Type: itemType, Title: title}
+	for _, u := range urls {
+		res := s.linkChecker.Check(ctx, u)
+		rep.Links = append(rep.Links, res)
+		if res.Dead {
+			rep.Dead++
+		}
+		if res.Redirected {
+			rep.Redirected++
+		}
+	}
+	rep.DeadRatio = float64(rep.Dead) / float64(len(rep.Links))
+	rep.Flagged = rep.DeadRatio > deadLinkThreshold
+	return rep, true
+}
+
+// linkRotted reports whether the last scan flagged the item.
+func (s *Server) linkRotted(id string) bool {
+	s.linksMu.RLock()
+	defer s.linksMu.RUnlock()
+	return s.linkReports[id].Flagged
+}
+
+func (s *Server) handleLinks(w http.ResponseWriter, r *http.Request) {
+	onlyFlagged := truthyParam(r.URL.Query().Get("flagged"))
+
+	s.linksMu.RLock()
+	reports := make([]LinkReport, 0, len(s.linkReports))
+	for _, rep := range s.linkReports {
+		if onlyFlagged && !rep.Flagged {
+			continue
+		}
+		reports = append(reports, rep)
+	}
+	scannedAt := s.linksScannedAt
+	s.linksMu.RUnlock()
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].DeadRatio != reports[j].DeadRatio {
+			return reports[i].DeadRatio > reports[j].DeadRatio
+		}
+		return reports[i].ItemID < reports[j].ItemID
+	})
+
+	resp := struct {
+		ScannedAt *time.Time   `json:"scanned_at,omitempty"`
+		Running   bool         `json:"running"`
+		Items     []LinkReport `json:"items"`
+	}{Running: s.linkScanning.Load(), Items: reports}
+	if !scannedAt.IsZero() {
+		resp.ScannedAt = &scannedAt
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) handleLinkScan(w http.ResponseWriter, r *http.Request) {
+	if s.linkScanning.Load() {
+		apiError(w, "scan already running", http.StatusConflict)
+		return
+	}
+	go s.scanLinks(context.Background())
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+/*
+File: internal/server/listitems.go
+Description: Checklist item endpoints. GET /api/notes/list-items?id= lists a
+list note's items with their paths; PATCH checks, unchecks, adds and removes
+items. Keep cannot update a note in place, so an edit replaces the note with
+a new one, and Axis moves the note's status, tags and scheduled deletion to
+the new name.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// maxListEditBody bounds a PATCH /api/notes/list-items body.
+const maxListEditBody = 1 << 20
+
+// ListItemsResponse is the body of GET /api/notes/list-items.
+type ListItemsResponse struct {
+	Note  string               `json:"note"`
+	Title string               `json:"title"`
+	Items []workspace.ListItem `json:"items"`
+}
+
+// ListEditRequest is the body of PATCH /api/notes/list-items.
+type ListEditRequest struct {
+	Edits []workspace.ListItemEdit `json:"edits"`
+}
+
+func (s *Server) handleListItems(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	note, err := s.wsFor(r.Context()).GetNote(r.Context(), id)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	items, err := workspace.NoteListItems(note)
+	if err != nil {
+		writeAPIError(w, err, http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ListItemsResponse{Note: note.Name, Title: note.Title, Items: items})
+}
+
+func (s *Server) handleListEdit(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	var req ListEditRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxListEditBody)).Decode(&req); err != nil {
+		apiError(w, "invalid edits: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := workspace.ValidateListItemEdits(req.Edits); err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	item := s.registryItem(id, "keep")
+	if err := s.checkProtected(item); err != nil {
+		writeAPIError(w, err, deleteErrorStatus(err))
+		return
+	}
+	actor, mode := actorFrom(r.Context()), s.currentMode()
+	ops := make([]string, len(req.Edits))
+	for i, e := range req.Edits {
+		ops[i] = e.Op
+	}
+	data := map[string]any{"title": item.Title, "edits": ops, "mode": mode}
+
+	switch mode {
+	case "SIMULATE":
+		s.logger.InfoContext(r.Context(), "would update note list", "id", id, "edits", len(req.Edits), "actor", actor)
+		s.events.Emit(events.Event{Type: events.TypeNoteWouldUpdate, Actor: actor, Subject: id, Data: data})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DocEditSimulated{Simulated: true, Edits: len(req.Edits)})
+		return
+	case "AIRGAP":
+		apiError(w, "editing notes is not possible in AIRGAP mode", http.StatusConflict)
+		return
+	}
+
+	res, err := s.ws.EditListNote(r.Context(), id, req.Edits)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, workspace.ErrNotListNote), errors.Is(err, workspace.ErrNoteNotEditable):
+			status = http.StatusConflict
+		case errors.Is(err, workspace.ErrListItemNotFound):
+			status = http.StatusUnprocessableEntity
+		}
+		writeAPIError(w, err, status)
+		return
+	}
+	s.moveItemState(res.Replaced, res.Note)
+
+	s.logger.InfoContext(r.Context(), "note list updated", "id", res.Replaced, "note", res.Note, "edits", len(req.Edits), "actor", actor)
+	data["replaced"] = res.Replaced
+	s.events.Emit(events.Event{Type: events.TypeNoteUpdated, Actor: actor, Subject: res.Note, Data: data})
+	s.refreshAndBroadcast()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// moveItemState carries an item's status, tags and scheduled deletion over to
+// the item that replaced it.
+func (s *Server) moveItemState(from, to string) {
+	s.updateStatuses(func(next statusSet) bool {
+		status, ok := next[from]
+		if ok {
+			delete(next, from)
+			next[to] = status
+		}
+		return ok
+	})
+	if tags := s.currentTags()[from]; len(tags) > 0 {
+		s.setTags(to, tags)
+		s.setTags(from, nil)
+	}
+	if at, ok := s.currentDeadlines()[from]; ok {
+		s.setDeadline(to, at)
+		s.setDeadline(from, time.Time{})
+	}
+	s.triggerStateSnapshot()
+}
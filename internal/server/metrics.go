@@ -0,0 +1,63 @@
+/*
+File: internal/server/metrics.go
+Description: Prometheus text exposition of Google API quota partitions,
+per-API call and retry counts, and enrichment stage timings. Each authenticated
+principal's requests are attributed to its own partition so a background sweep
+cannot starve interactive callers.
+*/
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"axis/internal/quota"
+)
+
+// WithQuota exposes the Google API transport's limiter and retry statistics.
+func WithQuota(t *quota.Transport) Option {
+	return func(s *Server) { s.quota = t }
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	var stats []quota.Stats
+	if s.quota != nil {
+		stats = s.quota.Limiter.Stats()
+	}
+
+	fmt.Fprintln(w, "# HELP axis_api_requests_total Google API requests admitted by the rate limiter.")
+	fmt.Fprintln(w, "# TYPE axis_api_requests_total counter")
+	for _, st := range stats {
+		fmt.Fprintf(w, "axis_api_requests_total{partition=%q} %d\n", st.Partition, st.Granted)
+	}
+	fmt.Fprintln(w, "# HELP axis_api_cancelled_total Google API requests abandoned while waiting for quota.")
+	fmt.Fprintln(w, "# TYPE axis_api_cancelled_total counter")
+	for _, st := range stats {
+		fmt.Fprintf(w, "axis_api_cancelled_total{partition=%q} %d\n", st.Partition, st.Cancelled)
+	}
+	fmt.Fprintln(w, "# HELP axis_api_wait_seconds_total Time admitted requests spent waiting for quota.")
+	fmt.Fprintln(w, "# TYPE axis_api_wait_seconds_total counter")
+	for _, st := range stats {
+		fmt.Fprintf(w, "axis_api_wait_seconds_total{partition=%q} %g\n", st.Partition, st.WaitTotal.Seconds())
+	}
+	fmt.Fprintln(w, "# HELP axis_api_waiting Google API requests currently queued for quota.")
+	fmt.Fprintln(w, "# TYPE axis_api_waiting gauge")
+	for _, st := range stats {
+		fmt.Fprintf(w, "axis_api_waiting{partition=%q} %d\n", st.Partition, st.Waiting)
+	}
+
+	apis := s.quota.Stats()
+	fmt.Fprintln(w, "# HELP axis_api_calls_total Google API requests sent, including retries.")
+	fmt.Fprintln(w, "# TYPE axis_api_calls_total counter")
+	for _, st := range apis {
+		fmt.Fprintf(w, "axis_api_calls_total{api=%q} %d\n", st.API, st.Calls)
+	}
+	fmt.Fprintln(w, "# HELP axis_api_retries_total Google API requests retried after rate limiting or transient failures.")
+	fmt.Fprintln(w, "# TYPE axis_api_retries_total counter")
+	for _, st := range apis {
+		fmt.Fprintf(w, "axis_api_retries_total{api=%q} %d\n", st.API, st.Retries)
+	}
+
+	s.writeEnricherMetrics(w)
+}
@@ -0,0 +1,54 @@
+/*
+File: internal/server/notelog.go
+Description: Optional tracking spreadsheet for notes. Every deleted Keep note is
+appended as a row in the background, and the log_to_sheet playbook action logs
+the notes it selects; sheet errors are logged and never fail the deletion.
+*/
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+const noteLogTimeout = 30 * time.Second
+
+var errNoNoteLog = errors.New("no note log sheet configured (set AXIS_NOTE_LOG_SHEET or the step's sheet)")
+
+// WithNoteLog appends deleted notes to the spreadsheet's a1Range (the first
+// tab's A:D when empty).
+func WithNoteLog(spreadsheetID, a1Range string) Option {
+	return func(s *Server) {
+		s.noteLogSheet = spreadsheetID
+		s.noteLogRange = a1Range
+	}
+}
+
+// logNote appends item to spreadsheetID, or the configured sheet when empty.
+func (s *Server) logNote(ctx context.Context, item workspace.RegistryItem, spreadsheetID, action string) error {
+	a1Range := ""
+	if spreadsheetID == "" {
+		spreadsheetID, a1Range = s.noteLogSheet, s.noteLogRange
+	}
+	if spreadsheetID == "" {
+		return errNoNoteLog
+	}
+	return s.ws.AppendNoteToSheet(ctx, spreadsheetID, a1Range, item, action, time.Now())
+}
+
+// logDeletedNote records a deleted note without holding up the caller.
+func (s *Server) logDeletedNote(item workspace.RegistryItem) {
+	if s.noteLogSheet == "" || item.Type != "keep" {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), noteLogTimeout)
+		defer cancel()
+		if err := s.logNote(ctx, item, "", "deleted"); err != nil {
+			s.logger.Error("note log failed", "id", item.ID, "error", err)
+		}
+	}()
+}
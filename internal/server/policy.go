@@ -0,0 +1,87 @@
+/*
+File: internal/server/policy.go
+Description: Sheet-backed policy integration. Reloads the policy spreadsheet on every
+registry cycle, keeps the last valid copy when a read fails, and exposes it at
+/api/policy. Protected items are refused by the central delete path.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"axis/internal/sheetconfig"
+	"axis/internal/workspace"
+)
+
+var errItemProtected = errors.New("item is protected by policy")
+
+// WithPolicySheet enables the spreadsheet policy source.
+func WithPolicySheet(l *sheetconfig.Loader) Option {
+	return func(s *Server) { s.policyLoader = l }
+}
+
+// reloadPolicy refreshes the policy; on failure the previous policy stays active.
+func (s *Server) reloadPolicy() {
+	if s.policyLoader == nil {
+		return
+	}
+	p, err := s.policyLoader.Load()
+	if err != nil {
+		s.logger.Error("policy sheet load failed, keeping previous policy", "error", err)
+		return
+	}
+	s.policy.Store(p)
+	if len(p.Problems) > 0 {
+		s.logger.Warn("policy sheet has invalid rows", "problems", len(p.Problems))
+	}
+	s.logger.Info("policy loaded", "protected", len(p.Protected), "users", len(p.Users), "campaigns", len(p.Campaigns))
+}
+
+// checkProtected refuses destructive actions on protected items.
+func (s *Server) checkProtected(item workspace.RegistryItem) error {
+	if reason, ok := s.policy.Load().IsProtected(item.ID); ok {
+		return fmt.Errorf("%w: %s", errItemProtected, reason)
+	}
+	return nil
+}
+
+// applyPolicy annotates an item with its protection and campaign assignment.
+func (s *Server) applyPolicy(item *workspace.RegistryItem) {
+	p := s.policy.Load()
+	if p == nil {
+		return
+	}
+	_, item.Protected = p.Protected[item.ID]
+	item.Campaign = p.Campaigns[item.ID]
+}
+
+func (s *Server) handlePolicy(w http.ResponseWriter, r *http.Request) {
+	if s.policyLoader == nil {
+		apiError(w, "policy sheet not configured", http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodPost || r.URL.Query().Has("reload") {
+		s.reloadPolicy()
+	}
+	p := s.policy.Load()
+	if p == nil {
+		apiError(w, "policy not loaded yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		SpreadsheetID string `json:"spreadsheet_id"`
+		*sheetconfig.Policy
+	}{s.policyLoader.SpreadsheetID(), p})
+}
+
+// deleteErrorStatus maps delete failures to HTTP status codes.
+func deleteErrorStatus(err error) int {
+	if errors.Is(err, errItemProtected) || errors.Is(err, errBudgetExhausted) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
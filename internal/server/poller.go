@@ -0,0 +1,203 @@
+/*
+File: internal/server/poller.go
+Description: The automation loop and its controls. Every pollInterval the
+leader executes due deletions and archives in AUTO mode, sends countdowns, and
+refreshes the item types whose schedule is due. Operators can pause the loop
+(scheduled work stops at the next tick; countdowns report paused), resume it,
+or trigger an immediate full refresh through /api/poller. Pausing lasts until
+resumed or the server restarts and is relayed to every cluster instance.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+)
+
+const relayPoller = "state.poller"
+
+// poller is the loop's control surface. trigger holds at most one waiting
+// refresh request, so triggers made while one is pending coalesce.
+type poller struct {
+	trigger chan struct{}
+
+	mu       sync.Mutex
+	paused   bool
+	pausedAt time.Time
+	pausedBy string
+}
+
+func newPoller() *poller {
+	return &poller{trigger: make(chan struct{}, 1)}
+}
+
+// isPaused reports whether scheduled work is held.
+func (p *poller) isPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// setPaused pauses or resumes the loop and reports whether that changed it.
+func (p *poller) setPaused(paused bool, actor string, at time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused == paused {
+		return false
+	}
+	p.paused, p.pausedAt, p.pausedBy = paused, at, actor
+	if !paused {
+		p.pausedAt, p.pausedBy = time.Time{}, ""
+	}
+	return true
+}
+
+// requestRefresh queues an immediate refresh and reports whether one was
+// already waiting.
+func (p *poller) requestRefresh() (pending bool) {
+	select {
+	case p.trigger <- struct{}{}:
+		return false
+	default:
+		return true
+	}
+}
+
+// PollerResponse is the body of the /api/poller endpoints. NextRefresh is the
+// number of seconds until the next scheduled refresh, while one is scheduled.
+type PollerResponse struct {
+	Paused      bool         `json:"paused"`
+	PausedAt    *time.Time   `json:"paused_at,omitempty"`
+	PausedBy    string       `json:"paused_by,omitempty"`
+	Mode        string       `json:"mode"`
+	Leader      bool         `json:"leader"`
+	NextRefresh *int         `json:"next_refresh,omitempty"`
+	Pending     bool         `json:"pending,omitempty"` // a triggered refresh is waiting to run
+	LastPrune   *PruneReport `json:"last_prune,omitempty"`
+}
+
+func (s *Server) pollerState() PollerResponse {
+	s.poller.mu.Lock()
+	resp := PollerResponse{Paused: s.poller.paused, PausedBy: s.poller.pausedBy}
+	if s.poller.paused {
+		at := s.poller.pausedAt
+		resp.PausedAt = &at
+	}
+	s.poller.mu.Unlock()
+	resp.Mode, resp.Leader = s.currentMode(), s.IsLeader()
+	resp.Pending = len(s.poller.trigger) > 0
+	resp.LastPrune = s.statusGC.lastPrune()
+	if !resp.Paused && resp.Leader && (resp.Mode == "AUTO" || resp.Mode == "AIRGAP") {
+		next := int(s.schedule.Until(time.Now()).Round(time.Second) / time.Second)
+		resp.NextRefresh = &next
+	}
+	return resp
+}
+
+func (s *Server) runPoller(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	countdown := false // whether the last countdown listed any item
+	for {
+		select {
+		case now := <-ticker.C:
+			mode, paused := s.currentMode(), s.poller.isPaused()
+			if s.IsLeader() {
+				if mode == "AUTO" && !paused {
+					s.executeDeadlines(ctx, now)
+					s.executeArchives(ctx, now)
+				}
+				countdown = s.broadcastCountdown(now, countdown)
+			}
+
+			// Followers receive the leader's ticks and registry relays.
+			// AIRGAP scans on schedule too; it only proposes changes.
+			if (mode == "AUTO" || mode == "AIRGAP") && s.IsLeader() && !paused {
+				if due := s.schedule.Due(now); len(due) > 0 {
+					s.refreshRegistryCache(due...)
+					s.broadcastRegistry()
+				}
+				s.broadcastTick(int(s.schedule.Until(now).Round(time.Second) / time.Second))
+			} else {
+				s.schedule.Reset(now)
+			}
+		case <-s.poller.trigger:
+			// A triggered refresh lists every type, in any mode and paused or
+			// not, and restarts the schedule from now.
+			s.refreshRegistryCache()
+			s.broadcastRegistry()
+			s.schedule.Reset(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pausePoller pauses or resumes the loop, emitting the change. Other
+// instances apply it from the relay.
+func (s *Server) pausePoller(ctx context.Context, paused bool) {
+	actor := actorFrom(ctx)
+	if !s.poller.setPaused(paused, actor, time.Now()) {
+		return
+	}
+	typ, msg := events.TypePollerResumed, "poller resumed"
+	if paused {
+		typ, msg = events.TypePollerPaused, "poller paused"
+	}
+	s.logger.InfoContext(ctx, msg, "actor", actor)
+	s.events.Emit(events.Event{Type: typ, Actor: actor, Data: map[string]any{"mode": s.currentMode()}})
+	s.relayJSON(relayPoller, s.pollerState())
+}
+
+// applyRelayedPoller takes another instance's pause state.
+func (s *Server) applyRelayedPoller(data []byte) {
+	var p PollerResponse
+	if json.Unmarshal(data, &p) != nil {
+		return
+	}
+	at := time.Now()
+	if p.PausedAt != nil {
+		at = *p.PausedAt
+	}
+	s.poller.setPaused(p.Paused, p.PausedBy, at)
+}
+
+func (s *Server) writePollerState(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(s.pollerState())
+}
+
+// handlePoller serves GET /api/poller.
+func (s *Server) handlePoller(w http.ResponseWriter, r *http.Request) {
+	s.writePollerState(w, http.StatusOK)
+}
+
+// handlePollerPause serves POST /api/poller/pause.
+func (s *Server) handlePollerPause(w http.ResponseWriter, r *http.Request) {
+	s.pausePoller(r.Context(), true)
+	s.writePollerState(w, http.StatusOK)
+}
+
+// handlePollerResume serves POST /api/poller/resume.
+func (s *Server) handlePollerResume(w http.ResponseWriter, r *http.Request) {
+	s.pausePoller(r.Context(), false)
+	s.writePollerState(w, http.StatusOK)
+}
+
+// handlePollerTrigger serves POST /api/poller/trigger. The refresh runs on
+// the loop, so the response is 202 as soon as it is queued.
+func (s *Server) handlePollerTrigger(w http.ResponseWriter, r *http.Request) {
+	actor := actorFrom(r.Context())
+	if !s.poller.requestRefresh() {
+		s.logger.InfoContext(r.Context(), "poller refresh triggered", "actor", actor)
+		s.events.Emit(events.Event{Type: events.TypePollerTriggered, Actor: actor, Data: map[string]any{"mode": s.currentMode()}})
+	}
+	s.writePollerState(w, http.StatusAccepted)
+}
@@ -0,0 +1,278 @@
+/*
+File: internal/server/registry.go
+Description: Incremental registry fetching and conditional, paginated responses.
+Item types are fetched concurrently and fail independently. Between full listings, Docs and Sheets are relisted only when the Drive changes
+feed reports a change of that type and only updated Keep notes are fetched.
+/api/registry carries an ETag so unchanged clients get 304 Not Modified, and
+pages with ?limit= and ?page_token=.
+*/
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/workspace"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// fullRelistEvery bounds how long a type goes without a full listing, which
+	// catches Keep notes deleted outright (they never show up as changes).
+	fullRelistEvery = 15 * time.Minute
+	// keepChangeOverlap absorbs clock skew between Axis and Keep; re-fetching a
+	// note that did not change is harmless.
+	keepChangeOverlap = time.Minute
+)
+
+// fetchItems updates byType for the given types and reports what was fetched
+// ("keep~" marks an incremental Keep fetch; unchanged types are left out).
+// A full fetch lists every given type and resets the change tracking. Types
+// are fetched concurrently; a type that fails keeps its entry in byType and
+// is reported in the returned map instead.
+func (s *Server) fetchItems(types []string, byType map[string][]workspace.RegistryItem, full bool) ([]string, map[string]error) {
+	ctx := context.Background()
+	start := time.Now()
+	// Disabled types are never listed, so their scopes need not be granted.
+	types = slices.DeleteFunc(slices.Clone(types), func(t string) bool { return !s.ws.Enabled(t) })
+
+	s.registryCache.mu.RLock()
+	driveToken := s.registryCache.driveToken
+	keepSince := s.registryCache.keepSince
+	listedAt := make(map[string]time.Time, len(s.registryCache.listedAt))
+	for t, at := range s.registryCache.listedAt {
+		listedAt[t] = at
+	}
+	dirty := make(map[string]bool, len(s.registryCache.driveDirty))
+	for t := range s.registryCache.driveDirty {
+		dirty[t] = true
+	}
+	s.registryCache.mu.RUnlock()
+
+	incremental := func(itemType string) bool {
+		return !full && start.Sub(listedAt[itemType]) < fullRelistEvery
+	}
+	wantsDrive := false
+	for _, t := range types {
+		wantsDrive = wantsDrive || t != "keep"
+	}
+
+	// Changes read from the Drive feed stay dirty until their type is relisted,
+	// so a refresh of one type does not swallow changes to another. Without a
+	// token, take a fresh one before listing so changes made meanwhile are seen
+	// on the next refresh.
+	tracking := driveToken != ""
+	if driveToken != "" && wantsDrive && !full {
+		if ch, err := s.ws.ListDriveChanges(ctx, driveToken); err != nil {
+			s.logger.Warn("drive changes unavailable; relisting", "error", err)
+			tracking = false
+		} else {
+			for t := range ch.Types {
+				dirty[t] = true
+			}
+			driveToken = ch.Token
+		}
+	}
+	if (full || !tracking) && wantsDrive {
+		if tok, err := s.ws.DriveStartToken(ctx); err != nil {
+			s.logger.Warn("drive change tracking unavailable", "error", err)
+			driveToken = ""
+		} else {
+			driveToken = tok
+		}
+	}
+
+	var (
+		fetched []string
+		failed  = make(map[string]error)
+		mu      sync.Mutex // guards the state above and byType, listedAt, dirty, keepSince
+		g       errgroup.Group
+	)
+	keepChanges := incremental("keep") && !keepSince.IsZero()
+	for _, itemType := range types {
+		if itemType == "keep" && keepChanges {
+			g.Go(func() error {
+				ch, err := s.ws.ListKeepChanges(ctx, keepSince)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					failed["keep"] = err
+					return nil
+				}
+				byType["keep"] = mergeItems(byType["keep"], ch.Updated, ch.Trashed)
+				keepSince = start.Add(-keepChangeOverlap)
+				fetched = append(fetched, "keep~")
+				return nil
+			})
+			continue
+		}
+		if itemType != "keep" && incremental(itemType) && tracking && !dirty[itemType] {
+			continue
+		}
+		g.Go(func() error {
+			list, err := s.ws.ListItems(ctx, itemType)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[itemType] = err
+				return nil
+			}
+			byType[itemType] = list
+			listedAt[itemType] = start
+			delete(dirty, itemType)
+			if itemType == "keep" {
+				keepSince = start.Add(-keepChangeOverlap)
+			}
+			fetched = append(fetched, itemType)
+			return nil
+		})
+	}
+	g.Wait()
+	sort.Strings(fetched)
+
+	s.registryCache.mu.Lock()
+	s.registryCache.driveToken = driveToken
+	s.registryCache.driveDirty = dirty
+	s.registryCache.keepSince = keepSince
+	s.registryCache.listedAt = listedAt
+	s.registryCache.mu.Unlock()
+	return fetched, failed
+}
+
+// mergeItems replaces or appends updated items and drops removed IDs, keeping
+// the existing order.
+func mergeItems(items, updated []workspace.RegistryItem, removed []string) []workspace.RegistryItem {
+	if len(updated) == 0 && len(removed) == 0 {
+		return items
+	}
+	drop := make(map[string]bool, len(removed))
+	for _, id := range removed {
+		drop[id] = true
+	}
+	pending := make(map[string]workspace.RegistryItem, len(updated))
+	for _, item := range updated {
+		pending[item.ID] = item
+	}
+	merged := make([]workspace.RegistryItem, 0, len(items)+len(updated))
+	for _, item := range items {
+		if drop[item.ID] {
+			continue
+		}
+		if u, ok := pending[item.ID]; ok {
+			item = u
+			delete(pending, item.ID)
+		}
+		merged = append(merged, item)
+	}
+	for _, item := range updated {
+		if _, ok := pending[item.ID]; ok {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// paginate cuts one page out of items for ?limit= and ?page_token= (or a
+// plain ?offset=) and returns the token of the following page, or "" on the
+// last. Without a limit every item is returned. Tokens are opaque offsets into
+// the listing.
+func paginate[T any](r *http.Request, items []T) ([]T, string, error) {
+	q := r.URL.Query()
+	offset := 0
+	if raw := q.Get("page_token"); raw != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(raw)
+		if err == nil {
+			offset, err = strconv.Atoi(string(decoded))
+		}
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid page_token")
+		}
+	} else if raw := q.Get("offset"); raw != "" {
+		var err error
+		if offset, err = strconv.Atoi(raw); err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid offset")
+		}
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	raw := q.Get("limit")
+	if raw == "" {
+		return items, "", nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return nil, "", fmt.Errorf("invalid limit")
+	}
+	if limit >= len(items) {
+		return items, "", nil
+	}
+	next := base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset + limit)))
+	return items[:limit], next, nil
+}
+
+// writeRegistryPage writes one page of the registry. The total count and the
+// next page's token and link travel in headers so the body stays an array.
+func writeRegistryPage(w http.ResponseWriter, r *http.Request, items []workspace.RegistryItem) {
+	page, next, err := paginate(r, items)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+	if next != "" {
+		u := *r.URL
+		q := u.Query()
+		q.Set("page_token", next)
+		q.Del("refresh")
+		u.RawQuery = q.Encode()
+		w.Header().Set("X-Next-Page-Token", next)
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", u.RequestURI()))
+	}
+	writeRegistry(w, r, page)
+}
+
+// writeRegistry writes the enriched registry with a content ETag, answering
+// 304 when the client's If-None-Match already matches.
+func writeRegistry(w http.ResponseWriter, r *http.Request, items []workspace.RegistryItem) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(items); err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// etagMatch implements the weak comparison If-None-Match uses.
+func etagMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+/*
+File: internal/server/registrydiff.go
+Description: Differential registry broadcasts. Each registry cycle is compared
+with what clients were last sent, and only the items added, updated or removed
+go out, as item-added, item-updated and item-removed events. A full registry
+snapshot still goes out every registryResync so clients that missed an event
+converge, and whenever the changes outweigh the listing. A client that connects
+is sent the listing the baseline describes, taken under the same lock the
+broadcasts go out under, so no diff can precede the snapshot it applies to.
+*/
+package server
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/workspace"
+)
+
+// registryResync is the longest clients go without a full snapshot.
+const registryResync = 5 * time.Minute
+
+// registryBaseline is the registry as clients last saw it: each item's
+// encoding by ID, the listing and its encoding as a registry event carries it,
+// and when the last full snapshot went out.
+type registryBaseline struct {
+	items  map[string]string
+	list   []workspace.RegistryItem
+	data   []byte
+	fullAt time.Time
+}
+
+// registryEventsLocked returns the broadcasts that bring clients from the
+// baseline to items, which data encodes, and moves the baseline there. It is a
+// single registry snapshot when one is due, and nothing when no item changed.
+// The caller holds diffMu until the broadcasts are published.
+func (s *Server) registryEventsLocked(items []workspace.RegistryItem, data []byte, now time.Time) []broker.Event {
+	next := make(map[string]string, len(items))
+	var added, updated []workspace.RegistryItem
+	for _, item := range items {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		next[item.ID] = string(encoded)
+		prev, ok := s.diffBase.items[item.ID]
+		switch {
+		case !ok:
+			added = append(added, item)
+		case prev != string(encoded):
+			updated = append(updated, item)
+		}
+	}
+	var removed []string
+	for id := range s.diffBase.items {
+		if _, ok := next[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	slices.Sort(removed)
+
+	changes := len(added) + len(updated) + len(removed)
+	full := s.diffBase.items == nil || now.Sub(s.diffBase.fullAt) >= registryResync || 2*changes > len(items)
+	s.diffBase.items = next
+	s.diffBase.list, s.diffBase.data = items, data
+	if len(items) == 0 {
+		s.diffBase.data = nil
+	}
+	if full {
+		s.diffBase.fullAt = now
+		return []broker.Event{{Type: broker.TypeRegistry, Data: data}}
+	}
+	var out []broker.Event
+	for _, d := range []struct {
+		typ string
+		v   any
+		n   int
+	}{
+		{broker.TypeItemRemoved, removed, len(removed)},
+		{broker.TypeItemAdded, added, len(added)},
+		{broker.TypeItemUpdated, updated, len(updated)},
+	} {
+		if d.n == 0 {
+			continue
+		}
+		if encoded, err := json.Marshal(d.v); err == nil {
+			out = append(out, broker.Event{Type: d.typ, Data: encoded})
+		}
+	}
+	return out
+}
+
+// applyRegistryDelta folds a relayed item-added, item-updated or
+// item-removed broadcast into the cache and the baseline, as a relayed
+// snapshot replaces them, and passes it on to local clients.
+func (s *Server) applyRegistryDelta(typ string, data []byte) {
+	var updated []workspace.RegistryItem
+	var removed []string
+	if typ == broker.TypeItemRemoved {
+		if json.Unmarshal(data, &removed) != nil {
+			return
+		}
+	} else if json.Unmarshal(data, &updated) != nil {
+		return
+	}
+	s.registryCache.update(func(cur *registrySnapshot) *registrySnapshot {
+		if !cur.loaded {
+			return cur
+		}
+		next := *cur
+		next.items = mergeItems(cur.items, updated, removed)
+		return &next
+	})
+	s.diffMu.Lock()
+	defer s.diffMu.Unlock()
+	defer s.hub.Publish(broker.Event{Type: typ, Data: data})
+	if s.diffBase.items == nil {
+		return
+	}
+	for _, item := range updated {
+		if encoded, err := json.Marshal(item); err == nil {
+			s.diffBase.items[item.ID] = string(encoded)
+		}
+	}
+	for _, id := range removed {
+		delete(s.diffBase.items, id)
+	}
+	s.diffBase.list = mergeItems(s.diffBase.list, updated, removed)
+	s.diffBase.data = nil
+	if len(s.diffBase.list) > 0 {
+		s.diffBase.data, _ = json.Marshal(s.diffBase.list)
+	}
+}
+
+// resetRegistryBaseline makes the baseline a relayed snapshot, which data
+// encodes, and passes the snapshot on to local clients.
+func (s *Server) resetRegistryBaseline(items []workspace.RegistryItem, data []byte, at time.Time) {
+	base := make(map[string]string, len(items))
+	for _, item := range items {
+		if encoded, err := json.Marshal(item); err == nil {
+			base[item.ID] = string(encoded)
+		}
+	}
+	s.diffMu.Lock()
+	defer s.diffMu.Unlock()
+	s.diffBase = registryBaseline{items: base, list: items, data: data, fullAt: at}
+	s.hub.Publish(broker.Event{Type: broker.TypeRegistry, Data: data})
+}
+
+// subscribeClient subscribes a streaming client after event ID after. Along
+// with the backlog it returns the events that bring the client current: the
+// mode, failing token sources and, unless the backlog covers the whole gap,
+// the registry listing the subscription continues from and source health.
+func (s *Server) subscribeClient(after uint64) (sub *broker.Subscription, backlog []broker.Event, complete bool, initial []broker.Event) {
+	s.diffMu.Lock()
+	sub, backlog, complete = s.hub.SubscribeAfter(after)
+	listing := s.diffBase.data
+	s.diffMu.Unlock()
+
+	if data, err := json.Marshal(ModeResponse{Mode: s.currentMode()}); err == nil {
+		initial = append(initial, broker.Event{Type: broker.TypeMode, Data: data})
+	}
+	initial = append(initial, s.tokenHealthEvents()...)
+	if after != 0 && complete {
+		return sub, backlog, complete, initial
+	}
+	if listing == nil {
+		// Nothing has gone out yet; the first registry snapshot reaches this
+		// subscriber with everyone else.
+		go s.broadcastRegistry()
+		return sub, backlog, complete, initial
+	}
+	initial = append(initial, broker.Event{Type: broker.TypeRegistry, Data: listing})
+	if sources, err := json.Marshal(s.sources.list()); err == nil {
+		initial = append(initial, broker.Event{Type: broker.TypeSources, Data: sources})
+	}
+	return sub, backlog, complete, initial
+}
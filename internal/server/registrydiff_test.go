@@ -0,0 +1,113 @@
+/*
+File: internal/server/registrydiff_test.go
+Description: Clients that connect while registry diffs are going out converge
+on the last listing: the connect snapshot never arrives after a diff newer than
+it.
+*/
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/workspace"
+)
+
+// applyRegistryEvent folds one registry event into a client's view, as the
+// dashboard does.
+func applyRegistryEvent(t *testing.T, view map[string]string, e broker.Event) {
+	t.Helper()
+	switch e.Type {
+	case broker.TypeRegistry, broker.TypeItemAdded, broker.TypeItemUpdated:
+		var items []workspace.RegistryItem
+		if err := json.Unmarshal(e.Data, &items); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type == broker.TypeRegistry {
+			clear(view)
+		}
+		for _, item := range items {
+			view[item.ID] = item.Title
+		}
+	case broker.TypeItemRemoved:
+		var ids []string
+		if err := json.Unmarshal(e.Data, &ids); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range ids {
+			delete(view, id)
+		}
+	}
+}
+
+func TestConnectSnapshotPrecedesDiffs(t *testing.T) {
+	const cycles, clients = 300, 20
+	s := &Server{hub: broker.New(4*cycles, -1)}
+	s.mode.Store("AUTO")
+
+	listing := func(cycle int) []workspace.RegistryItem {
+		items := make([]workspace.RegistryItem, 5)
+		for i := range items {
+			items[i] = workspace.RegistryItem{ID: fmt.Sprint("item-", i), Type: "keep", Title: "v0"}
+		}
+		// One item changes per cycle, too few for a full snapshot.
+		items[cycle%len(items)].Title = fmt.Sprint("v", cycle)
+		return items
+	}
+	publish := func(cycle int) {
+		items := listing(cycle)
+		data, _ := json.Marshal(items)
+		s.diffMu.Lock()
+		for _, e := range s.registryEventsLocked(items, data, time.Now()) {
+			s.hub.Publish(e)
+		}
+		s.diffMu.Unlock()
+	}
+	publish(0)
+
+	var wg sync.WaitGroup
+	subs := make([]*broker.Subscription, clients)
+	initials := make([][]broker.Event, clients)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for cycle := 1; cycle <= cycles; cycle++ {
+			publish(cycle)
+		}
+	}()
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			subs[c], _, _, initials[c] = s.subscribeClient(0)
+		}()
+	}
+	wg.Wait()
+
+	want := make(map[string]string)
+	for _, item := range listing(cycles) {
+		want[item.ID] = item.Title
+	}
+	for c := range subs {
+		view := make(map[string]string)
+		for _, e := range initials[c] {
+			applyRegistryEvent(t, view, e)
+		}
+	drain:
+		for {
+			select {
+			case e := <-subs[c].C:
+				applyRegistryEvent(t, view, e)
+			default:
+				break drain
+			}
+		}
+		if fmt.Sprint(view) != fmt.Sprint(want) {
+			t.Fatalf("client %d ends at %v, want %v", c, view, want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+/*
+File: internal/server/registryquery.go
+Description: Registry queries. /api/registry filters and orders the cached
+inventory on the server (by type, status, last update, title, trash state and
+tags) before it is paginated, so clients fetch only the items they show.
+*/
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+// registrySortKeys compares registry items by a ?sort= field. Items without
+// the field's time or owner are put last before comparing.
+var registrySortKeys = map[string]func(a, b workspace.RegistryItem) int{
+	"title": func(a, b workspace.RegistryItem) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	},
+	"type":     func(a, b workspace.RegistryItem) int { return strings.Compare(a.Type, b.Type) },
+	"status":   func(a, b workspace.RegistryItem) int { return strings.Compare(a.Status, b.Status) },
+	"created":  func(a, b workspace.RegistryItem) int { return a.Created.Compare(*b.Created) },
+	"modified": func(a, b workspace.RegistryItem) int { return a.Modified.Compare(*b.Modified) },
+	"owner":    func(a, b workspace.RegistryItem) int { return strings.Compare(a.Owner, b.Owner) },
+	"size":     func(a, b workspace.RegistryItem) int { return cmp.Compare(a.Size, b.Size) },
+	"trashed":  func(a, b workspace.RegistryItem) int { return compareBools(a.Trashed, b.Trashed) },
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// registryQuery is the filter and order of one /api/registry request.
+type registryQuery struct {
+	types, statuses []string
+	updatedBefore   time.Time
+	titleContains   string
+	trashed         *bool
+	tags            []string // lowercased, without the tag namespace
+	sort            string
+	desc            bool
+}
+
+// parseRegistryQuery reads ?type=, ?status= and ?tag= (comma-separated, any
+// of), ?updated_before= (RFC 3339 or a date), ?title_contains=, ?trashed=, and
+// ?sort= with ?order=asc|desc or a leading - for descending; order wins over
+// the prefix.
+func parseRegistryQuery(r *http.Request) (registryQuery, error) {
+	q := r.URL.Query()
+	var rq registryQuery
+	for t := range strings.SplitSeq(q.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if !slices.Contains(workspace.ItemTypes, t) {
+			return rq, fmt.Errorf("invalid type %q (want %s)", t, strings.Join(workspace.ItemTypes, ", "))
+		}
+		rq.types = append(rq.types, t)
+	}
+	for st := range strings.SplitSeq(q.Get("status"), ",") {
+		if st = strings.TrimSpace(st); st != "" {
+			rq.statuses = append(rq.statuses, st)
+		}
+	}
+	if raw := q.Get("updated_before"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, raw); err != nil {
+				return rq, fmt.Errorf("invalid updated_before %q (want RFC 3339 or YYYY-MM-DD)", raw)
+			}
+		}
+		rq.updatedBefore = t
+	}
+	rq.titleContains = strings.ToLower(q.Get("title_contains"))
+	if raw := q.Get("trashed"); raw != "" {
+		trashed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return rq, fmt.Errorf("invalid trashed %q (want true or false)", raw)
+		}
+		rq.trashed = &trashed
+	}
+	for tag := range strings.SplitSeq(q.Get("tag"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), workspace.TagNamespace)
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			rq.tags = append(rq.tags, tag)
+		}
+	}
+
+	field, desc := strings.CutPrefix(q.Get("sort"), "-")
+	if field == "updated" {
+		field = "modified"
+	}
+	if _, ok := registrySortKeys[field]; field != "" && !ok {
+		return rq, fmt.Errorf("invalid sort %q (want title, type, status, created, modified, owner, size or trashed)", field)
+	}
+	switch order := q.Get("order"); order {
+	case "":
+	case "asc", "desc":
+		desc = order == "desc"
+	default:
+		return rq, fmt.Errorf("invalid order %q (want asc or desc)", order)
+	}
+	rq.sort, rq.desc = field, desc
+	return rq, nil
+}
+
+// apply returns the matching items in the query's order. Without a sort the
+// listing order is kept; with one, ties keep it, so pages stay consistent.
+func (rq registryQuery) apply(items []workspace.RegistryItem) []workspace.RegistryItem {
+	out := make([]workspace.RegistryItem, 0, len(items))
+	for _, item := range items {
+		if rq.matches(item) {
+			out = append(out, item)
+		}
+	}
+	compare := registrySortKeys[rq.sort]
+	if compare == nil {
+		return out
+	}
+	unknown := func(item workspace.RegistryItem) bool {
+		switch rq.sort {
+		case "created":
+			return item.Created == nil
+		case "modified":
+			return item.Modified == nil
+		case "owner":
+			return item.Owner == ""
+		}
+		return false
+	}
+	slices.SortStableFunc(out, func(a, b workspace.RegistryItem) int {
+		if ua, ub := unknown(a), unknown(b); ua || ub {
+			return compareBools(ua, ub)
+		}
+		if rq.desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return out
+}
+
+func (rq registryQuery) matches(item workspace.RegistryItem) bool {
+	if len(rq.types) > 0 && !slices.Contains(rq.types, item.Type) {
+		return false
+	}
+	if len(rq.statuses) > 0 && !slices.ContainsFunc(rq.statuses, func(st string) bool { return strings.EqualFold(st, item.Status) }) {
+		return false
+	}
+	// An item without an update time cannot be shown to predate the cutoff.
+	if !rq.updatedBefore.IsZero() && (item.Modified == nil || !item.Modified.Before(rq.updatedBefore)) {
+		return false
+	}
+	if rq.trashed != nil && item.Trashed != *rq.trashed {
+		return false
+	}
+	if len(rq.tags) > 0 && !slices.ContainsFunc(item.Tags, func(tag string) bool {
+		return slices.Contains(rq.tags, strings.ToLower(strings.TrimPrefix(tag, workspace.TagNamespace)))
+	}) {
+		return false
+	}
+	return rq.titleContains == "" || strings.Contains(strings.ToLower(item.Title), rq.titleContains)
+}
@@ -0,0 +1,64 @@
+/*
+File: internal/server/reminders.go
+Description: Optional Calendar sync for reminders extracted from Keep notes. Each
+registry cycle creates events for reminders that are still in the future;
+deterministic event IDs make the sync idempotent across restarts.
+*/
+package server
+
+import (
+	"context"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+const reminderSyncTimeout = time.Minute
+
+// WithReminderCalendar creates Calendar events for note reminders in calendarID.
+func WithReminderCalendar(calendarID string) Option {
+	return func(s *Server) { s.reminderCalendar = calendarID }
+}
+
+// syncReminders runs in the background; overlapping cycles are skipped.
+func (s *Server) syncReminders(items []workspace.RegistryItem) {
+	if s.reminderCalendar == "" || !s.IsLeader() || !s.reminderSyncing.CompareAndSwap(false, true) {
+		return
+	}
+	defer s.reminderSyncing.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), reminderSyncTimeout)
+	defer cancel()
+
+	now := time.Now()
+	created := 0
+	for _, item := range items {
+		for _, r := range item.Reminders {
+			if r.Due == nil || r.Due.Before(now) {
+				continue
+			}
+			id := workspace.ReminderEventID(item.ID, r.Text, *r.Due)
+			if s.syncedReminders[id] {
+				continue
+			}
+			ok, err := s.ws.CreateReminderEvent(ctx, s.reminderCalendar, workspace.ReminderEvent{
+				NoteID: item.ID,
+				Title:  sanitizeNoteTitle(item.Title),
+				Text:   r.Text,
+				Due:    *r.Due,
+				AllDay: r.Due.Hour() == 0 && r.Due.Minute() == 0,
+			})
+			if err != nil {
+				s.logger.Error("reminder sync failed", "id", item.ID, "error", err)
+				continue
+			}
+			s.syncedReminders[id] = true
+			if ok {
+				created++
+			}
+		}
+	}
+	if created > 0 {
+		s.logger.Info("reminder events created", "count", created)
+	}
+}
@@ -0,0 +1,303 @@
+/*
+File: internal/server/reports.go
+Description: Summary reports. On a cron schedule the leader writes a Google Doc
+or Sheet into the configured Drive folder summarizing the registry: items by
+type and status, last week's deletions and the stalest items. With recipients
+configured the report is also mailed through Gmail with a link to the file.
+*/
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"axis/internal/cron"
+	"axis/internal/events"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const (
+	reportWindow   = 7 * 24 * time.Hour
+	reportStale    = 0.75 // staleness from which an item is listed as stale, about nine months untouched
+	reportMaxStale = 50
+	reportNoStatus = "(none)"
+)
+
+var errReportsDisabled = errors.New("no report folder configured (set AXIS_REPORT_FOLDER)")
+
+// ReportSummary is what a summary report holds.
+type ReportSummary struct {
+	Generated       time.Time                 `json:"generated"`
+	Items           int                       `json:"items"`
+	ByType          map[string]int            `json:"by_type"`
+	ByStatus        map[string]map[string]int `json:"by_status"` // type to status to count
+	Deletions       int                       `json:"deletions"`
+	DeletionsByType map[string]int            `json:"deletions_by_type"`
+	StaleCount      int                       `json:"stale_count"`
+	Stale           []workspace.RegistryItem  `json:"stale"` // stalest first, at most reportMaxStale
+}
+
+// ReportResponse describes a written summary report.
+type ReportResponse struct {
+	File       string        `json:"file"`
+	Format     string        `json:"format"`
+	Title      string        `json:"title"`
+	URL        string        `json:"url"`
+	Recipients []string      `json:"recipients,omitempty"`
+	MailError  string        `json:"mail_error,omitempty"`
+	Summary    ReportSummary `json:"summary"`
+}
+
+// WithReports writes summary reports as format ("doc" or "sheet") into the
+// Drive folder folderID, on schedule if it is not nil, and mails them to
+// recipients.
+func WithReports(folderID, format string, schedule *cron.Schedule, recipients []string) Option {
+	return func(s *Server) {
+		s.reportFolder = folderID
+		s.reportFormat = format
+		s.reportSchedule = schedule
+		s.reportRecipients = recipients
+	}
+}
+
+// reportSummary gathers the report from the enriched registry and the
+// deletion history.
+func (s *Server) reportSummary(ctx context.Context, now time.Time) (ReportSummary, error) {
+	items, err := s.RegistrySnapshot(ctx)
+	if err != nil {
+		return ReportSummary{}, err
+	}
+	deletions, err := s.store.ListDeletions(ctx, store.Query{Since: now.Add(-reportWindow)})
+	if err != nil {
+		return ReportSummary{}, err
+	}
+	sum := ReportSummary{
+		Generated:       now,
+		Items:           len(items),
+		ByType:          make(map[string]int),
+		ByStatus:        make(map[string]map[string]int),
+		DeletionsByType: make(map[string]int),
+		Stale:           []workspace.RegistryItem{},
+	}
+	for _, item := range items {
+		sum.ByType[item.Type]++
+		if sum.ByStatus[item.Type] == nil {
+			sum.ByStatus[item.Type] = make(map[string]int)
+		}
+		sum.ByStatus[item.Type][cmp.Or(item.Status, reportNoStatus)]++
+		if item.Staleness >= reportStale {
+			sum.Stale = append(sum.Stale, item)
+		}
+	}
+	sum.StaleCount = len(sum.Stale)
+	slices.SortStableFunc(sum.Stale, func(a, b workspace.RegistryItem) int { return cmp.Compare(b.Staleness, a.Staleness) })
+	if len(sum.Stale) > reportMaxStale {
+		sum.Stale = sum.Stale[:reportMaxStale]
+	}
+	for _, d := range deletions {
+		if d.Outcome == store.OutcomeDeleted {
+			sum.Deletions++
+			sum.DeletionsByType[d.ItemType]++
+		}
+	}
+	return sum, nil
+}
+
+// sortedKeys returns m's keys with the largest count first.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int { return cmp.Or(cmp.Compare(m[b], m[a]), strings.Compare(a, b)) })
+	return keys
+}
+
+// reportText renders the summary for a Doc and the mail body.
+func reportText(sum ReportSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Axis summary for %s\n", sum.Generated.UTC().Format("2006-01-02 15:04 UTC"))
+	fmt.Fprintf(&b, "\n%d items\n", sum.Items)
+	for _, typ := range sortedKeys(sum.ByType) {
+		fmt.Fprintf(&b, "- %s: %d", typ, sum.ByType[typ])
+		var parts []string
+		for _, status := range sortedKeys(sum.ByStatus[typ]) {
+			parts = append(parts, fmt.Sprintf("%s %d", status, sum.ByStatus[typ][status]))
+		}
+		fmt.Fprintf(&b, " (%s)\n", strings.Join(parts, ", "))
+	}
+	fmt.Fprintf(&b, "\n%d deletions in the last 7 days\n", sum.Deletions)
+	for _, typ := range sortedKeys(sum.DeletionsByType) {
+		fmt.Fprintf(&b, "- %s: %d\n", typ, sum.DeletionsByType[typ])
+	}
+	fmt.Fprintf(&b, "\n%d stale items\n", sum.StaleCount)
+	for _, item := range sum.Stale {
+		fmt.Fprintf(&b, "- %s\n", reviewEntry(item))
+	}
+	if sum.StaleCount > len(sum.Stale) {
+		fmt.Fprintf(&b, "- and %d more\n", sum.StaleCount-len(sum.Stale))
+	}
+	return b.String()
+}
+
+// reportSheets lays the summary out as tabs.
+func reportSheets(sum ReportSummary) []workspace.NewSheet {
+	summary := [][]any{
+		{"Generated", sum.Generated.UTC().Format(time.RFC3339)},
+		{"Items", sum.Items},
+		{"Deletions (7 days)", sum.Deletions},
+		{"Stale items", sum.StaleCount},
+	}
+	byStatus := [][]any{{"Type", "Status", "Items"}}
+	for _, typ := range sortedKeys(sum.ByType) {
+		for _, status := range sortedKeys(sum.ByStatus[typ]) {
+			byStatus = append(byStatus, []any{typ, status, sum.ByStatus[typ][status]})
+		}
+	}
+	deletions := [][]any{{"Type", "Deleted"}}
+	for _, typ := range sortedKeys(sum.DeletionsByType) {
+		deletions = append(deletions, []any{typ, sum.DeletionsByType[typ]})
+	}
+	stale := [][]any{{"Title", "Type", "Owner", "Modified", "Staleness", "Link"}}
+	for _, item := range sum.Stale {
+		modified := ""
+		if item.Modified != nil {
+			modified = item.Modified.UTC().Format("2006-01-02")
+		}
+		stale = append(stale, []any{item.Title, item.Type, item.Owner, modified, item.Staleness, workspace.ItemURL(item)})
+	}
+	return []workspace.NewSheet{
+		{Title: "Summary", Rows: summary},
+		{Title: "By status", Rows: byStatus},
+		{Title: "Deletions", Rows: deletions},
+		{Title: "Stale", Rows: stale},
+	}
+}
+
+// createReport writes the report into the folder and mails it.
+func (s *Server) createReport(ctx context.Context) (ReportResponse, error) {
+	now := time.Now()
+	sum, err := s.reportSummary(ctx, now)
+	if err != nil {
+		return ReportResponse{}, err
+	}
+	resp := ReportResponse{
+		Format:     s.reportFormat,
+		Title:      fmt.Sprintf("Axis summary: %s", now.UTC().Format("2006-01-02")),
+		Recipients: s.reportRecipients,
+		Summary:    sum,
+	}
+	if s.reportFormat == "sheet" {
+		sheet, err := s.ws.CreateSpreadsheet(ctx, resp.Title, reportSheets(sum))
+		if err != nil {
+			return ReportResponse{}, err
+		}
+		resp.File, resp.URL = sheet.SpreadsheetId, sheet.SpreadsheetUrl
+	} else {
+		text := reportText(sum)
+		doc, err := s.ws.CreateDoc(ctx, resp.Title, text)
+		if err != nil {
+			return ReportResponse{}, err
+		}
+		resp.File = doc.DocumentId
+		resp.URL = workspace.ItemURL(workspace.RegistryItem{ID: doc.DocumentId, Type: "doc"})
+	}
+	if err := s.ws.MoveToFolder(ctx, resp.File, s.reportFolder); err != nil {
+		return resp, fmt.Errorf("wrote report %s: %w", resp.File, err)
+	}
+
+	// As with the cleanup plan Doc, the report exists now; a mail failure is
+	// reported and the link can be passed on by hand.
+	if len(s.reportRecipients) > 0 {
+		body := reportText(sum) + "\nReport: " + resp.URL + "\n"
+		if err := s.ws.SendMail(context.WithoutCancel(ctx), s.reportRecipients, resp.Title, body); err != nil {
+			s.logger.ErrorContext(ctx, "report mail failed", "file", resp.File, "error", err)
+			resp.MailError = err.Error()
+		}
+	}
+
+	s.logger.InfoContext(ctx, "report created", "file", resp.File, "format", resp.Format, "items", sum.Items, "recipients", len(s.reportRecipients))
+	data := map[string]any{"title": resp.Title, "format": resp.Format, "items": sum.Items, "recipients": s.reportRecipients}
+	if resp.MailError != "" {
+		data["mail_error"] = resp.MailError
+	}
+	s.events.Emit(events.Event{Type: events.TypeReportCreated, Actor: actorFrom(ctx), Subject: resp.File, Data: data})
+	return resp, nil
+}
+
+// runReports writes the report at each scheduled time. A report.created event
+// since that time marks it done, so another leader does not write it again;
+// times missed while no instance led are skipped.
+func (s *Server) runReports(ctx context.Context) {
+	if s.reportSchedule == nil || s.reportFolder == "" {
+		return
+	}
+	for {
+		due := s.reportSchedule.Next(time.Now())
+		if due.IsZero() {
+			s.logger.WarnContext(ctx, "report schedule never fires", "schedule", s.reportSchedule.String())
+			return
+		}
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		if !s.IsLeader() || s.currentMode() == "AIRGAP" {
+			continue
+		}
+		written, err := s.store.ListEvents(ctx, store.Query{Since: due, Types: []string{events.TypeReportCreated}, Limit: 1})
+		if err != nil {
+			s.logger.WarnContext(ctx, "report check failed", "error", err)
+			continue
+		}
+		if len(written) > 0 {
+			continue
+		}
+		if _, err := s.createReport(withActor(ctx, "report")); err != nil {
+			s.logger.ErrorContext(ctx, "report failed", "due", due, "error", err)
+		}
+	}
+}
+
+// handleReportSummary serves GET /api/reports/summary, the report's contents
+// without writing it.
+func (s *Server) handleReportSummary(w http.ResponseWriter, r *http.Request) {
+	sum, err := s.reportSummary(r.Context(), time.Now())
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sum)
+}
+
+// handleReportCreate serves POST /api/reports/summary, writing the report now.
+func (s *Server) handleReportCreate(w http.ResponseWriter, r *http.Request) {
+	if s.reportFolder == "" {
+		writeAPIError(w, errReportsDisabled, http.StatusNotFound)
+		return
+	}
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "reports are written to Drive, which AIRGAP mode cannot do", http.StatusConflict)
+		return
+	}
+	resp, err := s.createReport(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
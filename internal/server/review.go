@@ -0,0 +1,134 @@
+/*
+File: internal/server/review.go
+Description: Review checklists. Summarizes a proposal batch (registry items in a
+given status) as a Keep checklist note with one entry per item and its link, then
+shares the note with reviewers so the review itself happens in Keep.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+const defaultReviewStatus = "Execute"
+
+// ReviewResponse describes a created review checklist.
+type ReviewResponse struct {
+	Note       string   `json:"note"`
+	Title      string   `json:"title"`
+	Items      int      `json:"items"`
+	Reviewers  []string `json:"reviewers"`
+	ShareError string   `json:"share_error,omitempty"`
+}
+
+// WithReviewers sets the default reviewers for review checklists.
+func WithReviewers(emails []string) Option {
+	return func(s *Server) { s.reviewers = emails }
+}
+
+// handleReview creates a checklist for the batch selected by ?status= (default
+// Execute) and shares it with ?reviewers= or the configured default reviewers.
+func (s *Server) handleReview(w http.ResponseWriter, r *http.Request) {
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "review checklists write to Keep, which AIRGAP mode cannot do", http.StatusConflict)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = defaultReviewStatus
+	}
+	reviewers := s.reviewers
+	if raw := r.URL.Query().Get("reviewers"); raw != "" {
+		reviewers = strings.Split(raw, ",")
+	}
+	reviewers, err := parseReviewers(reviewers)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	items, err := s.RegistrySnapshot(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	var batch []workspace.ListItemInput
+	for _, item := range items {
+		if item.Status != status {
+			continue
+		}
+		batch = append(batch, workspace.ListItemInput{Text: reviewEntry(item)})
+	}
+	if len(batch) == 0 {
+		apiError(w, fmt.Sprintf("no items with status %q", status), http.StatusNotFound)
+		return
+	}
+
+	title := fmt.Sprintf("Axis review: %d %s items (%s)", len(batch), status, time.Now().Format("2006-01-02"))
+	note, err := s.ws.CreateListNote(r.Context(), title, batch)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+
+	resp := ReviewResponse{Note: note.Name, Title: title, Items: len(batch), Reviewers: reviewers}
+	// The note exists at this point, so a sharing failure is reported rather
+	// than failing the request; the operator can share it by hand.
+	if _, err := s.ws.AddNoteWriters(context.WithoutCancel(r.Context()), note.Name, reviewers); err != nil {
+		s.logger.ErrorContext(r.Context(), "review share failed", "note", note.Name, "error", err)
+		resp.ShareError = err.Error()
+	}
+
+	s.ensureKeepNoteCached(note.Name, title)
+	s.broadcastRegistry()
+	s.events.Emit(events.Event{
+		Type:    events.TypeReviewCreated,
+		Actor:   actorFrom(r.Context()),
+		Subject: note.Name,
+		Data:    map[string]any{"status": status, "items": len(batch), "reviewers": reviewers},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func reviewEntry(item workspace.RegistryItem) string {
+	title := strings.TrimSpace(item.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+	entry := fmt.Sprintf("[%s] %s", item.Type, title)
+	if link := workspace.ItemURL(item); link != "" {
+		entry += " " + link
+	}
+	return entry
+}
+
+func parseReviewers(raw []string) ([]string, error) {
+	var out []string
+	for _, r := range raw {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reviewer %q", r)
+		}
+		out = append(out, addr.Address)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no reviewers given")
+	}
+	return out, nil
+}
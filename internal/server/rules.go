@@ -0,0 +1,153 @@
+/*
+File: internal/server/rules.go
+Description: Rules integration. Builds rule attributes from registry items (adding
+content statistics only when a rule needs them), evaluates the configured rules
+at /api/rules, emits rule.matched events for newly matching items, and resolves
+default statuses.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/rules"
+	"axis/internal/workspace"
+)
+
+// RuleMatches lists the items one rule currently matches.
+type RuleMatches struct {
+	Rule  rules.Rule       `json:"rule"`
+	Items []RuleMatchEntry `json:"items"`
+}
+
+// RuleMatchEntry identifies a matched item.
+type RuleMatchEntry struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+}
+
+// WithRules sets the rules evaluated against the registry.
+func WithRules(set *rules.Set) Option {
+	return func(s *Server) { s.rules = set }
+}
+
+// itemAttributes builds the facts rules test for item. Content statistics are
+// fetched only when withContent is set and the item has text content.
+func (s *Server) itemAttributes(ctx context.Context, item workspace.RegistryItem, withContent bool) rules.Attributes {
+	attrs := rules.Attributes{
+		rules.AttrID:        item.ID,
+		rules.AttrType:      item.Type,
+		rules.AttrTitle:     item.Title,
+		rules.AttrStatus:    item.Status,
+		rules.AttrProtected: item.Protected,
+		rules.AttrCampaign:  item.Campaign,
+		rules.AttrLinkRot:   item.LinkRot,
+		rules.AttrExternal:  item.External,
+	}
+	if item.Modified != nil {
+		attrs[rules.AttrModified] = *item.Modified
+	}
+	if item.Folder != "" {
+		attrs[rules.AttrFolder] = item.Folder
+	}
+	if item.Owner != "" {
+		attrs[rules.AttrOwner] = item.Owner
+	}
+	if item.Size > 0 {
+		attrs[rules.AttrSize] = float64(item.Size)
+	}
+	if act, ok := s.ownerActivity(item.Owner); ok {
+		attrs[rules.AttrOwnerInactive] = act.inactive(time.Now(), s.inactiveThreshold())
+		if act.LastLogin != nil {
+			attrs[rules.AttrOwnerLastLogin] = *act.LastLogin
+		}
+	}
+	attrs[rules.AttrStaleness] = item.Staleness
+	if !withContent || (item.Type != "keep" && item.Type != "doc") {
+		return attrs
+	}
+	st, err := s.contentStats(ctx, item)
+	if err != nil {
+		s.logger.WarnContext(ctx, "content stats unavailable", "id", item.ID, "error", err)
+		return attrs
+	}
+	attrs[rules.AttrWords] = float64(st.Words)
+	attrs[rules.AttrChars] = float64(st.Chars)
+	attrs[rules.AttrLanguage] = st.Language
+	attrs[rules.AttrRecency] = st.Recency
+	return attrs
+}
+
+// defaultStatus resolves the status an item gets when it has none, from the
+// rules file's defaults or the built-in ones. It sees the fields set by the
+// enrichers that ran before the status stage.
+func (s *Server) defaultStatus(item workspace.RegistryItem) string {
+	item.Status = ""
+	return s.rules.DefaultStatus(s.itemAttributes(context.Background(), item, false), time.Now())
+}
+
+// evaluateRules matches every rule against the registry and emits rule.matched
+// for pairs that did not match at the previous evaluation.
+func (s *Server) evaluateRules(ctx context.Context) ([]RuleMatches, error) {
+	items, err := s.RegistrySnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	ruleSet := s.rules.Rules()
+	results := make([]RuleMatches, len(ruleSet))
+	seen := make(map[string]bool)
+	for i, rule := range ruleSet {
+		results[i] = RuleMatches{Rule: rule, Items: []RuleMatchEntry{}}
+	}
+	for _, item := range items {
+		// Cheap attributes first; content is only fetched for rules that need it.
+		attrs := s.itemAttributes(ctx, item, false)
+		withContent := false
+		for i, rule := range ruleSet {
+			if rule.NeedsContent() && !withContent {
+				attrs = s.itemAttributes(ctx, item, true)
+				withContent = true
+			}
+			if !rule.Matches(attrs, now) {
+				continue
+			}
+			results[i].Items = append(results[i].Items, RuleMatchEntry{ID: item.ID, Type: item.Type, Title: item.Title})
+			seen[rule.Name+"\x00"+item.ID] = true
+		}
+	}
+
+	s.rulesMu.Lock()
+	previous := s.ruleMatches
+	s.ruleMatches = seen
+	s.rulesMu.Unlock()
+	for _, res := range results {
+		for _, m := range res.Items {
+			if previous[res.Rule.Name+"\x00"+m.ID] {
+				continue
+			}
+			s.events.Emit(events.Event{
+				Type:    events.TypeRuleMatched,
+				Actor:   "rules",
+				Subject: m.ID,
+				Data:    map[string]any{"rule": res.Rule.Name, "type": m.Type, "title": m.Title},
+			})
+		}
+	}
+	return results, nil
+}
+
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	results, err := s.evaluateRules(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,93 @@
+/*
+File: internal/server/search.go
+Description: /api/search. Answers from the local full-text index when it is
+built, otherwise queries Keep, Docs and Sheets live and merges the matches into
+one ranking. Results run through the enrichment pipeline and page with the same
+?limit= and ?page_token= as /api/registry.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"axis/internal/workspace"
+)
+
+// SearchResponse is the body of /api/search. NextPageToken is empty on the
+// last page.
+type SearchResponse struct {
+	Query         string                   `json:"query"`
+	Source        string                   `json:"source"` // "index" or "live"
+	Results       []workspace.SearchResult `json:"results"`
+	Total         int                      `json:"total"`
+	NextPageToken string                   `json:"next_page_token,omitempty"`
+}
+
+// handleSearch serves ?q= with an optional ?type=keep,doc filter. Once the local
+// index is built it answers without API calls; ?live=1 queries Google instead.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		apiError(w, "missing q", http.StatusBadRequest)
+		return
+	}
+	types, err := workspace.ParseTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	source := "index"
+	var results []workspace.SearchResult
+	if s.indexReady() && !truthyParam(r.URL.Query().Get("live")) {
+		results = s.searchIndex(query, types)
+	} else {
+		source = "live"
+		if results, err = s.ws.Search(r.Context(), query, types); err != nil {
+			s.logger.ErrorContext(r.Context(), "search failed", "query", query, "error", err)
+			apiError(w, "search failed", http.StatusBadGateway)
+			return
+		}
+	}
+	page, next, err := paginate(r, results)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	items := make([]workspace.RegistryItem, len(page))
+	for i := range page {
+		items[i] = page[i].RegistryItem
+	}
+	for i, item := range s.enrichItems(items) {
+		page[i].RegistryItem = item
+	}
+	if page == nil {
+		page = []workspace.SearchResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SearchResponse{Query: query, Source: source, Results: page, Total: len(results), NextPageToken: next})
+}
+
+// searchIndex resolves index hits against the cached registry, dropping items
+// removed since the last index pass.
+func (s *Server) searchIndex(query string, types []string) []workspace.SearchResult {
+	items, _ := s.cachedItemsFresh()
+	if len(items) == 0 {
+		s.refreshRegistryCache()
+		items, _ = s.cachedItemsFresh()
+	}
+	byID := make(map[string]workspace.RegistryItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	var results []workspace.SearchResult
+	for _, hit := range s.index.Search(query, types) {
+		if item, ok := byID[hit.ID]; ok {
+			results = append(results, workspace.SearchResult{RegistryItem: item, Score: hit.Score})
+		}
+	}
+	return results
+}
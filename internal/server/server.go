@@ -10,13 +10,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"axis/internal/auth"
+	"axis/internal/backup"
+	"axis/internal/broker"
+	"axis/internal/cluster"
+	"axis/internal/credentials"
+	"axis/internal/cron"
+	"axis/internal/events"
+	"axis/internal/faults"
+	"axis/internal/federation"
+	"axis/internal/index"
+	"axis/internal/jobs"
+	"axis/internal/linkcheck"
+	"axis/internal/playbook"
+	"axis/internal/quota"
+	"axis/internal/rules"
+	"axis/internal/scheduler"
+	"axis/internal/sheetconfig"
+	"axis/internal/store"
+	"axis/internal/textstats"
 	"axis/internal/workspace"
 )
 
@@ -25,42 +48,173 @@ const (
 	cacheTTL         = 5 * time.Minute
 	persistInterval  = 10 * time.Second
 	pollInterval     = 1 * time.Second
-	autoRefreshTicks = 60
+	autoRefreshEvery = 60 * time.Second
+	shutdownTimeout  = 10 * time.Second
 )
 
 // RegistryCache stores the latest registry snapshot with a TTL.
 type RegistryCache struct {
-	items     []workspace.RegistryItem
-	expiresAt time.Time
-	mu        sync.RWMutex
-}
-
-// SSEMessage wraps data with an optional event type.
-type SSEMessage struct {
-	Event string
-	Data  []byte
-}
-
-// persistentState defines the structure for disk storage.
-type persistentState struct {
-	Mode     string            `json:"mode"`
-	Statuses map[string]string `json:"statuses"`
+	snap atomic.Pointer[registrySnapshot]
+	mu   sync.RWMutex // serializes snapshot updates; guards the fields below
+
+	// Change detection state; see registry.go.
+	listedAt   map[string]time.Time // last full listing per item type
+	driveToken string               // Drive changes page token
+	driveDirty map[string]bool      // types with Drive changes not yet relisted
+	keepSince  time.Time            // Keep notes updated after this are relisted
 }
 
 // Server handles HTTP communication and TUI orchestration.
 type Server struct {
-	ws       *workspace.Service
-	user     *workspace.User
-	mode     string
-	statuses map[string]string
-	modeMu   sync.RWMutex
+	ws        *workspace.Service
+	user      *workspace.User
+	mode      atomic.Value // string; see snapshot.go
+	statuses  atomic.Pointer[statusSet]
+	tags      atomic.Pointer[tagSet]
+	deadlines atomic.Pointer[deadlineSet]
+	statusMu  sync.Mutex // serializes status writers
+
+	deadlineRunning  atomic.Bool
+	deadlineFailures map[string]deadlineFailure // guarded by deadlineMu
+	deadlineMu       sync.Mutex
+
+	archiveFolder  string
+	archiveRunning atomic.Bool
+	archiveRetry   map[string]time.Time // failed archives by next attempt; guarded by archiveMu
+	archiveMu      sync.Mutex
 
 	registryCache RegistryCache
-	stateChan     chan persistentState
+	diffBase      registryBaseline // what clients were last sent; guarded by diffMu
+	diffMu        sync.Mutex
+	store         store.Store
+	stateDirty    chan struct{} // signals that the state snapshot needs persisting
+
+	hub    *broker.Broker
+	logger *slog.Logger
+
+	playbooks  *playbook.Catalog
+	publishers []events.Publisher
+	events     *events.Emitter
+
+	policyLoader *sheetconfig.Loader
+	policy       atomic.Pointer[sheetconfig.Policy]
+
+	reminderCalendar string
+	reminderSyncing  atomic.Bool
+	syncedReminders  map[string]bool // guarded by reminderSyncing
+
+	noteLogSheet string
+	noteLogRange string
+
+	linkChecker      *linkcheck.Checker
+	linkScanInterval time.Duration
+	linkScanning     atomic.Bool
+	linkReports      map[string]LinkReport
+	linksScannedAt   time.Time
+	linksMu          sync.RWMutex
+
+	reviewers []string
+
+	noteConvert      bool
+	attachmentFolder string
+	driveUpload      bool
+
+	cleanupDocRecipients []string
+	cleanupDocDay        time.Weekday
+
+	reportFolder     string
+	reportFormat     string
+	reportSchedule   *cron.Schedule // nil writes reports on request only
+	reportRecipients []string
+
+	internalDomains map[string]bool
+
+	wsOrigins []string
+	auth      *auth.Authenticator
+
+	legacyGETMutations bool
+
+	statsCache    map[string]statsEntry
+	sheetProfiles map[string]sheetProfileEntry
+	statsMu       sync.Mutex
+
+	rules       *rules.Set
+	ruleMatches map[string]bool // rule name + item ID pairs seen at the last evaluation
+	rulesMu     sync.Mutex
+	budgets     budgetLedger
+
+	userSchemas      []string
+	userRuleInterval time.Duration
+	userRuleMatches  map[string]bool // rule name + email pairs acted on; nil until restored
+	userRulesMu      sync.Mutex
+
+	suspendedPlaybook string
+	suspended         suspendedCache
+
+	sources sourceHealth
+
+	userCache microCache
+
+	inactiveAfter time.Duration
+	activity      atomic.Pointer[userActivity]
+
+	directoryGroups bool
+
+	storageMaxBytes     int64
+	storageQuotaPercent float64
+	storage             atomic.Pointer[storageReading]
+
+	quota *quota.Transport
+
+	credentials *credentials.Manager
+	apiTokens   tokenCache
+	backups     *backup.Backups
+	tokens      *credentials.Monitor
+
+	faults *faults.Injector // nil unless built with -tags faults
+
+	about     About
+	readiness readiness
+
+	enrichers       []*enrichStage
+	enricherNames   []string
+	enricherPlugins []Enricher
+
+	cluster *cluster.Node
+	tenants map[string]*Server // hosted tenants by name
+
+	subjects subjectServices
+
+	schedule *scheduler.Scheduler
+	poller   *poller
+	jobs     *jobs.Manager
 
-	clients   map[chan SSEMessage]bool
-	clientsMu sync.Mutex
-	logger    *slog.Logger
+	journalRetention  time.Duration
+	snapshotRetention time.Duration
+	statusGrace       time.Duration
+	statusGC          statusGC
+	journalBase       map[string]string // item ID to JSON as of the last journaled snapshot
+	journalMu         sync.Mutex
+
+	federation         *federation.Federation
+	federationInterval time.Duration
+
+	index        *index.Index
+	indexPath    string
+	indexDirty   chan struct{} // signals that the registry changed since the last index pass
+	indexContent chan struct{} // signals that Docs and Sheets may await export
+
+	airgap *airGap // non-nil locks the server into AIRGAP mode
+
+	life lifecycle
+}
+
+// Option customizes optional server subsystems.
+type Option func(*Server)
+
+// WithPlaybooks enables inbound triggers backed by the supplied playbook catalog.
+func WithPlaybooks(c *playbook.Catalog) Option {
+	return func(s *Server) { s.playbooks = c }
 }
 
 // UserResponse provides minimal operator context for the UI.
@@ -75,221 +229,356 @@ type ModeResponse struct {
 	Mode string `json:"mode"`
 }
 
+// WithPublisher forwards operational events to an external publisher.
+func WithPublisher(p events.Publisher) Option {
+	return func(s *Server) { s.publishers = append(s.publishers, p) }
+}
+
+// WithStore selects the state backend. Without it the server uses the JSON
+// state file in the working directory.
+func WithStore(st store.Store) Option {
+	return func(s *Server) { s.store = st }
+}
+
 // NewServer initializes the server with the workspace service and user context.
-func NewServer(ws *workspace.Service, user *workspace.User) *Server {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+func NewServer(ws *workspace.Service, user *workspace.User, opts ...Option) *Server {
+	logger := slog.New(requestIDHandler{slog.NewJSONHandler(os.Stdout, nil)})
 	s := &Server{
-		ws:        ws,
-		user:      user,
-		mode:      "AUTO",
-		statuses:  make(map[string]string),
-		stateChan: make(chan persistentState, 16),
-		clients:   make(map[chan SSEMessage]bool),
-		logger:    logger,
-	}
+		ws:           ws,
+		user:         user,
+		stateDirty:   make(chan struct{}, 1),
+		indexDirty:   make(chan struct{}, 1),
+		indexContent: make(chan struct{}, 1),
+		hub:          broker.New(broker.DefaultBuffer, broker.DefaultMaxDrops),
+		poller:       newPoller(),
+		logger:       logger,
+
+		syncedReminders:  make(map[string]bool),
+		linkChecker:      linkcheck.NewChecker(linkcheck.DefaultInterval, linkcheck.DefaultTTL),
+		statsCache:       make(map[string]statsEntry),
+		sheetProfiles:    make(map[string]sheetProfileEntry),
+		ruleMatches:      make(map[string]bool),
+		deadlineFailures: make(map[string]deadlineFailure),
+		archiveRetry:     make(map[string]time.Time),
+		userCache:        microCache{ttl: userCacheTTL},
+
+		journalRetention:  defaultJournalRetention,
+		snapshotRetention: defaultSnapshotRetention,
+		statusGrace:       defaultStatusGrace,
+	}
+	s.mode.Store("AUTO")
+	s.statuses.Store(&statusSet{})
+	s.tags.Store(&tagSet{})
+	s.deadlines.Store(&deadlineSet{})
+	s.schedule, _ = scheduler.New(workspace.ItemTypes, autoRefreshEvery, nil)
+	s.jobs = jobs.New(jobs.DefaultWorkers, jobs.DefaultQueue, jobs.DefaultRetention, s.notifyJob)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.store == nil {
+		s.store = store.NewFileStore(stateFileName)
+	}
+	s.buildEnrichers()
+	// Every emitted event is also kept in the audit history.
+	publishers := append([]events.Publisher{store.EventRecorder{Store: s.store}, auditJournal{s}}, s.publishers...)
+	s.events = events.NewEmitter(s.logger, publishers...)
 	s.loadState()
+	s.applyAirGap()
 	return s
 }
 
-// loadState restores mode/statuses from disk if available.
+// loadState restores mode/statuses from the state store if available.
 func (s *Server) loadState() {
 	start := time.Now()
-	data, err := os.ReadFile(stateFileName)
+	ps, err := s.store.LoadState(context.Background())
 	if err != nil {
-		if !os.IsNotExist(err) {
-			s.logger.Error("failed to read state file", "error", err)
-		}
+		s.logger.Error("failed to load state", "error", err)
 		return
 	}
-
-	var ps persistentState
-	if err := json.Unmarshal(data, &ps); err != nil {
-		s.logger.Error("corrupt state file", "error", err)
-		return
+	if rec, ok := s.store.(store.Recoverer); ok {
+		if backup := rec.Recovered(); backup != "" {
+			s.logger.Warn("state file unreadable, restored from backup", "backup", backup)
+			s.events.Emit(events.Event{Type: events.TypeStateRecovered, Actor: "store", Subject: backup})
+		}
+		if moved := rec.Quarantined(); moved != "" {
+			s.logger.Error("state file corrupt and no backup readable; starting with empty state", "moved_to", moved)
+			s.events.Emit(events.Event{Type: events.TypeStateDiscarded, Actor: "store", Subject: moved})
+		}
 	}
 
-	s.modeMu.Lock()
-	defer s.modeMu.Unlock()
-	if ps.Mode == "AUTO" || ps.Mode == "MANUAL" {
-		s.mode = ps.Mode
+	if validMode(ps.Mode) {
+		s.swapMode(ps.Mode)
 	}
-	if ps.Statuses != nil {
-		// Migrate old state values to new ones
-		s.statuses = make(map[string]string, len(ps.Statuses))
-		for id, status := range ps.Statuses {
-			switch status {
-			case "Keep", "Delete":
-				s.statuses[id] = "Pending"
-			case "Execute":
-				s.statuses[id] = "Execute"
-			default:
-				s.statuses[id] = status
-			}
+	if len(ps.Statuses) > 0 {
+		// Migrate old state values to new ones, unless the taxonomy still
+		// uses them.
+		if tx := s.rules.Statuses(); !tx.Has("Keep") && !tx.Has("Delete") {
+			ps, _ = store.NormalizeState(ps)
 		}
+		restored := statusSet(ps.Statuses)
+		s.statuses.Store(&restored)
+	}
+	if len(ps.Tags) > 0 {
+		tags := tagSet(ps.Tags)
+		s.tags.Store(&tags)
+	}
+	if len(ps.Deadlines) > 0 {
+		deadlines := deadlineSet(ps.Deadlines)
+		s.deadlines.Store(&deadlines)
 	}
-	s.logger.Info("state restored", "duration", time.Since(start), "items", len(s.statuses))
+	s.logger.Info("state restored", "duration", time.Since(start), "items", len(s.currentStatuses()))
 }
 
-// Start launches the HTTP server and background automation ticker.
-func (s *Server) Start(port string) error {
+// routes registers the API, login and static asset handlers.
+func (s *Server) routes() *http.ServeMux {
 	mux := http.NewServeMux()
 
+	viewer := requires(auth.RoleViewer)
+	operator := requires(auth.RoleOperator)
+	admin := requires(auth.RoleAdmin)
+
 	// API Routes
-	mux.HandleFunc("/api/notes", s.handleNotes)
-	mux.HandleFunc("/api/notes/delete", s.handleDelete)
-	mux.HandleFunc("/api/notes/detail", s.handleNoteDetail)
-	mux.HandleFunc("/api/mode", s.handleMode)
-	mux.HandleFunc("/api/user", s.handleUser)
-	mux.HandleFunc("/api/sheets", s.handleGetSheet)
-	mux.HandleFunc("/api/sheets/delete", s.handleDeleteSheet)
-	mux.HandleFunc("/api/docs", s.handleGetDoc)
-	mux.HandleFunc("/api/docs/delete", s.handleDeleteDoc)
-	mux.HandleFunc("/api/registry", s.handleRegistry)
-	mux.HandleFunc("/api/status", s.handleStatus)
+	mux.HandleFunc("/api/notes", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleNotes)))
+	mux.HandleFunc("DELETE /api/notes/delete", s.guard(operator, s.mutation(s.handleDelete)))
+	mux.HandleFunc("GET /api/notes/delete", s.guard(operator, s.legacy("", s.handleDelete)))
+	mux.HandleFunc("POST /api/notes/bulk-delete", s.guard(operator, s.mutation(s.handleBulkDelete)))
+	mux.HandleFunc("POST /api/notes/bulk-status", s.guardScoped(operator, auth.ScopeStatusWrite, s.mutation(s.handleBulkStatus)))
+	mux.HandleFunc("/api/notes/detail", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleNoteDetail)))
+	mux.HandleFunc("/api/notes/attachments", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleAttachment)))
+	mux.HandleFunc("POST /api/drive/upload", s.guard(operator, s.mutation(s.handleDriveUpload)))
+	mux.HandleFunc("GET /api/drive/download", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleDriveDownload)))
+	mux.HandleFunc("GET /api/drive/export", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleDriveExport)))
+	mux.HandleFunc("POST /api/notes/attachments/sync", s.guard(operator, s.mutation(s.handleAttachmentSync)))
+	mux.HandleFunc("GET /api/notes/permissions", s.guard(viewer, s.handleNotePermissions))
+	mux.HandleFunc("POST /api/notes/convert", s.guard(operator, s.mutation(s.handleNoteConvert)))
+	mux.HandleFunc("GET /api/notes/list-items", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleListItems)))
+	mux.HandleFunc("PATCH /api/notes/list-items", s.guard(operator, s.mutation(s.handleListEdit)))
+	mux.HandleFunc("GET /api/mode", s.guard(requiresWhen("set", auth.RoleViewer, auth.RoleOperator), cached(cacheNoStore, s.legacy("set", s.handleMode))))
+	mux.HandleFunc("POST /api/mode", s.guard(operator, s.mutation(s.handleMode)))
+	mux.HandleFunc("/api/user", s.guard(viewer, cached(cacheUser, s.userCache.serve(s.handleUser))))
+	mux.HandleFunc("/api/sheets", s.guard(viewer, s.handleGetSheet))
+	mux.HandleFunc("POST /api/sheets", s.guard(operator, s.mutation(s.handleCreateSheet)))
+	mux.HandleFunc("GET /api/sheets/values", s.guard(viewer, s.handleSheetValues))
+	mux.HandleFunc("PUT /api/sheets/values", s.guard(operator, s.mutation(s.handleSheetValuesUpdate)))
+	mux.HandleFunc("DELETE /api/sheets/delete", s.guard(operator, s.mutation(s.handleDeleteSheet)))
+	mux.HandleFunc("GET /api/sheets/delete", s.guard(operator, s.legacy("", s.handleDeleteSheet)))
+	mux.HandleFunc("/api/docs", s.guard(viewer, s.handleGetDoc))
+	mux.HandleFunc("POST /api/docs", s.guard(operator, s.mutation(s.handleCreateDoc)))
+	mux.HandleFunc("PATCH /api/docs", s.guard(operator, s.mutation(s.handleDocEdit)))
+	mux.HandleFunc("GET /api/tags", s.guard(viewer, s.handleTags))
+	mux.HandleFunc("PUT /api/tags", s.guardScoped(operator, auth.ScopeTagsWrite, s.mutation(s.handleTagsUpdate)))
+	mux.HandleFunc("GET /api/docs/text", s.guard(viewer, s.handleDocText))
+	mux.HandleFunc("DELETE /api/docs/delete", s.guard(operator, s.mutation(s.handleDeleteDoc)))
+	mux.HandleFunc("GET /api/docs/delete", s.guard(operator, s.legacy("", s.handleDeleteDoc)))
+	mux.HandleFunc("/api/registry", s.guard(requiresSubject(auth.RoleViewer), cached(cacheRegistry, s.subjectScoped(s.handleRegistry))))
+	mux.HandleFunc("GET /api/items", s.guard(requiresSubject(auth.RoleViewer), s.subjectScoped(s.handleItem)))
+	mux.HandleFunc("GET /api/registry/sources", s.guard(viewer, s.handleRegistrySources))
+	mux.HandleFunc("GET /api/registry/summary", s.guard(viewer, s.handleRegistrySummary))
+	mux.HandleFunc("GET /api/registry/export", s.guard(viewer, cached(cacheNoStore, s.handleRegistryExport)))
+	mux.HandleFunc("GET /api/registry/comments", s.guard(viewer, s.handleComments))
+	mux.HandleFunc("POST /api/registry/archive", s.guard(operator, s.mutation(s.handleArchive)))
+	mux.HandleFunc("POST /api/registry/comments", s.guardScoped(operator, auth.ScopeCommentsWrite, s.mutation(s.handleCommentAdd)))
+	mux.HandleFunc("GET /api/search", s.guard(viewer, s.handleSearch))
+	mux.HandleFunc("GET /api/plan", s.guard(viewer, s.handlePlan))
+	mux.HandleFunc("POST /api/plan/export", s.guard(operator, s.mutation(s.handlePlanExport)))
+	mux.HandleFunc("DELETE /api/plan", s.guard(operator, s.mutation(s.handlePlanDiscard)))
+	mux.HandleFunc("POST /api/status", s.guardScoped(operator, auth.ScopeStatusWrite, s.mutation(s.handleStatus)))
+	mux.HandleFunc("GET /api/status", s.guardScoped(operator, auth.ScopeStatusWrite, s.legacy("", s.handleStatus)))
+	mux.HandleFunc("GET /api/status/history", s.guard(viewer, s.handleStatusHistory))
+	mux.HandleFunc("GET /api/statuses", s.guard(viewer, s.handleStatuses))
+	mux.HandleFunc("GET /api/status/deadlines", s.guard(viewer, s.handleDeadlines))
+	mux.HandleFunc("POST /api/status/deadline", s.guardScoped(operator, auth.ScopeStatusWrite, s.mutation(s.handleDeadlineSet)))
+	mux.HandleFunc("DELETE /api/status/deadline", s.guardScoped(operator, auth.ScopeStatusWrite, s.mutation(s.handleDeadlineCancel)))
+	mux.HandleFunc("/api/export", s.guard(admin, s.handleExport))
+	mux.HandleFunc("GET /api/policy", s.guard(requiresWhen("reload", auth.RoleViewer, auth.RoleOperator), s.legacy("reload", s.handlePolicy)))
+	mux.HandleFunc("POST /api/policy/reload", s.guard(operator, s.mutation(s.handlePolicy)))
+	mux.HandleFunc("/api/audit", s.guard(admin, s.handleAudit))
+	mux.HandleFunc("GET /api/links", s.guard(viewer, s.handleLinks))
+	mux.HandleFunc("POST /api/links/scan", s.guard(operator, s.mutation(s.handleLinkScan)))
+	mux.HandleFunc("POST /api/review", s.guard(operator, s.mutation(s.handleReview)))
+	mux.HandleFunc("GET /api/rules", s.guard(viewer, s.handleRules))
+	mux.HandleFunc("POST /api/rules/preview", s.guard(viewer, s.handleRulePreview))
+	mux.HandleFunc("GET /api/reports/inactive-users", s.guard(operator, s.handleInactiveUsers))
+	mux.HandleFunc("GET /api/domain/storage", s.guard(operator, s.handleDomainStorage))
+	mux.HandleFunc("POST /api/reports/cleanup-doc", s.guard(operator, s.mutation(s.handleCleanupDoc)))
+	mux.HandleFunc("GET /api/reports/summary", s.guard(viewer, cached(cacheNoStore, s.handleReportSummary)))
+	mux.HandleFunc("GET /api/analysis/stale", s.guard(viewer, cached(cacheRegistry, s.handleStaleAnalysis)))
+	mux.HandleFunc("POST /api/reports/summary", s.guard(operator, s.mutation(s.handleReportCreate)))
+	mux.HandleFunc("GET /api/rules/users", s.guard(operator, s.handleUserRules))
+	mux.HandleFunc("GET /api/budgets", s.guard(viewer, s.handleBudgets))
+	mux.HandleFunc("GET /api/federation", s.guard(viewer, s.handleFederation))
+	mux.HandleFunc("GET /api/users", s.guard(operator, s.handleUsers))
+	mux.HandleFunc("POST /api/users/lookup", s.guard(operator, s.handleUserLookup))
+	mux.HandleFunc("GET /api/admin/groups", s.guard(operator, s.handleGroups))
+	mux.HandleFunc("GET /api/admin/groups/{group}/members", s.guard(operator, s.handleGroupMembers))
+	mux.HandleFunc("GET /api/admin/orgunits", s.guard(operator, s.handleOrgUnits))
+	mux.HandleFunc("GET /api/suspended", s.guard(operator, s.handleSuspended))
+	mux.HandleFunc("POST /api/suspended/launch", s.guard(operator, s.mutation(s.handleSuspendedLaunch)))
+	mux.HandleFunc("PUT /api/users/fields", s.guard(admin, s.mutation(s.handleUserFields)))
+	mux.HandleFunc("GET /api/credentials", s.guard(admin, s.handleCredentials))
+	mux.HandleFunc("POST /api/credentials/activate", s.guard(admin, s.mutation(s.handleCredentialsActivate)))
+	mux.HandleFunc("GET /api/about", s.guard(operator, s.handleAbout))
+	mux.HandleFunc("GET /api/auth/me", s.guard(viewer, s.handleMe))
+	mux.HandleFunc("GET /api/auth/status", s.guard(viewer, s.handleAuthStatus))
+	mux.HandleFunc("GET /api/tokens", s.guard(admin, s.handleTokens))
+	mux.HandleFunc("POST /api/tokens", s.guard(admin, s.mutation(s.handleTokenCreate)))
+	mux.HandleFunc("DELETE /api/tokens", s.guard(admin, s.mutation(s.handleTokenRevoke)))
+	mux.HandleFunc("GET /metrics", s.guard(viewer, s.handleMetrics))
+	mux.HandleFunc("GET /api/poller", s.guard(viewer, cached(cacheNoStore, s.handlePoller)))
+	mux.HandleFunc("POST /api/poller/pause", s.guard(operator, s.mutation(s.handlePollerPause)))
+	mux.HandleFunc("POST /api/poller/resume", s.guard(operator, s.mutation(s.handlePollerResume)))
+	mux.HandleFunc("POST /api/poller/trigger", s.guard(operator, s.mutation(s.handlePollerTrigger)))
+	mux.HandleFunc("GET /api/jobs", s.guard(operator, cached(cacheNoStore, s.handleJobs)))
+	mux.HandleFunc("GET /api/jobs/{id}", s.guard(operator, cached(cacheNoStore, s.handleJob)))
+	mux.HandleFunc("DELETE /api/jobs/{id}", s.guard(operator, s.mutation(s.handleJobCancel)))
+	mux.HandleFunc("GET /api/jobs/{id}/result", s.guard(operator, cached(cacheNoStore, s.handleJobResult)))
+	mux.HandleFunc("GET /api/config", s.guard(viewer, s.handleConfig))
+	mux.HandleFunc("PUT /api/config", s.guard(admin, s.mutation(s.handleConfigUpdate)))
+
+	if s.faults != nil {
+		mux.HandleFunc("GET /api/debug/faults", s.guard(admin, s.handleFaults))
+		mux.HandleFunc("PUT /api/debug/faults", s.guard(admin, s.mutation(s.handleFaults)))
+		mux.HandleFunc("DELETE /api/debug/faults", s.guard(admin, s.mutation(s.handleFaults)))
+	}
+
+	// Probes are unauthenticated so orchestrators can reach them.
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+
+	// Triggers authenticate with their own HMAC signature.
+	mux.HandleFunc("POST /api/triggers/{name}", s.handleTrigger)
 
 	// SSE Endpoint
-	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/events", s.streamTenant(func(t *Server) http.HandlerFunc { return t.guard(viewer, t.handleEvents) }))
+	mux.HandleFunc("GET /api/events/replay", s.guard(viewer, s.handleReplay))
+	mux.HandleFunc("/api/ws", s.streamTenant(func(t *Server) http.HandlerFunc { return t.guard(viewer, t.handleWebSocket) }))
+
+	// Hosted tenants
+	mux.HandleFunc("GET /api/tenants", s.guard(viewer, s.handleTenants))
+	if len(s.tenants) > 0 {
+		mux.HandleFunc("/api/t/{tenant}/", s.tenantRoutes())
+	}
+
+	// Login
+	mux.HandleFunc("GET /auth/login", s.auth.HandleLogin)
+	mux.HandleFunc("GET /auth/callback", s.auth.HandleCallback)
+	mux.HandleFunc("GET /auth/logout", s.auth.HandleLogout)
 
 	// Static Asset Mounting
 	fileServer := http.FileServer(http.Dir("./web/dist"))
 	mux.Handle("/", fileServer)
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	go s.runPersistence(ctx)
-	go s.runPoller(ctx)
-
-	s.logger.Info("axis server active", "port", port, "sse", true)
-	return http.ListenAndServe(":"+port, mux)
+	return mux
 }
 
 func (s *Server) runPersistence(ctx context.Context) {
 	ticker := time.NewTicker(persistInterval)
 	defer ticker.Stop()
 
-	var lastState persistentState
 	dirty := false
 
 	for {
 		select {
-		case state := <-s.stateChan:
-			lastState = state
+		case <-s.stateDirty:
 			dirty = true
 		case <-ticker.C:
 			if dirty {
-				s.flushToDisk(lastState)
+				s.flushToDisk(s.storeState())
 				dirty = false
 			}
 		case <-ctx.Done():
 			if dirty {
-				s.flushToDisk(lastState)
+				s.flushToDisk(s.storeState())
 			}
 			return
 		}
 	}
 }
 
-func (s *Server) flushToDisk(ps persistentState) {
+func (s *Server) flushToDisk(ps store.State) {
 	start := time.Now()
-	data, err := json.MarshalIndent(ps, "", "  ")
-	if err != nil {
-		s.logger.Error("marshal error", "error", err)
-		return
-	}
-	if err := os.WriteFile(stateFileName, data, 0644); err != nil {
-		s.logger.Error("disk write error", "error", err)
+	if err := s.store.SaveState(context.Background(), ps); err != nil {
+		s.logger.Error("state write error", "error", err)
 		return
 	}
 	s.logger.Info("state flushed", "latency", time.Since(start), "entries", len(ps.Statuses))
 }
 
-func (s *Server) runPoller(ctx context.Context) {
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
+// refreshRegistryCache lists the given item types (all when none are given)
+// and merges them with the cached items of the other types. The first refresh
+// always lists every type, so stale-status cleanup sees the whole registry.
+func (s *Server) refreshRegistryCache(types ...string) {
+	start := time.Now()
+	s.reloadPolicy()
 
-	remaining := autoRefreshTicks
-	for {
-		select {
-		case <-ticker.C:
-			s.modeMu.RLock()
-			mode := s.mode
-			s.modeMu.RUnlock()
-
-			if mode == "AUTO" {
-				remaining--
-				s.broadcastTick(remaining)
-				if remaining <= 0 {
-					s.refreshRegistryCache()
-					s.broadcastRegistry()
-					remaining = autoRefreshTicks
-				}
-			} else {
-				remaining = autoRefreshTicks
-			}
-		case <-ctx.Done():
-			return
-		}
+	cached := s.registryCache.snapshot()
+	loaded := cached.loaded
+	byType := make(map[string][]workspace.RegistryItem)
+	for _, item := range cached.items {
+		byType[item.Type] = append(byType[item.Type], item)
 	}
-}
 
-func (s *Server) refreshRegistryCache() {
-	start := time.Now()
-	items, err := s.ws.ListRegistryItems()
-	if err != nil {
-		s.logger.Error("workspace fetch failed", "error", err)
+	full := len(types) == 0 || !loaded
+	if full {
+		types = workspace.ItemTypes
+	}
+	fetched, failed := s.fetchItems(types, byType, full)
+	for itemType, err := range failed {
+		s.logger.Error("workspace fetch failed", "type", itemType, "error", err)
+	}
+	if s.sources.record(fetched, failed) {
+		s.broadcastSources()
+	}
+	if len(fetched) == 0 && len(failed) > 0 {
 		return
 	}
+	// A first listing with failed types is served but not marked loaded, so
+	// the next refresh lists every type again.
+	loaded = loaded || len(failed) == 0
+	var items []workspace.RegistryItem
+	for _, itemType := range workspace.ItemTypes {
+		items = append(items, byType[itemType]...)
+	}
+	// Archived Docs and Sheets remain in Drive but leave the registry.
+	if folder := s.archiveFolder; folder != "" {
+		items = slices.DeleteFunc(items, func(item workspace.RegistryItem) bool { return item.Folder == folder })
+	}
 
-	needsSnapshot := s.backfillKeepStatuses(items)
+	needsSnapshot := s.backfillDefaultStatuses(items)
+	go s.syncReminders(items)
 
-	// Clean up statuses for notes that no longer exist
-	if s.cleanupStaleStatuses(items) {
+	// Clean up statuses for items that have stayed out of the registry,
+	// unless a failed type left its items out.
+	if loaded && s.cleanupStaleStatuses(items) {
 		needsSnapshot = true
 	}
 
-	s.registryCache.mu.Lock()
-	s.registryCache.items = cloneItems(items)
-	s.registryCache.expiresAt = time.Now().Add(cacheTTL)
-	s.registryCache.mu.Unlock()
+	s.registryCache.update(func(*registrySnapshot) *registrySnapshot {
+		return &registrySnapshot{items: items, expiresAt: time.Now().Add(cacheTTL), loaded: loaded}
+	})
 
 	if needsSnapshot {
 		s.triggerStateSnapshot()
 	}
+	s.signalIndex()
 
-	s.logger.Info("cache refreshed", "duration", time.Since(start), "count", len(items))
+	s.logger.Info("cache refreshed", "duration", time.Since(start), "types", types, "fetched", fetched, "count", len(items))
 }
 
+// cachedItemsFresh returns the cached items, which callers must not modify,
+// and whether they are within the TTL.
 func (s *Server) cachedItemsFresh() ([]workspace.RegistryItem, bool) {
-	s.registryCache.mu.RLock()
-	defer s.registryCache.mu.RUnlock()
-	fresh := time.Now().Before(s.registryCache.expiresAt)
-	return cloneItems(s.registryCache.items), fresh
-}
-
-func cloneItems(items []workspace.RegistryItem) []workspace.RegistryItem {
-	if len(items) == 0 {
-		return nil
-	}
-	dup := make([]workspace.RegistryItem, len(items))
-	copy(dup, items)
-	return dup
+	snap := s.registryCache.snapshot()
+	return snap.items, time.Now().Before(snap.expiresAt)
 }
 
-func (s *Server) enrichItems(items []workspace.RegistryItem) []workspace.RegistryItem {
-	s.modeMu.RLock()
-	defer s.modeMu.RUnlock()
-
-	res := make([]workspace.RegistryItem, len(items))
-	for i, item := range items {
-		res[i] = item
-		if status, ok := s.statuses[item.ID]; ok {
-			res[i].Status = status
-		} else if item.Type == "keep" {
-			res[i].Status = "Pending"
-		}
+// RegistrySnapshot returns the current enriched registry, refreshing a stale cache.
+func (s *Server) RegistrySnapshot(ctx context.Context) ([]workspace.RegistryItem, error) {
+	items, fresh := s.cachedItemsFresh()
+	if !fresh || len(items) == 0 {
+		s.refreshRegistryCache()
+		items, _ = s.cachedItemsFresh()
 	}
-	return res
+	return s.enrichItems(items), nil
 }
 
 func (s *Server) broadcastRegistry() {
@@ -298,33 +587,30 @@ func (s *Server) broadcastRegistry() {
 		s.refreshRegistryCache()
 		items, _ = s.cachedItemsFresh()
 	}
-	data, err := json.Marshal(s.enrichItems(items))
+	items = s.enrichItems(items)
+	data, err := json.Marshal(items)
 	if err != nil {
 		s.logger.Error("registry marshal failed", "error", err)
 		return
 	}
 
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- SSEMessage{Data: data}:
-		default:
-		}
+	// Publishing under diffMu keeps broadcasts in the order the baseline moved,
+	// so clients never apply an older diff over a newer one.
+	s.diffMu.Lock()
+	evs := s.registryEventsLocked(items, data, time.Now())
+	for _, e := range evs {
+		s.broadcast(e)
+	}
+	s.diffMu.Unlock()
+	// The journal keeps its own diff and needs the full listing to compute it.
+	if len(evs) == 0 || evs[0].Type != broker.TypeRegistry {
+		s.journalRegistry(data)
 	}
 }
 
 func (s *Server) broadcastTick(remaining int) {
 	data := []byte(fmt.Sprintf(`{"seconds_remaining": %d}`, remaining))
-
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- SSEMessage{Event: "tick", Data: data}:
-		default:
-		}
-	}
+	s.broadcast(broker.Event{Type: broker.TypeTick, Data: data})
 }
 
 func (s *Server) broadcastStatusChange(id, status, title string) {
@@ -333,52 +619,44 @@ func (s *Server) broadcastStatusChange(id, status, title string) {
 		"status": status,
 		"title":  title,
 	}
-	data, err := json.Marshal(payload)
-	if err != nil {
+	if err := s.broadcastJSON(broker.TypeStatus, payload); err != nil {
 		s.logger.Error("status change marshal failed", "error", err)
-		return
-	}
-
-	s.clientsMu.Lock()
-	defer s.clientsMu.Unlock()
-	for clientChan := range s.clients {
-		select {
-		case clientChan <- SSEMessage{Event: "status", Data: data}:
-		default:
-		}
 	}
 }
 
+// triggerStateSnapshot marks the state dirty. The persistence loop writes the
+// snapshot current at flush time, so nothing is lost when signals coalesce.
 func (s *Server) triggerStateSnapshot() {
-	s.modeMu.RLock()
-	ps := s.snapshotStateLocked()
-	s.modeMu.RUnlock()
-
 	select {
-	case s.stateChan <- ps:
+	case s.stateDirty <- struct{}{}:
 	default:
-		s.logger.Warn("persistence channel full, dropping snapshot")
 	}
 }
 
-func (s *Server) snapshotStateLocked() persistentState {
-	statuses := make(map[string]string, len(s.statuses))
-	for k, v := range s.statuses {
-		statuses[k] = v
-	}
-	return persistentState{Mode: s.mode, Statuses: statuses}
+// isSimulating reports whether destructive actions are computed but not executed.
+func (s *Server) isSimulating() bool {
+	return s.currentMode() == "SIMULATE"
 }
 
-func (s *Server) isManualMode() bool {
-	s.modeMu.RLock()
-	defer s.modeMu.RUnlock()
-	return s.mode == "MANUAL"
+// isInteractiveMode reports whether the operator drives the registry by hand;
+// SIMULATE behaves like MANUAL except that nothing is deleted, and AIRGAP
+// queues deletes as plan proposals.
+func (s *Server) isInteractiveMode() bool {
+	mode := s.currentMode()
+	return mode == "MANUAL" || mode == "SIMULATE" || mode == "AIRGAP"
+}
+
+func validMode(mode string) bool {
+	switch mode {
+	case "AUTO", "MANUAL", "SIMULATE", "AIRGAP":
+		return true
+	default:
+		return false
+	}
 }
 
 func (s *Server) getItemTitle(id string) string {
-	s.registryCache.mu.RLock()
-	defer s.registryCache.mu.RUnlock()
-	for _, item := range s.registryCache.items {
+	for _, item := range s.registryCache.snapshot().items {
 		if item.ID == id {
 			return item.Title
 		}
@@ -386,98 +664,86 @@ func (s *Server) getItemTitle(id string) string {
 	return ""
 }
 
-func (s *Server) backfillKeepStatuses(items []workspace.RegistryItem) bool {
-	needSnapshot := false
-	s.modeMu.Lock()
-	var newItems []workspace.RegistryItem
-	for _, item := range items {
-		if item.Type != "keep" {
+// backfillDefaultStatuses assigns the default status to items that have none.
+func (s *Server) backfillDefaultStatuses(items []workspace.RegistryItem) bool {
+	// The status enricher resolves defaults; do it before taking the writer
+	// lock since rules may be slow.
+	current := s.currentStatuses()
+	defaults := make(map[string]string)
+	for _, item := range s.enrichItems(items) {
+		if _, exists := current[item.ID]; exists {
 			continue
 		}
-		if _, exists := s.statuses[item.ID]; exists {
-			continue
+		if item.Status != "" {
+			defaults[item.ID] = item.Status
 		}
-		s.statuses[item.ID] = "Pending"
-		needSnapshot = true
-		newItems = append(newItems, item)
 	}
-	s.modeMu.Unlock()
-
-	// Broadcast telemetry for new notes initialized to Pending
-	for _, item := range newItems {
-		s.broadcastStatusChange(item.ID, "Pending", item.Title)
+	if len(defaults) == 0 {
+		return false
 	}
 
-	return needSnapshot
-}
-
-// cleanupStaleStatuses removes statuses for keep notes that no longer exist
-func (s *Server) cleanupStaleStatuses(items []workspace.RegistryItem) bool {
-	// Build a set of current keep note IDs
-	keepIDs := make(map[string]bool)
-	for _, item := range items {
-		if item.Type == "keep" {
-			keepIDs[item.ID] = true
+	var newItems []workspace.RegistryItem
+	_, needSnapshot := s.updateStatuses(func(next statusSet) bool {
+		newItems = nil
+		for _, item := range items {
+			status, ok := defaults[item.ID]
+			if !ok {
+				continue
+			}
+			if _, exists := next[item.ID]; exists {
+				continue
+			}
+			next[item.ID] = status
+			newItems = append(newItems, item)
 		}
-	}
+		return len(newItems) > 0
+	})
 
-	needSnapshot := false
-	s.modeMu.Lock()
-	for id := range s.statuses {
-		// If this status is for a keep note that no longer exists, remove it
-		if !keepIDs[id] {
-			delete(s.statuses, id)
-			needSnapshot = true
-			s.logger.Info("removed stale status", "id", id)
-		}
+	// Broadcast telemetry for items initialized to their default
+	for _, item := range newItems {
+		s.broadcastStatusChange(item.ID, defaults[item.ID], item.Title)
 	}
-	s.modeMu.Unlock()
+
 	return needSnapshot
 }
 
 func (s *Server) ensureStatusDefault(id, defaultStatus string) (string, bool) {
-	s.modeMu.Lock()
-	defer s.modeMu.Unlock()
-
-	if status, ok := s.statuses[id]; ok {
-		return status, false
-	}
-
-	s.statuses[id] = defaultStatus
-	return defaultStatus, true
+	st, created := s.updateStatuses(func(next statusSet) bool {
+		if _, ok := next[id]; ok || defaultStatus == "" {
+			return false
+		}
+		next[id] = defaultStatus
+		return true
+	})
+	return st[id], created
 }
 
 func (s *Server) ensureKeepNoteCached(id, title string) bool {
 	if id == "" {
 		return false
 	}
-
-	status, created := s.ensureStatusDefault(id, "Pending")
-	needSnapshot := created
-	added := false
-	item := workspace.RegistryItem{
+	return s.ensureItemCached(workspace.RegistryItem{
 		ID:      id,
 		Type:    "keep",
 		Title:   sanitizeNoteTitle(title),
 		Snippet: "Google Keep Note",
-		Status:  status,
-	}
+	})
+}
 
-	s.registryCache.mu.Lock()
-	replaced := false
-	for i := range s.registryCache.items {
-		if s.registryCache.items[i].ID == id {
-			s.registryCache.items[i] = item
-			replaced = true
-			break
-		}
-	}
-	if !replaced {
-		s.registryCache.items = append(s.registryCache.items, item)
-		added = true
-	}
-	s.registryCache.expiresAt = time.Now().Add(cacheTTL)
-	s.registryCache.mu.Unlock()
+// ensureItemCached adds an item the registry listing may not show yet, such as
+// one Axis just created, and reports whether it was new to the cache.
+func (s *Server) ensureItemCached(item workspace.RegistryItem) bool {
+	added := false
+	id := item.ID
+	status, created := s.ensureStatusDefault(id, s.enrichItems([]workspace.RegistryItem{item})[0].Status)
+	needSnapshot := created
+	item.Status = status
+
+	s.registryCache.update(func(cur *registrySnapshot) *registrySnapshot {
+		items := mergeItems(cur.items, []workspace.RegistryItem{item}, nil)
+		added = len(items) > len(cur.items)
+		return &registrySnapshot{items: items, expiresAt: time.Now().Add(cacheTTL), loaded: cur.loaded}
+	})
 
 	if needSnapshot {
 		s.triggerStateSnapshot()
@@ -503,10 +769,21 @@ func truthyParam(v string) bool {
 	}
 }
 
+// handleNotes serves /api/notes, the first page of note summaries. ?trashed=
+// lists only trashed (true) or untrashed (false) notes.
 func (s *Server) handleNotes(w http.ResponseWriter, r *http.Request) {
-	notes, err := s.ws.ListNotes()
+	var opts workspace.ListNotesOptions
+	if raw := r.URL.Query().Get("trashed"); raw != "" {
+		trashed, err := strconv.ParseBool(raw)
+		if err != nil {
+			apiError(w, fmt.Sprintf("invalid trashed %q (want true or false)", raw), http.StatusBadRequest)
+			return
+		}
+		opts.Trashed = &trashed
+	}
+	notes, err := s.wsFor(r.Context()).ListNotes(r.Context(), opts)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, err, http.StatusInternalServerError)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -516,48 +793,59 @@ func (s *Server) handleNotes(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleNoteDetail(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	note, err := s.ws.GetNote(context.Background(), id)
+	note, err := s.wsFor(r.Context()).GetNote(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	if note != nil {
-		added := s.ensureKeepNoteCached(note.Name, note.Title)
-		if added {
-			s.broadcastRegistry()
-		}
+	if note == nil {
+		apiError(w, "note not found", http.StatusNotFound)
+		return
+	}
+	if _, ok := subjectFrom(r.Context()); ok {
+		// Another user's note stays out of the registry.
+	} else if added := s.ensureKeepNoteCached(note.Name, note.Title); added {
+		s.broadcastRegistry()
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(note); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	writeWithExtras(w, note, map[string]any{"stats": s.noteStats(note)})
+}
+
+func (s *Server) handleNotePermissions(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
+
+	perms, err := s.ws.ListNotePermissions(r.Context(), id)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(perms)
 }
 
 func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	s.modeMu.RLock()
-	currentMode := s.mode
-	s.modeMu.RUnlock()
-
-	if currentMode != "MANUAL" {
-		http.Error(w, "delete requires MANUAL mode", http.StatusForbidden)
+	if !s.isInteractiveMode() {
+		apiError(w, "delete requires MANUAL, SIMULATE or AIRGAP mode", http.StatusForbidden)
 		return
 	}
 
-	if err := s.ws.DeleteNote(context.Background(), id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := s.deleteRegistryItem(r.Context(), s.registryItem(id, "keep")); err != nil {
+		writeAPIError(w, err, deleteErrorStatus(err))
 		return
 	}
 
@@ -569,30 +857,178 @@ func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleMode(w http.ResponseWriter, r *http.Request) {
 	newMode := r.URL.Query().Get("set")
 
-	s.modeMu.Lock()
-	if newMode == "" {
-		mode := s.mode
-		s.modeMu.Unlock()
+	if newMode == "" && r.Method == http.MethodGet {
+		mode := s.currentMode()
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(ModeResponse{Mode: mode})
 		return
 	}
 
-	if newMode != "AUTO" && newMode != "MANUAL" {
-		s.modeMu.Unlock()
-		http.Error(w, "invalid mode", http.StatusBadRequest)
+	if err := s.setMode(r.Context(), newMode); err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
 		return
 	}
-	s.mode = newMode
-	s.modeMu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+// setMode validates and applies an operating mode, then schedules persistence.
+func (s *Server) setMode(ctx context.Context, mode string) error {
+	if !validMode(mode) {
+		return fmt.Errorf("invalid mode")
+	}
+	if err := s.checkModeChange(mode); err != nil {
+		return err
+	}
+	previous := s.swapMode(mode)
 
 	s.triggerStateSnapshot()
-	w.WriteHeader(http.StatusOK)
+	if previous != mode {
+		s.publishMode(mode)
+		s.relayJSON(relayMode, ModeResponse{Mode: mode})
+		s.events.Emit(events.Event{
+			Type:  events.TypeModeChanged,
+			Actor: actorFrom(ctx),
+			Data:  map[string]any{"from": previous, "to": mode},
+		})
+	}
+	return nil
+}
+
+// publishMode tells this instance's clients the mode changed. Other instances
+// publish it when the relayed change reaches them.
+func (s *Server) publishMode(mode string) {
+	if err := s.hub.PublishJSON(broker.TypeMode, ModeResponse{Mode: mode}); err != nil {
+		s.logger.Error("mode change marshal failed", "error", err)
+	}
+}
+
+// deleteRegistryItem removes an item through the API matching its type. Every
+// destructive path funnels through here so each attempt is audited and events
+// are emitted consistently.
+func (s *Server) deleteRegistryItem(ctx context.Context, item workspace.RegistryItem) error {
+	actor, mode := actorFrom(ctx), s.currentMode()
+	err := s.checkProtected(item)
+	if err == nil && mode == "SIMULATE" {
+		s.simulateDeletion(ctx, item, actor)
+		return nil
+	}
+	if err == nil && mode == "AIRGAP" {
+		return s.proposeDeletion(ctx, item, actor)
+	}
+	var charge *budgetCharge
+	if err == nil {
+		charge, err = s.chargeBudgets(ctx, item)
+	}
+	var backupRef string
+	if err == nil {
+		if backupRef, err = s.backupItem(ctx, item); err == nil {
+			err = s.purgeItem(ctx, item)
+		}
+		if err != nil {
+			s.refundBudgets(charge)
+		}
+	}
+
+	s.recordDeletion(ctx, item, actor, mode, backupRef, err)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{"type": item.Type, "title": item.Title, "mode": mode}
+	if backupRef != "" {
+		data["backup"] = backupRef
+	}
+	if charge != nil {
+		// Read back by restoreBudgetUsage after a restart.
+		data["budgets"], data["bytes"] = charge.budgets, charge.bytes
+	}
+	s.events.Emit(events.Event{
+		Type:    events.TypeItemDeleted,
+		Actor:   actor,
+		Subject: item.ID,
+		Data:    data,
+	})
+	s.logDeletedNote(item)
+	return nil
+}
+
+func (s *Server) purgeItem(ctx context.Context, item workspace.RegistryItem) error {
+	switch item.Type {
+	case "keep":
+		return s.ws.DeleteNote(ctx, item.ID)
+	case "doc":
+		return s.ws.DeleteDoc(ctx, item.ID)
+	case "sheet":
+		return s.ws.DeleteSheet(ctx, item.ID)
+	default:
+		return fmt.Errorf("unsupported item type %q", item.Type)
+	}
+}
+
+// setItemStatus checks a status change against the taxonomy, records it in
+// the item's transition history and notifies clients and publishers.
+func (s *Server) setItemStatus(ctx context.Context, id, status, title string) error {
+	var from string
+	var err error
+	s.updateStatuses(func(next statusSet) bool {
+		from = next[id]
+		if status, err = s.rules.Statuses().Check(from, status); err != nil {
+			return false
+		}
+		next[id] = status
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	actor := actorFrom(ctx)
+	if from != status {
+		if err := s.store.RecordTransition(ctx, store.Transition{ItemID: id, From: from, To: status, Actor: actor}); err != nil {
+			s.logger.ErrorContext(ctx, "failed to record status transition", "id", id, "error", err)
+		}
+	}
+	s.relayJSON(relayStatus, map[string]string{"id": id, "status": status})
+	if title != "" {
+		s.broadcastStatusChange(id, status, title)
+	}
+	s.triggerStateSnapshot()
+	s.events.Emit(events.Event{
+		Type:    events.TypeStatusChanged,
+		Actor:   actor,
+		Subject: id,
+		Data:    map[string]any{"status": status, "from": from, "title": title},
+	})
+	return nil
+}
+
+// registryItem resolves a cached registry item, falling back to a bare reference.
+func (s *Server) registryItem(id, itemType string) workspace.RegistryItem {
+	for _, item := range s.registryCache.snapshot().items {
+		if item.ID == id {
+			return item
+		}
+	}
+	return workspace.RegistryItem{ID: id, Type: itemType}
+}
+
+type actorKey struct{}
+
+// withActor attributes actions performed under ctx to the given actor.
+func withActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return "operator"
 }
 
 func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
 	if s.user == nil {
-		http.Error(w, "user profile unavailable", http.StatusServiceUnavailable)
+		apiError(w, "user profile unavailable", http.StatusServiceUnavailable)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -600,7 +1036,21 @@ func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleRegistry(w http.ResponseWriter, r *http.Request) {
-	manual := s.isManualMode()
+	query, err := parseRegistryQuery(r)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	if _, ok := subjectFrom(r.Context()); ok {
+		items, err := s.subjectRegistry(r.Context())
+		if err != nil {
+			writeAPIError(w, err, http.StatusBadGateway)
+			return
+		}
+		writeRegistryPage(w, r, query.apply(items))
+		return
+	}
+	manual := s.isInteractiveMode()
 	forceRefresh := manual && truthyParam(r.URL.Query().Get("refresh"))
 	if forceRefresh {
 		s.refreshRegistryCache()
@@ -613,11 +1063,10 @@ func (s *Server) handleRegistry(w http.ResponseWriter, r *http.Request) {
 		items, _ = s.cachedItemsFresh()
 	}
 
-	enriched := s.enrichItems(items)
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(enriched); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if degraded := s.sources.degraded(); len(degraded) > 0 {
+		w.Header().Set("X-Degraded-Sources", strings.Join(degraded, ","))
 	}
+	writeRegistryPage(w, r, query.apply(filterExternal(r, s.enrichItems(items))))
 }
 
 func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
@@ -625,21 +1074,15 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
 
 	if id == "" || status == "" {
-		http.Error(w, "missing id or status", http.StatusBadRequest)
+		apiError(w, "missing id or status", http.StatusBadRequest)
 		return
 	}
 
-	s.modeMu.Lock()
-	s.statuses[id] = status
-	s.modeMu.Unlock()
-
 	// Look up the note title for telemetry
-	title := s.getItemTitle(id)
-	if title != "" {
-		s.broadcastStatusChange(id, status, title)
+	if err := s.setItemStatus(r.Context(), id, status, s.getItemTitle(id)); err != nil {
+		writeAPIError(w, err, statusErrorCode(err))
+		return
 	}
-
-	s.triggerStateSnapshot()
 	s.broadcastRegistry()
 	w.WriteHeader(http.StatusOK)
 }
@@ -647,35 +1090,38 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleGetSheet(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	sheet, err := s.ws.GetSheet(id)
+	sheet, err := s.ws.GetSheet(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(sheet); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	extras := map[string]any{}
+	if profile, err := s.sheetProfile(r.Context(), s.registryItem(id, "sheet")); err != nil {
+		s.logger.WarnContext(r.Context(), "sheet profile unavailable", "id", id, "error", err)
+	} else {
+		extras["profile"] = profile
 	}
+	writeWithExtras(w, sheet, extras)
 }
 
 func (s *Server) handleDeleteSheet(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.ws.DeleteSheet(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := s.deleteRegistryItem(r.Context(), s.registryItem(id, "sheet")); err != nil {
+		writeAPIError(w, err, deleteErrorStatus(err))
 		return
 	}
 
-	if s.isManualMode() {
+	if s.isInteractiveMode() {
 		s.refreshRegistryCache()
 		s.broadcastRegistry()
 	} else {
@@ -687,35 +1133,66 @@ func (s *Server) handleDeleteSheet(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleGetDoc(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	doc, err := s.ws.GetDoc(id)
+	doc, err := s.ws.GetDoc(r.Context(), id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(doc); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	// The Docs API has no edit time; Drive's comes from the registry listing.
+	var modified time.Time
+	if item := s.registryItem(id, "doc"); item.Modified != nil {
+		modified = *item.Modified
+	}
+	st := textstats.Compute(workspace.DocRawText(doc), modified, time.Now())
+	s.cacheStats(id, modified, st)
+	writeWithExtras(w, doc, map[string]any{"stats": st})
+}
+
+// handleDocText serves a Doc as plain text, or as Markdown with
+// ?format=markdown.
+func (s *Server) handleDocText(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
 	}
+	format, err := workspace.ParseDocFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	text, err := s.ws.GetDocText(r.Context(), id, format)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	contentType := "text/plain; charset=utf-8"
+	if format == workspace.DocFormatMarkdown {
+		contentType = "text/markdown; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	io.WriteString(w, text)
 }
 
 func (s *Server) handleDeleteDoc(w http.ResponseWriter, r *http.Request) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
-		http.Error(w, "missing id", http.StatusBadRequest)
+		apiError(w, "missing id", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.ws.DeleteDoc(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := s.deleteRegistryItem(r.Context(), s.registryItem(id, "doc")); err != nil {
+		writeAPIError(w, err, deleteErrorStatus(err))
 		return
 	}
 
-	if s.isManualMode() {
+	if s.isInteractiveMode() {
 		s.refreshRegistryCache()
 		s.broadcastRegistry()
 	} else {
@@ -732,31 +1209,54 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
-		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		apiError(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
 
-	msgChan := make(chan SSEMessage, 10)
-	s.clientsMu.Lock()
-	s.clients[msgChan] = true
-	s.clientsMu.Unlock()
-
-	defer func() {
-		s.clientsMu.Lock()
-		delete(s.clients, msgChan)
-		s.clientsMu.Unlock()
-		close(msgChan)
-	}()
+	// A reconnecting EventSource sends the ID of the last event it received;
+	// what it missed since is replayed before live events.
+	after, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub, backlog, complete, initial := s.subscribeClient(after)
+	defer s.hub.Unsubscribe(sub)
 
-	go s.sendInitialRegistrySnapshot(msgChan)
+	var last uint64
+	write := func(msg broker.Event) {
+		if !streamVisible(r, msg) {
+			return
+		}
+		if msg.ID != 0 {
+			if msg.ID <= last {
+				return
+			}
+			last = msg.ID
+			fmt.Fprintf(w, "id: %d\n", msg.ID)
+		}
+		// Registry snapshots are the unnamed default event.
+		if msg.Type != broker.TypeRegistry {
+			fmt.Fprintf(w, "event: %s\n", msg.Type)
+		}
+		fmt.Fprintf(w, "data: %s\n\n", msg.Data)
+	}
+	// The connect events, such as the registry snapshot, describe the state
+	// after the backlog and before anything on the channel.
+	for _, msg := range append(backlog, initial...) {
+		write(msg)
+	}
+	flusher.Flush()
+	if after != 0 {
+		s.logger.InfoContext(r.Context(), "sse client resumed", "remote", r.RemoteAddr, "last_event_id", after, "replayed", len(backlog), "complete", complete)
+	}
 
 	for {
 		select {
-		case msg := <-msgChan:
-			if msg.Event != "" {
-				fmt.Fprintf(w, "event: %s\n", msg.Event)
+		case msg, ok := <-sub.C:
+			if !ok {
+				if !s.hub.Closed() {
+					s.logger.WarnContext(r.Context(), "sse client dropped for falling behind", "remote", r.RemoteAddr, "missed", sub.Dropped())
+				}
+				return
 			}
-			fmt.Fprintf(w, "data: %s\n\n", msg.Data)
+			write(msg)
 			flusher.Flush()
 		case <-r.Context().Done():
 			return
@@ -764,26 +1264,6 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) sendInitialRegistrySnapshot(ch chan<- SSEMessage) {
-	items, fresh := s.cachedItemsFresh()
-	if !fresh || len(items) == 0 {
-		s.refreshRegistryCache()
-		items, _ = s.cachedItemsFresh()
-	}
-	if len(items) == 0 {
-		return
-	}
-	data, err := json.Marshal(s.enrichItems(items))
-	if err != nil {
-		s.logger.Error("initial snapshot marshal failed", "error", err)
-		return
-	}
-	select {
-	case ch <- SSEMessage{Data: data}:
-	default:
-	}
-}
-
 func (s *Server) refreshAndBroadcast() {
 	s.refreshRegistryCache()
 	s.broadcastRegistry()
@@ -0,0 +1,130 @@
+/*
+File: internal/server/sheetvalues.go
+Description: Cell data for Sheets. GET /api/sheets/values reads an A1 range and
+PUT writes one, so the UI can show and edit cells rather than only spreadsheet
+metadata. Writes honor policy protection; in SIMULATE mode they are reported as
+"would update" events and in AIRGAP mode queued as plan proposals instead of
+being written.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/plan"
+)
+
+// maxSheetValuesBody bounds a PUT /api/sheets/values body.
+const maxSheetValuesBody = 4 << 20
+
+// SheetValuesRequest is the body of PUT /api/sheets/values. Input "raw" stores
+// strings exactly as given; the default parses them as if typed.
+type SheetValuesRequest struct {
+	Values [][]any `json:"values"`
+	Input  string  `json:"input,omitempty"`
+}
+
+// SheetValuesDeferred answers a write made in SIMULATE or AIRGAP mode.
+type SheetValuesDeferred struct {
+	Simulated    bool   `json:"simulated,omitempty"`
+	Proposed     bool   `json:"proposed,omitempty"`
+	UpdatedRange string `json:"updatedRange"`
+	UpdatedCells int    `json:"updatedCells"`
+}
+
+// handleSheetValues serves ?id=&range= (e.g. range=Sheet1!A1:D20).
+func (s *Server) handleSheetValues(w http.ResponseWriter, r *http.Request) {
+	id, a1Range := r.URL.Query().Get("id"), r.URL.Query().Get("range")
+	if id == "" || a1Range == "" {
+		apiError(w, "missing id or range", http.StatusBadRequest)
+		return
+	}
+	values, err := s.ws.GetSheetValues(id, a1Range)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(values)
+}
+
+func (s *Server) handleSheetValuesUpdate(w http.ResponseWriter, r *http.Request) {
+	id, a1Range := r.URL.Query().Get("id"), r.URL.Query().Get("range")
+	if id == "" || a1Range == "" {
+		apiError(w, "missing id or range", http.StatusBadRequest)
+		return
+	}
+	var req SheetValuesRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSheetValuesBody)).Decode(&req); err != nil {
+		apiError(w, "invalid values: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Input != "" && req.Input != "raw" && req.Input != "user_entered" {
+		apiError(w, "input must be raw or user_entered", http.StatusBadRequest)
+		return
+	}
+	cells := 0
+	for _, row := range req.Values {
+		cells += len(row)
+	}
+
+	item := s.registryItem(id, "sheet")
+	if err := s.checkProtected(item); err != nil {
+		writeAPIError(w, err, deleteErrorStatus(err))
+		return
+	}
+	actor, mode := actorFrom(r.Context()), s.currentMode()
+	data := map[string]any{"title": item.Title, "range": a1Range, "cells": cells, "mode": mode}
+
+	if mode == "SIMULATE" {
+		s.logger.InfoContext(r.Context(), "would update sheet", "id", id, "range", a1Range, "cells", cells, "actor", actor)
+		s.events.Emit(events.Event{Type: events.TypeSheetWouldUpdate, Actor: actor, Subject: id, Data: data})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SheetValuesDeferred{Simulated: true, UpdatedRange: a1Range, UpdatedCells: cells})
+		return
+	}
+	if mode == "AIRGAP" {
+		err := s.propose(r.Context(), plan.Action{
+			Op:       plan.OpSheetUpdate,
+			ItemID:   id,
+			ItemType: "sheet",
+			Title:    item.Title,
+			Modified: item.Modified,
+			Range:    a1Range,
+			Values:   req.Values,
+			Raw:      req.Input == "raw",
+			Actor:    actor,
+			Proposed: time.Now().UTC(),
+		})
+		if err != nil {
+			writeAPIError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(SheetValuesDeferred{Proposed: true, UpdatedRange: a1Range, UpdatedCells: cells})
+		return
+	}
+
+	backupRef, err := s.backupItem(r.Context(), item)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if backupRef != "" {
+		data["backup"] = backupRef
+	}
+	resp, err := s.ws.UpdateSheetValues(r.Context(), id, a1Range, req.Values, req.Input == "raw")
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.logger.InfoContext(r.Context(), "sheet updated", "id", id, "range", resp.UpdatedRange, "cells", resp.UpdatedCells, "actor", actor)
+	data["range"], data["cells"] = resp.UpdatedRange, resp.UpdatedCells
+	s.events.Emit(events.Event{Type: events.TypeSheetUpdated, Actor: actor, Subject: id, Data: data})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,53 @@
+/*
+File: internal/server/simulate.go
+Description: SIMULATE mode support. Deletes that pass policy checks are recorded,
+logged and broadcast as "would delete" events instead of being executed, so
+operators can validate policies and playbooks before enabling them.
+*/
+package server
+
+import (
+	"context"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/events"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+func (s *Server) simulateDeletion(ctx context.Context, item workspace.RegistryItem, actor string) {
+	d := store.Deletion{
+		Time:     time.Now().UTC(),
+		ItemID:   item.ID,
+		ItemType: item.Type,
+		Title:    item.Title,
+		Actor:    actor,
+		Mode:     "SIMULATE",
+		Outcome:  store.OutcomeSimulated,
+	}
+	if err := s.store.RecordDeletion(context.WithoutCancel(ctx), d); err != nil {
+		s.logger.ErrorContext(ctx, "audit write failed", "id", item.ID, "outcome", d.Outcome, "error", err)
+	}
+
+	s.logger.InfoContext(ctx, "would delete", "id", item.ID, "type", item.Type, "title", item.Title, "actor", actor)
+	s.broadcastSimulated(item, actor)
+	s.events.Emit(events.Event{
+		Type:    events.TypeItemWouldDelete,
+		Actor:   actor,
+		Subject: item.ID,
+		Data:    map[string]any{"type": item.Type, "title": item.Title, "mode": "SIMULATE"},
+	})
+}
+
+func (s *Server) broadcastSimulated(item workspace.RegistryItem, actor string) {
+	err := s.broadcastJSON(broker.TypeSimulated, map[string]string{
+		"id":    item.ID,
+		"type":  item.Type,
+		"title": item.Title,
+		"actor": actor,
+	})
+	if err != nil {
+		s.logger.Error("simulation marshal failed", "error", err)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+File: internal/server/snapshot.go
+Description: Copy-on-write views of operational state. Mode, item statuses, tags,
+deletion deadlines and the registry cache are published independently as immutable values: readers
+load the current one without locking and writers build a replacement, so
+broadcasts and registry reads never wait on writers, writers of one never wait
+on another, and persistence works from a settled copy.
+*/
+package server
+
+import (
+	"time"
+
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+// statusSet is an immutable map of item statuses. It must not be modified once
+// published; use updateStatuses.
+type statusSet map[string]string
+
+// currentMode returns the operating mode. Mode is kept apart from statuses, so
+// mode reads and changes never wait on status writers.
+func (s *Server) currentMode() string {
+	return s.mode.Load().(string)
+}
+
+// swapMode sets the operating mode and returns the previous one.
+func (s *Server) swapMode(mode string) string {
+	return s.mode.Swap(mode).(string)
+}
+
+// currentStatuses returns the published status set.
+func (s *Server) currentStatuses() statusSet {
+	return *s.statuses.Load()
+}
+
+// updateStatuses applies fn to a private copy of the statuses and publishes the
+// copy when fn reports a change. Writers are serialized; readers never wait.
+func (s *Server) updateStatuses(fn func(next statusSet) bool) (statusSet, bool) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	cur := *s.statuses.Load()
+	next := make(statusSet, len(cur)+1)
+	for id, status := range cur {
+		next[id] = status
+	}
+	if !fn(next) {
+		return cur, false
+	}
+	s.statuses.Store(&next)
+	return next, true
+}
+
+// tagSet is an immutable map of the tags Axis stores itself, by item ID.
+type tagSet map[string][]string
+
+// currentTags returns the published tag set.
+func (s *Server) currentTags() tagSet {
+	return *s.tags.Load()
+}
+
+// setTags publishes a copy of the tag set with id's tags replaced; empty tags
+// remove the entry. It shares statusMu with status writers, which are rare.
+func (s *Server) setTags(id string, tags []string) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	cur := *s.tags.Load()
+	next := make(tagSet, len(cur)+1)
+	for k, v := range cur {
+		next[k] = v
+	}
+	if len(tags) == 0 {
+		delete(next, id)
+	} else {
+		next[id] = tags
+	}
+	s.tags.Store(&next)
+}
+
+// deadlineSet is an immutable map of scheduled deletion times, by item ID.
+type deadlineSet map[string]time.Time
+
+// currentDeadlines returns the published deadline set.
+func (s *Server) currentDeadlines() deadlineSet {
+	return *s.deadlines.Load()
+}
+
+// setDeadline publishes a copy of the deadline set with id's deadline set to
+// at; the zero time removes it. It reports whether the set changed.
+func (s *Server) setDeadline(id string, at time.Time) bool {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	cur := *s.deadlines.Load()
+	prev, ok := cur[id]
+	if (at.IsZero() && !ok) || (ok && prev.Equal(at)) {
+		return false
+	}
+	next := make(deadlineSet, len(cur)+1)
+	for k, v := range cur {
+		next[k] = v
+	}
+	if at.IsZero() {
+		delete(next, id)
+	} else {
+		next[id] = at
+	}
+	s.deadlines.Store(&next)
+	return true
+}
+
+// storeState captures the state for persistence. The status, tag and deadline
+// maps are shared, which is safe because neither side modifies them.
+func (s *Server) storeState() store.State {
+	return store.State{Mode: s.currentMode(), Statuses: s.currentStatuses(), Tags: s.currentTags(), Deadlines: s.currentDeadlines()}
+}
+
+// registrySnapshot is an immutable registry listing. Items must not be modified
+// in place; the writers in this package build a new slice instead.
+type registrySnapshot struct {
+	items     []workspace.RegistryItem
+	expiresAt time.Time
+	loaded    bool // every item type has been listed at least once
+}
+
+// snapshot returns the published registry snapshot, never nil.
+func (c *RegistryCache) snapshot() *registrySnapshot {
+	if snap := c.snap.Load(); snap != nil {
+		return snap
+	}
+	return &registrySnapshot{}
+}
+
+// update publishes the result of fn applied to the current snapshot. Callers
+// hold no lock; concurrent updates are serialized.
+func (c *RegistryCache) update(fn func(cur *registrySnapshot) *registrySnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snap.Store(fn(c.snapshot()))
+}
@@ -0,0 +1,96 @@
+/*
+File: internal/server/snapshot_test.go
+Description: Status reads against concurrent writers, through the copy-on-write
+status set and, for comparison, a map behind a read-write mutex.
+*/
+package server
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const benchStatuses = 1000
+
+func benchStatusIDs() []string {
+	ids := make([]string, benchStatuses)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("item-%d", i)
+	}
+	return ids
+}
+
+// writeUntil calls write in a loop until stop is closed.
+func writeUntil(stop <-chan struct{}, wg *sync.WaitGroup, write func(i int)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				write(i)
+			}
+		}
+	}()
+}
+
+func BenchmarkStatusReads(b *testing.B) {
+	ids := benchStatusIDs()
+
+	b.Run("copy-on-write", func(b *testing.B) {
+		s := &Server{}
+		initial := make(statusSet, len(ids))
+		for _, id := range ids {
+			initial[id] = "REVIEW"
+		}
+		s.statuses.Store(&initial)
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		writeUntil(stop, &wg, func(i int) {
+			s.updateStatuses(func(next statusSet) bool {
+				next[ids[i%len(ids)]] = "DELETE"
+				return true
+			})
+		})
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				_ = s.currentStatuses()[ids[i%len(ids)]]
+			}
+		})
+		b.StopTimer()
+		close(stop)
+		wg.Wait()
+	})
+
+	b.Run("rwmutex", func(b *testing.B) {
+		var mu sync.RWMutex
+		statuses := make(map[string]string, len(ids))
+		for _, id := range ids {
+			statuses[id] = "REVIEW"
+		}
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		writeUntil(stop, &wg, func(i int) {
+			mu.Lock()
+			statuses[ids[i%len(ids)]] = "DELETE"
+			mu.Unlock()
+		})
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				mu.RLock()
+				_ = statuses[ids[i%len(ids)]]
+				mu.RUnlock()
+			}
+		})
+		b.StopTimer()
+		close(stop)
+		wg.Wait()
+	})
+}
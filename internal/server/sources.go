@@ -0,0 +1,102 @@
+/*
+File: internal/server/sources.go
+Description: Registry source health. Each item type (keep, doc, sheet) is a
+source that can fail on its own; the registry then serves the other sources
+plus the failed one's last good listing. Health changes are broadcast to SSE
+and WebSocket clients as "sources" events and are available at
+GET /api/registry/sources.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/broker"
+	"axis/internal/workspace"
+)
+
+// SourceStatus is the health of one registry source.
+type SourceStatus struct {
+	Type  string `json:"type"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// Since is when the source entered its current state.
+	Since  time.Time  `json:"since"`
+	LastOK *time.Time `json:"last_ok,omitempty"`
+}
+
+// sourceHealth tracks SourceStatus per item type. Types not fetched yet are
+// absent.
+type sourceHealth struct {
+	mu     sync.Mutex
+	byType map[string]SourceStatus
+}
+
+// record applies one fetch's outcome and reports whether any source changed
+// between healthy and failing.
+func (h *sourceHealth) record(fetched []string, failed map[string]error) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.byType == nil {
+		h.byType = make(map[string]SourceStatus)
+	}
+	now := time.Now()
+	changed := false
+	for _, t := range fetched {
+		t = strings.TrimSuffix(t, "~")
+		prev, seen := h.byType[t]
+		next := SourceStatus{Type: t, OK: true, Since: prev.Since, LastOK: &now}
+		if !seen || !prev.OK {
+			next.Since, changed = now, true
+		}
+		h.byType[t] = next
+	}
+	for t, err := range failed {
+		prev, seen := h.byType[t]
+		next := SourceStatus{Type: t, Error: err.Error(), Since: prev.Since, LastOK: prev.LastOK}
+		if !seen || prev.OK {
+			next.Since, changed = now, true
+		}
+		h.byType[t] = next
+	}
+	return changed
+}
+
+// list returns the known statuses in display order.
+func (h *sourceHealth) list() []SourceStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	statuses := []SourceStatus{}
+	for _, t := range workspace.ItemTypes {
+		if st, ok := h.byType[t]; ok {
+			statuses = append(statuses, st)
+		}
+	}
+	return statuses
+}
+
+// degraded lists the types whose last fetch failed.
+func (h *sourceHealth) degraded() []string {
+	var types []string
+	for _, st := range h.list() {
+		if !st.OK {
+			types = append(types, st.Type)
+		}
+	}
+	return types
+}
+
+func (s *Server) broadcastSources() {
+	if err := s.broadcastJSON(broker.TypeSources, s.sources.list()); err != nil {
+		s.logger.Error("source status marshal failed", "error", err)
+	}
+}
+
+func (s *Server) handleRegistrySources(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.sources.list())
+}
@@ -0,0 +1,143 @@
+/*
+File: internal/server/stats.go
+Description: Content statistics for Keep notes and Docs and data profiles for
+Sheets. Both are cached per item until its modification time changes and attached
+to the detail endpoints; note and doc stats also feed rules as content attributes.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"axis/internal/textstats"
+	"axis/internal/workspace"
+
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+type statsEntry struct {
+	modified time.Time
+	stats    textstats.Stats
+}
+
+func (s *Server) cacheStats(id string, modified time.Time, st textstats.Stats) {
+	s.statsMu.Lock()
+	s.statsCache[id] = statsEntry{modified: modified, stats: st}
+	s.statsMu.Unlock()
+}
+
+func (s *Server) noteStats(note *keepapi.Note) textstats.Stats {
+	var modified time.Time
+	if t := parseAPITime(note.UpdateTime); t != nil {
+		modified = *t
+	}
+	st := textstats.Compute(workspace.NoteText(note.Body), modified, time.Now())
+	s.cacheStats(note.Name, modified, st)
+	return st
+}
+
+// contentStats returns cached stats for a note or doc, fetching the body when
+// the item changed since the last computation.
+func (s *Server) contentStats(ctx context.Context, item workspace.RegistryItem) (textstats.Stats, error) {
+	var modified time.Time
+	if item.Modified != nil {
+		modified = *item.Modified
+	}
+	s.statsMu.Lock()
+	entry, ok := s.statsCache[item.ID]
+	s.statsMu.Unlock()
+	if ok && !modified.IsZero() && entry.modified.Equal(modified) {
+		// Recency is relative to now, so refresh it on every read.
+		entry.stats.Recency = textstats.Recency(modified, time.Now())
+		return entry.stats, nil
+	}
+
+	switch item.Type {
+	case "keep":
+		note, err := s.ws.GetNote(ctx, item.ID)
+		if err != nil {
+			return textstats.Stats{}, err
+		}
+		return s.noteStats(note), nil
+	case "doc":
+		doc, err := s.ws.GetDoc(ctx, item.ID)
+		if err != nil {
+			return textstats.Stats{}, err
+		}
+		st := textstats.Compute(workspace.DocRawText(doc), modified, time.Now())
+		s.cacheStats(item.ID, modified, st)
+		return st, nil
+	default:
+		return textstats.Stats{}, fmt.Errorf("no text content for %s items", item.Type)
+	}
+}
+
+// writeWithExtras encodes an API object with additional top-level members. The
+// Google API types marshal themselves, so the object is re-encoded through a map.
+func writeWithExtras(w http.ResponseWriter, v any, extras map[string]any) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	for key, extra := range extras {
+		if fields[key], err = json.Marshal(extra); err != nil {
+			writeAPIError(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
+
+func parseAPITime(raw string) *time.Time {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+type sheetProfileEntry struct {
+	modified  time.Time
+	fetchedAt time.Time
+	profile   *workspace.SheetProfile
+}
+
+// sheetProfile returns the cached profile while the sheet is unchanged. Without
+// a known modification time the profile is reused for cacheTTL.
+func (s *Server) sheetProfile(ctx context.Context, item workspace.RegistryItem) (*workspace.SheetProfile, error) {
+	var modified time.Time
+	if item.Modified != nil {
+		modified = *item.Modified
+	}
+	s.statsMu.Lock()
+	entry, ok := s.sheetProfiles[item.ID]
+	s.statsMu.Unlock()
+	if ok {
+		if modified.IsZero() && time.Since(entry.fetchedAt) < cacheTTL {
+			return entry.profile, nil
+		}
+		if !modified.IsZero() && entry.modified.Equal(modified) {
+			return entry.profile, nil
+		}
+	}
+
+	profile, err := s.ws.ProfileSheet(ctx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.statsMu.Lock()
+	s.sheetProfiles[item.ID] = sheetProfileEntry{modified: modified, fetchedAt: time.Now(), profile: profile}
+	s.statsMu.Unlock()
+	return profile, nil
+}
@@ -0,0 +1,65 @@
+/*
+File: internal/server/statuses.go
+Description: Status taxonomy and transition history endpoints. Clients read the
+statuses and allowed changes to offer only valid ones, and an item's history
+lists every change made to its status.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"axis/internal/rules"
+	"axis/internal/store"
+)
+
+// StatusesResponse is the body of GET /api/statuses. Transitions lists, for
+// every status, the statuses it may change to.
+type StatusesResponse struct {
+	Values      []string            `json:"values"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// statusErrorCode is the HTTP status for a refused status change: 400 for a
+// status outside the taxonomy, 409 for a change it does not allow.
+func statusErrorCode(err error) int {
+	switch {
+	case errors.Is(err, rules.ErrTransition):
+		return http.StatusConflict
+	case errors.Is(err, rules.ErrUnknownStatus):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (s *Server) handleStatuses(w http.ResponseWriter, r *http.Request) {
+	tx := s.rules.Statuses()
+	resp := StatusesResponse{Values: tx.Values, Transitions: make(map[string][]string, len(tx.Values))}
+	for _, v := range tx.Values {
+		resp.Transitions[v] = tx.Next(v)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleStatusHistory serves GET /api/status/history?id=, oldest change first.
+func (s *Server) handleStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	list, err := s.store.ListTransitions(r.Context(), id)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []store.Transition{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
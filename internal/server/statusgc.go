@@ -0,0 +1,132 @@
+/*
+File: internal/server/statusgc.go
+Description: Stale status collection. Each full registry refresh reconciles the
+recorded statuses and deadlines with the registry: an ID that is no longer
+listed is marked missing, and once it has stayed missing for the grace period
+its status and deadline are removed. The grace period keeps a status through a
+source's brief outage or an item's round trip through trash. Missing times are
+kept in memory, so a restart starts the grace period over.
+*/
+package server
+
+import (
+	"sync"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+const defaultStatusGrace = 24 * time.Hour
+
+// WithStatusGrace sets how long an item may be missing from the registry
+// before its status and deadline are removed (default 24 hours).
+func WithStatusGrace(d time.Duration) Option {
+	return func(s *Server) { s.statusGrace = d }
+}
+
+// PruneReport describes the last stale status pass. Pending counts the
+// missing IDs still within the grace period.
+type PruneReport struct {
+	Time      time.Time `json:"time"`
+	Statuses  int       `json:"statuses"`
+	Deadlines int       `json:"deadlines"`
+	Pending   int       `json:"pending"`
+}
+
+// statusGC remembers since when each ID with a status or deadline has been
+// missing from the registry.
+type statusGC struct {
+	mu      sync.Mutex
+	missing map[string]time.Time
+	last    *PruneReport
+}
+
+// lastPrune returns the report of the last pass, nil before the first.
+func (g *statusGC) lastPrune() *PruneReport {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.last
+}
+
+// cleanupStaleStatuses removes the statuses and deadlines of items that have
+// been missing from items, the full registry, for the grace period, and
+// reports whether state changed.
+func (s *Server) cleanupStaleStatuses(items []workspace.RegistryItem) bool {
+	now := time.Now()
+	present := make(map[string]bool, len(items))
+	for _, item := range items {
+		present[item.ID] = true
+	}
+	statuses, deadlines := s.currentStatuses(), s.currentDeadlines()
+
+	gc := &s.statusGC
+	gc.mu.Lock()
+	if gc.missing == nil {
+		gc.missing = make(map[string]time.Time)
+	}
+	seen := make(map[string]bool)
+	expired := make(map[string]bool)
+	mark := func(id string) {
+		if present[id] || seen[id] {
+			return
+		}
+		seen[id] = true
+		since, ok := gc.missing[id]
+		if !ok {
+			gc.missing[id] = now
+			since = now
+		}
+		if now.Sub(since) >= s.statusGrace {
+			expired[id] = true
+		}
+	}
+	for id := range statuses {
+		mark(id)
+	}
+	for id := range deadlines {
+		mark(id)
+	}
+	// Forget IDs that came back or no longer have anything to remove.
+	for id := range gc.missing {
+		if !seen[id] || expired[id] {
+			delete(gc.missing, id)
+		}
+	}
+	report := &PruneReport{Time: now, Pending: len(gc.missing)}
+	gc.mu.Unlock()
+
+	var removed []string
+	_, changed := s.updateStatuses(func(next statusSet) bool {
+		removed = nil
+		for id := range next {
+			if expired[id] {
+				delete(next, id)
+				removed = append(removed, id)
+			}
+		}
+		return len(removed) > 0
+	})
+	for _, id := range removed {
+		s.logger.Debug("removed stale status", "id", id)
+	}
+	report.Statuses = len(removed)
+	for id := range deadlines {
+		if expired[id] && s.clearDeadline(id) {
+			s.logger.Debug("removed stale deadline", "id", id)
+			report.Deadlines++
+			changed = true
+		}
+	}
+
+	gc.mu.Lock()
+	gc.last = report
+	gc.mu.Unlock()
+	if report.Statuses > 0 || report.Deadlines > 0 {
+		s.logger.Info("stale statuses pruned", "statuses", report.Statuses, "deadlines", report.Deadlines, "pending", report.Pending, "grace", s.statusGrace)
+		s.events.Emit(events.Event{Type: events.TypeStatusesPruned, Actor: "poller", Data: map[string]any{
+			"statuses": report.Statuses, "deadlines": report.Deadlines, "pending": report.Pending,
+		}})
+	}
+	return changed
+}
@@ -0,0 +1,185 @@
+/*
+File: internal/server/storage.go
+Description: Domain storage reporting. Per-user storage comes from the Reports
+usage log, refreshed every few hours; GET /api/domain/storage lists it and,
+for one user, adds their live Drive figures. Users past the configured size or
+quota share are flagged, and so are the registry items they own.
+*/
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"axis/internal/workspace"
+)
+
+const (
+	storageRefreshInterval = 6 * time.Hour
+	storageTimeout         = 10 * time.Minute
+)
+
+// storageReading is one reading of every user's storage.
+type storageReading struct {
+	users map[string]workspace.UserStorage // by lowercased email
+	date  string                           // usage report date
+	at    time.Time
+}
+
+// StorageUser is one account's storage with its threshold check. QuotaUsed is
+// the percentage of the account's quota in use, when it has one.
+type StorageUser struct {
+	workspace.UserStorage
+	QuotaUsed float64 `json:"quota_used,omitempty"`
+	Over      bool    `json:"over"`
+}
+
+// StorageResponse is the body of GET /api/domain/storage. Live is the
+// requested user's current Drive storage.
+type StorageResponse struct {
+	Users          []StorageUser         `json:"users"`
+	Over           int                   `json:"over"`
+	Date           string                `json:"date"`
+	Computed       time.Time             `json:"computed"`
+	ThresholdBytes int64                 `json:"threshold_bytes,omitempty"`
+	QuotaPercent   float64               `json:"quota_percent,omitempty"`
+	Live           *workspace.DriveQuota `json:"live,omitempty"`
+	LiveError      string                `json:"live_error,omitempty"`
+}
+
+// WithStorageThresholds flags users storing more than maxBytes or using more
+// than quotaPercent of their quota; zero turns a check off.
+func WithStorageThresholds(maxBytes int64, quotaPercent float64) Option {
+	return func(s *Server) {
+		s.storageMaxBytes = maxBytes
+		s.storageQuotaPercent = quotaPercent
+	}
+}
+
+// storageUser checks u against the thresholds.
+func (s *Server) storageUser(u workspace.UserStorage) StorageUser {
+	su := StorageUser{UserStorage: u}
+	if u.QuotaBytes > 0 {
+		su.QuotaUsed = float64(u.UsedBytes) / float64(u.QuotaBytes) * 100
+	}
+	su.Over = (s.storageMaxBytes > 0 && u.UsedBytes > s.storageMaxBytes) ||
+		(s.storageQuotaPercent > 0 && su.QuotaUsed > s.storageQuotaPercent)
+	return su
+}
+
+// refreshStorage reads the newest usage report.
+func (s *Server) refreshStorage(ctx context.Context) (*storageReading, error) {
+	now := time.Now()
+	users, date, err := s.ws.StorageUsage(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	reading := &storageReading{users: users, date: date, at: now}
+	s.storage.Store(reading)
+	return reading, nil
+}
+
+// runStorageRefresh keeps storage current so registry items can be flagged.
+// The Reports API publishes a day at a time, so a few readings a day suffice.
+func (s *Server) runStorageRefresh(ctx context.Context) {
+	if !s.ws.UsageReportsEnabled() || (s.storageMaxBytes <= 0 && s.storageQuotaPercent <= 0) {
+		return
+	}
+	refresh := func() {
+		rctx, cancel := context.WithTimeout(ctx, storageTimeout)
+		defer cancel()
+		if _, err := s.refreshStorage(rctx); err != nil {
+			s.logger.ErrorContext(ctx, "storage usage refresh failed", "error", err)
+			return
+		}
+		s.broadcastRegistry()
+	}
+	refresh()
+	ticker := time.NewTicker(storageRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresh()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ownerOverStorage reports whether an item owner is past a storage threshold.
+func (s *Server) ownerOverStorage(owner string) bool {
+	reading := s.storage.Load()
+	if reading == nil || owner == "" {
+		return false
+	}
+	u, ok := reading.users[strings.ToLower(owner)]
+	return ok && s.storageUser(u).Over
+}
+
+// handleDomainStorage serves GET /api/domain/storage[?over=1][&user=][&refresh=1].
+// Readings older than the refresh interval are taken again; ?user= narrows
+// the list to one account and reads its Drive storage as that user.
+func (s *Server) handleDomainStorage(w http.ResponseWriter, r *http.Request) {
+	if !s.ws.UsageReportsEnabled() {
+		apiError(w, "storage reports not enabled (AXIS_STORAGE_REPORTS)", http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	reading := s.storage.Load()
+	if reading == nil || truthyParam(q.Get("refresh")) || time.Since(reading.at) > storageRefreshInterval {
+		var err error
+		if reading, err = s.refreshStorage(r.Context()); err != nil {
+			writeAPIError(w, err, http.StatusBadGateway)
+			return
+		}
+	}
+
+	user := strings.ToLower(strings.TrimSpace(q.Get("user")))
+	overOnly := truthyParam(q.Get("over"))
+	resp := StorageResponse{
+		Users:          []StorageUser{},
+		Date:           reading.date,
+		Computed:       reading.at,
+		ThresholdBytes: s.storageMaxBytes,
+		QuotaPercent:   s.storageQuotaPercent,
+	}
+	for email, u := range reading.users {
+		if user != "" && email != user {
+			continue
+		}
+		su := s.storageUser(u)
+		if su.Over {
+			resp.Over++
+		}
+		if !overOnly || su.Over {
+			resp.Users = append(resp.Users, su)
+		}
+	}
+	// Largest first.
+	slices.SortFunc(resp.Users, func(a, b StorageUser) int {
+		return cmp.Or(cmp.Compare(b.UsedBytes, a.UsedBytes), strings.Compare(a.Email, b.Email))
+	})
+
+	if user != "" {
+		as, err := s.ws.ActAs(r.Context(), user)
+		if err == nil {
+			var live workspace.DriveQuota
+			if live, err = as.DriveQuota(r.Context()); err == nil {
+				resp.Live = &live
+			}
+		}
+		if err != nil {
+			s.logger.WarnContext(r.Context(), "live drive storage unavailable", "user", user, "error", err)
+			resp.LiveError = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
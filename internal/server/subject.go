@@ -0,0 +1,188 @@
+/*
+File: internal/server/subject.go
+Description: Per-user context for read endpoints. An admin names an employee
+with the X-Axis-Subject header or ?user=, and the notes, registry, item detail,
+attachment and Drive download endpoints then answer as that user, through the
+read-only impersonation the suspended-user dashboard uses. Every such request
+is recorded as a subject.accessed event in the audit history.
+*/
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/auth"
+	"axis/internal/events"
+	"axis/internal/workspace"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	subjectHeader = "X-Axis-Subject"
+	subjectParam  = "user"
+	// subjectTTL is how long an impersonated service is reused.
+	subjectTTL = 10 * time.Minute
+)
+
+// subjectServices caches the services acting as each subject. mu only
+// guards the map; minting runs outside it, once per subject at a time.
+type subjectServices struct {
+	mu      sync.Mutex
+	byID    map[string]subjectService
+	minting singleflight.Group
+}
+
+type subjectService struct {
+	ws      *workspace.Service
+	expires time.Time
+}
+
+type subjectKey struct{}
+
+type subjectContext struct {
+	email string
+	ws    *workspace.Service
+}
+
+// subjectOf returns the user a request asks to act as, lowercased.
+func subjectOf(r *http.Request) string {
+	subject := r.Header.Get(subjectHeader)
+	if subject == "" {
+		subject = r.URL.Query().Get(subjectParam)
+	}
+	return strings.ToLower(strings.TrimSpace(subject))
+}
+
+// requiresSubject is base, or admin when the request names a subject.
+func requiresSubject(base auth.Role) func(*http.Request) auth.Role {
+	return func(r *http.Request) auth.Role {
+		if subjectOf(r) != "" {
+			return auth.RoleAdmin
+		}
+		return base
+	}
+}
+
+// subjectFrom returns the subject the request acts as, if any.
+func subjectFrom(ctx context.Context) (string, bool) {
+	sc, ok := ctx.Value(subjectKey{}).(subjectContext)
+	return sc.email, ok
+}
+
+// wsFor returns the Workspace service a request acts through: the subject's
+// when one is named, otherwise the server's own.
+func (s *Server) wsFor(ctx context.Context) *workspace.Service {
+	if sc, ok := ctx.Value(subjectKey{}).(subjectContext); ok {
+		return sc.ws
+	}
+	return s.ws
+}
+
+// actAs returns a service acting as email, reusing one minted in the last
+// subjectTTL. Concurrent requests for the same subject share one mint, which
+// is not cancelled when the request that started it goes away.
+func (s *Server) actAs(ctx context.Context, email string) (*workspace.Service, error) {
+	if ws, ok := s.cachedSubject(email, time.Now()); ok {
+		return ws, nil
+	}
+	ch := s.subjects.minting.DoChan(email, func() (any, error) {
+		ws, err := s.ws.ActAs(context.WithoutCancel(ctx), email)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		s.subjects.mu.Lock()
+		defer s.subjects.mu.Unlock()
+		if s.subjects.byID == nil {
+			s.subjects.byID = make(map[string]subjectService)
+		}
+		for id, cached := range s.subjects.byID {
+			if !now.Before(cached.expires) {
+				delete(s.subjects.byID, id)
+			}
+		}
+		s.subjects.byID[email] = subjectService{ws: ws, expires: now.Add(subjectTTL)}
+		return ws, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*workspace.Service), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// cachedSubject returns the service minted for email if it has not expired.
+func (s *Server) cachedSubject(email string, now time.Time) (*workspace.Service, bool) {
+	s.subjects.mu.Lock()
+	defer s.subjects.mu.Unlock()
+	cached, ok := s.subjects.byID[email]
+	if !ok || !now.Before(cached.expires) {
+		return nil, false
+	}
+	return cached.ws, true
+}
+
+// subjectRegistry lists the subject's items of every enabled type, live:
+// the registry cache only holds the server's own.
+func (s *Server) subjectRegistry(ctx context.Context) ([]workspace.RegistryItem, error) {
+	ws := s.wsFor(ctx)
+	var items []workspace.RegistryItem
+	for _, itemType := range workspace.ItemTypes {
+		list, err := ws.ListItems(ctx, itemType)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, list...)
+	}
+	return items, nil
+}
+
+// subjectScoped lets a read endpoint act as the subject the request names.
+// The guard has already required admin for it.
+func (s *Server) subjectScoped(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		email := subjectOf(r)
+		if email == "" {
+			h(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			apiError(w, "acting as another user is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		if _, domain, ok := strings.Cut(email, "@"); !ok || domain == "" {
+			apiError(w, "subject "+email+" is not an email address", http.StatusBadRequest)
+			return
+		}
+		ws, err := s.actAs(r.Context(), email)
+		switch {
+		case errors.Is(err, workspace.ErrNoImpersonation):
+			writeAPIError(w, err, http.StatusNotImplemented)
+			return
+		case err != nil:
+			writeAPIError(w, err, http.StatusBadGateway)
+			return
+		}
+		actor := actorFrom(r.Context())
+		s.logger.InfoContext(r.Context(), "acting as subject", "actor", actor, "subject", email, "path", r.URL.Path)
+		s.events.Emit(events.Event{
+			Type:    events.TypeSubjectAccessed,
+			Actor:   actor,
+			Subject: email,
+			Data:    map[string]any{"method": r.Method, "path": r.URL.Path, "query": r.URL.Query().Encode()},
+		})
+		w.Header().Set(subjectHeader, email)
+		w.Header().Add("Vary", subjectHeader)
+		h(w, r.WithContext(context.WithValue(r.Context(), subjectKey{}, subjectContext{email: email, ws: ws})))
+	}
+}
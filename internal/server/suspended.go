@@ -0,0 +1,168 @@
+/*
+File: internal/server/suspended.go
+Description: Suspended-user content dashboard. GET /api/suspended lists every
+suspended account with the Drive files, Keep notes and upcoming Calendar events
+it owns, read by impersonating the account; POST /api/suspended/launch starts
+the configured transfer or archive playbook for one of them. The scan can run
+as a background job with ?async=true.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/jobs"
+	"axis/internal/workspace"
+)
+
+const (
+	suspendedCacheTTL    = 5 * time.Minute
+	suspendedUserTimeout = 2 * time.Minute
+	suspendedParallelism = 4
+)
+
+// SuspendedUser is a suspended account and the content it still owns.
+type SuspendedUser struct {
+	workspace.DirectoryUser
+	Content workspace.UserContent `json:"content"`
+	// Error is set when the account could not be read at all.
+	Error string `json:"error,omitempty"`
+}
+
+// SuspendedResponse is the body of GET /api/suspended.
+type SuspendedResponse struct {
+	Users    []SuspendedUser `json:"users"`
+	Playbook string          `json:"playbook,omitempty"`
+	Scanned  time.Time       `json:"scanned"`
+}
+
+// suspendedCache holds the last dashboard scan, which impersonates every
+// suspended account and is too slow to repeat on each view.
+type suspendedCache struct {
+	mu    sync.Mutex
+	users []SuspendedUser
+	at    time.Time
+}
+
+// WithSuspendedPlaybook names the playbook the dashboard launches per user.
+func WithSuspendedPlaybook(name string) Option {
+	return func(s *Server) { s.suspendedPlaybook = name }
+}
+
+// suspendedUsers lists suspended accounts and their content, from the cache
+// unless it is stale or refresh is set, counting each account read on p.
+func (s *Server) suspendedUsers(ctx context.Context, refresh bool, p *jobs.Progress) ([]SuspendedUser, time.Time, error) {
+	c := &s.suspended
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !refresh && c.users != nil && time.Since(c.at) < suspendedCacheTTL {
+		return c.users, c.at, nil
+	}
+
+	all, err := s.ws.ListDirectoryUsers(ctx, s.userSchemas)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	users := []SuspendedUser{}
+	for _, u := range all {
+		if u.Suspended {
+			users = append(users, SuspendedUser{DirectoryUser: u})
+		}
+	}
+
+	p.SetTotal(len(users))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, suspendedParallelism)
+	for i := range users {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(u *SuspendedUser) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer p.Add(1)
+			uctx, cancel := context.WithTimeout(ctx, suspendedUserTimeout)
+			defer cancel()
+			content, err := s.ws.UserContent(uctx, u.Email)
+			u.Content = content
+			if err != nil {
+				u.Error = err.Error()
+				s.logger.WarnContext(ctx, "suspended user content unavailable", "user", u.Email, "error", err)
+			}
+		}(&users[i])
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	c.users, c.at = users, time.Now()
+	return users, c.at, nil
+}
+
+// handleSuspended serves GET /api/suspended. ?async=true always rescans, in a
+// job whose result is the response.
+func (s *Server) handleSuspended(w http.ResponseWriter, r *http.Request) {
+	if asyncRequested(r) {
+		s.submitJob(w, r, "suspended-scan", func(ctx context.Context, p *jobs.Progress) (jobs.Result, error) {
+			users, at, err := s.suspendedUsers(ctx, true, p)
+			if err != nil {
+				return jobs.Result{}, err
+			}
+			return jobs.Result{Value: SuspendedResponse{Users: users, Playbook: s.suspendedPlaybook, Scanned: at}}, nil
+		})
+		return
+	}
+	users, at, err := s.suspendedUsers(r.Context(), truthyParam(r.URL.Query().Get("refresh")), nil)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SuspendedResponse{Users: users, Playbook: s.suspendedPlaybook, Scanned: at})
+}
+
+// handleSuspendedLaunch serves POST /api/suspended/launch?email=. The account
+// is looked up again so a playbook never starts for a user who was restored
+// since the last scan.
+func (s *Server) handleSuspendedLaunch(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		apiError(w, "missing email", http.StatusBadRequest)
+		return
+	}
+	pb, ok := s.playbooks.Playbook(s.suspendedPlaybook)
+	if !ok {
+		apiError(w, "no suspended-user playbook configured (set AXIS_SUSPENDED_PLAYBOOK)", http.StatusNotFound)
+		return
+	}
+	users, err := s.ws.ListDirectoryUsers(r.Context(), s.userSchemas)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	var target *workspace.DirectoryUser
+	for i := range users {
+		if strings.EqualFold(users[i].Email, email) {
+			target = &users[i]
+		}
+	}
+	if target == nil || !target.Suspended {
+		apiError(w, "no suspended user "+email, http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), triggerRunTimeout)
+	defer cancel()
+	res := s.runUserPlaybook(ctx, pb, *target, map[string]any{"suspended": true})
+
+	w.Header().Set("Content-Type", "application/json")
+	if !res.OK {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,130 @@
+/*
+File: internal/server/tags.go
+Description: Tags in the keep: namespace, Axis's stand-in for Keep labels. Keep
+notes keep their tags in Axis state; Docs and Sheets keep them in a Drive Label
+when one is configured (and in Axis state otherwise), so tags set in Drive show
+up in the registry and tags set here are written back to Drive.
+*/
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+)
+
+// TagsRequest is the body of PUT /api/tags. The tags replace the item's tags;
+// an empty list removes them.
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// TagsResponse reports an item's tags after a change.
+type TagsResponse struct {
+	ID   string   `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+// TagCount is one tag in use and how many items carry it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Items int    `json:"items"`
+}
+
+// handleTags lists the tags in use across the registry, most used first.
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	counts := make(map[string]int)
+	for _, item := range s.enrichItems(s.registryCache.snapshot().items) {
+		for _, tag := range item.Tags {
+			counts[tag]++
+		}
+	}
+	list := make([]TagCount, 0, len(counts))
+	for tag, n := range counts {
+		list = append(list, TagCount{Tag: tag, Items: n})
+	}
+	slices.SortFunc(list, func(a, b TagCount) int {
+		if a.Items != b.Items {
+			return b.Items - a.Items
+		}
+		return strings.Compare(a.Tag, b.Tag)
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleTagsUpdate serves PUT /api/tags?id=&type=.
+func (s *Server) handleTagsUpdate(w http.ResponseWriter, r *http.Request) {
+	id, itemType := r.URL.Query().Get("id"), r.URL.Query().Get("type")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	var req TagsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		apiError(w, "invalid tags: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tags, err := workspace.NormalizeTags(req.Tags)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	item := s.registryItem(id, itemType)
+	switch item.Type {
+	case "keep", "doc", "sheet":
+	default:
+		apiError(w, "unknown item type (pass type=keep, doc or sheet)", http.StatusBadRequest)
+		return
+	}
+	if item.Type == "keep" || !s.ws.DriveTagsEnabled() {
+		s.setTags(id, tags)
+		s.relayJSON(relayTags, TagsResponse{ID: id, Tags: tags})
+		s.triggerStateSnapshot()
+	} else {
+		if s.currentMode() == "AIRGAP" {
+			apiError(w, "Drive labels cannot be written in AIRGAP mode", http.StatusConflict)
+			return
+		}
+		if err := s.ws.SetDriveTags(r.Context(), id, tags); err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, workspace.ErrUnknownTag) {
+				status = http.StatusBadRequest
+			}
+			writeAPIError(w, err, status)
+			return
+		}
+		// The listing carries Drive tags, so update the cached copy until the
+		// next refresh reads them back.
+		s.registryCache.update(func(cur *registrySnapshot) *registrySnapshot {
+			items := slices.Clone(cur.items)
+			for i := range items {
+				if items[i].ID == id {
+					items[i].Tags = tags
+				}
+			}
+			return &registrySnapshot{items: items, expiresAt: cur.expiresAt, loaded: cur.loaded}
+		})
+	}
+
+	s.broadcastRegistry()
+	s.logger.InfoContext(r.Context(), "tags changed", "id", id, "type", item.Type, "tags", tags, "actor", actorFrom(r.Context()))
+	s.events.Emit(events.Event{
+		Type:    events.TypeTagsChanged,
+		Actor:   actorFrom(r.Context()),
+		Subject: id,
+		Data:    map[string]any{"type": item.Type, "title": item.Title, "tags": tags},
+	})
+
+	if tags == nil {
+		tags = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TagsResponse{ID: id, Tags: tags})
+}
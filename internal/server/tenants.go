@@ -0,0 +1,107 @@
+/*
+File: internal/server/tenants.go
+Description: Multi-tenant serving. The main server manages one Workspace domain
+and can host further tenants, each a full Server with its own Workspace
+clients, state store and broker. A tenant's API is the main API under
+/api/t/{tenant}/ (e.g. /api/t/acme/registry), and /api/events and /api/ws take
+?tenant= to stream a tenant's events. Tenants' background loops start and stop
+with the main server.
+*/
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// WithTenants hosts further tenants by name. Each server is built with its own
+// options and is never started itself.
+func WithTenants(tenants map[string]*Server) Option {
+	return func(s *Server) { s.tenants = tenants }
+}
+
+// TenantInfo describes one tenant in GET /api/tenants. The main tenant has no
+// name and is served at /api.
+type TenantInfo struct {
+	Name   string `json:"name,omitempty"`
+	Domain string `json:"domain"`
+	Mode   string `json:"mode"`
+	Items  int    `json:"items"`
+	Base   string `json:"base"`
+}
+
+func (s *Server) tenantInfo(name, base string) TenantInfo {
+	items, _ := s.cachedItemsFresh()
+	return TenantInfo{Name: name, Domain: s.about.Tenant, Mode: s.currentMode(), Items: len(items), Base: base}
+}
+
+// tenantNames returns the hosted tenants' names in order.
+func (s *Server) tenantNames() []string {
+	names := make([]string, 0, len(s.tenants))
+	for name := range s.tenants {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// handleTenants serves GET /api/tenants, the main tenant first.
+func (s *Server) handleTenants(w http.ResponseWriter, r *http.Request) {
+	list := []TenantInfo{s.tenantInfo("", "/api")}
+	for _, name := range s.tenantNames() {
+		list = append(list, s.tenants[name].tenantInfo(name, "/api/t/"+name))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// tenantRoutes serves /api/t/{tenant}/... with the tenant's own routes,
+// which authenticate and authorize the request themselves.
+func (s *Server) tenantRoutes() http.HandlerFunc {
+	muxes := make(map[string]http.Handler, len(s.tenants))
+	for name, t := range s.tenants {
+		muxes[name] = t.routes()
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("tenant")
+		mux, ok := muxes[name]
+		if !ok {
+			apiError(w, "unknown tenant "+name, http.StatusNotFound)
+			return
+		}
+		r2 := r.Clone(withAPIPrefix(r.Context(), "/api/t/"+name))
+		r2.URL.Path = "/api" + strings.TrimPrefix(r.URL.Path, "/api/t/"+name)
+		r2.URL.RawPath = ""
+		mux.ServeHTTP(w, r2)
+	}
+}
+
+// streamTenant serves a stream route: with ?tenant= the tenant's own route,
+// which authenticates the request against that tenant, otherwise the main
+// server's. An unknown tenant is answered 404.
+func (s *Server) streamTenant(route func(*Server) http.HandlerFunc) http.HandlerFunc {
+	routes := make(map[string]http.HandlerFunc, len(s.tenants))
+	for name, t := range s.tenants {
+		routes[name] = route(t)
+	}
+	main := route(s)
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		name := q.Get("tenant")
+		if name == "" {
+			main(w, r)
+			return
+		}
+		h, ok := routes[name]
+		if !ok {
+			apiError(w, "unknown tenant "+name, http.StatusNotFound)
+			return
+		}
+		q.Del("tenant")
+		r2 := r.Clone(r.Context())
+		r2.URL.RawQuery = q.Encode()
+		h(w, r2)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+File: internal/server/tenants_test.go
+Description: A stream request for a hosted tenant is authenticated against that
+tenant: credentials the main server accepts do not open another tenant's
+stream.
+*/
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"axis/internal/auth"
+	"axis/internal/store"
+)
+
+// keyServer returns a server without Workspace clients whose only credential
+// is an operator API key.
+func keyServer(t *testing.T, name, key string, opts ...Option) *Server {
+	t.Helper()
+	env := "AXIS_TEST_KEY_" + name
+	t.Setenv(env, key)
+	a, err := auth.New(auth.Config{APIKeys: []auth.APIKey{{Name: name, Role: "operator", KeyEnv: env}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append(opts, WithStore(store.NewFileStore(filepath.Join(t.TempDir(), "state.json"))), WithAuth(a))
+	s := NewServer(nil, nil, opts...)
+	// A listing to send on connect, so streaming never fetches one.
+	s.diffBase.data = []byte("[]")
+	return s
+}
+
+func TestTenantStreamAuthenticatesAgainstTenant(t *testing.T) {
+	acme := keyServer(t, "acme", "acme-secret")
+	s := keyServer(t, "main", "main-secret", WithTenants(map[string]*Server{"acme": acme}))
+	srv := httptest.NewServer(s.routes())
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name, path, key string
+		want            int
+	}{
+		{"main key on main stream", "/api/events", "main-secret", http.StatusOK},
+		{"main key on tenant stream", "/api/events?tenant=acme", "main-secret", http.StatusUnauthorized},
+		{"main key on tenant websocket", "/api/ws?tenant=acme", "main-secret", http.StatusUnauthorized},
+		{"tenant key on tenant stream", "/api/events?tenant=acme", "acme-secret", http.StatusOK},
+		{"tenant key on main stream", "/api/events", "acme-secret", http.StatusUnauthorized},
+		{"unknown tenant", "/api/events?tenant=nope", "main-secret", http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.key)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+/*
+File: internal/server/tokens.go
+Description: Minted API tokens at /api/tokens. Admins create tokens limited to
+a few capabilities ("read registry only", "status updates for campaign X")
+with an expiry, list them, and revoke them; the secret is shown once and only
+its digest is stored. Tokens are cached per instance and re-read from the
+store periodically and whenever another instance changes them. A revoked token
+is dropped from the cache at once, even if the re-read fails; another instance
+that misses the relay keeps accepting it for up to tokenReload.
+*/
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"axis/internal/auth"
+	"axis/internal/events"
+	"axis/internal/store"
+)
+
+const (
+	defaultTokenTTL = 30 * 24 * time.Hour
+	maxTokenTTL     = 365 * 24 * time.Hour
+	// tokenReload bounds how long a token minted or revoked on another
+	// instance goes unnoticed if the relay is lost, and how long a store
+	// outage keeps the cached tokens in use.
+	tokenReload = 30 * time.Second
+
+	relayTokens = "state.tokens"
+)
+
+var errTokenScope = errors.New("token not permitted")
+
+// TokenRequest is the body of POST /api/tokens. ExpiresIn is a Go duration or
+// a number of days ("90d"); the default is 30 days and the limit a year.
+type TokenRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Campaign  string   `json:"campaign,omitempty"`
+	ExpiresIn string   `json:"expires_in,omitempty"`
+}
+
+// TokenInfo describes a minted token without its secret.
+type TokenInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	Campaign  string    `json:"campaign,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+	Expired   bool      `json:"expired"`
+}
+
+// TokenCreated is the response to POST /api/tokens. Token is the secret,
+// which is not shown again.
+type TokenCreated struct {
+	TokenInfo
+	Token string `json:"token"`
+}
+
+func tokenInfo(t store.Token, now time.Time) TokenInfo {
+	return TokenInfo{
+		ID:        t.ID,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		Campaign:  t.Campaign,
+		CreatedBy: t.CreatedBy,
+		Created:   t.Created,
+		Expires:   t.Expires,
+		Expired:   !now.Before(t.Expires),
+	}
+}
+
+// tokenCache holds the store's tokens by digest.
+type tokenCache struct {
+	mu       sync.Mutex
+	byDigest map[string]store.Token
+	loadedAt time.Time
+}
+
+// tokenResolver adapts the server's token cache to auth.TokenResolver.
+type tokenResolver struct{ s *Server }
+
+// ResolveToken implements auth.TokenResolver.
+func (t tokenResolver) ResolveToken(digest string) (auth.Token, bool) {
+	c := &t.s.apiTokens
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.loadedAt) > tokenReload {
+		t.s.loadTokensLocked(context.Background())
+	}
+	tok, ok := c.byDigest[digest]
+	if !ok {
+		return auth.Token{}, false
+	}
+	return auth.Token{ID: tok.ID, Name: tok.Name, Scopes: tok.Scopes, Campaign: tok.Campaign, Expires: tok.Expires}, true
+}
+
+// loadTokensLocked re-reads the tokens; on failure the cached ones stay in
+// use until the next attempt. The caller holds apiTokens.mu.
+func (s *Server) loadTokensLocked(ctx context.Context) {
+	c := &s.apiTokens
+	c.loadedAt = time.Now()
+	list, err := s.store.ListTokens(ctx)
+	if err != nil {
+		s.logger.Error("failed to load api tokens", "error", err)
+		return
+	}
+	c.byDigest = make(map[string]store.Token, len(list))
+	for _, t := range list {
+		c.byDigest[t.Digest] = t
+	}
+}
+
+// reloadTokens re-reads the tokens now, after a change here or elsewhere. A
+// revoked token, named by its ID, is dropped first, so it stops working here
+// whether or not the store answers.
+func (s *Server) reloadTokens(revoked string) {
+	c := &s.apiTokens
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for digest, t := range c.byDigest {
+		if revoked != "" && t.ID == revoked {
+			delete(c.byDigest, digest)
+		}
+	}
+	s.loadTokensLocked(context.Background())
+}
+
+// checkTokenScope applies a minted token's limits to a request: viewer routes
+// need registry:read, other routes the scope they were registered with, and
+// routes without one are closed to tokens.
+func (s *Server) checkTokenScope(r *http.Request, p *auth.Principal, role auth.Role, scope string) error {
+	if scope == "" {
+		if role > auth.RoleViewer {
+			return fmt.Errorf("%w: minted tokens cannot call this endpoint", errTokenScope)
+		}
+		scope = auth.ScopeRegistryRead
+	}
+	if !p.HasScope(scope) {
+		return fmt.Errorf("%w: requires the %s scope", errTokenScope, scope)
+	}
+	if scope == auth.ScopeRegistryRead {
+		return nil
+	}
+	if id := r.URL.Query().Get("id"); id != "" {
+		return s.checkTokenCampaign(p, id)
+	}
+	return nil
+}
+
+// checkTokenCampaign refuses changes by a campaign-restricted token to items
+// outside its campaign. Other principals pass.
+func (s *Server) checkTokenCampaign(p *auth.Principal, id string) error {
+	if p == nil || p.Campaign == "" {
+		return nil
+	}
+	var campaign string
+	if policy := s.policy.Load(); policy != nil {
+		campaign = policy.Campaigns[id]
+	}
+	if campaign != p.Campaign {
+		return fmt.Errorf("%w: %s is not in campaign %s", errTokenScope, id, p.Campaign)
+	}
+	return nil
+}
+
+// itemAllowed is checkTokenCampaign for the principal of ctx, for endpoints
+// that name their items in the body.
+func (s *Server) itemAllowed(ctx context.Context, id string) error {
+	p, _ := auth.FromContext(ctx)
+	return s.checkTokenCampaign(p, id)
+}
+
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	list, err := s.store.ListTokens(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	out := make([]TokenInfo, 0, len(list))
+	for _, t := range list {
+		out = append(out, tokenInfo(t, now))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+func (s *Server) handleTokenCreate(w http.ResponseWriter, r *http.Request) {
+	var req TokenRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		apiError(w, "invalid token request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		apiError(w, "missing name", http.StatusBadRequest)
+		return
+	}
+	scopes, err := auth.ParseScopes(req.Scopes)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	ttl, err := parseSpan("expires_in", req.ExpiresIn, defaultTokenTTL)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+	if ttl > maxTokenTTL {
+		apiError(w, "expires_in must be at most 365d", http.StatusBadRequest)
+		return
+	}
+
+	secret, digest, err := auth.NewToken()
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	actor := actorFrom(r.Context())
+	t := store.Token{
+		ID:        hex.EncodeToString(id),
+		Name:      req.Name,
+		Digest:    digest,
+		Scopes:    scopes,
+		Campaign:  req.Campaign,
+		CreatedBy: actor,
+		Created:   now,
+		Expires:   now.Add(ttl),
+	}
+	if err := s.store.SaveToken(r.Context(), t); err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	s.reloadTokens("")
+	s.relayJSON(relayTokens, map[string]string{"id": t.ID})
+
+	s.logger.InfoContext(r.Context(), "api token created", "id", t.ID, "name", t.Name, "scopes", scopes, "campaign", t.Campaign, "expires", t.Expires, "actor", actor)
+	s.events.Emit(events.Event{
+		Type:    events.TypeTokenCreated,
+		Actor:   actor,
+		Subject: t.ID,
+		Data:    map[string]any{"name": t.Name, "scopes": scopes, "campaign": t.Campaign, "expires": t.Expires},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(TokenCreated{TokenInfo: tokenInfo(t, now), Token: secret})
+}
+
+// handleTokenRevoke serves DELETE /api/tokens?id=.
+func (s *Server) handleTokenRevoke(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apiError(w, "missing id", http.StatusBadRequest)
+		return
+	}
+	var name string
+	if list, err := s.store.ListTokens(r.Context()); err == nil {
+		for _, t := range list {
+			if t.ID == id {
+				name = t.Name
+			}
+		}
+	}
+	ok, err := s.store.DeleteToken(r.Context(), id)
+	if err != nil {
+		writeAPIError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		apiError(w, "no token "+id, http.StatusNotFound)
+		return
+	}
+	s.reloadTokens(id)
+	s.relayJSON(relayTokens, map[string]string{"revoked": id})
+
+	actor := actorFrom(r.Context())
+	s.logger.InfoContext(r.Context(), "api token revoked", "id", id, "name", name, "actor", actor)
+	s.events.Emit(events.Event{
+		Type:    events.TypeTokenRevoked,
+		Actor:   actor,
+		Subject: id,
+		Data:    map[string]any{"name": name},
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
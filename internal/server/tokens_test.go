@@ -0,0 +1,151 @@
+/*
+File: internal/server/tokens_test.go
+Description: A campaign-restricted token cannot change items outside its
+campaign through single, bulk or background requests, and a revoked token stops
+working at once, even while the store cannot list tokens.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"axis/internal/auth"
+	"axis/internal/sheetconfig"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+// campaignServer serves a registry of notes/in, in the offboarding campaign,
+// and notes/out, in another, to a token for offboarding statuses.
+func campaignServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s := rolesServer(t, map[string]store.Token{
+		"axt_offboarding": {Name: "offboarding", Scopes: []string{auth.ScopeStatusWrite}, Campaign: "offboarding", Expires: time.Now().Add(time.Hour)},
+	})
+	s.policy.Store(&sheetconfig.Policy{Campaigns: map[string]string{"notes/in": "offboarding", "notes/out": "retention"}})
+	items := []workspace.RegistryItem{
+		{ID: "notes/in", Type: "keep", Title: "In"},
+		{ID: "notes/out", Type: "keep", Title: "Out"},
+	}
+	s.registryCache.update(func(*registrySnapshot) *registrySnapshot {
+		return &registrySnapshot{items: items, expiresAt: time.Now().Add(time.Hour), loaded: true}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.jobs.Run(ctx)
+	srv := httptest.NewServer(s.routes())
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+	})
+	return s, srv
+}
+
+func call(t *testing.T, srv *httptest.Server, method, path, key, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestCampaignTokenSingleStatus(t *testing.T) {
+	s, srv := campaignServer(t)
+	for id, want := range map[string]int{"notes/in": http.StatusOK, "notes/out": http.StatusForbidden} {
+		if resp := call(t, srv, http.MethodPost, "/api/status?status=Execute&id="+id, "axt_offboarding", ""); resp.StatusCode != want {
+			t.Errorf("%s: status %d, want %d", id, resp.StatusCode, want)
+		}
+	}
+	if got := s.currentStatuses()["notes/out"]; got != "" {
+		t.Errorf("notes/out status changed to %q", got)
+	}
+}
+
+// checkCampaignBulk checks a bulk-status outcome: notes/in changed, notes/out
+// refused and untouched.
+func checkCampaignBulk(t *testing.T, s *Server, res BulkResponse) {
+	t.Helper()
+	if len(res.Results) != 2 || !res.Results[0].OK || res.Results[1].OK {
+		t.Fatalf("results %+v, want notes/in only", res.Results)
+	}
+	if !strings.Contains(res.Results[1].Error, "not in campaign") {
+		t.Errorf("notes/out error %q", res.Results[1].Error)
+	}
+	statuses := s.currentStatuses()
+	if statuses["notes/in"] != "Execute" || statuses["notes/out"] != "" {
+		t.Errorf("statuses %v", statuses)
+	}
+}
+
+func TestCampaignTokenBulkStatus(t *testing.T) {
+	s, srv := campaignServer(t)
+	resp := call(t, srv, http.MethodPost, "/api/notes/bulk-status", "axt_offboarding", `{"ids": ["notes/in", "notes/out"], "status": "Execute"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var res BulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	checkCampaignBulk(t, s, res)
+}
+
+func TestCampaignTokenBulkStatusJob(t *testing.T) {
+	s, srv := campaignServer(t)
+	resp := call(t, srv, http.MethodPost, "/api/notes/bulk-status?async=true", "axt_offboarding", `{"ids": ["notes/in", "notes/out"], "status": "Execute"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var job struct{ ID string }
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if j, ok := s.jobs.Get(job.ID); ok && j.Final() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("job did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp = call(t, srv, http.MethodGet, "/api/jobs/"+job.ID+"/result", "admin", "")
+	var res BulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	checkCampaignBulk(t, s, res)
+}
+
+// unlistedTokens is a store that cannot list tokens.
+type unlistedTokens struct{ store.Store }
+
+func (unlistedTokens) ListTokens(context.Context) ([]store.Token, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func TestRevokedTokenStopsAtOnce(t *testing.T) {
+	s, srv := campaignServer(t)
+	if resp := call(t, srv, http.MethodPost, "/api/status?status=Execute&id=notes/in", "axt_offboarding", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("before revoke: status %d", resp.StatusCode)
+	}
+	// The revoke must take effect even when the tokens cannot be re-read.
+	s.store = unlistedTokens{s.store}
+	if resp := call(t, srv, http.MethodDelete, "/api/tokens?id=offboarding", "admin", ""); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoke: status %d", resp.StatusCode)
+	}
+	if resp := call(t, srv, http.MethodPost, "/api/status?status=Execute&id=notes/in", "axt_offboarding", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("after revoke: status %d, want 401", resp.StatusCode)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+File: internal/server/tracing.go
+Description: OpenTelemetry spans for API requests. Each API call gets a server
+span named after its method and path, continuing any trace propagated by the
+caller; the request context carries it into the workspace and Google API
+spans below. Streams and static assets are not traced.
+*/
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// traced wraps the API handler in server spans.
+func traced(h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, "axis",
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			switch r.URL.Path {
+			case "/api/events", "/api/ws":
+				return false
+			}
+			return strings.HasPrefix(r.URL.Path, "/api/")
+		}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+	)
+}
@@ -0,0 +1,143 @@
+/*
+File: internal/server/triggers.go
+Description: Inbound automation triggers. External systems POST signed,
+timestamped payloads to /api/triggers/{name}; the mapped playbook runs against
+the registry through the server's executor adapter, whose deletes are gated by
+mode like the operator's.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/playbook"
+	"axis/internal/workspace"
+)
+
+const (
+	triggerBodyLimit  = 1 << 20
+	triggerRunTimeout = 2 * time.Minute
+	signatureHeader   = "X-Axis-Signature"
+	timestampHeader   = "X-Axis-Timestamp"
+)
+
+func (s *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	trigger, pb, ok := s.playbooks.Trigger(name)
+	if !ok {
+		apiError(w, "unknown trigger", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, triggerBodyLimit))
+	if err != nil {
+		apiError(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+	if err := s.playbooks.Verify(trigger.Name, r.Header.Get(timestampHeader), body, r.Header.Get(signatureHeader), time.Now()); err != nil {
+		s.logger.WarnContext(r.Context(), "trigger signature rejected", "trigger", name, "remote", r.RemoteAddr, "error", err)
+		writeAPIError(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	vars, err := payloadVars(body)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(withActor(r.Context(), "trigger:"+name), triggerRunTimeout)
+	defer cancel()
+
+	start := time.Now()
+	res := playbook.Run(ctx, pb, playbookExecutor{s}, vars)
+	s.logger.InfoContext(r.Context(), "trigger executed", "trigger", name, "playbook", pb.Name, "ok", res.OK, "duration", time.Since(start))
+	s.events.Emit(events.Event{
+		Type:  events.TypePlaybookCompleted,
+		Actor: actorFrom(ctx),
+		Data:  map[string]any{"playbook": pb.Name, "trigger": name, "ok": res.OK, "steps": res.Steps},
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if !res.OK {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// payloadVars flattens top-level scalar fields of a JSON object for ${field} expansion.
+func payloadVars(body []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	if len(body) == 0 {
+		return vars, nil
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("payload must be a JSON object")
+	}
+	for k, v := range raw {
+		switch val := v.(type) {
+		case string:
+			vars[k] = val
+		case float64, bool:
+			vars[k] = fmt.Sprint(val)
+		}
+	}
+	return vars, nil
+}
+
+// playbookExecutor adapts the server to the playbook.Executor interface.
+type playbookExecutor struct {
+	s *Server
+}
+
+func (e playbookExecutor) Refresh(ctx context.Context) error {
+	e.s.refreshAndBroadcast()
+	return nil
+}
+
+func (e playbookExecutor) Items(ctx context.Context) ([]workspace.RegistryItem, error) {
+	return e.s.RegistrySnapshot(ctx)
+}
+
+func (e playbookExecutor) SetMode(ctx context.Context, mode string) error {
+	return e.s.setMode(ctx, mode)
+}
+
+func (e playbookExecutor) SetStatus(ctx context.Context, item workspace.RegistryItem, status string) error {
+	return e.s.setItemStatus(ctx, item.ID, status, item.Title)
+}
+
+func (e playbookExecutor) LogNote(ctx context.Context, item workspace.RegistryItem, sheet, action string) error {
+	return e.s.logNote(ctx, item, sheet, action)
+}
+
+// Delete goes through deleteRegistryItem like the operator's deletes, so
+// SIMULATE only reports it and AIRGAP queues it as a proposal.
+func (e playbookExecutor) Delete(ctx context.Context, item workspace.RegistryItem) error {
+	if err := e.s.deleteRegistryItem(ctx, item); err != nil {
+		return err
+	}
+	// A reported or proposed delete left the registry as it was.
+	if mode := e.s.currentMode(); mode != "SIMULATE" && mode != "AIRGAP" {
+		go e.s.refreshAndBroadcast()
+	}
+	return nil
+}
+
+func (e playbookExecutor) Archive(ctx context.Context, item workspace.RegistryItem) error {
+	res, err := e.s.archiveRegistryItem(ctx, item)
+	if err != nil {
+		return err
+	}
+	if !res.Simulated {
+		go e.s.refreshAndBroadcast()
+	}
+	return nil
+}
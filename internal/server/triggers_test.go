@@ -0,0 +1,104 @@
+/*
+File: internal/server/triggers_test.go
+Description: Trigger requests run their playbook only with a fresh signature
+over their timestamp and body, once; playbook deletes obey the mode.
+*/
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"axis/internal/playbook"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+// hmacHex is the hex HMAC-SHA256 of msg.
+func hmacHex(secret, msg string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func triggerSignature(secret, timestamp, body string) string {
+	return "sha256=" + hmacHex(secret, timestamp+"."+body)
+}
+
+func TestTriggerSignatures(t *testing.T) {
+	t.Setenv("AXIS_TEST_TRIGGER_SECRET", "trigger-secret")
+	catalog, err := playbook.NewCatalog(playbook.Config{
+		Playbooks: []playbook.Playbook{{Name: "manual", Steps: []playbook.Step{{Action: playbook.ActionSetMode, Mode: "MANUAL"}}}},
+		Triggers:  []playbook.Trigger{{Name: "ticket", Playbook: "manual", SecretEnv: "AXIS_TEST_TRIGGER_SECRET"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := keyServer(t, "main", "main-secret", WithPlaybooks(catalog))
+	srv := httptest.NewServer(s.routes())
+	defer srv.Close()
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-playbook.SignatureTolerance-time.Minute).Unix(), 10)
+	const body = `{"ticket":"42"}`
+	for _, tc := range []struct {
+		name, trigger, timestamp, signature string
+		want                                int
+	}{
+		{"valid", "ticket", now, triggerSignature("trigger-secret", now, body), http.StatusOK},
+		{"replayed", "ticket", now, triggerSignature("trigger-secret", now, body), http.StatusUnauthorized},
+		{"replayed in upper case", "ticket", now, "sha256=" + strings.ToUpper(hmacHex("trigger-secret", now+"."+body)), http.StatusUnauthorized},
+		{"wrong secret", "ticket", now, triggerSignature("other-secret", now, body), http.StatusUnauthorized},
+		{"body only", "ticket", now, "sha256=" + hmacHex("trigger-secret", body), http.StatusUnauthorized},
+		{"timestamp changed", "ticket", stale, triggerSignature("trigger-secret", now, body), http.StatusUnauthorized},
+		{"stale", "ticket", stale, triggerSignature("trigger-secret", stale, body), http.StatusUnauthorized},
+		{"no timestamp", "ticket", "", triggerSignature("trigger-secret", "", body), http.StatusUnauthorized},
+		{"unknown trigger", "nope", now, triggerSignature("trigger-secret", now, body), http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s.mode.Store("AUTO")
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/api/triggers/"+tc.trigger, strings.NewReader(body))
+			req.Header.Set(timestampHeader, tc.timestamp)
+			req.Header.Set(signatureHeader, tc.signature)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tc.want)
+			}
+			ran := s.currentMode() == "MANUAL"
+			if ran != (tc.want == http.StatusOK) {
+				t.Errorf("playbook ran = %v", ran)
+			}
+		})
+	}
+}
+
+func TestPlaybookDeleteSimulated(t *testing.T) {
+	s := keyServer(t, "main", "main-secret")
+	s.mode.Store("SIMULATE")
+	ctx := context.Background()
+	// The server has no Workspace clients: anything but a simulated delete
+	// would fail.
+	item := workspace.RegistryItem{ID: "note-1", Type: "keep", Title: "Offboarding"}
+	if err := (playbookExecutor{s}).Delete(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	deletions, err := s.store.ListDeletions(ctx, store.Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deletions) != 1 || deletions[0].Outcome != store.OutcomeSimulated {
+		t.Fatalf("deletions = %+v, want one simulated", deletions)
+	}
+}
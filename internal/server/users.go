@@ -0,0 +1,404 @@
+/*
+File: internal/server/users.go
+Description: Directory users and user rules. GET /api/users lists users with
+their custom schema fields and PUT /api/users/fields writes them; user rules
+from the rules file are evaluated on a schedule, and each user that comes to
+match a rule starts the rule's playbook (e.g. users past their offboardDate
+start offboarding).
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/playbook"
+	"axis/internal/rules"
+	"axis/internal/store"
+	"axis/internal/workspace"
+)
+
+const (
+	defaultUserRuleInterval = time.Hour
+	userRuleTimeout         = 10 * time.Minute
+	maxUserFieldsBody       = 64 << 10
+	maxLookupEmails         = 1000
+)
+
+// UserRuleMatches lists the users one user rule currently matches.
+type UserRuleMatches struct {
+	Rule  rules.UserRule   `json:"rule"`
+	Users []UserMatchEntry `json:"users"`
+}
+
+// UserMatchEntry identifies a matched user.
+type UserMatchEntry struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// UserFieldsRequest is the body of PUT /api/users/fields. A null value clears
+// a field.
+type UserFieldsRequest struct {
+	Fields map[string]any `json:"fields"`
+}
+
+// UserLookupRequest is the body of POST /api/users/lookup.
+type UserLookupRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// UserLookupResponse maps each requested email that has an account to its
+// user (an alias resolves to the primary account) and lists the rest.
+type UserLookupResponse struct {
+	Found   map[string]workspace.DirectoryUser `json:"found"`
+	Missing []string                           `json:"missing"`
+}
+
+// WithUserSchemas limits the custom schemas read with users; all schemas are
+// read when none are given.
+func WithUserSchemas(schemas []string) Option {
+	return func(s *Server) { s.userSchemas = schemas }
+}
+
+// WithUserRuleInterval sets how often user rules are evaluated (hourly by
+// default).
+func WithUserRuleInterval(d time.Duration) Option {
+	return func(s *Server) { s.userRuleInterval = d }
+}
+
+// userAttributes builds the facts user rules test. Custom fields keep their
+// "Schema.field" names; dates (YYYY-MM-DD) become times so the age operators
+// apply, and multi-valued fields are joined with commas.
+func userAttributes(u workspace.DirectoryUser) rules.Attributes {
+	attrs := rules.Attributes{
+		rules.AttrID:        u.ID,
+		rules.AttrEmail:     u.Email,
+		rules.AttrName:      u.Name,
+		rules.AttrSuspended: u.Suspended,
+	}
+	if u.LastLogin != nil {
+		attrs[rules.AttrLastLogin] = *u.LastLogin
+	}
+	if u.OrgUnit != "" {
+		attrs[rules.AttrOrgUnit] = u.OrgUnit
+	}
+	if u.Groups != nil {
+		attrs[rules.AttrGroups] = strings.Join(u.Groups, ",")
+	}
+	for key, v := range u.Fields {
+		switch val := v.(type) {
+		case string:
+			if t, err := time.Parse(time.DateOnly, val); err == nil {
+				attrs[key] = t
+			} else {
+				attrs[key] = val
+			}
+		case float64, bool:
+			attrs[key] = val
+		case []any:
+			var values []string
+			for _, entry := range val {
+				if m, ok := entry.(map[string]any); ok && m["value"] != nil {
+					values = append(values, strings.TrimSpace(anyString(m["value"])))
+				}
+			}
+			attrs[key] = strings.Join(values, ",")
+		}
+	}
+	return attrs
+}
+
+func anyString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// matchUserRules evaluates every user rule against the directory.
+func (s *Server) matchUserRules(ctx context.Context) ([]UserRuleMatches, map[string]workspace.DirectoryUser, error) {
+	users, err := s.ws.ListDirectoryUsers(ctx, s.userSchemas)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.directoryGroups && s.rules.NeedsGroups() {
+		memberships, err := s.ws.GroupMemberships(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range users {
+			// An empty list keeps the attribute, so ne matches users in no group.
+			users[i].Groups = append([]string{}, memberships[strings.ToLower(users[i].Email)]...)
+		}
+	}
+	now := time.Now()
+	ruleSet := s.rules.UserRules()
+	results := make([]UserRuleMatches, len(ruleSet))
+	for i, rule := range ruleSet {
+		results[i] = UserRuleMatches{Rule: rule, Users: []UserMatchEntry{}}
+	}
+	byEmail := make(map[string]workspace.DirectoryUser, len(users))
+	for _, u := range users {
+		byEmail[u.Email] = u
+		attrs := userAttributes(u)
+		for i, rule := range ruleSet {
+			if rule.Matches(attrs, now) {
+				results[i].Users = append(results[i].Users, UserMatchEntry{Email: u.Email, Name: u.Name})
+			}
+		}
+	}
+	return results, byEmail, nil
+}
+
+func (s *Server) runUserRules(ctx context.Context) {
+	if len(s.rules.UserRules()) == 0 {
+		return
+	}
+	interval := s.userRuleInterval
+	if interval <= 0 {
+		interval = defaultUserRuleInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.IsLeader() {
+				s.applyUserRules(ctx)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// applyUserRules starts the playbook of each rule for users that did not match
+// it at the previous evaluation. Earlier matches are read back from the event
+// history after a restart, so a playbook does not run twice for one user.
+func (s *Server) applyUserRules(parent context.Context) {
+	ctx, cancel := context.WithTimeout(parent, userRuleTimeout)
+	defer cancel()
+	results, users, err := s.matchUserRules(ctx)
+	if err != nil {
+		s.logger.Error("user rules: directory listing failed", "error", err)
+		return
+	}
+
+	s.userRulesMu.Lock()
+	defer s.userRulesMu.Unlock()
+	if s.userRuleMatches == nil {
+		s.userRuleMatches = s.pastUserMatches(ctx)
+	}
+	seen := make(map[string]bool)
+	for _, res := range results {
+		for _, m := range res.Users {
+			key := res.Rule.Name + "\x00" + m.Email
+			seen[key] = true
+			if s.userRuleMatches[key] {
+				continue
+			}
+			s.events.Emit(events.Event{
+				Type:    events.TypeUserRuleMatched,
+				Actor:   "rules",
+				Subject: m.Email,
+				Data:    map[string]any{"rule": res.Rule.Name, "name": m.Name, "playbook": res.Rule.Playbook},
+			})
+			if res.Rule.Playbook != "" {
+				s.startUserPlaybook(ctx, res.Rule, users[m.Email])
+			}
+		}
+	}
+	s.userRuleMatches = seen
+}
+
+// pastUserMatches restores the rule and user pairs already acted on.
+func (s *Server) pastUserMatches(ctx context.Context) map[string]bool {
+	past := make(map[string]bool)
+	list, err := s.store.ListEvents(ctx, store.Query{Types: []string{events.TypeUserRuleMatched}})
+	if err != nil {
+		s.logger.WarnContext(ctx, "user rules: event history unavailable", "error", err)
+		return past
+	}
+	for _, e := range list {
+		if rule, ok := e.Data["rule"].(string); ok {
+			past[rule+"\x00"+e.Subject] = true
+		}
+	}
+	return past
+}
+
+// startUserPlaybook runs a user rule's playbook for a newly matching user.
+func (s *Server) startUserPlaybook(ctx context.Context, rule rules.UserRule, u workspace.DirectoryUser) {
+	pb, ok := s.playbooks.Playbook(rule.Playbook)
+	if !ok {
+		s.logger.ErrorContext(ctx, "user rule references unknown playbook", "rule", rule.Name, "playbook", rule.Playbook)
+		return
+	}
+	s.runUserPlaybook(withActor(ctx, "user_rule:"+rule.Name), pb, u, map[string]any{"user_rule": rule.Name})
+}
+
+// runUserPlaybook runs pb with the user as ${email}, ${name}, ${id} and
+// ${Schema.field}, and reports it as playbook.completed with data added.
+func (s *Server) runUserPlaybook(ctx context.Context, pb playbook.Playbook, u workspace.DirectoryUser, data map[string]any) playbook.Result {
+	vars := map[string]string{"email": u.Email, "name": u.Name, "id": u.ID}
+	for key, v := range userAttributes(u) {
+		if _, set := vars[key]; set {
+			continue
+		}
+		if t, ok := v.(time.Time); ok {
+			vars[key] = t.Format(time.DateOnly)
+		} else {
+			vars[key] = anyString(v)
+		}
+	}
+	start := time.Now()
+	res := playbook.Run(ctx, pb, playbookExecutor{s}, vars)
+	s.logger.InfoContext(ctx, "user playbook executed", "user", u.Email, "playbook", pb.Name, "ok", res.OK, "duration", time.Since(start), "actor", actorFrom(ctx))
+	data["playbook"], data["ok"], data["steps"] = pb.Name, res.OK, res.Steps
+	s.events.Emit(events.Event{
+		Type:    events.TypePlaybookCompleted,
+		Actor:   actorFrom(ctx),
+		Subject: u.Email,
+		Data:    data,
+	})
+	return res
+}
+
+// handleUsers serves GET /api/users. With ?q=, ?limit= or ?page_token= it
+// returns one page of matching users, with the next page's token in
+// X-Next-Page-Token; otherwise every user.
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Has("q") || q.Has("limit") || q.Has("page_token") {
+		var limit int64
+		if raw := q.Get("limit"); raw != "" {
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || n < 1 || n > 500 {
+				apiError(w, "invalid limit (1-500)", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		users, next, err := s.ws.ListUsers(r.Context(), workspace.UserQuery{
+			Query: q.Get("q"), Schemas: s.userSchemas, PageSize: limit, PageToken: q.Get("page_token"),
+		})
+		if err != nil {
+			writeAPIError(w, err, http.StatusBadGateway)
+			return
+		}
+		if next != "" {
+			w.Header().Set("X-Next-Page-Token", next)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users)
+		return
+	}
+	users, err := s.ws.ListDirectoryUsers(r.Context(), s.userSchemas)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if users == nil {
+		users = []workspace.DirectoryUser{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// handleUserLookup serves POST /api/users/lookup, checking many emails in one
+// request.
+func (s *Server) handleUserLookup(w http.ResponseWriter, r *http.Request) {
+	var req UserLookupRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUserFieldsBody)).Decode(&req); err != nil {
+		apiError(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	seen := make(map[string]bool, len(req.Emails))
+	var emails []string
+	for _, e := range req.Emails {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e != "" && !seen[e] {
+			seen[e] = true
+			emails = append(emails, e)
+		}
+	}
+	switch {
+	case len(emails) == 0:
+		apiError(w, "missing emails", http.StatusBadRequest)
+		return
+	case len(emails) > maxLookupEmails:
+		apiError(w, fmt.Sprintf("at most %d emails per lookup", maxLookupEmails), http.StatusBadRequest)
+		return
+	}
+
+	found, missing, err := s.ws.LookupUsers(r.Context(), emails)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	if missing == nil {
+		missing = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserLookupResponse{Found: found, Missing: missing})
+}
+
+// handleUserFields serves PUT /api/users/fields?email=&schema=.
+func (s *Server) handleUserFields(w http.ResponseWriter, r *http.Request) {
+	email, schema := r.URL.Query().Get("email"), r.URL.Query().Get("schema")
+	if email == "" || schema == "" {
+		apiError(w, "missing email or schema", http.StatusBadRequest)
+		return
+	}
+	if s.currentMode() == "AIRGAP" {
+		apiError(w, "user fields cannot be written in AIRGAP mode", http.StatusConflict)
+		return
+	}
+	var req UserFieldsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUserFieldsBody)).Decode(&req); err != nil {
+		apiError(w, "invalid fields: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Fields) == 0 {
+		apiError(w, "missing fields", http.StatusBadRequest)
+		return
+	}
+	u, err := s.ws.SetUserFields(r.Context(), email, schema, req.Fields)
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	names := make([]string, 0, len(req.Fields))
+	for name := range req.Fields {
+		names = append(names, name)
+	}
+	s.logger.InfoContext(r.Context(), "user fields updated", "user", email, "schema", schema, "fields", names, "actor", actorFrom(r.Context()))
+	s.events.Emit(events.Event{
+		Type:    events.TypeUserUpdated,
+		Actor:   actorFrom(r.Context()),
+		Subject: email,
+		Data:    map[string]any{"schema": schema, "fields": names},
+	})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(u)
+}
+
+// handleUserRules reports current user rule matches without starting
+// playbooks; the scheduled evaluation does that.
+func (s *Server) handleUserRules(w http.ResponseWriter, r *http.Request) {
+	results, _, err := s.matchUserRules(r.Context())
+	if err != nil {
+		writeAPIError(w, err, http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
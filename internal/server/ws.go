@@ -0,0 +1,107 @@
+/*
+File: internal/server/ws.go
+Description: WebSocket uplink for clients behind proxies that buffer SSE. Carries
+the same registry, tick and status events as /api/events from the shared client
+hub, pings each client to keep idle connections alive, and closes with
+"going away" when the server shuts down.
+*/
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"axis/internal/broker"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsWriteTimeout = 10 * time.Second
+)
+
+// wsFrame is the JSON envelope for one hub event.
+type wsFrame struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// WithWebSocketOrigins allows cross-origin WebSocket clients whose Origin host
+// matches one of the patterns (path.Match syntax, e.g. "localhost:5173").
+func WithWebSocketOrigins(patterns []string) Option {
+	return func(s *Server) { s.wsOrigins = patterns }
+}
+
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: s.wsOrigins})
+	if err != nil {
+		// Accept has already written the HTTP error response.
+		s.logger.WarnContext(r.Context(), "websocket upgrade failed", "remote", r.RemoteAddr, "error", err)
+		return
+	}
+	defer conn.CloseNow()
+
+	sub, _, _, initial := s.subscribeClient(0)
+	defer s.hub.Unsubscribe(sub)
+
+	// Clients never send data frames; CloseRead still services control frames
+	// (pong, close) and cancels ctx once the peer goes away. It is detached from
+	// the request so shutdown can send a proper close frame below.
+	ctx := conn.CloseRead(context.WithoutCancel(r.Context()))
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	send := func(msg broker.Event) error {
+		if !streamVisible(r, msg) {
+			return nil
+		}
+		frame, err := json.Marshal(wsFrame{Event: msg.Type, Data: msg.Data})
+		if err != nil {
+			s.logger.ErrorContext(r.Context(), "websocket frame marshal failed", "error", err)
+			return nil
+		}
+		return s.wsWrite(ctx, conn, frame)
+	}
+	// The registry snapshot goes out before anything on the channel.
+	for _, msg := range initial {
+		if err := send(msg); err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				if s.hub.Closed() {
+					conn.Close(websocket.StatusGoingAway, "server shutting down")
+				} else {
+					conn.Close(websocket.StatusTryAgainLater, "client fell behind")
+				}
+				return
+			}
+			if err := send(msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			pctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+			err := conn.Ping(pctx)
+			cancel()
+			if err != nil {
+				s.logger.InfoContext(r.Context(), "websocket client unresponsive", "remote", r.RemoteAddr, "error", err)
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Server) wsWrite(ctx context.Context, conn *websocket.Conn, frame []byte) error {
+	wctx, cancel := context.WithTimeout(ctx, wsWriteTimeout)
+	defer cancel()
+	return conn.Write(wctx, websocket.MessageText, frame)
+}
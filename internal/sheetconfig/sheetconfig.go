@@ -0,0 +1,179 @@
+/*
+File: internal/sheetconfig/sheetconfig.go
+Description: Google Sheet backed policy source. Non-engineer admins maintain
+protection lists, in-scope users, and campaign assignments in a designated
+spreadsheet; Axis reads and validates it each cycle, keeping valid rows and
+reporting problems per row.
+*/
+package sheetconfig
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// Tab ranges read from the policy spreadsheet. Row 1 of each tab is a header.
+const (
+	RangeProtected = "Protected!A:B"
+	RangeUsers     = "Users!A:B"
+	RangeCampaigns = "Campaigns!A:B"
+)
+
+// ValueReader is the subset of workspace.Service needed to read the sheet.
+type ValueReader interface {
+	GetSheetValues(spreadsheetId, a1Range string) (*sheets.ValueRange, error)
+}
+
+// Problem describes a rejected row.
+type Problem struct {
+	Tab     string `json:"tab"`
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// Policy is the validated content of the spreadsheet.
+type Policy struct {
+	// Protected maps item IDs that must never be deleted to the stated reason.
+	Protected map[string]string `json:"protected"`
+	// Users lists the user emails in scope for automation.
+	Users []string `json:"users"`
+	// Campaigns maps item IDs to the campaign they are assigned to.
+	Campaigns map[string]string `json:"campaigns"`
+	Problems  []Problem         `json:"problems,omitempty"`
+	LoadedAt  time.Time         `json:"loaded_at"`
+}
+
+// IsProtected reports whether an item is on the protection list.
+func (p *Policy) IsProtected(id string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	reason, ok := p.Protected[id]
+	return reason, ok
+}
+
+// Loader reads the policy from a fixed spreadsheet.
+type Loader struct {
+	reader        ValueReader
+	spreadsheetID string
+}
+
+// NewLoader creates a loader for the given spreadsheet.
+func NewLoader(reader ValueReader, spreadsheetID string) *Loader {
+	return &Loader{reader: reader, spreadsheetID: spreadsheetID}
+}
+
+// SpreadsheetID returns the configured sheet ID.
+func (l *Loader) SpreadsheetID() string {
+	return l.spreadsheetID
+}
+
+// Load reads every tab. A tab that cannot be read fails the whole load so a
+// transient API error never drops the protection list; invalid rows only
+// produce problems.
+func (l *Loader) Load() (*Policy, error) {
+	p := &Policy{
+		Protected: make(map[string]string),
+		Campaigns: make(map[string]string),
+		LoadedAt:  time.Now().UTC(),
+	}
+
+	protected, err := l.rows(RangeProtected)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range protected {
+		id, reason := cell(row, 0), cell(row, 1)
+		switch {
+		case id == "":
+			p.problem("Protected", i, "missing item id")
+		case p.Protected[id] != "":
+			p.problem("Protected", i, fmt.Sprintf("duplicate item id %s", id))
+		default:
+			if reason == "" {
+				reason = "protected by policy sheet"
+			}
+			p.Protected[id] = reason
+		}
+	}
+
+	users, err := l.rows(RangeUsers)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i, row := range users {
+		email, enabled := strings.ToLower(cell(row, 0)), cell(row, 1)
+		if email == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(email); err != nil {
+			p.problem("Users", i, fmt.Sprintf("invalid email %q", email))
+			continue
+		}
+		if !isEnabled(enabled) {
+			continue
+		}
+		if seen[email] {
+			p.problem("Users", i, fmt.Sprintf("duplicate user %s", email))
+			continue
+		}
+		seen[email] = true
+		p.Users = append(p.Users, email)
+	}
+
+	campaigns, err := l.rows(RangeCampaigns)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range campaigns {
+		name, id := cell(row, 0), cell(row, 1)
+		switch {
+		case name == "" || id == "":
+			p.problem("Campaigns", i, "campaign and item id are required")
+		case p.Campaigns[id] != "" && p.Campaigns[id] != name:
+			p.problem("Campaigns", i, fmt.Sprintf("item %s already assigned to %s", id, p.Campaigns[id]))
+		default:
+			p.Campaigns[id] = name
+		}
+	}
+	return p, nil
+}
+
+// rows returns the data rows of a range, skipping the header.
+func (l *Loader) rows(a1Range string) ([][]any, error) {
+	vr, err := l.reader.GetSheetValues(l.spreadsheetID, a1Range)
+	if err != nil {
+		return nil, err
+	}
+	if len(vr.Values) <= 1 {
+		return nil, nil
+	}
+	return vr.Values[1:], nil
+}
+
+// problem records a row issue; idx is the data-row index, reported as the sheet row.
+func (p *Policy) problem(tab string, idx int, msg string) {
+	p.Problems = append(p.Problems, Problem{Tab: tab, Row: idx + 2, Message: msg})
+}
+
+func cell(row []any, i int) string {
+	if i >= len(row) || row[i] == nil {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprint(row[i]))
+}
+
+// isEnabled treats a blank enabled column as enabled.
+func isEnabled(v string) bool {
+	switch strings.ToLower(v) {
+	case "", "true", "yes", "y", "1", "x":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,117 @@
+/*
+File: internal/store/cluster.go
+Description: Coordination primitives for several Axis instances sharing one SQL
+or Redis store: named leases for leader election and an append-only broadcast log that
+relays client events and state changes between instances.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Broadcast is one event relayed between instances.
+type Broadcast struct {
+	ID     int64
+	Origin string
+	Type   string
+	Data   []byte
+	Time   time.Time
+}
+
+// Coordinator is implemented by stores that multiple instances can share. The
+// JSON file store is single-instance only and does not implement it.
+type Coordinator interface {
+	// AcquireLease takes or renews the named lease for holder. It reports false
+	// while another holder's lease is unexpired.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, holder string) error
+
+	AppendBroadcast(ctx context.Context, b Broadcast) error
+	// ListBroadcasts returns broadcasts with IDs above afterID, oldest first.
+	ListBroadcasts(ctx context.Context, afterID int64, limit int) ([]Broadcast, error)
+	LatestBroadcastID(ctx context.Context) (int64, error)
+	PruneBroadcasts(ctx context.Context, before time.Time) error
+}
+
+// AcquireLease implements Coordinator.
+func (s *SQLStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	res, err := s.exec(ctx,
+		`INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+		WHERE leases.holder = excluded.holder OR leases.expires_at < ?`,
+		name, holder, now.Add(ttl).UnixMicro(), now.UnixMicro())
+	if err != nil {
+		return false, fmt.Errorf("unable to acquire lease %s: %w", name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ReleaseLease implements Coordinator.
+func (s *SQLStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := s.exec(ctx, `DELETE FROM leases WHERE name = ? AND holder = ?`, name, holder); err != nil {
+		return fmt.Errorf("unable to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// AppendBroadcast implements Coordinator.
+func (s *SQLStore) AppendBroadcast(ctx context.Context, b Broadcast) error {
+	if b.Time.IsZero() {
+		b.Time = time.Now()
+	}
+	_, err := s.exec(ctx, `INSERT INTO broadcasts (origin, type, data, time) VALUES (?, ?, ?, ?)`,
+		b.Origin, b.Type, string(b.Data), b.Time.UnixMicro())
+	if err != nil {
+		return fmt.Errorf("unable to append %s broadcast: %w", b.Type, err)
+	}
+	return nil
+}
+
+// ListBroadcasts implements Coordinator.
+func (s *SQLStore) ListBroadcasts(ctx context.Context, afterID int64, limit int) ([]Broadcast, error) {
+	rows, err := s.query(ctx,
+		`SELECT id, origin, type, data, time FROM broadcasts WHERE id > ? ORDER BY id LIMIT ?`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list broadcasts: %w", err)
+	}
+	defer rows.Close()
+	var out []Broadcast
+	for rows.Next() {
+		var b Broadcast
+		var data string
+		var at int64
+		if err := rows.Scan(&b.ID, &b.Origin, &b.Type, &data, &at); err != nil {
+			return nil, err
+		}
+		b.Data = []byte(data)
+		b.Time = time.UnixMicro(at)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// LatestBroadcastID implements Coordinator.
+func (s *SQLStore) LatestBroadcastID(ctx context.Context) (int64, error) {
+	var id int64
+	row := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM broadcasts`)
+	if err := row.Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to read broadcast position: %w", err)
+	}
+	return id, nil
+}
+
+// PruneBroadcasts implements Coordinator.
+func (s *SQLStore) PruneBroadcasts(ctx context.Context, before time.Time) error {
+	if _, err := s.exec(ctx, `DELETE FROM broadcasts WHERE time < ?`, before.UnixMicro()); err != nil {
+		return fmt.Errorf("unable to prune broadcasts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+File: internal/store/comments.go
+Description: Operator comments on registry items. Comments are kept per item
+ID, attributed and timestamped; a reply names the comment it answers, so a
+thread is one top-level comment and its replies.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Comment is one operator comment on an item. ReplyTo is the ID of the
+// comment it answers, zero for a new thread.
+type Comment struct {
+	ID      int64     `json:"id"`
+	ItemID  string    `json:"item_id"`
+	ReplyTo int64     `json:"reply_to,omitempty"`
+	Author  string    `json:"author"`
+	Body    string    `json:"body"`
+	Time    time.Time `json:"time"`
+}
+
+// AddComment implements Store.
+func (s *SQLStore) AddComment(ctx context.Context, c Comment) (Comment, error) {
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	row := s.db.QueryRowContext(ctx, s.dialect.rebind(
+		`INSERT INTO comments (item_id, reply_to, author, body, time) VALUES (?, ?, ?, ?, ?) RETURNING id`),
+		c.ItemID, c.ReplyTo, c.Author, c.Body, c.Time.UnixMicro())
+	if err := row.Scan(&c.ID); err != nil {
+		return c, fmt.Errorf("unable to store comment: %w", err)
+	}
+	return c, nil
+}
+
+// ListComments implements Store.
+func (s *SQLStore) ListComments(ctx context.Context, itemID string) ([]Comment, error) {
+	where, args := "", []any(nil)
+	if itemID != "" {
+		where, args = " WHERE item_id = ?", []any{itemID}
+	}
+	rows, err := s.query(ctx, `SELECT id, item_id, reply_to, author, body, time FROM comments`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read comments: %w", err)
+	}
+	defer rows.Close()
+	var out []Comment
+	for rows.Next() {
+		var c Comment
+		var at int64
+		if err := rows.Scan(&c.ID, &c.ItemID, &c.ReplyTo, &c.Author, &c.Body, &at); err != nil {
+			return nil, err
+		}
+		c.Time = time.UnixMicro(at).UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
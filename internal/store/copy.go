@@ -0,0 +1,212 @@
+/*
+File: internal/store/copy.go
+Description: Backend-to-backend migration of Axis state, e.g. upgrading from the
+legacy axis.state.json or moving a SQLite deployment to Postgres before running
+several instances. Copies are verified by re-reading the destination.
+*/
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// CopyReport summarizes a Copy.
+type CopyReport struct {
+	Statuses    int `json:"statuses"`
+	Deletions   int `json:"deletions"`
+	Events      int `json:"events"`
+	Comments    int `json:"comments"`
+	Tokens      int `json:"tokens"`
+	Transitions int `json:"transitions"`
+}
+
+// Copy writes the mode, statuses, comments, status transitions, API tokens,
+// deletion history and audit events of src into dst. dst must hold no deletion
+// history, comments or transitions, since none carries a natural key and a
+// second copy would duplicate them. The SQL and Redis backends skip events they
+// already hold.
+func Copy(ctx context.Context, dst, src Store) (CopyReport, error) {
+	var rep CopyReport
+	existing, err := dst.ListDeletions(ctx, Query{Limit: 1})
+	if err != nil {
+		return rep, err
+	}
+	if len(existing) > 0 {
+		return rep, errors.New("destination already has deletion history")
+	}
+	if existing, err := dst.ListComments(ctx, ""); err != nil {
+		return rep, err
+	} else if len(existing) > 0 {
+		return rep, errors.New("destination already has comments")
+	}
+	if existing, err := dst.ListTransitions(ctx, ""); err != nil {
+		return rep, err
+	} else if len(existing) > 0 {
+		return rep, errors.New("destination already has status transitions")
+	}
+
+	st, err := src.LoadState(ctx)
+	if err != nil {
+		return rep, fmt.Errorf("unable to read source state: %w", err)
+	}
+	st, _ = NormalizeState(st)
+	if err := dst.SaveState(ctx, st); err != nil {
+		return rep, fmt.Errorf("unable to write state: %w", err)
+	}
+	rep.Statuses = len(st.Statuses)
+
+	deletions, err := src.ListDeletions(ctx, Query{})
+	if err != nil {
+		return rep, fmt.Errorf("unable to read source deletions: %w", err)
+	}
+	// Lists are newest first; replay oldest first to keep insertion order.
+	for i := len(deletions) - 1; i >= 0; i-- {
+		if err := dst.RecordDeletion(ctx, deletions[i]); err != nil {
+			return rep, err
+		}
+		rep.Deletions++
+	}
+
+	evts, err := src.ListEvents(ctx, Query{})
+	if err != nil {
+		return rep, fmt.Errorf("unable to read source events: %w", err)
+	}
+	for i := len(evts) - 1; i >= 0; i-- {
+		if err := dst.AppendEvent(ctx, evts[i]); err != nil {
+			return rep, err
+		}
+		rep.Events++
+	}
+
+	comments, err := src.ListComments(ctx, "")
+	if err != nil {
+		return rep, fmt.Errorf("unable to read source comments: %w", err)
+	}
+	// The destination numbers comments itself; replies follow their thread.
+	ids := make(map[int64]int64, len(comments))
+	for _, c := range comments {
+		old := c.ID
+		c.ID, c.ReplyTo = 0, ids[c.ReplyTo]
+		added, err := dst.AddComment(ctx, c)
+		if err != nil {
+			return rep, err
+		}
+		ids[old] = added.ID
+		rep.Comments++
+	}
+
+	transitions, err := src.ListTransitions(ctx, "")
+	if err != nil {
+		return rep, fmt.Errorf("unable to read source transitions: %w", err)
+	}
+	for _, t := range transitions {
+		t.ID = 0
+		if err := dst.RecordTransition(ctx, t); err != nil {
+			return rep, err
+		}
+		rep.Transitions++
+	}
+
+	tokens, err := src.ListTokens(ctx)
+	if err != nil {
+		return rep, fmt.Errorf("unable to read source tokens: %w", err)
+	}
+	for _, t := range tokens {
+		if err := dst.SaveToken(ctx, t); err != nil {
+			return rep, err
+		}
+		rep.Tokens++
+	}
+	return rep, nil
+}
+
+// NormalizeState upgrades status values written by older releases ("Keep" and
+// "Delete" became "Pending") and reports how many were changed.
+func NormalizeState(st State) (State, int) {
+	out := State{Mode: st.Mode, Statuses: make(map[string]string, len(st.Statuses)), Tags: st.Tags, Deadlines: st.Deadlines}
+	changed := 0
+	for id, status := range st.Statuses {
+		switch status {
+		case "Keep", "Delete":
+			status = "Pending"
+			changed++
+		}
+		out.Statuses[id] = status
+	}
+	return out, changed
+}
+
+// Inventory counts what a store holds.
+func Inventory(ctx context.Context, st Store) (CopyReport, error) {
+	var rep CopyReport
+	state, err := st.LoadState(ctx)
+	if err != nil {
+		return rep, fmt.Errorf("unable to read state: %w", err)
+	}
+	deletions, err := st.ListDeletions(ctx, Query{})
+	if err != nil {
+		return rep, fmt.Errorf("unable to read deletions: %w", err)
+	}
+	evts, err := st.ListEvents(ctx, Query{})
+	if err != nil {
+		return rep, fmt.Errorf("unable to read events: %w", err)
+	}
+	comments, err := st.ListComments(ctx, "")
+	if err != nil {
+		return rep, fmt.Errorf("unable to read comments: %w", err)
+	}
+	transitions, err := st.ListTransitions(ctx, "")
+	if err != nil {
+		return rep, fmt.Errorf("unable to read transitions: %w", err)
+	}
+	tokens, err := st.ListTokens(ctx)
+	if err != nil {
+		return rep, fmt.Errorf("unable to read tokens: %w", err)
+	}
+	return CopyReport{Statuses: len(state.Statuses), Deletions: len(deletions), Events: len(evts), Comments: len(comments), Transitions: len(transitions), Tokens: len(tokens)}, nil
+}
+
+// Verify checks that dst holds src's normalized mode and statuses and at least
+// as much history.
+func Verify(ctx context.Context, dst, src Store) error {
+	want, err := src.LoadState(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read source state: %w", err)
+	}
+	want, _ = NormalizeState(want)
+	got, err := dst.LoadState(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read destination state: %w", err)
+	}
+	if got.Mode != want.Mode {
+		return fmt.Errorf("mode mismatch: destination has %q, source %q", got.Mode, want.Mode)
+	}
+	if !maps.Equal(got.Statuses, want.Statuses) {
+		return fmt.Errorf("status mismatch: destination has %d statuses, source %d", len(got.Statuses), len(want.Statuses))
+	}
+	srcRep, err := Inventory(ctx, src)
+	if err != nil {
+		return err
+	}
+	dstRep, err := Inventory(ctx, dst)
+	if err != nil {
+		return err
+	}
+	if dstRep.Deletions < srcRep.Deletions || dstRep.Events < srcRep.Events {
+		return fmt.Errorf("history incomplete: destination has %d deletions and %d events, source %d and %d",
+			dstRep.Deletions, dstRep.Events, srcRep.Deletions, srcRep.Events)
+	}
+	if dstRep.Comments < srcRep.Comments {
+		return fmt.Errorf("comments incomplete: destination has %d, source %d", dstRep.Comments, srcRep.Comments)
+	}
+	if dstRep.Transitions < srcRep.Transitions {
+		return fmt.Errorf("transitions incomplete: destination has %d, source %d", dstRep.Transitions, srcRep.Transitions)
+	}
+	if dstRep.Tokens < srcRep.Tokens {
+		return fmt.Errorf("tokens incomplete: destination has %d, source %d", dstRep.Tokens, srcRep.Tokens)
+	}
+	return nil
+}
@@ -0,0 +1,633 @@
+/*
+File: internal/store/file.go
+Description: JSON file backend. Keeps the historical axis.state.json layout (mode and
+statuses) and adds bounded deletion history to the same document; events and journal
+entries, written far more often, are appended to a log beside it instead. Each
+document write goes to a temporary file that is synced and renamed over the state
+file, so a crash leaves either the old document or the new one. The previous
+documents are kept as numbered backups, and a state file that cannot be read falls
+back to the newest backup that can, or is set aside so Axis starts empty.
+*/
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+)
+
+const (
+	// fileHistoryLimit bounds each history list so the file stays small
+	// enough to rewrite on every change.
+	fileHistoryLimit = 5000
+	// fileBackups is how many earlier documents are kept, as path.1 (newest)
+	// to path.3.
+	fileBackups = 3
+	// fileBackupInterval spaces the backups out: the state file is rewritten
+	// on every change, so rotating on each write would leave only the last
+	// few seconds.
+	fileBackupInterval = 10 * time.Minute
+	// fileLogSlack is how many more lines than it keeps the log may hold
+	// before it is rewritten with only the kept entries.
+	fileLogSlack = 2 * fileHistoryLimit
+)
+
+// errCorruptState marks a state file that was read but could not be decoded.
+var errCorruptState = errors.New("corrupt state file")
+
+// fileDocument is the state file. Events and Journal are only read from
+// documents written before the log, and are moved to the log on the first
+// write.
+type fileDocument struct {
+	Mode        string               `json:"mode"`
+	Statuses    map[string]string    `json:"statuses"`
+	Tags        map[string][]string  `json:"tags,omitempty"`
+	Deadlines   map[string]time.Time `json:"deadlines,omitempty"`
+	Deletions   []Deletion           `json:"deletions,omitempty"`
+	Events      []events.Event       `json:"events,omitempty"`
+	Journal     []JournalEntry       `json:"journal,omitempty"`
+	Comments    []Comment            `json:"comments,omitempty"`
+	Transitions []Transition         `json:"transitions,omitempty"`
+	Tokens      []Token              `json:"tokens,omitempty"`
+}
+
+// fileLogRecord is one line of the log: an event or a journal entry.
+type fileLogRecord struct {
+	Event   *events.Event `json:"event,omitempty"`
+	Journal *JournalEntry `json:"journal,omitempty"`
+}
+
+// FileStore persists state as a JSON document and a log of history lines.
+type FileStore struct {
+	path        string
+	mu          sync.Mutex
+	doc         fileDocument
+	loaded      bool
+	rotated     time.Time // when the last backup was taken
+	recovered   string    // the backup loaded in place of an unreadable state file
+	quarantined string    // where an unreadable state file with no usable backup was moved
+	corrupt     bool      // the state file on disk is not worth keeping as a backup
+	logLines    int       // lines in the log, kept or not
+	compact     bool      // the log must be rewritten before the next append or document write
+}
+
+// NewFileStore returns a store backed by the JSON file at path. The file is
+// read lazily and created on first write.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// loadLocked reads the document and the log once; a missing file with no
+// backups is an empty state. A state file that is missing or unreadable while
+// a backup can be read is replaced by the newest such backup. A corrupt state
+// file with no usable backup is renamed to path.corrupt-<time> and the state
+// starts empty, so one bad file does not fail every write.
+func (f *FileStore) loadLocked() error {
+	if f.loaded {
+		return nil
+	}
+	doc, err := readFileDocument(f.path)
+	if err != nil {
+		backup, bdoc, ok := f.readBackup()
+		switch {
+		case ok:
+			doc, f.recovered, f.corrupt = bdoc, backup, true
+		case errors.Is(err, os.ErrNotExist):
+		case errors.Is(err, errCorruptState):
+			moved := fmt.Sprintf("%s.corrupt-%s", f.path, time.Now().UTC().Format("20060102T150405Z"))
+			if rerr := os.Rename(f.path, moved); rerr != nil {
+				return fmt.Errorf("%w; unable to set it aside: %v", err, rerr)
+			}
+			doc, f.quarantined = fileDocument{}, moved
+		default:
+			return err
+		}
+	}
+	if err := f.readLog(&doc); err != nil {
+		return err
+	}
+	f.doc, f.loaded = doc, true
+	return nil
+}
+
+// readBackup returns the newest backup that can be read.
+func (f *FileStore) readBackup() (string, fileDocument, bool) {
+	for i := 1; i <= fileBackups; i++ {
+		backup := f.backupPath(i)
+		if doc, err := readFileDocument(backup); err == nil {
+			return backup, doc, true
+		}
+	}
+	return "", fileDocument{}, false
+}
+
+func (f *FileStore) logPath() string {
+	return f.path + ".log"
+}
+
+// readLog replaces the document's events and journal with the log's. Without
+// a log the document's own, from before the log, are kept until the first
+// write moves them. A line that cannot be decoded, such as one torn by a
+// crash, is skipped and the log rewritten before the next append.
+func (f *FileStore) readLog(doc *fileDocument) error {
+	data, err := os.ReadFile(f.logPath())
+	if errors.Is(err, os.ErrNotExist) {
+		f.compact = len(doc.Events) > 0 || len(doc.Journal) > 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read state log %s: %w", f.logPath(), err)
+	}
+	doc.Events, doc.Journal = nil, nil
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		f.logLines++
+		var rec fileLogRecord
+		switch {
+		case json.Unmarshal(line, &rec) != nil:
+			f.compact = true
+		case rec.Event != nil:
+			doc.Events = appendBounded(doc.Events, *rec.Event)
+		case rec.Journal != nil:
+			doc.Journal = appendBounded(doc.Journal, *rec.Journal)
+		}
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		f.compact = true
+	}
+	return nil
+}
+
+// readFileDocument reads and decodes one state document.
+func readFileDocument(path string) (fileDocument, error) {
+	var doc fileDocument
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return doc, err
+		}
+		return doc, fmt.Errorf("unable to read state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("%w %s: %w", errCorruptState, path, err)
+	}
+	return doc, nil
+}
+
+func (f *FileStore) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+// Recovered implements Recoverer.
+func (f *FileStore) Recovered() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.recovered
+}
+
+// Quarantined implements Recoverer.
+func (f *FileStore) Quarantined() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.quarantined
+}
+
+// writeLocked replaces the state file with the current document, without
+// the history the log holds.
+func (f *FileStore) writeLocked() error {
+	if f.compact {
+		// History from a document written before the log must reach the
+		// log before the document stops carrying it.
+		if err := f.compactLocked(); err != nil {
+			return err
+		}
+	}
+	doc := f.doc
+	doc.Events, doc.Journal = nil, nil
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if time.Since(f.rotated) >= fileBackupInterval {
+		f.rotateLocked()
+	}
+	if err := replaceFile(f.path, data); err != nil {
+		return fmt.Errorf("unable to write state file %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// replaceFile writes data under a temporary name in path's directory, syncs
+// it and renames it over path.
+func replaceFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// rotateLocked shifts the backups up by one and links the state file as
+// path.1, copying it where hard links are unsupported; the state file itself
+// stays in place until the new document is renamed over it. A recovered
+// state file is left for the next write to replace, so the corrupt one never
+// becomes a backup.
+func (f *FileStore) rotateLocked() {
+	f.rotated = time.Now()
+	if f.corrupt {
+		f.corrupt = false
+		return
+	}
+	for i := fileBackups - 1; i >= 1; i-- {
+		os.Rename(f.backupPath(i), f.backupPath(i+1))
+	}
+	backup := f.backupPath(1)
+	os.Remove(backup)
+	if os.Link(f.path, backup) == nil {
+		return
+	}
+	if data, err := os.ReadFile(f.path); err == nil {
+		replaceFile(backup, data)
+	}
+}
+
+// appendLocked adds one record to the log, or rewrites the log when it is
+// due. The record is already part of the document.
+func (f *FileStore) appendLocked(rec fileLogRecord) error {
+	if f.compact || f.logLines >= len(f.doc.Events)+len(f.doc.Journal)+fileLogSlack {
+		return f.compactLocked()
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	log, err := os.OpenFile(f.logPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to append to state log %s: %w", f.logPath(), err)
+	}
+	_, err = log.Write(append(line, '\n'))
+	if err == nil {
+		err = log.Sync()
+	}
+	if cerr := log.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// A partial line is skipped on load; rewrite the log next time so
+		// the following lines do not run into it.
+		f.compact = true
+		return fmt.Errorf("unable to append to state log %s: %w", f.logPath(), err)
+	}
+	f.logLines++
+	return nil
+}
+
+// compactLocked replaces the log with the events and journal entries the
+// document keeps.
+func (f *FileStore) compactLocked() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range f.doc.Events {
+		if err := enc.Encode(fileLogRecord{Event: &f.doc.Events[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range f.doc.Journal {
+		if err := enc.Encode(fileLogRecord{Journal: &f.doc.Journal[i]}); err != nil {
+			return err
+		}
+	}
+	if err := replaceFile(f.logPath(), buf.Bytes()); err != nil {
+		return fmt.Errorf("unable to write state log %s: %w", f.logPath(), err)
+	}
+	f.logLines, f.compact = len(f.doc.Events)+len(f.doc.Journal), false
+	return nil
+}
+
+// syncDir makes a rename in dir durable. Not every platform can sync a
+// directory, so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// Probe implements Prober by creating and removing a file next to the state
+// file, so a read-only or full volume is caught before a save fails.
+func (f *FileStore) Probe(ctx context.Context) error {
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), ".axis-probe-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.WriteString(time.Now().UTC().Format(time.RFC3339))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if rerr := os.Remove(tmp.Name()); err == nil {
+		err = rerr
+	}
+	return err
+}
+
+// LoadState implements Store.
+func (f *FileStore) LoadState(ctx context.Context) (State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return State{}, err
+	}
+	statuses := make(map[string]string, len(f.doc.Statuses))
+	for k, v := range f.doc.Statuses {
+		statuses[k] = v
+	}
+	return State{Mode: f.doc.Mode, Statuses: statuses, Tags: maps.Clone(f.doc.Tags), Deadlines: maps.Clone(f.doc.Deadlines)}, nil
+}
+
+// SaveState implements Store.
+func (f *FileStore) SaveState(ctx context.Context, st State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return err
+	}
+	f.doc.Mode = st.Mode
+	f.doc.Statuses = st.Statuses
+	f.doc.Tags = st.Tags
+	f.doc.Deadlines = st.Deadlines
+	return f.writeLocked()
+}
+
+// RecordDeletion implements Store.
+func (f *FileStore) RecordDeletion(ctx context.Context, d Deletion) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return err
+	}
+	f.doc.Deletions = appendBounded(f.doc.Deletions, d)
+	return f.writeLocked()
+}
+
+// ListDeletions implements Store.
+func (f *FileStore) ListDeletions(ctx context.Context, q Query) ([]Deletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	var out []Deletion
+	for _, d := range f.doc.Deletions {
+		if q.matches(d.Time, d.ItemType) {
+			out = append(out, d)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return limit(out, q.Limit), nil
+}
+
+// AppendEvent implements Store.
+func (f *FileStore) AppendEvent(ctx context.Context, e events.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return err
+	}
+	f.doc.Events = appendBounded(f.doc.Events, e)
+	return f.appendLocked(fileLogRecord{Event: &e})
+}
+
+// ListEvents implements Store.
+func (f *FileStore) ListEvents(ctx context.Context, q Query) ([]events.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	var out []events.Event
+	for _, e := range f.doc.Events {
+		if q.matches(e.Time, e.Type) {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return limit(out, q.Limit), nil
+}
+
+// Close implements Store.
+func (f *FileStore) Close() error {
+	return nil
+}
+
+// AppendJournal implements Store.
+func (f *FileStore) AppendJournal(ctx context.Context, e JournalEntry) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return 0, err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Seq = 1
+	if n := len(f.doc.Journal); n > 0 {
+		e.Seq = f.doc.Journal[n-1].Seq + 1
+	}
+	f.doc.Journal = appendBounded(f.doc.Journal, e)
+	return e.Seq, f.appendLocked(fileLogRecord{Journal: &e})
+}
+
+// ListJournal implements Store.
+func (f *FileStore) ListJournal(ctx context.Context, q JournalQuery) ([]JournalEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	filter := Query{Since: q.Since, Types: q.Types}
+	var out []JournalEntry
+	for _, e := range f.doc.Journal {
+		if e.Seq > q.AfterSeq && filter.matches(e.Time, e.Type) {
+			out = append(out, e)
+		}
+	}
+	return limit(out, q.Limit), nil
+}
+
+// PruneJournal implements Store.
+func (f *FileStore) PruneJournal(ctx context.Context, before time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return err
+	}
+	kept := f.doc.Journal[:0]
+	for _, e := range f.doc.Journal {
+		if !e.Time.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(f.doc.Journal) {
+		return nil
+	}
+	f.doc.Journal = kept
+	return f.compactLocked()
+}
+
+// AddComment implements Store. Comments are not bounded like the history
+// lists, since dropping old ones would lose discussions silently.
+func (f *FileStore) AddComment(ctx context.Context, c Comment) (Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return c, err
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	c.ID = 1
+	if n := len(f.doc.Comments); n > 0 {
+		c.ID = f.doc.Comments[n-1].ID + 1
+	}
+	f.doc.Comments = append(f.doc.Comments, c)
+	return c, f.writeLocked()
+}
+
+// ListComments implements Store.
+func (f *FileStore) ListComments(ctx context.Context, itemID string) ([]Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	var out []Comment
+	for _, c := range f.doc.Comments {
+		if itemID == "" || c.ItemID == itemID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// RecordTransition implements Store. The history is bounded like the other
+// history lists, oldest changes first out.
+func (f *FileStore) RecordTransition(ctx context.Context, t Transition) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return err
+	}
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	t.ID = 1
+	if n := len(f.doc.Transitions); n > 0 {
+		t.ID = f.doc.Transitions[n-1].ID + 1
+	}
+	f.doc.Transitions = appendBounded(f.doc.Transitions, t)
+	return f.writeLocked()
+}
+
+// ListTransitions implements Store.
+func (f *FileStore) ListTransitions(ctx context.Context, itemID string) ([]Transition, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	var out []Transition
+	for _, t := range f.doc.Transitions {
+		if itemID == "" || t.ItemID == itemID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// SaveToken implements Store.
+func (f *FileStore) SaveToken(ctx context.Context, t Token) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return err
+	}
+	for _, o := range f.doc.Tokens {
+		if o.ID == t.ID || o.Digest == t.Digest {
+			return fmt.Errorf("unable to store token %s: duplicate", t.Name)
+		}
+	}
+	f.doc.Tokens = append(f.doc.Tokens, t)
+	return f.writeLocked()
+}
+
+// ListTokens implements Store.
+func (f *FileStore) ListTokens(ctx context.Context) ([]Token, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.doc.Tokens), nil
+}
+
+// DeleteToken implements Store.
+func (f *FileStore) DeleteToken(ctx context.Context, id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.loadLocked(); err != nil {
+		return false, err
+	}
+	n := len(f.doc.Tokens)
+	f.doc.Tokens = slices.DeleteFunc(f.doc.Tokens, func(t Token) bool { return t.ID == id })
+	if len(f.doc.Tokens) == n {
+		return false, nil
+	}
+	return true, f.writeLocked()
+}
+
+func appendBounded[T any](list []T, v T) []T {
+	list = append(list, v)
+	if len(list) > fileHistoryLimit {
+		list = list[len(list)-fileHistoryLimit:]
+	}
+	return list
+}
+
+func limit[T any](list []T, n int) []T {
+	if n > 0 && len(list) > n {
+		return list[:n]
+	}
+	return list
+}
@@ -0,0 +1,146 @@
+/*
+File: internal/store/file_test.go
+Description: The file backend's recovery: truncated and missing state files fall
+back to a backup, a corrupt file with none is set aside for an empty state, and
+history appended to the log survives a restart.
+*/
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"axis/internal/events"
+)
+
+// saved writes a state with one status through a fresh store and returns the
+// state file's path.
+func saved(t *testing.T, status string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "axis.state.json")
+	if err := NewFileStore(path).SaveState(context.Background(), State{Mode: "MANUAL", Statuses: map[string]string{"notes/a": status}}); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadStatus(t *testing.T, f *FileStore) string {
+	t.Helper()
+	st, err := f.LoadState(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st.Statuses["notes/a"]
+}
+
+func TestFileStoreTruncatedFallsBackToBackup(t *testing.T) {
+	path := saved(t, "Keep")
+	// The first write after opening links the state file as the backup.
+	if err := NewFileStore(path).SaveState(context.Background(), State{Mode: "MANUAL", Statuses: map[string]string{"notes/a": "Execute"}}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, data[:len(data)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFileStore(path)
+	if got := loadStatus(t, f); got != "Keep" {
+		t.Errorf("status %q, want the backup's Keep", got)
+	}
+	if f.Recovered() != path+".1" || f.Quarantined() != "" {
+		t.Errorf("recovered %q, quarantined %q", f.Recovered(), f.Quarantined())
+	}
+}
+
+func TestFileStoreMissingFallsBackToBackup(t *testing.T) {
+	path := saved(t, "Keep")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFileStore(path)
+	if got := loadStatus(t, f); got != "Keep" {
+		t.Errorf("status %q, want the backup's Keep", got)
+	}
+	if f.Recovered() != path+".1" {
+		t.Errorf("recovered %q", f.Recovered())
+	}
+}
+
+func TestFileStoreCorruptWithoutBackupStartsEmpty(t *testing.T) {
+	path := saved(t, "Keep")
+	if err := os.WriteFile(path, []byte(`{"mode": "MAN`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFileStore(path)
+	if got := loadStatus(t, f); got != "" {
+		t.Errorf("status %q, want empty state", got)
+	}
+	moved := f.Quarantined()
+	if !strings.HasPrefix(moved, path+".corrupt-") {
+		t.Fatalf("quarantined %q", moved)
+	}
+	if data, err := os.ReadFile(moved); err != nil || string(data) != `{"mode": "MAN` {
+		t.Errorf("set-aside file %q, %v", data, err)
+	}
+	if err := f.SaveState(context.Background(), State{Mode: "AUTO"}); err != nil {
+		t.Errorf("write after starting empty: %v", err)
+	}
+}
+
+func TestFileStoreHistoryLog(t *testing.T) {
+	ctx := context.Background()
+	path := saved(t, "Keep")
+	f := NewFileStore(path)
+	if err := f.AppendEvent(ctx, events.Event{Type: events.TypeModeChanged, Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.AppendJournal(ctx, JournalEntry{Type: "status"}); err != nil {
+		t.Fatal(err)
+	}
+	doc, _ := os.ReadFile(path)
+	if strings.Contains(string(doc), events.TypeModeChanged) {
+		t.Error("event written into the state file")
+	}
+	// A crash mid-append leaves a torn line, which is skipped.
+	log, _ := os.OpenFile(path+".log", os.O_WRONLY|os.O_APPEND, 0)
+	log.WriteString(`{"event": {"ty`)
+	log.Close()
+
+	f = NewFileStore(path)
+	evs, err := f.ListEvents(ctx, Query{})
+	if err != nil || len(evs) != 1 {
+		t.Fatalf("events %v, %v", evs, err)
+	}
+	seq, err := f.AppendJournal(ctx, JournalEntry{Type: "status"})
+	if err != nil || seq != 2 {
+		t.Fatalf("journal seq %d, %v", seq, err)
+	}
+	entries, _ := NewFileStore(path).ListJournal(ctx, JournalQuery{})
+	if len(entries) != 2 {
+		t.Errorf("journal after restart %v", entries)
+	}
+}
+
+func TestFileStoreMovesInlineHistoryToLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "axis.state.json")
+	legacy := `{"mode": "AUTO", "statuses": {}, "events": [{"type": "mode.changed", "time": "2026-01-02T03:04:05Z"}]}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFileStore(path).SaveState(ctx, State{Mode: "MANUAL"}); err != nil {
+		t.Fatal(err)
+	}
+	if doc, _ := os.ReadFile(path); strings.Contains(string(doc), "mode.changed") {
+		t.Error("history still in the state file")
+	}
+	evs, err := NewFileStore(path).ListEvents(ctx, Query{})
+	if err != nil || len(evs) != 1 {
+		t.Errorf("events after the move %v, %v", evs, err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+File: internal/store/journal.go
+Description: Event journal. A bounded, time-retained record of the client event
+stream (registry deltas, status changes, simulations and audit events) that
+reconnecting clients replay through /api/events/replay.
+*/
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JournalEntry is one recorded stream event. Seq increases monotonically per
+// store and is the resume cursor.
+type JournalEntry struct {
+	Seq  int64           `json:"seq"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// JournalQuery selects entries after a cursor: entries with Seq above AfterSeq
+// and not older than Since. Types filters by entry type.
+type JournalQuery struct {
+	AfterSeq int64
+	Since    time.Time
+	Types    []string
+	Limit    int
+}
+
+// AppendJournal implements Store.
+func (s *SQLStore) AppendJournal(ctx context.Context, e JournalEntry) (int64, error) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	var seq int64
+	row := s.db.QueryRowContext(ctx, s.dialect.rebind(
+		`INSERT INTO journal (type, time, data) VALUES (?, ?, ?) RETURNING seq`),
+		e.Type, e.Time.UnixMicro(), string(e.Data))
+	if err := row.Scan(&seq); err != nil {
+		return 0, fmt.Errorf("unable to journal %s: %w", e.Type, err)
+	}
+	return seq, nil
+}
+
+// ListJournal implements Store.
+func (s *SQLStore) ListJournal(ctx context.Context, q JournalQuery) ([]JournalEntry, error) {
+	where, args := Query{Since: q.Since, Types: q.Types}.sqlFilter("time", "type")
+	if where == "" {
+		where = " WHERE seq > ?"
+	} else {
+		where += " AND seq > ?"
+	}
+	args = append(args, q.AfterSeq)
+	rows, err := s.query(ctx,
+		`SELECT seq, type, time, data FROM journal`+where+` ORDER BY seq`+Query{Limit: q.Limit}.sqlLimit(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read journal: %w", err)
+	}
+	defer rows.Close()
+	var out []JournalEntry
+	for rows.Next() {
+		var e JournalEntry
+		var at int64
+		var data string
+		if err := rows.Scan(&e.Seq, &e.Type, &at, &data); err != nil {
+			return nil, err
+		}
+		e.Time = time.UnixMicro(at).UTC()
+		e.Data = json.RawMessage(data)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// PruneJournal implements Store.
+func (s *SQLStore) PruneJournal(ctx context.Context, before time.Time) error {
+	if _, err := s.exec(ctx, `DELETE FROM journal WHERE time < ?`, before.UnixMicro()); err != nil {
+		return fmt.Errorf("unable to prune journal: %w", err)
+	}
+	return nil
+}
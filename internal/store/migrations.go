@@ -0,0 +1,376 @@
+/*
+File: internal/store/migrations.go
+Description: Versioned schema migrations for the SQL backends. Each migration runs
+once inside a transaction and is recorded in schema_migrations; append new
+versions, never edit applied ones.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type migration struct {
+	version int
+	name    string
+	sql     []string
+}
+
+var sqliteMigrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		sql: []string{
+			`CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+			`CREATE TABLE statuses (item_id TEXT PRIMARY KEY, status TEXT NOT NULL, updated_at BIGINT NOT NULL)`,
+			`CREATE TABLE deletions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				time BIGINT NOT NULL,
+				item_id TEXT NOT NULL,
+				item_type TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				actor TEXT NOT NULL DEFAULT '',
+				mode TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX deletions_time ON deletions (time)`,
+			`CREATE TABLE audit_events (
+				id TEXT PRIMARY KEY,
+				type TEXT NOT NULL,
+				time BIGINT NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				subject TEXT NOT NULL DEFAULT '',
+				data TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX audit_events_time ON audit_events (time)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "deletion outcomes",
+		sql: []string{
+			`ALTER TABLE deletions ADD COLUMN outcome TEXT NOT NULL DEFAULT 'deleted'`,
+			`ALTER TABLE deletions ADD COLUMN detail TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 3,
+		name:    "cluster coordination",
+		sql: []string{
+			`CREATE TABLE leases (name TEXT PRIMARY KEY, holder TEXT NOT NULL, expires_at BIGINT NOT NULL)`,
+			`CREATE TABLE broadcasts (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				origin TEXT NOT NULL,
+				type TEXT NOT NULL,
+				data TEXT NOT NULL DEFAULT '',
+				time BIGINT NOT NULL
+			)`,
+			`CREATE INDEX broadcasts_time ON broadcasts (time)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "event journal",
+		sql: []string{
+			`CREATE TABLE journal (
+				seq INTEGER PRIMARY KEY AUTOINCREMENT,
+				type TEXT NOT NULL,
+				time BIGINT NOT NULL,
+				data TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX journal_time ON journal (time)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "item tags",
+		sql: []string{
+			`CREATE TABLE tags (item_id TEXT NOT NULL, tag TEXT NOT NULL, PRIMARY KEY (item_id, tag))`,
+		},
+	},
+	{
+		version: 6,
+		name:    "registry snapshots",
+		sql: []string{
+			`CREATE TABLE registry_snapshots (time BIGINT PRIMARY KEY, items TEXT NOT NULL)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "item comments",
+		sql: []string{
+			`CREATE TABLE comments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				item_id TEXT NOT NULL,
+				reply_to BIGINT NOT NULL DEFAULT 0,
+				author TEXT NOT NULL,
+				body TEXT NOT NULL,
+				time BIGINT NOT NULL
+			)`,
+			`CREATE INDEX comments_item ON comments (item_id)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "deletion backups",
+		sql: []string{
+			`ALTER TABLE deletions ADD COLUMN backup TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 9,
+		name:    "deletion deadlines",
+		sql: []string{
+			`CREATE TABLE deadlines (item_id TEXT PRIMARY KEY, due_at BIGINT NOT NULL)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "api tokens",
+		sql: []string{
+			`CREATE TABLE tokens (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				digest TEXT NOT NULL UNIQUE,
+				scopes TEXT NOT NULL,
+				campaign TEXT NOT NULL DEFAULT '',
+				created_by TEXT NOT NULL DEFAULT '',
+				created BIGINT NOT NULL,
+				expires BIGINT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "status transitions",
+		sql: []string{
+			`CREATE TABLE transitions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				item_id TEXT NOT NULL,
+				from_status TEXT NOT NULL DEFAULT '',
+				to_status TEXT NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				time BIGINT NOT NULL
+			)`,
+			`CREATE INDEX transitions_item ON transitions (item_id)`,
+		},
+	},
+}
+
+// postgresMigrations mirror sqliteMigrations version for version.
+var postgresMigrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		sql: []string{
+			`CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+			`CREATE TABLE statuses (item_id TEXT PRIMARY KEY, status TEXT NOT NULL, updated_at BIGINT NOT NULL)`,
+			`CREATE TABLE deletions (
+				id BIGSERIAL PRIMARY KEY,
+				time BIGINT NOT NULL,
+				item_id TEXT NOT NULL,
+				item_type TEXT NOT NULL,
+				title TEXT NOT NULL DEFAULT '',
+				actor TEXT NOT NULL DEFAULT '',
+				mode TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX deletions_time ON deletions (time)`,
+			`CREATE TABLE audit_events (
+				id TEXT PRIMARY KEY,
+				type TEXT NOT NULL,
+				time BIGINT NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				subject TEXT NOT NULL DEFAULT '',
+				data TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX audit_events_time ON audit_events (time)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "deletion outcomes",
+		sql: []string{
+			`ALTER TABLE deletions ADD COLUMN outcome TEXT NOT NULL DEFAULT 'deleted'`,
+			`ALTER TABLE deletions ADD COLUMN detail TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 3,
+		name:    "cluster coordination",
+		sql: []string{
+			`CREATE TABLE leases (name TEXT PRIMARY KEY, holder TEXT NOT NULL, expires_at BIGINT NOT NULL)`,
+			`CREATE TABLE broadcasts (
+				id BIGSERIAL PRIMARY KEY,
+				origin TEXT NOT NULL,
+				type TEXT NOT NULL,
+				data TEXT NOT NULL DEFAULT '',
+				time BIGINT NOT NULL
+			)`,
+			`CREATE INDEX broadcasts_time ON broadcasts (time)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "event journal",
+		sql: []string{
+			`CREATE TABLE journal (
+				seq BIGSERIAL PRIMARY KEY,
+				type TEXT NOT NULL,
+				time BIGINT NOT NULL,
+				data TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX journal_time ON journal (time)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "item tags",
+		sql: []string{
+			`CREATE TABLE tags (item_id TEXT NOT NULL, tag TEXT NOT NULL, PRIMARY KEY (item_id, tag))`,
+		},
+	},
+	{
+		version: 6,
+		name:    "registry snapshots",
+		sql: []string{
+			`CREATE TABLE registry_snapshots (time BIGINT PRIMARY KEY, items TEXT NOT NULL)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "item comments",
+		sql: []string{
+			`CREATE TABLE comments (
+				id BIGSERIAL PRIMARY KEY,
+				item_id TEXT NOT NULL,
+				reply_to BIGINT NOT NULL DEFAULT 0,
+				author TEXT NOT NULL,
+				body TEXT NOT NULL,
+				time BIGINT NOT NULL
+			)`,
+			`CREATE INDEX comments_item ON comments (item_id)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "deletion backups",
+		sql: []string{
+			`ALTER TABLE deletions ADD COLUMN backup TEXT NOT NULL DEFAULT ''`,
+		},
+	},
+	{
+		version: 9,
+		name:    "deletion deadlines",
+		sql: []string{
+			`CREATE TABLE deadlines (item_id TEXT PRIMARY KEY, due_at BIGINT NOT NULL)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "api tokens",
+		sql: []string{
+			`CREATE TABLE tokens (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				digest TEXT NOT NULL UNIQUE,
+				scopes TEXT NOT NULL,
+				campaign TEXT NOT NULL DEFAULT '',
+				created_by TEXT NOT NULL DEFAULT '',
+				created BIGINT NOT NULL,
+				expires BIGINT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "status transitions",
+		sql: []string{
+			`CREATE TABLE transitions (
+				id BIGSERIAL PRIMARY KEY,
+				item_id TEXT NOT NULL,
+				from_status TEXT NOT NULL DEFAULT '',
+				to_status TEXT NOT NULL,
+				actor TEXT NOT NULL DEFAULT '',
+				time BIGINT NOT NULL
+			)`,
+			`CREATE INDEX transitions_item ON transitions (item_id)`,
+		},
+	},
+}
+
+// AppliedMigration is one row of schema_migrations.
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Migrations lists the applied schema migrations, oldest first.
+func (s *SQLStore) Migrations(ctx context.Context) ([]AppliedMigration, error) {
+	rows, err := s.query(ctx, `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	var out []AppliedMigration
+	for rows.Next() {
+		var m AppliedMigration
+		var at int64
+		if err := rows.Scan(&m.Version, &m.Name, &at); err != nil {
+			return nil, err
+		}
+		m.AppliedAt = time.UnixMicro(at).UTC()
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// Dialect names the SQL database behind the store.
+func (s *SQLStore) Dialect() string { return s.dialect.name }
+
+func (s *SQLStore) migrate(ctx context.Context) error {
+	if _, err := s.exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at BIGINT NOT NULL)`); err != nil {
+		return fmt.Errorf("unable to create schema_migrations: %w", err)
+	}
+
+	applied := make(map[int]bool)
+	rows, err := s.query(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("unable to read schema_migrations: %w", err)
+	}
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return err
+		}
+		applied[v] = true
+	}
+	rows.Close()
+
+	for _, m := range s.dialect.migrations {
+		if applied[m.version] {
+			continue
+		}
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range m.sql {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.rebind(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`),
+			m.version, m.name, time.Now().UnixMicro()); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+/*
+File: internal/store/postgres.go
+Description: Postgres backend using the pgx driver, for shared (multi-instance)
+deployments and registries too large for a single SQLite file.
+*/
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+var postgresDialect = dialect{name: "postgres", positional: true, migrations: postgresMigrations}
+
+// OpenPostgres connects to the database at dsn (a postgres:// URL or key=value
+// string) and applies pending migrations.
+func OpenPostgres(dsn string) (*SQLStore, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open postgres: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to reach postgres: %w", err)
+	}
+	return newSQLStore(db, postgresDialect)
+}
@@ -0,0 +1,679 @@
+/*
+File: internal/store/redis.go
+Description: Redis implementation of Store and Coordinator, for deployments
+that already run Redis and want to share state between instances without a SQL
+database. State lives in hashes under a key prefix; deletions and events are
+sorted sets scored by time, and the sequenced lists (journal, comments,
+transitions, broadcasts) are sorted sets scored by an ID that a script assigns
+and stores in one step.
+*/
+package store
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+)
+
+const (
+	// redisHistoryLimit bounds deletions, events and transitions, which the
+	// SQL backends keep in full, so the data set stays in memory comfortably.
+	redisHistoryLimit = 100000
+	// redisPage is how many entries a filtered read fetches at a time.
+	redisPage = 500
+	// redisChunk bounds the fields sent in one HSET or HDEL.
+	redisChunk = 1000
+	// redisSaveAttempts is how often SaveState retries a transaction that
+	// another instance's save interrupted.
+	redisSaveAttempts = 3
+)
+
+// errStateRaced is a SaveState transaction aborted by a concurrent save.
+var errStateRaced = errors.New("state changed during save")
+
+// Scripts run atomically on the server.
+const (
+	// appendSeqScript gives ARGV[1] the next ID from counter KEYS[2] and adds
+	// it to KEYS[1] as "id:value", keeping at most ARGV[2] entries (0: all).
+	appendSeqScript = `local id = redis.call('INCR', KEYS[2])
+redis.call('ZADD', KEYS[1], id, id .. ':' .. ARGV[1])
+local max = tonumber(ARGV[2])
+if max > 0 then redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -max - 1) end
+return id`
+	acquireLeaseScript = `local holder = redis.call('GET', KEYS[1])
+if holder == false or holder == ARGV[1] then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+  return 1
+end
+return 0`
+	releaseLeaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then redis.call('DEL', KEYS[1]) end
+return 0`
+	// saveTokenScript stores token ARGV[3] under ID ARGV[1] unless the ID or
+	// digest ARGV[2] is taken.
+	saveTokenScript = `if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 or redis.call('HEXISTS', KEYS[2], ARGV[2]) == 1 then return 0 end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+redis.call('HSET', KEYS[2], ARGV[2], ARGV[1])
+return 1`
+	deleteTokenScript = `local t = redis.call('HGET', KEYS[1], ARGV[1])
+if not t then return 0 end
+redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], cjson.decode(t).digest)
+return 1`
+)
+
+// RedisStore persists state in Redis.
+type RedisStore struct {
+	client *redisClient
+	prefix string
+
+	savedMu      sync.Mutex
+	saved        map[string]string // statuses as of the last successful SaveState
+	savedTags    map[string]string // tags per item, as JSON, as of the same save
+	savedDue     map[string]string // deadlines in Unix microseconds, as of the same save
+	savedVersion int64             // the state version that save wrote
+}
+
+// OpenRedis connects to the Redis at rawURL, see parseRedisURL.
+func OpenRedis(rawURL string) (*RedisStore, error) {
+	client, prefix, err := parseRedisURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), redisDialTimeout)
+	defer cancel()
+	if _, err := client.do(ctx, "PING"); err != nil {
+		client.close()
+		return nil, err
+	}
+	return &RedisStore{client: client, prefix: prefix}, nil
+}
+
+func (s *RedisStore) key(name string) string {
+	return s.prefix + ":" + name
+}
+
+// Probe implements Prober.
+func (s *RedisStore) Probe(ctx context.Context) error {
+	_, err := s.client.do(ctx, "SET", s.key(settingProbe), time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// LoadState implements Store.
+func (s *RedisStore) LoadState(ctx context.Context) (State, error) {
+	st := State{Statuses: make(map[string]string)}
+	replies, err := s.client.pipeline(ctx, [][]any{
+		{"GET", s.key(settingMode)},
+		{"HGETALL", s.key("statuses")},
+		{"HGETALL", s.key("tags")},
+		{"HGETALL", s.key("deadlines")},
+	})
+	if err != nil {
+		return st, fmt.Errorf("unable to load state: %w", err)
+	}
+	st.Mode = redisString(replies[0])
+	for id, status := range redisHash(replies[1]) {
+		st.Statuses[id] = status
+	}
+	for id, raw := range redisHash(replies[2]) {
+		var tags []string
+		if err := json.Unmarshal([]byte(raw), &tags); err != nil {
+			return st, fmt.Errorf("corrupt tags for %s: %w", id, err)
+		}
+		if st.Tags == nil {
+			st.Tags = make(map[string][]string)
+		}
+		st.Tags[id] = tags
+	}
+	for id, raw := range redisHash(replies[3]) {
+		due, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return st, fmt.Errorf("corrupt deadline for %s: %w", id, err)
+		}
+		if st.Deadlines == nil {
+			st.Deadlines = make(map[string]time.Time)
+		}
+		st.Deadlines[id] = time.UnixMicro(due).UTC()
+	}
+	return st, nil
+}
+
+// redisHash converts an HGETALL reply.
+func redisHash(v any) map[string]string {
+	flat := redisStrings(v)
+	m := make(map[string]string, len(flat)/2)
+	for i := 0; i+1 < len(flat); i += 2 {
+		m[flat[i]] = flat[i+1]
+	}
+	return m
+}
+
+// SaveState implements Store. Every save bumps a version key. A save writes
+// only what changed since this instance's last save when the version is still
+// the one that save wrote, and replaces the stored state otherwise, so another
+// instance's saves in between are never left half overwritten. WATCH on the
+// version aborts the transaction if a save lands between the check and the
+// write, and the save is retried.
+func (s *RedisStore) SaveState(ctx context.Context, st State) error {
+	s.savedMu.Lock()
+	defer s.savedMu.Unlock()
+
+	tags := make(map[string]string, len(st.Tags))
+	for id, list := range st.Tags {
+		if len(list) == 0 {
+			continue
+		}
+		data, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		tags[id] = string(data)
+	}
+	due := make(map[string]string, len(st.Deadlines))
+	for id, at := range st.Deadlines {
+		due[id] = strconv.FormatInt(at.UnixMicro(), 10)
+	}
+	statuses := st.Statuses
+	if statuses == nil {
+		statuses = map[string]string{}
+	}
+
+	version := s.key("state:version")
+	var err error
+	for range redisSaveAttempts {
+		err = s.client.session(ctx, func(send func([][]any) ([]any, error)) error {
+			replies, err := send([][]any{{"WATCH", version}, {"GET", version}})
+			if err != nil {
+				return err
+			}
+			saved, savedTags, savedDue := s.saved, s.savedTags, s.savedDue
+			if redisInt(replies[1]) != s.savedVersion {
+				saved, savedTags, savedDue = nil, nil, nil
+			}
+			cmds := [][]any{{"MULTI"}, {"SET", s.key(settingMode), st.Mode}}
+			cmds = append(cmds, hashDiff(s.key("statuses"), saved, statuses)...)
+			cmds = append(cmds, hashDiff(s.key("tags"), savedTags, tags)...)
+			cmds = append(cmds, hashDiff(s.key("deadlines"), savedDue, due)...)
+			cmds = append(cmds, []any{"INCR", version}, []any{"EXEC"})
+			if replies, err = send(cmds); err != nil {
+				return err
+			}
+			exec := replies[len(replies)-1]
+			if exec == nil {
+				return errStateRaced
+			}
+			if err := execError(exec); err != nil {
+				return err
+			}
+			if results, _ := exec.([]any); len(results) > 0 {
+				s.savedVersion = redisInt(results[len(results)-1])
+			}
+			return nil
+		})
+		if !errors.Is(err, errStateRaced) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("unable to save state: %w", err)
+	}
+	s.saved, s.savedTags, s.savedDue = maps.Clone(statuses), tags, due
+	return nil
+}
+
+// hashDiff returns the commands that turn hash key from saved into current;
+// with nothing saved yet the hash is replaced.
+func hashDiff(key string, saved, current map[string]string) [][]any {
+	var cmds [][]any
+	if saved == nil {
+		cmds = append(cmds, []any{"DEL", key})
+	}
+	var removed, changed []string
+	for id := range saved {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	for id, v := range current {
+		if prev, ok := saved[id]; !ok || prev != v {
+			changed = append(changed, id)
+		}
+	}
+	for chunk := range slices.Chunk(removed, redisChunk) {
+		cmd := []any{"HDEL", key}
+		for _, id := range chunk {
+			cmd = append(cmd, id)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for chunk := range slices.Chunk(changed, redisChunk) {
+		cmd := []any{"HSET", key}
+		for _, id := range chunk {
+			cmd = append(cmd, id, current[id])
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
+
+// execError returns the first error among the results of an EXEC.
+func execError(v any) error {
+	if v == nil {
+		return fmt.Errorf("transaction aborted")
+	}
+	list, _ := v.([]any)
+	for _, r := range list {
+		if err, ok := r.(redisError); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTimed adds value to the time-scored set name, trimming the oldest.
+func (s *RedisStore) addTimed(ctx context.Context, name string, at time.Time, value []byte) error {
+	_, err := s.client.pipeline(ctx, [][]any{
+		{"ZADD", s.key(name), at.UnixMicro(), value},
+		{"ZREMRANGEBYRANK", s.key(name), 0, -redisHistoryLimit - 1},
+	})
+	return err
+}
+
+// rangeTimed calls fn with the members of the time-scored set name within q's
+// time bounds, newest first, until fn returns false.
+func (s *RedisStore) rangeTimed(ctx context.Context, name string, q Query, fn func([]byte) (bool, error)) error {
+	hi, lo := "+inf", "-inf"
+	if !q.Until.IsZero() {
+		hi = "(" + strconv.FormatInt(q.Until.UnixMicro(), 10)
+	}
+	if !q.Since.IsZero() {
+		lo = strconv.FormatInt(q.Since.UnixMicro(), 10)
+	}
+	for offset := 0; ; offset += redisPage {
+		reply, err := s.client.do(ctx, "ZREVRANGEBYSCORE", s.key(name), hi, lo, "LIMIT", offset, redisPage)
+		if err != nil {
+			return err
+		}
+		members := redisStrings(reply)
+		for _, m := range members {
+			more, err := fn([]byte(m))
+			if err != nil || !more {
+				return err
+			}
+		}
+		if len(members) < redisPage {
+			return nil
+		}
+	}
+}
+
+// appendSeq adds value to the ID-scored set name and returns its ID.
+func (s *RedisStore) appendSeq(ctx context.Context, name string, value []byte, max int) (int64, error) {
+	reply, err := s.client.do(ctx, "EVAL", appendSeqScript, 2, s.key(name), s.key(name+":seq"), value, max)
+	if err != nil {
+		return 0, err
+	}
+	return redisInt(reply), nil
+}
+
+// rangeSeq calls fn with the ID and value of each entry of the ID-scored set
+// name after afterID, oldest first, until fn returns false.
+func (s *RedisStore) rangeSeq(ctx context.Context, name string, afterID int64, fn func(id int64, value []byte) (bool, error)) error {
+	lo := "(" + strconv.FormatInt(afterID, 10)
+	for offset := 0; ; offset += redisPage {
+		reply, err := s.client.do(ctx, "ZRANGEBYSCORE", s.key(name), lo, "+inf", "LIMIT", offset, redisPage)
+		if err != nil {
+			return err
+		}
+		members := redisStrings(reply)
+		for _, m := range members {
+			rawID, value, _ := strings.Cut(m, ":")
+			id, err := strconv.ParseInt(rawID, 10, 64)
+			if err != nil {
+				return fmt.Errorf("corrupt %s entry %q", name, rawID)
+			}
+			more, err := fn(id, []byte(value))
+			if err != nil || !more {
+				return err
+			}
+		}
+		if len(members) < redisPage {
+			return nil
+		}
+	}
+}
+
+// pruneSeq removes the entries of the ID-scored set name older than before.
+// IDs are assigned in time order, so the old entries are a prefix of the set.
+func (s *RedisStore) pruneSeq(ctx context.Context, name string, before time.Time, timeOf func([]byte) (time.Time, error)) error {
+	var last int64
+	err := s.rangeSeq(ctx, name, 0, func(id int64, value []byte) (bool, error) {
+		at, err := timeOf(value)
+		if err != nil || !at.Before(before) {
+			return false, err
+		}
+		last = id
+		return true, nil
+	})
+	if err != nil || last == 0 {
+		return err
+	}
+	_, err = s.client.do(ctx, "ZREMRANGEBYSCORE", s.key(name), "-inf", last)
+	return err
+}
+
+// RecordDeletion implements Store.
+func (s *RedisStore) RecordDeletion(ctx context.Context, d Deletion) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if err := s.addTimed(ctx, "deletions", d.Time, data); err != nil {
+		return fmt.Errorf("unable to record deletion of %s: %w", d.ItemID, err)
+	}
+	return nil
+}
+
+// ListDeletions implements Store.
+func (s *RedisStore) ListDeletions(ctx context.Context, q Query) ([]Deletion, error) {
+	var out []Deletion
+	err := s.rangeTimed(ctx, "deletions", q, func(data []byte) (bool, error) {
+		var d Deletion
+		if err := json.Unmarshal(data, &d); err != nil {
+			return false, err
+		}
+		if q.matches(d.Time, d.ItemType) {
+			out = append(out, d)
+		}
+		return q.Limit <= 0 || len(out) < q.Limit, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list deletions: %w", err)
+	}
+	return out, nil
+}
+
+// AppendEvent implements Store. An event stored twice is one member of the
+// set, so copies skip the events a store already holds.
+func (s *RedisStore) AppendEvent(ctx context.Context, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("unable to encode event %s: %w", e.ID, err)
+	}
+	if err := s.addTimed(ctx, "events", e.Time, data); err != nil {
+		return fmt.Errorf("unable to append event %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// ListEvents implements Store.
+func (s *RedisStore) ListEvents(ctx context.Context, q Query) ([]events.Event, error) {
+	var out []events.Event
+	err := s.rangeTimed(ctx, "events", q, func(data []byte) (bool, error) {
+		var e events.Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return false, err
+		}
+		if q.matches(e.Time, e.Type) {
+			out = append(out, e)
+		}
+		return q.Limit <= 0 || len(out) < q.Limit, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list events: %w", err)
+	}
+	return out, nil
+}
+
+// AppendJournal implements Store.
+func (s *RedisStore) AppendJournal(ctx context.Context, e JournalEntry) (int64, error) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Seq = 0
+	data, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	seq, err := s.appendSeq(ctx, "journal", data, 0)
+	if err != nil {
+		return 0, fmt.Errorf("unable to append %s to journal: %w", e.Type, err)
+	}
+	return seq, nil
+}
+
+// ListJournal implements Store.
+func (s *RedisStore) ListJournal(ctx context.Context, q JournalQuery) ([]JournalEntry, error) {
+	filter := Query{Since: q.Since, Types: q.Types}
+	var out []JournalEntry
+	err := s.rangeSeq(ctx, "journal", q.AfterSeq, func(seq int64, data []byte) (bool, error) {
+		var e JournalEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return false, err
+		}
+		e.Seq = seq
+		if filter.matches(e.Time, e.Type) {
+			out = append(out, e)
+		}
+		return q.Limit <= 0 || len(out) < q.Limit, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read journal: %w", err)
+	}
+	return out, nil
+}
+
+// PruneJournal implements Store.
+func (s *RedisStore) PruneJournal(ctx context.Context, before time.Time) error {
+	err := s.pruneSeq(ctx, "journal", before, func(data []byte) (time.Time, error) {
+		var e JournalEntry
+		err := json.Unmarshal(data, &e)
+		return e.Time, err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to prune journal: %w", err)
+	}
+	return nil
+}
+
+// AddComment implements Store. Comments are kept in full.
+func (s *RedisStore) AddComment(ctx context.Context, c Comment) (Comment, error) {
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	c.ID = 0
+	data, err := json.Marshal(c)
+	if err != nil {
+		return c, err
+	}
+	if c.ID, err = s.appendSeq(ctx, "comments", data, 0); err != nil {
+		return c, fmt.Errorf("unable to add comment to %s: %w", c.ItemID, err)
+	}
+	return c, nil
+}
+
+// ListComments implements Store.
+func (s *RedisStore) ListComments(ctx context.Context, itemID string) ([]Comment, error) {
+	var out []Comment
+	err := s.rangeSeq(ctx, "comments", 0, func(id int64, data []byte) (bool, error) {
+		var c Comment
+		if err := json.Unmarshal(data, &c); err != nil {
+			return false, err
+		}
+		if itemID == "" || c.ItemID == itemID {
+			c.ID = id
+			out = append(out, c)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list comments: %w", err)
+	}
+	return out, nil
+}
+
+// RecordTransition implements Store.
+func (s *RedisStore) RecordTransition(ctx context.Context, t Transition) error {
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	t.ID = 0
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if _, err := s.appendSeq(ctx, "transitions", data, redisHistoryLimit); err != nil {
+		return fmt.Errorf("unable to record transition of %s: %w", t.ItemID, err)
+	}
+	return nil
+}
+
+// ListTransitions implements Store.
+func (s *RedisStore) ListTransitions(ctx context.Context, itemID string) ([]Transition, error) {
+	var out []Transition
+	err := s.rangeSeq(ctx, "transitions", 0, func(id int64, data []byte) (bool, error) {
+		var t Transition
+		if err := json.Unmarshal(data, &t); err != nil {
+			return false, err
+		}
+		if itemID == "" || t.ItemID == itemID {
+			t.ID = id
+			out = append(out, t)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list transitions: %w", err)
+	}
+	return out, nil
+}
+
+// SaveToken implements Store.
+func (s *RedisStore) SaveToken(ctx context.Context, t Token) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	reply, err := s.client.do(ctx, "EVAL", saveTokenScript, 2, s.key("tokens"), s.key("token_digests"), t.ID, t.Digest, data)
+	if err != nil {
+		return fmt.Errorf("unable to store token %s: %w", t.Name, err)
+	}
+	if redisInt(reply) == 0 {
+		return fmt.Errorf("unable to store token %s: duplicate", t.Name)
+	}
+	return nil
+}
+
+// ListTokens implements Store.
+func (s *RedisStore) ListTokens(ctx context.Context) ([]Token, error) {
+	reply, err := s.client.do(ctx, "HVALS", s.key("tokens"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tokens: %w", err)
+	}
+	var out []Token
+	for _, raw := range redisStrings(reply) {
+		var t Token
+		if err := json.Unmarshal([]byte(raw), &t); err != nil {
+			return nil, fmt.Errorf("unable to read tokens: %w", err)
+		}
+		out = append(out, t)
+	}
+	slices.SortFunc(out, func(a, b Token) int { return cmp.Or(a.Created.Compare(b.Created), strings.Compare(a.ID, b.ID)) })
+	return out, nil
+}
+
+// DeleteToken implements Store.
+func (s *RedisStore) DeleteToken(ctx context.Context, id string) (bool, error) {
+	reply, err := s.client.do(ctx, "EVAL", deleteTokenScript, 2, s.key("tokens"), s.key("token_digests"), id)
+	if err != nil {
+		return false, fmt.Errorf("unable to delete token %s: %w", id, err)
+	}
+	return redisInt(reply) == 1, nil
+}
+
+// Close implements Store.
+func (s *RedisStore) Close() error {
+	return s.client.close()
+}
+
+// AcquireLease implements Coordinator.
+func (s *RedisStore) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	reply, err := s.client.do(ctx, "EVAL", acquireLeaseScript, 1, s.key("lease:"+name), holder, ttl.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("unable to acquire lease %s: %w", name, err)
+	}
+	return redisInt(reply) == 1, nil
+}
+
+// ReleaseLease implements Coordinator.
+func (s *RedisStore) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := s.client.do(ctx, "EVAL", releaseLeaseScript, 1, s.key("lease:"+name), holder); err != nil {
+		return fmt.Errorf("unable to release lease %s: %w", name, err)
+	}
+	return nil
+}
+
+// AppendBroadcast implements Coordinator.
+func (s *RedisStore) AppendBroadcast(ctx context.Context, b Broadcast) error {
+	if b.Time.IsZero() {
+		b.Time = time.Now()
+	}
+	b.ID = 0
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	if _, err := s.appendSeq(ctx, "broadcasts", data, 0); err != nil {
+		return fmt.Errorf("unable to append %s broadcast: %w", b.Type, err)
+	}
+	return nil
+}
+
+// ListBroadcasts implements Coordinator.
+func (s *RedisStore) ListBroadcasts(ctx context.Context, afterID int64, limit int) ([]Broadcast, error) {
+	var out []Broadcast
+	err := s.rangeSeq(ctx, "broadcasts", afterID, func(id int64, data []byte) (bool, error) {
+		var b Broadcast
+		if err := json.Unmarshal(data, &b); err != nil {
+			return false, err
+		}
+		b.ID = id
+		out = append(out, b)
+		return limit <= 0 || len(out) < limit, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list broadcasts: %w", err)
+	}
+	return out, nil
+}
+
+// LatestBroadcastID implements Coordinator.
+func (s *RedisStore) LatestBroadcastID(ctx context.Context) (int64, error) {
+	reply, err := s.client.do(ctx, "GET", s.key("broadcasts:seq"))
+	if err != nil {
+		return 0, fmt.Errorf("unable to read broadcast position: %w", err)
+	}
+	return redisInt(reply), nil
+}
+
+// PruneBroadcasts implements Coordinator.
+func (s *RedisStore) PruneBroadcasts(ctx context.Context, before time.Time) error {
+	err := s.pruneSeq(ctx, "broadcasts", before, func(data []byte) (time.Time, error) {
+		var b Broadcast
+		err := json.Unmarshal(data, &b)
+		return b.Time, err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to prune broadcasts: %w", err)
+	}
+	return nil
+}
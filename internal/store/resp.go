@@ -0,0 +1,361 @@
+/*
+File: internal/store/resp.go
+Description: Minimal Redis client for the redis backend. Speaks RESP2 over TCP
+or TLS with a small pool of connections; commands are sent one at a time, as
+a pipeline, or as a session of pipelines on one connection for WATCH. Every
+round trip has a deadline, the context's or redisIOTimeout, and a context
+cancels a command by expiring its connection.
+*/
+package store
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	redisDefaultPort = "6379"
+	redisMaxIdle     = 4
+	redisDialTimeout = 5 * time.Second
+	// redisIOTimeout bounds a round trip whose context has no earlier
+	// deadline, so a stalled server cannot hang a caller forever.
+	redisIOTimeout = 10 * time.Second
+)
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisClient holds the connection settings and idle connections.
+type redisClient struct {
+	addr     string
+	tls      *tls.Config
+	user     string
+	password string
+	db       int
+	timeout  time.Duration // per round trip, see redisIOTimeout
+
+	mu   sync.Mutex
+	idle []*redisConn
+}
+
+type redisConn struct {
+	net.Conn
+	r       *bufio.Reader
+	w       *bufio.Writer
+	timeout time.Duration
+	// cancelled is set when a context cancellation fired during a round
+	// trip; its callback may still expire the deadline, so the connection
+	// is not reused.
+	cancelled bool
+}
+
+// parseRedisURL reads redis://[user:password@]host[:port][/db][?prefix=name];
+// rediss:// connects with TLS. It returns the client and the key prefix.
+func parseRedisURL(raw string) (*redisClient, string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid redis URL: %w", err)
+	}
+	c := &redisClient{timeout: redisIOTimeout}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		c.tls = &tls.Config{ServerName: u.Hostname()}
+	default:
+		return nil, "", fmt.Errorf("invalid redis URL: scheme %q (want redis or rediss)", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, "", errors.New("invalid redis URL: no host")
+	}
+	port := u.Port()
+	if port == "" {
+		port = redisDefaultPort
+	}
+	c.addr = net.JoinHostPort(u.Hostname(), port)
+	if u.User != nil {
+		c.password, _ = u.User.Password()
+		c.user = u.User.Username()
+		if _, ok := u.User.Password(); !ok {
+			// redis://secret@host is a password alone.
+			c.user, c.password = "", c.user
+		}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil || c.db < 0 {
+			return nil, "", fmt.Errorf("invalid redis URL: database %q", db)
+		}
+	}
+	prefix := u.Query().Get("prefix")
+	if prefix == "" {
+		prefix = "axis"
+	}
+	return c, prefix, nil
+}
+
+// do sends one command and returns its reply.
+func (c *redisClient) do(ctx context.Context, args ...any) (any, error) {
+	replies, err := c.pipeline(ctx, [][]any{args})
+	if err != nil {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline sends the commands together and returns their replies in order. An
+// error reply to any of them is returned as the error.
+func (c *redisClient) pipeline(ctx context.Context, cmds [][]any) ([]any, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := conn.roundTrip(ctx, cmds)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return replies, err
+}
+
+// session runs fn with a send function whose pipelines all go over one
+// connection, for a WATCH whose reply decides the transaction that follows.
+// The connection is dropped if fn fails, since a WATCH may still be active.
+func (c *redisClient) session(ctx context.Context, fn func(send func(cmds [][]any) ([]any, error)) error) error {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(func(cmds [][]any) ([]any, error) {
+		return conn.roundTrip(ctx, cmds)
+	})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	c.put(conn)
+	return nil
+}
+
+func (c *redisClient) get(ctx context.Context) (*redisConn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return conn, nil
+	}
+	c.mu.Unlock()
+
+	dialer := &net.Dialer{Timeout: redisDialTimeout}
+	var nc net.Conn
+	var err error
+	if c.tls != nil {
+		nc, err = (&tls.Dialer{NetDialer: dialer, Config: c.tls}).DialContext(ctx, "tcp", c.addr)
+	} else {
+		nc, err = dialer.DialContext(ctx, "tcp", c.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach redis %s: %w", c.addr, err)
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc), timeout: c.timeout}
+	var setup [][]any
+	switch {
+	case c.user != "":
+		setup = append(setup, []any{"AUTH", c.user, c.password})
+	case c.password != "":
+		setup = append(setup, []any{"AUTH", c.password})
+	}
+	if c.db != 0 {
+		setup = append(setup, []any{"SELECT", c.db})
+	}
+	if len(setup) > 0 {
+		if _, err := conn.roundTrip(ctx, setup); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("unable to open redis %s: %w", c.addr, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *redisClient) put(conn *redisConn) {
+	if conn.cancelled {
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.idle) >= redisMaxIdle {
+		conn.Close()
+		return
+	}
+	c.idle = append(c.idle, conn)
+}
+
+func (c *redisClient) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, conn := range c.idle {
+		conn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// roundTrip writes cmds and reads one reply per command. Every reply is read
+// even after an error reply, so the connection stays usable.
+func (conn *redisConn) roundTrip(ctx context.Context, cmds [][]any) ([]any, error) {
+	deadline := time.Now().Add(conn.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Unix(1, 0)) })
+	// stop does not wait for a callback already running.
+	defer func() { conn.cancelled = conn.cancelled || !stop() }()
+
+	for _, args := range cmds {
+		fmt.Fprintf(conn.w, "*%d\r\n", len(args))
+		for _, arg := range args {
+			var s string
+			switch v := arg.(type) {
+			case string:
+				s = v
+			case []byte:
+				s = string(v)
+			case int:
+				s = strconv.Itoa(v)
+			case int64:
+				s = strconv.FormatInt(v, 10)
+			default:
+				s = fmt.Sprint(v)
+			}
+			fmt.Fprintf(conn.w, "$%d\r\n%s\r\n", len(s), s)
+		}
+	}
+	if err := conn.w.Flush(); err != nil {
+		return nil, ctxErr(ctx, err)
+	}
+	replies := make([]any, len(cmds))
+	var first error
+	for i := range cmds {
+		v, err := conn.read()
+		if err != nil {
+			return nil, ctxErr(ctx, err)
+		}
+		if rerr, ok := v.(redisError); ok && first == nil {
+			first = rerr
+		}
+		replies[i] = v
+	}
+	return replies, first
+}
+
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// read decodes one reply: string for simple strings, []byte or nil for bulk
+// strings, int64, []any for arrays (nil when null) and redisError. An error
+// reply nested in an array, as EXEC returns them, is kept as an element.
+func (conn *redisConn) read() (any, error) {
+	line, err := conn.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return redisError(body), nil
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(conn.r, buf); err != nil {
+			return nil, err
+		}
+		if string(buf[n:]) != "\r\n" {
+			return nil, fmt.Errorf("redis: bulk string of %d bytes not terminated", n)
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", body)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		out := make([]any, n)
+		for i := range out {
+			if out[i], err = conn.read(); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// Reply conversions. A nil reply converts to the zero value.
+
+func redisString(v any) string {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return ""
+}
+
+func redisInt(v any) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case []byte:
+		n, _ := strconv.ParseInt(string(v), 10, 64)
+		return n
+	}
+	return 0
+}
+
+func redisStrings(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, len(list))
+	for i, e := range list {
+		out[i] = redisString(e)
+	}
+	return out
+}
@@ -0,0 +1,403 @@
+/*
+File: internal/store/resp_test.go
+Description: The Redis client against a fake server: reply parsing, pooled
+connections, the default round-trip timeout, and SaveState when several
+instances save the same state.
+*/
+package store
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves the handful of commands the store sends, with MULTI/EXEC
+// and WATCH, from memory.
+type fakeRedis struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	strs     map[string]string
+	hashes   map[string]map[string]string
+	changes  map[string]int // writes per key, for WATCH
+	accepted int
+	execs    int
+	stall    bool   // read commands but never answer
+	onExec   func() // runs before each EXEC is checked
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{ln: ln, strs: map[string]string{}, hashes: map[string]map[string]string{}, changes: map[string]int{}}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.accepted++
+			f.mu.Unlock()
+			go f.serve(c)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return f
+}
+
+func (f *fakeRedis) url() string { return "redis://" + f.ln.Addr().String() }
+
+func (f *fakeRedis) connections() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.accepted
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	r, w := bufio.NewReader(c), bufio.NewWriter(c)
+	watched := map[string]int{}
+	var queue [][]string
+	multi := false
+	for {
+		cmd, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		stall := f.stall
+		f.mu.Unlock()
+		if stall {
+			continue
+		}
+		name := strings.ToUpper(cmd[0])
+		var reply any
+		switch {
+		case name == "MULTI":
+			multi, queue, reply = true, nil, "OK"
+		case name == "EXEC":
+			f.mu.Lock()
+			f.execs++
+			hook := f.onExec
+			f.mu.Unlock()
+			if hook != nil {
+				hook()
+			}
+			f.mu.Lock()
+			aborted := false
+			for key, n := range watched {
+				aborted = aborted || f.changes[key] != n
+			}
+			if !aborted {
+				results := make([]any, len(queue))
+				for i, q := range queue {
+					results[i] = f.applyLocked(q)
+				}
+				reply = results
+			}
+			f.mu.Unlock()
+			multi, queue, watched = false, nil, map[string]int{}
+		case multi:
+			queue, reply = append(queue, cmd), "QUEUED"
+		case name == "WATCH":
+			f.mu.Lock()
+			for _, key := range cmd[1:] {
+				watched[key] = f.changes[key]
+			}
+			f.mu.Unlock()
+			reply = "OK"
+		default:
+			f.mu.Lock()
+			reply = f.applyLocked(cmd)
+			f.mu.Unlock()
+		}
+		writeReply(w, reply)
+		w.Flush()
+	}
+}
+
+// applyLocked runs one command outside a transaction's queue.
+func (f *fakeRedis) applyLocked(cmd []string) any {
+	name, args := strings.ToUpper(cmd[0]), cmd[1:]
+	switch name {
+	case "PING":
+		return "PONG"
+	case "AUTH", "SELECT", "UNWATCH":
+		return "OK"
+	case "GET":
+		if v, ok := f.strs[args[0]]; ok {
+			return []byte(v)
+		}
+		return nil
+	case "SET":
+		f.strs[args[0]] = args[1]
+		f.changes[args[0]]++
+		return "OK"
+	case "INCR":
+		n, _ := strconv.ParseInt(f.strs[args[0]], 10, 64)
+		n++
+		f.strs[args[0]] = strconv.FormatInt(n, 10)
+		f.changes[args[0]]++
+		return n
+	case "DEL":
+		delete(f.strs, args[0])
+		delete(f.hashes, args[0])
+		f.changes[args[0]]++
+		return int64(1)
+	case "HSET":
+		h := f.hashes[args[0]]
+		if h == nil {
+			h = map[string]string{}
+			f.hashes[args[0]] = h
+		}
+		for i := 1; i+1 < len(args); i += 2 {
+			h[args[i]] = args[i+1]
+		}
+		f.changes[args[0]]++
+		return int64(len(args) / 2)
+	case "HDEL":
+		for _, field := range args[1:] {
+			delete(f.hashes[args[0]], field)
+		}
+		f.changes[args[0]]++
+		return int64(len(args) - 1)
+	case "HGETALL":
+		var out []any
+		for k, v := range f.hashes[args[0]] {
+			out = append(out, []byte(k), []byte(v))
+		}
+		return out
+	}
+	return redisError("ERR unknown command '" + cmd[0] + "'")
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || line[0] != '*' {
+		return nil, fmt.Errorf("bad command %q", line)
+	}
+	cmd := make([]string, n)
+	for i := range cmd {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		cmd[i] = string(buf[:size])
+	}
+	return cmd, nil
+}
+
+func writeReply(w *bufio.Writer, v any) {
+	switch v := v.(type) {
+	case nil:
+		w.WriteString("*-1\r\n")
+	case string:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case redisError:
+		fmt.Fprintf(w, "-%s\r\n", string(v))
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case []byte:
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+	case []any:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, e := range v {
+			if e == nil {
+				w.WriteString("$-1\r\n")
+				continue
+			}
+			writeReply(w, e)
+		}
+	}
+}
+
+func TestRedisReplies(t *testing.T) {
+	for _, tc := range []struct {
+		wire    string
+		want    any
+		wantErr bool
+	}{
+		{"+OK\r\n", "OK", false},
+		{"-ERR wrong type\r\n", redisError("ERR wrong type"), false},
+		{":42\r\n", int64(42), false},
+		{"$5\r\nhello\r\n", []byte("hello"), false},
+		{"$0\r\n\r\n", []byte{}, false},
+		{"$-1\r\n", nil, false},
+		{"*-1\r\n", nil, false},
+		{"*3\r\n:1\r\n$-1\r\n-ERR inner\r\n", []any{int64(1), nil, redisError("ERR inner")}, false},
+		{"*1\r\n*1\r\n+nested\r\n", []any{[]any{"nested"}}, false},
+		{"+OK\n", nil, true},
+		{"$5\r\nhelloXX", nil, true},
+		{"$5\r\nhel", nil, true},
+		{"$x\r\n", nil, true},
+		{"*2\r\n:1\r\n", nil, true},
+		{"?what\r\n", nil, true},
+	} {
+		conn := &redisConn{r: bufio.NewReader(strings.NewReader(tc.wire))}
+		got, err := conn.read()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: error %v, want error %v", tc.wire, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %#v, want %#v", tc.wire, got, tc.want)
+		}
+	}
+}
+
+func TestRedisPoolReusesConnections(t *testing.T) {
+	f := newFakeRedis(t)
+	c, _, err := parseRedisURL(f.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if _, err := c.do(ctx, "SET", "k", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An error reply leaves the connection usable.
+	var rerr redisError
+	if _, err := c.do(ctx, "NOPE"); !errors.As(err, &rerr) {
+		t.Fatalf("error %v, want an error reply", err)
+	}
+	if v, err := c.do(ctx, "GET", "k"); err != nil || redisString(v) != "4" {
+		t.Fatalf("GET = %v, %v", v, err)
+	}
+	if n := f.connections(); n != 1 {
+		t.Errorf("%d connections, want 1", n)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.do(cancelled, "GET", "k"); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled GET error %v", err)
+	}
+	if _, err := c.do(ctx, "GET", "k"); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.connections(); n != 2 {
+		t.Errorf("%d connections after a cancelled command, want 2", n)
+	}
+}
+
+func TestRedisRoundTripTimesOut(t *testing.T) {
+	f := newFakeRedis(t)
+	c, _, err := parseRedisURL(f.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.close()
+	c.timeout = 100 * time.Millisecond
+	f.mu.Lock()
+	f.stall = true
+	f.mu.Unlock()
+
+	start := time.Now()
+	// No deadline on the context: the client's own bounds the wait.
+	_, err = c.do(context.Background(), "PING")
+	var ne net.Error
+	if !errors.As(err, &ne) || !ne.Timeout() {
+		t.Fatalf("error %v, want a timeout", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("timed out after %v", d)
+	}
+	if len(c.idle) != 0 {
+		t.Error("timed-out connection pooled")
+	}
+}
+
+func openFakeRedis(t *testing.T, f *fakeRedis) *RedisStore {
+	t.Helper()
+	s, err := OpenRedis(f.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestRedisSaveStateAcrossInstances(t *testing.T) {
+	f := newFakeRedis(t)
+	a, b := openFakeRedis(t, f), openFakeRedis(t, f)
+	ctx := context.Background()
+	save := func(s *RedisStore, statuses map[string]string) {
+		t.Helper()
+		if err := s.SaveState(ctx, State{Mode: "MANUAL", Statuses: statuses}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(a, map[string]string{"notes/x": "Keep"})
+	save(b, map[string]string{"notes/x": "Delete", "notes/z": "Keep"})
+	// a's last save no longer describes the store: a diff against it would
+	// leave notes/x and notes/z as b wrote them.
+	save(a, map[string]string{"notes/x": "Keep", "notes/y": "Keep"})
+	st, err := b.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"notes/x": "Keep", "notes/y": "Keep"}; !reflect.DeepEqual(st.Statuses, want) {
+		t.Errorf("statuses %v, want %v", st.Statuses, want)
+	}
+}
+
+func TestRedisSaveStateRetriesRacedSave(t *testing.T) {
+	f := newFakeRedis(t)
+	s := openFakeRedis(t, f)
+	ctx := context.Background()
+	if err := s.SaveState(ctx, State{Mode: "MANUAL", Statuses: map[string]string{"notes/x": "Keep"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Another instance saves between this one's version check and its EXEC.
+	f.mu.Lock()
+	f.execs = 0
+	f.onExec = func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.onExec = nil
+		f.applyLocked([]string{"HSET", s.key("statuses"), "notes/z", "Delete"})
+		f.applyLocked([]string{"INCR", s.key("state:version")})
+	}
+	f.mu.Unlock()
+	if err := s.SaveState(ctx, State{Mode: "MANUAL", Statuses: map[string]string{"notes/x": "Delete"}}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := s.LoadState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"notes/x": "Delete"}; !reflect.DeepEqual(st.Statuses, want) {
+		t.Errorf("statuses %v, want %v", st.Statuses, want)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.execs != 2 {
+		t.Errorf("%d transactions, want the raced one and its retry", f.execs)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+File: internal/store/snapshots.go
+Description: Registry snapshots. The SQL backends keep a full copy of the
+registry taken about once a day, so rules can be evaluated against the past;
+the JSON state file would grow too large and does not keep them.
+*/
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Snapshot is the registry as of Time, as a JSON array of registry items.
+type Snapshot struct {
+	Time  time.Time       `json:"time"`
+	Items json.RawMessage `json:"items"`
+}
+
+// Snapshotter is implemented by stores that keep registry snapshots.
+type Snapshotter interface {
+	SaveSnapshot(ctx context.Context, sn Snapshot) error
+	// ListSnapshots returns snapshots oldest first; Query.Types is ignored.
+	ListSnapshots(ctx context.Context, q Query) ([]Snapshot, error)
+	// LatestSnapshot returns the time of the newest snapshot, zero if none.
+	LatestSnapshot(ctx context.Context) (time.Time, error)
+	PruneSnapshots(ctx context.Context, before time.Time) error
+}
+
+// SaveSnapshot implements Snapshotter.
+func (s *SQLStore) SaveSnapshot(ctx context.Context, sn Snapshot) error {
+	if sn.Time.IsZero() {
+		sn.Time = time.Now()
+	}
+	if _, err := s.exec(ctx, `INSERT INTO registry_snapshots (time, items) VALUES (?, ?)`,
+		sn.Time.UnixMicro(), string(sn.Items)); err != nil {
+		return fmt.Errorf("unable to save registry snapshot: %w", err)
+	}
+	return nil
+}
+
+// ListSnapshots implements Snapshotter.
+func (s *SQLStore) ListSnapshots(ctx context.Context, q Query) ([]Snapshot, error) {
+	where, args := Query{Since: q.Since, Until: q.Until}.sqlFilter("time", "")
+	rows, err := s.query(ctx, `SELECT time, items FROM registry_snapshots`+where+` ORDER BY time`+q.sqlLimit(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read registry snapshots: %w", err)
+	}
+	defer rows.Close()
+	var out []Snapshot
+	for rows.Next() {
+		var at int64
+		var items string
+		if err := rows.Scan(&at, &items); err != nil {
+			return nil, err
+		}
+		out = append(out, Snapshot{Time: time.UnixMicro(at).UTC(), Items: json.RawMessage(items)})
+	}
+	return out, rows.Err()
+}
+
+// LatestSnapshot implements Snapshotter.
+func (s *SQLStore) LatestSnapshot(ctx context.Context) (time.Time, error) {
+	var at *int64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(time) FROM registry_snapshots`).Scan(&at); err != nil {
+		return time.Time{}, fmt.Errorf("unable to read registry snapshots: %w", err)
+	}
+	if at == nil {
+		return time.Time{}, nil
+	}
+	return time.UnixMicro(*at).UTC(), nil
+}
+
+// PruneSnapshots implements Snapshotter.
+func (s *SQLStore) PruneSnapshots(ctx context.Context, before time.Time) error {
+	if _, err := s.exec(ctx, `DELETE FROM registry_snapshots WHERE time < ?`, before.UnixMicro()); err != nil {
+		return fmt.Errorf("unable to prune registry snapshots: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,418 @@
+/*
+File: internal/store/sql.go
+Description: database/sql implementation of Store. Queries are written with "?"
+placeholders and rebound per dialect; the schema is managed by versioned
+migrations recorded in schema_migrations.
+*/
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+)
+
+const (
+	settingMode  = "mode"
+	settingProbe = "probe"
+)
+
+// dialect captures the SQL differences between supported databases.
+type dialect struct {
+	name       string
+	positional bool // $1, $2 placeholders instead of ?
+	migrations []migration
+}
+
+func (d dialect) rebind(q string) string {
+	if !d.positional {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SQLStore persists state in a relational database.
+type SQLStore struct {
+	db      *sql.DB
+	dialect dialect
+
+	saved     map[string]string // statuses as of the last successful SaveState
+	savedTags map[string]string // tags per item, joined, as of the same save
+	savedDue  map[string]int64  // deadlines in Unix microseconds, as of the same save
+	savedMu   sync.Mutex
+}
+
+func newSQLStore(db *sql.DB, d dialect) (*SQLStore, error) {
+	s := &SQLStore{db: db, dialect: d}
+	if err := s.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLStore) exec(ctx context.Context, q string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.dialect.rebind(q), args...)
+}
+
+func (s *SQLStore) query(ctx context.Context, q string, args ...any) (*sql.Rows, error) {
+	return s.db.QueryContext(ctx, s.dialect.rebind(q), args...)
+}
+
+// LoadState implements Store.
+func (s *SQLStore) LoadState(ctx context.Context) (State, error) {
+	st := State{Statuses: make(map[string]string)}
+	row := s.db.QueryRowContext(ctx, s.dialect.rebind(`SELECT value FROM settings WHERE key = ?`), settingMode)
+	if err := row.Scan(&st.Mode); err != nil && err != sql.ErrNoRows {
+		return st, fmt.Errorf("unable to load mode: %w", err)
+	}
+
+	rows, err := s.query(ctx, `SELECT item_id, status FROM statuses`)
+	if err != nil {
+		return st, fmt.Errorf("unable to load statuses: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return st, err
+		}
+		st.Statuses[id] = status
+	}
+	if err := rows.Err(); err != nil {
+		return st, err
+	}
+
+	tagRows, err := s.query(ctx, `SELECT item_id, tag FROM tags ORDER BY item_id, tag`)
+	if err != nil {
+		return st, fmt.Errorf("unable to load tags: %w", err)
+	}
+	defer tagRows.Close()
+	for tagRows.Next() {
+		var id, tag string
+		if err := tagRows.Scan(&id, &tag); err != nil {
+			return st, err
+		}
+		if st.Tags == nil {
+			st.Tags = make(map[string][]string)
+		}
+		st.Tags[id] = append(st.Tags[id], tag)
+	}
+	if err := tagRows.Err(); err != nil {
+		return st, err
+	}
+
+	dueRows, err := s.query(ctx, `SELECT item_id, due_at FROM deadlines`)
+	if err != nil {
+		return st, fmt.Errorf("unable to load deadlines: %w", err)
+	}
+	defer dueRows.Close()
+	for dueRows.Next() {
+		var id string
+		var due int64
+		if err := dueRows.Scan(&id, &due); err != nil {
+			return st, err
+		}
+		if st.Deadlines == nil {
+			st.Deadlines = make(map[string]time.Time)
+		}
+		st.Deadlines[id] = time.UnixMicro(due).UTC()
+	}
+	return st, dueRows.Err()
+}
+
+// SaveState implements Store. The first save replaces the status table; later
+// saves write only the statuses that changed since, which keeps flushes cheap
+// on large registries and avoids clobbering rows other instances wrote. Each
+// save runs in one transaction so readers never see a partial snapshot.
+func (s *SQLStore) SaveState(ctx context.Context, st State) error {
+	s.savedMu.Lock()
+	defer s.savedMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UnixMicro()
+	if _, err := tx.ExecContext(ctx, s.dialect.rebind(
+		`INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`),
+		settingMode, st.Mode); err != nil {
+		return fmt.Errorf("unable to save mode: %w", err)
+	}
+
+	if s.saved == nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM statuses`); err != nil {
+			return fmt.Errorf("unable to reset statuses: %w", err)
+		}
+	} else {
+		del, err := tx.PrepareContext(ctx, s.dialect.rebind(`DELETE FROM statuses WHERE item_id = ?`))
+		if err != nil {
+			return err
+		}
+		defer del.Close()
+		for id := range s.saved {
+			if _, ok := st.Statuses[id]; ok {
+				continue
+			}
+			if _, err := del.ExecContext(ctx, id); err != nil {
+				return fmt.Errorf("unable to remove status for %s: %w", id, err)
+			}
+		}
+	}
+	upsert, err := tx.PrepareContext(ctx, s.dialect.rebind(
+		`INSERT INTO statuses (item_id, status, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (item_id) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at`))
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+	for id, status := range st.Statuses {
+		if prev, ok := s.saved[id]; ok && prev == status {
+			continue
+		}
+		if _, err := upsert.ExecContext(ctx, id, status, now); err != nil {
+			return fmt.Errorf("unable to save status for %s: %w", id, err)
+		}
+	}
+	tags, err := s.saveTags(ctx, tx, st.Tags)
+	if err != nil {
+		return err
+	}
+	due, err := s.saveDeadlines(ctx, tx, st.Deadlines)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.saved = make(map[string]string, len(st.Statuses))
+	for id, status := range st.Statuses {
+		s.saved[id] = status
+	}
+	s.savedTags = tags
+	s.savedDue = due
+	return nil
+}
+
+// saveTags writes the tag rows of items whose tags changed since the last
+// save, or all of them on the first save, and returns the new baseline.
+func (s *SQLStore) saveTags(ctx context.Context, tx *sql.Tx, tags map[string][]string) (map[string]string, error) {
+	joined := make(map[string]string, len(tags))
+	for id, list := range tags {
+		if len(list) > 0 {
+			joined[id] = strings.Join(list, "\x00")
+		}
+	}
+	if s.savedTags == nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tags`); err != nil {
+			return nil, fmt.Errorf("unable to reset tags: %w", err)
+		}
+	}
+	del, err := tx.PrepareContext(ctx, s.dialect.rebind(`DELETE FROM tags WHERE item_id = ?`))
+	if err != nil {
+		return nil, err
+	}
+	defer del.Close()
+	ins, err := tx.PrepareContext(ctx, s.dialect.rebind(`INSERT INTO tags (item_id, tag) VALUES (?, ?)`))
+	if err != nil {
+		return nil, err
+	}
+	defer ins.Close()
+	for id := range s.savedTags {
+		if _, ok := joined[id]; ok {
+			continue
+		}
+		if _, err := del.ExecContext(ctx, id); err != nil {
+			return nil, fmt.Errorf("unable to remove tags for %s: %w", id, err)
+		}
+	}
+	for id, list := range joined {
+		prev, ok := s.savedTags[id]
+		if ok && prev == list {
+			continue
+		}
+		if ok {
+			if _, err := del.ExecContext(ctx, id); err != nil {
+				return nil, fmt.Errorf("unable to replace tags for %s: %w", id, err)
+			}
+		}
+		for _, tag := range tags[id] {
+			if _, err := ins.ExecContext(ctx, id, tag); err != nil {
+				return nil, fmt.Errorf("unable to save tags for %s: %w", id, err)
+			}
+		}
+	}
+	return joined, nil
+}
+
+// saveDeadlines writes the deadlines that changed since the last save, or all
+// of them on the first save, and returns the new baseline.
+func (s *SQLStore) saveDeadlines(ctx context.Context, tx *sql.Tx, deadlines map[string]time.Time) (map[string]int64, error) {
+	due := make(map[string]int64, len(deadlines))
+	for id, t := range deadlines {
+		due[id] = t.UnixMicro()
+	}
+	if s.savedDue == nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM deadlines`); err != nil {
+			return nil, fmt.Errorf("unable to reset deadlines: %w", err)
+		}
+	}
+	for id := range s.savedDue {
+		if _, ok := due[id]; ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.rebind(`DELETE FROM deadlines WHERE item_id = ?`), id); err != nil {
+			return nil, fmt.Errorf("unable to remove deadline for %s: %w", id, err)
+		}
+	}
+	for id, at := range due {
+		if prev, ok := s.savedDue[id]; ok && prev == at {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.rebind(
+			`INSERT INTO deadlines (item_id, due_at) VALUES (?, ?) ON CONFLICT (item_id) DO UPDATE SET due_at = excluded.due_at`),
+			id, at); err != nil {
+			return nil, fmt.Errorf("unable to save deadline for %s: %w", id, err)
+		}
+	}
+	return due, nil
+}
+
+// RecordDeletion implements Store.
+func (s *SQLStore) RecordDeletion(ctx context.Context, d Deletion) error {
+	_, err := s.exec(ctx,
+		`INSERT INTO deletions (time, item_id, item_type, title, actor, mode, outcome, detail, backup) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.Time.UnixMicro(), d.ItemID, d.ItemType, d.Title, d.Actor, d.Mode, d.Outcome, d.Detail, d.Backup)
+	if err != nil {
+		return fmt.Errorf("unable to record deletion of %s: %w", d.ItemID, err)
+	}
+	return nil
+}
+
+// ListDeletions implements Store.
+func (s *SQLStore) ListDeletions(ctx context.Context, q Query) ([]Deletion, error) {
+	where, args := q.sqlFilter("time", "item_type")
+	rows, err := s.query(ctx,
+		`SELECT time, item_id, item_type, title, actor, mode, outcome, detail, backup FROM deletions`+where+` ORDER BY time DESC`+q.sqlLimit(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list deletions: %w", err)
+	}
+	defer rows.Close()
+	var out []Deletion
+	for rows.Next() {
+		var d Deletion
+		var at int64
+		if err := rows.Scan(&at, &d.ItemID, &d.ItemType, &d.Title, &d.Actor, &d.Mode, &d.Outcome, &d.Detail, &d.Backup); err != nil {
+			return nil, err
+		}
+		d.Time = time.UnixMicro(at).UTC()
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// AppendEvent implements Store.
+func (s *SQLStore) AppendEvent(ctx context.Context, e events.Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Errorf("unable to encode event %s: %w", e.ID, err)
+	}
+	_, err = s.exec(ctx,
+		`INSERT INTO audit_events (id, type, time, actor, subject, data) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		e.ID, e.Type, e.Time.UnixMicro(), e.Actor, e.Subject, string(data))
+	if err != nil {
+		return fmt.Errorf("unable to append event %s: %w", e.ID, err)
+	}
+	return nil
+}
+
+// ListEvents implements Store.
+func (s *SQLStore) ListEvents(ctx context.Context, q Query) ([]events.Event, error) {
+	where, args := q.sqlFilter("time", "type")
+	rows, err := s.query(ctx,
+		`SELECT id, type, time, actor, subject, data FROM audit_events`+where+` ORDER BY time DESC`+q.sqlLimit(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list events: %w", err)
+	}
+	defer rows.Close()
+	var out []events.Event
+	for rows.Next() {
+		var e events.Event
+		var at int64
+		var data string
+		if err := rows.Scan(&e.ID, &e.Type, &at, &e.Actor, &e.Subject, &data); err != nil {
+			return nil, err
+		}
+		e.Time = time.UnixMicro(at).UTC()
+		e.Source = "axis"
+		if data != "" && data != "null" {
+			json.Unmarshal([]byte(data), &e.Data)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Probe implements Prober by writing the probe time to its own setting row.
+func (s *SQLStore) Probe(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.dialect.rebind(
+		`INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value`),
+		settingProbe, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// Close implements Store.
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+func (q Query) sqlFilter(timeCol, typeCol string) (string, []any) {
+	var conds []string
+	var args []any
+	if !q.Since.IsZero() {
+		conds = append(conds, timeCol+" >= ?")
+		args = append(args, q.Since.UnixMicro())
+	}
+	if !q.Until.IsZero() {
+		conds = append(conds, timeCol+" < ?")
+		args = append(args, q.Until.UnixMicro())
+	}
+	if len(q.Types) > 0 {
+		marks := strings.TrimSuffix(strings.Repeat("?, ", len(q.Types)), ", ")
+		conds = append(conds, typeCol+" IN ("+marks+")")
+		for _, t := range q.Types {
+			args = append(args, t)
+		}
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (q Query) sqlLimit() string {
+	if q.Limit <= 0 {
+		return ""
+	}
+	return " LIMIT " + strconv.Itoa(q.Limit)
+}
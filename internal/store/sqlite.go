@@ -0,0 +1,28 @@
+/*
+File: internal/store/sqlite.go
+Description: SQLite backend using the pure-Go modernc driver, so Axis stays a single
+static binary.
+*/
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+var sqliteDialect = dialect{name: "sqlite", migrations: sqliteMigrations}
+
+// OpenSQLite opens (creating if needed) the database file at path and applies
+// pending migrations.
+func OpenSQLite(path string) (*SQLStore, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sqlite %s: %w", path, err)
+	}
+	// SQLite permits one writer; a single connection avoids SQLITE_BUSY churn.
+	db.SetMaxOpenConns(1)
+	return newSQLStore(db, sqliteDialect)
+}
@@ -0,0 +1,179 @@
+/*
+File: internal/store/store.go
+Description: Persistent state storage for Axis. Defines the Store interface holding
+the operating mode, item statuses, comments, API tokens, deletion history, and audit events, with
+SQLite, Postgres and Redis backends and the legacy JSON state file as a fallback.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"axis/internal/events"
+)
+
+// Backend names accepted by Open.
+const (
+	BackendFile     = "file"
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+	BackendRedis    = "redis"
+)
+
+// State is the operational state restored at startup. Tags holds the tags Axis
+// keeps itself, by item ID (Keep notes have no labels in the API); Deadlines
+// holds when scheduled deletions fall due, by item ID.
+type State struct {
+	Mode      string               `json:"mode"`
+	Statuses  map[string]string    `json:"statuses"`
+	Tags      map[string][]string  `json:"tags,omitempty"`
+	Deadlines map[string]time.Time `json:"deadlines,omitempty"`
+}
+
+// Deletion outcomes.
+const (
+	OutcomeDeleted = "deleted"
+	OutcomeRefused = "refused"
+	OutcomeFailed  = "failed"
+	// OutcomeSimulated marks a delete computed in SIMULATE mode but not executed.
+	OutcomeSimulated = "simulated"
+	// OutcomeProposed marks a delete queued for a plan in AIRGAP mode.
+	OutcomeProposed = "proposed"
+)
+
+// Deletion records one destructive operation attempt.
+type Deletion struct {
+	Time     time.Time `json:"time"`
+	ItemID   string    `json:"item_id"`
+	ItemType string    `json:"item_type"`
+	Title    string    `json:"title"`
+	Actor    string    `json:"actor"`
+	Mode     string    `json:"mode"`
+	Outcome  string    `json:"outcome"`
+	Detail   string    `json:"detail,omitempty"`
+	// Backup is "path#revision" of the Doc or Sheet as it was before the
+	// delete (see internal/backup).
+	Backup string `json:"backup,omitempty"`
+}
+
+// Query filters history reads. Zero values mean unbounded; Types matches any listed value.
+type Query struct {
+	Since time.Time
+	Until time.Time
+	Types []string
+	Limit int
+}
+
+// Prober is implemented by stores that can verify they accept writes without
+// changing Axis state, for readiness checks.
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+// Recoverer is implemented by stores that fall back to a backup when their
+// state cannot be read. Recovered names the backup loaded, or is empty.
+// Quarantined names where unreadable state with no usable backup was moved
+// before starting empty, or is empty.
+type Recoverer interface {
+	Recovered() string
+	Quarantined() string
+}
+
+// Store persists Axis state. Implementations must be safe for concurrent use.
+type Store interface {
+	LoadState(ctx context.Context) (State, error)
+	SaveState(ctx context.Context, st State) error
+
+	RecordDeletion(ctx context.Context, d Deletion) error
+	// ListDeletions returns deletions newest first; Query.Types filters by item type.
+	ListDeletions(ctx context.Context, q Query) ([]Deletion, error)
+
+	AppendEvent(ctx context.Context, e events.Event) error
+	// ListEvents returns events newest first; Query.Types filters by event type.
+	ListEvents(ctx context.Context, q Query) ([]events.Event, error)
+
+	// AppendJournal records a stream event and returns its sequence number.
+	AppendJournal(ctx context.Context, e JournalEntry) (int64, error)
+	// ListJournal returns entries oldest first.
+	ListJournal(ctx context.Context, q JournalQuery) ([]JournalEntry, error)
+	PruneJournal(ctx context.Context, before time.Time) error
+
+	// AddComment stores a comment and returns it with its ID and time set.
+	AddComment(ctx context.Context, c Comment) (Comment, error)
+	// ListComments returns an item's comments oldest first, or every
+	// comment when itemID is empty.
+	ListComments(ctx context.Context, itemID string) ([]Comment, error)
+
+	// RecordTransition appends a status change to its item's history.
+	RecordTransition(ctx context.Context, t Transition) error
+	// ListTransitions returns an item's status changes oldest first, or every
+	// item's when itemID is empty.
+	ListTransitions(ctx context.Context, itemID string) ([]Transition, error)
+
+	SaveToken(ctx context.Context, t Token) error
+	// ListTokens returns every token, expired ones included, oldest first.
+	ListTokens(ctx context.Context) ([]Token, error)
+	// DeleteToken removes a token and reports whether it existed.
+	DeleteToken(ctx context.Context, id string) (bool, error)
+
+	Close() error
+}
+
+// Open returns the store for a backend name and its location.
+func Open(backend, location string) (Store, error) {
+	switch backend {
+	case "", BackendFile:
+		return NewFileStore(location), nil
+	case BackendSQLite:
+		st, err := OpenSQLite(location)
+		if err != nil {
+			return nil, err
+		}
+		return st, nil
+	case BackendPostgres:
+		st, err := OpenPostgres(location)
+		if err != nil {
+			return nil, err
+		}
+		return st, nil
+	case BackendRedis:
+		st, err := OpenRedis(location)
+		if err != nil {
+			return nil, err
+		}
+		return st, nil
+	default:
+		return nil, fmt.Errorf("unknown state backend %q (want file, sqlite, postgres or redis)", backend)
+	}
+}
+
+// EventRecorder adapts a Store into an events.Publisher so every emitted event
+// becomes part of the audit history.
+type EventRecorder struct {
+	Store Store
+}
+
+// Publish implements events.Publisher.
+func (r EventRecorder) Publish(ctx context.Context, e events.Event) error {
+	return r.Store.AppendEvent(ctx, e)
+}
+
+func (q Query) matches(t time.Time, typ string) bool {
+	if !q.Since.IsZero() && t.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !t.Before(q.Until) {
+		return false
+	}
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, want := range q.Types {
+		if want == typ {
+			return true
+		}
+	}
+	return false
+}
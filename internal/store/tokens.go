@@ -0,0 +1,69 @@
+/*
+File: internal/store/tokens.go
+Description: Scoped API tokens minted at runtime. Only the SHA-256 digest of a
+token's secret is stored, with its scopes, optional campaign restriction,
+creator and expiry.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Token is one minted API token. Digest is the hex SHA-256 of the secret.
+type Token struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Digest    string    `json:"digest"`
+	Scopes    []string  `json:"scopes"`
+	Campaign  string    `json:"campaign,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+}
+
+// SaveToken implements Store.
+func (s *SQLStore) SaveToken(ctx context.Context, t Token) error {
+	_, err := s.exec(ctx,
+		`INSERT INTO tokens (id, name, digest, scopes, campaign, created_by, created, expires) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Name, t.Digest, strings.Join(t.Scopes, " "), t.Campaign, t.CreatedBy, t.Created.UnixMicro(), t.Expires.UnixMicro())
+	if err != nil {
+		return fmt.Errorf("unable to store token %s: %w", t.Name, err)
+	}
+	return nil
+}
+
+// ListTokens implements Store.
+func (s *SQLStore) ListTokens(ctx context.Context) ([]Token, error) {
+	rows, err := s.query(ctx, `SELECT id, name, digest, scopes, campaign, created_by, created, expires FROM tokens ORDER BY created, id`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tokens: %w", err)
+	}
+	defer rows.Close()
+	var out []Token
+	for rows.Next() {
+		var t Token
+		var scopes string
+		var created, expires int64
+		if err := rows.Scan(&t.ID, &t.Name, &t.Digest, &scopes, &t.Campaign, &t.CreatedBy, &created, &expires); err != nil {
+			return nil, err
+		}
+		t.Scopes = strings.Fields(scopes)
+		t.Created, t.Expires = time.UnixMicro(created).UTC(), time.UnixMicro(expires).UTC()
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// DeleteToken implements Store.
+func (s *SQLStore) DeleteToken(ctx context.Context, id string) (bool, error) {
+	res, err := s.exec(ctx, `DELETE FROM tokens WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("unable to delete token %s: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,61 @@
+/*
+File: internal/store/transitions.go
+Description: Status transition history. Every status change made through the
+API, a bulk update or a playbook is kept per item with the status it replaced,
+who made it and when, so an item's path through the taxonomy can be traced.
+*/
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Transition is one status change. From is empty when the item had no status.
+type Transition struct {
+	ID     int64     `json:"id"`
+	ItemID string    `json:"item_id"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Actor  string    `json:"actor"`
+	Time   time.Time `json:"time"`
+}
+
+// RecordTransition implements Store.
+func (s *SQLStore) RecordTransition(ctx context.Context, t Transition) error {
+	if t.Time.IsZero() {
+		t.Time = time.Now()
+	}
+	_, err := s.exec(ctx,
+		`INSERT INTO transitions (item_id, from_status, to_status, actor, time) VALUES (?, ?, ?, ?, ?)`,
+		t.ItemID, t.From, t.To, t.Actor, t.Time.UnixMicro())
+	if err != nil {
+		return fmt.Errorf("unable to store transition of %s: %w", t.ItemID, err)
+	}
+	return nil
+}
+
+// ListTransitions implements Store.
+func (s *SQLStore) ListTransitions(ctx context.Context, itemID string) ([]Transition, error) {
+	where, args := "", []any(nil)
+	if itemID != "" {
+		where, args = " WHERE item_id = ?", []any{itemID}
+	}
+	rows, err := s.query(ctx, `SELECT id, item_id, from_status, to_status, actor, time FROM transitions`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read transitions: %w", err)
+	}
+	defer rows.Close()
+	var out []Transition
+	for rows.Next() {
+		var t Transition
+		var at int64
+		if err := rows.Scan(&t.ID, &t.ItemID, &t.From, &t.To, &t.Actor, &at); err != nil {
+			return nil, err
+		}
+		t.Time = time.UnixMicro(at).UTC()
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
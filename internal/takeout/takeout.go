@@ -0,0 +1,175 @@
+/*
+File: internal/takeout/takeout.go
+Description: Importer for Google Takeout Keep exports. Reads the per-note JSON files
+(and verifies referenced media) from a Takeout directory and recreates the notes
+through the workspace Keep helpers, preserving titles and checklist state.
+*/
+package takeout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"axis/internal/workspace"
+
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+// Note mirrors the fields of a Takeout Keep JSON file that Axis can recreate.
+type Note struct {
+	Title       string       `json:"title"`
+	TextContent string       `json:"textContent"`
+	ListContent []ListEntry  `json:"listContent"`
+	Attachments []Attachment `json:"attachments"`
+	IsTrashed   bool         `json:"isTrashed"`
+	IsArchived  bool         `json:"isArchived"`
+	IsPinned    bool         `json:"isPinned"`
+}
+
+// ListEntry is one checklist line. Takeout flattens nested items.
+type ListEntry struct {
+	Text      string `json:"text"`
+	IsChecked bool   `json:"isChecked"`
+}
+
+// Attachment references a media file stored next to the JSON.
+type Attachment struct {
+	FilePath string `json:"filePath"`
+	MimeType string `json:"mimetype"`
+}
+
+// Creator is the subset of workspace.Service used to recreate notes.
+type Creator interface {
+	CreateTextNote(ctx context.Context, title, content string) (*keepapi.Note, error)
+	CreateListNote(ctx context.Context, title string, items []workspace.ListItemInput) (*keepapi.Note, error)
+}
+
+// Options controls which notes are imported.
+type Options struct {
+	DryRun          bool
+	IncludeTrashed  bool
+	IncludeArchived bool
+}
+
+// Report summarizes an import run. Keep's API has no attachment upload, so media
+// is verified on disk and reported as skipped rather than attached.
+type Report struct {
+	Scanned            int      `json:"scanned"`
+	Created            int      `json:"created"`
+	Skipped            int      `json:"skipped"`
+	AttachmentsSkipped int      `json:"attachments_skipped"`
+	AttachmentsMissing []string `json:"attachments_missing,omitempty"`
+	Failed             []string `json:"failed,omitempty"`
+	CreatedNames       []string `json:"created_names,omitempty"`
+}
+
+// Entry is a parsed note and the file it came from.
+type Entry struct {
+	Path string
+	Note Note
+}
+
+// Read parses every Keep note JSON file beneath dir. Files that are not notes
+// (no title, text, or list) are ignored.
+func Read(dir string) ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", path, err)
+		}
+		var n Note
+		if err := json.Unmarshal(data, &n); err != nil {
+			return nil
+		}
+		if n.Title == "" && n.TextContent == "" && len(n.ListContent) == 0 && len(n.Attachments) == 0 {
+			return nil
+		}
+		entries = append(entries, Entry{Path: path, Note: n})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// includes reports whether opts imports n.
+func (opts Options) includes(n Note) bool {
+	return (!n.IsTrashed || opts.IncludeTrashed) && (!n.IsArchived || opts.IncludeArchived)
+}
+
+// Selected returns the entries an Import with opts would recreate.
+func Selected(entries []Entry, opts Options) []Entry {
+	var selected []Entry
+	for _, e := range entries {
+		if opts.includes(e.Note) {
+			selected = append(selected, e)
+		}
+	}
+	return selected
+}
+
+// Import recreates the notes found in dir.
+func Import(ctx context.Context, c Creator, dir string, opts Options) (Report, error) {
+	var rep Report
+	entries, err := Read(dir)
+	if err != nil {
+		return rep, err
+	}
+	for _, e := range entries {
+		rep.Scanned++
+		n := e.Note
+		if !opts.includes(n) {
+			rep.Skipped++
+			continue
+		}
+
+		for _, att := range n.Attachments {
+			rep.AttachmentsSkipped++
+			media := filepath.Join(filepath.Dir(e.Path), att.FilePath)
+			if _, err := os.Stat(media); err != nil {
+				rep.AttachmentsMissing = append(rep.AttachmentsMissing, media)
+			}
+		}
+
+		if opts.DryRun {
+			rep.Created++
+			continue
+		}
+		created, err := createNote(ctx, c, n)
+		if err != nil {
+			rep.Failed = append(rep.Failed, fmt.Sprintf("%s: %v", e.Path, err))
+			continue
+		}
+		rep.Created++
+		if created != nil {
+			rep.CreatedNames = append(rep.CreatedNames, created.Name)
+		}
+	}
+	return rep, nil
+}
+
+func createNote(ctx context.Context, c Creator, n Note) (*keepapi.Note, error) {
+	if len(n.ListContent) > 0 {
+		items := make([]workspace.ListItemInput, 0, len(n.ListContent))
+		for _, entry := range n.ListContent {
+			items = append(items, workspace.ListItemInput{Text: entry.Text, Checked: entry.IsChecked})
+		}
+		return c.CreateListNote(ctx, n.Title, items)
+	}
+	return c.CreateTextNote(ctx, n.Title, n.TextContent)
+}
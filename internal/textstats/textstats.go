@@ -0,0 +1,123 @@
+/*
+File: internal/textstats/textstats.go
+Description: Text statistics for notes and documents: word and character counts,
+last-edit recency buckets, and a lightweight stopword-based language guess.
+*/
+package textstats
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Recency buckets, from most to least recently edited.
+const (
+	RecencyWeek    = "week"
+	RecencyMonth   = "month"
+	RecencyQuarter = "quarter"
+	RecencyYear    = "year"
+	RecencyOlder   = "older"
+	RecencyUnknown = "unknown"
+)
+
+// LanguageUnknown is reported when the text is too short or unrecognized.
+const LanguageUnknown = "und"
+
+// minLanguageWords is the least amount of words worth guessing a language for.
+const minLanguageWords = 5
+
+// Stats describes one item's textual content.
+type Stats struct {
+	Words    int        `json:"words"`
+	Chars    int        `json:"chars"`
+	Language string     `json:"language"`
+	LastEdit *time.Time `json:"last_edit,omitempty"`
+	Recency  string     `json:"recency"`
+}
+
+// Compute derives stats for text last edited at lastEdit (zero if unknown).
+func Compute(text string, lastEdit, now time.Time) Stats {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\'' && r != '-'
+	})
+	st := Stats{
+		Words:    len(words),
+		Chars:    utf8.RuneCountInString(strings.TrimSpace(text)),
+		Language: DetectLanguage(words),
+		Recency:  Recency(lastEdit, now),
+	}
+	if !lastEdit.IsZero() {
+		t := lastEdit.UTC()
+		st.LastEdit = &t
+	}
+	return st
+}
+
+// Recency buckets the time since the last edit.
+func Recency(lastEdit, now time.Time) string {
+	if lastEdit.IsZero() {
+		return RecencyUnknown
+	}
+	age := now.Sub(lastEdit)
+	switch {
+	case age < 7*24*time.Hour:
+		return RecencyWeek
+	case age < 30*24*time.Hour:
+		return RecencyMonth
+	case age < 90*24*time.Hour:
+		return RecencyQuarter
+	case age < 365*24*time.Hour:
+		return RecencyYear
+	default:
+		return RecencyOlder
+	}
+}
+
+// stopwords holds the most frequent function words per ISO 639-1 language.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "it", "for", "with", "this", "on", "are", "be", "you", "was", "not"},
+	"es": {"el", "la", "de", "que", "y", "en", "los", "las", "por", "con", "para", "una", "es", "del", "se", "no", "lo"},
+	"fr": {"le", "la", "les", "de", "des", "et", "est", "que", "une", "un", "pour", "dans", "pas", "du", "au", "sur", "qui"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "zu", "den", "mit", "von", "auf", "sich", "für", "dem", "ich"},
+	"pt": {"o", "a", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "os", "as", "no", "na"},
+	"it": {"il", "di", "che", "e", "la", "per", "un", "una", "non", "sono", "con", "del", "della", "gli", "le", "lo", "è"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "op", "te", "niet", "met", "voor", "zijn", "er", "ook", "maar", "ik"},
+}
+
+var stopwordIndex = func() map[string][]string {
+	idx := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// DetectLanguage guesses the language of a word list by stopword frequency and
+// returns an ISO 639-1 code, or LanguageUnknown when there is no clear winner.
+func DetectLanguage(words []string) string {
+	if len(words) < minLanguageWords {
+		return LanguageUnknown
+	}
+	scores := make(map[string]int)
+	for _, w := range words {
+		for _, lang := range stopwordIndex[strings.ToLower(w)] {
+			scores[lang]++
+		}
+	}
+	best, bestScore, runnerUp := LanguageUnknown, 0, 0
+	for lang, score := range scores {
+		if score > bestScore {
+			best, bestScore, runnerUp = lang, score, bestScore
+		} else if score > runnerUp {
+			runnerUp = score
+		}
+	}
+	if bestScore < 2 || bestScore == runnerUp {
+		return LanguageUnknown
+	}
+	return best
+}
@@ -0,0 +1,179 @@
+/*
+File: internal/warehouse/sources.go
+Description: Built-in warehouse sources: registry snapshots with per-type/status
+counts (the analytics series), and an event buffer that doubles as an events
+publisher so every emitted event lands in the audit table.
+*/
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"axis/internal/events"
+	"axis/internal/workspace"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+const (
+	tableSnapshots = "registry_snapshots"
+	tableStats     = "registry_stats"
+	tableAudit     = "audit_events"
+
+	eventBufferLimit = 10000
+)
+
+func field(name, typ, mode string) *bigquery.TableFieldSchema {
+	return &bigquery.TableFieldSchema{Name: name, Type: typ, Mode: mode}
+}
+
+// RegistrySource snapshots the registry and derives item counts per type and status.
+type RegistrySource struct {
+	snapshot func(ctx context.Context) ([]workspace.RegistryItem, error)
+}
+
+// NewRegistrySource wraps a function returning the current enriched registry.
+func NewRegistrySource(snapshot func(ctx context.Context) ([]workspace.RegistryItem, error)) *RegistrySource {
+	return &RegistrySource{snapshot: snapshot}
+}
+
+// Tables implements Source.
+func (r *RegistrySource) Tables() []Table {
+	return []Table{
+		{
+			Name:           tableSnapshots,
+			Description:    "Point-in-time registry contents",
+			PartitionField: "snapshot_time",
+			Schema: []*bigquery.TableFieldSchema{
+				field("snapshot_time", "TIMESTAMP", "REQUIRED"),
+				field("id", "STRING", "REQUIRED"),
+				field("type", "STRING", "REQUIRED"),
+				field("title", "STRING", "NULLABLE"),
+				field("status", "STRING", "NULLABLE"),
+			},
+		},
+		{
+			Name:           tableStats,
+			Description:    "Registry item counts per type and status over time",
+			PartitionField: "snapshot_time",
+			Schema: []*bigquery.TableFieldSchema{
+				field("snapshot_time", "TIMESTAMP", "REQUIRED"),
+				field("type", "STRING", "REQUIRED"),
+				field("status", "STRING", "NULLABLE"),
+				field("count", "INTEGER", "REQUIRED"),
+			},
+		},
+	}
+}
+
+// Collect implements Source.
+func (r *RegistrySource) Collect(ctx context.Context, at time.Time) (map[string][]Row, error) {
+	items, err := r.snapshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("registry snapshot failed: %w", err)
+	}
+	ts := at.Format(time.RFC3339Nano)
+
+	type key struct{ typ, status string }
+	counts := make(map[key]int)
+	snapshots := make([]Row, 0, len(items))
+	for _, item := range items {
+		snapshots = append(snapshots, Row{
+			InsertID: ts + "/" + item.ID,
+			Values: map[string]bigquery.JsonValue{
+				"snapshot_time": ts,
+				"id":            item.ID,
+				"type":          item.Type,
+				"title":         item.Title,
+				"status":        item.Status,
+			},
+		})
+		counts[key{item.Type, item.Status}]++
+	}
+
+	stats := make([]Row, 0, len(counts))
+	for k, n := range counts {
+		stats = append(stats, Row{
+			InsertID: fmt.Sprintf("%s/%s/%s", ts, k.typ, k.status),
+			Values: map[string]bigquery.JsonValue{
+				"snapshot_time": ts,
+				"type":          k.typ,
+				"status":        k.status,
+				"count":         n,
+			},
+		})
+	}
+	return map[string][]Row{tableSnapshots: snapshots, tableStats: stats}, nil
+}
+
+// EventSource buffers published events until the next export cycle.
+type EventSource struct {
+	mu      sync.Mutex
+	pending []events.Event
+}
+
+// NewEventSource creates an empty event buffer.
+func NewEventSource() *EventSource {
+	return &EventSource{}
+}
+
+// Publish implements events.Publisher. The oldest events are discarded when the
+// buffer limit is reached between exports.
+func (s *EventSource) Publish(ctx context.Context, e events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= eventBufferLimit {
+		s.pending = s.pending[1:]
+	}
+	s.pending = append(s.pending, e)
+	return nil
+}
+
+// Tables implements Source.
+func (s *EventSource) Tables() []Table {
+	return []Table{{
+		Name:           tableAudit,
+		Description:    "Axis operational events",
+		PartitionField: "time",
+		Schema: []*bigquery.TableFieldSchema{
+			field("id", "STRING", "REQUIRED"),
+			field("type", "STRING", "REQUIRED"),
+			field("time", "TIMESTAMP", "REQUIRED"),
+			field("actor", "STRING", "NULLABLE"),
+			field("subject", "STRING", "NULLABLE"),
+			field("data", "STRING", "NULLABLE"),
+		},
+	}}
+}
+
+// Collect implements Source, draining the buffer.
+func (s *EventSource) Collect(ctx context.Context, at time.Time) (map[string][]Row, error) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	rows := make([]Row, 0, len(pending))
+	for _, e := range pending {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			data = []byte("{}")
+		}
+		rows = append(rows, Row{
+			InsertID: e.ID,
+			Values: map[string]bigquery.JsonValue{
+				"id":      e.ID,
+				"type":    e.Type,
+				"time":    e.Time.Format(time.RFC3339Nano),
+				"actor":   e.Actor,
+				"subject": e.Subject,
+				"data":    string(data),
+			},
+		})
+	}
+	return map[string][]Row{tableAudit: rows}, nil
+}
@@ -0,0 +1,212 @@
+/*
+File: internal/warehouse/warehouse.go
+Description: Scheduled BigQuery exporter. Sources contribute rows for their tables on
+each cycle; the exporter creates the dataset and tables on first run, adds columns
+when a source schema grows, and streams rows with insert IDs for de-duplication.
+*/
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+)
+
+const insertBatchSize = 500
+
+// Table describes a destination table and its schema.
+type Table struct {
+	Name        string
+	Description string
+	Schema      []*bigquery.TableFieldSchema
+	// PartitionField enables daily time partitioning on a TIMESTAMP column.
+	PartitionField string
+}
+
+// Row is a single table row with its de-duplication ID.
+type Row struct {
+	InsertID string
+	Values   map[string]bigquery.JsonValue
+}
+
+// Source produces rows for one or more tables at each export cycle.
+type Source interface {
+	Tables() []Table
+	Collect(ctx context.Context, at time.Time) (map[string][]Row, error)
+}
+
+// Exporter periodically writes source rows to a BigQuery dataset.
+type Exporter struct {
+	svc      *bigquery.Service
+	project  string
+	dataset  string
+	interval time.Duration
+	sources  []Source
+	logger   *slog.Logger
+}
+
+// NewExporter creates an exporter for project.dataset.
+func NewExporter(svc *bigquery.Service, project, dataset string, interval time.Duration, logger *slog.Logger, sources ...Source) *Exporter {
+	return &Exporter{
+		svc:      svc,
+		project:  project,
+		dataset:  dataset,
+		interval: interval,
+		sources:  sources,
+		logger:   logger,
+	}
+}
+
+// Run ensures the schema exists and exports on every interval until ctx ends.
+func (e *Exporter) Run(ctx context.Context) {
+	if err := e.EnsureSchema(ctx); err != nil {
+		e.logger.Error("bigquery schema setup failed", "error", err)
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.ExportOnce(ctx); err != nil {
+				e.logger.Error("bigquery export failed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// EnsureSchema creates the dataset and tables, and appends columns missing
+// from existing tables. Columns are never removed or retyped.
+func (e *Exporter) EnsureSchema(ctx context.Context) error {
+	_, err := e.svc.Datasets.Get(e.project, e.dataset).Context(ctx).Do()
+	if isNotFound(err) {
+		_, err = e.svc.Datasets.Insert(e.project, &bigquery.Dataset{
+			DatasetReference: &bigquery.DatasetReference{ProjectId: e.project, DatasetId: e.dataset},
+			Description:      "Axis registry and audit exports",
+		}).Context(ctx).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("unable to ensure dataset %s.%s: %w", e.project, e.dataset, err)
+	}
+
+	for _, src := range e.sources {
+		for _, t := range src.Tables() {
+			if err := e.ensureTable(ctx, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) ensureTable(ctx context.Context, t Table) error {
+	existing, err := e.svc.Tables.Get(e.project, e.dataset, t.Name).Context(ctx).Do()
+	if isNotFound(err) {
+		table := &bigquery.Table{
+			TableReference: &bigquery.TableReference{ProjectId: e.project, DatasetId: e.dataset, TableId: t.Name},
+			Description:    t.Description,
+			Schema:         &bigquery.TableSchema{Fields: t.Schema},
+		}
+		if t.PartitionField != "" {
+			table.TimePartitioning = &bigquery.TimePartitioning{Type: "DAY", Field: t.PartitionField}
+		}
+		if _, err := e.svc.Tables.Insert(e.project, e.dataset, table).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("unable to create table %s: %w", t.Name, err)
+		}
+		e.logger.Info("bigquery table created", "table", t.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get table %s: %w", t.Name, err)
+	}
+
+	have := make(map[string]bool)
+	var fields []*bigquery.TableFieldSchema
+	if existing.Schema != nil {
+		fields = existing.Schema.Fields
+		for _, f := range fields {
+			have[f.Name] = true
+		}
+	}
+	added := 0
+	for _, f := range t.Schema {
+		if !have[f.Name] {
+			// New columns must be nullable to be added to populated tables.
+			col := *f
+			col.Mode = "NULLABLE"
+			fields = append(fields, &col)
+			added++
+		}
+	}
+	if added == 0 {
+		return nil
+	}
+	_, err = e.svc.Tables.Patch(e.project, e.dataset, t.Name, &bigquery.Table{
+		Schema: &bigquery.TableSchema{Fields: fields},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to extend table %s: %w", t.Name, err)
+	}
+	e.logger.Info("bigquery table schema extended", "table", t.Name, "columns", added)
+	return nil
+}
+
+// ExportOnce collects rows from every source and streams them to BigQuery.
+func (e *Exporter) ExportOnce(ctx context.Context) error {
+	start := time.Now()
+	at := start.UTC()
+	var errs []error
+	total := 0
+	for _, src := range e.sources {
+		batches, err := src.Collect(ctx, at)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for table, rows := range batches {
+			if err := e.insert(ctx, table, rows); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			total += len(rows)
+		}
+	}
+	e.logger.Info("bigquery export complete", "rows", total, "duration", time.Since(start), "errors", len(errs))
+	return errors.Join(errs...)
+}
+
+func (e *Exporter) insert(ctx context.Context, table string, rows []Row) error {
+	for start := 0; start < len(rows); start += insertBatchSize {
+		end := min(start+insertBatchSize, len(rows))
+		req := &bigquery.TableDataInsertAllRequest{}
+		for _, r := range rows[start:end] {
+			req.Rows = append(req.Rows, &bigquery.TableDataInsertAllRequestRows{InsertId: r.InsertID, Json: r.Values})
+		}
+		resp, err := e.svc.Tabledata.InsertAll(e.project, e.dataset, table, req).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to insert into %s: %w", table, err)
+		}
+		if len(resp.InsertErrors) > 0 {
+			first := resp.InsertErrors[0]
+			reason := ""
+			if len(first.Errors) > 0 {
+				reason = first.Errors[0].Message
+			}
+			return fmt.Errorf("%d rows rejected by %s (row %d: %s)", len(resp.InsertErrors), table, first.Index, reason)
+		}
+	}
+	return nil
+}
+
+func isNotFound(err error) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == http.StatusNotFound
+}
@@ -0,0 +1,62 @@
+/*
+File: internal/workspace/archive.go
+Description: Archiving. Docs and Sheets are moved into an archive folder in
+Drive; Keep notes, which live outside Drive, are first copied into a new Doc
+there so their text survives the note's removal.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+// MoveToFolder moves a Drive file into folderID, out of every folder it was in.
+func (s *Service) MoveToFolder(ctx context.Context, fileID, folderID string) error {
+	ctx, span := startSpan(ctx, "MoveToFolder")
+	defer span.End()
+	file, err := s.driveService.Files.Get(fileID).Fields("parents").SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to read folders of %s: %w", fileID, err)
+	}
+	var remove []string
+	for _, p := range file.Parents {
+		if p != folderID {
+			remove = append(remove, p)
+		}
+	}
+	call := s.driveService.Files.Update(fileID, &drive.File{}).AddParents(folderID).SupportsAllDrives(true)
+	if len(remove) > 0 {
+		call = call.RemoveParents(strings.Join(remove, ","))
+	}
+	if _, err := call.Fields("id").Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to move %s to folder %s: %w", fileID, folderID, err)
+	}
+	return nil
+}
+
+// ExportNoteToDoc copies a Keep note's title and text into a new Doc in
+// folderID and returns the Doc's ID. The note itself is left alone.
+func (s *Service) ExportNoteToDoc(ctx context.Context, noteID, folderID string) (string, error) {
+	ctx, span := startSpan(ctx, "ExportNoteToDoc")
+	defer span.End()
+	note, err := s.GetNote(ctx, noteID)
+	if err != nil {
+		return "", err
+	}
+	title := note.Title
+	if title == "" {
+		title = "Untitled note"
+	}
+	doc, err := s.CreateDoc(ctx, title, NoteText(note.Body))
+	if err != nil {
+		return "", err
+	}
+	if err := s.MoveToFolder(ctx, doc.DocumentId, folderID); err != nil {
+		return doc.DocumentId, fmt.Errorf("exported note %s to doc %s: %w", noteID, doc.DocumentId, err)
+	}
+	return doc.DocumentId, nil
+}
@@ -0,0 +1,96 @@
+/*
+File: internal/workspace/attachsync.go
+Description: Note attachment sync. Keep media lives only as long as its note, so
+attachments are copied into a Drive folder under the names the download
+endpoint gives them, with their MIME types. Each upload records the attachment
+it came from, so syncing a note again skips what is already there.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+// attachmentProperty is the Drive app property naming the Keep attachment a
+// file was uploaded from.
+const attachmentProperty = "keepAttachment"
+
+// SyncedAttachment is one attachment copied to Drive.
+type SyncedAttachment struct {
+	Attachment string `json:"attachment"`
+	File       string `json:"file"`
+	Name       string `json:"name"`
+	MimeType   string `json:"mime_type"`
+	// Existing marks an attachment uploaded by an earlier sync.
+	Existing bool `json:"existing,omitempty"`
+}
+
+// SyncNoteAttachments uploads each attachment of a Keep note to folderID. It
+// stops at the first failure, returning what was synced before it.
+func (s *Service) SyncNoteAttachments(ctx context.Context, noteID, folderID string) ([]SyncedAttachment, error) {
+	ctx, span := startSpan(ctx, "SyncNoteAttachments")
+	defer span.End()
+	note, err := s.GetNote(ctx, noteID)
+	if err != nil {
+		return nil, err
+	}
+	var out []SyncedAttachment
+	for _, att := range note.Attachments {
+		if att == nil || att.Name == "" {
+			continue
+		}
+		if file, err := s.findAttachmentFile(ctx, folderID, att.Name); err != nil {
+			return out, err
+		} else if file != nil {
+			out = append(out, SyncedAttachment{Attachment: att.Name, File: file.Id, Name: file.Name, MimeType: file.MimeType, Existing: true})
+			continue
+		}
+		synced, err := s.syncAttachment(ctx, folderID, att.Name, att.MimeType)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, synced)
+	}
+	return out, nil
+}
+
+func (s *Service) syncAttachment(ctx context.Context, folderID, name string, mimeTypes []string) (SyncedAttachment, error) {
+	mimeType := ""
+	if len(mimeTypes) > 0 {
+		mimeType = mimeTypes[0]
+	}
+	media, err := s.OpenAttachmentMedia(ctx, name, mimeType)
+	if err != nil {
+		return SyncedAttachment{}, err
+	}
+	defer media.Body.Close()
+	contentType := media.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	file, err := s.UploadFile(ctx, folderID, AttachmentFilename(name, contentType), contentType, media.Body,
+		map[string]string{attachmentProperty: name})
+	if err != nil {
+		return SyncedAttachment{}, fmt.Errorf("attachment %s: %w", name, err)
+	}
+	return SyncedAttachment{Attachment: name, File: file.Id, Name: file.Name, MimeType: file.MimeType}, nil
+}
+
+// findAttachmentFile returns the file an earlier sync uploaded the
+// attachment to, or nil.
+func (s *Service) findAttachmentFile(ctx context.Context, folderID, attachment string) (*drive.File, error) {
+	q := fmt.Sprintf("'%s' in parents and appProperties has { key='%s' and value='%s' } and trashed = false",
+		driveQueryEscaper.Replace(folderID), attachmentProperty, driveQueryEscaper.Replace(attachment))
+	list, err := s.driveService.Files.List().Q(q).Fields("files(id, name, mimeType)").PageSize(1).
+		SupportsAllDrives(true).IncludeItemsFromAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up attachment %s in folder %s: %w", attachment, folderID, err)
+	}
+	if len(list.Files) == 0 {
+		return nil, nil
+	}
+	return list.Files[0], nil
+}
@@ -0,0 +1,72 @@
+/*
+File: internal/workspace/calendar.go
+Description: Google Calendar helpers. Creates reminder events for dates extracted
+from notes, using deterministic event IDs so repeated syncs never duplicate.
+*/
+package workspace
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	calendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+var errCalendarUnavailable = errors.New("google calendar service is not configured")
+
+// ReminderEvent describes a calendar reminder for a note.
+type ReminderEvent struct {
+	NoteID string
+	Title  string
+	Text   string
+	Due    time.Time
+	// AllDay creates a date-only event when no time of day was stated.
+	AllDay bool
+}
+
+// ReminderEventID derives the stable Calendar event ID for a reminder. Hex is a
+// subset of the base32hex alphabet Calendar requires.
+func ReminderEventID(noteID, text string, due time.Time) string {
+	sum := sha1.Sum([]byte(noteID + "\x00" + text + "\x00" + due.UTC().Format(time.RFC3339)))
+	return "axis" + hex.EncodeToString(sum[:])
+}
+
+// CreateReminderEvent inserts the reminder unless it already exists. It reports
+// whether a new event was created.
+func (s *Service) CreateReminderEvent(ctx context.Context, calendarID string, r ReminderEvent) (bool, error) {
+	ctx, span := startSpan(ctx, "CreateReminderEvent")
+	defer span.End()
+	if s.calendarService == nil {
+		return false, errCalendarUnavailable
+	}
+	ev := &calendar.Event{
+		Id:          ReminderEventID(r.NoteID, r.Text, r.Due),
+		Summary:     r.Title,
+		Description: fmt.Sprintf("%s\n\nExtracted by Axis from Keep note %s", r.Text, r.NoteID),
+		Reminders:   &calendar.EventReminders{UseDefault: true},
+	}
+	if r.AllDay {
+		day := r.Due.Format(time.DateOnly)
+		ev.Start = &calendar.EventDateTime{Date: day}
+		ev.End = &calendar.EventDateTime{Date: r.Due.AddDate(0, 0, 1).Format(time.DateOnly)}
+	} else {
+		ev.Start = &calendar.EventDateTime{DateTime: r.Due.Format(time.RFC3339)}
+		ev.End = &calendar.EventDateTime{DateTime: r.Due.Add(30 * time.Minute).Format(time.RFC3339)}
+	}
+
+	_, err := s.calendarService.Events.Insert(calendarID, ev).Context(ctx).Do()
+	if err != nil {
+		var gerr *googleapi.Error
+		if errors.As(err, &gerr) && gerr.Code == http.StatusConflict {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to create reminder event for %s: %w", r.NoteID, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,107 @@
+/*
+File: internal/workspace/changes.go
+Description: Change detection. Follows the Drive changes feed so registry
+refreshes only relist Docs or Sheets when something of that type changed, and
+lists only the Keep notes updated since the last refresh.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	keep "google.golang.org/api/keep/v1"
+)
+
+// driveMimeTypes maps Drive MIME types to registry item types.
+var driveMimeTypes = map[string]string{
+	"application/vnd.google-apps.document":    "doc",
+	"application/vnd.google-apps.spreadsheet": "sheet",
+}
+
+// DriveChanges reports which Drive-backed item types changed since a page token.
+type DriveChanges struct {
+	// Types holds the item types with at least one change.
+	Types map[string]bool
+	// Token is the page token to pass next time.
+	Token string
+}
+
+// Changed reports whether items of itemType need relisting.
+func (c DriveChanges) Changed(itemType string) bool { return c.Types[itemType] }
+
+// DriveStartToken returns the current Drive changes page token.
+func (s *Service) DriveStartToken(ctx context.Context) (string, error) {
+	ctx, span := startSpan(ctx, "DriveStartToken")
+	defer span.End()
+	tok, err := s.driveService.Changes.GetStartPageToken().Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get drive start page token: %w", err)
+	}
+	return tok.StartPageToken, nil
+}
+
+// ListDriveChanges reads the Drive changes feed from token. Removed files carry
+// no MIME type, so a removal marks every Drive-backed type as changed.
+func (s *Service) ListDriveChanges(ctx context.Context, token string) (DriveChanges, error) {
+	ctx, span := startSpan(ctx, "ListDriveChanges")
+	defer span.End()
+	res := DriveChanges{Types: make(map[string]bool)}
+	for token != "" {
+		page, err := s.driveService.Changes.List(token).
+			PageSize(1000).
+			Fields("nextPageToken,newStartPageToken,changes(removed,file(mimeType))").
+			Context(ctx).Do()
+		if err != nil {
+			return res, fmt.Errorf("failed to list drive changes: %w", err)
+		}
+		for _, ch := range page.Changes {
+			if ch.Removed || ch.File == nil {
+				for _, t := range driveMimeTypes {
+					res.Types[t] = true
+				}
+				continue
+			}
+			if t, ok := driveMimeTypes[ch.File.MimeType]; ok {
+				res.Types[t] = true
+			}
+		}
+		if page.NewStartPageToken != "" {
+			res.Token = page.NewStartPageToken
+		}
+		token = page.NextPageToken
+	}
+	return res, nil
+}
+
+// KeepChanges holds the Keep notes updated since a given time.
+type KeepChanges struct {
+	Updated []RegistryItem
+	Trashed []string
+}
+
+// ListKeepChanges lists notes whose update time is after since. Setting a
+// filter disables Keep's default trashed = false, so notes trashed since then
+// come back too and are reported in Trashed. Notes deleted outright are not
+// reported; callers relist periodically to catch them.
+func (s *Service) ListKeepChanges(ctx context.Context, since time.Time) (KeepChanges, error) {
+	ctx, span := startSpan(ctx, "ListKeepChanges")
+	defer span.End()
+	var res KeepChanges
+	filter := fmt.Sprintf("update_time > %q", since.UTC().Format(time.RFC3339Nano))
+	err := s.keepService.Notes.List().Filter(filter).PageSize(100).Pages(ctx, func(page *keep.ListNotesResponse) error {
+		for _, note := range page.Notes {
+			if note.Trashed {
+				res.Trashed = append(res.Trashed, note.Name)
+			} else {
+				res.Updated = append(res.Updated, noteItem(note))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to list changed keep notes: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,72 @@
+/*
+File: internal/workspace/content.go
+Description: Drive file exports and revisions. The search index reads Docs as
+text and Sheets as CSV (Drive exports a Sheet's first tab only), up to a byte
+limit so large files cost no more than the index will keep; backups export
+whole files and note the revision they were taken from.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+// ExportText returns at most limit bytes of fileID exported as mimeType,
+// cut at a character boundary.
+func (s *Service) ExportText(ctx context.Context, fileID, mimeType string, limit int) (string, error) {
+	ctx, span := startSpan(ctx, "ExportText")
+	defer span.End()
+	resp, err := s.driveService.Files.Export(fileID, mimeType).Context(ctx).Download()
+	if err != nil {
+		return "", fmt.Errorf("unable to export %s: %w", fileID, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+	if err != nil {
+		return "", fmt.Errorf("unable to read export of %s: %w", fileID, err)
+	}
+	// A character split by the limit is dropped with any other invalid bytes.
+	return strings.ToValidUTF8(string(data), ""), nil
+}
+
+// ExportFile writes fileID exported as mimeType to w and returns the bytes
+// written. Drive refuses exports larger than 10 MB.
+func (s *Service) ExportFile(ctx context.Context, fileID, mimeType string, w io.Writer) (int64, error) {
+	ctx, span := startSpan(ctx, "ExportFile")
+	defer span.End()
+	resp, err := s.driveService.Files.Export(fileID, mimeType).Context(ctx).Download()
+	if err != nil {
+		return 0, fmt.Errorf("unable to export %s: %w", fileID, err)
+	}
+	defer resp.Body.Close()
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("unable to read export of %s: %w", fileID, err)
+	}
+	return n, nil
+}
+
+// LatestRevision returns the ID of fileID's newest Drive revision.
+func (s *Service) LatestRevision(ctx context.Context, fileID string) (string, error) {
+	ctx, span := startSpan(ctx, "LatestRevision")
+	defer span.End()
+	var latest string
+	err := s.driveService.Revisions.List(fileID).Fields("nextPageToken", "revisions(id)").PageSize(1000).Pages(ctx, func(page *drive.RevisionList) error {
+		if n := len(page.Revisions); n > 0 {
+			latest = page.Revisions[n-1].Id
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to list revisions of %s: %w", fileID, err)
+	}
+	if latest == "" {
+		return "", fmt.Errorf("%s has no revisions", fileID)
+	}
+	return latest, nil
+}
@@ -0,0 +1,177 @@
+/*
+File: internal/workspace/convert.go
+Description: Keep-to-Doc conversion. A note becomes a Google Doc titled like the
+note, holding its text or checklist and its attachments. The Docs API only
+inlines images from a public URL, so each image is uploaded to Drive, shared
+with anyone holding the link for as long as the Doc takes to copy it, then
+removed; other attachments stay in Drive and are linked from the Doc.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	docs "google.golang.org/api/docs/v1"
+	drive "google.golang.org/api/drive/v3"
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+// ConvertNoteToDoc creates a Doc from a Keep note and returns its ID. The note
+// itself is left alone. Attachments that cannot be copied are skipped; the
+// returned error then lists them alongside the Doc's ID.
+func (s *Service) ConvertNoteToDoc(ctx context.Context, noteID string) (string, error) {
+	ctx, span := startSpan(ctx, "ConvertNoteToDoc")
+	defer span.End()
+	note, err := s.GetNote(ctx, noteID)
+	if err != nil {
+		return "", err
+	}
+	title := note.Title
+	if title == "" {
+		title = "Untitled note"
+	}
+	doc, err := s.docsService.Documents.Create(&docs.Document{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("unable to create doc %q: %w", title, err)
+	}
+
+	heading := title + "\n"
+	body := heading + noteDocText(note.Body)
+	_, err = s.docsService.Documents.BatchUpdate(doc.DocumentId, &docs.BatchUpdateDocumentRequest{
+		Requests: []*docs.Request{
+			{InsertText: &docs.InsertTextRequest{Location: &docs.Location{Index: 1}, Text: body}},
+			{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+				Range:          &docs.Range{StartIndex: 1, EndIndex: 1 + utf16Len(heading)},
+				ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: "TITLE"},
+				Fields:         "namedStyleType",
+			}},
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return doc.DocumentId, fmt.Errorf("created doc %s but unable to write note %s into it: %w", doc.DocumentId, noteID, err)
+	}
+
+	// Attachments follow the text, each in its own paragraph; index is where
+	// the next one goes.
+	index := 1 + utf16Len(body)
+	var errs []error
+	for _, att := range note.Attachments {
+		n, err := s.embedAttachment(ctx, doc.DocumentId, index, att)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		index += n
+	}
+	if err := errors.Join(errs...); err != nil {
+		return doc.DocumentId, fmt.Errorf("converted note %s to doc %s without some attachments: %w", noteID, doc.DocumentId, err)
+	}
+	return doc.DocumentId, nil
+}
+
+// noteDocText renders a note body for a Doc. Checklist items are marked ☐ or
+// ☑ and indented under their parent.
+func noteDocText(section *keepapi.Section) string {
+	if section == nil || section.List == nil {
+		text := NoteText(section)
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		return text
+	}
+	var b strings.Builder
+	var walk func(items []*keepapi.ListItem, depth int)
+	walk = func(items []*keepapi.ListItem, depth int) {
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			if item.Text != nil {
+				box := "☐"
+				if item.Checked {
+					box = "☑"
+				}
+				fmt.Fprintf(&b, "%s%s %s\n", strings.Repeat("    ", depth), box, item.Text.Text)
+			}
+			walk(item.ChildListItems, depth+1)
+		}
+	}
+	walk(section.List.ListItems, 0)
+	return b.String()
+}
+
+// embedAttachment copies one attachment into the Doc at index and returns
+// the length it added. Images are inlined; anything else, or an image the
+// Doc could not fetch, becomes a link to the uploaded file.
+func (s *Service) embedAttachment(ctx context.Context, docID string, index int64, att *keepapi.Attachment) (int64, error) {
+	if att == nil || att.Name == "" {
+		return 0, fmt.Errorf("attachment has no name")
+	}
+	mimeType := ""
+	if len(att.MimeType) > 0 {
+		mimeType = att.MimeType[0]
+	}
+	media, err := s.OpenAttachmentMedia(ctx, att.Name, mimeType)
+	if err != nil {
+		return 0, err
+	}
+	defer media.Body.Close()
+	name := AttachmentFilename(att.Name, media.ContentType)
+	file, err := s.UploadFile(ctx, "", name, media.ContentType, media.Body, nil)
+	if err != nil {
+		return 0, fmt.Errorf("attachment %s: %w", att.Name, err)
+	}
+
+	if strings.HasPrefix(media.ContentType, "image/") {
+		if err := s.inlineImage(ctx, docID, index, file.Id); err == nil {
+			// The Doc holds its own copy of the image now.
+			if err := s.driveService.Files.Delete(file.Id).Context(ctx).Do(); err != nil {
+				return 2, fmt.Errorf("embedded attachment %s but unable to remove its upload %s: %w", att.Name, file.Id, err)
+			}
+			return 2, nil
+		}
+	}
+
+	text := name + "\n"
+	_, err = s.docsService.Documents.BatchUpdate(docID, &docs.BatchUpdateDocumentRequest{
+		Requests: []*docs.Request{
+			{InsertText: &docs.InsertTextRequest{Location: &docs.Location{Index: index}, Text: text}},
+			{UpdateTextStyle: &docs.UpdateTextStyleRequest{
+				Range:     &docs.Range{StartIndex: index, EndIndex: index + utf16Len(name)},
+				TextStyle: &docs.TextStyle{Link: &docs.Link{Url: file.WebViewLink}},
+				Fields:    "link",
+			}},
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("unable to link attachment %s (uploaded as %s): %w", att.Name, file.Id, err)
+	}
+	return utf16Len(text), nil
+}
+
+// inlineImage inserts the Drive image fileID at index, followed by a
+// paragraph break. The file is readable by link only during the insert.
+func (s *Service) inlineImage(ctx context.Context, docID string, index int64, fileID string) error {
+	perm, err := s.driveService.Permissions.Create(fileID, &drive.Permission{Type: "anyone", Role: "reader"}).
+		Fields("id").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to share image %s for embedding: %w", fileID, err)
+	}
+	defer s.driveService.Permissions.Delete(fileID, perm.Id).Context(context.WithoutCancel(ctx)).Do()
+	_, err = s.docsService.Documents.BatchUpdate(docID, &docs.BatchUpdateDocumentRequest{
+		Requests: []*docs.Request{
+			{InsertInlineImage: &docs.InsertInlineImageRequest{
+				Location: &docs.Location{Index: index},
+				Uri:      "https://drive.google.com/uc?export=view&id=" + fileID,
+			}},
+			{InsertText: &docs.InsertTextRequest{Location: &docs.Location{Index: index + 1}, Text: "\n"}},
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to embed image %s: %w", fileID, err)
+	}
+	return nil
+}
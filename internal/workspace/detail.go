@@ -0,0 +1,168 @@
+/*
+File: internal/workspace/detail.go
+Description: Unified item details. Notes, Docs, Sheets and other Drive files are
+described by one ItemDetail, so clients show any item without knowing which API
+it came from.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+// previewRunes bounds ItemDetail.Preview.
+const previewRunes = 500
+
+// ErrItemType rejects a lookup whose type does not match the item.
+var ErrItemType = errors.New("wrong item type")
+
+// Sharing values of ItemDetail, from most to least restricted.
+const (
+	SharingPrivate = "private" // the owner only
+	SharingShared  = "shared"  // named users or groups
+	SharingDomain  = "domain"  // anyone in a domain
+	SharingPublic  = "public"  // anyone with the link
+)
+
+// ItemDetail describes one item. Type is keep, doc, sheet or, for any other
+// Drive file, file. Size is a note's text length or a file's Drive storage.
+type ItemDetail struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	Preview       string     `json:"preview,omitempty"`
+	Owner         string     `json:"owner,omitempty"`
+	Created       *time.Time `json:"created,omitempty"`
+	Updated       *time.Time `json:"updated,omitempty"`
+	Size          int64      `json:"size"`
+	MimeType      string     `json:"mime_type,omitempty"`
+	URL           string     `json:"url,omitempty"`
+	Sharing       string     `json:"sharing"`
+	Collaborators []string   `json:"collaborators,omitempty"`
+}
+
+// itemDetailFields limits the Drive lookup to what ItemDetail carries.
+const itemDetailFields = "id,name,mimeType,createdTime,modifiedTime,quotaBytesUsed,webViewLink,owners(emailAddress),permissions(type,role,emailAddress,domain)"
+
+// GetItemDetail describes the item id of itemType. An empty itemType means a
+// note for IDs starting notes/ and a Drive file otherwise, typed by its MIME
+// type. A preview that cannot be exported is omitted rather than failing
+// the lookup.
+func (s *Service) GetItemDetail(ctx context.Context, id, itemType string) (ItemDetail, error) {
+	ctx, span := startSpan(ctx, "GetItemDetail")
+	defer span.End()
+	if itemType == "keep" || itemType == "" && strings.HasPrefix(id, "notes/") {
+		return s.noteDetail(ctx, id)
+	}
+	file, err := s.driveService.Files.Get(id).Fields(itemDetailFields).SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return ItemDetail{}, fmt.Errorf("unable to get file %s: %w", id, err)
+	}
+	d := ItemDetail{
+		ID:       file.Id,
+		Type:     driveItemType(file.MimeType),
+		Title:    file.Name,
+		Owner:    fileOwner(file),
+		Created:  parseTime(file.CreatedTime),
+		Updated:  parseTime(file.ModifiedTime),
+		Size:     file.QuotaBytesUsed,
+		MimeType: file.MimeType,
+		URL:      file.WebViewLink,
+	}
+	if itemType != "" && itemType != d.Type {
+		return ItemDetail{}, fmt.Errorf("%w: %s is a %s, not a %s", ErrItemType, id, d.Type, itemType)
+	}
+	d.Sharing, d.Collaborators = fileSharing(file)
+	switch d.Type {
+	case "doc":
+		d.Preview = s.filePreview(ctx, id, "text/plain")
+	case "sheet":
+		d.Preview = s.filePreview(ctx, id, "text/csv")
+	}
+	return d, nil
+}
+
+func (s *Service) noteDetail(ctx context.Context, id string) (ItemDetail, error) {
+	note, err := s.GetNote(ctx, id)
+	if err != nil {
+		return ItemDetail{}, err
+	}
+	item := noteItem(note)
+	sharing := SharingPrivate
+	if len(item.Source.Shared) > 0 {
+		sharing = SharingShared
+	}
+	return ItemDetail{
+		ID:            note.Name,
+		Type:          "keep",
+		Title:         note.Title,
+		Preview:       preview(item.Source.Text),
+		Owner:         item.Source.Owner,
+		Created:       parseTime(note.CreateTime),
+		Updated:       item.Modified,
+		Size:          item.Source.Bytes,
+		URL:           ItemURL(item),
+		Sharing:       sharing,
+		Collaborators: item.Source.Shared,
+	}, nil
+}
+
+// driveItemType maps a Drive MIME type to an item type.
+func driveItemType(mimeType string) string {
+	if t, ok := driveMimeTypes[mimeType]; ok {
+		return t
+	}
+	return "file"
+}
+
+// fileSharing returns the widest audience of a file's permissions and the
+// users and groups it is shared with, owner excluded.
+func fileSharing(file *drive.File) (string, []string) {
+	order := []string{SharingPrivate, SharingShared, SharingDomain, SharingPublic}
+	sharing := SharingPrivate
+	var collaborators []string
+	widen := func(to string) {
+		if slices.Index(order, to) > slices.Index(order, sharing) {
+			sharing = to
+		}
+	}
+	for _, p := range file.Permissions {
+		switch {
+		case p.Role == "owner":
+		case p.Type == "anyone":
+			widen(SharingPublic)
+		case p.Type == "domain":
+			widen(SharingDomain)
+		case p.EmailAddress != "":
+			widen(SharingShared)
+			collaborators = append(collaborators, p.EmailAddress)
+		}
+	}
+	return sharing, collaborators
+}
+
+// filePreview exports the start of a file as mimeType, or "" on failure.
+func (s *Service) filePreview(ctx context.Context, id, mimeType string) string {
+	text, err := s.ExportText(ctx, id, mimeType, previewRunes*utf8.UTFMax)
+	if err != nil {
+		return ""
+	}
+	return preview(text)
+}
+
+// preview trims text to previewRunes, marking a cut with an ellipsis.
+func preview(text string) string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= previewRunes {
+		return text
+	}
+	return string([]rune(text)[:previewRunes]) + "…"
+}
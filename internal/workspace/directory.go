@@ -0,0 +1,189 @@
+/*
+File: internal/workspace/directory.go
+Description: Directory users with their custom schema fields (costCenter,
+offboardDate, ...). Fields are flattened to "Schema.field" keys so rules can
+test them, and can be written back one schema at a time.
+*/
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// DirectoryUser is a domain user and the custom schema fields Axis reads.
+type DirectoryUser struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	Suspended bool   `json:"suspended"`
+	// OrgUnit is the path of the user's organizational unit, e.g. "/Sales".
+	OrgUnit string `json:"org_unit,omitempty"`
+	// LastLogin is the Directory's last sign-in time; nil if never.
+	LastLogin *time.Time `json:"last_login,omitempty"`
+	// Fields maps "Schema.field" to the field's value: a string, number or
+	// bool, or a list of {"value", "type"} objects for multi-valued fields.
+	Fields map[string]any `json:"fields,omitempty"`
+	// Groups lists the emails of the user's groups when they were read.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// UserQuery selects one page of users for ListUsers.
+type UserQuery struct {
+	// Query uses the Directory search syntax, e.g. "email:ann*" or
+	// "orgUnitPath=/Sales".
+	Query     string
+	Schemas   []string
+	PageSize  int64 // 1-500, default 100
+	PageToken string
+}
+
+// usersCall lists the domain's users with the custom fields of schemas
+// (every schema when empty).
+func (s *Service) usersCall(schemas []string) *admin.UsersListCall {
+	call := s.adminService.Users.List().Customer("my_customer").OrderBy("email")
+	if len(schemas) > 0 {
+		return call.Projection("custom").CustomFieldMask(strings.Join(schemas, ","))
+	}
+	return call.Projection("full")
+}
+
+// ListUsers returns one page of users matching q and the next page's token,
+// or "" on the last page.
+func (s *Service) ListUsers(ctx context.Context, q UserQuery) ([]DirectoryUser, string, error) {
+	ctx, span := startSpan(ctx, "ListUsers")
+	defer span.End()
+	size := q.PageSize
+	if size <= 0 {
+		size = 100
+	}
+	call := s.usersCall(q.Schemas).MaxResults(min(size, 500)).Context(ctx)
+	if q.Query != "" {
+		call = call.Query(q.Query)
+	}
+	if q.PageToken != "" {
+		call = call.PageToken(q.PageToken)
+	}
+	page, err := call.Do()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list users: %w", err)
+	}
+	users := make([]DirectoryUser, 0, len(page.Users))
+	for _, u := range page.Users {
+		users = append(users, directoryUser(u))
+	}
+	return users, page.NextPageToken, nil
+}
+
+// LookupUsers resolves emails, primary or alias, to users. Emails without an
+// account are returned as missing; any other failure fails the lookup.
+func (s *Service) LookupUsers(ctx context.Context, emails []string) (map[string]DirectoryUser, []string, error) {
+	ctx, span := startSpan(ctx, "LookupUsers")
+	defer span.End()
+	found := make(map[string]DirectoryUser, len(emails))
+	var missing []string
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(lookupParallelism)
+	for _, email := range emails {
+		g.Go(func() error {
+			u, err := s.adminService.Users.Get(email).Fields("id", "primaryEmail", "name/fullName", "suspended", "orgUnitPath", "lastLoginTime").Context(ctx).Do()
+			mu.Lock()
+			defer mu.Unlock()
+			var gerr *googleapi.Error
+			if errors.As(err, &gerr) && gerr.Code == http.StatusNotFound {
+				missing = append(missing, email)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("unable to look up user %s: %w", email, err)
+			}
+			found[email] = directoryUser(u)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(missing)
+	return found, missing, nil
+}
+
+// lookupParallelism bounds concurrent Directory reads in LookupUsers.
+const lookupParallelism = 8
+
+// ListDirectoryUsers lists the domain's users with the custom fields of
+// schemas (every schema when empty).
+func (s *Service) ListDirectoryUsers(ctx context.Context, schemas []string) ([]DirectoryUser, error) {
+	ctx, span := startSpan(ctx, "ListDirectoryUsers")
+	defer span.End()
+	call := s.usersCall(schemas).MaxResults(500)
+	var users []DirectoryUser
+	err := call.Pages(ctx, func(page *admin.Users) error {
+		for _, u := range page.Users {
+			users = append(users, directoryUser(u))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list users: %w", err)
+	}
+	return users, nil
+}
+
+// SetUserFields writes fields of one custom schema on a user and returns the
+// user as stored. A nil value clears the field; fields not named are kept.
+func (s *Service) SetUserFields(ctx context.Context, email, schema string, fields map[string]any) (DirectoryUser, error) {
+	ctx, span := startSpan(ctx, "SetUserFields")
+	defer span.End()
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return DirectoryUser{}, fmt.Errorf("invalid fields for schema %s: %w", schema, err)
+	}
+	_, err = s.adminService.Users.Patch(email, &admin.User{
+		CustomSchemas: map[string]googleapi.RawMessage{schema: raw},
+	}).Context(ctx).Do()
+	if err != nil {
+		return DirectoryUser{}, fmt.Errorf("unable to update %s fields of %s: %w", schema, email, err)
+	}
+	u, err := s.adminService.Users.Get(email).Projection("custom").CustomFieldMask(schema).Context(ctx).Do()
+	if err != nil {
+		return DirectoryUser{}, fmt.Errorf("unable to retrieve user %s: %w", email, err)
+	}
+	return directoryUser(u), nil
+}
+
+func directoryUser(u *admin.User) DirectoryUser {
+	du := DirectoryUser{ID: u.Id, Email: u.PrimaryEmail, Suspended: u.Suspended, OrgUnit: u.OrgUnitPath}
+	// Accounts that never signed in report the Unix epoch.
+	if t := parseTime(u.LastLoginTime); t != nil && t.Year() > 1970 {
+		du.LastLogin = t
+	}
+	if u.Name != nil {
+		du.Name = u.Name.FullName
+	}
+	for schema, raw := range u.CustomSchemas {
+		var fields map[string]any
+		if json.Unmarshal(raw, &fields) != nil {
+			continue
+		}
+		if du.Fields == nil {
+			du.Fields = make(map[string]any)
+		}
+		for name, v := range fields {
+			du.Fields[schema+"."+name] = v
+		}
+	}
+	return du
+}
@@ -0,0 +1,282 @@
+/*
+File: internal/workspace/docedit.go
+Description: Structured Doc editing. Callers describe edits as insert, replace
+and append-heading operations; UpdateDoc works out the document indexes and
+sends them as Docs batch updates, so no caller has to do UTF-16 index math.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	docs "google.golang.org/api/docs/v1"
+)
+
+// Doc edit operations.
+const (
+	DocEditInsertText    = "insert_text"
+	DocEditReplaceText   = "replace_text"
+	DocEditAppendHeading = "append_heading"
+)
+
+// ErrDocTextNotFound reports an insert_text anchor missing from the document.
+var ErrDocTextNotFound = errors.New("text not found")
+
+// DocEdit is one edit of the document body.
+//
+//   - insert_text inserts Text at the end of the body, at its start when At is
+//     "start", or right after the first occurrence of After. A "\n" in Text
+//     starts a new paragraph.
+//   - replace_text replaces every occurrence of Find with Text, ignoring case
+//     unless MatchCase is set.
+//   - append_heading adds Text as a new heading paragraph of Level 1-6
+//     (default 1) at the end of the body.
+type DocEdit struct {
+	Op        string `json:"op"`
+	Text      string `json:"text"`
+	At        string `json:"at,omitempty"`
+	After     string `json:"after,omitempty"`
+	Find      string `json:"find,omitempty"`
+	MatchCase bool   `json:"match_case,omitempty"`
+	Level     int    `json:"level,omitempty"`
+}
+
+// DocEditResult reports an applied set of edits.
+type DocEditResult struct {
+	Revision string `json:"revision"`
+	Replaced int64  `json:"replaced"` // occurrences changed by replace_text
+	Batches  int    `json:"batches"`
+}
+
+// ValidateDocEdits checks edits before anything is sent.
+func ValidateDocEdits(edits []DocEdit) error {
+	if len(edits) == 0 {
+		return fmt.Errorf("no edits")
+	}
+	for i, e := range edits {
+		var err error
+		switch e.Op {
+		case DocEditInsertText:
+			switch {
+			case e.Text == "":
+				err = fmt.Errorf("insert_text requires text")
+			case e.At != "" && e.At != "start" && e.At != "end":
+				err = fmt.Errorf("at must be start or end")
+			case e.At != "" && e.After != "":
+				err = fmt.Errorf("use at or after, not both")
+			}
+		case DocEditReplaceText:
+			if e.Find == "" {
+				err = fmt.Errorf("replace_text requires find")
+			}
+		case DocEditAppendHeading:
+			switch {
+			case strings.TrimSpace(e.Text) == "" || strings.Contains(e.Text, "\n"):
+				err = fmt.Errorf("append_heading requires single-line text")
+			case e.Level < 0 || e.Level > 6:
+				err = fmt.Errorf("level must be 1-6")
+			}
+		default:
+			err = fmt.Errorf("unknown op %q", e.Op)
+		}
+		if err != nil {
+			return fmt.Errorf("edit %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// needsIndexes reports whether the edit is placed by document indexes, which
+// must be read from the document as it is when the edit runs.
+func (e DocEdit) needsIndexes() bool {
+	return e.Op == DocEditAppendHeading || (e.Op == DocEditInsertText && e.After != "")
+}
+
+// UpdateDoc applies edits in order. Edits go out in as few batch updates as
+// possible: a batch is only cut before an edit placed by index that follows
+// other edits, after which the document is read again. Each batch requires the
+// revision it was computed from, so a concurrent edit fails the update rather
+// than landing text in the wrong place; earlier batches stay applied.
+func (s *Service) UpdateDoc(ctx context.Context, documentID string, edits []DocEdit) (DocEditResult, error) {
+	ctx, span := startSpan(ctx, "UpdateDoc")
+	defer span.End()
+	var res DocEditResult
+	if err := ValidateDocEdits(edits); err != nil {
+		return res, err
+	}
+	var doc *docs.Document
+	var pending []*docs.Request
+	var replaces []int // positions in pending of ReplaceAllText requests
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		req := &docs.BatchUpdateDocumentRequest{Requests: pending}
+		if doc != nil {
+			req.WriteControl = &docs.WriteControl{RequiredRevisionId: doc.RevisionId}
+		}
+		resp, err := s.docsService.Documents.BatchUpdate(documentID, req).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to update doc %s: %w", documentID, err)
+		}
+		for _, i := range replaces {
+			if i < len(resp.Replies) && resp.Replies[i].ReplaceAllText != nil {
+				res.Replaced += resp.Replies[i].ReplaceAllText.OccurrencesChanged
+			}
+		}
+		if resp.WriteControl != nil {
+			res.Revision = resp.WriteControl.RequiredRevisionId
+		}
+		res.Batches++
+		pending, replaces, doc = nil, nil, nil
+		return nil
+	}
+
+	for i, e := range edits {
+		if e.needsIndexes() && (doc == nil || len(pending) > 0) {
+			if err := flush(); err != nil {
+				return res, err
+			}
+			var err error
+			if doc, err = s.GetDoc(ctx, documentID); err != nil {
+				return res, err
+			}
+		}
+		reqs, err := docEditRequests(doc, e)
+		if err != nil {
+			return res, fmt.Errorf("edit %d: %w", i+1, err)
+		}
+		if e.Op == DocEditReplaceText {
+			replaces = append(replaces, len(pending))
+		}
+		pending = append(pending, reqs...)
+	}
+	return res, flush()
+}
+
+// docEditRequests translates one edit. doc is only read by edits placed by
+// index, and is then as of the batch the requests join.
+func docEditRequests(doc *docs.Document, e DocEdit) ([]*docs.Request, error) {
+	switch e.Op {
+	case DocEditReplaceText:
+		return []*docs.Request{{ReplaceAllText: &docs.ReplaceAllTextRequest{
+			ContainsText: &docs.SubstringMatchCriteria{Text: e.Find, MatchCase: e.MatchCase},
+			ReplaceText:  e.Text,
+		}}}, nil
+	case DocEditInsertText:
+		insert := &docs.InsertTextRequest{Text: e.Text}
+		switch {
+		case e.After != "":
+			index, ok := indexAfter(doc, e.After)
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrDocTextNotFound, e.After)
+			}
+			insert.Location = &docs.Location{Index: index}
+		case e.At == "start":
+			insert.Location = &docs.Location{Index: 1}
+		default:
+			insert.EndOfSegmentLocation = &docs.EndOfSegmentLocation{}
+		}
+		return []*docs.Request{{InsertText: insert}}, nil
+	case DocEditAppendHeading:
+		level := e.Level
+		if level == 0 {
+			level = 1
+		}
+		// Text goes before the body's final newline. A newline ahead of it
+		// splits off a new paragraph unless the body is empty.
+		end := bodyEnd(doc)
+		text, start := "\n"+e.Text, end
+		if end <= 2 {
+			text, start = e.Text, 1
+		}
+		headingRange := &docs.Range{StartIndex: start, EndIndex: start + utf16Len(e.Text)}
+		return []*docs.Request{
+			{InsertText: &docs.InsertTextRequest{Location: &docs.Location{Index: end - 1}, Text: text}},
+			{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+				Range:          headingRange,
+				ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: fmt.Sprintf("HEADING_%d", level)},
+				Fields:         "namedStyleType",
+			}},
+			// The split paragraph inherits list membership; a heading should not.
+			{DeleteParagraphBullets: &docs.DeleteParagraphBulletsRequest{Range: headingRange}},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", e.Op)
+}
+
+// bodyEnd returns the end index of the body, one past its final newline.
+func bodyEnd(doc *docs.Document) int64 {
+	if doc == nil || doc.Body == nil || len(doc.Body.Content) == 0 {
+		return 2
+	}
+	return doc.Body.Content[len(doc.Body.Content)-1].EndIndex
+}
+
+// indexAfter finds the index just past the first occurrence of text within a
+// paragraph of the body, tables included.
+func indexAfter(doc *docs.Document, text string) (int64, bool) {
+	if doc == nil || doc.Body == nil {
+		return 0, false
+	}
+	return searchParagraphs(doc.Body.Content, text)
+}
+
+func searchParagraphs(content []*docs.StructuralElement, text string) (int64, bool) {
+	for _, el := range content {
+		if el == nil {
+			continue
+		}
+		switch {
+		case el.Paragraph != nil:
+			if index, ok := searchParagraph(el.Paragraph, text); ok {
+				return index, true
+			}
+		case el.Table != nil:
+			for _, row := range el.Table.TableRows {
+				for _, cell := range row.TableCells {
+					if index, ok := searchParagraphs(cell.Content, text); ok {
+						return index, true
+					}
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// searchParagraph matches text against a paragraph's runs. Elements other than
+// text runs (inline images, page breaks, ...) occupy their indexes as
+// placeholders, so a match never spans them and offsets stay exact.
+func searchParagraph(p *docs.Paragraph, text string) (int64, bool) {
+	var line strings.Builder
+	var start int64 = -1
+	for _, pe := range p.Elements {
+		if pe == nil {
+			continue
+		}
+		if start < 0 {
+			start = pe.StartIndex
+		}
+		if pe.TextRun != nil {
+			line.WriteString(pe.TextRun.Content)
+		} else {
+			line.WriteString(strings.Repeat("\uFFFC", int(pe.EndIndex-pe.StartIndex)))
+		}
+	}
+	pos := strings.Index(line.String(), text)
+	if pos < 0 || start < 0 {
+		return 0, false
+	}
+	return start + utf16Len(line.String()[:pos+len(text)]), true
+}
+
+// utf16Len counts the UTF-16 code units Docs indexes are measured in.
+func utf16Len(s string) int64 {
+	return int64(len(utf16.Encode([]rune(s))))
+}
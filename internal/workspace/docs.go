@@ -0,0 +1,273 @@
+/*
+File: internal/workspace/docs.go
+Description: Google Docs content helpers. Walks a document's structural elements
+(paragraphs, tables, table of contents) to collect text runs and hyperlinks, and
+renders a document as readable plain text or Markdown.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	docs "google.golang.org/api/docs/v1"
+)
+
+// DocFormat selects how GetDocText renders a document.
+type DocFormat string
+
+const (
+	DocFormatText     DocFormat = "text"
+	DocFormatMarkdown DocFormat = "markdown"
+)
+
+// ParseDocFormat accepts "text" (or "") and "markdown" (or "md").
+func ParseDocFormat(raw string) (DocFormat, error) {
+	switch strings.ToLower(raw) {
+	case "", "text", "txt":
+		return DocFormatText, nil
+	case "markdown", "md":
+		return DocFormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want text or markdown)", raw)
+}
+
+// GetDocText fetches a document and renders it in the given format.
+func (s *Service) GetDocText(ctx context.Context, documentID string, format DocFormat) (string, error) {
+	ctx, span := startSpan(ctx, "GetDocText")
+	defer span.End()
+	doc, err := s.GetDoc(ctx, documentID)
+	if err != nil {
+		return "", err
+	}
+	if format == DocFormatMarkdown {
+		return DocMarkdown(doc), nil
+	}
+	return DocText(doc), nil
+}
+
+// walkDocContent calls fn for every text run in document order, including
+// runs nested inside tables and tables of contents.
+func walkDocContent(content []*docs.StructuralElement, fn func(run *docs.TextRun)) {
+	for _, el := range content {
+		if el == nil {
+			continue
+		}
+		switch {
+		case el.Paragraph != nil:
+			for _, pe := range el.Paragraph.Elements {
+				if pe != nil && pe.TextRun != nil {
+					fn(pe.TextRun)
+				}
+			}
+		case el.Table != nil:
+			for _, row := range el.Table.TableRows {
+				for _, cell := range row.TableCells {
+					walkDocContent(cell.Content, fn)
+				}
+			}
+		case el.TableOfContents != nil:
+			walkDocContent(el.TableOfContents.Content, fn)
+		}
+	}
+}
+
+// DocLinks returns hyperlink targets attached to text in the document body.
+// URLs typed as plain text are not included; extract them from the text.
+func DocLinks(doc *docs.Document) []string {
+	if doc == nil || doc.Body == nil {
+		return nil
+	}
+	var links []string
+	walkDocContent(doc.Body.Content, func(run *docs.TextRun) {
+		if run.TextStyle != nil && run.TextStyle.Link != nil && run.TextStyle.Link.Url != "" {
+			links = append(links, run.TextStyle.Link.Url)
+		}
+	})
+	return links
+}
+
+// DocRawText concatenates every text run of the document body.
+func DocRawText(doc *docs.Document) string {
+	if doc == nil || doc.Body == nil {
+		return ""
+	}
+	var out []byte
+	walkDocContent(doc.Body.Content, func(run *docs.TextRun) {
+		out = append(out, run.Content...)
+	})
+	return string(out)
+}
+
+// DocText renders the document body as plain text: one line per paragraph,
+// line breaks kept, and table cells separated by tabs.
+func DocText(doc *docs.Document) string {
+	if doc == nil || doc.Body == nil {
+		return ""
+	}
+	var b strings.Builder
+	writeDocText(&b, doc.Body.Content)
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeDocText(b *strings.Builder, content []*docs.StructuralElement) {
+	for _, el := range content {
+		if el == nil {
+			continue
+		}
+		switch {
+		case el.Paragraph != nil:
+			var line strings.Builder
+			for _, pe := range el.Paragraph.Elements {
+				if pe != nil && pe.TextRun != nil {
+					line.WriteString(pe.TextRun.Content)
+				}
+			}
+			b.WriteString(strings.ReplaceAll(strings.TrimRight(line.String(), "\n"), "\v", "\n"))
+			b.WriteByte('\n')
+		case el.Table != nil:
+			for _, row := range el.Table.TableRows {
+				cells := make([]string, len(row.TableCells))
+				for i, cell := range row.TableCells {
+					var cb strings.Builder
+					writeDocText(&cb, cell.Content)
+					cells[i] = strings.Join(strings.Fields(cb.String()), " ")
+				}
+				b.WriteString(strings.Join(cells, "\t"))
+				b.WriteByte('\n')
+			}
+		case el.TableOfContents != nil:
+			writeDocText(b, el.TableOfContents.Content)
+		}
+	}
+}
+
+// DocMarkdown renders the document body as Markdown: headings, nested bulleted
+// and numbered lists, bold, italic, links and tables. Images are left out.
+func DocMarkdown(doc *docs.Document) string {
+	if doc == nil || doc.Body == nil {
+		return ""
+	}
+	var b strings.Builder
+	writeDocMarkdown(&b, doc, doc.Body.Content)
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeDocMarkdown(b *strings.Builder, doc *docs.Document, content []*docs.StructuralElement) {
+	inList := false
+	for _, el := range content {
+		if el == nil {
+			continue
+		}
+		isBullet := el.Paragraph != nil && el.Paragraph.Bullet != nil
+		if inList && !isBullet {
+			b.WriteByte('\n')
+			inList = false
+		}
+		switch {
+		case el.Paragraph != nil:
+			text := docParagraphMarkdown(el.Paragraph)
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			if isBullet {
+				level := int(el.Paragraph.Bullet.NestingLevel)
+				marker := "-"
+				if docListOrdered(doc, el.Paragraph.Bullet) {
+					marker = "1."
+				}
+				fmt.Fprintf(b, "%s%s %s\n", strings.Repeat("  ", level), marker, text)
+				inList = true
+				continue
+			}
+			if prefix := docHeadingPrefix(el.Paragraph); prefix != "" {
+				text = prefix + " " + text
+			}
+			b.WriteString(text)
+			b.WriteString("\n\n")
+		case el.Table != nil:
+			for i, row := range el.Table.TableRows {
+				cells := make([]string, len(row.TableCells))
+				for j, cell := range row.TableCells {
+					var cb strings.Builder
+					writeDocMarkdown(&cb, doc, cell.Content)
+					cells[j] = strings.ReplaceAll(strings.Join(strings.Fields(cb.String()), " "), "|", "\\|")
+				}
+				fmt.Fprintf(b, "| %s |\n", strings.Join(cells, " | "))
+				if i == 0 {
+					fmt.Fprintf(b, "|%s\n", strings.Repeat(" --- |", len(cells)))
+				}
+			}
+			b.WriteByte('\n')
+		case el.TableOfContents != nil:
+			writeDocMarkdown(b, doc, el.TableOfContents.Content)
+		}
+	}
+}
+
+// docHeadingPrefix maps a paragraph's named style to Markdown heading marks.
+func docHeadingPrefix(p *docs.Paragraph) string {
+	if p.ParagraphStyle == nil {
+		return ""
+	}
+	switch style := p.ParagraphStyle.NamedStyleType; style {
+	case "TITLE":
+		return "#"
+	case "SUBTITLE":
+		return "##"
+	case "HEADING_1", "HEADING_2", "HEADING_3", "HEADING_4", "HEADING_5", "HEADING_6":
+		return strings.Repeat("#", int(style[len(style)-1]-'0'))
+	}
+	return ""
+}
+
+// docListOrdered reports whether a bullet's list level uses a numbering glyph
+// rather than a symbol.
+func docListOrdered(doc *docs.Document, bullet *docs.Bullet) bool {
+	list, ok := doc.Lists[bullet.ListId]
+	if !ok || list.ListProperties == nil {
+		return false
+	}
+	levels := list.ListProperties.NestingLevels
+	level := int(bullet.NestingLevel)
+	if level >= len(levels) || levels[level] == nil {
+		return false
+	}
+	glyph := levels[level].GlyphType
+	return glyph != "" && glyph != "GLYPH_TYPE_UNSPECIFIED" && glyph != "NONE"
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+func docParagraphMarkdown(p *docs.Paragraph) string {
+	var b strings.Builder
+	for _, pe := range p.Elements {
+		if pe == nil || pe.TextRun == nil {
+			continue
+		}
+		raw := strings.TrimRight(pe.TextRun.Content, "\n")
+		// Emphasis marks must hug the text, so surrounding spaces stay outside.
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			b.WriteString(raw)
+			continue
+		}
+		lead := raw[:strings.Index(raw, trimmed)]
+		trail := raw[len(lead)+len(trimmed):]
+		text := strings.ReplaceAll(markdownEscaper.Replace(trimmed), "\v", "  \n")
+		if st := pe.TextRun.TextStyle; st != nil {
+			if st.Bold {
+				text = "**" + text + "**"
+			}
+			if st.Italic {
+				text = "_" + text + "_"
+			}
+			if st.Link != nil && st.Link.Url != "" {
+				text = fmt.Sprintf("[%s](%s)", text, st.Link.Url)
+			}
+		}
+		b.WriteString(lead + text + trail)
+	}
+	return b.String()
+}
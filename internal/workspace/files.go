@@ -0,0 +1,73 @@
+/*
+File: internal/workspace/files.go
+Description: Drive file transfer. Files are uploaded and downloaded as streams,
+so neither direction holds a whole file in memory. Google-native Docs, Sheets
+and Slides have no bytes to download and go through ExportFile instead.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	drive "google.golang.org/api/drive/v3"
+)
+
+// ErrNativeFile rejects downloading a Google-native file.
+var ErrNativeFile = errors.New("google-native files cannot be downloaded, only exported")
+
+// DriveMedia is an open Drive file download. Callers must close Body.
+type DriveMedia struct {
+	Body          io.ReadCloser
+	Name          string
+	ContentType   string
+	ContentLength int64
+}
+
+// UploadFile creates a Drive file from body in folderID, or in the root of
+// My Drive when folderID is empty. properties become the file's app
+// properties.
+func (s *Service) UploadFile(ctx context.Context, folderID, name, mimeType string, body io.Reader, properties map[string]string) (*drive.File, error) {
+	ctx, span := startSpan(ctx, "UploadFile")
+	defer span.End()
+	meta := &drive.File{Name: name, MimeType: mimeType, AppProperties: properties}
+	if folderID != "" {
+		meta.Parents = []string{folderID}
+	}
+	file, err := s.driveService.Files.Create(meta).Media(body).SupportsAllDrives(true).
+		Fields("id, name, mimeType, size, webViewLink").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to upload %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// DownloadFile starts downloading a Drive file's content.
+func (s *Service) DownloadFile(ctx context.Context, fileID string) (*DriveMedia, error) {
+	ctx, span := startSpan(ctx, "DownloadFile")
+	defer span.End()
+	file, err := s.driveService.Files.Get(fileID).Fields("name, mimeType, size").SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get file %s: %w", fileID, err)
+	}
+	if strings.HasPrefix(file.MimeType, "application/vnd.google-apps.") {
+		return nil, fmt.Errorf("%s is %s: %w", fileID, file.MimeType, ErrNativeFile)
+	}
+	resp, err := s.driveService.Files.Get(fileID).SupportsAllDrives(true).Context(ctx).Download()
+	if err != nil {
+		return nil, fmt.Errorf("unable to download file %s: %w", fileID, err)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = file.MimeType
+	}
+	return &DriveMedia{
+		Body:          resp.Body,
+		Name:          file.Name,
+		ContentType:   contentType,
+		ContentLength: resp.ContentLength,
+	}, nil
+}
@@ -0,0 +1,181 @@
+/*
+File: internal/workspace/groups.go
+Description: Directory groups, group members and organizational units. Lists
+are paged as the Directory pages them; GroupMemberships reads every group's
+members once and inverts them, so user rules can test a user's groups without
+a Directory call per user.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// Group is a Directory group.
+type Group struct {
+	ID          string `json:"id"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Members     int64  `json:"members"` // direct members
+}
+
+// GroupMember is one direct member of a group: a user, a nested group or the
+// whole customer.
+type GroupMember struct {
+	ID     string `json:"id"`
+	Email  string `json:"email,omitempty"`
+	Role   string `json:"role"`   // OWNER, MANAGER or MEMBER
+	Type   string `json:"type"`   // USER, GROUP or CUSTOMER
+	Status string `json:"status"` // ACTIVE, SUSPENDED, ...
+}
+
+// OrgUnit is a Directory organizational unit. Path is its full path, such as
+// "/Contractors/EU".
+type OrgUnit struct {
+	ID          string `json:"id"`
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parent      string `json:"parent,omitempty"` // parent's path
+}
+
+// GroupQuery selects one page of groups for ListGroups.
+type GroupQuery struct {
+	// Query uses the Directory group search syntax, e.g. "email:eng*".
+	Query string
+	// User lists only the groups this user or group belongs to directly.
+	User      string
+	PageSize  int64 // 1-200, default 100
+	PageToken string
+}
+
+// ListGroups returns one page of groups matching q and the next page's token,
+// or "" on the last page.
+func (s *Service) ListGroups(ctx context.Context, q GroupQuery) ([]Group, string, error) {
+	ctx, span := startSpan(ctx, "ListGroups")
+	defer span.End()
+	size := q.PageSize
+	if size <= 0 {
+		size = 100
+	}
+	call := s.adminService.Groups.List().MaxResults(min(size, 200)).Context(ctx)
+	if q.User != "" {
+		// userKey and customer exclude each other.
+		call = call.UserKey(q.User)
+	} else {
+		call = call.Customer("my_customer").OrderBy("email")
+	}
+	if q.Query != "" {
+		call = call.Query(q.Query)
+	}
+	if q.PageToken != "" {
+		call = call.PageToken(q.PageToken)
+	}
+	page, err := call.Do()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list groups: %w", err)
+	}
+	groups := make([]Group, 0, len(page.Groups))
+	for _, g := range page.Groups {
+		groups = append(groups, Group{ID: g.Id, Email: g.Email, Name: g.Name, Description: g.Description, Members: g.DirectMembersCount})
+	}
+	return groups, page.NextPageToken, nil
+}
+
+// ListGroupMembers returns one page of a group's direct members and the next
+// page's token, or "" on the last page. pageSize is 1-200 (default 200).
+func (s *Service) ListGroupMembers(ctx context.Context, groupKey string, pageSize int64, pageToken string) ([]GroupMember, string, error) {
+	ctx, span := startSpan(ctx, "ListGroupMembers")
+	defer span.End()
+	if pageSize <= 0 {
+		pageSize = 200
+	}
+	call := s.adminService.Members.List(groupKey).MaxResults(min(pageSize, 200)).Context(ctx)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	page, err := call.Do()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to list members of %s: %w", groupKey, err)
+	}
+	members := make([]GroupMember, 0, len(page.Members))
+	for _, m := range page.Members {
+		members = append(members, GroupMember{ID: m.Id, Email: m.Email, Role: m.Role, Type: m.Type, Status: m.Status})
+	}
+	return members, page.NextPageToken, nil
+}
+
+// ListOrgUnits returns every organizational unit, sorted by path.
+func (s *Service) ListOrgUnits(ctx context.Context) ([]OrgUnit, error) {
+	ctx, span := startSpan(ctx, "ListOrgUnits")
+	defer span.End()
+	resp, err := s.adminService.Orgunits.List("my_customer").Type("all").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list org units: %w", err)
+	}
+	units := make([]OrgUnit, 0, len(resp.OrganizationUnits))
+	for _, u := range resp.OrganizationUnits {
+		units = append(units, OrgUnit{ID: u.OrgUnitId, Path: u.OrgUnitPath, Name: u.Name, Description: u.Description, Parent: u.ParentOrgUnitPath})
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Path < units[j].Path })
+	return units, nil
+}
+
+// GroupMemberships maps each user, by lowercased email, to the emails of the
+// groups they belong to directly, sorted. Membership through a nested group
+// is not followed.
+func (s *Service) GroupMemberships(ctx context.Context) (map[string][]string, error) {
+	ctx, span := startSpan(ctx, "GroupMemberships")
+	defer span.End()
+	var groups []string
+	err := s.adminService.Groups.List().Customer("my_customer").MaxResults(200).Fields("nextPageToken", "groups(email)").
+		Pages(ctx, func(page *admin.Groups) error {
+			for _, g := range page.Groups {
+				groups = append(groups, strings.ToLower(g.Email))
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list groups: %w", err)
+	}
+
+	memberships := make(map[string][]string)
+	var mu sync.Mutex
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(lookupParallelism)
+	for _, group := range groups {
+		g.Go(func() error {
+			err := s.adminService.Members.List(group).MaxResults(200).Fields("nextPageToken", "members(email,type)").
+				Pages(ctx, func(page *admin.Members) error {
+					mu.Lock()
+					defer mu.Unlock()
+					for _, m := range page.Members {
+						if m.Type == "USER" && m.Email != "" {
+							email := strings.ToLower(m.Email)
+							memberships[email] = append(memberships[email], group)
+						}
+					}
+					return nil
+				})
+			if err != nil {
+				return fmt.Errorf("unable to list members of %s: %w", group, err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	for _, list := range memberships {
+		sort.Strings(list)
+	}
+	return memberships, nil
+}
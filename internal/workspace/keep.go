@@ -2,7 +2,9 @@
 File: internal/workspace/keep.go
 Description: Implementation of Google Keep service logic. Handles note lifecycle
 management including listing, creation, retrieval, and deletion of notes
-and list items.
+and list items. The Keep API reports a note's trash state and attachments but
+not its pinned, archived or color state or its Keep labels, so summaries carry
+only what the API exposes.
 */
 package workspace
 
@@ -11,7 +13,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"path"
 	"strings"
+	"time"
 
 	keepapi "google.golang.org/api/keep/v1"
 )
@@ -23,28 +28,53 @@ const (
 
 // Note represents a simplified Keep note
 type Note struct {
-	Title   string `json:"title"`
-	Snippet string `json:"snippet"`
-	ID      string `json:"id"`
+	Title       string     `json:"title"`
+	Snippet     string     `json:"snippet"`
+	ID          string     `json:"id"`
+	Trashed     bool       `json:"trashed,omitempty"`
+	TrashTime   *time.Time `json:"trash_time,omitempty"`
+	Attachments int        `json:"attachments,omitempty"`
 }
 
 var errKeepUnavailable = errors.New("google keep service is not configured")
 
 // ListNotesOptions allows callers to control pagination and filtering.
+// Trashed, when set, lists only trashed or only untrashed notes, combined with
+// Filter. Keep lists untrashed notes when no filter is given and every note
+// once one is.
 type ListNotesOptions struct {
 	Filter    string
+	Trashed   *bool
 	PageSize  int64
 	PageToken string
 }
 
-// ListNotes fetches the first 30 notes for the authenticated user and returns summaries.
-func (s *Service) ListNotes() ([]Note, error) {
-	summaries, _, err := s.ListNoteSummaries(context.Background(), ListNotesOptions{PageSize: defaultListPageSize})
+// filter returns the Keep list filter for the options.
+func (o ListNotesOptions) filter() string {
+	if o.Trashed == nil {
+		return o.Filter
+	}
+	trashed := fmt.Sprintf("trashed = %t", *o.Trashed)
+	if o.Filter == "" {
+		return trashed
+	}
+	return "(" + o.Filter + ") AND " + trashed
+}
+
+// ListNotes fetches the first page of notes matching opts for the
+// authenticated user, 30 unless opts sets a page size, and returns summaries.
+func (s *Service) ListNotes(ctx context.Context, opts ListNotesOptions) ([]Note, error) {
+	if opts.PageSize == 0 {
+		opts.PageSize = defaultListPageSize
+	}
+	summaries, _, err := s.ListNoteSummaries(ctx, opts)
 	return summaries, err
 }
 
 // ListNoteSummaries returns a page of note summaries and the next page token.
 func (s *Service) ListNoteSummaries(ctx context.Context, opts ListNotesOptions) ([]Note, string, error) {
+	ctx, span := startSpan(ctx, "ListNoteSummaries")
+	defer span.End()
 	resp, err := s.listNotes(ctx, opts)
 	if err != nil {
 		return nil, "", err
@@ -60,6 +90,8 @@ func (s *Service) ListNoteSummaries(ctx context.Context, opts ListNotesOptions)
 
 // ListAllNoteSummaries exhausts the iterator and returns all note summaries matching the options.
 func (s *Service) ListAllNoteSummaries(ctx context.Context, opts ListNotesOptions) ([]Note, error) {
+	ctx, span := startSpan(ctx, "ListAllNoteSummaries")
+	defer span.End()
 	var all []Note
 	pageToken := opts.PageToken
 	for {
@@ -79,6 +111,8 @@ func (s *Service) ListAllNoteSummaries(ctx context.Context, opts ListNotesOption
 
 // ListKeepNotes returns a page of raw keep notes and the next page token.
 func (s *Service) ListKeepNotes(ctx context.Context, opts ListNotesOptions) ([]*keepapi.Note, string, error) {
+	ctx, span := startSpan(ctx, "ListKeepNotes")
+	defer span.End()
 	resp, err := s.listNotes(ctx, opts)
 	if err != nil {
 		return nil, "", err
@@ -88,6 +122,8 @@ func (s *Service) ListKeepNotes(ctx context.Context, opts ListNotesOptions) ([]*
 
 // ListAllKeepNotes fetches every note matching the supplied options.
 func (s *Service) ListAllKeepNotes(ctx context.Context, opts ListNotesOptions) ([]*keepapi.Note, error) {
+	ctx, span := startSpan(ctx, "ListAllKeepNotes")
+	defer span.End()
 	var all []*keepapi.Note
 	pageToken := opts.PageToken
 	for {
@@ -107,6 +143,8 @@ func (s *Service) ListAllKeepNotes(ctx context.Context, opts ListNotesOptions) (
 
 // GetNote retrieves a single keep note.
 func (s *Service) GetNote(ctx context.Context, noteID string) (*keepapi.Note, error) {
+	ctx, span := startSpan(ctx, "GetNote")
+	defer span.End()
 	svc, err := s.ensureKeepService()
 	if err != nil {
 		return nil, err
@@ -121,6 +159,8 @@ func (s *Service) GetNote(ctx context.Context, noteID string) (*keepapi.Note, er
 
 // CreateNote submits a fully specified keep note to the API.
 func (s *Service) CreateNote(ctx context.Context, note *keepapi.Note) (*keepapi.Note, error) {
+	ctx, span := startSpan(ctx, "CreateNote")
+	defer span.End()
 	if note == nil {
 		return nil, errors.New("note must not be nil")
 	}
@@ -137,6 +177,8 @@ func (s *Service) CreateNote(ctx context.Context, note *keepapi.Note) (*keepapi.
 
 // CreateTextNote is a convenience for creating a note containing only text.
 func (s *Service) CreateTextNote(ctx context.Context, title, content string) (*keepapi.Note, error) {
+	ctx, span := startSpan(ctx, "CreateTextNote")
+	defer span.End()
 	return s.CreateNote(ctx, &keepapi.Note{
 		Title: title,
 		Body: &keepapi.Section{
@@ -154,6 +196,8 @@ type ListItemInput struct {
 
 // CreateListNote is a convenience for building list-based notes.
 func (s *Service) CreateListNote(ctx context.Context, title string, items []ListItemInput) (*keepapi.Note, error) {
+	ctx, span := startSpan(ctx, "CreateListNote")
+	defer span.End()
 	listItems := buildListItems(items)
 	return s.CreateNote(ctx, &keepapi.Note{
 		Title: title,
@@ -165,6 +209,8 @@ func (s *Service) CreateListNote(ctx context.Context, title string, items []List
 
 // DeleteNote removes a keep note permanently.
 func (s *Service) DeleteNote(ctx context.Context, noteID string) error {
+	ctx, span := startSpan(ctx, "DeleteNote")
+	defer span.End()
 	svc, err := s.ensureKeepService()
 	if err != nil {
 		return err
@@ -179,6 +225,8 @@ func (s *Service) DeleteNote(ctx context.Context, noteID string) error {
 
 // AddNoteWriters grants writer access to the specified note for the provided emails.
 func (s *Service) AddNoteWriters(ctx context.Context, noteID string, writerEmails []string) ([]*keepapi.Permission, error) {
+	ctx, span := startSpan(ctx, "AddNoteWriters")
+	defer span.End()
 	if len(writerEmails) == 0 {
 		return nil, nil
 	}
@@ -215,6 +263,8 @@ func (s *Service) AddNoteWriters(ctx context.Context, noteID string, writerEmail
 
 // RemoveNotePermissions revokes the supplied permission resource names from the note.
 func (s *Service) RemoveNotePermissions(ctx context.Context, noteID string, permissionNames []string) error {
+	ctx, span := startSpan(ctx, "RemoveNotePermissions")
+	defer span.End()
 	if len(permissionNames) == 0 {
 		return nil
 	}
@@ -242,8 +292,50 @@ func (s *Service) RemoveNotePermissions(ctx context.Context, noteID string, perm
 	return nil
 }
 
+// NotePermission is one collaborator of a note. Name is the permission's
+// resource name, as RemoveNotePermissions takes it.
+type NotePermission struct {
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Email string `json:"email"`
+	Kind  string `json:"kind"` // user, group or family
+}
+
+// ListNotePermissions returns the note's current permissions, owner included.
+// Keep has no listing call for permissions; they are read from the note.
+func (s *Service) ListNotePermissions(ctx context.Context, noteID string) ([]NotePermission, error) {
+	ctx, span := startSpan(ctx, "ListNotePermissions")
+	defer span.End()
+	svc, err := s.ensureKeepService()
+	if err != nil {
+		return nil, err
+	}
+	name := ensureNoteName(noteID)
+	note, err := svc.Notes.Get(name).Fields("permissions").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get permissions of note %s: %w", name, err)
+	}
+	perms := make([]NotePermission, 0, len(note.Permissions))
+	for _, p := range note.Permissions {
+		if p.Deleted {
+			continue
+		}
+		kind := "user"
+		switch {
+		case p.Group != nil:
+			kind = "group"
+		case p.Family != nil:
+			kind = "family"
+		}
+		perms = append(perms, NotePermission{Name: p.Name, Role: p.Role, Email: p.Email, Kind: kind})
+	}
+	return perms, nil
+}
+
 // GetAttachmentMetadata fetches metadata for a single attachment.
 func (s *Service) GetAttachmentMetadata(ctx context.Context, attachmentName string) (*keepapi.Attachment, error) {
+	ctx, span := startSpan(ctx, "GetAttachmentMetadata")
+	defer span.End()
 	svc, err := s.ensureKeepService()
 	if err != nil {
 		return nil, err
@@ -257,6 +349,31 @@ func (s *Service) GetAttachmentMetadata(ctx context.Context, attachmentName stri
 
 // DownloadAttachmentMedia downloads the raw bytes for an attachment.
 func (s *Service) DownloadAttachmentMedia(ctx context.Context, attachmentName, mimeType string) ([]byte, error) {
+	ctx, span := startSpan(ctx, "DownloadAttachmentMedia")
+	defer span.End()
+	media, err := s.OpenAttachmentMedia(ctx, attachmentName, mimeType)
+	if err != nil {
+		return nil, err
+	}
+	defer media.Body.Close()
+	data, err := io.ReadAll(media.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read attachment %s: %w", attachmentName, err)
+	}
+	return data, nil
+}
+
+// AttachmentMedia is an open attachment download. Callers must close Body.
+type AttachmentMedia struct {
+	Body          io.ReadCloser
+	ContentType   string
+	ContentLength int64
+}
+
+// OpenAttachmentMedia starts an attachment download without buffering it in memory.
+func (s *Service) OpenAttachmentMedia(ctx context.Context, attachmentName, mimeType string) (*AttachmentMedia, error) {
+	ctx, span := startSpan(ctx, "OpenAttachmentMedia")
+	defer span.End()
 	svc, err := s.ensureKeepService()
 	if err != nil {
 		return nil, err
@@ -269,12 +386,41 @@ func (s *Service) DownloadAttachmentMedia(ctx context.Context, attachmentName, m
 	if err != nil {
 		return nil, fmt.Errorf("unable to download attachment %s: %w", attachmentName, err)
 	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read attachment %s: %w", attachmentName, err)
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = mimeType
 	}
-	return data, nil
+	return &AttachmentMedia{
+		Body:          resp.Body,
+		ContentType:   contentType,
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+// preferredExtensions pins extensions for common Keep media, where the system
+// MIME table would otherwise pick the alphabetically first alias (e.g. ".jfif").
+var preferredExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"audio/3gpp": ".3gp",
+	"audio/amr":  ".amr",
+	"audio/mpeg": ".mp3",
+}
+
+// AttachmentFilename derives a file name from an attachment resource name
+// (notes/{note}/attachments/{id}) and its content type.
+func AttachmentFilename(name, contentType string) string {
+	base := path.Base(name)
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if ext, ok := preferredExtensions[mediaType]; ok {
+			return base + ext
+		}
+		if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+			return base + exts[0]
+		}
+	}
+	return base + ".bin"
 }
 
 func (s *Service) ensureKeepService() (*keepapi.Service, error) {
@@ -290,8 +436,8 @@ func (s *Service) listNotes(ctx context.Context, opts ListNotesOptions) (*keepap
 		return nil, err
 	}
 	call := svc.Notes.List()
-	if opts.Filter != "" {
-		call.Filter(opts.Filter)
+	if filter := opts.filter(); filter != "" {
+		call.Filter(filter)
 	}
 	if opts.PageSize > 0 {
 		call.PageSize(opts.PageSize)
@@ -317,9 +463,12 @@ func summarizeNote(note *keepapi.Note) Note {
 	}
 
 	return Note{
-		ID:      note.Name,
-		Title:   title,
-		Snippet: noteSnippet(note.Body),
+		ID:          note.Name,
+		Title:       title,
+		Snippet:     noteSnippet(note.Body),
+		Trashed:     note.Trashed,
+		TrashTime:   parseTime(note.TrashTime),
+		Attachments: len(note.Attachments),
 	}
 }
 
@@ -339,6 +488,35 @@ func noteSnippet(section *keepapi.Section) string {
 	return "..."
 }
 
+// NoteText flattens a note body into plain text; list items become one line each.
+func NoteText(section *keepapi.Section) string {
+	if section == nil {
+		return ""
+	}
+	if section.Text != nil {
+		return section.Text.Text
+	}
+	if section.List == nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(items []*keepapi.ListItem)
+	walk = func(items []*keepapi.ListItem) {
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			if item.Text != nil {
+				b.WriteString(item.Text.Text)
+				b.WriteByte('\n')
+			}
+			walk(item.ChildListItems)
+		}
+	}
+	walk(section.List.ListItems)
+	return b.String()
+}
+
 func truncateSnippet(src string) string {
 	if len(src) <= noteSnippetLimit {
 		return src
@@ -0,0 +1,222 @@
+/*
+File: internal/workspace/listitems.go
+Description: Checklist editing for Keep list notes. Items are addressed by
+their index path ([2] is the third item, [2, 0] its first child) and can be
+checked, unchecked, added or removed. The Keep API cannot update a note, so
+EditListNote writes the edited list as a new note, shares it with the same
+writers and then deletes the original.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	keepapi "google.golang.org/api/keep/v1"
+)
+
+// List item operations.
+const (
+	ListItemCheck   = "check"
+	ListItemUncheck = "uncheck"
+	ListItemAdd     = "add"
+	ListItemRemove  = "remove"
+)
+
+// Keep nests list items one level deep.
+const maxListItemDepth = 2
+
+var (
+	// ErrNotListNote reports a list item edit of a text note.
+	ErrNotListNote = errors.New("note is not a checklist")
+	// ErrNoteNotEditable reports a note that cannot be recreated as it is.
+	ErrNoteNotEditable = errors.New("note cannot be edited")
+	// ErrListItemNotFound reports an edit path with no item at it.
+	ErrListItemNotFound = errors.New("no list item at path")
+)
+
+// ListItemEdit is one change to a checklist.
+//
+//   - check and uncheck set the item at Path.
+//   - add inserts Text, checked if Checked is set, as the last child of the
+//     item at Path, or as the last top-level item when Path is empty.
+//   - remove deletes the item at Path with its children.
+type ListItemEdit struct {
+	Op      string `json:"op"`
+	Path    []int  `json:"path,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Checked bool   `json:"checked,omitempty"`
+}
+
+// ListItem is one checklist entry with the path edits address it by.
+type ListItem struct {
+	Path     []int      `json:"path"`
+	Text     string     `json:"text"`
+	Checked  bool       `json:"checked"`
+	Children []ListItem `json:"children,omitempty"`
+}
+
+// ListEditResult reports an edited checklist. Note is the new note's name and
+// Replaced the deleted original's.
+type ListEditResult struct {
+	Note     string     `json:"note"`
+	Replaced string     `json:"replaced"`
+	Items    []ListItem `json:"items"`
+}
+
+// ValidateListItemEdits checks edits before anything is sent.
+func ValidateListItemEdits(edits []ListItemEdit) error {
+	if len(edits) == 0 {
+		return fmt.Errorf("no edits")
+	}
+	for i, e := range edits {
+		if slices.ContainsFunc(e.Path, func(n int) bool { return n < 0 }) {
+			return fmt.Errorf("edit %d: negative index in path", i)
+		}
+		switch e.Op {
+		case ListItemCheck, ListItemUncheck, ListItemRemove:
+			if len(e.Path) == 0 {
+				return fmt.Errorf("edit %d: %s needs a path", i, e.Op)
+			}
+			if len(e.Path) > maxListItemDepth {
+				return fmt.Errorf("edit %d: path is deeper than Keep nests items", i)
+			}
+		case ListItemAdd:
+			if strings.TrimSpace(e.Text) == "" {
+				return fmt.Errorf("edit %d: add needs text", i)
+			}
+			if len(e.Path) >= maxListItemDepth {
+				return fmt.Errorf("edit %d: Keep nests items one level deep", i)
+			}
+		default:
+			return fmt.Errorf("edit %d: unknown op %q", i, e.Op)
+		}
+	}
+	return nil
+}
+
+// NoteListItems returns a list note's items.
+func NoteListItems(note *keepapi.Note) ([]ListItem, error) {
+	if note.Body == nil || note.Body.List == nil {
+		return nil, ErrNotListNote
+	}
+	return listItems(listItemInputs(note.Body.List.ListItems), nil), nil
+}
+
+// EditListNote applies edits, in order, to the list note noteID. The result
+// replaces the note: a new note with the same title and writers is created
+// and the original deleted, so the note's name changes. Trashed notes and
+// notes with attachments, which a new note cannot carry, are refused.
+func (s *Service) EditListNote(ctx context.Context, noteID string, edits []ListItemEdit) (ListEditResult, error) {
+	ctx, span := startSpan(ctx, "EditListNote")
+	defer span.End()
+	note, err := s.GetNote(ctx, noteID)
+	if err != nil {
+		return ListEditResult{}, err
+	}
+	if note.Body == nil || note.Body.List == nil {
+		return ListEditResult{}, ErrNotListNote
+	}
+	switch {
+	case note.Trashed:
+		return ListEditResult{}, fmt.Errorf("%w: it is in the trash", ErrNoteNotEditable)
+	case len(note.Attachments) > 0:
+		return ListEditResult{}, fmt.Errorf("%w: its attachments cannot be copied to a new note", ErrNoteNotEditable)
+	}
+	items := listItemInputs(note.Body.List.ListItems)
+	for i, e := range edits {
+		if items, err = applyListItemEdit(items, e); err != nil {
+			return ListEditResult{}, fmt.Errorf("edit %d: %w", i, err)
+		}
+	}
+
+	created, err := s.CreateListNote(ctx, note.Title, items)
+	if err != nil {
+		return ListEditResult{}, err
+	}
+	// Until the original is gone the new note is removed on failure, so a
+	// retry does not leave two copies.
+	undo := func(err error) error {
+		if uerr := s.DeleteNote(context.WithoutCancel(ctx), created.Name); uerr != nil {
+			return errors.Join(err, fmt.Errorf("new note %s left in place: %w", created.Name, uerr))
+		}
+		return err
+	}
+	var writers []string
+	for _, p := range note.Permissions {
+		if p.Role == "WRITER" && !p.Deleted && p.Email != "" {
+			writers = append(writers, p.Email)
+		}
+	}
+	if _, err := s.AddNoteWriters(ctx, created.Name, writers); err != nil {
+		return ListEditResult{}, undo(err)
+	}
+	if err := s.DeleteNote(ctx, note.Name); err != nil {
+		return ListEditResult{}, undo(err)
+	}
+	// The created note is read back because blank items are dropped on the
+	// way in, which shifts later paths.
+	res := ListEditResult{Note: created.Name, Replaced: note.Name}
+	if res.Items, err = NoteListItems(created); err != nil {
+		res.Items = listItems(items, nil)
+	}
+	return res, nil
+}
+
+// applyListItemEdit returns items with e applied. The slices along the path
+// are copied, so items is left as it was.
+func applyListItemEdit(items []ListItemInput, e ListItemEdit) ([]ListItemInput, error) {
+	items = slices.Clone(items)
+	if e.Op == ListItemAdd && len(e.Path) == 0 {
+		return append(items, ListItemInput{Text: e.Text, Checked: e.Checked}), nil
+	}
+	i := e.Path[0]
+	if i >= len(items) {
+		return nil, fmt.Errorf("%w %v", ErrListItemNotFound, e.Path)
+	}
+	if len(e.Path) > 1 {
+		children, err := applyListItemEdit(items[i].Children, ListItemEdit{Op: e.Op, Path: e.Path[1:], Text: e.Text, Checked: e.Checked})
+		if err != nil {
+			return nil, fmt.Errorf("%w %v", ErrListItemNotFound, e.Path)
+		}
+		items[i].Children = children
+		return items, nil
+	}
+	switch e.Op {
+	case ListItemCheck, ListItemUncheck:
+		items[i].Checked = e.Op == ListItemCheck
+	case ListItemAdd:
+		items[i].Children = append(slices.Clone(items[i].Children), ListItemInput{Text: e.Text, Checked: e.Checked})
+	case ListItemRemove:
+		items = slices.Delete(items, i, i+1)
+	}
+	return items, nil
+}
+
+func listItemInputs(items []*keepapi.ListItem) []ListItemInput {
+	out := make([]ListItemInput, 0, len(items))
+	for _, item := range items {
+		in := ListItemInput{Checked: item.Checked, Children: listItemInputs(item.ChildListItems)}
+		if item.Text != nil {
+			in.Text = item.Text.Text
+		}
+		out = append(out, in)
+	}
+	return out
+}
+
+func listItems(inputs []ListItemInput, parent []int) []ListItem {
+	out := make([]ListItem, 0, len(inputs))
+	for i, in := range inputs {
+		path := append(slices.Clone(parent), i)
+		item := ListItem{Path: path, Text: in.Text, Checked: in.Checked}
+		if len(in.Children) > 0 {
+			item.Children = listItems(in.Children, path)
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+/*
+File: internal/workspace/mail.go
+Description: Plain-text email sent through Gmail as the admin account, for
+reports Axis mails out.
+*/
+package workspace
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	gmail "google.golang.org/api/gmail/v1"
+)
+
+// ErrNoMail means no Gmail service was attached with WithGmail.
+var ErrNoMail = errors.New("gmail not configured")
+
+// WithGmail enables sending mail.
+func WithGmail(svc *gmail.Service) Option {
+	return func(s *Service) { s.gmailService = svc }
+}
+
+// SendMail sends a plain-text message to every address in to, from the
+// account the service acts as.
+func (s *Service) SendMail(ctx context.Context, to []string, subject, body string) error {
+	ctx, span := startSpan(ctx, "SendMail")
+	defer span.End()
+	if s.gmailService == nil {
+		return ErrNoMail
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	raw := base64.URLEncoding.EncodeToString([]byte(msg.String()))
+	if _, err := s.gmailService.Users.Messages.Send("me", &gmail.Message{Raw: raw}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("unable to send mail to %s: %w", strings.Join(to, ", "), err)
+	}
+	return nil
+}
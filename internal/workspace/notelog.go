@@ -0,0 +1,41 @@
+/*
+File: internal/workspace/notelog.go
+Description: Note-to-sheet logging. Appends one row per note (timestamp, title,
+snippet, action) to a tracking spreadsheet so processed and deleted notes leave
+a record outside Keep.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sheets "google.golang.org/api/sheets/v4"
+)
+
+// DefaultNoteLogRange appends to the first four columns of the first tab.
+const DefaultNoteLogRange = "A:D"
+
+// AppendNoteToSheet adds a row for note to the table found in a1Range (the
+// default range when empty). Cells are written as raw strings, so a title
+// starting with "=" is never evaluated as a formula.
+func (s *Service) AppendNoteToSheet(ctx context.Context, spreadsheetId, a1Range string, note RegistryItem, action string, at time.Time) error {
+	ctx, span := startSpan(ctx, "AppendNoteToSheet")
+	defer span.End()
+	if a1Range == "" {
+		a1Range = DefaultNoteLogRange
+	}
+	snippet := note.Snippet
+	if note.Source.Text != "" {
+		snippet = truncateSnippet(note.Source.Text)
+	}
+	row := []any{at.UTC().Format(time.RFC3339), note.Title, snippet, action}
+	_, err := s.sheetsService.Spreadsheets.Values.Append(spreadsheetId, a1Range, &sheets.ValueRange{
+		Values: [][]any{row},
+	}).ValueInputOption("RAW").InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to log note %s to sheet %s: %w", note.ID, spreadsheetId, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+/*
+File: internal/workspace/reports.go
+Description: Login activity from the Admin SDK Reports API. Directory users
+carry a lastLoginTime, but it lags and misses some sign-in paths; the login
+audit log records every successful sign-in, so the two are combined to judge
+whether an account is still in use.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	reports "google.golang.org/api/admin/reports/v1"
+)
+
+// WithReports enables login activity from the Reports API.
+func WithReports(svc *reports.Service) Option {
+	return func(s *Service) { s.reportsService = svc }
+}
+
+// ReportsEnabled reports whether login activity can be read.
+func (s *Service) ReportsEnabled() bool {
+	return s != nil && s.reportsService != nil
+}
+
+// LastLogins returns each user's latest successful login since the given
+// time, keyed by lowercased email. Users without a login are absent.
+func (s *Service) LastLogins(ctx context.Context, since time.Time) (map[string]time.Time, error) {
+	ctx, span := startSpan(ctx, "LastLogins")
+	defer span.End()
+	if s.reportsService == nil {
+		return nil, fmt.Errorf("login reports are not configured")
+	}
+	logins := make(map[string]time.Time)
+	err := s.reportsService.Activities.List("all", "login").
+		EventName("login_success").
+		StartTime(since.UTC().Format(time.RFC3339)).
+		MaxResults(1000).
+		Fields("nextPageToken", "items(actor(email),id(time))").
+		Pages(ctx, func(page *reports.Activities) error {
+			for _, a := range page.Items {
+				if a.Actor == nil || a.Id == nil || a.Actor.Email == "" {
+					continue
+				}
+				at := parseTime(a.Id.Time)
+				email := strings.ToLower(a.Actor.Email)
+				if at != nil && at.After(logins[email]) {
+					logins[email] = *at
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list login activity: %w", err)
+	}
+	return logins, nil
+}
@@ -0,0 +1,166 @@
+/*
+File: internal/workspace/search.go
+Description: Cross-type search. Docs and Sheets are found with Drive's fullText
+query; the Keep API filter only covers timestamps and trash state, so notes are
+listed and their titles and bodies matched locally. Results are scored on one
+scale so they can be merged into a single ranking.
+*/
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	drive "google.golang.org/api/drive/v3"
+	keep "google.golang.org/api/keep/v1"
+)
+
+// SearchResult is a registry item with its relevance score.
+type SearchResult struct {
+	RegistryItem
+	Score float64 `json:"score"`
+}
+
+// searchLimit caps the Drive matches read per type.
+const searchLimit = 500
+
+var driveQueryEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// Search finds items of the given types (all when none) matching query,
+// ordered by descending score.
+func (s *Service) Search(ctx context.Context, query string, types []string) ([]SearchResult, error) {
+	ctx, span := startSpan(ctx, "Search")
+	defer span.End()
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	if len(types) == 0 {
+		types = ItemTypes
+	}
+
+	var results []SearchResult
+	for _, itemType := range types {
+		var found []SearchResult
+		var err error
+		if slices.Contains(ItemTypes, itemType) && !s.Enabled(itemType) {
+			continue
+		}
+		switch itemType {
+		case "keep":
+			found, err = s.searchNotes(ctx, query, terms)
+		case "doc":
+			found, err = s.searchDrive(ctx, query, terms, "application/vnd.google-apps.document", "doc", "Google Doc")
+		case "sheet":
+			found, err = s.searchDrive(ctx, query, terms, "application/vnd.google-apps.spreadsheet", "sheet", "Google Sheet")
+		default:
+			return nil, fmt.Errorf("unknown item type %q", itemType)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Title) < strings.ToLower(results[j].Title)
+	})
+	return results, nil
+}
+
+func (s *Service) searchNotes(ctx context.Context, query string, terms []string) ([]SearchResult, error) {
+	var results []SearchResult
+	err := s.keepService.Notes.List().PageSize(keepPageSize).Pages(ctx, func(page *keep.ListNotesResponse) error {
+		for _, note := range page.Notes {
+			if note.Trashed {
+				continue
+			}
+			if score := textScore(query, terms, note.Title, NoteText(note.Body)); score > 0 {
+				results = append(results, SearchResult{RegistryItem: noteItem(note), Score: score})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search keep notes: %w", err)
+	}
+	return results, nil
+}
+
+// searchDrive relies on Drive's relevance order for body matches, which is
+// turned into a score that decays with rank, plus the local title score.
+func (s *Service) searchDrive(ctx context.Context, query string, terms []string, mimeType, itemType, snippet string) ([]SearchResult, error) {
+	q := fmt.Sprintf("mimeType='%s' and trashed=false and fullText contains '%s'", mimeType, driveQueryEscaper.Replace(query))
+	var files []*drive.File
+	err := s.driveService.Files.List().Q(q).PageSize(min(drivePageSize, searchLimit)).
+		Fields("nextPageToken", registryFileFields).
+		Pages(ctx, func(page *drive.FileList) error {
+			files = append(files, page.Files...)
+			if len(files) >= searchLimit {
+				return errSearchLimit
+			}
+			return nil
+		})
+	if err != nil && err != errSearchLimit {
+		return nil, fmt.Errorf("failed to search %ss: %w", itemType, err)
+	}
+	files = files[:min(len(files), searchLimit)]
+
+	results := make([]SearchResult, 0, len(files))
+	for i, file := range files {
+		relevance := 2 * (1 - float64(i)/float64(len(files)+1))
+		results = append(results, SearchResult{
+			RegistryItem: driveItem(file, itemType, snippet),
+			Score:        relevance + textScore(query, terms, file.Name, ""),
+		})
+	}
+	return results, nil
+}
+
+var errSearchLimit = fmt.Errorf("search limit reached")
+
+// textScore rates a title and body: 3 per term in the title, 1 per term in the
+// body plus a little per repeat, and 5 more when the whole query is in the
+// title. Every term must appear somewhere for a non-zero score when a body is
+// given.
+func textScore(query string, terms []string, title, body string) float64 {
+	title, body = strings.ToLower(title), strings.ToLower(body)
+	score := 0.0
+	for _, term := range terms {
+		inTitle := strings.Contains(title, term)
+		count := strings.Count(body, term)
+		if body != "" && !inTitle && count == 0 {
+			return 0
+		}
+		if inTitle {
+			score += 3
+		}
+		if count > 0 {
+			score += 1 + 0.2*float64(min(count, 5))
+		}
+	}
+	if len(terms) > 1 && strings.Contains(title, strings.ToLower(strings.TrimSpace(query))) {
+		score += 5
+	}
+	return score
+}
+
+// ParseTypes splits a comma-separated type filter, rejecting unknown types.
+func ParseTypes(raw string) ([]string, error) {
+	var types []string
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if !slices.Contains(ItemTypes, t) {
+			return nil, fmt.Errorf("unknown item type %q", t)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
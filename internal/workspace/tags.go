@@ -0,0 +1,202 @@
+/*
+File: internal/workspace/tags.go
+Description: Tags in the keep: namespace, standing in for labels the Keep API does
+not expose. For Docs and Sheets they map to the choices of one selection field
+on a Drive Label, so classification made in Drive shows up in listings and tags
+set through Axis are written back to Drive. Choices are matched by display
+name; the label schema is re-read when an unknown choice or name turns up.
+*/
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	drive "google.golang.org/api/drive/v3"
+	drivelabels "google.golang.org/api/drivelabels/v2"
+)
+
+// TagNamespace prefixes every tag Axis manages.
+const TagNamespace = "keep:"
+
+// tagSchemaRefresh limits how often an unknown choice re-reads the label.
+const tagSchemaRefresh = time.Minute
+
+// ErrUnknownTag reports a tag the Drive label has no choice for.
+var ErrUnknownTag = errors.New("unknown tag")
+
+// tagLabel is the Drive Label field tags map to.
+type tagLabel struct {
+	svc     *drivelabels.Service
+	labelID string
+	fieldID string
+
+	mu       sync.Mutex
+	names    map[string]string // choice ID to display name
+	ids      map[string]string // lower-case display name to choice ID
+	loadedAt time.Time
+}
+
+// WithTagLabel maps tags on Docs and Sheets to the choices of the selection
+// field fieldID on the Drive Label labelID.
+func WithTagLabel(svc *drivelabels.Service, labelID, fieldID string) Option {
+	return func(s *Service) {
+		s.tagLabel = &tagLabel{svc: svc, labelID: labelID, fieldID: fieldID}
+	}
+}
+
+// DriveTagsEnabled reports whether Doc and Sheet tags live in a Drive Label.
+func (s *Service) DriveTagsEnabled() bool {
+	return s != nil && s.tagLabel != nil
+}
+
+// NormalizeTags validates tags, trims them and drops duplicates, keeping the
+// first spelling of each.
+func NormalizeTags(tags []string) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		name, ok := strings.CutPrefix(strings.TrimSpace(tag), TagNamespace)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("tag %q must be %s<name>", tag, TagNamespace)
+		}
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			out = append(out, TagNamespace+name)
+		}
+	}
+	return out, nil
+}
+
+// loadLocked reads the field's choices; callers hold the lock.
+func (l *tagLabel) loadLocked(ctx context.Context) error {
+	l.loadedAt = time.Now()
+	label, err := l.svc.Labels.Get("labels/" + l.labelID).View("LABEL_VIEW_FULL").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to read Drive label %s: %w", l.labelID, err)
+	}
+	for _, field := range label.Fields {
+		if field.Id != l.fieldID {
+			continue
+		}
+		if field.SelectionOptions == nil {
+			return fmt.Errorf("field %s of Drive label %s is not a selection field", l.fieldID, l.labelID)
+		}
+		l.names = make(map[string]string)
+		l.ids = make(map[string]string)
+		for _, choice := range field.SelectionOptions.Choices {
+			if choice.Properties == nil || choice.Properties.DisplayName == "" {
+				continue
+			}
+			l.names[choice.Id] = choice.Properties.DisplayName
+			l.ids[strings.ToLower(choice.Properties.DisplayName)] = choice.Id
+		}
+		return nil
+	}
+	return fmt.Errorf("Drive label %s has no field %s", l.labelID, l.fieldID)
+}
+
+// resolve runs fn under the lock. When fn reports a miss, or the schema was
+// never read, the schema is re-read and fn run again, at most once per
+// tagSchemaRefresh so a failing label API is not called for every file.
+func (l *tagLabel) resolve(ctx context.Context, fn func() bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.names != nil && fn() {
+		return nil
+	}
+	if time.Since(l.loadedAt) < tagSchemaRefresh {
+		if l.names == nil {
+			return fmt.Errorf("Drive label %s is unavailable", l.labelID)
+		}
+		return nil
+	}
+	if err := l.loadLocked(ctx); err != nil {
+		return err
+	}
+	fn()
+	return nil
+}
+
+// fileTags converts a file's label values to tags. Unknown choices are
+// skipped, as is everything when the schema cannot be read.
+func (l *tagLabel) fileTags(ctx context.Context, info *drive.FileLabelInfo) []string {
+	var choices []string
+	if info != nil {
+		for _, label := range info.Labels {
+			if label.Id == l.labelID {
+				choices = label.Fields[l.fieldID].Selection
+			}
+		}
+	}
+	if len(choices) == 0 {
+		return nil
+	}
+	var tags []string
+	l.resolve(ctx, func() bool {
+		tags = tags[:0]
+		complete := true
+		for _, id := range choices {
+			if name, ok := l.names[id]; ok {
+				tags = append(tags, TagNamespace+name)
+			} else {
+				complete = false
+			}
+		}
+		return complete
+	})
+	return tags
+}
+
+// SetDriveTags replaces the tags on a Doc or Sheet, removing the label when
+// tags is empty. Each tag must name a choice of the label field.
+func (s *Service) SetDriveTags(ctx context.Context, fileID string, tags []string) error {
+	ctx, span := startSpan(ctx, "SetDriveTags")
+	defer span.End()
+	l := s.tagLabel
+	if l == nil {
+		return errors.New("no Drive label is configured for tags")
+	}
+	var choices []string
+	var missing string
+	err := l.resolve(ctx, func() bool {
+		choices, missing = choices[:0], ""
+		for _, tag := range tags {
+			id, ok := l.ids[strings.ToLower(strings.TrimPrefix(tag, TagNamespace))]
+			if !ok {
+				missing = tag
+				return false
+			}
+			choices = append(choices, id)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if missing != "" {
+		return fmt.Errorf("%w %q: Drive label %s has no such choice", ErrUnknownTag, missing, l.labelID)
+	}
+
+	mod := &drive.LabelModification{LabelId: l.labelID}
+	if len(choices) == 0 {
+		mod.RemoveLabel = true
+	} else {
+		mod.FieldModifications = []*drive.LabelFieldModification{{
+			FieldId:            l.fieldID,
+			SetSelectionValues: choices,
+		}}
+	}
+	_, err = s.driveService.Files.ModifyLabels(fileID, &drive.ModifyLabelsRequest{
+		LabelModifications: []*drive.LabelModification{mod},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to set tags on %s: %w", fileID, err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+/*
+File: internal/workspace/tracing.go
+Description: OpenTelemetry spans for Service methods. Each method that takes a
+context opens a "workspace.<Method>" span around its Google API calls, whose
+HTTP spans then nest under it.
+*/
+package workspace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("axis/internal/workspace")
+
+// startSpan opens the span for the Service method name.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "workspace."+name, trace.WithAttributes(attrs...))
+}
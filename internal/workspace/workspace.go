@@ -6,11 +6,23 @@ initialization logic for interfacing with Google Admin and Keep APIs.
 package workspace
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"axis/internal/reminder"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
 	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	calendar "google.golang.org/api/calendar/v3"
 	docs "google.golang.org/api/docs/v1"
 	drive "google.golang.org/api/drive/v3"
+	gmail "google.golang.org/api/gmail/v1"
 	keep "google.golang.org/api/keep/v1"
 	sheets "google.golang.org/api/sheets/v4"
 )
@@ -22,6 +34,74 @@ type Service struct {
 	docsService   *docs.Service
 	sheetsService *sheets.Service
 	driveService  *drive.Service
+
+	calendarService *calendar.Service
+	tagLabel        *tagLabel
+	impersonate     Impersonator
+	reportsService  *reports.Service
+	usageService    *reports.Service
+	gmailService    *gmail.Service
+	scopes          []string
+	types           []string // enabled item types; nil means all
+}
+
+// Option attaches optional Google API services.
+type Option func(*Service)
+
+// WithCalendar enables Calendar-backed features such as reminder events.
+func WithCalendar(svc *calendar.Service) Option {
+	return func(s *Service) { s.calendarService = svc }
+}
+
+// WithScopes records the OAuth scopes the API clients were built with.
+func WithScopes(scopes []string) Option {
+	return func(s *Service) { s.scopes = scopes }
+}
+
+// WithItemTypes limits the registry to the given item types. The others list
+// as empty without an API call, so their scopes need not be granted.
+func WithItemTypes(types ...string) Option {
+	return func(s *Service) { s.types = types }
+}
+
+// Types returns the enabled item types in display order.
+func (s *Service) Types() []string {
+	if s.types == nil {
+		return ItemTypes
+	}
+	var out []string
+	for _, t := range ItemTypes {
+		if slices.Contains(s.types, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Enabled reports whether itemType is enabled.
+func (s *Service) Enabled(itemType string) bool {
+	return s.types == nil || slices.Contains(s.types, itemType)
+}
+
+// Scopes returns the OAuth scopes recorded with WithScopes.
+func (s *Service) Scopes() []string {
+	return append([]string(nil), s.scopes...)
+}
+
+// Probe reports whether Google APIs answer: Drive first, then Keep. It
+// succeeds as soon as one of them does.
+func (s *Service) Probe(ctx context.Context) error {
+	ctx, span := startSpan(ctx, "Probe")
+	defer span.End()
+	_, derr := s.driveService.About.Get().Fields("user(emailAddress)").Context(ctx).Do()
+	if derr == nil {
+		return nil
+	}
+	_, kerr := s.keepService.Notes.List().PageSize(1).Context(ctx).Do()
+	if kerr == nil {
+		return nil
+	}
+	return fmt.Errorf("no Google API reachable: drive: %w; keep: %w", derr, kerr)
 }
 
 // User represents a simplified user structure
@@ -38,6 +118,75 @@ type RegistryItem struct {
 	Title   string `json:"title"`
 	Snippet string `json:"snippet"`
 	Status  string `json:"status,omitempty"`
+	// Tags are in the keep: namespace (see TagNamespace).
+	Tags []string `json:"tags,omitempty"`
+
+	Protected bool   `json:"protected,omitempty"`
+	Campaign  string `json:"campaign,omitempty"`
+
+	Reminders []reminder.Reminder `json:"reminders,omitempty"`
+	LinkRot   bool                `json:"link_rot,omitempty"`
+
+	// Created and Modified are the creation and last edit times reported by
+	// Keep or Drive, when known.
+	Created  *time.Time `json:"created,omitempty"`
+	Modified *time.Time `json:"modified,omitempty"`
+	// Trashed is set for Drive files in the trash, since TrashTime when
+	// Drive reports it; trashed notes are not listed.
+	Trashed   bool       `json:"trashed,omitempty"`
+	TrashTime *time.Time `json:"trash_time,omitempty"`
+	// WebLink opens the item in its Google app.
+	WebLink string `json:"web_link,omitempty"`
+	// Folder is the ID of the Drive folder holding a Doc or Sheet.
+	Folder string `json:"folder,omitempty"`
+
+	// Set by the server's enrichment pipeline.
+	Owner     string  `json:"owner,omitempty"`
+	Size      int64   `json:"size,omitempty"`
+	Staleness float64 `json:"staleness,omitempty"`
+	// OwnerOverStorage is set when the owner is past a storage threshold.
+	OwnerOverStorage bool `json:"owner_over_storage,omitempty"`
+	// External is set when the item is shared outside the Workspace domain;
+	// ExternalCollaborators lists those grants (see ItemSource.Shared).
+	External              bool     `json:"external,omitempty"`
+	ExternalCollaborators []string `json:"external_collaborators,omitempty"`
+	// DeleteAt is when a scheduled deletion falls due.
+	DeleteAt *time.Time `json:"delete_at,omitempty"`
+
+	// Source is listing metadata that enrichers may surface; it is not sent
+	// to clients.
+	Source ItemSource `json:"-"`
+}
+
+// ItemSource carries what a listing reported beyond the displayed fields.
+type ItemSource struct {
+	Owner string // owner's email
+	Bytes int64  // Drive storage used, or a Keep note's text length
+	Text  string // a Keep note's body; Drive listings carry no content
+	// Shared lists who else has access: emails, "domain:example.com" for a
+	// whole domain, or GrantAnyone for link sharing.
+	Shared []string
+}
+
+// GrantAnyone marks a Drive file anyone with the link can open.
+const GrantAnyone = "anyone"
+
+// fileGrants lists who a Drive file is shared with besides its owner. Drive
+// only reports permissions to callers allowed to share the file.
+func fileGrants(file *drive.File) []string {
+	var grants []string
+	for _, p := range file.Permissions {
+		switch {
+		case p.Role == "owner":
+		case p.Type == "anyone":
+			grants = append(grants, GrantAnyone)
+		case p.Type == "domain" && p.Domain != "":
+			grants = append(grants, "domain:"+p.Domain)
+		case p.EmailAddress != "":
+			grants = append(grants, p.EmailAddress)
+		}
+	}
+	return grants
 }
 
 // NewService creates a new workspace service wrapper
@@ -47,14 +196,19 @@ func NewService(
 	docsSvc *docs.Service,
 	sheetsSvc *sheets.Service,
 	driveSvc *drive.Service,
+	opts ...Option,
 ) *Service {
-	return &Service{
+	s := &Service{
 		adminService:  adminSvc,
 		keepService:   keepSvc,
 		docsService:   docsSvc,
 		sheetsService: sheetsSvc,
 		driveService:  driveSvc,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // GetUser retrieves a user by email
@@ -71,68 +225,302 @@ func (s *Service) GetUser(email string) (*User, error) {
 	}, nil
 }
 
+// ItemTypes lists the registry item types in display order.
+var ItemTypes = []string{"keep", "doc", "sheet"}
+
 // ListRegistryItems provides a consolidated list of Keep, Docs, and Sheets.
+// The types are listed concurrently; when some fail, the items of the others
+// are returned with an error naming the failed ones.
 func (s *Service) ListRegistryItems() ([]RegistryItem, error) {
+	lists := make([][]RegistryItem, len(ItemTypes))
+	errs := make([]error, len(ItemTypes))
+	var g errgroup.Group
+	for i, itemType := range ItemTypes {
+		g.Go(func() error {
+			lists[i], errs[i] = s.ListItems(context.Background(), itemType)
+			return nil
+		})
+	}
+	g.Wait()
 	var items []RegistryItem
+	for _, list := range lists {
+		items = append(items, list...)
+	}
+	return items, errors.Join(errs...)
+}
 
-	// 1. Fetch Keep Notes
-	notes, err := s.keepService.Notes.List().Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list keep notes: %w", err)
-	}
-	for _, note := range notes.Notes {
-		if !note.Trashed {
-			items = append(items, RegistryItem{
-				ID:      note.Name,
-				Type:    "keep",
-				Title:   note.Title,
-				Snippet: "Google Keep Note",
-			})
+// ListItems lists every registry item of one type, following pagination to the
+// end, so each type can be refreshed on its own schedule.
+func (s *Service) ListItems(ctx context.Context, itemType string) ([]RegistryItem, error) {
+	ctx, span := startSpan(ctx, "ListItems", attribute.String("item.type", itemType))
+	defer span.End()
+	if slices.Contains(ItemTypes, itemType) && !s.Enabled(itemType) {
+		return nil, nil
+	}
+	switch itemType {
+	case "keep":
+		var items []RegistryItem
+		err := s.keepService.Notes.List().PageSize(keepPageSize).Pages(ctx, func(page *keep.ListNotesResponse) error {
+			for _, note := range page.Notes {
+				if !note.Trashed {
+					items = append(items, noteItem(note))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list keep notes: %w", err)
+		}
+		return items, nil
+	case "doc":
+		items, err := s.listDriveItems(ctx, "application/vnd.google-apps.document", "doc", "Google Doc")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list docs: %w", err)
+		}
+		return items, nil
+	case "sheet":
+		items, err := s.listDriveItems(ctx, "application/vnd.google-apps.spreadsheet", "sheet", "Google Sheet")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list sheets: %w", err)
 		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unknown item type %q", itemType)
 	}
+}
 
-	// 2. Fetch Google Docs
-	docsList, err := s.driveService.Files.List().Q("mimeType='application/vnd.google-apps.document'").PageSize(50).Do()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list docs: %w", err)
-	}
-	for _, file := range docsList.Files {
-		items = append(items, RegistryItem{
-			ID:      file.Id,
-			Type:    "doc",
-			Title:   file.Name,
-			Snippet: "Google Doc",
-		})
+// listDriveItems lists every Drive file of one MIME type.
+func (s *Service) listDriveItems(ctx context.Context, mimeType, itemType, snippet string) ([]RegistryItem, error) {
+	var items []RegistryItem
+	call := s.driveService.Files.List().
+		Q(fmt.Sprintf("mimeType='%s'", mimeType)).
+		PageSize(drivePageSize).
+		Fields("nextPageToken", registryFileFields)
+	if s.tagLabel != nil {
+		call = call.IncludeLabels(s.tagLabel.labelID).Fields("nextPageToken", registryFileFieldsWithLabels)
+	}
+	err := call.Pages(ctx, func(page *drive.FileList) error {
+		for _, file := range page.Files {
+			item := driveItem(file, itemType, snippet)
+			if s.tagLabel != nil {
+				item.Tags = s.tagLabel.fileTags(ctx, file.LabelInfo)
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// driveItem converts a listed Drive file to a registry item.
+func driveItem(file *drive.File, itemType, snippet string) RegistryItem {
+	item := RegistryItem{
+		ID:        file.Id,
+		Type:      itemType,
+		Title:     file.Name,
+		Snippet:   snippet,
+		Created:   parseTime(file.CreatedTime),
+		Modified:  parseTime(file.ModifiedTime),
+		Trashed:   file.Trashed,
+		TrashTime: parseTime(file.TrashedTime),
+		WebLink:   file.WebViewLink,
+		Folder:    firstParent(file.Parents),
+		Source:    ItemSource{Owner: fileOwner(file), Bytes: file.QuotaBytesUsed, Shared: fileGrants(file)},
+	}
+	if item.WebLink == "" {
+		item.WebLink = ItemURL(item)
+	}
+	return item
+}
+
+// ItemURL returns the browser link for a registry item, or "" for unknown types.
+func ItemURL(item RegistryItem) string {
+	switch item.Type {
+	case "keep":
+		return "https://keep.google.com/#NOTE/" + strings.TrimPrefix(item.ID, "notes/")
+	case "doc":
+		return "https://docs.google.com/document/d/" + item.ID + "/edit"
+	case "sheet":
+		return "https://docs.google.com/spreadsheets/d/" + item.ID + "/edit"
+	default:
+		return ""
+	}
+}
+
+// registryFileFields limits Drive listings to what registry items carry.
+const registryFileFields = "files(id,name,createdTime,modifiedTime,trashed,trashedTime,webViewLink,parents,owners(emailAddress),quotaBytesUsed,permissions(type,role,emailAddress,domain))"
+
+// registryFileFieldsWithLabels adds the tag label's values.
+const registryFileFieldsWithLabels = "files(id,name,createdTime,modifiedTime,trashed,trashedTime,webViewLink,parents,owners(emailAddress),quotaBytesUsed,permissions(type,role,emailAddress,domain),labelInfo)"
+
+// Page sizes for full registry listings: the Drive maximum, and Keep's.
+const (
+	drivePageSize = 1000
+	keepPageSize  = 100
+)
+
+// fileOwner returns the email of a Drive file's owner, if any.
+func fileOwner(file *drive.File) string {
+	if len(file.Owners) == 0 {
+		return ""
 	}
+	return file.Owners[0].EmailAddress
+}
 
-	// 3. Fetch Google Sheets
-	sheetsList, err := s.driveService.Files.List().Q("mimeType='application/vnd.google-apps.spreadsheet'").PageSize(50).Do()
+// firstParent returns a file's folder; Drive files have at most one parent.
+func firstParent(parents []string) string {
+	if len(parents) == 0 {
+		return ""
+	}
+	return parents[0]
+}
+
+// parseTime converts an RFC 3339 API timestamp, returning nil when absent.
+func parseTime(raw string) *time.Time {
+	t, err := time.Parse(time.RFC3339, raw)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list sheets: %w", err)
-	}
-	for _, file := range sheetsList.Files {
-		items = append(items, RegistryItem{
-			ID:      file.Id,
-			Type:    "sheet",
-			Title:   file.Name,
-			Snippet: "Google Sheet",
-		})
+		return nil
+	}
+	return &t
+}
+
+// noteItem converts a Keep note to a registry item.
+func noteItem(note *keep.Note) RegistryItem {
+	text := NoteText(note.Body)
+	item := RegistryItem{
+		ID:        note.Name,
+		Type:      "keep",
+		Title:     note.Title,
+		Snippet:   "Google Keep Note",
+		Reminders: reminder.Extract(text, noteReference(note)),
+		Created:   parseTime(note.CreateTime),
+		Modified:  parseTime(note.UpdateTime),
+		Trashed:   note.Trashed,
+		TrashTime: parseTime(note.TrashTime),
+		Source:    ItemSource{Bytes: int64(len(text)), Text: text},
+	}
+	item.WebLink = ItemURL(item)
+	for _, p := range note.Permissions {
+		switch {
+		case p.Deleted || p.Email == "":
+		case p.Role == "OWNER" && item.Source.Owner == "":
+			item.Source.Owner = p.Email
+		case p.Role != "OWNER":
+			item.Source.Shared = append(item.Source.Shared, p.Email)
+		}
 	}
+	return item
+}
 
-	return items, nil
+// noteReference is the time relative reminder phrases are resolved against.
+func noteReference(note *keep.Note) time.Time {
+	if t, err := time.Parse(time.RFC3339, note.UpdateTime); err == nil {
+		return t
+	}
+	return time.Now()
 }
 
 // GetSheet retrieves a Google Sheet by its ID
-func (s *Service) GetSheet(spreadsheetId string) (*sheets.Spreadsheet, error) {
-	sheet, err := s.sheetsService.Spreadsheets.Get(spreadsheetId).Do()
+func (s *Service) GetSheet(ctx context.Context, spreadsheetId string) (*sheets.Spreadsheet, error) {
+	ctx, span := startSpan(ctx, "GetSheet")
+	defer span.End()
+	sheet, err := s.sheetsService.Spreadsheets.Get(spreadsheetId).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve sheet %s: %w", spreadsheetId, err)
 	}
 	return sheet, nil
 }
 
+// GetSheetValues reads the cell values of an A1 range (e.g. "Protected!A:C").
+func (s *Service) GetSheetValues(spreadsheetId, a1Range string) (*sheets.ValueRange, error) {
+	values, err := s.sheetsService.Spreadsheets.Values.Get(spreadsheetId, a1Range).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read range %s of sheet %s: %w", a1Range, spreadsheetId, err)
+	}
+	return values, nil
+}
+
+// UpdateSheetValues writes rows of values into an A1 range and returns the
+// written range as Sheets now renders it. Unless raw is set, values are parsed
+// as if typed into the UI, so "=SUM(A1:A3)" becomes a formula and "3" a number.
+func (s *Service) UpdateSheetValues(ctx context.Context, spreadsheetId, a1Range string, values [][]any, raw bool) (*sheets.UpdateValuesResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateSheetValues")
+	defer span.End()
+	input := "USER_ENTERED"
+	if raw {
+		input = "RAW"
+	}
+	resp, err := s.sheetsService.Spreadsheets.Values.Update(spreadsheetId, a1Range, &sheets.ValueRange{
+		Range:  a1Range,
+		Values: values,
+	}).ValueInputOption(input).IncludeValuesInResponse(true).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to write range %s of sheet %s: %w", a1Range, spreadsheetId, err)
+	}
+	return resp, nil
+}
+
+// NewSheet describes one tab of a spreadsheet to create. Rows hold the
+// initial cells: strings are stored as text, numbers and booleans as such.
+type NewSheet struct {
+	Title string  `json:"title"`
+	Rows  [][]any `json:"rows,omitempty"`
+}
+
+// CreateSpreadsheet creates a spreadsheet with the given tabs, or a single
+// default tab when none are given.
+func (s *Service) CreateSpreadsheet(ctx context.Context, title string, tabs []NewSheet) (*sheets.Spreadsheet, error) {
+	ctx, span := startSpan(ctx, "CreateSpreadsheet")
+	defer span.End()
+	spreadsheet := &sheets.Spreadsheet{Properties: &sheets.SpreadsheetProperties{Title: title}}
+	for _, tab := range tabs {
+		sheet := &sheets.Sheet{Properties: &sheets.SheetProperties{Title: tab.Title}}
+		if len(tab.Rows) > 0 {
+			grid := &sheets.GridData{}
+			for _, row := range tab.Rows {
+				cells := make([]*sheets.CellData, len(row))
+				for i, v := range row {
+					cells[i] = &sheets.CellData{UserEnteredValue: cellValue(v)}
+				}
+				grid.RowData = append(grid.RowData, &sheets.RowData{Values: cells})
+			}
+			sheet.Data = []*sheets.GridData{grid}
+		}
+		spreadsheet.Sheets = append(spreadsheet.Sheets, sheet)
+	}
+	created, err := s.sheetsService.Spreadsheets.Create(spreadsheet).
+		Fields("spreadsheetId", "spreadsheetUrl", "properties.title", "sheets.properties").
+		Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create sheet %q: %w", title, err)
+	}
+	return created, nil
+}
+
+// cellValue converts a JSON value to a cell value; nil leaves the cell empty.
+func cellValue(v any) *sheets.ExtendedValue {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case bool:
+		return &sheets.ExtendedValue{BoolValue: &val}
+	case float64:
+		return &sheets.ExtendedValue{NumberValue: &val}
+	case int:
+		n := float64(val)
+		return &sheets.ExtendedValue{NumberValue: &n}
+	case string:
+		return &sheets.ExtendedValue{StringValue: &val}
+	default:
+		text := fmt.Sprint(val)
+		return &sheets.ExtendedValue{StringValue: &text}
+	}
+}
+
 // DeleteSheet deletes a Google Sheet by its ID
-func (s *Service) DeleteSheet(spreadsheetId string) error {
+func (s *Service) DeleteSheet(ctx context.Context, spreadsheetId string) error {
+	ctx, span := startSpan(ctx, "DeleteSheet")
+	defer span.End()
 	_, err := s.sheetsService.Spreadsheets.BatchUpdate(spreadsheetId, &sheets.BatchUpdateSpreadsheetRequest{
 		Requests: []*sheets.Request{
 			{
@@ -141,7 +529,7 @@ func (s *Service) DeleteSheet(spreadsheetId string) error {
 				},
 			},
 		},
-	}).Do()
+	}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("unable to delete sheet %s: %w", spreadsheetId, err)
 	}
@@ -149,16 +537,84 @@ func (s *Service) DeleteSheet(spreadsheetId string) error {
 }
 
 // GetDoc retrieves a Google Doc by its ID
-func (s *Service) GetDoc(documentId string) (*docs.Document, error) {
-	doc, err := s.docsService.Documents.Get(documentId).Do()
+func (s *Service) GetDoc(ctx context.Context, documentId string) (*docs.Document, error) {
+	ctx, span := startSpan(ctx, "GetDoc")
+	defer span.End()
+	doc, err := s.docsService.Documents.Get(documentId).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve doc %s: %w", documentId, err)
 	}
 	return doc, nil
 }
 
+// CreateDoc creates a Google Doc holding bodyText. The Docs API creates
+// documents empty, so the text is inserted by a second call; if that fails the
+// created document is still returned with the error.
+func (s *Service) CreateDoc(ctx context.Context, title, bodyText string) (*docs.Document, error) {
+	ctx, span := startSpan(ctx, "CreateDoc")
+	defer span.End()
+	doc, err := s.docsService.Documents.Create(&docs.Document{Title: title}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create doc %q: %w", title, err)
+	}
+	if bodyText == "" {
+		return doc, nil
+	}
+	_, err = s.docsService.Documents.BatchUpdate(doc.DocumentId, &docs.BatchUpdateDocumentRequest{
+		Requests: []*docs.Request{{
+			InsertText: &docs.InsertTextRequest{
+				Location: &docs.Location{Index: 1},
+				Text:     bodyText,
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return doc, fmt.Errorf("created doc %s but unable to write its body: %w", doc.DocumentId, err)
+	}
+	return doc, nil
+}
+
+// AppendDocText adds text to the end of a Google Doc's body.
+func (s *Service) AppendDocText(ctx context.Context, documentId, text string) error {
+	ctx, span := startSpan(ctx, "AppendDocText")
+	defer span.End()
+	if text == "" {
+		return nil
+	}
+	_, err := s.docsService.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{
+		Requests: []*docs.Request{{
+			InsertText: &docs.InsertTextRequest{
+				EndOfSegmentLocation: &docs.EndOfSegmentLocation{},
+				Text:                 text,
+			},
+		}},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("unable to append to doc %s: %w", documentId, err)
+	}
+	return nil
+}
+
+// ShareFile grants each email role ("reader", "commenter" or "writer") on a
+// Drive file; Drive emails each of them the link. It stops at the first
+// failed grant.
+func (s *Service) ShareFile(ctx context.Context, fileID, role string, emails []string) error {
+	ctx, span := startSpan(ctx, "ShareFile")
+	defer span.End()
+	for _, email := range emails {
+		_, err := s.driveService.Permissions.Create(fileID, &drive.Permission{Type: "user", Role: role, EmailAddress: email}).
+			SupportsAllDrives(true).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("unable to share %s with %s: %w", fileID, email, err)
+		}
+	}
+	return nil
+}
+
 // DeleteDoc deletes a Google Doc by its ID
-func (s *Service) DeleteDoc(documentId string) error {
+func (s *Service) DeleteDoc(ctx context.Context, documentId string) error {
+	ctx, span := startSpan(ctx, "DeleteDoc")
+	defer span.End()
 	_, err := s.docsService.Documents.BatchUpdate(documentId, &docs.BatchUpdateDocumentRequest{
 		Requests: []*docs.Request{
 			{
@@ -169,7 +625,7 @@ func (s *Service) DeleteDoc(documentId string) error {
 				},
 			},
 		},
-	}).Do()
+	}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("unable to delete doc %s: %w", documentId, err)
 	}
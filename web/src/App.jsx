@@ -3,7 +3,7 @@ File: web/src/App.jsx
 Description: React terminal interface for Axis Mundi. Handles keyboard navigation, 
 real-time telemetry display, and unified registry management with lowercase key binding.
 */
-import { useState, useEffect, useRef, useMemo } from 'react';
+import { useState, useEffect, useRef } from 'react';
 
 const App = () => {
     const [mode, setMode] = useState('MANUAL');
@@ -19,14 +19,20 @@ const App = () => {
     const [detailError, setDetailError] = useState(null);
     const [connected, setConnected] = useState(false);
     const [secondsRemaining, setSecondsRemaining] = useState(null);
+    const [countdowns, setCountdowns] = useState({});
+    const [view, setView] = useState('registry');
+    const [degraded, setDegraded] = useState([]);
+    const [suspended, setSuspended] = useState({ users: [], playbook: '' });
+    const [suspendedIndex, setSuspendedIndex] = useState(0);
+    const statusesRef = useRef(null);
     const scrollRef = useRef(null);
     const registryRef = useRef(null);
     const detailRef = useRef(null);
 
-    const stateRef = useRef({ mode, selectedIndex, registry, showDetail });
+    const stateRef = useRef({ mode, selectedIndex, registry, showDetail, view, suspended, suspendedIndex });
     useEffect(() => {
-        stateRef.current = { mode, selectedIndex, registry, showDetail };
-    }, [mode, selectedIndex, registry, showDetail]);
+        stateRef.current = { mode, selectedIndex, registry, showDetail, view, suspended, suspendedIndex };
+    }, [mode, selectedIndex, registry, showDetail, view, suspended, suspendedIndex]);
 
     // Auto-scroll registry list when selectedIndex changes
     useEffect(() => {
@@ -71,7 +77,7 @@ const App = () => {
     const syncMode = async (newMode) => {
         setMode(newMode);
         try {
-            await fetch(`/api/mode?set=${newMode}`);
+            await fetch(`/api/mode?set=${newMode}`, { method: 'POST' });
         } catch (err) {
             addLog('error', `Failed to sync mode ${newMode}`);
         }
@@ -79,7 +85,7 @@ const App = () => {
 
     const fetchRegistry = async () => {
         try {
-            const res = await fetch('/api/registry');
+            const res = await fetch('/api/registry?type=keep', { cache: 'no-cache' });
             const data = await res.json();
             const list = Array.isArray(data) ? data : [];
             const filtered = list.filter(item => item.type === 'keep');
@@ -90,6 +96,31 @@ const App = () => {
         }
     };
 
+    const fetchSuspended = async (refresh) => {
+        addLog('system', 'Scanning suspended accounts...');
+        try {
+            const res = await fetch(`/api/suspended${refresh ? '?refresh=1' : ''}`);
+            if (!res.ok) throw new Error('scan failed');
+            const data = await res.json();
+            setSuspended({ users: data.users || [], playbook: data.playbook || '' });
+            setSuspendedIndex(0);
+            addLog('success', `Suspended accounts: ${(data.users || []).length}`);
+        } catch (err) {
+            addLog('error', 'Failed to scan suspended accounts.');
+        }
+    };
+
+    const launchSuspendedPlaybook = async (u) => {
+        if (!u) return;
+        try {
+            const res = await fetch(`/api/suspended/launch?email=${encodeURIComponent(u.email)}`, { method: 'POST' });
+            if (res.ok) addLog('execute', `Playbook launched for ${u.email}`);
+            else addLog('error', `Playbook failed for ${u.email}: ${(await res.json().catch(() => ({}))).error?.message || res.status}`);
+        } catch (err) {
+            addLog('error', `Playbook launch failed for ${u.email}`);
+        }
+    };
+
     const loadItemDetail = async (item) => {
         if (!item || !item.id) {
             setDetailError('Missing item identifier.');
@@ -99,25 +130,8 @@ const App = () => {
         setDetailItem(null);
         setDetailError(null);
 
-        let url = '';
-        switch (item.type) {
-            case 'keep':
-                url = `/api/notes/detail?id=${encodeURIComponent(item.id)}`;
-                break;
-            case 'doc':
-                url = `/api/docs?id=${encodeURIComponent(item.id)}`;
-                break;
-            case 'sheet':
-                url = `/api/sheets?id=${encodeURIComponent(item.id)}`;
-                break;
-            default:
-                setDetailError(`Unknown item type: ${item.type}`);
-                setDetailLoading(false);
-                return;
-        }
-
         try {
-            const res = await fetch(url);
+            const res = await fetch(`/api/items?id=${encodeURIComponent(item.id)}&type=${encodeURIComponent(item.type || '')}`);
             if (!res.ok) throw new Error(`detail fetch failed for ${item.type}`);
             const data = await res.json();
             setDetailItem(data);
@@ -152,7 +166,8 @@ const App = () => {
         try {
             const res = await fetch(url, { method: 'DELETE' });
             if (res.ok) {
-                addLog('success', `Object purged (${item.type}): ${item.id}`);
+                if (stateRef.current.mode === 'AIRGAP') addLog('system', `Deletion proposed for plan (${item.type}): ${item.id}`);
+                else if (stateRef.current.mode !== 'SIMULATE') addLog('success', `Object purged (${item.type}): ${item.id}`);
             } else {
                 throw new Error('Purge request failed');
             }
@@ -161,12 +176,47 @@ const App = () => {
         }
     };
 
+    // Moves the item to the archive folder; notes are exported to a Doc first.
+    const archiveItem = async (item) => {
+        if (!item || !item.id) return;
+        try {
+            const res = await fetch(`/api/registry/archive?id=${encodeURIComponent(item.id)}&type=${item.type}`, { method: 'POST' });
+            if (!res.ok) throw new Error(`HTTP ${res.status}`);
+            const data = await res.json();
+            addLog(data.simulated ? 'warning' : 'success', `${data.simulated ? 'Would archive' : 'Archived'} (${item.type}): ${item.title}`);
+        } catch (err) {
+            addLog('error', `Archive failed for ${item.type}: ${item.id}`);
+        }
+    };
+
+    // Schedules the item for deletion in 7 days, or cancels its schedule.
+    const toggleDeadline = async (item) => {
+        if (!item || !item.id) return;
+        const cancel = Boolean(item.delete_at);
+        const url = `/api/status/deadline?id=${encodeURIComponent(item.id)}&type=${item.type}${cancel ? '' : '&in=7d'}`;
+        try {
+            const res = await fetch(url, { method: cancel ? 'DELETE' : 'POST' });
+            if (!res.ok) throw new Error(`HTTP ${res.status}`);
+            addLog('warning', cancel ? `Scheduled deletion canceled: ${item.title}` : `Deletion scheduled in 7 days: ${item.title}`);
+        } catch (err) {
+            addLog('error', `Failed to ${cancel ? 'cancel' : 'schedule'} deletion: ${item.title}`);
+        }
+    };
+
     useEffect(() => {
         const init = async () => {
             try {
                 const userRes = await fetch('/api/user');
+                if (userRes.status === 401) {
+                    window.location.href = '/auth/login';
+                    return;
+                }
+                if (userRes.status === 403) addLog('error', 'Access denied: insufficient role');
                 if (userRes.ok) setUser(await userRes.json());
                 
+                const statusesRes = await fetch('/api/statuses');
+                if (statusesRes.ok) statusesRef.current = await statusesRes.json();
+
                 const modeRes = await fetch('/api/mode');
                 if (modeRes.ok) {
                     const modeData = await modeRes.json();
@@ -175,6 +225,17 @@ const App = () => {
                         addLog('system', `State asserted: ${modeData.mode}`);
                     }
                 }
+
+                // Replay the last day of status changes missed while offline.
+                const since = new Date(Date.now() - 24 * 3600 * 1000).toISOString();
+                const replayRes = await fetch(`/api/events/replay?since=${encodeURIComponent(since)}&type=status,simulated&limit=50`);
+                if (replayRes.ok) {
+                    const { entries } = await replayRes.json();
+                    (entries || []).forEach(({ type, data }) => {
+                        if (type === 'status' && data.status && data.title) addLog('system', `Replayed status → ${data.status}: ${data.title}`);
+                        if (type === 'simulated') addLog('system', `Replayed simulation (${data.type}): ${data.title || data.id}`);
+                    });
+                }
             } catch (e) {
                 console.error("Init failed", e);
             }
@@ -183,12 +244,18 @@ const App = () => {
     }, []);
 
     useEffect(() => {
-        const es = new EventSource('/api/events');
-        es.onopen = () => { setConnected(true); addLog('success', 'Uplink established (SSE).'); };
-        
-        es.onmessage = (e) => {
-            try {
-                const data = JSON.parse(e.data);
+        const upsertItems = (data) => {
+            const changed = (Array.isArray(data) ? data : []).filter(item => item.type === 'keep');
+            if (changed.length === 0) return;
+            setRegistry(prev => {
+                const byId = new Map(changed.map(item => [item.id, item]));
+                const next = prev.map(item => byId.get(item.id) || item);
+                const known = new Set(prev.map(item => item.id));
+                return [...next, ...changed.filter(item => !known.has(item.id))];
+            });
+        };
+        const handlers = {
+            registry: (data) => {
                 const list = Array.isArray(data) ? data : [];
                 const filtered = list.filter(item => item.type === 'keep');
                 setRegistry(filtered);
@@ -197,26 +264,99 @@ const App = () => {
                     if (filtered.length === 0) return 0;
                     return Math.min(prev, filtered.length - 1);
                 });
-            } catch (err) { console.error('Stream parse error', err); }
-        };
-
-        es.addEventListener('tick', (e) => {
-            try {
-                const data = JSON.parse(e.data);
+            },
+            // Between full snapshots the registry arrives as changes only.
+            'item-added': (data) => upsertItems(data),
+            'item-updated': (data) => upsertItems(data),
+            'item-removed': (data) => {
+                const gone = new Set(Array.isArray(data) ? data : []);
+                setRegistry(prev => {
+                    const next = prev.filter(item => !gone.has(item.id));
+                    setSelectedIndex(i => Math.max(0, Math.min(i, next.length - 1)));
+                    return next;
+                });
+            },
+            tick: (data) => {
                 if (data.seconds_remaining !== undefined) {
                     setSecondsRemaining(data.seconds_remaining);
                 }
-            } catch (err) { console.error('Tick parse error', err); }
-        });
-
-        es.addEventListener('status', (e) => {
-            try {
-                const data = JSON.parse(e.data);
+            },
+            countdown: (data) => {
+                const next = {};
+                (data.items || []).forEach(item => { next[item.id] = item.seconds_remaining; });
+                setCountdowns(next);
+            },
+            status: (data) => {
                 if (data.status && data.title) {
                     const logType = data.status === 'Execute' ? 'execute' : 'warning';
                     addLog(logType, `Status → ${data.status}: ${data.title}`);
                 }
-            } catch (err) { console.error('Status event parse error', err); }
+            },
+            mode: (data) => {
+                if (data.mode) {
+                    setMode(data.mode);
+                    addLog('system', `State asserted: ${data.mode}`);
+                }
+            },
+            simulated: (data) => {
+                addLog('simulate', `Would delete (${data.type}): ${data.title || data.id}`);
+            },
+            sources: (data) => {
+                const list = Array.isArray(data) ? data : [];
+                setDegraded(prev => [...prev.filter(name => name.startsWith('auth:')), ...list.filter(src => !src.ok).map(src => src.type)]);
+                list.filter(src => !src.ok).forEach(src => addLog('error', `Source degraded (${src.type}): ${src.error}`));
+            },
+            'auth-degraded': (data) => {
+                setDegraded(prev => prev.includes(`auth:${data.name}`) ? prev : [...prev, `auth:${data.name}`]);
+                addLog('error', `Credentials failing (${data.name}): ${data.error}`);
+            },
+            'auth-restored': (data) => {
+                setDegraded(prev => prev.filter(name => name !== `auth:${data.name}`));
+                addLog('success', `Credentials restored (${data.name}).`);
+            },
+            job: (data) => {
+                if (data.state === 'succeeded') addLog('success', `Job ${data.kind} finished (${data.done}${data.total ? `/${data.total}` : ''}).`);
+                if (data.state === 'failed') addLog('error', `Job ${data.kind} failed: ${data.error}`);
+                if (data.state === 'canceled') addLog('warning', `Job ${data.kind} canceled.`);
+            },
+        };
+
+        const dispatch = (event, raw) => {
+            try {
+                const handler = handlers[event];
+                if (handler) handler(typeof raw === 'string' ? JSON.parse(raw) : raw);
+            } catch (err) { console.error(`Stream parse error (${event})`, err); }
+        };
+
+        // ?transport=ws switches to the WebSocket uplink for proxies that buffer SSE.
+        if (new URLSearchParams(window.location.search).get('transport') === 'ws') {
+            let ws;
+            let retry;
+            let closed = false;
+            const connect = () => {
+                const proto = window.location.protocol === 'https:' ? 'wss' : 'ws';
+                ws = new WebSocket(`${proto}://${window.location.host}/api/ws`);
+                ws.onopen = () => { setConnected(true); addLog('success', 'Uplink established (WebSocket).'); };
+                ws.onmessage = (e) => {
+                    try {
+                        const msg = JSON.parse(e.data);
+                        dispatch(msg.event || 'registry', msg.data);
+                    } catch (err) { console.error('Stream parse error', err); }
+                };
+                ws.onclose = () => {
+                    setConnected(false);
+                    if (!closed) retry = setTimeout(connect, 3000);
+                };
+            };
+            connect();
+            return () => { closed = true; clearTimeout(retry); ws.close(); setConnected(false); };
+        }
+
+        const es = new EventSource('/api/events');
+        es.onopen = () => { setConnected(true); addLog('success', 'Uplink established (SSE).'); };
+        es.onmessage = (e) => dispatch('registry', e.data);
+        ['item-added', 'item-updated', 'item-removed', 'tick', 'countdown', 'status', 'mode', 'simulated', 'sources', 'auth-degraded', 'auth-restored', 'job'].forEach(event => {
+            es.addEventListener(event, (e) => dispatch(event, e.data));
         });
 
         es.onerror = () => setConnected(false);
@@ -225,17 +365,34 @@ const App = () => {
 
     useEffect(() => {
         const handleKeyDown = (e) => {
-            const { mode, selectedIndex, registry, showDetail } = stateRef.current;
+            const { mode, selectedIndex, registry, showDetail, view, suspended, suspendedIndex } = stateRef.current;
             const key = e.key.toLowerCase();
 
+            if (key === 'u') {
+                const next = view === 'suspended' ? 'registry' : 'suspended';
+                setView(next);
+                if (next === 'suspended') fetchSuspended(false);
+                return;
+            }
+            if (view === 'suspended') {
+                const count = suspended.users.length;
+                if (key === 'r') fetchSuspended(true);
+                if (key === 'escape') setView('registry');
+                if (key === 'l') launchSuspendedPlaybook(suspended.users[suspendedIndex]);
+                if (e.key === 'ArrowDown' && count > 0) { e.preventDefault(); setSuspendedIndex((suspendedIndex + 1) % count); }
+                if (e.key === 'ArrowUp' && count > 0) { e.preventDefault(); setSuspendedIndex((suspendedIndex - 1 + count) % count); }
+                return;
+            }
+
             if (key === 'a') { syncMode('AUTO'); setShowDetail(false); return; }
             if (key === 'm') { syncMode('MANUAL'); return; }
+            if (key === 's') { syncMode('SIMULATE'); return; }
             if (key === 'r') { 
-                if (mode === 'MANUAL') fetchRegistry(); 
+                if (mode !== 'AUTO') fetchRegistry(); 
                 return; 
             }
 
-            if (mode !== 'MANUAL') return;
+            if (mode === 'AUTO') return;
 
             if (showDetail && key === 'escape') {
                 setShowDetail(false);
@@ -276,6 +433,14 @@ const App = () => {
                 case 'Backspace':
                     if (registry[selectedIndex]) deleteItem(registry[selectedIndex]);
                     break;
+                case 'v':
+                case 'V':
+                    if (registry[selectedIndex]) archiveItem(registry[selectedIndex]);
+                    break;
+                case 't':
+                case 'T':
+                    if (registry[selectedIndex]) toggleDeadline(registry[selectedIndex]);
+                    break;
                 case 'PageUp':
                 case 'PageDown':
                     e.preventDefault();
@@ -283,24 +448,40 @@ const App = () => {
                     const currentItem = registry[selectedIndex];
                     if (currentItem && currentItem.type === 'keep') {
                         const currentStatus = currentItem.status || 'Pending';
-                        const cycle = ['Pending', 'Execute'];
+                        // Step through the taxonomy, skipping statuses the
+                        // current one may not change to.
+                        const taxonomy = statusesRef.current;
+                        const cycle = taxonomy?.values || ['Pending', 'Execute'];
+                        const allowed = taxonomy?.transitions?.[currentStatus];
+                        const step = e.key === 'PageUp' ? 1 : -1;
                         let idx = cycle.indexOf(currentStatus);
                         if (idx === -1) idx = 0;
-                        
-                        if (e.key === 'PageUp') {
-                            idx = (idx + 1) % cycle.length;
-                        } else {
-                            idx = (idx - 1 + cycle.length) % cycle.length;
+                        let newStatus = null;
+                        for (let n = 1; n < cycle.length; n++) {
+                            const candidate = cycle[(idx + step * n + cycle.length * n) % cycle.length];
+                            if (candidate !== currentStatus && (!allowed || allowed.includes(candidate))) {
+                                newStatus = candidate;
+                                break;
+                            }
+                        }
+                        if (!newStatus) {
+                            addLog('warning', `Status ${currentStatus} is final: ${currentItem.title}`);
+                            break;
                         }
-                        
-                        const newStatus = cycle[idx];
                         
                         // Optimistic update
                         setRegistry(prev => prev.map(item => 
                             item.id === currentItem.id ? { ...item, status: newStatus } : item
                         ));
 
-                        fetch(`/api/status?id=${encodeURIComponent(currentItem.id)}&status=${newStatus}`, { method: 'POST' })
+                        fetch(`/api/status?id=${encodeURIComponent(currentItem.id)}&status=${encodeURIComponent(newStatus)}`, { method: 'POST' })
+                            .then(res => {
+                                if (res.ok) return;
+                                setRegistry(prev => prev.map(item =>
+                                    item.id === currentItem.id ? { ...item, status: currentItem.status } : item
+                                ));
+                                addLog('error', `Status change refused: ${currentStatus} → ${newStatus}`);
+                            })
                             .catch(err => addLog('error', 'Failed to save status'));
                     }
                     break;
@@ -311,64 +492,17 @@ const App = () => {
         return () => window.removeEventListener('keydown', handleKeyDown);
     }, []);
 
-    const formatNoteContent = useMemo(() => {
-        const firstDefined = (obj, keys) => {
-            if (!obj) return undefined;
-            for (const key of keys) {
-                if (obj[key] !== undefined && obj[key] !== null) return obj[key];
-            }
-            return undefined;
-        };
-        const normalizeString = (value) => {
-            if (typeof value === 'string') return value;
-            if (!value) return '';
-            if (typeof value.text === 'string') return value.text;
-            if (typeof value.Text === 'string') return value.Text;
-            if (typeof value.value === 'string') return value.value;
-            return '';
-        };
-
-        return {
-            fromNote(note) {
-                const section = firstDefined(note, ['body', 'Body']);
-                if (!section) return 'No body content.';
-
-                const text = normalizeString(firstDefined(section, ['text', 'Text']));
-                if (text.trim() !== '') return text;
-
-                const list = firstDefined(section, ['list', 'List']);
-                const itemsList = firstDefined(list, ['listItems', 'ListItems']);
-                const items = Array.isArray(itemsList) ? itemsList : [];
-                
-                if (items.length > 0) {
-                    const lines = [];
-                    const walk = (entries, depth) => {
-                        entries.forEach((entry) => {
-                            const raw = normalizeString(firstDefined(entry, ['text', 'Text']));
-                            const label = raw.trim() === '' ? '[Empty]' : raw;
-                            const isChecked = firstDefined(entry, ['checked', 'Checked']);
-                            const checkedMarker = isChecked ? ' [x]' : '';
-                            lines.push(`${'  '.repeat(depth)}- ${label}${checkedMarker}`);
-                            const children = firstDefined(entry, ['childListItems', 'ChildListItems']);
-                            if (Array.isArray(children) && children.length > 0) walk(children, depth + 1);
-                        });
-                    };
-                    walk(items, 0);
-                    return lines.join('\n');
-                }
-                return 'No body content.';
-            },
-        };
-    }, []);
+    const formatCountdown = (seconds) => {
+        if (seconds <= 0) return 'due';
+        const d = Math.floor(seconds / 86400);
+        const h = Math.floor((seconds % 86400) / 3600);
+        const m = Math.floor((seconds % 3600) / 60);
+        if (d > 0) return `${d}d ${h}h`;
+        if (h > 0) return `${h}h ${m}m`;
+        return `${m}m ${seconds % 60}s`;
+    };
 
-    const detailContent = useMemo(() => {
-        if (!detailItem) return '';
-        const selectedItem = registry[selectedIndex];
-        if (selectedItem && selectedItem.type === 'keep') {
-            return formatNoteContent.fromNote(detailItem);
-        }
-        return 'Detail view not applicable for this item type.';
-    }, [detailItem, formatNoteContent, registry, selectedIndex]);
+    const detailContent = detailItem?.preview || '';
 
     const getTagStyles = (tag) => {
         switch (tag) {
@@ -403,9 +537,10 @@ const App = () => {
                 <div className="flex gap-8">
                     <span className={mode === 'AUTO' ? "text-emerald-500 font-bold" : "text-gray-600 cursor-pointer"} onClick={() => syncMode('AUTO')}>[A] AUTO</span>
                     <span className={mode === 'MANUAL' ? "text-yellow-600 font-bold" : "text-gray-600 cursor-pointer"} onClick={() => syncMode('MANUAL')}>[M] MANUAL</span>
-                    <span className={mode === 'MANUAL' ? "text-blue-500 cursor-pointer" : "text-gray-700 cursor-not-allowed"} onClick={() => mode === 'MANUAL' && fetchRegistry()}>[R] REFRESH</span>
+                    <span className={mode === 'SIMULATE' ? "text-cyan-400 font-bold" : "text-gray-600 cursor-pointer"} onClick={() => syncMode('SIMULATE')}>[S] SIMULATE</span>
+                    <span className={mode !== 'AUTO' ? "text-blue-500 cursor-pointer" : "text-gray-700 cursor-not-allowed"} onClick={() => mode !== 'AUTO' && fetchRegistry()}>[R] REFRESH</span>
                 </div>
-                <div className={mode === 'AUTO' ? "text-emerald-400 animate-pulse" : "text-yellow-600"}>STATUS: {mode}</div>
+                <div className={mode === 'AUTO' ? "text-emerald-400 animate-pulse" : mode === 'SIMULATE' || mode === 'AIRGAP' ? "text-cyan-400" : "text-yellow-600"}>STATUS: {mode}</div>
             </div>
 
             <div className="flex flex-1 gap-4 overflow-hidden">
@@ -420,6 +555,7 @@ const App = () => {
                                     log.type === 'success' ? 'text-emerald-500' :
                                     log.type === 'warning' ? 'text-yellow-500' :
                                     log.type === 'execute' ? 'text-purple-300' :
+                                    log.type === 'simulate' ? 'text-cyan-400' :
                                     'text-gray-500'
                                 }>
                                     {log.message}
@@ -431,22 +567,47 @@ const App = () => {
 
                 <div className="w-1/2 flex flex-col border border-gray-900 bg-black/40 rounded overflow-hidden relative">
                     <div className="text-[9px] text-gray-600 uppercase border-b border-gray-900 p-2 flex justify-between bg-black/60 z-10">
-                        <span>Unified Registry</span>
-                        <span className="text-[8px] text-gray-700">{connected ? 'LIVE STREAM' : 'DISCONNECTED'}</span>
+                        <span>{view === 'suspended' ? 'Suspended Users' : 'Unified Registry'}</span>
+                        <span className="text-[8px] text-gray-700">{connected ? (degraded.length ? `DEGRADED: ${degraded.join(', ').toUpperCase()}` : 'LIVE STREAM') : 'DISCONNECTED'}</span>
                     </div>
-                    {!showDetail ? (
+                    {view === 'suspended' ? (
+                        <div className="flex-1 space-y-1 overflow-y-auto scrollbar-hide p-2 pb-2">
+                            <div className="text-[9px] text-gray-600 uppercase mb-1">
+                                Suspended accounts {suspended.playbook ? `| [L] launch ${suspended.playbook}` : '| no playbook configured'}
+                            </div>
+                            {suspended.users.map((u, i) => (
+                                <div key={u.email} className={`p-2 border ${i === suspendedIndex ? 'bg-emerald-950/30 border-emerald-500 text-emerald-300' : 'border-transparent text-gray-600'}`}>
+                                    <div className="flex justify-between text-xs font-bold">
+                                        <span>{u.name || u.email}</span>
+                                        <span className="text-[9px] text-gray-500">{u.email}</span>
+                                    </div>
+                                    <div className="text-[10px]">
+                                        drive {u.content.drive.length} | keep {u.content.keep.length} | calendar {u.content.calendar.length}
+                                    </div>
+                                    {u.error && <div className="text-[10px] text-red-500">{u.error}</div>}
+                                    {Object.entries(u.content.errors || {}).map(([source, err]) => (
+                                        <div key={source} className="text-[10px] text-red-500 truncate">{source}: {err}</div>
+                                    ))}
+                                </div>
+                            ))}
+                            {suspended.users.length === 0 && <div className="text-[10px] text-gray-600 italic">No suspended accounts.</div>}
+                        </div>
+                    ) : !showDetail ? (
                         <div ref={registryRef} className="flex-1 space-y-1 overflow-y-auto scrollbar-hide p-2 pb-2">
                             {registry.map((item, i) => {
                                 const tagLabel = (item.type === 'keep')
                                     ? (item.status || 'Pending')
                                     : item.type;
                                 return (
-                                <div key={item.id} className={`p-2 border transition-all ${i === selectedIndex && mode === 'MANUAL' ? 'bg-emerald-950/30 border-emerald-500 text-emerald-300' : 'border-transparent text-gray-600'}`}>
+                                <div key={item.id} className={`p-2 border transition-all ${i === selectedIndex && mode !== 'AUTO' ? 'bg-emerald-950/30 border-emerald-500 text-emerald-300' : 'border-transparent text-gray-600'}`}>
                                     <div className="flex justify-between text-xs font-bold">
                                         <span>{item.title}</span>
                                         <span className={`text-[9px] uppercase px-2 py-0.5 rounded-full border ${getTagStyles(tagLabel)}`}>{tagLabel}</span>
                                     </div>
                                     <div className="text-[10px] truncate italic">{item.snippet || 'No content preview.'}</div>
+                                    {countdowns[item.id] !== undefined && (
+                                        <div className="text-[9px] text-red-400 uppercase">Delete in {formatCountdown(countdowns[item.id])}</div>
+                                    )}
                                 </div>
                                 );
                             })}
@@ -473,14 +634,15 @@ const App = () => {
                             )}
                             {!detailLoading && !detailError && detailItem && (
                                 <div ref={detailRef} className="flex-1 flex flex-col gap-2 overflow-auto scrollbar-hide">
-                                    {registry[selectedIndex]?.type === 'keep' && (
-                                        <div className="border border-emerald-900/40 bg-black/50 p-2 rounded">
-                                            <div className="text-[9px] uppercase text-emerald-500 mb-1">Body Content</div>
-                                            <div className="text-[11px] text-emerald-200 whitespace-pre-wrap leading-relaxed select-text">
-                                                {detailContent || 'No body content.'}
-                                            </div>
+                                    <div className="border border-emerald-900/40 bg-black/50 p-2 rounded">
+                                        <div className="text-[9px] uppercase text-emerald-500 mb-1">Preview</div>
+                                        <div className="text-[11px] text-emerald-200 whitespace-pre-wrap leading-relaxed select-text">
+                                            {detailContent || 'No body content.'}
                                         </div>
-                                    )}
+                                        <div className="text-[9px] text-emerald-600 mt-1">
+                                            {detailItem.owner || 'No owner'} · {detailItem.sharing} · {detailItem.size} bytes
+                                        </div>
+                                    </div>
                                     <div className="border border-blue-900/40 bg-black/50 p-2 rounded">
                                         <div className="text-[9px] uppercase text-blue-400 mb-1">Raw Payload</div>
                                         <pre className="text-[10px] text-blue-300 overflow-auto scrollbar-hide bg-black/40 p-2 rounded select-text">
@@ -497,7 +659,7 @@ const App = () => {
                 </div>
             </div>
             <div className="mt-4 flex justify-between text-[9px] text-gray-600 border-t border-gray-900 pt-2 uppercase italic">
-                <span>Arrows: Nav | Enter: Inspect | Delete: Kill</span>
+                <span>{view === 'suspended' ? 'Arrows: Nav | L: Launch Playbook | R: Rescan | U: Registry' : 'Arrows: Nav | Enter: Inspect | Delete: Kill | T: Delete in 7d | U: Suspended Users'}</span>
                 <span className="flex gap-4">
                     {mode === 'AUTO' && secondsRemaining !== null && (
                         <span className="text-emerald-500 font-bold">NEXT TICK: {secondsRemaining}s</span>